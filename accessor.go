@@ -0,0 +1,306 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"time"
+)
+
+// ErrFieldNotFound is returned by the Field accessors when the object has no
+// field with the requested key
+var ErrFieldNotFound = errors.New("bogo: field not found")
+
+var accessorErr = errors.New("accessor error")
+
+// LookupField returns the encoded value of field key in the object encoded in
+// data, starting at its type byte. Other fields are skipped using their size
+// information, so nothing is decoded or allocated. data is either a complete
+// document, starting with the version byte, or an encoded object value such as
+// one returned by FieldObject. A nil object, as FieldObject returns for null,
+// has no fields. In a document with a symbol table, values that may refer to
+// it are returned under a copy of the table, so they decode on their own.
+func LookupField(data []byte, key string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, key)
+	}
+
+	var value []byte
+	err := walkFields(data, nil, func(k, v []byte) bool {
+		if string(k) == key {
+			value = v
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value != nil {
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, key)
+}
+
+// Fields returns an iterator over the fields of the object encoded in data, in
+// the order they appear on the wire, yielding each key with its encoded value
+// as LookupField returns it. Duplicate keys are yielded every time they occur.
+// data takes the same forms as for LookupField. The object is checked before
+// the iterator is returned, so iteration itself cannot fail; data must not be
+// modified while iterating.
+func Fields(data []byte) (iter.Seq2[string, []byte], error) {
+	if len(data) == 0 {
+		return func(func(string, []byte) bool) {}, nil
+	}
+	if err := walkFields(data, nil, func(_, _ []byte) bool { return true }); err != nil {
+		return nil, err
+	}
+	return func(yield func(string, []byte) bool) {
+		_ = walkFields(data, nil, func(key, value []byte) bool {
+			return yield(string(key), value)
+		})
+	}, nil
+}
+
+// walkFields calls fn with the key and encoded value of each field of the
+// object in data until fn returns false. Fields without a value are reported
+// as null, and values are put under the symbol table of the object as
+// withSymbols does. The keys of a keyed object without a table of its own are
+// resolved against symbols.
+func walkFields(data []byte, symbols []string, fn func(key, value []byte) bool) error {
+	value, err := objectValue(data)
+	if err != nil {
+		return err
+	}
+	table, obj, err := splitSymbols(value)
+	if err == nil && table != nil {
+		symbols, err = tableSymbols(table)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", accessorErr, err)
+	}
+
+	fields, err := containerPayload(obj[1:])
+	if err != nil {
+		return wrapError(accessorErr, err.Error())
+	}
+	return walkObject(obj, fields, symbols, func(_, _ int, key, value []byte) bool {
+		return fn(key, withSymbols(table, value))
+	})
+}
+
+// walkEntries calls fn with the bounds, key and encoded value of each entry of
+// the object payload fields until fn returns false. An entry spans
+// fields[start:end], including its size prefix.
+func walkEntries(fields []byte, fn func(start, end int, key, value []byte) bool) error {
+	for pos := 0; pos < len(fields); {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			return wrapError(accessorErr, "insufficient data for entry size")
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil {
+			return wrapError(accessorErr, err.Error())
+		}
+		entryStart := pos + 1 + entrySizeLen
+		entryEnd := entryStart + int(entrySize)
+		if entryEnd > len(fields) || entryStart >= entryEnd {
+			return wrapError(accessorErr, "insufficient data for entry content")
+		}
+		entry := fields[entryStart:entryEnd]
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			return wrapError(accessorErr, "insufficient data for key")
+		}
+		value := entry[1+keyLen:]
+		if len(value) == 0 {
+			value = []byte{TypeNull}
+		}
+		if !fn(pos, entryEnd, entry[1:1+keyLen], value) {
+			return nil
+		}
+
+		pos = entryEnd
+	}
+	return nil
+}
+
+// objectValue strips the magic prefix or envelope and the version byte from a
+// complete document. An object value starts with TypeObject or
+// TypeKeyedObject, or TypeSymbols when it is under a symbol table, while a
+// document starts with Magic, EnvelopeMagic or Version, so the forms cannot be
+// confused. The returned value keeps its symbol tables.
+func objectValue(data []byte) ([]byte, error) {
+	data, _, err := openDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", accessorErr, err)
+	}
+	if len(data) >= 2 && data[0] == Version {
+		data = data[1:]
+	}
+	_, obj, err := splitSymbols(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", accessorErr, err)
+	}
+	if len(obj) == 0 || (Type(obj[0]) != TypeObject && Type(obj[0]) != TypeKeyedObject) {
+		return nil, wrapError(accessorErr, "data is not an encoded object")
+	}
+	return data, nil
+}
+
+// fieldValue decodes the single field key of the object in data
+func fieldValue(data []byte, key string) (any, error) {
+	raw, err := LookupField(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValue(raw)
+}
+
+func fieldTypeError(key string, want string, got any) error {
+	return wrapError(accessorErr, fmt.Sprintf("field %q is %T, not %s", key, got, want))
+}
+
+// FieldValue decodes field key of the object in data as Decode would
+func FieldValue(data []byte, key string) (any, error) {
+	return fieldValue(data, key)
+}
+
+// FieldString reads string field key of the object in data. Null reads as "".
+func FieldString(data []byte, key string) (string, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fieldTypeError(key, "string", v)
+	}
+	return s, nil
+}
+
+// FieldInt reads integer field key of the object in data. Unsigned values are
+// accepted when they fit in an int64. Null reads as 0.
+func FieldInt(data []byte, key string) (int64, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	switch n := widenNumber(v).(type) {
+	case int64:
+		return n, nil
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, wrapError(accessorErr, fmt.Sprintf("field %q overflows int64", key))
+		}
+		return int64(n), nil
+	case byte:
+		return int64(n), nil
+	}
+	return 0, fieldTypeError(key, "an integer", v)
+}
+
+// FieldUint reads unsigned integer field key of the object in data. Signed
+// values are accepted when they are not negative. Null reads as 0.
+func FieldUint(data []byte, key string) (uint64, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	switch n := widenNumber(v).(type) {
+	case uint64:
+		return n, nil
+	case int64:
+		if n < 0 {
+			return 0, wrapError(accessorErr, fmt.Sprintf("field %q is negative", key))
+		}
+		return uint64(n), nil
+	case byte:
+		return uint64(n), nil
+	}
+	return 0, fieldTypeError(key, "an unsigned integer", v)
+}
+
+// FieldFloat reads float field key of the object in data. Null reads as 0.
+func FieldFloat(data []byte, key string) (float64, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	f, ok := widenNumber(v).(float64)
+	if !ok {
+		return 0, fieldTypeError(key, "float64", v)
+	}
+	return f, nil
+}
+
+// FieldBool reads boolean field key of the object in data. Null reads as false.
+func FieldBool(data []byte, key string) (bool, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fieldTypeError(key, "bool", v)
+	}
+	return b, nil
+}
+
+// FieldBlob reads []byte field key of the object in data. Null reads as nil.
+func FieldBlob(data []byte, key string) ([]byte, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fieldTypeError(key, "[]byte", v)
+	}
+	return b, nil
+}
+
+// FieldTime reads time.Time field key of the object in data. Timestamps and
+// RFC 3339 strings are accepted, integers are read as Unix milliseconds. Null
+// reads as time.Time{}.
+func FieldTime(data []byte, key string) (time.Time, error) {
+	v, err := fieldValue(data, key)
+	if err != nil || v == nil {
+		return time.Time{}, err
+	}
+	t, ok := timeFromValue(v, TimeFormatMillis)
+	if !ok {
+		return time.Time{}, fieldTypeError(key, "time.Time", v)
+	}
+	return t, nil
+}
+
+// FieldObject returns the encoded object held in field key of the object in
+// data, for further navigation with the Field accessors. Null reads as nil.
+func FieldObject(data []byte, key string) ([]byte, error) {
+	raw, err := LookupField(data, key)
+	if err != nil {
+		return nil, err
+	}
+	_, value, err := splitSymbols(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", accessorErr, err)
+	}
+	switch Type(value[0]) {
+	case TypeNull:
+		return nil, nil
+	case TypeObject, TypeKeyedObject:
+		size, err := getElementSize(raw)
+		if err != nil {
+			return nil, wrapError(accessorErr, err.Error())
+		}
+		if size > len(raw) {
+			return nil, wrapError(accessorErr, "insufficient data for object")
+		}
+		return raw[:size], nil
+	}
+	return nil, wrapError(accessorErr, fmt.Sprintf("field %q is %s, not an object", key, Type(value[0])))
+}
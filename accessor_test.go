@@ -0,0 +1,178 @@
+package bogo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldAccessors(t *testing.T) {
+	when := time.UnixMilli(1700000000000)
+	data, err := Encode(map[string]any{
+		"name":    "Ada",
+		"age":     36,
+		"visits":  uint64(12),
+		"score":   9.5,
+		"admin":   true,
+		"avatar":  []byte{1, 2},
+		"joined":  when,
+		"manager": nil,
+		"address": map[string]any{"city": "London"},
+	})
+	require.NoError(t, err)
+
+	t.Run("scalars", func(t *testing.T) {
+		name, err := FieldString(data, "name")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", name)
+
+		age, err := FieldInt(data, "age")
+		require.NoError(t, err)
+		assert.Equal(t, int64(36), age)
+
+		visits, err := FieldUint(data, "visits")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(12), visits)
+
+		score, err := FieldFloat(data, "score")
+		require.NoError(t, err)
+		assert.Equal(t, 9.5, score)
+
+		admin, err := FieldBool(data, "admin")
+		require.NoError(t, err)
+		assert.True(t, admin)
+
+		avatar, err := FieldBlob(data, "avatar")
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2}, avatar)
+
+		joined, err := FieldTime(data, "joined")
+		require.NoError(t, err)
+		assert.True(t, when.Equal(joined))
+	})
+
+	t.Run("nested objects", func(t *testing.T) {
+		address, err := FieldObject(data, "address")
+		require.NoError(t, err)
+
+		city, err := FieldString(address, "city")
+		require.NoError(t, err)
+		assert.Equal(t, "London", city)
+
+		manager, err := FieldObject(data, "manager")
+		require.NoError(t, err)
+		assert.Nil(t, manager)
+	})
+
+	t.Run("null reads as zero", func(t *testing.T) {
+		s, err := FieldString(data, "manager")
+		require.NoError(t, err)
+		assert.Equal(t, "", s)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, err := FieldString(data, "email")
+		assert.True(t, errors.Is(err, ErrFieldNotFound))
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		_, err := FieldInt(data, "name")
+		assert.Error(t, err)
+
+		_, err = FieldObject(data, "name")
+		assert.Error(t, err)
+	})
+
+	t.Run("compact encodings", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithCompactIntegers(true), WithCompactStrings(true))
+		data, err := encoder.Encode(map[string]any{"n": 3, "s": "hi"})
+		require.NoError(t, err)
+
+		n, err := FieldInt(data, "n")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		s, err := FieldString(data, "s")
+		require.NoError(t, err)
+		assert.Equal(t, "hi", s)
+	})
+
+	t.Run("numeric widths", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithNumericWidths(true))
+		data, err := encoder.Encode(map[string]any{"small": int16(-3), "count": uint32(7), "ratio": float32(0.5)})
+		require.NoError(t, err)
+
+		small, err := FieldInt(data, "small")
+		require.NoError(t, err)
+		assert.Equal(t, int64(-3), small)
+
+		count, err := FieldUint(data, "count")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), count)
+
+		ratio, err := FieldFloat(data, "ratio")
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, ratio)
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		data, err := Encode("plain")
+		require.NoError(t, err)
+		_, err = LookupField(data, "x")
+		assert.Error(t, err)
+	})
+}
+
+func TestFields(t *testing.T) {
+	data := append([]byte{Version}, rawObject(
+		rawEntry("zeta", "last"),
+		rawEntry("alpha", int64(1)),
+		[]byte{3, 'n', 'i', 'l'}, // entry without a value
+		rawEntry("alpha", true),
+	)...)
+
+	fields, err := Fields(data)
+	require.NoError(t, err)
+
+	var keys []string
+	var values []any
+	for key, raw := range fields {
+		keys = append(keys, key)
+		value, err := decodeValue(raw)
+		require.NoError(t, err)
+		values = append(values, value)
+	}
+	assert.Equal(t, []string{"zeta", "alpha", "nil", "alpha"}, keys)
+	assert.Equal(t, []any{"last", int64(1), nil, true}, values)
+
+	t.Run("stops early", func(t *testing.T) {
+		var keys []string
+		for key := range fields {
+			keys = append(keys, key)
+			if key == "alpha" {
+				break
+			}
+		}
+		assert.Equal(t, []string{"zeta", "alpha"}, keys)
+	})
+
+	t.Run("nil object", func(t *testing.T) {
+		fields, err := Fields(nil)
+		require.NoError(t, err)
+		for range fields {
+			t.Fatal("nil object has no fields")
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := Fields([]byte{Version, TypeString, 1, 0})
+		assert.ErrorIs(t, err, accessorErr)
+
+		truncated := rawObjectFields(append(rawSize(10), rawEntry("a", "b")...))
+		_, err = Fields(truncated)
+		assert.ErrorIs(t, err, accessorErr)
+	})
+}
@@ -0,0 +1,47 @@
+package bogo
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var byteType = reflect.TypeFor[byte]()
+
+// byteArray returns a copy of the bytes of rv, an array of bytes
+func byteArray(rv reflect.Value) []byte {
+	b := make([]byte, rv.Len())
+	reflect.Copy(reflect.ValueOf(b), rv)
+	return b
+}
+
+// assignArray stores a decoded list or blob in the array target. The lengths
+// must match, so that fixed-size values such as hashes and UUIDs are never
+// silently truncated or padded.
+func assignArray(value any, target reflect.Value, d *Decoder) error {
+	source := reflect.ValueOf(value)
+	if source.Kind() != reflect.Slice && source.Kind() != reflect.Array {
+		return fmt.Errorf("cannot assign %T to %s", value, target.Type())
+	}
+	if source.Len() != target.Len() {
+		return fmt.Errorf("cannot assign %d elements to %s", source.Len(), target.Type())
+	}
+	if source.Type().Elem() == target.Type().Elem() {
+		reflect.Copy(target, source)
+		return nil
+	}
+
+	var errs DecodeErrors
+	for i := 0; i < source.Len(); i++ {
+		if err := assignValueToField(source.Index(i).Interface(), target.Index(i), d); err != nil {
+			if d.CollectErrors {
+				errs = collectErrors(errs, err, fmt.Sprintf("[%d]", i))
+				continue
+			}
+			return prefixPath(err, fmt.Sprintf("[%d]", i))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package bogo
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X int `json:"x"`
+}
+
+type arrayDoc struct {
+	Hash   [32]byte    `json:"hash"`
+	ID     [16]byte    `json:"id"`
+	IPv4   [4]byte     `json:"ipv4"`
+	Counts [3]int      `json:"counts"`
+	Names  [2]string   `json:"names"`
+	Points [2]point    `json:"points"`
+	Grid   [2][2]int16 `json:"grid"`
+}
+
+func TestFixedSizeArrays(t *testing.T) {
+	doc := arrayDoc{
+		Hash:   sha256.Sum256([]byte("bogo")),
+		ID:     [16]byte{0: 0xde, 15: 0xad},
+		IPv4:   [4]byte{10, 0, 0, 1},
+		Counts: [3]int{1, -2, 3},
+		Names:  [2]string{"a", "b"},
+		Points: [2]point{{X: 1}, {X: 2}},
+		Grid:   [2][2]int16{{1, 2}, {3, 4}},
+	}
+
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+
+	var got arrayDoc
+	require.NoError(t, Unmarshal(data, &got))
+	assert.Equal(t, doc, got)
+
+	t.Run("byte arrays are blobs", func(t *testing.T) {
+		hash, err := LookupField(data, "hash")
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeBlob), hash[0])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, doc.Hash[:], decoded.(map[string]any)["hash"])
+	})
+
+	t.Run("top-level arrays", func(t *testing.T) {
+		data, err := Marshal([4]byte{1, 2, 3, 4})
+		require.NoError(t, err)
+		var ip [4]byte
+		require.NoError(t, Unmarshal(data, &ip))
+		assert.Equal(t, [4]byte{1, 2, 3, 4}, ip)
+
+		data, err = Marshal([]int64{5, 6})
+		require.NoError(t, err)
+		var pair [2]int32
+		require.NoError(t, Unmarshal(data, &pair))
+		assert.Equal(t, [2]int32{5, 6}, pair)
+	})
+
+	t.Run("lengths must match", func(t *testing.T) {
+		data, err := Marshal([]byte{1, 2, 3, 4, 5})
+		require.NoError(t, err)
+		var ip [4]byte
+		assert.ErrorContains(t, Unmarshal(data, &ip), "cannot assign 5 elements to [4]uint8")
+
+		data, err = Marshal(map[string]any{"counts": []any{int64(1)}})
+		require.NoError(t, err)
+		var doc arrayDoc
+		assert.ErrorContains(t, Unmarshal(data, &doc), "cannot assign 1 elements to [3]int")
+	})
+}
@@ -51,19 +51,30 @@ func isNullValue(v any) bool {
 		return true
 	}
 
+	// Common types are checked without reflection
+	switch val := v.(type) {
+	case string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64:
+		return false
+	case []byte:
+		return val == nil
+	case []any:
+		return val == nil
+	case map[string]any:
+		return val == nil
+	}
+
 	data := reflect.ValueOf(v)
 	if !data.IsValid() {
 		return true
 	}
 
-	// Handle nil pointers, slices, maps, etc.
+	// Handle nil pointers, slices, maps, etc. Nil channels and functions are
+	// not null: like any other channel or function they are subject to the
+	// encoder's UnsupportedKindPolicy.
 	if data.CanInterface() && data.Kind() != reflect.Invalid {
 		switch data.Kind() {
-		case reflect.Ptr, reflect.Interface,
-			reflect.Slice, reflect.Map,
-
-			// we should never get here add for completeness sakes
-			reflect.Chan, reflect.Func:
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
 			if data.IsNil() {
 				return true
 			}
@@ -73,6 +84,23 @@ func isNullValue(v any) bool {
 	return false
 }
 
+// emptyCollection returns an empty value of the type of v when v is a nil
+// slice or map, for Encoder.NilCollectionsAsEmpty
+func emptyCollection(v any) (any, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.IsNil() {
+			return reflect.MakeSlice(rv.Type(), 0, 0).Interface(), true
+		}
+	case reflect.Map:
+		if rv.IsNil() {
+			return reflect.MakeMap(rv.Type()).Interface(), true
+		}
+	}
+	return nil, false
+}
+
 // isZeroValue checks if a value is the zero value for its type
 func isZeroValue(v any) bool {
 	if v == nil {
@@ -0,0 +1,337 @@
+package bogo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// BatchMagic starts every batch written by MarshalMany
+var BatchMagic = [4]byte{'B', 'G', 'O', 'M'}
+
+var batchErr = errors.New("bogo batch error")
+
+// A batch is laid out as
+//
+//	magic(4) | version(1) | record count | key count | keys | blob count | blobs | records
+//
+// Counts are size prefixes, and keys, blobs and records are length prefixed
+// as WriteLengthPrefixed writes them. A record is a value without the version
+// byte, and the key of every object entry in it is replaced by the uvarint
+// index of the key in the batch's dictionary, so that each distinct key is
+// stored once per batch rather than once per record. Likewise the payload of
+// every blob is replaced by the uvarint index of the payload in the blob
+// table, which holds each distinct payload once, so records carrying the same
+// attachment share a single copy of it.
+
+// MarshalMany encodes values with the default encoder into a single batch
+// sharing one version byte, one dictionary of object keys and one copy of
+// each distinct blob
+func MarshalMany[T any](values []T) ([]byte, error) {
+	dict := &keyDictionary{index: map[string]uint64{}, blobIndex: map[[sha256.Size]byte]uint64{}}
+	records := make([][]byte, len(values))
+	size := 0
+	for i, v := range values {
+		data, err := defaultEncoder.Encode(v)
+		if err != nil {
+			return nil, fmt.Errorf("bogo: batch record %d: %w", i, err)
+		}
+		if records[i], err = dict.appendValue(nil, stripMagic(data)[1:], false, 0); err != nil {
+			return nil, fmt.Errorf("bogo: batch record %d: %w", i, err)
+		}
+		size += len(records[i]) + 10
+	}
+
+	for _, blob := range dict.blobs {
+		size += len(blob) + 10
+	}
+
+	out := make([]byte, 0, len(BatchMagic)+1+size)
+	out = append(append(out, BatchMagic[:]...), Version)
+	out = appendUvarintLen(out, uint64(len(values)))
+	out = appendUvarintLen(out, uint64(len(dict.keys)))
+	for _, key := range dict.keys {
+		out = append(appendUvarintLen(out, uint64(len(key))), key...)
+	}
+	out = appendUvarintLen(out, uint64(len(dict.blobs)))
+	for _, blob := range dict.blobs {
+		out = append(appendUvarintLen(out, uint64(len(blob))), blob...)
+	}
+	for _, record := range records {
+		out = append(appendUvarintLen(out, uint64(len(record))), record...)
+	}
+	return out, nil
+}
+
+// UnmarshalMany decodes a batch written by MarshalMany with the default
+// decoder, storing the records in *v. The slice is allocated once, at the
+// size recorded in the batch header.
+func UnmarshalMany[T any](data []byte, v *[]T) error {
+	if v == nil {
+		return fmt.Errorf("bogo: UnmarshalMany destination must be a non-nil pointer")
+	}
+	if !bytes.HasPrefix(data, BatchMagic[:]) {
+		return wrapError(batchErr, "data is not a batch")
+	}
+	r := &batchReader{data: data[len(BatchMagic):]}
+	if len(r.data) == 0 {
+		return wrapError(batchErr, "insufficient data for version")
+	}
+	if version := r.data[0]; version != Version {
+		return fmt.Errorf("%w: %w", batchErr, versionMismatch(version))
+	}
+	r.data = r.data[1:]
+
+	count, err := r.uvarint("record count")
+	if err != nil {
+		return err
+	}
+	keyCount, err := r.uvarint("key count")
+	if err != nil {
+		return err
+	}
+	// Every key and record takes at least two bytes, which bounds what a
+	// corrupt count can make us allocate
+	if keyCount > uint64(len(r.data)/2) || count > uint64(len(r.data)/2) {
+		return wrapError(batchErr, "counts exceed the batch size")
+	}
+
+	dict := &keyDictionary{keys: make([]string, keyCount), maxDepth: defaultDecoder.MaxDepth}
+	for i := range dict.keys {
+		key, err := r.bytes("key")
+		if err != nil {
+			return err
+		}
+		if len(key) > 255 {
+			return wrapError(batchErr, fmt.Sprintf("key %d is longer than 255 bytes", i))
+		}
+		dict.keys[i] = string(key)
+	}
+
+	blobCount, err := r.uvarint("blob count")
+	if err != nil {
+		return err
+	}
+	if blobCount > uint64(len(r.data)/2) {
+		return wrapError(batchErr, "counts exceed the batch size")
+	}
+	dict.blobs = make([][]byte, blobCount)
+	for i := range dict.blobs {
+		if dict.blobs[i], err = r.bytes("blob"); err != nil {
+			return err
+		}
+	}
+
+	out := make([]T, count)
+	for i := range out {
+		record, err := r.bytes("record")
+		if err != nil {
+			return err
+		}
+		// Each record gets its own buffer, as decoded blobs refer to it
+		doc, err := dict.appendValue([]byte{Version}, record, true, 0)
+		if err != nil {
+			return fmt.Errorf("bogo: batch record %d: %w", i, err)
+		}
+		if err := defaultDecoder.Unmarshal(doc, &out[i]); err != nil {
+			return fmt.Errorf("bogo: batch record %d: %w", i, err)
+		}
+	}
+	if len(r.data) > 0 {
+		return wrapError(batchErr, fmt.Sprintf("%d trailing bytes after the records", len(r.data)))
+	}
+	*v = out
+	return nil
+}
+
+// batchReader reads the fields of a batch from the front of data
+type batchReader struct {
+	data []byte
+}
+
+// uvarint reads a size prefix
+func (r *batchReader) uvarint(what string) (uint64, error) {
+	if len(r.data) == 0 || len(r.data) < 1+int(r.data[0]) {
+		return 0, wrapError(batchErr, "insufficient data for "+what)
+	}
+	width := 1 + int(r.data[0])
+	n, err := decodeUint(r.data[1:width])
+	if err != nil {
+		return 0, wrapError(batchErr, fmt.Sprintf("%s: %v", what, err))
+	}
+	r.data = r.data[width:]
+	return n, nil
+}
+
+// bytes reads a length-prefixed field
+func (r *batchReader) bytes(what string) ([]byte, error) {
+	size, err := r.uvarint(what + " size")
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(r.data)) {
+		return nil, wrapError(batchErr, "insufficient data for "+what)
+	}
+	b := r.data[:size]
+	r.data = r.data[size:]
+	return b, nil
+}
+
+// keyDictionary maps the object keys and blob payloads of a batch to their
+// indexes. Blobs are looked up by the SHA-256 of their payload, so that the
+// index does not hold a second copy of every payload.
+type keyDictionary struct {
+	keys      []string
+	index     map[string]uint64
+	blobs     [][]byte
+	blobIndex map[[sha256.Size]byte]uint64
+	maxDepth  int // Deepest nesting accepted when expanding records (0 = unlimited)
+}
+
+// appendValue appends value to dst with the key of every object entry and
+// the payload of every blob either replaced by its dictionary index or, when
+// expand is set, restored from it
+func (k *keyDictionary) appendValue(dst, value []byte, expand bool, depth int) ([]byte, error) {
+	if len(value) == 0 {
+		return nil, wrapError(batchErr, "empty value")
+	}
+	typ := Type(value[0])
+	if typ == TypeBlob {
+		if expand {
+			return k.expandBlob(dst, value[1:])
+		}
+		return k.referenceBlob(dst, value[1:])
+	}
+	if len(value) == 1 || typ != TypeObject && typ != TypeUntypedList {
+		return append(dst, value...), nil
+	}
+	if expand && k.maxDepth > 0 && depth >= k.maxDepth {
+		return nil, wrapError(batchErr, fmt.Sprintf("maximum nesting depth exceeded (%d)", k.maxDepth))
+	}
+
+	payload, err := containerPayload(value[1:])
+	if err != nil {
+		return nil, wrapError(batchErr, err.Error())
+	}
+	if typ == TypeObject && len(payload) == 1 && payload[0] == TypeNull {
+		return append(dst, value...), nil
+	}
+
+	var rewritten []byte
+	for pos := 0; pos < len(payload); {
+		if typ == TypeUntypedList {
+			size, err := getElementSize(payload[pos:])
+			if expand && Type(payload[pos]) == TypeBlob {
+				size, err = blobReferenceSize(payload[pos:])
+			}
+			if err != nil || size > len(payload)-pos {
+				return nil, wrapError(batchErr, "invalid list element")
+			}
+			if rewritten, err = k.appendValue(rewritten, payload[pos:pos+size], expand, depth+1); err != nil {
+				return nil, err
+			}
+			pos += size
+			continue
+		}
+
+		entryPrefix, err := readSizePrefix(payload, pos)
+		if err != nil || entryPrefix.value > uint64(len(payload)-pos-entryPrefix.width) {
+			return nil, wrapError(batchErr, "invalid object entry")
+		}
+		entry := payload[pos+entryPrefix.width : pos+entryPrefix.width+int(entryPrefix.value)]
+		pos += entryPrefix.width + int(entryPrefix.value)
+
+		var key, rest []byte
+		if expand {
+			key, rest, err = k.expandKey(entry)
+		} else {
+			key, rest, err = k.referenceKey(entry)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 0 {
+			if key, err = k.appendValue(key, rest, expand, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		rewritten = append(appendUvarintLen(rewritten, uint64(len(key))), key...)
+	}
+
+	dst = appendTypedHeader(dst, typ, uint64(len(rewritten)))
+	return append(dst, rewritten...), nil
+}
+
+// referenceKey returns the dictionary index of the key at the start of entry,
+// adding the key when it is new, and the rest of the entry
+func (k *keyDictionary) referenceKey(entry []byte) ([]byte, []byte, error) {
+	if len(entry) == 0 || len(entry) < 1+int(entry[0]) {
+		return nil, nil, wrapError(batchErr, "insufficient data for key")
+	}
+	key := entry[1 : 1+int(entry[0])]
+	index, ok := k.index[string(key)]
+	if !ok {
+		index = uint64(len(k.keys))
+		k.keys = append(k.keys, string(key))
+		k.index[string(key)] = index
+	}
+	return binary.AppendUvarint(nil, index), entry[1+len(key):], nil
+}
+
+// expandKey returns the key referenced at the start of entry in the regular
+// key encoding, and the rest of the entry
+func (k *keyDictionary) expandKey(entry []byte) ([]byte, []byte, error) {
+	index, n := binary.Uvarint(entry)
+	if n <= 0 {
+		return nil, nil, wrapError(batchErr, "invalid key reference")
+	}
+	if index >= uint64(len(k.keys)) {
+		return nil, nil, wrapError(batchErr, fmt.Sprintf("key reference %d out of range", index))
+	}
+	key := k.keys[index]
+	return append(append(make([]byte, 0, 1+len(key)), byte(len(key))), key...), entry[n:], nil
+}
+
+// referenceBlob appends the blob whose encoding, after the type byte, is data
+// to dst as a reference to its payload in the blob table, adding the payload
+// when it is new
+func (k *keyDictionary) referenceBlob(dst, data []byte) ([]byte, error) {
+	payload, err := decodeBlob(data)
+	if err != nil {
+		return nil, wrapError(batchErr, err.Error())
+	}
+	sum := sha256.Sum256(payload)
+	index, ok := k.blobIndex[sum]
+	if !ok {
+		index = uint64(len(k.blobs))
+		k.blobs = append(k.blobs, payload)
+		k.blobIndex[sum] = index
+	}
+	return binary.AppendUvarint(append(dst, byte(TypeBlob)), index), nil
+}
+
+// expandBlob appends the blob referenced by data, the uvarint following the
+// type byte, to dst in the regular blob encoding
+func (k *keyDictionary) expandBlob(dst, data []byte) ([]byte, error) {
+	index, n := binary.Uvarint(data)
+	if n <= 0 || n != len(data) {
+		return nil, wrapError(batchErr, "invalid blob reference")
+	}
+	if index >= uint64(len(k.blobs)) {
+		return nil, wrapError(batchErr, fmt.Sprintf("blob reference %d out of range", index))
+	}
+	blob := k.blobs[index]
+	return append(appendTypedHeader(dst, TypeBlob, uint64(len(blob))), blob...), nil
+}
+
+// blobReferenceSize returns the size of the blob reference at the start of
+// data, type byte included
+func blobReferenceSize(data []byte) (int, error) {
+	_, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return 0, wrapError(batchErr, "invalid blob reference")
+	}
+	return 1 + n, nil
+}
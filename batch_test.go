@@ -0,0 +1,154 @@
+package bogo
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchRecord struct {
+	ID      int64             `json:"id"`
+	Status  string            `json:"status"`
+	Avatar  []byte            `json:"avatar"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Events  []batchEvent      `json:"events"`
+	Created time.Time         `json:"created"`
+}
+
+type batchEvent struct {
+	Kind string `json:"kind"`
+}
+
+func TestMarshalMany(t *testing.T) {
+	records := make([]batchRecord, 50)
+	for i := range records {
+		records[i] = batchRecord{
+			ID:      int64(i),
+			Status:  "active",
+			Avatar:  []byte{byte(i)},
+			Tags:    []string{"a", "b"},
+			Labels:  map[string]string{"team": fmt.Sprint("t", i%3)},
+			Events:  []batchEvent{{Kind: "created"}, {Kind: "updated"}},
+			Created: time.UnixMilli(1703030400000 + int64(i)).UTC(),
+		}
+	}
+
+	data, err := MarshalMany(records)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, BatchMagic[:]))
+
+	var separate int
+	for _, record := range records {
+		encoded, err := Marshal(record)
+		require.NoError(t, err)
+		separate += len(encoded)
+	}
+	assert.Less(t, len(data), separate*3/4, "keys are stored once per batch")
+
+	var got []batchRecord
+	require.NoError(t, UnmarshalMany(data, &got))
+	require.Len(t, got, len(records))
+	assert.Equal(t, len(records), cap(got))
+	for i := range records {
+		assert.True(t, records[i].Created.Equal(got[i].Created))
+		got[i].Created = records[i].Created
+	}
+	assert.Equal(t, records, got)
+
+	t.Run("values of any type", func(t *testing.T) {
+		values := []any{"x", int64(1), nil, []any{map[string]any{"k": map[string]any{}}}, map[string]any{"k": nil}}
+		data, err := MarshalMany(values)
+		require.NoError(t, err)
+
+		var got []any
+		require.NoError(t, UnmarshalMany(data, &got))
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		data, err := MarshalMany([]int64{})
+		require.NoError(t, err)
+		got := []int64{1}
+		require.NoError(t, UnmarshalMany(data, &got))
+		assert.Empty(t, got)
+	})
+
+	t.Run("identical blobs are stored once", func(t *testing.T) {
+		attachment := bytes.Repeat([]byte("shared image "), 100)
+		messages := make([]map[string]any, 20)
+		for i := range messages {
+			messages[i] = map[string]any{
+				"id":          int64(i),
+				"attachment":  attachment,
+				"attachments": []any{attachment, []byte{byte(i)}},
+			}
+		}
+		data, err := MarshalMany(messages)
+		require.NoError(t, err)
+		assert.Equal(t, 1, bytes.Count(data, attachment))
+		assert.Less(t, len(data), 2*len(attachment))
+
+		var got []map[string]any
+		require.NoError(t, UnmarshalMany(data, &got))
+		require.Len(t, got, len(messages))
+		for i := range messages {
+			assert.Equal(t, messages[i]["id"], got[i]["id"])
+			assert.Equal(t, attachment, got[i]["attachment"])
+			assert.Equal(t, []any{attachment, []byte{byte(i)}}, got[i]["attachments"])
+		}
+
+		got[0]["attachment"].([]byte)[0] = 'X'
+		assert.Equal(t, attachment, got[1]["attachment"], "records do not share decoded blobs")
+	})
+
+	t.Run("encode errors", func(t *testing.T) {
+		_, err := MarshalMany([]any{"ok", make(chan int)})
+		assert.ErrorContains(t, err, "batch record 1")
+	})
+}
+
+func TestUnmarshalManyErrors(t *testing.T) {
+	data, err := MarshalMany([]map[string]any{{"a": "x"}, {"a": "y"}})
+	require.NoError(t, err)
+
+	var got []map[string]any
+	assert.ErrorIs(t, UnmarshalMany([]byte("nope"), &got), batchErr)
+	assert.Error(t, UnmarshalMany(data, (*[]map[string]any)(nil)))
+	for i := len(BatchMagic); i < len(data); i++ {
+		assert.Error(t, UnmarshalMany(data[:i], &got), "truncated at %d", i)
+	}
+	assert.ErrorIs(t, UnmarshalMany(append(bytes.Clone(data), 0), &got), batchErr)
+
+	huge := append(append(BatchMagic[:], Version), appendUvarintLen(nil, 1<<40)...)
+	huge = appendUvarintLen(huge, 0)
+	assert.ErrorContains(t, UnmarshalMany(huge, &got), "counts exceed")
+
+	badRef := append(append(BatchMagic[:], Version), 1, 1, 1, 0, 1, 0)
+	record := appendTypedHeader(nil, TypeObject, 4)
+	record = append(record, 1, 2, 7, TypeNull)
+	badRef = append(appendUvarintLen(badRef, uint64(len(record))), record...)
+	assert.ErrorContains(t, UnmarshalMany(badRef, &got), "key reference 7 out of range")
+	assert.Nil(t, got, "the destination is untouched on error")
+
+	badBlob := append(append(BatchMagic[:], Version), 1, 1, 1, 0, 1, 1, 1, 1, 'x')
+	badBlob = append(appendUvarintLen(badBlob, 2), byte(TypeBlob), 3)
+	var blobs [][]byte
+	assert.ErrorContains(t, UnmarshalMany(badBlob, &blobs), "blob reference 3 out of range")
+
+	deep := any("leaf")
+	for i := 0; i < 10; i++ {
+		deep = map[string]any{"k": []any{deep}}
+	}
+	encoded, err := Marshal(deep)
+	require.NoError(t, err)
+	dict := &keyDictionary{index: map[string]uint64{}, maxDepth: 5}
+	record, err = dict.appendValue(nil, encoded[1:], false, 0)
+	require.NoError(t, err, "depth is only limited when expanding")
+	_, err = dict.appendValue(nil, record, true, 0)
+	assert.ErrorContains(t, err, "maximum nesting depth exceeded (5)")
+}
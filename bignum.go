@@ -0,0 +1,203 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+var bigNumErr = errors.New("bogo big number error")
+
+// Big number flags
+const (
+	bigNegative byte = 1 << iota
+	bigInfinite
+
+	bigKnownFlags = bigNegative | bigInfinite
+)
+
+// bigFloatHeader is the size of the flags, precision and exponent of a
+// TypeBigFloat payload
+const bigFloatHeader = 1 + 4 + 4
+
+// A big integer is laid out as
+//
+//	TypeBigInt | size prefix | flags(1) | magnitude
+//
+// and a big float, whose value is magnitude × 2^exponent, as
+//
+//	TypeBigFloat | size prefix | flags(1) | precision(4) | exponent(4) | magnitude
+//
+// The magnitude is big-endian and empty for zero and infinities; precision
+// and exponent are little-endian. Floats keep their precision but not their
+// rounding mode.
+
+func encodeBigInt(x *big.Int) ([]byte, error) {
+	var flags byte
+	if x.Sign() < 0 {
+		flags |= bigNegative
+	}
+	magnitude := x.Bytes()
+	buf := appendTypedHeader(make([]byte, 0, 3+maxStorageByteLength+len(magnitude)), TypeBigInt, uint64(1+len(magnitude)))
+	buf = append(buf, flags)
+	return append(buf, magnitude...), nil
+}
+
+func encodeBigFloat(x *big.Float) ([]byte, error) {
+	var flags byte
+	if x.Signbit() {
+		flags |= bigNegative
+	}
+
+	var magnitude []byte
+	exponent := 0
+	switch {
+	case x.IsInf():
+		flags |= bigInfinite
+	case x.Sign() != 0:
+		// x = mantissa × 2^exp with at most prec mantissa bits, so scaling
+		// the mantissa by 2^prec makes it an integer without rounding
+		mantissa := new(big.Float)
+		exp := x.MantExp(mantissa)
+		scaled, _ := mantissa.SetMantExp(mantissa, int(x.Prec())).Int(nil)
+		magnitude = scaled.Bytes()
+		exponent = exp - int(x.Prec())
+		// Trailing zero bytes of the magnitude are folded into the exponent
+		for len(magnitude) > 0 && magnitude[len(magnitude)-1] == 0 {
+			magnitude = magnitude[:len(magnitude)-1]
+			exponent += 8
+		}
+	}
+	if exponent < math.MinInt32 || exponent > math.MaxInt32 {
+		return nil, wrapError(bigNumErr, fmt.Sprintf("exponent %d out of range", exponent))
+	}
+
+	buf := appendTypedHeader(make([]byte, 0, 2+maxStorageByteLength+bigFloatHeader+len(magnitude)), TypeBigFloat, uint64(bigFloatHeader+len(magnitude)))
+	buf = append(buf, flags)
+	buf = wireOrder.AppendUint32(buf, uint32(x.Prec()))
+	buf = wireOrder.AppendUint32(buf, uint32(int32(exponent)))
+	return append(buf, magnitude...), nil
+}
+
+func decodeBigInt(data []byte) (*big.Int, error) {
+	payload, err := bigPayload(data, 1)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(payload[1:])
+	if payload[0]&bigNegative != 0 {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+func decodeBigFloat(data []byte) (*big.Float, error) {
+	payload, err := bigPayload(data, bigFloatHeader)
+	if err != nil {
+		return nil, err
+	}
+	flags := payload[0]
+	prec := uint(wireOrder.Uint32(payload[1:5]))
+	exponent := int(int32(wireOrder.Uint32(payload[5:9])))
+	magnitude := payload[bigFloatHeader:]
+	if prec > big.MaxPrec {
+		return nil, wrapError(bigNumErr, fmt.Sprintf("precision %d out of range", prec))
+	}
+
+	x := new(big.Float).SetPrec(prec)
+	switch {
+	case flags&bigInfinite != 0:
+		return x.SetInf(flags&bigNegative != 0), nil
+	case len(magnitude) > 0:
+		x.SetInt(new(big.Int).SetBytes(magnitude))
+		x.SetMantExp(x, exponent)
+	}
+	if flags&bigNegative != 0 {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+// bigPayload returns the payload of a big number, checking that it holds at
+// least header bytes and no unknown flags
+func bigPayload(data []byte, header int) ([]byte, error) {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return nil, wrapError(bigNumErr, err.Error())
+	}
+	if len(payload) < header {
+		return nil, wrapError(bigNumErr, "insufficient data for header")
+	}
+	if unknown := payload[0] &^ bigKnownFlags; unknown != 0 {
+		return nil, wrapError(bigNumErr, fmt.Sprintf("unknown flags %#x", unknown))
+	}
+	return payload, nil
+}
+
+// assignBig stores a decoded number in a big.Int or big.Float destination,
+// reporting whether dest is one
+func assignBig(value any, dest reflect.Value) (bool, error) {
+	switch dest.Type() {
+	case bigIntType:
+		x, err := toBigInt(value)
+		if err != nil {
+			return true, err
+		}
+		dest.Set(reflect.ValueOf(x).Elem())
+		return true, nil
+	case bigFloatType:
+		x, err := toBigFloat(value)
+		if err != nil {
+			return true, err
+		}
+		dest.Set(reflect.ValueOf(x).Elem())
+		return true, nil
+	}
+	return false, nil
+}
+
+func toBigInt(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case byte:
+		return big.NewInt(int64(v)), nil
+	case *big.Float:
+		if x, acc := v.Int(nil); acc == big.Exact {
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot assign %T to big.Int", value)
+}
+
+func toBigFloat(value any) (*big.Float, error) {
+	switch v := value.(type) {
+	case *big.Float:
+		return v, nil
+	case *big.Int:
+		return new(big.Float).SetInt(v), nil
+	case float64:
+		if math.IsNaN(v) {
+			return nil, fmt.Errorf("cannot assign NaN to big.Float")
+		}
+		return big.NewFloat(v), nil
+	case int64:
+		return new(big.Float).SetInt64(v), nil
+	case uint64:
+		return new(big.Float).SetUint64(v), nil
+	case byte:
+		return new(big.Float).SetUint64(uint64(v)), nil
+	}
+	return nil, fmt.Errorf("cannot assign %T to big.Float", value)
+}
@@ -0,0 +1,132 @@
+package bogo
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ledgerEntry struct {
+	Account string     `json:"account"`
+	Amount  *big.Int   `json:"amount"`
+	Balance big.Int    `json:"balance"`
+	Rate    *big.Float `json:"rate"`
+	Fee     big.Float  `json:"fee"`
+}
+
+func bigIntOf(t *testing.T, s string) *big.Int {
+	t.Helper()
+	x, ok := new(big.Int).SetString(s, 10)
+	require.True(t, ok)
+	return x
+}
+
+func TestBigInt(t *testing.T) {
+	for _, s := range []string{"0", "1", "-1", "255", "-256", "123456789012345678901234567890", "-1606938044258990275541962092341162602522202993782792835301376"} {
+		x := bigIntOf(t, s)
+		data, err := Marshal(x)
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeBigInt), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		require.IsType(t, &big.Int{}, decoded)
+		assert.Zero(t, x.Cmp(decoded.(*big.Int)), s)
+	}
+}
+
+func TestBigFloat(t *testing.T) {
+	third := new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3))
+	values := []*big.Float{
+		new(big.Float),
+		big.NewFloat(1.5),
+		big.NewFloat(-1024),
+		big.NewFloat(math.SmallestNonzeroFloat64),
+		new(big.Float).SetPrec(500).SetMantExp(big.NewFloat(1), 100000),
+		third,
+		new(big.Float).Neg(third),
+		new(big.Float).SetInf(false),
+		new(big.Float).SetInf(true),
+		new(big.Float).Neg(new(big.Float)),
+	}
+
+	for _, x := range values {
+		data, err := Marshal(x)
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeBigFloat), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		require.IsType(t, &big.Float{}, decoded)
+		got := decoded.(*big.Float)
+		assert.Zero(t, x.Cmp(got), x.Text('g', -1))
+		assert.Equal(t, x.Prec(), got.Prec(), x.Text('g', -1))
+		assert.Equal(t, x.Signbit(), got.Signbit(), x.Text('g', -1))
+	}
+}
+
+func TestBigNumberFields(t *testing.T) {
+	entry := ledgerEntry{
+		Account: "acme",
+		Amount:  bigIntOf(t, "-98765432109876543210"),
+		Rate:    new(big.Float).SetPrec(100).SetFloat64(0.0425),
+	}
+	entry.Balance.Set(bigIntOf(t, "1000000000000000000000000"))
+	entry.Fee.SetPrec(80).SetInt64(3)
+
+	data, err := Marshal(entry)
+	require.NoError(t, err)
+
+	var decoded ledgerEntry
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, "acme", decoded.Account)
+	assert.Zero(t, entry.Amount.Cmp(decoded.Amount))
+	assert.Zero(t, entry.Balance.Cmp(&decoded.Balance))
+	assert.Zero(t, entry.Rate.Cmp(decoded.Rate))
+	assert.Equal(t, uint(100), decoded.Rate.Prec())
+	assert.Zero(t, entry.Fee.Cmp(&decoded.Fee))
+
+	t.Run("from regular numbers", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"amount": -5, "balance": uint64(7), "rate": 0.5, "fee": 2})
+		require.NoError(t, err)
+
+		var decoded ledgerEntry
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, "-5", decoded.Amount.String())
+		assert.Equal(t, "7", decoded.Balance.String())
+		assert.Equal(t, "0.5", decoded.Rate.Text('g', -1))
+		assert.Equal(t, "2", decoded.Fee.Text('g', -1))
+	})
+
+	t.Run("mismatched values", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"amount": 1.5})
+		require.NoError(t, err)
+		var decoded ledgerEntry
+		assert.Error(t, Unmarshal(data, &decoded))
+	})
+
+	t.Run("top-level destinations", func(t *testing.T) {
+		data, err := Marshal(bigIntOf(t, "42"))
+		require.NoError(t, err)
+
+		var x big.Int
+		require.NoError(t, Unmarshal(data, &x))
+		assert.Equal(t, "42", x.String())
+
+		var f *big.Float
+		require.NoError(t, Unmarshal(data, &f))
+		assert.Equal(t, "42", f.Text('g', -1))
+	})
+}
+
+func TestBigNumberCorruption(t *testing.T) {
+	_, err := Decode([]byte{Version, TypeBigInt, 1, 2, 0x04, 1})
+	assert.ErrorIs(t, err, bigNumErr, "unknown flags")
+	_, err = Decode([]byte{Version, TypeBigInt, 1, 0})
+	assert.ErrorIs(t, err, bigNumErr, "missing flags")
+	_, err = Decode([]byte{Version, TypeBigFloat, 1, 3, 0, 1, 0})
+	assert.ErrorIs(t, err, bigNumErr, "short header")
+}
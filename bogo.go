@@ -69,12 +69,24 @@
 //   - Nil values are encoded as TypeNull and decode back to nil
 //   - Enables tri-state logic: true/false/unknown, value/zero/unset
 //
+// # Small Builds
+//
+// The bogo_small build tag leaves out the expvar adapters, which pull in
+// net/http, and checks the "email" format without net/mail, for size-constrained
+// targets such as WebAssembly (see package bogojs). TinyGo builds get the same
+// treatment automatically. Primitives, blobs, times and typed lists of the
+// common slice types are encoded without reflection, so firmware emitting
+// telemetry only depends on the parts of reflect that TinyGo supports.
+//
 // For complete technical specifications, see: https://github.com/bubunyo/bogo/blob/main/spec.md
 package bogo
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
 	"time"
 )
 
@@ -123,6 +135,43 @@ func encode(v any) ([]byte, error) {
 	if t, ok := v.(time.Time); ok {
 		return encodeTimestamp(t.UnixMilli())
 	}
+	if exp, ok := v.(Expiry); ok {
+		return encodeTimestamp(exp.Time().UnixMilli())
+	}
+	if date, ok := v.(Date); ok {
+		return encodeDate(date)
+	}
+	if d, ok := v.(time.Duration); ok {
+		return encodeDuration(d)
+	}
+	if x, ok := v.(*big.Int); ok {
+		return encodeBigInt(x)
+	}
+	if x, ok := v.(*big.Float); ok {
+		return encodeBigFloat(x)
+	}
+	if tod, ok := v.(TimeOfDay); ok {
+		return encodeTimeOfDay(tod)
+	}
+	if r, ok := v.(rangeValue); ok {
+		return encodeRange(r, encode)
+	}
+	if ls, ok := v.(LocalizedString); ok {
+		return encodeObject(map[string]any{ls.Lang: ls.Value})
+	}
+	if c, ok := v.(Char); ok {
+		return encodeString(string(rune(c)))
+	}
+	if g, ok := v.(Graph); ok {
+		obj, err := graphObject(g)
+		if err != nil {
+			return nil, err
+		}
+		return encodeObject(obj)
+	}
+	if data, ok, err := encodeCustom(v); ok {
+		return data, err
+	}
 
 	switch data.Kind() {
 	case reflect.Ptr:
@@ -139,8 +188,8 @@ func encode(v any) ([]byte, error) {
 		return buf, nil
 
 	case reflect.Uint8:
-		// Special case for byte (uint8)
-		return encodeByte(data.Interface().(byte))
+		// Special case for byte (uint8), including named uint8 types
+		return encodeByte(byte(data.Uint()))
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64:
@@ -150,11 +199,16 @@ func encode(v any) ([]byte, error) {
 		}
 		return res, nil
 
+	case reflect.Complex64, reflect.Complex128:
+		return encodeComplex(data.Complex())
+
 	case reflect.Slice, reflect.Array:
 		// Special case for []byte - encode as blob
 		if data.Type().Elem().Kind() == reflect.Uint8 {
-			byteSlice := data.Interface().([]byte)
-			return encodeBlob(byteSlice)
+			if data.Kind() == reflect.Array {
+				return encodeBlob(byteArray(data))
+			}
+			return encodeBlob(data.Bytes())
 		}
 		return encodeList(data.Interface())
 	case reflect.Map:
@@ -165,7 +219,7 @@ func encode(v any) ([]byte, error) {
 		return encodeStruct(data.Interface())
 	}
 
-	return nil, fmt.Errorf("bogo error: unsupported type. type=%T", v)
+	return nil, &UnsupportedTypeError{Type: data.Type()}
 }
 
 // Decode deserializes Bogo binary data back into a Go value.
@@ -195,34 +249,32 @@ func encode(v any) ([]byte, error) {
 //
 // Returns the decoded value and any decoding error.
 func Decode(data []byte) (any, error) {
+	data, _, err := openDocument(data)
+	if err != nil {
+		return nil, err
+	}
 	if len(data) < 2 {
 		return nil, fmt.Errorf("bogo decode error: insufficient data, need at least 2 bytes for version and type")
 	}
 
 	version := data[0]
 	if version != Version {
-		return nil, fmt.Errorf("bogo decode error: unsupported version %d, expected version %d", version, Version)
+		return nil, fmt.Errorf("bogo decode error: %w", versionMismatch(version))
+	}
+
+	if v, ok, err := decodeCompact(data[1:]); ok {
+		return v, err
 	}
 
 	switch Type(data[1]) {
 	case TypeNull:
 		return nil, nil
-	case TypeString:
-		sizeLen := int(data[2])
-		return decodeString(data[3:], sizeLen)
 	case TypeBoolTrue:
 		return true, nil
 	case TypeBoolFalse:
 		return false, nil
-	case TypeInt:
-		sizeLen := int(data[2])
-		return decodeInt(data[3 : 3+sizeLen])
-	case TypeUint:
-		sizeLen := int(data[2])
-		return decodeUint(data[3 : 3+sizeLen])
-	case TypeFloat:
-		sizeLen := int(data[2])
-		return decodeFloat(data[3 : 3+sizeLen])
+	case TypeString, TypeInt, TypeUint, TypeFloat:
+		return decodeValueWith(data[1:], stringMaker{})
 	case TypeBlob:
 		blob, err := decodeBlob(data[2:])
 		if err != nil {
@@ -236,6 +288,38 @@ func Decode(data []byte) (any, error) {
 		}
 		// Convert timestamp back to time.Time (in UTC to maintain consistency)
 		return time.UnixMilli(timestamp).UTC(), nil
+	case TypeDate:
+		return decodeDate(data[2:])
+	case TypeTimeOfDay:
+		return decodeTimeOfDay(data[2:])
+	case TypeRange:
+		return decodeRange(data[2:])
+	case TypeDuration:
+		return decodeDuration(data[2:])
+	case TypeZonedTimestamp:
+		return decodeZonedTimestamp(data[2:])
+	case TypeTimestampNanos:
+		return decodeTimestampNanos(data[2:])
+	case TypeExt:
+		return decodeExt(data[2:])
+	case TypeDecimal:
+		return decodeDecimal(data[2:])
+	case TypeExpiring:
+		return decodeExpiringWith(data[2:], decodeValue)
+	case TypeSymbols, TypeSymbol, TypeKeyedObject:
+		return decodeValueWith(data[1:], stringMaker{})
+	case TypeUnion:
+		return decodeUnionWith(data[2:], decodeValue)
+	case TypeNumeric:
+		return decodeNumeric(data[2:])
+	case TypeChunkedList:
+		return decodeChunkedListWith(data[2:], stringMaker{})
+	case TypeBigInt:
+		return decodeBigInt(data[2:])
+	case TypeBigFloat:
+		return decodeBigFloat(data[2:])
+	case TypeComplex:
+		return decodeComplex(data[2:])
 	case TypeByte:
 		byteVal, err := decodeByte(data[2:])
 		if err != nil {
@@ -260,6 +344,10 @@ func Decode(data []byte) (any, error) {
 			return nil, err
 		}
 		return obj, nil
+	case TypeMap:
+		return decodeMapWith(data[2:], stringMaker{})
+	case TypeObjectList:
+		return decodeObjectListWith(data[2:], stringMaker{})
 	default:
 		return nil, fmt.Errorf("type coder not supported, type=%d", data[1])
 	}
@@ -292,6 +380,14 @@ func Marshal(v any) ([]byte, error) {
 	return defaultEncoder.Encode(v)
 }
 
+// MarshalAppend encodes v as Marshal does and appends the document to dst,
+// returning the extended slice, or dst unchanged when v fails to encode.
+// Reusing dst across calls, for example through a sync.Pool of buffers, saves
+// allocating the document on every call.
+func MarshalAppend(dst []byte, v any) ([]byte, error) {
+	return defaultEncoder.EncodeAppend(dst, v)
+}
+
 // Unmarshal parses Bogo binary data and stores the result in the value pointed to by v.
 //
 // Unmarshal is compatible with json.Unmarshal and can be used as a drop-in replacement.
@@ -316,22 +412,25 @@ func Marshal(v any) ([]byte, error) {
 //
 // Returns an error if the data cannot be decoded or assigned to v.
 func Unmarshal(data []byte, v any) error {
-	result, err := defaultDecoder.Decode(data)
-	if err != nil {
-		return err
-	}
-
-	return assignResult(result, v)
+	return defaultDecoder.Unmarshal(data, v)
 }
 
 // assignResult assigns the decoded result to the pointer provided by the user
+// using the default decoder's configuration
 func assignResult(result any, v any) error {
+	return assignResultWith(result, v, defaultDecoder)
+}
+
+// assignResultWith assigns the decoded result to the pointer provided by the user
+// using the configuration of the given decoder
+func assignResultWith(result any, v any, d *Decoder) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return fmt.Errorf("bogo: Unmarshal destination must be a non-nil pointer")
 	}
 
 	elem := rv.Elem()
+	result = fromExpiring(result, elem)
 	resultValue := reflect.ValueOf(result)
 
 	// Handle nil result
@@ -340,12 +439,30 @@ func assignResult(result any, v any) error {
 		return nil
 	}
 
+	if ok, err := assignRegistered(result, elem, d); ok {
+		return err
+	}
+	if ok, err := assignUnmarshaler(result, elem); ok {
+		return err
+	}
+	result = fromNumber(result, elem)
+	resultValue = reflect.ValueOf(result)
+
 	// Try direct assignment first
 	if resultValue.Type().AssignableTo(elem.Type()) {
 		elem.Set(resultValue)
 		return nil
 	}
 
+	// Lazy objects are decoded in full for other destinations
+	if lazy, ok := result.(*LazyObject); ok && elem.Kind() != reflect.Interface {
+		m, err := lazy.Map()
+		if err != nil {
+			return err
+		}
+		return assignResultWith(m, v, d)
+	}
+
 	// Handle type conversions for common cases
 	switch elem.Kind() {
 	case reflect.Interface:
@@ -355,11 +472,20 @@ func assignResult(result any, v any) error {
 
 	case reflect.String:
 		if str, ok := result.(string); ok {
+			if err := checkEnum(elem.Type(), str); err != nil {
+				return err
+			}
 			elem.SetString(str)
 			return nil
 		}
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if assignRunes(result, elem) {
+			return nil
+		}
+		if d, ok := result.(time.Duration); ok {
+			result = int64(d)
+		}
 		if val, ok := result.(int64); ok {
 			if elem.OverflowInt(val) {
 				return fmt.Errorf("bogo: value %d overflows %s", val, elem.Type())
@@ -390,6 +516,15 @@ func assignResult(result any, v any) error {
 			return nil
 		}
 
+	case reflect.Complex64, reflect.Complex128:
+		if val, ok := result.(complex128); ok {
+			if elem.OverflowComplex(val) {
+				return fmt.Errorf("bogo: value %v overflows %s", val, elem.Type())
+			}
+			elem.SetComplex(val)
+			return nil
+		}
+
 	case reflect.Bool:
 		if val, ok := result.(bool); ok {
 			elem.SetBool(val)
@@ -404,11 +539,21 @@ func assignResult(result any, v any) error {
 				return nil
 			}
 		}
+		if assignRunes(result, elem) {
+			return nil
+		}
 		// Handle other slice types
 		if resultValue.Kind() == reflect.Slice && resultValue.Type().ConvertibleTo(elem.Type()) {
 			elem.Set(resultValue.Convert(elem.Type()))
 			return nil
 		}
+		// Convert the elements of decoded lists one by one
+		if resultValue.Kind() == reflect.Slice {
+			return assignValueToField(result, elem, d)
+		}
+
+	case reflect.Array:
+		return assignArray(result, elem, d)
 
 	case reflect.Map:
 		if resultValue.Kind() == reflect.Map {
@@ -418,15 +563,55 @@ func assignResult(result any, v any) error {
 			}
 			// Handle map[string]any -> map[string]T conversion
 			if elem.Type().Key() == reflect.TypeOf("") && resultValue.Type() == reflect.TypeOf(map[string]any{}) {
-				elem.Set(resultValue)
-				return nil
+				return convertMap(result.(map[string]any), elem, d)
 			}
+			return convertKeyedMap(resultValue, elem, d)
 		}
 
+	case reflect.Ptr:
+		return assignValueToField(result, elem, d)
+
 	case reflect.Struct:
+		if elem.Type() == graphType {
+			g, err := graphFromValue(result)
+			if err != nil {
+				return err
+			}
+			elem.Set(reflect.ValueOf(g))
+			return nil
+		}
+		if elem.Type() == localizedStringType {
+			if ls, ok := localizedStringFromValue(result, d.Locales); ok {
+				elem.Set(reflect.ValueOf(ls))
+				return nil
+			}
+		}
+		if ok, err := assignBig(result, elem); ok {
+			return err
+		}
+		if ok, err := assignNullable(result, elem, d); ok {
+			return err
+		}
 		// Handle map[string]any -> struct conversion using tags
 		if resultMap, ok := result.(map[string]any); ok {
-			return assignMapToStruct(resultMap, elem, defaultDecoder.TagName)
+			return assignMapToStruct(resultMap, elem, d)
+		}
+		if elem.Type() == reflect.TypeOf(time.Time{}) {
+			if t, ok := d.decodeTime(result); ok {
+				elem.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		if src, ok := result.(Range[any]); ok {
+			if assigner, ok := elem.Addr().Interface().(rangeAssigner); ok {
+				return assigner.assignRange(src, d)
+			}
+		}
+		if elem.Type() == expiryType {
+			if t, ok := d.decodeTime(result); ok {
+				elem.Set(reflect.ValueOf(Expiry(t)))
+				return nil
+			}
 		}
 	}
 
@@ -434,8 +619,10 @@ func assignResult(result any, v any) error {
 }
 
 // assignMapToStruct assigns values from a map[string]any to a struct using struct tags
-func assignMapToStruct(resultMap map[string]any, structValue reflect.Value, tagName string) error {
+func assignMapToStruct(resultMap map[string]any, structValue reflect.Value, d *Decoder) error {
 	structType := structValue.Type()
+	tagName := structTagName(structType, d.TagName)
+	var errs DecodeErrors
 
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
@@ -461,12 +648,33 @@ func assignMapToStruct(resultMap map[string]any, structValue reflect.Value, tagN
 			continue
 		}
 
+		// Enforce the format declared in the tag before assigning
+		if format := getStructFieldFormat(field, tagName); format != "" {
+			if err := validateFormat(mapValue, format); err != nil {
+				if d.CollectErrors {
+					errs = collectErrors(errs, err, fieldName)
+					continue
+				}
+				return prefixPath(err, fieldName)
+			}
+		}
+
 		// Recursively assign the value
-		if err := assignValueToField(mapValue, fieldValue, tagName); err != nil {
+		if err := assignValueToField(mapValue, fieldValue, d); err != nil {
+			if d.CollectErrors {
+				errs = collectErrors(errs, err, fieldName)
+				continue
+			}
+			if errors.As(err, new(*ValidationError)) {
+				return prefixPath(err, fieldName)
+			}
 			return fmt.Errorf("bogo: error assigning field %s: %w", fieldName, err)
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
@@ -492,29 +700,70 @@ func getStructFieldName(field reflect.StructField, tagName string) string {
 }
 
 // assignValueToField assigns a value to a struct field with type conversion
-func assignValueToField(value any, fieldValue reflect.Value, tagName string) error {
+func assignValueToField(value any, fieldValue reflect.Value, d *Decoder) error {
+	value = fromExpiring(value, fieldValue)
 	if value == nil {
 		fieldValue.Set(reflect.Zero(fieldValue.Type()))
 		return nil
 	}
 
+	if ok, err := assignRegistered(value, fieldValue, d); ok {
+		return err
+	}
+	if ok, err := assignUnmarshaler(value, fieldValue); ok {
+		return err
+	}
+	value = fromNumber(value, fieldValue)
+
 	valueReflect := reflect.ValueOf(value)
 
+	// Zoned timestamps decode to times, which the zero time policy still maps
+	if t, ok := value.(time.Time); ok && fieldValue.Type() == timeType {
+		t, _ = d.decodeTime(t)
+		fieldValue.Set(reflect.ValueOf(t))
+		return nil
+	}
+
 	// Try direct assignment first
 	if valueReflect.Type().AssignableTo(fieldValue.Type()) {
 		fieldValue.Set(valueReflect)
 		return nil
 	}
 
+	// Locale objects are narrowed to the decoder's preferred locale
+	if fieldValue.Type() == localizedStringType {
+		if ls, ok := localizedStringFromValue(value, d.Locales); ok {
+			fieldValue.Set(reflect.ValueOf(ls))
+			return nil
+		}
+	}
+	if fieldValue.Type() == graphType {
+		g, err := graphFromValue(value)
+		if err != nil {
+			return err
+		}
+		fieldValue.Set(reflect.ValueOf(g))
+		return nil
+	}
+
 	// Handle type conversions
 	switch fieldValue.Kind() {
 	case reflect.String:
 		if str, ok := value.(string); ok {
+			if err := checkEnum(fieldValue.Type(), str); err != nil {
+				return err
+			}
 			fieldValue.SetString(str)
 			return nil
 		}
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if assignRunes(value, fieldValue) {
+			return nil
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = int64(d)
+		}
 		if val, ok := value.(int64); ok {
 			if fieldValue.OverflowInt(val) {
 				return fmt.Errorf("value %d overflows %s", val, fieldValue.Type())
@@ -545,6 +794,15 @@ func assignValueToField(value any, fieldValue reflect.Value, tagName string) err
 			return nil
 		}
 
+	case reflect.Complex64, reflect.Complex128:
+		if val, ok := value.(complex128); ok {
+			if fieldValue.OverflowComplex(val) {
+				return fmt.Errorf("value %v overflows %s", val, fieldValue.Type())
+			}
+			fieldValue.SetComplex(val)
+			return nil
+		}
+
 	case reflect.Bool:
 		if val, ok := value.(bool); ok {
 			fieldValue.SetBool(val)
@@ -553,25 +811,43 @@ func assignValueToField(value any, fieldValue reflect.Value, tagName string) err
 
 	case reflect.Slice:
 		if fieldValue.Type().Elem().Kind() == reflect.Uint8 {
-			// Handle []byte
-			if val, ok := value.([]byte); ok {
+			// Handle []byte, taking strings as their bytes
+			switch val := value.(type) {
+			case []byte:
 				fieldValue.SetBytes(val)
 				return nil
+			case string:
+				fieldValue.SetBytes([]byte(val))
+				return nil
 			}
 		}
+		if assignRunes(value, fieldValue) {
+			return nil
+		}
 		// Handle other slice types by creating a new slice and converting elements
 		if valueReflect.Kind() == reflect.Slice {
 			newSlice := reflect.MakeSlice(fieldValue.Type(), valueReflect.Len(), valueReflect.Len())
+			var errs DecodeErrors
 			for i := 0; i < valueReflect.Len(); i++ {
 				elem := valueReflect.Index(i)
-				if err := assignValueToField(elem.Interface(), newSlice.Index(i), tagName); err != nil {
-					return err
+				if err := assignValueToField(elem.Interface(), newSlice.Index(i), d); err != nil {
+					if d.CollectErrors {
+						errs = collectErrors(errs, err, fmt.Sprintf("[%d]", i))
+						continue
+					}
+					return prefixPath(err, fmt.Sprintf("[%d]", i))
 				}
 			}
 			fieldValue.Set(newSlice)
+			if len(errs) > 0 {
+				return errs
+			}
 			return nil
 		}
 
+	case reflect.Array:
+		return assignArray(value, fieldValue, d)
+
 	case reflect.Map:
 		if valueReflect.Kind() == reflect.Map {
 			if valueReflect.Type().AssignableTo(fieldValue.Type()) {
@@ -581,13 +857,20 @@ func assignValueToField(value any, fieldValue reflect.Value, tagName string) err
 
 			// Handle map[string]interface{} to map[string]T conversion
 			if valueReflect.Type() == reflect.TypeOf(map[string]any{}) && fieldValue.Type().Key() == reflect.TypeOf("") {
-				return convertMap(value.(map[string]any), fieldValue, tagName)
+				return convertMap(value.(map[string]any), fieldValue, d)
 			}
+			return convertKeyedMap(valueReflect, fieldValue, d)
 		}
 
 	case reflect.Struct:
+		if ok, err := assignBig(value, fieldValue); ok {
+			return err
+		}
+		if ok, err := assignNullable(value, fieldValue, d); ok {
+			return err
+		}
 		if valueMap, ok := value.(map[string]any); ok {
-			return assignMapToStruct(valueMap, fieldValue, tagName)
+			return assignMapToStruct(valueMap, fieldValue, d)
 		}
 
 	case reflect.Ptr:
@@ -595,14 +878,25 @@ func assignValueToField(value any, fieldValue reflect.Value, tagName string) err
 		if fieldValue.IsNil() {
 			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
 		}
-		return assignValueToField(value, fieldValue.Elem(), tagName)
+		return assignValueToField(value, fieldValue.Elem(), d)
 	}
 
 	// Handle special cases for specific types
 	if fieldValue.Type() == reflect.TypeOf(time.Time{}) {
-		if ts, ok := value.(int64); ok {
-			// The timestamp is in milliseconds
-			fieldValue.Set(reflect.ValueOf(time.UnixMilli(ts)))
+		// Timestamps may arrive as integers or RFC 3339 strings depending on the time format
+		if t, ok := d.decodeTime(value); ok {
+			fieldValue.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+	if src, ok := value.(Range[any]); ok && fieldValue.CanAddr() {
+		if assigner, ok := fieldValue.Addr().Interface().(rangeAssigner); ok {
+			return assigner.assignRange(src, d)
+		}
+	}
+	if fieldValue.Type() == expiryType {
+		if t, ok := d.decodeTime(value); ok {
+			fieldValue.Set(reflect.ValueOf(Expiry(t)))
 			return nil
 		}
 	}
@@ -611,18 +905,34 @@ func assignValueToField(value any, fieldValue reflect.Value, tagName string) err
 }
 
 // convertMap converts a map[string]interface{} to a typed map
-func convertMap(sourceMap map[string]any, targetMapValue reflect.Value, tagName string) error {
+func convertMap(sourceMap map[string]any, targetMapValue reflect.Value, d *Decoder) error {
 	targetType := targetMapValue.Type()
 	valueType := targetType.Elem()
 
 	newMap := reflect.MakeMap(targetType)
+	var errs DecodeErrors
+
+	keys := make([]string, 0, len(sourceMap))
+	for key := range sourceMap {
+		keys = append(keys, key)
+	}
+	if d.CollectErrors {
+		sort.Strings(keys) // report problems in a stable order
+	}
 
-	for key, value := range sourceMap {
+	for _, key := range keys {
 		keyValue := reflect.ValueOf(key)
 
 		// Convert the map value to the target type
 		convertedValue := reflect.New(valueType).Elem()
-		if err := assignValueToField(value, convertedValue, tagName); err != nil {
+		if err := assignValueToField(sourceMap[key], convertedValue, d); err != nil {
+			if d.CollectErrors {
+				errs = collectErrors(errs, err, key)
+				continue
+			}
+			if errors.As(err, new(*ValidationError)) {
+				return prefixPath(err, key)
+			}
 			return fmt.Errorf("failed to convert map value for key %s: %w", key, err)
 		}
 
@@ -630,6 +940,9 @@ func convertMap(sourceMap map[string]any, targetMapValue reflect.Value, tagName
 	}
 
 	targetMapValue.Set(newMap)
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
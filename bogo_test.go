@@ -544,6 +544,106 @@ func TestMarshalUnmarshal(t *testing.T) {
 	}
 }
 
+func TestMarshalAppend(t *testing.T) {
+	v := map[string]any{"key": "value"}
+	want := mustMarshal(t, v)
+
+	buf := []byte("prefix")
+	buf, err := MarshalAppend(buf, v)
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte("prefix"), want...), buf)
+
+	reused, err := MarshalAppend(buf[:0], v)
+	require.NoError(t, err)
+	assert.Equal(t, want, reused)
+	assert.Same(t, &buf[0], &reused[0], "the buffer has room, so it is reused")
+
+	e := NewConfigurableEncoder(WithMagicPrefix(true))
+	data, err := e.EncodeAppend(nil, "x")
+	require.NoError(t, err)
+	assert.True(t, hasMagic(data))
+
+	kept, err := MarshalAppend([]byte("pre"), make(chan int))
+	assert.Error(t, err)
+	assert.Equal(t, []byte("pre"), kept)
+}
+
+func TestUnmarshalTopLevelContainers(t *testing.T) {
+	t.Run("untyped list", func(t *testing.T) {
+		data, err := Marshal([]any{"a", int64(1), nil})
+		require.NoError(t, err)
+
+		var result any
+		require.NoError(t, Unmarshal(data, &result))
+		assert.Equal(t, []any{"a", int64(1), nil}, result)
+	})
+
+	t.Run("typed map", func(t *testing.T) {
+		data, err := Marshal(map[string]int{"a": 1, "b": 2})
+		require.NoError(t, err)
+
+		var result map[string]int
+		require.NoError(t, Unmarshal(data, &result))
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, result)
+	})
+
+	t.Run("list of structs", func(t *testing.T) {
+		type item struct {
+			Name string `json:"name"`
+		}
+		data, err := Marshal([]item{{Name: "a"}, {Name: "b"}})
+		require.NoError(t, err)
+
+		var result []item
+		require.NoError(t, Unmarshal(data, &result))
+		assert.Equal(t, []item{{Name: "a"}, {Name: "b"}}, result)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		data, err := Marshal("hello")
+		require.NoError(t, err)
+
+		var result *string
+		require.NoError(t, Unmarshal(data, &result))
+		require.NotNil(t, result)
+		assert.Equal(t, "hello", *result)
+	})
+}
+
+func TestDecodeLists(t *testing.T) {
+	t.Run("malformed elements", func(t *testing.T) {
+		for name, elements := range map[string][]byte{
+			"string size":   {byte(TypeString)},
+			"string":        {byte(TypeString), 1, 0x76, 'a'},
+			"int size":      {byte(TypeInt), 8, 1},
+			"float":         {byte(TypeFloat), 8},
+			"huge list":     {byte(TypeUntypedList), 8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			"nested string": {byte(TypeUntypedList), 1, 3, byte(TypeString), 1, 0x20},
+		} {
+			t.Run(name, func(t *testing.T) {
+				document := append([]byte{Version, byte(TypeUntypedList), 1, byte(len(elements))}, elements...)
+				assertRejected(t, document)
+			})
+		}
+	})
+
+	t.Run("options apply to elements", func(t *testing.T) {
+		data, err := Marshal([]any{[]any{[]any{"a"}}, "b"})
+		require.NoError(t, err)
+		data[len(data)-1] = 0xff
+
+		_, err = NewConfigurableDecoder(WithDecoderMaxDepth(2)).Decode(data)
+		assert.ErrorContains(t, err, "maximum nesting depth exceeded")
+
+		_, err = NewConfigurableDecoder(WithDecoderMaxDepth(10)).Decode(data)
+		assert.ErrorContains(t, err, "invalid UTF-8")
+
+		v, err := NewConfigurableDecoder(WithUTF8Validation(false)).Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{[]any{[]any{"a"}}, "\xff"}, v)
+	})
+}
+
 func TestNumbersEncodingDecoding(t *testing.T) {
 	tests := []struct {
 		name  string
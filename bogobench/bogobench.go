@@ -0,0 +1,111 @@
+// Package bogobench benchmarks bogo against encoding/json and MessagePack on
+// your own payloads, so that encoder and decoder options can be chosen with
+// evidence rather than guesswork.
+//
+//	func BenchmarkOrders(b *testing.B) {
+//		bogobench.Run(b, sampleOrder(),
+//			bogobench.Variant{Name: "UntypedLists", Encoder: []bogo.EncoderOption{bogo.WithCompactLists(false)}},
+//		)
+//	}
+//
+// Every codec is reported under Encode/<codec> and Decode/<codec> with the
+// encoded size as a wire-bytes metric alongside the usual timings and
+// allocations.
+package bogobench
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Variant is a bogo configuration to benchmark next to the defaults
+type Variant struct {
+	Name    string               // Reported as Bogo-<Name>
+	Encoder []bogo.EncoderOption // Options used to encode the payload
+	Decoder []bogo.DecoderOption // Options used to decode the encoded payload
+}
+
+// codec is one way of encoding and decoding the payload
+type codec struct {
+	name      string
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// Run benchmarks encoding and decoding data with encoding/json, MessagePack,
+// bogo's default configuration and each variant. Decoding targets a new value
+// of the same type as data. A codec that cannot encode data is skipped.
+func Run(b *testing.B, data any, variants ...Variant) {
+	b.Helper()
+
+	codecs := newCodecs(variants)
+	encoded := make([][]byte, len(codecs))
+	errs := make([]error, len(codecs))
+	for i, c := range codecs {
+		encoded[i], errs[i] = c.marshal(data)
+	}
+
+	b.Run("Encode", func(b *testing.B) {
+		for i, c := range codecs {
+			b.Run(c.name, func(b *testing.B) {
+				if errs[i] != nil {
+					b.Skipf("%s cannot encode the payload: %v", c.name, errs[i])
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					if _, err := c.marshal(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(encoded[i])), "wire-bytes")
+			})
+		}
+	})
+
+	typ := reflect.TypeOf(data)
+	b.Run("Decode", func(b *testing.B) {
+		for i, c := range codecs {
+			b.Run(c.name, func(b *testing.B) {
+				if errs[i] != nil {
+					b.Skipf("%s cannot encode the payload: %v", c.name, errs[i])
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					if err := c.unmarshal(encoded[i], newTarget(typ)); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(encoded[i])), "wire-bytes")
+			})
+		}
+	})
+}
+
+// newCodecs returns the baselines followed by bogo and its variants
+func newCodecs(variants []Variant) []codec {
+	codecs := []codec{
+		{name: "JSON", marshal: json.Marshal, unmarshal: json.Unmarshal},
+		{name: "MessagePack", marshal: msgpack.Marshal, unmarshal: msgpack.Unmarshal},
+		{name: "Bogo", marshal: bogo.Marshal, unmarshal: bogo.Unmarshal},
+	}
+	for _, variant := range variants {
+		encoder := bogo.NewConfigurableEncoder(variant.Encoder...)
+		decoder := bogo.NewConfigurableDecoder(variant.Decoder...)
+		codecs = append(codecs, codec{name: "Bogo-" + variant.Name, marshal: encoder.Encode, unmarshal: decoder.Unmarshal})
+	}
+	return codecs
+}
+
+// newTarget returns a pointer to decode into, a *any when data was nil
+func newTarget(typ reflect.Type) any {
+	if typ == nil {
+		return new(any)
+	}
+	return reflect.New(typ).Interface()
+}
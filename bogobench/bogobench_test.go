@@ -0,0 +1,59 @@
+package bogobench
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type event struct {
+	ID       int64     `json:"id" msgpack:"id"`
+	Kind     string    `json:"kind" msgpack:"kind"`
+	At       time.Time `json:"at" msgpack:"at"`
+	Tags     []string  `json:"tags" msgpack:"tags"`
+	Readings []float64 `json:"readings" msgpack:"readings"`
+}
+
+func sampleEvent() event {
+	return event{
+		ID:       42,
+		Kind:     "checkout",
+		At:       time.Unix(1703030400, 0).UTC(),
+		Tags:     []string{"web", "eu-west", "returning"},
+		Readings: []float64{12.5, 13.25, 11.75, 14.0},
+	}
+}
+
+var variants = []Variant{
+	{Name: "UntypedLists", Encoder: []bogo.EncoderOption{bogo.WithCompactLists(false)}},
+	{Name: "Compact", Encoder: []bogo.EncoderOption{bogo.WithCompactIntegers(true), bogo.WithCompactStrings(true)}},
+}
+
+func BenchmarkRun(b *testing.B) {
+	Run(b, sampleEvent(), variants...)
+}
+
+func TestCodecs(t *testing.T) {
+	codecs := newCodecs(variants)
+
+	var names []string
+	for _, c := range codecs {
+		names = append(names, c.name)
+
+		data, err := c.marshal(sampleEvent())
+		require.NoError(t, err, c.name)
+		target := newTarget(reflect.TypeOf(sampleEvent()))
+		require.NoError(t, c.unmarshal(data, target), c.name)
+		got, want := *target.(*event), sampleEvent()
+		assert.True(t, want.At.Equal(got.At), c.name)
+		got.At = want.At
+		assert.Equal(t, want, got, c.name)
+	}
+	assert.Equal(t, []string{"JSON", "MessagePack", "Bogo", "Bogo-UntypedLists", "Bogo-Compact"}, names)
+
+	assert.IsType(t, new(any), newTarget(nil))
+}
@@ -0,0 +1,172 @@
+package bogographql
+
+import (
+	"testing"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelection(t *testing.T) {
+	query := `
+		# Fetch a user with their posts
+		query User($id: ID!, $withEmail: Boolean = false) {
+			user(id: $id, filter: {roles: ["admin", "staff"], note: "a } in a string"}) {
+				id
+				handle: login
+				email @include(if: $withEmail)
+				...Profile
+				posts(first: 10) {
+					edges { node { title, __typename } }
+				}
+				... on Admin { permissions }
+				... @skip(if: false) { id createdAt }
+			}
+		}
+
+		query Other { version }
+
+		fragment Profile on User {
+			avatar(size: 64)
+			posts { totalCount }
+			description(format: """multi "quoted"
+			line \""" text""")
+		}
+	`
+
+	sel, err := ParseSelection(query, "User")
+	require.NoError(t, err)
+	assert.Equal(t, bogo.Selection{
+		"user": {
+			"id":          nil,
+			"handle":      nil,
+			"email":       nil,
+			"avatar":      nil,
+			"description": nil,
+			"permissions": nil,
+			"createdAt":   nil,
+			"posts": {
+				"totalCount": nil,
+				"edges":      {"node": {"title": nil, "__typename": nil}},
+			},
+		},
+	}, sel)
+
+	sel, err = ParseSelection(query, "Other")
+	require.NoError(t, err)
+	assert.Equal(t, bogo.Selection{"version": nil}, sel)
+}
+
+func TestParseSelectionShorthand(t *testing.T) {
+	sel, err := ParseSelection("{ a b { c } }", "")
+	require.NoError(t, err)
+	assert.Equal(t, bogo.Selection{"a": nil, "b": {"c": nil}}, sel)
+}
+
+func TestParseSelectionErrors(t *testing.T) {
+	tests := []struct {
+		name, document, operation, err string
+	}{
+		{"ambiguous operation", "query A { a } query B { b }", "", "operation name required"},
+		{"missing operation", "query A { a }", "B", `operation "B" not found`},
+		{"unknown fragment", "{ ...Missing }", "", `unknown fragment "Missing"`},
+		{"recursive fragment", "{ ...F } fragment F on T { a { ...F } }", "", `fragment "F" spreads itself`},
+		{"unclosed", "{ a { b }", "", "unclosed selection set"},
+		{"unterminated string", `{ a(s: "x) }`, "", "unterminated string"},
+		{"unexpected keyword", "schema { query: Q }", "", `unexpected "schema"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSelection(tt.document, tt.operation)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, graphqlErr)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	result := Result{
+		Data: map[string]any{
+			"user": map[string]any{
+				"id":       "u1",
+				"internal": "not selected",
+				"posts": []any{
+					map[string]any{"title": "Hello", "body": "long text"},
+					map[string]any{"title": "Again", "body": "more text"},
+				},
+			},
+		},
+		Errors: []Error{{
+			Message:    "cannot load avatar",
+			Locations:  []Location{{Line: 3, Column: 5}},
+			Path:       []any{"user", "avatar"},
+			Extensions: map[string]any{"code": "UNAVAILABLE"},
+		}},
+		Extensions: map[string]any{"cost": int64(12)},
+	}
+
+	data, err := Encode(result)
+	require.NoError(t, err)
+
+	sel, err := ParseSelection("{ user { id posts { title } } }", "")
+	require.NoError(t, err)
+	decoded, err := Decode(data, sel)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"user": map[string]any{
+			"id": "u1",
+			"posts": []any{
+				map[string]any{"title": "Hello"},
+				map[string]any{"title": "Again"},
+			},
+		},
+	}, decoded.Data)
+	assert.Equal(t, result.Errors, decoded.Errors)
+	assert.Equal(t, result.Extensions, decoded.Extensions)
+
+	all, err := Decode(data, nil)
+	require.NoError(t, err)
+	assert.Equal(t, result.Data, all.Data)
+}
+
+func TestDecodeNullData(t *testing.T) {
+	data, err := Encode(Result{Errors: []Error{{Message: "boom"}}})
+	require.NoError(t, err)
+
+	decoded, err := Decode(data, bogo.Selection{"a": nil})
+	require.NoError(t, err)
+	assert.Nil(t, decoded.Data)
+	assert.Equal(t, []Error{{Message: "boom"}}, decoded.Errors)
+}
+
+func BenchmarkDecode(b *testing.B) {
+	posts := make([]any, 100)
+	for i := range posts {
+		posts[i] = map[string]any{"id": int64(i), "title": "Title", "body": "A long body nobody asked for", "tags": []string{"a", "b", "c"}}
+	}
+	data, err := Encode(Result{Data: map[string]any{"posts": posts}})
+	require.NoError(b, err)
+	sel, err := ParseSelection("{ posts { id title } }", "")
+	require.NoError(b, err)
+
+	b.Run("selection", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := Decode(data, sel); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("full", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := Decode(data, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
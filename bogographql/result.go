@@ -0,0 +1,107 @@
+// Package bogographql carries GraphQL execution results as bogo, so hops
+// between a gateway and its subgraphs stop paying JSON costs. The receiving
+// side decodes only the fields its query selects: the selection set of the
+// query drives selective decoding, and fields a subgraph sends beyond it are
+// skipped without being decoded.
+//
+//	// subgraph
+//	data, err := bogographql.Encode(bogographql.Result{Data: data})
+//
+//	// gateway
+//	sel, err := bogographql.ParseSelection(query, operationName)
+//	result, err := bogographql.Decode(body, sel)
+package bogographql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bubunyo/bogo"
+)
+
+var graphqlErr = errors.New("bogographql error")
+
+// Result is a GraphQL execution result
+type Result struct {
+	Data       any            `json:"data"`
+	Errors     []Error        `json:"errors,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Error is a GraphQL field or request error
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"` // Response keys and list indexes
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Location is a position in a GraphQL document
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Encode encodes result with bogo's default encoder
+func Encode(result Result) ([]byte, error) {
+	return bogo.Marshal(result)
+}
+
+// Decode decodes a result encoded by Encode with bogo's default decoder,
+// keeping only the fields of data in sel. A nil selection keeps every field.
+func Decode(data []byte, sel bogo.Selection) (*Result, error) {
+	return DecodeWith(bogo.NewConfigurableDecoder(), data, sel)
+}
+
+// DecodeWith is Decode using decoder
+func DecodeWith(decoder *bogo.Decoder, data []byte, sel bogo.Selection) (*Result, error) {
+	decoded, err := decoder.DecodeSelection(data, bogo.Selection{"data": sel, "errors": nil, "extensions": nil})
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a result object, got %T", graphqlErr, decoded)
+	}
+
+	result := &Result{Data: obj["data"]}
+	if extensions, ok := obj["extensions"].(map[string]any); ok {
+		result.Extensions = extensions
+	}
+	if errs, ok := obj["errors"].([]any); ok {
+		for i, e := range errs {
+			gqlErr, err := toError(e)
+			if err != nil {
+				return nil, fmt.Errorf("%w: errors[%d]: %w", graphqlErr, i, err)
+			}
+			result.Errors = append(result.Errors, gqlErr)
+		}
+	}
+	return result, nil
+}
+
+// toError converts a decoded error object
+func toError(v any) (Error, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return Error{}, fmt.Errorf("expected an object, got %T", v)
+	}
+	var e Error
+	e.Message, _ = obj["message"].(string)
+	e.Extensions, _ = obj["extensions"].(map[string]any)
+	if path, ok := obj["path"].([]any); ok {
+		e.Path = path
+	}
+	if locations, ok := obj["locations"].([]any); ok {
+		for _, l := range locations {
+			loc, ok := l.(map[string]any)
+			if !ok {
+				return Error{}, fmt.Errorf("expected a location object, got %T", l)
+			}
+			line, _ := loc["line"].(int64)
+			column, _ := loc["column"].(int64)
+			e.Locations = append(e.Locations, Location{Line: int(line), Column: int(column)})
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,362 @@
+package bogographql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bubunyo/bogo"
+)
+
+// ParseSelection returns the response keys selected by operation
+// operationName of a GraphQL document, as a selection for Decode. The name
+// may be empty when the document holds a single operation. Aliases are
+// response keys, fragment spreads and inline fragments are merged into the
+// enclosing selection, and fields under conditional directives such as
+// @include are kept, as they may be present.
+func ParseSelection(document, operationName string) (bogo.Selection, error) {
+	p := &parser{src: strings.TrimPrefix(document, "\ufeff")}
+	var operations []operation
+	fragments := map[string][]selectionNode{}
+
+	for p.skipIgnored(); !p.done(); p.skipIgnored() {
+		if p.peek() == '{' {
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, operation{set: set})
+			continue
+		}
+
+		keyword := p.name()
+		switch keyword {
+		case "query", "mutation", "subscription":
+			p.skipIgnored()
+			var name string
+			if isNameStart(p.peek()) {
+				name = p.name()
+			}
+			// Variable definitions and directives precede the selection set
+			if err := p.skipUntil('{'); err != nil {
+				return nil, err
+			}
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, operation{name: name, set: set})
+		case "fragment":
+			p.skipIgnored()
+			name := p.name()
+			if name == "" {
+				return nil, p.errorf("expected a fragment name")
+			}
+			if err := p.skipUntil('{'); err != nil {
+				return nil, err
+			}
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			fragments[name] = set
+		default:
+			return nil, p.errorf("unexpected %q", keyword)
+		}
+	}
+
+	var op *operation
+	for i := range operations {
+		if operationName == "" || operations[i].name == operationName {
+			if op != nil {
+				return nil, fmt.Errorf("%w: operation name required, the document has several operations", graphqlErr)
+			}
+			op = &operations[i]
+		}
+	}
+	if op == nil {
+		return nil, fmt.Errorf("%w: operation %q not found", graphqlErr, operationName)
+	}
+
+	sel := bogo.Selection{}
+	if err := merge(sel, op.set, fragments, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+type operation struct {
+	name string
+	set  []selectionNode
+}
+
+// selectionNode is a field, with its response key, or a fragment spread or
+// inline fragment, with its name or nothing
+type selectionNode struct {
+	key      string
+	spread   string
+	inline   bool
+	children []selectionNode // nil for leaf fields
+}
+
+// merge adds the response keys of set to sel, expanding fragments
+func merge(sel bogo.Selection, set []selectionNode, fragments map[string][]selectionNode, expanding map[string]bool) error {
+	for _, node := range set {
+		switch {
+		case node.inline:
+			if err := merge(sel, node.children, fragments, expanding); err != nil {
+				return err
+			}
+		case node.spread != "":
+			fragment, ok := fragments[node.spread]
+			if !ok {
+				return fmt.Errorf("%w: unknown fragment %q", graphqlErr, node.spread)
+			}
+			if expanding[node.spread] {
+				return fmt.Errorf("%w: fragment %q spreads itself", graphqlErr, node.spread)
+			}
+			expanding[node.spread] = true
+			err := merge(sel, fragment, fragments, expanding)
+			delete(expanding, node.spread)
+			if err != nil {
+				return err
+			}
+		case node.children == nil:
+			if _, ok := sel[node.key]; !ok {
+				sel[node.key] = nil
+			}
+		default:
+			sub := sel[node.key]
+			if sub == nil {
+				sub = bogo.Selection{}
+				sel[node.key] = sub
+			}
+			if err := merge(sub, node.children, fragments, expanding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parser reads the parts of GraphQL documents that shape responses, skipping
+// arguments, variable definitions and directives
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: offset %d: %s", graphqlErr, p.pos, fmt.Sprintf(format, args...))
+}
+
+// skipIgnored skips white space, commas and comments
+func (p *parser) skipIgnored() {
+	for !p.done() {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			p.pos++
+		case c == '#':
+			for !p.done() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func (p *parser) name() string {
+	start := p.pos
+	if !isNameStart(p.peek()) {
+		return ""
+	}
+	for !p.done() && (isNameStart(p.src[p.pos]) || p.src[p.pos] >= '0' && p.src[p.pos] <= '9') {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// skipUntil skips to the next top-level occurrence of c, passing over
+// strings and bracketed groups
+func (p *parser) skipUntil(c byte) error {
+	for p.skipIgnored(); !p.done(); p.skipIgnored() {
+		switch p.peek() {
+		case c:
+			return nil
+		case '"':
+			if err := p.skipString(); err != nil {
+				return err
+			}
+		case '(', '[':
+			if err := p.skipGroup(); err != nil {
+				return err
+			}
+		default:
+			p.pos++
+		}
+	}
+	return p.errorf("expected %q", c)
+}
+
+// skipGroup skips a balanced (...) or [...] group, such as arguments
+func (p *parser) skipGroup() error {
+	open := p.peek()
+	closing := map[byte]byte{'(': ')', '[': ']', '{': '}'}[open]
+	p.pos++
+	for p.skipIgnored(); !p.done(); p.skipIgnored() {
+		switch c := p.peek(); c {
+		case closing:
+			p.pos++
+			return nil
+		case '"':
+			if err := p.skipString(); err != nil {
+				return err
+			}
+		case '(', '[', '{':
+			if err := p.skipGroup(); err != nil {
+				return err
+			}
+		default:
+			p.pos++
+		}
+	}
+	return p.errorf("unclosed %q", open)
+}
+
+// skipString skips a string or block string value
+func (p *parser) skipString() error {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		for i := p.pos + 3; i < len(p.src); i++ {
+			switch {
+			case strings.HasPrefix(p.src[i:], `\"""`):
+				i += 3
+			case strings.HasPrefix(p.src[i:], `"""`):
+				p.pos = i + 3
+				return nil
+			}
+		}
+		return p.errorf("unterminated block string")
+	}
+	for p.pos++; !p.done(); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			return nil
+		case '\n':
+			return p.errorf("unterminated string")
+		}
+	}
+	return p.errorf("unterminated string")
+}
+
+// skipDirectives skips any @name(arguments) directives
+func (p *parser) skipDirectives() error {
+	for p.skipIgnored(); p.peek() == '@'; p.skipIgnored() {
+		p.pos++
+		if p.name() == "" {
+			return p.errorf("expected a directive name")
+		}
+		p.skipIgnored()
+		if p.peek() == '(' {
+			if err := p.skipGroup(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selectionSet parses a {...} selection set
+func (p *parser) selectionSet() ([]selectionNode, error) {
+	p.pos++ // {
+	set := []selectionNode{}
+	for p.skipIgnored(); ; p.skipIgnored() {
+		if p.done() {
+			return nil, p.errorf("unclosed selection set")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return set, nil
+		}
+
+		if strings.HasPrefix(p.src[p.pos:], "...") {
+			p.pos += 3
+			p.skipIgnored()
+			name := p.name()
+			if name != "" && name != "on" {
+				if err := p.skipDirectives(); err != nil {
+					return nil, err
+				}
+				set = append(set, selectionNode{spread: name})
+				continue
+			}
+			// Inline fragment, with an optional type condition
+			if name == "on" {
+				p.skipIgnored()
+				if p.name() == "" {
+					return nil, p.errorf("expected a type condition")
+				}
+			}
+			if err := p.skipDirectives(); err != nil {
+				return nil, err
+			}
+			if p.peek() != '{' {
+				return nil, p.errorf("expected a selection set")
+			}
+			children, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, selectionNode{inline: true, children: children})
+			continue
+		}
+
+		key := p.name()
+		if key == "" {
+			return nil, p.errorf("expected a field")
+		}
+		p.skipIgnored()
+		if p.peek() == ':' {
+			p.pos++
+			p.skipIgnored()
+			if p.name() == "" {
+				return nil, p.errorf("expected a field name after alias %q", key)
+			}
+			p.skipIgnored()
+		}
+		if p.peek() == '(' {
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		node := selectionNode{key: key}
+		if p.peek() == '{' {
+			children, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			node.children = children
+		}
+		set = append(set, node)
+	}
+}
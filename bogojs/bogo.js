@@ -0,0 +1,33 @@
+// Loader for the bogo WebAssembly module built from cmd/bogowasm.
+//
+// Load wasm_exec.js from $(go env GOROOT)/lib/wasm first, then:
+//
+//   const bogo = await loadBogo(fetch("bogo.wasm"));
+//   const value = bogo.decode(new Uint8Array(await response.arrayBuffer()));
+//   const bytes = bogo.encode({ name: "Ada", tags: ["admin"] });
+//
+// In Node.js, pass the module bytes instead of a fetch promise.
+
+export async function loadBogo(source) {
+  const go = new globalThis.Go();
+  const { instance } =
+    source instanceof Promise || source instanceof Response
+      ? await WebAssembly.instantiateStreaming(source, go.importObject)
+      : await WebAssembly.instantiate(source, go.importObject);
+
+  go.run(instance); // registers globalThis.bogo and keeps running
+
+  const bindings = globalThis.bogo;
+  const call = (fn, arg) => {
+    const [result, error] = fn(arg);
+    if (error !== null) {
+      throw new Error(error);
+    }
+    return result;
+  };
+
+  return {
+    encode: (value) => call(bindings.encode, value),
+    decode: (bytes) => call(bindings.decode, bytes),
+  };
+}
@@ -0,0 +1,295 @@
+//go:build js && wasm
+
+package bogojs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/bubunyo/bogo"
+)
+
+// maxSafeInteger is Number.MAX_SAFE_INTEGER, the largest integer a JavaScript
+// number holds exactly
+const maxSafeInteger = 1<<53 - 1
+
+var bindingErr = errors.New("bogojs error")
+
+// ToJS converts a value returned by bogo.Decode to a JavaScript value
+func ToJS(v any) (js.Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return js.Null(), nil
+	case bool, float64, string:
+		return js.ValueOf(val), nil
+	case int64:
+		if val > maxSafeInteger || val < -maxSafeInteger {
+			return js.Global().Call("BigInt", strconv.FormatInt(val, 10)), nil
+		}
+		return js.ValueOf(float64(val)), nil
+	case uint64:
+		if val > maxSafeInteger {
+			return js.Global().Call("BigInt", strconv.FormatUint(val, 10)), nil
+		}
+		return js.ValueOf(float64(val)), nil
+	case byte:
+		return js.ValueOf(int(val)), nil
+	case int8, int16, int32, uint16, uint32, float32:
+		// Numbers decoded with their width; all fit a JavaScript number
+		return js.ValueOf(val), nil
+	case int:
+		return ToJS(int64(val))
+	case uint:
+		return ToJS(uint64(val))
+	case []byte:
+		array := js.Global().Get("Uint8Array").New(len(val))
+		js.CopyBytesToJS(array, val)
+		return array, nil
+	case time.Time:
+		return js.Global().Get("Date").New(float64(val.UnixMilli())), nil
+	case bogo.Date:
+		return js.ValueOf(val.String()), nil
+	case bogo.TimeOfDay:
+		return js.ValueOf(val.String()), nil
+	case time.Duration:
+		return ToJS(int64(val)) // nanoseconds
+	case *big.Int:
+		return js.Global().Call("BigInt", val.String()), nil
+	case *big.Float:
+		f, _ := val.Float64()
+		return js.ValueOf(f), nil
+	case complex128:
+		obj := js.Global().Get("Object").New()
+		obj.Set("re", real(val))
+		obj.Set("im", imag(val))
+		return obj, nil
+	case bogo.Expiring:
+		// Readers in the browser see the value; pruning is left to servers
+		return ToJS(val.Value)
+	case bogo.Decimal:
+		// Kept exact as text; JavaScript numbers would round
+		return js.ValueOf(val.String()), nil
+	case bogo.Union:
+		value, err := ToJS(val.Value)
+		if err != nil {
+			return js.Value{}, err
+		}
+		obj := js.Global().Get("Object").New()
+		obj.Set("type", val.Name)
+		obj.Set("value", value)
+		return obj, nil
+	case bogo.Ext:
+		data, err := ToJS(val.Data)
+		if err != nil {
+			return js.Value{}, err
+		}
+		obj := js.Global().Get("Object").New()
+		obj.Set("ext", int(val.ID))
+		obj.Set("data", data)
+		return obj, nil
+	case bogo.Range[any]:
+		start, err := ToJS(val.Start)
+		if err != nil {
+			return js.Value{}, err
+		}
+		end, err := ToJS(val.End)
+		if err != nil {
+			return js.Value{}, err
+		}
+		obj := js.Global().Get("Object").New()
+		obj.Set("start", start)
+		obj.Set("end", end)
+		obj.Set("startInclusive", val.StartInclusive)
+		obj.Set("endInclusive", val.EndInclusive)
+		return obj, nil
+	case []any:
+		return toJSArray(val)
+	case []string:
+		return toJSArray(val)
+	case []int64:
+		return toJSArray(val)
+	case []uint64:
+		return toJSArray(val)
+	case []float64:
+		return toJSArray(val)
+	case []bool:
+		return toJSArray(val)
+	case [][]string:
+		return toJSArray(val)
+	case [][]int64:
+		return toJSArray(val)
+	case [][]uint64:
+		return toJSArray(val)
+	case [][]float64:
+		return toJSArray(val)
+	case [][]bool:
+		return toJSArray(val)
+	case map[string]any:
+		obj := js.Global().Get("Object").New()
+		for k, elem := range val {
+			jv, err := ToJS(elem)
+			if err != nil {
+				return js.Value{}, err
+			}
+			obj.Set(k, jv)
+		}
+		return obj, nil
+	case map[int64]any:
+		return toJSMap(val)
+	case map[uint64]any:
+		return toJSMap(val)
+	}
+	return js.Value{}, fmt.Errorf("%w: cannot convert %T to JavaScript", bindingErr, v)
+}
+
+func toJSArray[T any](values []T) (js.Value, error) {
+	array := js.Global().Get("Array").New(len(values))
+	for i, elem := range values {
+		jv, err := ToJS(elem)
+		if err != nil {
+			return js.Value{}, err
+		}
+		array.SetIndex(i, jv)
+	}
+	return array, nil
+}
+
+// toJSMap converts a map with integer keys to a JavaScript Map keyed by numbers
+func toJSMap[K int64 | uint64](values map[K]any) (js.Value, error) {
+	m := js.Global().Get("Map").New()
+	for k, elem := range values {
+		key, err := ToJS(k)
+		if err != nil {
+			return js.Value{}, err
+		}
+		jv, err := ToJS(elem)
+		if err != nil {
+			return js.Value{}, err
+		}
+		m.Call("set", key, jv)
+	}
+	return m, nil
+}
+
+// FromJS converts a JavaScript value to a value bogo.Encode accepts
+func FromJS(v js.Value) (any, error) {
+	// Value.Type panics on bigint, so values are told apart by their tag
+	switch tag := typeTag(v); tag {
+	case "Null", "Undefined":
+		return nil, nil
+	case "Boolean":
+		return v.Bool(), nil
+	case "String":
+		return v.String(), nil
+	case "Number":
+		f := v.Float()
+		if f == math.Trunc(f) && math.Abs(f) <= maxSafeInteger {
+			return int64(f), nil
+		}
+		return f, nil
+	case "BigInt":
+		s := js.Global().Get("String").Invoke(v).String()
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return u, nil
+		}
+		return nil, fmt.Errorf("%w: bigint %s overflows 64 bits", bindingErr, s)
+	case "Uint8Array":
+		b := make([]byte, v.Length())
+		js.CopyBytesToGo(b, v)
+		return b, nil
+	case "Date":
+		return time.UnixMilli(int64(v.Call("getTime").Float())).UTC(), nil
+	case "Array":
+		list := make([]any, v.Length())
+		for i := range list {
+			elem, err := FromJS(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+	case "Object":
+		keys := js.Global().Get("Object").Call("keys", v)
+		obj := make(map[string]any, keys.Length())
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			elem, err := FromJS(v.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = elem
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot convert %s to Go", bindingErr, tag)
+	}
+}
+
+// typeTag returns the tag Object.prototype.toString reports for v, e.g.
+// "Number" or "Uint8Array"
+func typeTag(v js.Value) string {
+	tag := js.Global().Get("Object").Get("prototype").Get("toString").Call("call", v).String()
+	return strings.TrimSuffix(strings.TrimPrefix(tag, "[object "), "]")
+}
+
+// Encode converts v to Go and encodes it, returning the document as a
+// Uint8Array
+func Encode(v js.Value) (js.Value, error) {
+	value, err := FromJS(v)
+	if err != nil {
+		return js.Value{}, err
+	}
+	data, err := bogo.Marshal(value)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return ToJS(data)
+}
+
+// Decode decodes the document held in the Uint8Array data and converts the
+// result to JavaScript
+func Decode(data js.Value) (js.Value, error) {
+	if typeTag(data) != "Uint8Array" {
+		return js.Value{}, fmt.Errorf("%w: decode expects a Uint8Array", bindingErr)
+	}
+	b := make([]byte, data.Length())
+	js.CopyBytesToGo(b, data)
+
+	value, err := bogo.Decode(b)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return ToJS(value)
+}
+
+// Register sets encode and decode functions on target. Go functions cannot
+// throw, so both return a [result, error] pair; bogo.js turns the error
+// message into a thrown Error.
+func Register(target js.Value) {
+	target.Set("encode", binding(Encode))
+	target.Set("decode", binding(Decode))
+}
+
+func binding(fn func(js.Value) (js.Value, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		arg := js.Undefined()
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		result, err := fn(arg)
+		if err != nil {
+			return []any{nil, err.Error()}
+		}
+		return []any{result, nil}
+	})
+}
@@ -0,0 +1,128 @@
+//go:build js && wasm
+
+package bogojs
+
+import (
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	joined := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data, err := bogo.Marshal(map[string]any{
+		"name":   "Ada",
+		"age":    36,
+		"score":  9.5,
+		"admin":  true,
+		"avatar": []byte{1, 2, 3},
+		"joined": joined,
+		"tags":   []string{"a", "b"},
+		"big":    uint64(1 << 60),
+		"none":   nil,
+		"nested": map[string]any{"list": []any{int64(1), "x"}},
+	})
+	require.NoError(t, err)
+
+	array, err := ToJS(data)
+	require.NoError(t, err)
+
+	value, err := Decode(array)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", value.Get("name").String())
+	assert.Equal(t, 36, value.Get("age").Int())
+	assert.Equal(t, 9.5, value.Get("score").Float())
+	assert.True(t, value.Get("admin").Bool())
+	assert.True(t, value.Get("avatar").InstanceOf(js.Global().Get("Uint8Array")))
+	assert.Equal(t, float64(joined.UnixMilli()), value.Get("joined").Float()) // nested timestamps decode as Unix milliseconds
+	assert.Equal(t, "b", value.Get("tags").Index(1).String())
+	assert.Equal(t, "BigInt", typeTag(value.Get("big")))
+	assert.Equal(t, "1152921504606846976", js.Global().Get("String").Invoke(value.Get("big")).String())
+	assert.True(t, value.Get("none").IsNull())
+	assert.Equal(t, "x", value.Get("nested").Get("list").Index(1).String())
+
+	encoded, err := Encode(value)
+	require.NoError(t, err)
+	b := make([]byte, encoded.Length())
+	js.CopyBytesToGo(b, encoded)
+
+	decoded, err := bogo.Decode(b)
+	require.NoError(t, err)
+	obj := decoded.(map[string]any)
+	assert.Equal(t, "Ada", obj["name"])
+	assert.EqualValues(t, 36, obj["age"])
+	assert.Equal(t, 9.5, obj["score"])
+	assert.Equal(t, []byte{1, 2, 3}, obj["avatar"])
+	assert.EqualValues(t, joined.UnixMilli(), obj["joined"])
+	assert.EqualValues(t, uint64(1<<60), obj["big"])
+	assert.Nil(t, obj["none"])
+}
+
+func TestTimeValues(t *testing.T) {
+	joined := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data, err := bogo.Marshal(joined)
+	require.NoError(t, err)
+
+	array, err := ToJS(data)
+	require.NoError(t, err)
+	value, err := Decode(array)
+	require.NoError(t, err)
+	assert.True(t, value.InstanceOf(js.Global().Get("Date")))
+	assert.Equal(t, float64(joined.UnixMilli()), value.Call("getTime").Float())
+
+	back, err := FromJS(value)
+	require.NoError(t, err)
+	assert.Equal(t, joined, back)
+}
+
+func TestCivilAndRangeValues(t *testing.T) {
+	v, err := ToJS(bogo.Date{Year: 2024, Month: time.May, Day: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01", v.String())
+
+	v, err = ToJS(bogo.Range[any]{Start: int64(1), End: int64(5), StartInclusive: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Get("start").Int())
+	assert.Equal(t, 5, v.Get("end").Int())
+	assert.True(t, v.Get("startInclusive").Bool())
+	assert.False(t, v.Get("endInclusive").Bool())
+}
+
+func TestFromJSNumbers(t *testing.T) {
+	v, err := FromJS(js.ValueOf(42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), v)
+
+	v, err = FromJS(js.ValueOf(1.5))
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, v)
+
+	v, err = FromJS(js.ValueOf(1e300))
+	require.NoError(t, err)
+	assert.Equal(t, 1e300, v)
+
+	v, err = FromJS(js.Global().Call("BigInt", "-9007199254740993"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(-9007199254740993), v)
+
+	_, err = FromJS(js.Global().Call("BigInt", "100000000000000000000"))
+	assert.ErrorContains(t, err, "overflows 64 bits")
+}
+
+func TestBindingErrors(t *testing.T) {
+	_, err := Decode(js.ValueOf("not bytes"))
+	assert.ErrorContains(t, err, "decode expects a Uint8Array")
+
+	_, err = FromJS(js.Global().Get("Symbol").Invoke("s"))
+	assert.ErrorContains(t, err, "cannot convert")
+
+	target := js.Global().Get("Object").New()
+	Register(target)
+	pair := target.Call("decode", js.ValueOf(1))
+	assert.True(t, pair.Index(0).IsNull())
+	assert.Contains(t, pair.Index(1).String(), "Uint8Array")
+}
@@ -0,0 +1,25 @@
+// Package bogojs exposes the bogo codec to JavaScript when compiled to
+// WebAssembly with GOOS=js GOARCH=wasm, so browsers can decode server payloads
+// with the same Go implementation that produced them.
+//
+// The bindings only use the dynamic value paths of bogo, Encode on maps, lists
+// and scalars and Decode into any, and never reflect over struct types. Values
+// are converted between the two languages as follows:
+//
+//	Go                          JavaScript
+//	nil                         null (undefined encodes as null)
+//	bool                        boolean
+//	int64, uint64               number, or bigint beyond ±2^53
+//	float64                     number
+//	string                      string
+//	[]byte                      Uint8Array
+//	time.Time                   Date
+//	bogo.Date, bogo.TimeOfDay   string, e.g. "2024-05-01", "13:45:00"
+//	bogo.Range[any]             {start, end, startInclusive, endInclusive}
+//	[]any and typed lists       Array
+//	map[string]any              Object
+//
+// JavaScript numbers encode as integers when they are safe integers and as
+// floats otherwise. cmd/bogowasm builds a module that registers the bindings
+// and bogo.js loads it in a page or in Node.js.
+package bogojs
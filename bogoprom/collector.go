@@ -0,0 +1,119 @@
+// Package bogoprom exports bogo encoder and decoder statistics as Prometheus
+// metrics.
+//
+//	collector := bogo.NewStatsCollector()
+//	prometheus.MustRegister(bogoprom.NewEncoderCollector(collector, "myapp"))
+//
+// Metrics are read from StatsCollector.GetStats and
+// DecoderStatsCollector.GetStats on every scrape. Calling ResetStats makes the
+// counters start over, which Prometheus treats as a counter reset.
+package bogoprom
+
+import (
+	"github.com/bubunyo/bogo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EncoderCollector is a prometheus.Collector for a bogo.StatsCollector
+type EncoderCollector struct {
+	stats *bogo.StatsCollector
+
+	bytes    *prometheus.Desc
+	errors   *prometheus.Desc
+	maxDepth *prometheus.Desc
+	values   *prometheus.Desc
+}
+
+// NewEncoderCollector creates a collector exporting the statistics of stats
+// with metric names prefixed by namespace
+func NewEncoderCollector(stats *bogo.StatsCollector, namespace string) *EncoderCollector {
+	return &EncoderCollector{
+		stats: stats,
+		bytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_encoder", "bytes_total"),
+			"Total bytes produced by successful encodes.", nil, nil),
+		errors: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_encoder", "errors_total"),
+			"Total failed encodes.", nil, nil),
+		maxDepth: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_encoder", "max_depth"),
+			"Deepest nesting seen while encoding.", nil, nil),
+		values: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_encoder", "values_total"),
+			"Total encoded values by top-level wire type.", []string{"type"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *EncoderCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytes
+	ch <- c.errors
+	ch <- c.maxDepth
+	ch <- c.values
+}
+
+// Collect implements prometheus.Collector
+func (c *EncoderCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats.GetStats()
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(stats.BytesEncoded))
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(stats.ErrorsCount))
+	ch <- prometheus.MustNewConstMetric(c.maxDepth, prometheus.GaugeValue, float64(stats.MaxDepthUsed))
+	for name, n := range typeCounts(stats.TypesEncoded) {
+		ch <- prometheus.MustNewConstMetric(c.values, prometheus.CounterValue, float64(n), name)
+	}
+}
+
+// DecoderCollector is a prometheus.Collector for a bogo.DecoderStatsCollector
+type DecoderCollector struct {
+	stats *bogo.DecoderStatsCollector
+
+	bytes        *prometheus.Desc
+	errors       *prometheus.Desc
+	unknownTypes *prometheus.Desc
+	maxDepth     *prometheus.Desc
+	values       *prometheus.Desc
+}
+
+// NewDecoderCollector creates a collector exporting the statistics of stats
+// with metric names prefixed by namespace
+func NewDecoderCollector(stats *bogo.DecoderStatsCollector, namespace string) *DecoderCollector {
+	return &DecoderCollector{
+		stats: stats,
+		bytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_decoder", "bytes_total"),
+			"Total bytes consumed by successful decodes.", nil, nil),
+		errors: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_decoder", "errors_total"),
+			"Total failed decodes.", nil, nil),
+		unknownTypes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_decoder", "unknown_types_total"),
+			"Total decodes that produced an UnknownType.", nil, nil),
+		maxDepth: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_decoder", "max_depth"),
+			"Deepest nesting seen while decoding.", nil, nil),
+		values: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bogo_decoder", "values_total"),
+			"Total decoded values by top-level wire type.", []string{"type"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *DecoderCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytes
+	ch <- c.errors
+	ch <- c.unknownTypes
+	ch <- c.maxDepth
+	ch <- c.values
+}
+
+// Collect implements prometheus.Collector
+func (c *DecoderCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats.GetStats()
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(stats.BytesDecoded))
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(stats.ErrorsCount))
+	ch <- prometheus.MustNewConstMetric(c.unknownTypes, prometheus.CounterValue, float64(stats.UnknownTypes))
+	ch <- prometheus.MustNewConstMetric(c.maxDepth, prometheus.GaugeValue, float64(stats.MaxDepthUsed))
+	for name, n := range typeCounts(stats.TypesDecoded) {
+		ch <- prometheus.MustNewConstMetric(c.values, prometheus.CounterValue, float64(n), name)
+	}
+}
+
+// typeCounts keys counts by bogo.TypeName, merging types that share a name
+func typeCounts(types map[bogo.Type]int) map[string]int {
+	counts := make(map[string]int, len(types))
+	for t, n := range types {
+		counts[bogo.TypeName(t)] += n
+	}
+	return counts
+}
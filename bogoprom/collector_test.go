@@ -0,0 +1,67 @@
+package bogoprom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderCollector(t *testing.T) {
+	stats := bogo.NewStatsCollector()
+	_, err := stats.Encode("hello")
+	require.NoError(t, err)
+	_, err = stats.Encode(int64(42))
+	require.NoError(t, err)
+	_, err = stats.Encode(int64(7))
+	require.NoError(t, err)
+
+	collector := NewEncoderCollector(stats, "test")
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	assert.Equal(t, 2, testutil.CollectAndCount(collector, "test_bogo_encoder_values_total"))
+	err = testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP test_bogo_encoder_values_total Total encoded values by top-level wire type.
+# TYPE test_bogo_encoder_values_total counter
+test_bogo_encoder_values_total{type="int"} 2
+test_bogo_encoder_values_total{type="string"} 1
+`), "test_bogo_encoder_values_total")
+	assert.NoError(t, err)
+
+	err = testutil.CollectAndCompare(collector, strings.NewReader(fmt.Sprintf(`
+# HELP test_bogo_encoder_bytes_total Total bytes produced by successful encodes.
+# TYPE test_bogo_encoder_bytes_total counter
+test_bogo_encoder_bytes_total %d
+`, stats.GetStats().BytesEncoded)), "test_bogo_encoder_bytes_total")
+	assert.NoError(t, err)
+}
+
+func TestDecoderCollector(t *testing.T) {
+	stats := bogo.NewDecoderStatsCollector()
+	data, err := bogo.Encode(map[string]any{"a": true})
+	require.NoError(t, err)
+	_, err = stats.Decode(data)
+	require.NoError(t, err)
+	_, err = stats.Decode([]byte{bogo.Version})
+	require.Error(t, err)
+
+	collector := NewDecoderCollector(stats, "")
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	err = testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP bogo_decoder_errors_total Total failed decodes.
+# TYPE bogo_decoder_errors_total counter
+bogo_decoder_errors_total 1
+# HELP bogo_decoder_values_total Total decoded values by top-level wire type.
+# TYPE bogo_decoder_values_total counter
+bogo_decoder_values_total{type="object"} 1
+`), "bogo_decoder_errors_total", "bogo_decoder_values_total")
+	assert.NoError(t, err)
+}
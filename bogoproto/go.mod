@@ -0,0 +1,20 @@
+module github.com/bubunyo/bogo/bogoproto
+
+go 1.23.2
+
+require (
+	github.com/bubunyo/bogo v0.0.0-20261016233946-a7f6d231cbc8
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/protobuf v1.32.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Builds inside this repository use the root module next to it; consumers,
+// for whom replace directives have no effect, get the version required above.
+replace github.com/bubunyo/bogo => ../
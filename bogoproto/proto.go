@@ -0,0 +1,389 @@
+// Package bogoproto encodes protocol buffer messages as bogo objects using
+// their descriptors, so services that own protobufs can emit bogo, for
+// example into internal caches, without duplicate struct definitions.
+//
+//	data, err := bogoproto.Marshal(order)
+//	...
+//	var cached orderpb.Order
+//	err = bogoproto.Unmarshal(data, &cached)
+//
+// Fields are keyed by their proto names, or by their numbers with
+// WithFieldNumbers. Only populated fields are written, as in the proto wire
+// format. Signed integers are encoded as ints, unsigned integers as uints,
+// enums as their numbers, bytes as blobs, and map keys as strings. Well-known
+// types such as google.protobuf.Timestamp are encoded as ordinary messages.
+package bogoproto
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/bubunyo/bogo"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var protoErr = errors.New("bogoproto error")
+
+// Option configures Marshal and Unmarshal
+type Option func(*options)
+
+type options struct {
+	fieldNumbers   bool
+	discardUnknown bool
+	encoder        *bogo.Encoder
+	decoder        *bogo.Decoder
+}
+
+// WithFieldNumbers keys fields by their numbers, e.g. "3", instead of their
+// names, so encoded messages survive field renames
+func WithFieldNumbers() Option {
+	return func(o *options) { o.fieldNumbers = true }
+}
+
+// WithDiscardUnknown makes Unmarshal ignore keys that name no field of the
+// message instead of failing
+func WithDiscardUnknown() Option {
+	return func(o *options) { o.discardUnknown = true }
+}
+
+// WithEncoder makes Marshal encode with encoder instead of bogo's defaults
+func WithEncoder(encoder *bogo.Encoder) Option {
+	return func(o *options) { o.encoder = encoder }
+}
+
+// WithDecoder makes Unmarshal decode with decoder instead of bogo's defaults
+func WithDecoder(decoder *bogo.Decoder) Option {
+	return func(o *options) { o.decoder = decoder }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Marshal encodes m as a bogo object
+func Marshal(m proto.Message, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	obj := o.messageObject(m.ProtoReflect())
+	if o.encoder != nil {
+		return o.encoder.Encode(obj)
+	}
+	return bogo.Marshal(obj)
+}
+
+// Unmarshal decodes a bogo object into m, which is reset first
+func Unmarshal(data []byte, m proto.Message, opts ...Option) error {
+	o := newOptions(opts)
+	var decoded any
+	var err error
+	if o.decoder != nil {
+		decoded, err = o.decoder.Decode(data)
+	} else {
+		decoded, err = bogo.Decode(data)
+	}
+	if err != nil {
+		return err
+	}
+	obj, ok := decoded.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: expected an object, got %T", protoErr, decoded)
+	}
+	proto.Reset(m)
+	return o.fillMessage(m.ProtoReflect(), obj, "")
+}
+
+// key returns the object key of field fd
+func (o *options) key(fd protoreflect.FieldDescriptor) string {
+	if o.fieldNumbers {
+		return strconv.Itoa(int(fd.Number()))
+	}
+	return string(fd.Name())
+}
+
+// field returns the field of md named by key
+func (o *options) field(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if !o.fieldNumbers {
+		return md.Fields().ByName(protoreflect.Name(key))
+	}
+	n, err := strconv.ParseInt(key, 10, 32)
+	if err != nil {
+		return nil
+	}
+	return md.Fields().ByNumber(protoreflect.FieldNumber(n))
+}
+
+func (o *options) messageObject(m protoreflect.Message) map[string]any {
+	obj := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			items := make([]any, list.Len())
+			for i := range items {
+				items[i] = o.scalarValue(fd, list.Get(i))
+			}
+			obj[o.key(fd)] = items
+		case fd.IsMap():
+			entries := make(map[string]any, v.Map().Len())
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				entries[k.String()] = o.scalarValue(fd.MapValue(), v)
+				return true
+			})
+			obj[o.key(fd)] = entries
+		default:
+			obj[o.key(fd)] = o.scalarValue(fd, v)
+		}
+		return true
+	})
+	return obj
+}
+
+// scalarValue converts a single value of field fd, which may be a message
+func (o *options) scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.EnumKind:
+		return int64(v.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BytesKind:
+		return v.Bytes()
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return o.messageObject(v.Message())
+	}
+	return nil
+}
+
+func (o *options) fillMessage(m protoreflect.Message, obj map[string]any, path string) error {
+	md := m.Descriptor()
+	for key, value := range obj {
+		fieldPath := joinPath(path, key)
+		fd := o.field(md, key)
+		if fd == nil {
+			if o.discardUnknown {
+				continue
+			}
+			return fmt.Errorf("%w: %s: no such field in %s", protoErr, fieldPath, md.FullName())
+		}
+		if value == nil {
+			continue
+		}
+		if err := o.fillField(m, fd, value, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *options) fillField(m protoreflect.Message, fd protoreflect.FieldDescriptor, value any, path string) error {
+	switch {
+	case fd.IsList():
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			return fmt.Errorf("%w: %s: expected a list, got %T", protoErr, path, value)
+		}
+		list := m.Mutable(fd).List()
+		for i := range items.Len() {
+			var elem protoreflect.Value
+			var err error
+			if fd.Message() != nil {
+				elem = list.NewElement()
+				err = o.fillNested(elem.Message(), items.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i))
+			} else {
+				elem, err = scalar(fd, items.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i))
+			}
+			if err != nil {
+				return err
+			}
+			list.Append(elem)
+		}
+		return nil
+
+	case fd.IsMap():
+		entries, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: %s: expected an object, got %T", protoErr, path, value)
+		}
+		mp := m.Mutable(fd).Map()
+		for k, v := range entries {
+			entryPath := joinPath(path, k)
+			key, err := mapKey(fd.MapKey(), k, entryPath)
+			if err != nil {
+				return err
+			}
+			var elem protoreflect.Value
+			if fd.MapValue().Message() != nil {
+				elem = mp.NewValue()
+				err = o.fillNested(elem.Message(), v, entryPath)
+			} else {
+				elem, err = scalar(fd.MapValue(), v, entryPath)
+			}
+			if err != nil {
+				return err
+			}
+			mp.Set(key, elem)
+		}
+		return nil
+
+	case fd.Message() != nil:
+		return o.fillNested(m.Mutable(fd).Message(), value, path)
+	}
+
+	v, err := scalar(fd, value, path)
+	if err != nil {
+		return err
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+func (o *options) fillNested(m protoreflect.Message, value any, path string) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: %s: expected an object, got %T", protoErr, path, value)
+	}
+	return o.fillMessage(m, obj, path)
+}
+
+// scalar converts a decoded value to a value of non-message field fd
+func scalar(fd protoreflect.FieldDescriptor, value any, path string) (protoreflect.Value, error) {
+	mismatch := func() (protoreflect.Value, error) {
+		return protoreflect.Value{}, fmt.Errorf("%w: %s: cannot assign %T to %s field", protoErr, path, value, fd.Kind())
+	}
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if b, ok := value.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case protoreflect.EnumKind:
+		if name, ok := value.(string); ok {
+			ev := fd.Enum().Values().ByName(protoreflect.Name(name))
+			if ev == nil {
+				return protoreflect.Value{}, fmt.Errorf("%w: %s: unknown %s value %q", protoErr, path, fd.Enum().FullName(), name)
+			}
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		if n, ok := toInt(value, math.MinInt32, math.MaxInt32); ok {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n, ok := toInt(value, math.MinInt32, math.MaxInt32); ok {
+			return protoreflect.ValueOfInt32(int32(n)), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if n, ok := toInt(value, math.MinInt64, math.MaxInt64); ok {
+			return protoreflect.ValueOfInt64(n), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if n, ok := toUint(value, math.MaxUint32); ok {
+			return protoreflect.ValueOfUint32(uint32(n)), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if n, ok := toUint(value, math.MaxUint64); ok {
+			return protoreflect.ValueOfUint64(n), nil
+		}
+	case protoreflect.FloatKind:
+		if f, ok := value.(float64); ok {
+			return protoreflect.ValueOfFloat32(float32(f)), nil
+		}
+	case protoreflect.DoubleKind:
+		if f, ok := value.(float64); ok {
+			return protoreflect.ValueOfFloat64(f), nil
+		}
+	case protoreflect.StringKind:
+		if s, ok := value.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+	case protoreflect.BytesKind:
+		if b, ok := value.([]byte); ok {
+			return protoreflect.ValueOfBytes(b), nil
+		}
+	}
+	return mismatch()
+}
+
+// mapKey parses an object key as a key of a map field
+func mapKey(fd protoreflect.FieldDescriptor, key string, path string) (protoreflect.MapKey, error) {
+	var v protoreflect.Value
+	var err error
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(key)
+	case protoreflect.BoolKind:
+		var b bool
+		b, err = strconv.ParseBool(key)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, err = strconv.ParseInt(key, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var n int64
+		n, err = strconv.ParseInt(key, 10, 64)
+		v = protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var n uint64
+		n, err = strconv.ParseUint(key, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var n uint64
+		n, err = strconv.ParseUint(key, 10, 64)
+		v = protoreflect.ValueOfUint64(n)
+	default:
+		err = fmt.Errorf("unsupported key kind %s", fd.Kind())
+	}
+	if err != nil {
+		return protoreflect.MapKey{}, fmt.Errorf("%w: %s: invalid map key: %w", protoErr, path, err)
+	}
+	return v.MapKey(), nil
+}
+
+// toInt returns a decoded integer when it lies within [min, max]
+func toInt(value any, min, max int64) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, v >= min && v <= max
+	case uint64:
+		return int64(v), v <= uint64(max)
+	case byte:
+		return int64(v), int64(v) <= max
+	}
+	return 0, false
+}
+
+// toUint returns a decoded integer when it lies within [0, max]
+func toUint(value any, max uint64) (uint64, bool) {
+	switch v := value.(type) {
+	case uint64:
+		return v, v <= max
+	case int64:
+		return uint64(v), v >= 0 && uint64(v) <= max
+	case byte:
+		return uint64(v), true
+	}
+	return 0, false
+}
+
+// joinPath appends key to a dotted field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,232 @@
+package bogoproto
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// sampleDescriptor describes
+//
+//	enum Status { UNKNOWN = 0; ACTIVE = 1; }
+//	message Sample {
+//	  int32 small = 1; sint64 big = 2; uint32 count = 3; fixed64 id = 4;
+//	  float ratio = 5; double score = 6; bool ok = 7; string name = 8;
+//	  bytes raw = 9; Status status = 10; repeated int64 values = 11;
+//	  repeated Sample children = 12; map<int32, string> labels = 13;
+//	  map<string, Sample> index = 14;
+//	}
+func sampleDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	entry := func(name string, key descriptorpb.FieldDescriptorProto_Type, value descriptorpb.FieldDescriptorProto_Type, valueType string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("key", 1, key, optional, ""),
+				field("value", 2, value, optional, valueType),
+			},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("sample.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("ACTIVE"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Sample"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("small", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, ""),
+				field("big", 2, descriptorpb.FieldDescriptorProto_TYPE_SINT64, optional, ""),
+				field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT32, optional, ""),
+				field("id", 4, descriptorpb.FieldDescriptorProto_TYPE_FIXED64, optional, ""),
+				field("ratio", 5, descriptorpb.FieldDescriptorProto_TYPE_FLOAT, optional, ""),
+				field("score", 6, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional, ""),
+				field("ok", 7, descriptorpb.FieldDescriptorProto_TYPE_BOOL, optional, ""),
+				field("name", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+				field("raw", 9, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, ""),
+				field("status", 10, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional, ".test.Status"),
+				field("values", 11, descriptorpb.FieldDescriptorProto_TYPE_INT64, repeated, ""),
+				field("children", 12, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".test.Sample"),
+				field("labels", 13, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".test.Sample.LabelsEntry"),
+				field("index", 14, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".test.Sample.IndexEntry"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{
+				entry("LabelsEntry", descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				entry("IndexEntry", descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Sample"),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+	return fd.Messages().ByName("Sample")
+}
+
+func newSample(t *testing.T, md protoreflect.MessageDescriptor) *dynamicpb.Message {
+	t.Helper()
+	child := dynamicpb.NewMessage(md)
+	child.Set(md.Fields().ByName("name"), protoreflect.ValueOfString("child"))
+
+	m := dynamicpb.NewMessage(md)
+	fields := md.Fields()
+	m.Set(fields.ByName("small"), protoreflect.ValueOfInt32(math.MinInt32))
+	m.Set(fields.ByName("big"), protoreflect.ValueOfInt64(math.MaxInt64))
+	m.Set(fields.ByName("count"), protoreflect.ValueOfUint32(math.MaxUint32))
+	m.Set(fields.ByName("id"), protoreflect.ValueOfUint64(math.MaxUint64))
+	m.Set(fields.ByName("ratio"), protoreflect.ValueOfFloat32(0.5))
+	m.Set(fields.ByName("score"), protoreflect.ValueOfFloat64(-1.25))
+	m.Set(fields.ByName("ok"), protoreflect.ValueOfBool(true))
+	m.Set(fields.ByName("name"), protoreflect.ValueOfString("parent"))
+	m.Set(fields.ByName("raw"), protoreflect.ValueOfBytes([]byte{0, 1, 2}))
+	m.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+	values := m.Mutable(fields.ByName("values")).List()
+	values.Append(protoreflect.ValueOfInt64(-7))
+	values.Append(protoreflect.ValueOfInt64(7))
+	m.Mutable(fields.ByName("children")).List().Append(protoreflect.ValueOfMessage(child))
+	labels := m.Mutable(fields.ByName("labels")).Map()
+	labels.Set(protoreflect.ValueOfInt32(-1).MapKey(), protoreflect.ValueOfString("minus one"))
+	index := m.Mutable(fields.ByName("index")).Map()
+	index.Set(protoreflect.ValueOfString("first").MapKey(), protoreflect.ValueOfMessage(child))
+	return m
+}
+
+func TestRoundTrip(t *testing.T) {
+	md := sampleDescriptor(t)
+	m := newSample(t, md)
+
+	for name, opts := range map[string][]Option{
+		"names":   nil,
+		"numbers": {WithFieldNumbers()},
+		"compact": {WithEncoder(bogo.NewConfigurableEncoder(bogo.WithCompactLists(true), bogo.WithCompactIntegers(true)))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := Marshal(m, opts...)
+			require.NoError(t, err)
+
+			decoded := dynamicpb.NewMessage(md)
+			require.NoError(t, Unmarshal(data, decoded, opts...))
+			assert.True(t, proto.Equal(m, decoded), "got %v", decoded)
+		})
+	}
+}
+
+func TestMarshalShape(t *testing.T) {
+	md := sampleDescriptor(t)
+	data, err := Marshal(newSample(t, md))
+	require.NoError(t, err)
+
+	decoded, err := bogo.Decode(data)
+	require.NoError(t, err)
+	obj := decoded.(map[string]any)
+	assert.Equal(t, int64(math.MinInt32), obj["small"])
+	assert.Equal(t, uint64(math.MaxUint64), obj["id"])
+	assert.Equal(t, int64(1), obj["status"])
+	assert.Equal(t, map[string]any{"-1": "minus one"}, obj["labels"])
+	assert.Equal(t, []any{map[string]any{"name": "child"}}, obj["children"])
+
+	data, err = Marshal(newSample(t, md), WithFieldNumbers())
+	require.NoError(t, err)
+	decoded, err = bogo.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "parent", decoded.(map[string]any)["8"])
+}
+
+func TestUnmarshalConversions(t *testing.T) {
+	md := sampleDescriptor(t)
+	data, err := bogo.Marshal(map[string]any{
+		"count":  uint8(3),
+		"status": "ACTIVE",
+		"name":   nil,
+	})
+	require.NoError(t, err)
+
+	m := dynamicpb.NewMessage(md)
+	require.NoError(t, Unmarshal(data, m))
+	assert.Equal(t, uint64(3), m.Get(md.Fields().ByName("count")).Uint())
+	assert.Equal(t, protoreflect.EnumNumber(1), m.Get(md.Fields().ByName("status")).Enum())
+	assert.False(t, m.Has(md.Fields().ByName("name")))
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	md := sampleDescriptor(t)
+	tests := []struct {
+		name  string
+		value any
+		err   string
+	}{
+		{"unknown field", map[string]any{"nope": 1}, "nope: no such field in test.Sample"},
+		{"out of range", map[string]any{"small": int64(math.MaxInt32) + 1}, "small: cannot assign int64 to int32 field"},
+		{"negative unsigned", map[string]any{"count": -1}, "count: cannot assign int64 to uint32 field"},
+		{"unknown enum", map[string]any{"status": "GONE"}, `status: unknown test.Status value "GONE"`},
+		{"nested", map[string]any{"children": []any{map[string]any{"ok": "yes"}}}, "children[0].ok: cannot assign string to bool field"},
+		{"map key", map[string]any{"labels": map[string]any{"x": "y"}}, "labels.x: invalid map key"},
+		{"not an object", []any{1}, "expected an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bogo.Marshal(tt.value)
+			require.NoError(t, err)
+			err = Unmarshal(data, dynamicpb.NewMessage(md))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, protoErr)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestDiscardUnknown(t *testing.T) {
+	md := sampleDescriptor(t)
+	data, err := bogo.Marshal(map[string]any{"name": "kept", "added_later": true})
+	require.NoError(t, err)
+
+	m := dynamicpb.NewMessage(md)
+	require.NoError(t, Unmarshal(data, m, WithDiscardUnknown()))
+	assert.Equal(t, "kept", m.Get(md.Fields().ByName("name")).String())
+}
+
+func TestGeneratedMessages(t *testing.T) {
+	s, err := structpb.NewStruct(map[string]any{
+		"name":  "ada",
+		"tags":  []any{"a", 1.5, true, nil},
+		"inner": map[string]any{"n": 2.0},
+	})
+	require.NoError(t, err)
+
+	data, err := Marshal(s)
+	require.NoError(t, err)
+
+	var decoded structpb.Struct
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.True(t, proto.Equal(s, &decoded))
+}
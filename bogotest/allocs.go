@@ -0,0 +1,71 @@
+package bogotest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+)
+
+// allocRuns is how many times an operation runs to average its allocations
+const allocRuns = 100
+
+// MaxAllocs fails the test when encoding v with bogo.Marshal, or decoding the
+// result with bogo.Unmarshal, allocates more than max times on average. Pin
+// representative values of your types with it so that allocation regressions
+// fail CI. Limits are not checked under the race detector, which adds
+// allocations of its own.
+func MaxAllocs(t testing.TB, v any, max int) {
+	t.Helper()
+	MaxEncodeAllocs(t, v, max)
+	MaxDecodeAllocs(t, v, max)
+}
+
+// MaxEncodeAllocs fails the test when encoding v with bogo.Marshal allocates
+// more than max times on average
+func MaxEncodeAllocs(t testing.TB, v any, max int) {
+	t.Helper()
+	if _, err := bogo.Marshal(v); err != nil {
+		t.Fatalf("bogotest: encoding %T: %v", v, err)
+		return
+	}
+	checkAllocs(t, "encoding", v, max, func() {
+		_, _ = bogo.Marshal(v)
+	})
+}
+
+// MaxDecodeAllocs fails the test when decoding the encoding of v into a new
+// value of the same type allocates more than max times on average. The count
+// includes allocating the destination itself.
+func MaxDecodeAllocs(t testing.TB, v any, max int) {
+	t.Helper()
+	data, err := bogo.Marshal(v)
+	if err != nil {
+		t.Fatalf("bogotest: encoding %T: %v", v, err)
+		return
+	}
+	typ := reflect.TypeOf(v)
+	newTarget := func() any {
+		if typ == nil {
+			return new(any)
+		}
+		return reflect.New(typ).Interface()
+	}
+	if err := bogo.Unmarshal(data, newTarget()); err != nil {
+		t.Fatalf("bogotest: decoding %T: %v", v, err)
+		return
+	}
+	checkAllocs(t, "decoding", v, max, func() {
+		_ = bogo.Unmarshal(data, newTarget())
+	})
+}
+
+func checkAllocs(t testing.TB, op string, v any, max int, fn func()) {
+	t.Helper()
+	if raceEnabled {
+		return
+	}
+	if allocs := testing.AllocsPerRun(allocRuns, fn); allocs > float64(max) {
+		t.Errorf("bogotest: %s %T allocates %.0f times, want at most %d", op, v, allocs, max)
+	}
+}
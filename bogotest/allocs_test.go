@@ -0,0 +1,83 @@
+package bogotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The allocation counts quoted in the readme's benchmark results, enforced on
+// the payloads they were measured with
+func TestDocumentedAllocations(t *testing.T) {
+	simple := map[string]any{
+		"id":       int64(12345),
+		"username": "john_doe_2024",
+		"email":    "john.doe@example.com",
+		"active":   true,
+		"balance":  1234.56,
+		"created":  time.Unix(1703030400, 0),
+	}
+	complex := map[string]any{
+		"meta": map[string]any{
+			"version":    "v2.1.3",
+			"timestamp":  time.Unix(1703030400, 0),
+			"request_id": "req_abc123def456",
+		},
+		"user": map[string]any{
+			"id":          int64(987654321),
+			"username":    "alice_developer",
+			"email":       "alice@techcorp.com",
+			"is_verified": true,
+			"is_premium":  false,
+			"balance":     2847.92,
+			"profile_pic": []byte("fake_compressed_image_data_here_12345"),
+			"tags":        []string{"developer", "premium_trial", "early_adopter", "beta_tester"},
+			"login_times": []int64{1703001600, 1702915200, 1702828800, 1702742400, 1702656000},
+			"scores":      []float64{85.5, 90.2, 78.8, 92.1, 88.7, 95.3, 82.4},
+			"preferences": map[string]any{
+				"theme":           "dark",
+				"notifications":   true,
+				"language":        "en-US",
+				"timezone_offset": -5,
+				"beta_features":   true,
+			},
+		},
+		"analytics": map[string]any{
+			"page_views":    []int64{1520, 1687, 1734, 1823, 1756, 1892, 2103},
+			"click_events":  []string{"button_click", "link_click", "menu_open", "search_query", "file_download", "share_action", "profile_edit", "settings_change"},
+			"session_times": []float64{45.2, 67.8, 34.1, 89.5, 23.7, 56.9, 78.3},
+			"metrics":       map[string]float64{"engagement_rate": 78.5, "conversion_rate": 12.3, "retention_rate": 89.7, "satisfaction": 4.2, "nps_score": 67.0},
+			"feature_flags": map[string]bool{"new_ui": true, "beta_analytics": false, "ai_assistant": true, "advanced_export": false, "real_time_sync": true},
+		},
+	}
+
+	MaxEncodeAllocs(t, simple, 18)
+	MaxDecodeAllocs(t, simple, 16)
+	MaxEncodeAllocs(t, complex, 291)
+	MaxDecodeAllocs(t, complex, 101)
+}
+
+func TestMaxAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation limits are not checked under the race detector")
+	}
+
+	r := &recorder{TB: t, name: t.Name()}
+	MaxAllocs(r, "hello", 10)
+	assert.Empty(t, r.failed)
+
+	MaxEncodeAllocs(r, map[string]any{"a": "b"}, 0)
+	assert.Contains(t, r.failed, "encoding map[string]interface {} allocates")
+
+	r.failed = ""
+	MaxDecodeAllocs(r, map[string]any{"a": "b"}, 0)
+	assert.Contains(t, r.failed, "decoding map[string]interface {} allocates")
+
+	r.failed = ""
+	MaxAllocs(r, nil, 10)
+	assert.Empty(t, r.failed)
+
+	MaxAllocs(r, make(chan int), 10)
+	assert.Contains(t, r.failed, "encoding chan int")
+}
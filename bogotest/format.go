@@ -0,0 +1,113 @@
+// Package bogotest provides helpers for testing code that produces bogo
+// documents.
+//
+//	func TestOrderEncoding(t *testing.T) {
+//		bogotest.Snapshot(t, order)
+//	}
+//
+// Snapshot renders a value as stable text and compares it with a golden file
+// under testdata, so changes to what a value encodes to show up as reviewable
+// text diffs. Run the tests with -update-snapshots to write the golden files.
+package bogotest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bubunyo/bogo"
+)
+
+// Format decodes a bogo document and renders it as indented text. The output
+// depends only on the values in the document: object keys are sorted, and
+// numbers and typed lists are labelled with the Go type they decode to.
+//
+//	# 37 bytes
+//	{
+//		"age": int64(36)
+//		"name": "Ada"
+//		"tags": []string [
+//			"admin"
+//		]
+//	}
+func Format(data []byte) (string, error) {
+	v, err := bogo.Decode(data)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %d bytes\n", len(data))
+	formatValue(&b, v, 0)
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+func formatValue(b *strings.Builder, v any, depth int) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case []byte:
+		b.WriteString("blob " + hex.EncodeToString(v))
+	case map[string]any:
+		formatObject(b, v, depth)
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			fmt.Fprintf(b, "%T(%v)", v, v)
+			return
+		}
+		if _, untyped := v.([]any); !untyped {
+			fmt.Fprintf(b, "%T ", v)
+		}
+		formatList(b, rv, depth)
+	}
+}
+
+func formatObject(b *strings.Builder, m map[string]any, depth int) {
+	if len(m) == 0 {
+		b.WriteString("{}")
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("{\n")
+	for _, key := range keys {
+		indent(b, depth+1)
+		b.WriteString(strconv.Quote(key) + ": ")
+		formatValue(b, m[key], depth+1)
+		b.WriteByte('\n')
+	}
+	indent(b, depth)
+	b.WriteByte('}')
+}
+
+func formatList(b *strings.Builder, list reflect.Value, depth int) {
+	if list.Len() == 0 {
+		b.WriteString("[]")
+		return
+	}
+	b.WriteString("[\n")
+	for i := 0; i < list.Len(); i++ {
+		indent(b, depth+1)
+		formatValue(b, list.Index(i).Interface(), depth+1)
+		b.WriteByte('\n')
+	}
+	indent(b, depth)
+	b.WriteByte(']')
+}
+
+func indent(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("\t", depth))
+}
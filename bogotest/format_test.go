@@ -0,0 +1,61 @@
+package bogotest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	data, err := bogo.Marshal(map[string]any{
+		"name":    "Ada",
+		"age":     int64(36),
+		"score":   9.5,
+		"admin":   true,
+		"avatar":  []byte{0xca, 0xfe},
+		"tags":    []string{"a", "b"},
+		"history": []any{"x", nil, map[string]any{}},
+		"address": map[string]any{"city": "London"},
+		"empty":   []any{},
+	})
+	require.NoError(t, err)
+
+	text, err := Format(data)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("# %d bytes", len(data))+`
+{
+	"address": {
+		"city": "London"
+	}
+	"admin": true
+	"age": int64(36)
+	"avatar": blob cafe
+	"empty": []
+	"history": [
+		"x"
+		null
+		{}
+	]
+	"name": "Ada"
+	"score": float64(9.5)
+	"tags": []string [
+		"a"
+		"b"
+	]
+}
+`, text)
+
+	for i := 0; i < 10; i++ {
+		again, err := bogo.Marshal(map[string]any{"name": "Ada", "age": int64(36), "address": map[string]any{"city": "London"}})
+		require.NoError(t, err)
+		first, err := Format(again)
+		require.NoError(t, err)
+		assert.Contains(t, first, "{\n\t\"address\": {\n\t\t\"city\": \"London\"\n\t}\n\t\"age\": int64(36)\n\t\"name\": \"Ada\"\n}", "output does not depend on key order")
+	}
+
+	_, err = Format([]byte{bogo.Version})
+	assert.Error(t, err)
+}
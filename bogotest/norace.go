@@ -0,0 +1,5 @@
+//go:build !race
+
+package bogotest
+
+const raceEnabled = false
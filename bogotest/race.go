@@ -0,0 +1,7 @@
+//go:build race
+
+package bogotest
+
+// raceEnabled is set when the race detector is on. It pads allocations, so
+// allocation limits are not checked.
+const raceEnabled = true
@@ -0,0 +1,114 @@
+package bogotest
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+)
+
+var update = flag.Bool("update-snapshots", false, "rewrite bogotest snapshot files instead of comparing against them")
+
+// snapshotDir holds the golden files, relative to the package under test
+var snapshotDir = "testdata"
+
+// Snapshot encodes v with bogo.Marshal, renders it with Format and compares
+// the text with the golden file testdata/<test name>.golden, reporting a line
+// diff on mismatch. With -update-snapshots the golden file is written instead.
+// Each test or subtest holds a single snapshot.
+func Snapshot(t testing.TB, v any) {
+	t.Helper()
+
+	data, err := bogo.Marshal(v)
+	if err != nil {
+		t.Fatalf("bogotest: encoding snapshot: %v", err)
+		return
+	}
+	got, err := Format(data)
+	if err != nil {
+		t.Fatalf("bogotest: formatting snapshot: %v", err)
+		return
+	}
+
+	path := filepath.Join(snapshotDir, snapshotName(t.Name())+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("bogotest: writing snapshot: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("bogotest: writing snapshot: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("bogotest: no snapshot at %s, run the test with -update-snapshots to create it", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("bogotest: reading snapshot: %v", err)
+		return
+	}
+	if string(want) != got {
+		t.Errorf("bogotest: snapshot %s does not match (-want +got):\n%s", path, lineDiff(string(want), got))
+	}
+}
+
+// snapshotName turns a test name into a file name, e.g. "TestOrder/paid"
+// becomes "TestOrder_paid"
+func snapshotName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// lineDiff renders the lines of want and got as a unified listing, prefixing
+// removed lines with "-" and added lines with "+"
+func lineDiff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,60 @@
+package bogotest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder captures the failures Snapshot reports
+type recorder struct {
+	testing.TB
+	name   string
+	failed string
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Errorf(format string, args ...any) { r.failed = fmt.Sprintf(format, args...) }
+
+func (r *recorder) Fatalf(format string, args ...any) { r.failed = fmt.Sprintf(format, args...) }
+
+func TestSnapshot(t *testing.T) {
+	Snapshot(t, map[string]any{
+		"id":    int64(7),
+		"lines": []any{map[string]any{"sku": "A-1", "qty": int64(2)}},
+		"paid":  true,
+	})
+}
+
+func TestSnapshotMismatch(t *testing.T) {
+	dir := t.TempDir()
+	snapshotDir = dir
+	defer func() { snapshotDir = "testdata" }()
+
+	r := &recorder{TB: t, name: "TestOrder/paid"}
+	Snapshot(r, map[string]any{"paid": true})
+	assert.Contains(t, r.failed, "-update-snapshots")
+
+	*update = true
+	Snapshot(r, map[string]any{"paid": true})
+	*update = false
+	golden, err := os.ReadFile(filepath.Join(dir, "TestOrder_paid.golden"))
+	require.NoError(t, err)
+	assert.Contains(t, string(golden), `"paid": true`)
+
+	r.failed = ""
+	Snapshot(r, map[string]any{"paid": true})
+	assert.Empty(t, r.failed)
+
+	Snapshot(r, map[string]any{"paid": false})
+	assert.Contains(t, r.failed, "- \t\"paid\": true\n+ \t\"paid\": false\n")
+}
+
+func TestLineDiff(t *testing.T) {
+	assert.Equal(t, "  a\n- b\n+ x\n  c\n+ d\n", lineDiff("a\nb\nc\n", "a\nx\nc\nd\n"))
+}
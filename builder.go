@@ -0,0 +1,204 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+var builderErr = errors.New("bogo builder error")
+
+// ObjectBuilder encodes an object one field at a time, for producers such as
+// rule engines and ETL jobs that assemble records dynamically and would
+// otherwise build a map[string]any for each of them. Fields are written in
+// the order they are set; setting a key twice writes it twice, and decoders
+// keep the last value.
+//
+// The first error is kept and reported by Bytes, so calls can be chained:
+//
+//	data, err := bogo.NewObjectBuilder().
+//		Set("id", 1).
+//		SetTyped("ts", bogo.TypeTimestamp, t).
+//		Bytes()
+//
+// A builder is not safe for concurrent use. Reset it to build the next object
+// in the same buffer.
+type ObjectBuilder struct {
+	encoder *Encoder
+	entries []byte
+	err     error
+}
+
+// NewObjectBuilder returns a builder encoding field values with an encoder
+// configured by options
+func NewObjectBuilder(options ...EncoderOption) *ObjectBuilder {
+	return &ObjectBuilder{encoder: NewConfigurableEncoder(options...)}
+}
+
+// Set encodes value as the encoder would encode it in a map[string]any
+func (b *ObjectBuilder) Set(key string, value any) *ObjectBuilder {
+	if b.err != nil {
+		return b
+	}
+	// Values sit one level down, inside the object being built
+	b.encoder.depth = 1
+	data, err := b.encoder.encode(value)
+	if err != nil {
+		pathErr, skip := b.encoder.childError(err, key)
+		switch {
+		case skip:
+			return b
+		case pathErr != nil:
+			b.err = pathErr
+		default:
+			b.err = fmt.Errorf("%w: field %s: %w", builderErr, key, err)
+		}
+		return b
+	}
+	return b.appendEntry(key, data)
+}
+
+// SetTyped encodes value as typ, converting between representations where
+// no information is lost: any integer as TypeInt, TypeUint or TypeByte when
+// it is in range, any number as TypeFloat, a string as TypeBlob or []byte as
+// TypeString, and a time.Time or integer Unix milliseconds as TypeTimestamp.
+// Other values must encode as typ.
+func (b *ObjectBuilder) SetTyped(key string, typ Type, value any) *ObjectBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.encoder.depth = 1
+	data, err := b.encoder.encodeAsType(typ, value)
+	if err != nil {
+		b.err = fmt.Errorf("%w: field %s: %w", builderErr, key, err)
+		return b
+	}
+	return b.appendEntry(key, data)
+}
+
+// SetField encodes field.Value as field.Type under field.Key, as SetTyped does
+func (b *ObjectBuilder) SetField(field FieldInfo) *ObjectBuilder {
+	return b.SetTyped(string(field.Key), field.Type, field.Value)
+}
+
+// Bytes returns the encoded object, or the first error met while setting
+// fields. The result does not refer to the builder's buffer.
+func (b *ObjectBuilder) Bytes() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	out := make([]byte, 0, len(Magic)+2+maxStorageByteLength+len(b.entries))
+	if b.encoder.MagicPrefix {
+		out = append(out, Magic[:]...)
+	}
+	out = appendTypedHeader(append(out, Version), TypeObject, uint64(len(b.entries)))
+	return append(out, b.entries...), nil
+}
+
+// Reset empties the builder and clears its error, keeping its buffer
+func (b *ObjectBuilder) Reset() {
+	b.entries = b.entries[:0]
+	b.err = nil
+}
+
+// appendEntry frames an encoded value as a field entry
+func (b *ObjectBuilder) appendEntry(key string, data []byte) *ObjectBuilder {
+	if len(key) > 255 {
+		b.err = wrapError(builderErr, fmt.Sprintf("key too long (%d bytes, max 255)", len(key)))
+		return b
+	}
+	if b.encoder.StrictMode && b.encoder.ValidateStrings && !isValidUTF8(key) {
+		b.err = wrapError(builderErr, "invalid UTF-8 in object key")
+		return b
+	}
+	b.entries = appendUvarintLen(b.entries, uint64(1+len(key)+len(data)))
+	b.entries = append(append(append(b.entries, byte(len(key))), key...), data...)
+	return b
+}
+
+// encodeAsType encodes value as typ, see ObjectBuilder.SetTyped
+func (e *Encoder) encodeAsType(typ Type, value any) ([]byte, error) {
+	rv := reflect.ValueOf(value)
+	isInteger := rv.CanInt() || rv.CanUint()
+
+	switch typ {
+	case TypeNull:
+		if !isNullValue(value) {
+			return nil, typeMismatch(typ, value)
+		}
+		return encodeNull(), nil
+
+	case TypeBoolTrue, TypeBoolFalse:
+		if v, ok := value.(bool); ok {
+			return encodeBool(v), nil
+		}
+		return nil, typeMismatch(typ, value)
+
+	case TypeString:
+		switch v := value.(type) {
+		case string:
+			return e.encode(v)
+		case []byte:
+			return e.encode(string(v))
+		}
+		return nil, typeMismatch(typ, value)
+
+	case TypeBlob:
+		switch v := value.(type) {
+		case []byte:
+			return encodeBlob(v)
+		case string:
+			return encodeBlob([]byte(v))
+		}
+		return nil, typeMismatch(typ, value)
+
+	case TypeByte, TypeInt, TypeUint:
+		if !isInteger {
+			return nil, typeMismatch(typ, value)
+		}
+		negative, magnitude := integerSign(rv), integerMagnitude(rv)
+		switch {
+		case typ == TypeByte && !negative && magnitude <= math.MaxUint8:
+			return encodeByte(byte(magnitude))
+		case typ == TypeUint && !negative:
+			return e.encodeUint(magnitude)
+		case typ == TypeInt && negative:
+			return e.encodeInt(rv.Int())
+		case typ == TypeInt && magnitude <= math.MaxInt64:
+			return e.encodeInt(int64(magnitude))
+		}
+		return nil, fmt.Errorf("%v is out of range for %s", value, TypeName(typ))
+
+	case TypeFloat:
+		f, _, ok := numberValue(rv)
+		if !ok {
+			return nil, typeMismatch(typ, value)
+		}
+		return encodeFloatWithPolicy(f, e.NaNPolicy)
+
+	case TypeTimestamp:
+		if t, ok := value.(time.Time); ok {
+			return encodeTimestamp(t.UnixMilli())
+		}
+		if rv.CanInt() {
+			return encodeTimestamp(rv.Int())
+		}
+		return nil, typeMismatch(typ, value)
+	}
+
+	data, err := e.encode(value)
+	if err != nil {
+		return nil, err
+	}
+	if Type(data[0]).baseType() != typ {
+		return nil, typeMismatch(typ, value)
+	}
+	return data, nil
+}
+
+// typeMismatch reports a value that cannot be encoded as typ
+func typeMismatch(typ Type, value any) error {
+	return fmt.Errorf("%T cannot be encoded as %s", value, TypeName(typ))
+}
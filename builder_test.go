@@ -0,0 +1,160 @@
+package bogo
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectBuilder(t *testing.T) {
+	ts := time.UnixMilli(1700000000123).UTC()
+
+	data, err := NewObjectBuilder().
+		Set("id", 1).
+		Set("tags", []string{"a", "b"}).
+		Set("address", map[string]any{"city": "London"}).
+		SetTyped("ts", TypeTimestamp, ts).
+		SetTyped("count", TypeUint, int8(3)).
+		SetTyped("ratio", TypeFloat, 2).
+		SetTyped("raw", TypeBlob, "xy").
+		SetField(FieldInfo{Key: []byte("flag"), Type: TypeByte, Value: uint16(7)}).
+		Bytes()
+	require.NoError(t, err)
+
+	want := map[string]any{
+		"id":      int64(1),
+		"tags":    []string{"a", "b"},
+		"address": map[string]any{"city": "London"},
+		"ts":      ts.UnixMilli(),
+		"count":   uint64(3),
+		"ratio":   float64(2),
+		"raw":     []byte("xy"),
+		"flag":    byte(7),
+	}
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, want, decoded)
+
+	// The same object built from a map encodes to the same values
+	fromMap, err := Marshal(map[string]any{"id": 1, "tags": []string{"a", "b"}})
+	require.NoError(t, err)
+	built, err := NewObjectBuilder().Set("tags", []string{"a", "b"}).Set("id", 1).Bytes()
+	require.NoError(t, err)
+	equal, err := Equal(fromMap, built)
+	require.NoError(t, err)
+	assert.True(t, equal)
+
+	t.Run("fields in set order", func(t *testing.T) {
+		assert.Equal(t, []string{"id", "tags", "address", "ts", "count", "ratio", "raw", "flag"}, wireKeys(t, data, ""))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		data, err := NewObjectBuilder().Bytes()
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{}, decoded)
+	})
+
+	t.Run("encoder options", func(t *testing.T) {
+		data, err := NewObjectBuilder(WithMagicPrefix(true), WithCompactIntegers(true)).Set("n", 5).Bytes()
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, Magic[:]))
+
+		var v map[string]any
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, map[string]any{"n": int64(5)}, v)
+	})
+}
+
+func TestObjectBuilderReset(t *testing.T) {
+	b := NewObjectBuilder()
+	first, err := b.Set("a", "x").Bytes()
+	require.NoError(t, err)
+
+	b.Reset()
+	second, err := b.Set("b", "y").Bytes()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"a": "x"}, decodeDocument(t, Document(first)), "earlier results do not share the buffer")
+	assert.Equal(t, map[string]any{"b": "y"}, decodeDocument(t, Document(second)))
+
+	b.Reset()
+	_, err = b.SetTyped("a", TypeInt, "x").Bytes()
+	require.Error(t, err)
+	b.Reset()
+	_, err = b.Set("a", 1).Bytes()
+	assert.NoError(t, err, "Reset clears the error")
+}
+
+func TestObjectBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *ObjectBuilder) *ObjectBuilder
+		err   string
+	}{
+		{"int overflow", func(b *ObjectBuilder) *ObjectBuilder {
+			return b.SetTyped("n", TypeInt, uint64(math.MaxUint64))
+		}, "out of range for int"},
+		{"negative uint", func(b *ObjectBuilder) *ObjectBuilder { return b.SetTyped("n", TypeUint, -1) }, "out of range for uint"},
+		{"byte overflow", func(b *ObjectBuilder) *ObjectBuilder { return b.SetTyped("n", TypeByte, 256) }, "out of range for byte"},
+		{"mismatch", func(b *ObjectBuilder) *ObjectBuilder { return b.SetTyped("s", TypeString, 1) }, "int cannot be encoded as string"},
+		{"container mismatch", func(b *ObjectBuilder) *ObjectBuilder {
+			return b.SetTyped("o", TypeObject, []any{1})
+		}, "[]interface {} cannot be encoded as object"},
+		{"long key", func(b *ObjectBuilder) *ObjectBuilder { return b.Set(strings.Repeat("k", 256), 1) }, "key too long"},
+		{"first error kept", func(b *ObjectBuilder) *ObjectBuilder {
+			return b.SetTyped("a", TypeNull, 1).Set(strings.Repeat("k", 256), 1)
+		}, "field a: int cannot be encoded as null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build(NewObjectBuilder()).Bytes()
+			require.ErrorIs(t, err, builderErr)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("unsupported kinds", func(t *testing.T) {
+		_, err := NewObjectBuilder().Set("fn", func() {}).Bytes()
+		var unsupported *UnsupportedTypeError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "fn", unsupported.Path)
+
+		data, err := NewObjectBuilder(WithUnsupportedKinds(UnsupportedKindSkip)).Set("fn", func() {}).Set("a", 1).Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": int64(1)}, decodeDocument(t, Document(data)))
+	})
+}
+
+func BenchmarkObjectBuilder(b *testing.B) {
+	ts := time.UnixMilli(1700000000123)
+
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			record := map[string]any{"id": i, "name": "rule", "score": 0.5, "ts": ts}
+			if _, err := Marshal(record); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("builder", func(b *testing.B) {
+		b.ReportAllocs()
+		builder := NewObjectBuilder()
+		for i := 0; i < b.N; i++ {
+			builder.Reset()
+			_, err := builder.Set("id", i).Set("name", "rule").Set("score", 0.5).SetTyped("ts", TypeTimestamp, ts).Bytes()
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package bogo
+
+// CanonicalOrder selects the order in which canonical encoding writes object
+// keys. Canonical encoding writes equal values as identical bytes, so that
+// documents can be hashed and signed; without it map keys are written in Go's
+// map iteration order, which changes from one run to the next.
+type CanonicalOrder int
+
+const (
+	// CanonicalOff writes keys in map iteration order (default)
+	CanonicalOff CanonicalOrder = iota
+	// CanonicalLexical writes the keys of every object in bytewise order
+	CanonicalLexical
+	// CanonicalDeclaration writes struct fields in the order they are declared
+	// in, and the keys of maps, which have no such order, in bytewise order
+	CanonicalDeclaration
+)
+
+func (o CanonicalOrder) String() string {
+	switch o {
+	case CanonicalOff:
+		return "off"
+	case CanonicalLexical:
+		return "lexical"
+	case CanonicalDeclaration:
+		return "declaration"
+	}
+	return "<unknown>"
+}
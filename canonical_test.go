@@ -0,0 +1,82 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type canonicalPayment struct {
+	Payee    string            `json:"payee"`
+	Amount   int64             `json:"amount"`
+	Currency string            `json:"currency"`
+	Memo     string            `json:"memo,omitempty"`
+	Meta     map[string]string `json:"meta"`
+}
+
+// wireKeys returns the keys of the object at path in data, in wire order
+func wireKeys(t *testing.T, data []byte, path string) []string {
+	t.Helper()
+	raw, err := Document(data).Get(path)
+	require.NoError(t, err)
+	fields, err := Fields(raw)
+	require.NoError(t, err)
+	var keys []string
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestCanonicalOrder(t *testing.T) {
+	payment := canonicalPayment{
+		Payee:    "Ada",
+		Amount:   1250,
+		Currency: "GBP",
+		Meta:     map[string]string{"ref": "inv-7", "channel": "web", "batch": "42"},
+	}
+
+	t.Run("declaration", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithCanonicalOrder(CanonicalDeclaration))
+		data, err := encoder.Encode(payment)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"payee", "amount", "currency", "meta"}, wireKeys(t, data, ""))
+		assert.Equal(t, []string{"batch", "channel", "ref"}, wireKeys(t, data, "meta"), "maps have no declaration order")
+
+		var decoded canonicalPayment
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, payment, decoded)
+	})
+
+	t.Run("lexical", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithCanonicalOrder(CanonicalLexical))
+		data, err := encoder.Encode(payment)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"amount", "currency", "meta", "payee"}, wireKeys(t, data, ""))
+		assert.Equal(t, []string{"batch", "channel", "ref"}, wireKeys(t, data, "meta"))
+	})
+
+	t.Run("identical bytes", func(t *testing.T) {
+		value := map[string]any{"payments": []any{payment, payment}}
+		for _, order := range []CanonicalOrder{CanonicalLexical, CanonicalDeclaration} {
+			encoder := NewConfigurableEncoder(WithCanonicalOrder(order))
+			first, err := encoder.Encode(value)
+			require.NoError(t, err)
+			for i := 0; i < 20; i++ {
+				data, err := encoder.Encode(value)
+				require.NoError(t, err)
+				require.Equal(t, first, data, order.String())
+			}
+		}
+	})
+}
+
+func TestCanonicalOrderString(t *testing.T) {
+	assert.Equal(t, "off", CanonicalOff.String())
+	assert.Equal(t, "lexical", CanonicalLexical.String())
+	assert.Equal(t, "declaration", CanonicalDeclaration.String())
+	assert.Equal(t, "<unknown>", CanonicalOrder(9).String())
+}
@@ -0,0 +1,190 @@
+package bogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+var chunkErr = errors.New("bogo chunked list error")
+
+// A chunked list is laid out as
+//
+//	TypeChunkedList | chunk ... | end
+//
+// where each chunk is a size prefix followed by that many bytes of complete
+// values and end is a size prefix of zero. Unlike a list, whose size comes
+// before its elements, it can be written while its elements are produced. It
+// decodes as a list.
+
+// seqChunkSize is the size EncodeSeq and EncodeChan buffer elements up to
+// before writing them as a chunk
+const seqChunkSize = 32 << 10
+
+// EncodeSeq encodes the values of seq to w as a single document holding a
+// chunked list, writing them in chunks as seq produces them, so lazily
+// generated data never has to be collected into a slice first. The options
+// configure the encoder used for the values; the document decodes as a list.
+//
+// When a value fails to encode, EncodeSeq stops and returns the error,
+// leaving w with a truncated document that fails to decode.
+func EncodeSeq[T any](w io.Writer, seq iter.Seq[T], options ...EncoderOption) error {
+	sw := newSeqWriter(w, options)
+	for v := range seq {
+		if err := sw.add(v); err != nil {
+			return err
+		}
+	}
+	return sw.close()
+}
+
+// EncodeChan encodes the values received from ch to w like EncodeSeq,
+// finishing the document once ch is closed. When ctx is done first, it
+// returns the context's error and the document is left truncated.
+func EncodeChan[T any](ctx context.Context, w io.Writer, ch <-chan T, options ...EncoderOption) error {
+	sw := newSeqWriter(w, options)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-ch:
+			if !ok {
+				return sw.close()
+			}
+			if err := sw.add(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// seqWriter writes a document holding a chunked list
+type seqWriter struct {
+	w       io.Writer
+	e       *Encoder
+	buf     []byte // header not yet written and values of the current chunk
+	pending int    // start of the values of the current chunk in buf
+	n       int    // values added
+}
+
+func newSeqWriter(w io.Writer, options []EncoderOption) *seqWriter {
+	e := NewConfigurableEncoder(options...)
+	buf := make([]byte, 0, seqChunkSize)
+	if e.MagicPrefix {
+		buf = append(buf, Magic[:]...)
+	}
+	buf = append(buf, Version, byte(TypeChunkedList))
+	return &seqWriter{w: w, e: e, buf: buf, pending: len(buf)}
+}
+
+// add encodes v into the current chunk, writing the chunk once it is full
+func (sw *seqWriter) add(v any) error {
+	sw.e.depth = 1
+	sw.e.listDepth = 1
+	data, err := sw.e.encode(v)
+	if err != nil {
+		pathErr, skip := sw.e.childError(err, fmt.Sprintf("[%d]", sw.n))
+		if pathErr != nil {
+			return pathErr
+		}
+		if !skip {
+			return wrapError(chunkErr, fmt.Sprintf("error encoding element %d", sw.n), err.Error())
+		}
+		data = encodeNull()
+	}
+	sw.n++
+	sw.buf = append(sw.buf, data...)
+	if len(sw.buf)-sw.pending >= seqChunkSize {
+		return sw.flush()
+	}
+	return nil
+}
+
+// flush writes what is buffered, closing the current chunk
+func (sw *seqWriter) flush() error {
+	values := sw.buf[sw.pending:]
+	out := appendUvarintLen(sw.buf[:sw.pending:sw.pending], uint64(len(values)))
+	if _, err := sw.w.Write(append(out, values...)); err != nil {
+		return err
+	}
+	sw.buf, sw.pending = sw.buf[:0], 0
+	return nil
+}
+
+// close writes the last chunk and the end of the list
+func (sw *seqWriter) close() error {
+	if len(sw.buf) > sw.pending {
+		if err := sw.flush(); err != nil {
+			return err
+		}
+	}
+	_, err := sw.w.Write(appendUvarintLen(sw.buf, 0))
+	return err
+}
+
+// walkChunks calls fn with the values of each chunk of the chunked list data,
+// which follows the type byte, and returns the size of the list without its
+// type byte. A nil fn only sizes the list.
+func walkChunks(data []byte, fn func(values []byte) error) (int, error) {
+	for pos := 0; ; {
+		chunk, err := readSizePrefix(data, pos)
+		if err != nil {
+			return 0, err
+		}
+		pos += chunk.width
+		if chunk.value == 0 {
+			return pos, nil
+		}
+		if chunk.value > uint64(len(data)-pos) {
+			return 0, errors.New("insufficient data for chunk")
+		}
+		end := pos + int(chunk.value)
+		if fn != nil {
+			if err := fn(data[pos:end]); err != nil {
+				return 0, err
+			}
+		}
+		pos = end
+	}
+}
+
+// walkChunkValues calls fn with each value of the chunked list data
+func walkChunkValues(data []byte, fn func(value []byte) error) error {
+	_, err := walkChunks(data, func(values []byte) error {
+		for pos := 0; pos < len(values); {
+			size, err := getElementSize(values[pos:])
+			if err != nil {
+				return err
+			}
+			if size <= 0 || size > len(values)-pos {
+				return errors.New("value overruns its chunk")
+			}
+			if err := fn(values[pos : pos+size]); err != nil {
+				return err
+			}
+			pos += size
+		}
+		return nil
+	})
+	return err
+}
+
+// decodeChunkedListWith decodes a chunked list into a list
+func decodeChunkedListWith(data []byte, strs stringMaker) (any, error) {
+	n := 0
+	if err := walkChunkValues(data, func([]byte) error { n++; return nil }); err != nil {
+		return nil, wrapError(chunkErr, err.Error())
+	}
+	list := make([]any, 0, n)
+	err := walkChunkValues(data, func(value []byte) error {
+		v, err := decodeValueWith(value, strs)
+		list = append(list, v)
+		return err
+	})
+	if err != nil {
+		return nil, wrapError(chunkErr, err.Error())
+	}
+	return list, nil
+}
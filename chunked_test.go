@@ -0,0 +1,135 @@
+package bogo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingWriter records the size of every write
+type countingWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestEncodeSeq(t *testing.T) {
+	readings := func(n int) iter.Seq[reading] {
+		return func(yield func(reading) bool) {
+			for i := 0; i < n; i++ {
+				if !yield(reading{Sensor: fmt.Sprint("s", i), Value: float64(i), Ok: i%2 == 0}) {
+					return
+				}
+			}
+		}
+	}
+
+	t.Run("writes chunks as values are produced", func(t *testing.T) {
+		var w countingWriter
+		require.NoError(t, EncodeSeq(&w, readings(5000)))
+		assert.Equal(t, byte(TypeChunkedList), w.Bytes()[1])
+		assert.Greater(t, len(w.writes), 2, "more than one chunk")
+		for _, n := range w.writes {
+			assert.Less(t, n, 2*seqChunkSize)
+		}
+
+		var got []reading
+		require.NoError(t, Unmarshal(w.Bytes(), &got))
+		assert.Equal(t, slices.Collect(readings(5000)), got)
+
+		_, err := NewSecureDecoder().Decode(w.Bytes())
+		assert.NoError(t, err)
+		_, err = Footprint(w.Bytes())
+		assert.NoError(t, err)
+	})
+
+	t.Run("decodes like a list", func(t *testing.T) {
+		values := []any{"a", int64(1), []any{true}, map[string]any{"k": nil}}
+		var buf bytes.Buffer
+		require.NoError(t, EncodeSeq(&buf, slices.Values(values)))
+
+		want, err := Decode(mustMarshal(t, values))
+		require.NoError(t, err)
+		decoded, err := Decode(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+		decoded, err = NewConfigurableDecoder().Decode(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+
+		doc, err := Marshal(map[string]any{"inner": RawValue(buf.Bytes())})
+		require.NoError(t, err)
+		inner, err := Document(doc).Get("inner")
+		require.NoError(t, err)
+		assert.Equal(t, buf.Bytes()[1:], inner)
+	})
+
+	t.Run("empty sequences", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeSeq(&buf, slices.Values([]string{})))
+		assert.Equal(t, []byte{Version, TypeChunkedList, 1, 0}, buf.Bytes())
+		decoded, err := Decode(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, []any{}, decoded)
+	})
+
+	t.Run("options apply to the values", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeSeq(&buf, slices.Values([]int64{1, 2}), WithCompactIntegers(true), WithMagicPrefix(true)))
+		assert.Equal(t, append(Magic[:], Version, TypeChunkedList, 1, 2, 0xE1, 0xE2, 1, 0), buf.Bytes())
+	})
+
+	t.Run("failed values leave the document truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := EncodeSeq(&buf, slices.Values([]any{"ok", make(chan int)}))
+		assert.Error(t, err)
+		_, err = Decode(buf.Bytes())
+		assert.Error(t, err)
+	})
+
+	t.Run("truncated documents", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeSeq(&buf, slices.Values([]string{"a", "b"})))
+		data := buf.Bytes()
+		for i := 2; i < len(data); i++ {
+			_, err := Decode(data[:i])
+			assert.Error(t, err, "truncated at %d", i)
+		}
+	})
+}
+
+func TestEncodeChan(t *testing.T) {
+	t.Run("drains the channel", func(t *testing.T) {
+		ch := make(chan string)
+		go func() {
+			defer close(ch)
+			for _, s := range []string{"a", "b", "c"} {
+				ch <- s
+			}
+		}()
+		var buf bytes.Buffer
+		require.NoError(t, EncodeChan(context.Background(), &buf, ch))
+
+		var got []string
+		require.NoError(t, Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, []string{"a", "b", "c"}, got)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var buf bytes.Buffer
+		err := EncodeChan(ctx, &buf, make(chan int))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,129 @@
+package bogo
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Date is a calendar date without a time or time zone. It is encoded as
+// TypeDate so birthdays and schedules are not shifted by time zone conversions.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the calendar date of t in t's location
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// In returns the time.Time at midnight of the date in loc
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports whether the date is unset
+func (d Date) IsZero() bool {
+	return d.Year == 0 && d.Month == 0 && d.Day == 0
+}
+
+// String returns the date in YYYY-MM-DD format
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// TimeOfDay is a wall clock time without a date or time zone. It is encoded as
+// TypeTimeOfDay.
+type TimeOfDay struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// TimeOfDayOf returns the wall clock time of t in t's location
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(), Nanosecond: t.Nanosecond()}
+}
+
+// On returns the time.Time at this time of day on the given date in loc
+func (t TimeOfDay) On(d Date, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, t.Nanosecond, loc)
+}
+
+// String returns the time in HH:MM:SS format, with fractional seconds when present
+func (t TimeOfDay) String() string {
+	if t.Nanosecond == 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d.%09d", t.Hour, t.Minute, t.Second, t.Nanosecond)
+}
+
+var (
+	dateType      = reflect.TypeOf(Date{})
+	timeOfDayType = reflect.TypeOf(TimeOfDay{})
+)
+
+const (
+	dateSize      = 4 // year(2) + month(1) + day(1)
+	timeOfDaySize = 7 // hour(1) + minute(1) + second(1) + nanosecond(4)
+)
+
+func encodeDate(d Date) ([]byte, error) {
+	if d.Year < -32768 || d.Year > 32767 {
+		return nil, fmt.Errorf("date encode error: year %d out of range", d.Year)
+	}
+	if d.Month < 0 || d.Month > 12 || d.Day < 0 || d.Day > 31 {
+		return nil, fmt.Errorf("date encode error: invalid date %s", d)
+	}
+
+	buf := make([]byte, 1+dateSize)
+	buf[0] = byte(TypeDate)
+	wireOrder.PutUint16(buf[1:3], uint16(int16(d.Year)))
+	buf[3] = byte(d.Month)
+	buf[4] = byte(d.Day)
+	return buf, nil
+}
+
+func decodeDate(data []byte) (Date, error) {
+	if len(data) < dateSize {
+		return Date{}, fmt.Errorf("date decode error: insufficient data, need %d bytes, got %d", dateSize, len(data))
+	}
+
+	return Date{
+		Year:  int(int16(wireOrder.Uint16(data[0:2]))),
+		Month: time.Month(data[2]),
+		Day:   int(data[3]),
+	}, nil
+}
+
+func encodeTimeOfDay(t TimeOfDay) ([]byte, error) {
+	if t.Hour < 0 || t.Hour > 23 || t.Minute < 0 || t.Minute > 59 || t.Second < 0 || t.Second > 60 ||
+		t.Nanosecond < 0 || t.Nanosecond > 999999999 {
+		return nil, fmt.Errorf("time of day encode error: invalid time %s", t)
+	}
+
+	buf := make([]byte, 1+timeOfDaySize)
+	buf[0] = byte(TypeTimeOfDay)
+	buf[1] = byte(t.Hour)
+	buf[2] = byte(t.Minute)
+	buf[3] = byte(t.Second)
+	wireOrder.PutUint32(buf[4:8], uint32(t.Nanosecond))
+	return buf, nil
+}
+
+func decodeTimeOfDay(data []byte) (TimeOfDay, error) {
+	if len(data) < timeOfDaySize {
+		return TimeOfDay{}, fmt.Errorf("time of day decode error: insufficient data, need %d bytes, got %d", timeOfDaySize, len(data))
+	}
+
+	return TimeOfDay{
+		Hour:       int(data[0]),
+		Minute:     int(data[1]),
+		Second:     int(data[2]),
+		Nanosecond: int(wireOrder.Uint32(data[3:7])),
+	}, nil
+}
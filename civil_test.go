@@ -0,0 +1,73 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCivilTypes(t *testing.T) {
+	type Person struct {
+		Name     string    `json:"name"`
+		Birthday Date      `json:"birthday"`
+		Alarm    TimeOfDay `json:"alarm"`
+	}
+
+	t.Run("date round trip", func(t *testing.T) {
+		date := Date{Year: 1990, Month: time.March, Day: 14}
+		data, err := Encode(date)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, TypeDate, 0xC6, 0x07, 3, 14}, data)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, date, decoded)
+	})
+
+	t.Run("time of day round trip", func(t *testing.T) {
+		tod := TimeOfDay{Hour: 7, Minute: 30, Second: 5, Nanosecond: 250000000}
+		data, err := Encode(tod)
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, tod, decoded)
+		assert.Equal(t, "07:30:05.250000000", tod.String())
+	})
+
+	t.Run("struct fields and lists", func(t *testing.T) {
+		person := Person{
+			Name:     "Ada",
+			Birthday: Date{Year: 1815, Month: time.December, Day: 10},
+			Alarm:    TimeOfDay{Hour: 6},
+		}
+		data, err := Marshal(person)
+		require.NoError(t, err)
+
+		var decoded Person
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, person, decoded)
+
+		data, err = Encode([]any{person.Birthday, person.Alarm, "tail"})
+		require.NoError(t, err)
+		list, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{person.Birthday, person.Alarm, "tail"}, list)
+	})
+
+	t.Run("date is independent of time zone", func(t *testing.T) {
+		loc := time.FixedZone("UTC+14", 14*60*60)
+		date := DateOf(time.Date(2024, 2, 29, 23, 0, 0, 0, loc))
+		assert.Equal(t, "2024-02-29", date.String())
+		assert.Equal(t, 29, date.In(time.UTC).Day())
+	})
+
+	t.Run("invalid values are rejected", func(t *testing.T) {
+		_, err := Encode(TimeOfDay{Hour: 24})
+		assert.Error(t, err)
+		_, err = Encode(Date{Year: 1, Month: 13, Day: 1})
+		assert.Error(t, err)
+	})
+}
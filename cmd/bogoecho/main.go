@@ -0,0 +1,160 @@
+// Command bogoecho is a reference echo service for testing ports of bogo to
+// other languages against this implementation. Every document it receives is
+// decoded, re-encoded canonically and sent back, so a port can check both that
+// its output decodes here and that it decodes what this implementation writes:
+//
+//	go run github.com/bubunyo/bogo/cmd/bogoecho -http :8080 -tcp :9090
+//
+// Over HTTP, POST a document to /echo; the response body is the canonical
+// document, or a 400 with the decode error as text. Over TCP, send documents
+// framed as bogo.WriteLengthPrefixed frames them; each is answered with a frame
+// whose first byte is 0 followed by the canonical document, or 1 followed by
+// the error message. A connection stays open until the client closes it.
+//
+// Canonical documents write object keys in bytewise order and keep the magic
+// prefix when the request had one. They hold what the decoder returns, so
+// values the decoder does not round trip come back in their decoded form:
+// nested timestamps, for instance, come back as Unix milliseconds.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/bubunyo/bogo"
+)
+
+// maxDocumentSize bounds the documents accepted over either transport
+const maxDocumentSize = 16 << 20
+
+// TCP response statuses
+const (
+	statusOK    byte = 0
+	statusError byte = 1
+)
+
+func main() {
+	httpAddr := flag.String("http", "", "HTTP listen address, e.g. :8080")
+	tcpAddr := flag.String("tcp", "", "TCP listen address, e.g. :9090")
+	flag.Parse()
+
+	if err := run(*httpAddr, *tcpAddr); err != nil {
+		fmt.Fprintln(os.Stderr, "bogoecho:", err)
+		os.Exit(1)
+	}
+}
+
+func run(httpAddr, tcpAddr string) error {
+	if httpAddr == "" && tcpAddr == "" {
+		return errors.New("expected -http or -tcp")
+	}
+
+	errs := make(chan error, 2)
+	if tcpAddr != "" {
+		ln, err := net.Listen("tcp", tcpAddr)
+		if err != nil {
+			return err
+		}
+		log.Printf("bogoecho: tcp listening on %s", ln.Addr())
+		go func() { errs <- serveTCP(ln) }()
+	}
+	if httpAddr != "" {
+		ln, err := net.Listen("tcp", httpAddr)
+		if err != nil {
+			return err
+		}
+		log.Printf("bogoecho: http listening on %s", ln.Addr())
+		go func() { errs <- http.Serve(ln, newHandler()) }()
+	}
+	return <-errs
+}
+
+// echo decodes a document and encodes the result canonically
+func echo(data []byte) ([]byte, error) {
+	v, err := bogo.NewConfigurableDecoder(bogo.WithMaxObjectSize(maxDocumentSize)).Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	encoder := bogo.NewConfigurableEncoder(
+		bogo.WithCanonicalOrder(bogo.CanonicalLexical),
+		bogo.WithMagicPrefix(bytes.HasPrefix(data, bogo.Magic[:])),
+	)
+	return encoder.Encode(v)
+}
+
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /echo", func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDocumentSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		out, err := echo(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(out)
+	})
+	return mux
+}
+
+func serveTCP(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := handleConn(conn); err != nil {
+				log.Printf("bogoecho: %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handleConn answers the frames sent on conn until the client closes it
+func handleConn(conn io.ReadWriter) error {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		if _, err := r.Peek(1); err == io.EOF {
+			return nil
+		}
+		size, err := bogo.ReadUvarintLen(r)
+		if err != nil {
+			return err
+		}
+		if size > maxDocumentSize {
+			return fmt.Errorf("frame of %d bytes exceeds %d", size, maxDocumentSize)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+
+		response := []byte{statusOK}
+		if out, err := echo(data); err != nil {
+			response = append([]byte{statusError}, err.Error()...)
+		} else {
+			response = append(response, out...)
+		}
+		if _, err := bogo.WriteLengthPrefixed(w, response); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
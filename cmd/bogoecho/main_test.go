@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEchoIsCanonical(t *testing.T) {
+	doc := map[string]any{"b": int64(2), "a": []any{"x", true, nil}, "c": map[string]any{"z": 1.5, "y": "s"}}
+	data, err := bogo.Marshal(doc)
+	require.NoError(t, err)
+
+	out, err := echo(data)
+	require.NoError(t, err)
+	want, err := bogo.NewConfigurableEncoder(bogo.WithCanonicalOrder(bogo.CanonicalLexical)).Encode(doc)
+	require.NoError(t, err)
+	assert.Equal(t, want, out)
+
+	again, err := echo(out)
+	require.NoError(t, err)
+	assert.Equal(t, out, again, "canonical documents echo unchanged")
+
+	t.Run("keeps the magic prefix", func(t *testing.T) {
+		data, err := bogo.NewConfigurableEncoder(bogo.WithMagicPrefix(true)).Encode(doc)
+		require.NoError(t, err)
+		out, err := echo(data)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(out, bogo.Magic[:]))
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(newHandler())
+	defer server.Close()
+
+	data, err := bogo.Marshal(map[string]any{"id": int64(7)})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/echo", "application/octet-stream", bytes.NewReader(data))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, data, body)
+
+	resp, err = http.Post(server.URL+"/echo", "application/octet-stream", bytes.NewReader([]byte{0x7f}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTCP(t *testing.T) {
+	client, server := net.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- handleConn(server) }()
+
+	data, err := bogo.Marshal([]any{"hello", int64(1)})
+	require.NoError(t, err)
+	for _, frame := range [][]byte{data, {0x7f}, data} {
+		_, err := bogo.WriteLengthPrefixed(client, frame)
+		require.NoError(t, err)
+		response, err := bogo.ReadLengthPrefixed(client)
+		require.NoError(t, err)
+		require.NotEmpty(t, response)
+
+		if frame[0] == 0x7f {
+			assert.Equal(t, statusError, response[0])
+			assert.NotEmpty(t, response[1:])
+			continue
+		}
+		assert.Equal(t, statusOK, response[0])
+		assert.Equal(t, data, response[1:])
+	}
+
+	require.NoError(t, client.Close())
+	assert.NoError(t, <-done)
+}
+
+func TestRunRequiresAnAddress(t *testing.T) {
+	assert.Error(t, run("", ""))
+}
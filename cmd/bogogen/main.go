@@ -0,0 +1,310 @@
+// Command bogogen generates code for types encoded with bogo.
+//
+// With -accessors it emits, for every named struct type, a Doc type over the
+// encoded bytes whose methods read one field in place, flatbuffers-style,
+// without decoding the rest of the document into maps:
+//
+//	//go:generate go run github.com/bubunyo/bogo/cmd/bogogen -type User -accessors
+//
+//	name, err := UserDoc(data).Name()
+//
+// Nested struct fields get their own Doc type. Fields whose types have no
+// typed accessor (slices, maps, ...) are read with bogo.FieldValue.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names")
+	accessors := flag.Bool("accessors", false, "generate typed accessors over encoded bytes")
+	tagName := flag.String("tag", "json", "struct tag holding field names, as in bogo.WithStructTag")
+	output := flag.String("output", "", "output file name (default: <first type>_bogo.go)")
+	flag.Parse()
+
+	if err := run(*typeNames, *accessors, *tagName, *output, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "bogogen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(typeNames string, accessors bool, tagName, output string, args []string) error {
+	if typeNames == "" {
+		return errors.New("-type is required")
+	}
+	if !accessors {
+		return errors.New("nothing to generate, use -accessors")
+	}
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	types := strings.Split(typeNames, ",")
+
+	src, err := generateAccessors(dir, types, tagName)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.ToLower(types[0]) + "_bogo.go"
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// field is one accessor to generate
+type field struct {
+	Name   string // Go field name, used as the method name
+	Key    string // encoded object key
+	GoType string // Go type returned by the accessor
+	Kind   accessorKind
+}
+
+type accessorKind int
+
+const (
+	kindValue accessorKind = iota
+	kindString
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindBlob
+	kindTime
+	kindObject
+)
+
+var basicKinds = map[string]accessorKind{
+	"string":  kindString,
+	"int":     kindInt,
+	"int8":    kindInt,
+	"int16":   kindInt,
+	"int32":   kindInt,
+	"int64":   kindInt,
+	"uint":    kindUint,
+	"uint8":   kindUint,
+	"uint16":  kindUint,
+	"uint32":  kindUint,
+	"uint64":  kindUint,
+	"float32": kindFloat,
+	"float64": kindFloat,
+	"bool":    kindBool,
+	"byte":    kindUint,
+	"rune":    kindInt,
+}
+
+// narrowingChecks holds, for every numeric type narrower than the int64,
+// uint64 or float64 its accessor reads, the condition under which the value
+// v read from the document does not fit. int and uint are checked too, as
+// they are 32 bits wide on some platforms.
+var narrowingChecks = map[string]string{
+	"int":     "v < math.MinInt || v > math.MaxInt",
+	"int8":    "v < math.MinInt8 || v > math.MaxInt8",
+	"int16":   "v < math.MinInt16 || v > math.MaxInt16",
+	"int32":   "v < math.MinInt32 || v > math.MaxInt32",
+	"rune":    "v < math.MinInt32 || v > math.MaxInt32",
+	"uint":    "v > math.MaxUint",
+	"uint8":   "v > math.MaxUint8",
+	"byte":    "v > math.MaxUint8",
+	"uint16":  "v > math.MaxUint16",
+	"uint32":  "v > math.MaxUint32",
+	"float32": "!math.IsInf(v, 0) && math.Abs(v) > math.MaxFloat32",
+}
+
+// generateAccessors parses the package in dir and returns the formatted
+// source of the accessors for types and the struct types they reference
+func generateAccessors(dir string, types []string, tagName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	structs := make(map[string]*ast.StructType)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					structs[ts.Name.Name] = st
+				}
+			}
+		}
+	}
+
+	g := &generator{structs: structs, tagName: tagName, fields: make(map[string][]field)}
+	for _, name := range types {
+		if err := g.addType(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.source(pkg.Name)
+}
+
+type generator struct {
+	structs map[string]*ast.StructType
+	tagName string
+	order   []string
+	fields  map[string][]field
+}
+
+func (g *generator) addType(name string) error {
+	if _, done := g.fields[name]; done {
+		return nil
+	}
+	st, ok := g.structs[name]
+	if !ok {
+		return fmt.Errorf("struct type %s not found", name)
+	}
+	g.fields[name] = nil
+	g.order = append(g.order, name)
+
+	var fields []field
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			continue // embedded fields are not flattened by the encoder
+		}
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := ident.Name
+			if f.Tag != nil {
+				tag := reflect.StructTag(strings.Trim(f.Tag.Value, "`")).Get(g.tagName)
+				if name, _, _ := strings.Cut(tag, ","); name == "-" {
+					continue
+				} else if name != "" {
+					key = name
+				}
+			}
+
+			fd, err := g.fieldFor(ident.Name, key, f.Type)
+			if err != nil {
+				return err
+			}
+			fields = append(fields, fd)
+		}
+	}
+	g.fields[name] = fields
+	return nil
+}
+
+func (g *generator) fieldFor(name, key string, expr ast.Expr) (field, error) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X // null reads as the zero value
+	}
+
+	fd := field{Name: name, Key: key, GoType: "any", Kind: kindValue}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if kind, ok := basicKinds[t.Name]; ok {
+			fd.GoType, fd.Kind = t.Name, kind
+		} else if _, ok := g.structs[t.Name]; ok {
+			fd.GoType, fd.Kind = t.Name+"Doc", kindObject
+			if err := g.addType(t.Name); err != nil {
+				return field{}, err
+			}
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Time" {
+			fd.GoType, fd.Kind = "time.Time", kindTime
+		}
+	case *ast.ArrayType:
+		if elt, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (elt.Name == "byte" || elt.Name == "uint8") {
+			fd.GoType, fd.Kind = "[]byte", kindBlob
+		}
+	}
+	return fd, nil
+}
+
+func (g *generator) source(pkgName string) ([]byte, error) {
+	var body bytes.Buffer
+	usesTime := false
+	usesRange := false
+
+	for _, name := range g.order {
+		doc := name + "Doc"
+		fmt.Fprintf(&body, "\n// %s reads the fields of an encoded %s in place.\n", doc, name)
+		fmt.Fprintf(&body, "type %s []byte\n", doc)
+
+		for _, f := range g.fields[name] {
+			fmt.Fprintf(&body, "\n// %s reads field %q.\n", f.Name, f.Key)
+			fmt.Fprintf(&body, "func (d %s) %s() (%s, error) {\n", doc, f.Name, f.GoType)
+			switch f.Kind {
+			case kindString:
+				fmt.Fprintf(&body, "\treturn bogo.FieldString(d, %q)\n", f.Key)
+			case kindInt, kindUint, kindFloat:
+				fn := map[accessorKind]string{kindInt: "FieldInt", kindUint: "FieldUint", kindFloat: "FieldFloat"}[f.Kind]
+				fmt.Fprintf(&body, "\tv, err := bogo.%s(d, %q)\n", fn, f.Key)
+				overflow, narrow := narrowingChecks[f.GoType]
+				if !narrow {
+					fmt.Fprintf(&body, "\treturn %s(v), err\n", f.GoType)
+					break
+				}
+				usesRange = true
+				verb := map[accessorKind]string{kindInt: "%d", kindUint: "%d", kindFloat: "%g"}[f.Kind]
+				fmt.Fprintf(&body, "\tif err != nil {\n\t\treturn 0, err\n\t}\n")
+				msg := fmt.Sprintf("bogo: field %s: value %s overflows %s", strings.ReplaceAll(f.Key, "%", "%%"), verb, f.GoType)
+				fmt.Fprintf(&body, "\tif %s {\n\t\treturn 0, fmt.Errorf(%q, v)\n\t}\n", overflow, msg)
+				fmt.Fprintf(&body, "\treturn %s(v), nil\n", f.GoType)
+			case kindBool:
+				fmt.Fprintf(&body, "\treturn bogo.FieldBool(d, %q)\n", f.Key)
+			case kindBlob:
+				fmt.Fprintf(&body, "\treturn bogo.FieldBlob(d, %q)\n", f.Key)
+			case kindTime:
+				usesTime = true
+				fmt.Fprintf(&body, "\treturn bogo.FieldTime(d, %q)\n", f.Key)
+			case kindObject:
+				fmt.Fprintf(&body, "\tv, err := bogo.FieldObject(d, %q)\n\treturn %s(v), err\n", f.Key, f.GoType)
+			default:
+				fmt.Fprintf(&body, "\treturn bogo.FieldValue(d, %q)\n", f.Key)
+			}
+			body.WriteString("}\n")
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by bogogen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkgName)
+	if usesRange {
+		out.WriteString("\t\"fmt\"\n\t\"math\"\n")
+	}
+	if usesTime {
+		out.WriteString("\t\"time\"\n")
+	}
+	if usesRange || usesTime {
+		out.WriteString("\n")
+	}
+	out.WriteString("\t\"github.com/bubunyo/bogo\"\n)\n")
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
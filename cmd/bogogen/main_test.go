@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedAccessorsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "gentest")
+	src, err := generateAccessors(dir, []string{"User"}, "json")
+	require.NoError(t, err)
+
+	want, err := os.ReadFile(filepath.Join(dir, "user_bogo.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(src), "run go generate ./internal/gentest")
+}
+
+func TestGenerateAccessors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), []byte(`package models
+
+type Order struct {
+	ID     uint64            `+"`bogo:\"id\"`"+`
+	Total  float64           `+"`bogo:\"total,omitempty\"`"+`
+	Note   string
+	Meta   map[string]string `+"`bogo:\"meta\"`"+`
+	Hidden bool              `+"`bogo:\"-\"`"+`
+}
+`), 0o644))
+
+	require.NoError(t, run("Order", true, "bogo", "", []string{dir}))
+
+	src, err := os.ReadFile(filepath.Join(dir, "order_bogo.go"))
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.True(t, strings.HasPrefix(code, "// Code generated by bogogen; DO NOT EDIT."))
+	assert.Contains(t, code, "package models")
+	assert.Contains(t, code, `func (d OrderDoc) ID() (uint64, error) {`)
+	assert.Contains(t, code, `bogo.FieldUint(d, "id")`)
+	assert.Contains(t, code, `bogo.FieldFloat(d, "total")`)
+	assert.Contains(t, code, `bogo.FieldString(d, "Note")`)
+	assert.Contains(t, code, `func (d OrderDoc) Meta() (any, error) {`)
+	assert.NotContains(t, code, "Hidden")
+	assert.NotContains(t, code, `"time"`)
+	assert.NotContains(t, code, `"math"`, "64-bit fields need no range check")
+}
+
+func TestGenerateNarrowingChecks(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), []byte(`package models
+
+type Pixel struct {
+	X     int8
+	Alpha uint8
+	Gamma float32
+}
+`), 0o644))
+
+	src, err := generateAccessors(dir, []string{"Pixel"}, "json")
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.Contains(t, code, "if v < math.MinInt8 || v > math.MaxInt8 {")
+	assert.Contains(t, code, "if v > math.MaxUint8 {")
+	assert.Contains(t, code, "if !math.IsInf(v, 0) && math.Abs(v) > math.MaxFloat32 {")
+	assert.Contains(t, code, `return 0, fmt.Errorf("bogo: field X: value %d overflows int8", v)`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	assert.Error(t, run("", true, "json", "", nil))
+	assert.Error(t, run("User", false, "json", "", nil))
+
+	_, err := generateAccessors(filepath.Join("..", "..", "internal", "gentest"), []string{"Missing"}, "json")
+	assert.Error(t, err)
+}
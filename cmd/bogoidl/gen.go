@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// scalarTypes maps IDL scalar types to Go types
+var scalarTypes = map[string]string{
+	"double":    "float64",
+	"float":     "float32",
+	"int32":     "int32",
+	"int64":     "int64",
+	"sint32":    "int32",
+	"sint64":    "int64",
+	"sfixed32":  "int32",
+	"sfixed64":  "int64",
+	"uint32":    "uint32",
+	"uint64":    "uint64",
+	"fixed32":   "uint32",
+	"fixed64":   "uint64",
+	"bool":      "bool",
+	"string":    "string",
+	"bytes":     "[]byte",
+	"timestamp": "time.Time",
+
+	"google.protobuf.Timestamp": "time.Time",
+}
+
+// reservedNames are the methods generated on every message
+var reservedNames = map[string]bool{"Validate": true, "Encode": true, "Decode": true}
+
+// initialisms are written in upper case in Go names, as golint expects
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"uri": true, "url": true, "uuid": true,
+}
+
+// goName converts a snake_case IDL name to an exported Go name
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// generate returns the formatted Go source for s. source names the IDL file
+// in the generated header, pkgName overrides the package declared in s.
+func generate(s *schema, source, pkgName string) ([]byte, error) {
+	if pkgName == "" {
+		pkgName = s.Package[strings.LastIndex(s.Package, ".")+1:]
+	}
+
+	messages := make(map[string]bool)
+	for _, m := range s.Messages {
+		messages[m.Name] = true
+	}
+
+	g := &idlGenerator{messages: messages}
+	for _, m := range s.Messages {
+		if err := g.message(m); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by bogoidl from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkgName)
+	if g.usesErrors {
+		out.WriteString("\t\"errors\"\n")
+	}
+	if g.usesFmt {
+		out.WriteString("\t\"fmt\"\n")
+	}
+	if g.usesTime {
+		out.WriteString("\t\"time\"\n")
+	}
+	if g.usesErrors || g.usesFmt || g.usesTime {
+		out.WriteString("\n")
+	}
+	out.WriteString("\t\"github.com/bubunyo/bogo\"\n)\n")
+	out.Write(g.body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+type idlGenerator struct {
+	messages   map[string]bool
+	body       bytes.Buffer
+	usesErrors bool
+	usesFmt    bool
+	usesTime   bool
+}
+
+// goType returns the Go type of a single value of IDL type name
+func (g *idlGenerator) goType(name string) string {
+	if typ, ok := scalarTypes[name]; ok {
+		if typ == "time.Time" {
+			g.usesTime = true
+		}
+		return typ
+	}
+	return goName(name)
+}
+
+// fieldType returns the Go type of field f. Singular messages are pointers so
+// that an absent message encodes as null.
+func (g *idlGenerator) fieldType(f *idlField) string {
+	switch {
+	case f.Type == "map":
+		return "map[string]" + g.goType(f.ValType)
+	case f.Repeated:
+		return "[]" + g.goType(f.Type)
+	case g.messages[f.Type]:
+		return "*" + g.goType(f.Type)
+	}
+	return g.goType(f.Type)
+}
+
+func (g *idlGenerator) message(m *message) error {
+	name := goName(m.Name)
+	b := &g.body
+
+	fmt.Fprintf(b, "\n// Field IDs of %s\nconst (\n", name)
+	for _, f := range m.Fields {
+		fmt.Fprintf(b, "\t%s%sField = %d\n", name, goName(f.Name), f.ID)
+	}
+	b.WriteString(")\n")
+
+	fmt.Fprintf(b, "\n// %s is generated from message %s.\ntype %s struct {\n", name, m.Name, name)
+	for _, f := range m.Fields {
+		field := goName(f.Name)
+		if reservedNames[field] {
+			return fmt.Errorf("line %d: %s.%s: field name %s clashes with a generated method", f.Line, m.Name, f.Name, field)
+		}
+		tag := f.Name
+		if f.Optional {
+			tag += ",omitempty"
+		}
+		if format, ok := f.Options["format"]; ok {
+			tag += ",format=" + format
+		}
+		fmt.Fprintf(b, "\t%s %s `json:%q`\n", field, g.fieldType(f), tag)
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\n// Validate checks the constraints declared on %s.\n", m.Name)
+	fmt.Fprintf(b, "func (m *%s) Validate() error {\n", name)
+	for _, f := range m.Fields {
+		g.validation(f)
+	}
+	b.WriteString("\treturn nil\n}\n")
+
+	fmt.Fprintf(b, "\n// Encode validates m and encodes it with bogo.Marshal.\n")
+	fmt.Fprintf(b, "func (m *%s) Encode() ([]byte, error) {\n", name)
+	b.WriteString("\tif err := m.Validate(); err != nil {\n\t\treturn nil, err\n\t}\n")
+	b.WriteString("\treturn bogo.Marshal(m)\n}\n")
+
+	fmt.Fprintf(b, "\n// Decode decodes data into m with bogo.Unmarshal and validates the result.\n")
+	fmt.Fprintf(b, "func (m *%s) Decode(data []byte) error {\n", name)
+	b.WriteString("\tif err := bogo.Unmarshal(data, m); err != nil {\n\t\treturn err\n\t}\n")
+	b.WriteString("\treturn m.Validate()\n}\n")
+	return nil
+}
+
+// validation writes the checks for field f in the body of Validate
+func (g *idlGenerator) validation(f *idlField) {
+	b := &g.body
+	field := "m." + goName(f.Name)
+
+	if f.Options["required"] == "true" {
+		g.usesErrors = true
+		cond := fmt.Sprintf("len(%s) == 0", field)
+		if g.messages[f.Type] && !f.Repeated {
+			cond = field + " == nil"
+		}
+		fmt.Fprintf(b, "\tif %s {\n\t\treturn errors.New(\"%s is required\")\n\t}\n", cond, f.Name)
+	}
+
+	if format, ok := f.Options["format"]; ok {
+		g.usesFmt = true
+		fmt.Fprintf(b, "\tif err := bogo.ValidateFormat(%q, %s); err != nil {\n", format, field)
+		fmt.Fprintf(b, "\t\treturn fmt.Errorf(\"%s: %%w\", err)\n\t}\n", f.Name)
+	}
+
+	elem := f.Type
+	if f.Type == "map" {
+		elem = f.ValType
+	}
+	if !g.messages[elem] {
+		return
+	}
+	g.usesFmt = true
+	switch {
+	case f.Type == "map":
+		fmt.Fprintf(b, "\tfor k, v := range %s {\n\t\tif err := v.Validate(); err != nil {\n", field)
+		fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"%s[%%q]: %%w\", k, err)\n\t\t}\n\t}\n", f.Name)
+	case f.Repeated:
+		fmt.Fprintf(b, "\tfor i := range %s {\n\t\tif err := %s[i].Validate(); err != nil {\n", field, field)
+		fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"%s[%%d]: %%w\", i, err)\n\t\t}\n\t}\n", f.Name)
+	default:
+		fmt.Fprintf(b, "\tif %s != nil {\n\t\tif err := %s.Validate(); err != nil {\n", field, field)
+		fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"%s: %%w\", err)\n\t\t}\n\t}\n", f.Name)
+	}
+}
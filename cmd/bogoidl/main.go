@@ -0,0 +1,80 @@
+// Command bogoidl compiles message definitions into Go structs and bogo codecs,
+// for teams that want a contract-first workflow instead of hand-written struct
+// tags. Definitions use a subset of proto3 syntax:
+//
+//	syntax = "proto3";
+//	package users;
+//
+//	message User {
+//	  string name = 1 [required = true];
+//	  string email = 2 [format = "email"];
+//	  optional int64 age = 3;
+//	  repeated string tags = 4;
+//	  map<string, string> labels = 5;
+//	  timestamp joined = 6;
+//	  Address address = 7;
+//	}
+//
+// For every message bogoidl emits a struct whose fields are keyed by the field
+// names, constants holding the field IDs, and Validate, Encode and Decode
+// methods. Validate enforces required fields and the formats registered with
+// bogo.RegisterFormat; Encode and Decode run it around bogo.Marshal and
+// bogo.Unmarshal. Optional fields are omitted from the encoding when empty.
+//
+//	//go:generate go run github.com/bubunyo/bogo/cmd/bogoidl users.bogo
+//
+// Enums, oneofs, nested and imported definitions are not supported.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	pkgName := flag.String("package", "", "Go package name (default: last element of the IDL package)")
+	output := flag.String("output", "", "output file name (default: <input>.go)")
+	flag.Parse()
+
+	if err := run(*pkgName, *output, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "bogoidl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkgName, output string, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected one input file")
+	}
+	input := args[0]
+
+	src, err := compile(input, pkgName)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = input + ".go"
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// compile parses the IDL file at path and returns the generated Go source
+func compile(path, pkgName string) ([]byte, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	out, err := generate(s, filepath.Base(path), pkgName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
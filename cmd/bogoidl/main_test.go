@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedMessagesUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "idltest")
+	src, err := compile(filepath.Join(dir, "users.bogo"), "")
+	require.NoError(t, err)
+
+	want, err := os.ReadFile(filepath.Join(dir, "users.bogo.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(src), "run go generate ./internal/idltest")
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "orders.bogo")
+	require.NoError(t, os.WriteFile(input, []byte(`
+package shop.orders;
+
+message Order {
+  uint64 id = 1;
+  repeated string items = 2 [required = true];
+}
+`), 0o644))
+
+	require.NoError(t, run("", "", []string{input}))
+	src, err := os.ReadFile(input + ".go")
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.True(t, strings.HasPrefix(code, "// Code generated by bogoidl from orders.bogo; DO NOT EDIT."))
+	assert.Contains(t, code, "package orders")
+	assert.Contains(t, code, "OrderIDField    = 1")
+	assert.Contains(t, code, "Items []string `json:\"items\"`")
+	assert.Contains(t, code, `if len(m.Items) == 0 {`)
+	assert.NotContains(t, code, `"fmt"`)
+	assert.NotContains(t, code, `"time"`)
+
+	output := filepath.Join(dir, "custom.go")
+	require.NoError(t, run("shop", output, []string{input}))
+	src, err = os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package shop")
+
+	assert.Error(t, run("", "", nil))
+	assert.Error(t, run("", "", []string{filepath.Join(dir, "missing.bogo")}))
+}
+
+func TestParse(t *testing.T) {
+	s, err := parse(`
+syntax = "proto3"; // trailing comment
+package a.b;
+
+/* multi
+   line */
+message M {
+  optional string name = 1 [format = "email", required = false];
+  map<string, N> children = 2;
+  repeated google.protobuf.Timestamp times = 3;
+}
+message N {}
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "a.b", s.Package)
+	require.Len(t, s.Messages, 2)
+
+	m := s.Messages[0]
+	assert.Equal(t, 7, m.Line)
+	require.Len(t, m.Fields, 3)
+	assert.Equal(t, &idlField{Name: "name", ID: 1, Type: "string", Optional: true, Options: map[string]string{"format": "email", "required": "false"}, Line: 8}, m.Fields[0])
+	assert.Equal(t, "N", m.Fields[1].ValType)
+	assert.True(t, m.Fields[2].Repeated)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"no package", `message M {}`, "missing package declaration"},
+		{"proto2", `syntax = "proto2";`, "only proto3 syntax is supported"},
+		{"unknown statement", "package p;\nenum E {}", `line 2: unexpected "enum"`},
+		{"unterminated comment", `/* package p;`, "unterminated comment"},
+		{"unterminated string", `syntax = "proto3;`, "unterminated string"},
+		{"unclosed message", "package p;\nmessage M { string a = 1;", "message M is not closed"},
+		{"bad character", `package p; @`, "unexpected character"},
+		{"missing semicolon", "package p;\nmessage M { string a = 1 }", `line 2: expected ";", found "}"`},
+		{"bad field ID", "package p;\nmessage M { string a = x; }", `invalid field ID "x"`},
+		{"zero field ID", "package p;\nmessage M { string a = 0; }", "M.a: field ID must be positive"},
+		{"duplicate field ID", "package p;\nmessage M { string a = 1; string b = 1; }", "M.b: field ID 1 already used by a"},
+		{"duplicate field", "package p;\nmessage M { string a = 1; string a = 2; }", "duplicate field name"},
+		{"duplicate message", "package p;\nmessage M {}\nmessage M {}", "line 3: duplicate message M"},
+		{"unknown type", "package p;\nmessage M { Missing a = 1; }", "unknown type Missing"},
+		{"unknown map value", "package p;\nmessage M { map<string, Missing> a = 1; }", "unknown type Missing"},
+		{"map key", "package p;\nmessage M { map<int64, string> a = 1; }", "map keys must be strings"},
+		{"repeated map", "package p;\nmessage M { repeated map<string, string> a = 1; }", "maps cannot be repeated"},
+		{"required scalar", "package p;\nmessage M { int64 a = 1 [required = true]; }", "required needs a string"},
+		{"required value", "package p;\nmessage M { string a = 1 [required = yes]; }", "required must be true or false"},
+		{"format on int", "package p;\nmessage M { int64 a = 1 [format = \"email\"]; }", "format needs a string field"},
+		{"unknown option", "package p;\nmessage M { string a = 1 [packed = true]; }", "unknown option packed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.src)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	s, err := parse("package p;\nmessage M { string validate = 1; }")
+	require.NoError(t, err)
+
+	_, err = generate(s, "m.bogo", "")
+	assert.ErrorContains(t, err, "field name Validate clashes with a generated method")
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "UserID", goName("user_id"))
+	assert.Equal(t, "HomeURL", goName("home_url"))
+	assert.Equal(t, "PreviousAddresses", goName("previous_addresses"))
+	assert.Equal(t, "Name", goName("name"))
+	assert.Equal(t, "A2B", goName("a__2_b"))
+}
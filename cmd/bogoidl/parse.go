@@ -0,0 +1,357 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// schema is a parsed IDL file
+type schema struct {
+	Package  string
+	Messages []*message
+}
+
+type message struct {
+	Name   string
+	Fields []*idlField
+	Line   int
+}
+
+type idlField struct {
+	Name     string
+	ID       int
+	Type     string // scalar, message name or "map"
+	KeyType  string // map key type
+	ValType  string // map value type
+	Repeated bool
+	Optional bool
+	Options  map[string]string
+	Line     int
+}
+
+type token struct {
+	text string
+	str  bool // quoted string literal
+	line int
+}
+
+// tokenize splits src into identifiers, numbers, string literals and
+// punctuation, dropping // and /* */ comments
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) || src[j] != c {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			s, err := strconv.Unquote(`"` + strings.ReplaceAll(src[i+1:j], `"`, `\"`) + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string: %v", line, err)
+			}
+			tokens = append(tokens, token{text: s, str: true, line: line})
+			i = j + 1
+		case strings.ContainsRune("{}[]=;,<>", rune(c)):
+			tokens = append(tokens, token{text: string(c), line: line})
+			i++
+		case isIdentRune(rune(c)) || c == '-':
+			j := i + 1
+			for j < len(src) && (isIdentRune(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{text: src[i:j], line: line})
+			i = j
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	line := 0
+	if len(p.tokens) > 0 {
+		line = p.tokens[len(p.tokens)-1].line
+	}
+	return token{line: line}
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.text != text || t.str {
+		return fmt.Errorf("line %d: expected %q, found %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) ident() (token, error) {
+	t := p.next()
+	if t.str || t.text == "" || !isIdentRune(rune(t.text[0])) || unicode.IsDigit(rune(t.text[0])) {
+		return t, fmt.Errorf("line %d: expected identifier, found %q", t.line, t.text)
+	}
+	return t, nil
+}
+
+// parse parses the proto3-style subset described in the package documentation
+func parse(src string) (*schema, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	s := &schema{}
+
+	for p.pos < len(p.tokens) {
+		t := p.next()
+		switch t.text {
+		case "syntax":
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			if v := p.next(); !v.str || v.text != "proto3" {
+				return nil, fmt.Errorf("line %d: only proto3 syntax is supported", v.line)
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "package":
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			s.Package = name.text
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "message":
+			m, err := p.message(t.line)
+			if err != nil {
+				return nil, err
+			}
+			s.Messages = append(s.Messages, m)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q, expected syntax, package or message", t.line, t.text)
+		}
+	}
+
+	return s, s.check()
+}
+
+func (p *parser) message(line int) (*message, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	m := &message{Name: name.text, Line: line}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for p.peek().text != "}" {
+		if p.peek().text == "" {
+			return nil, fmt.Errorf("line %d: message %s is not closed", line, m.Name)
+		}
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		m.Fields = append(m.Fields, f)
+	}
+	p.next()
+	return m, nil
+}
+
+func (p *parser) field() (*idlField, error) {
+	f := &idlField{Options: map[string]string{}, Line: p.peek().line}
+
+	switch p.peek().text {
+	case "repeated":
+		f.Repeated = true
+		p.next()
+	case "optional":
+		f.Optional = true
+		p.next()
+	}
+
+	typ, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	f.Type = typ.text
+	if f.Type == "map" {
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		val, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		f.KeyType, f.ValType = key.text, val.text
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	f.Name = name.text
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	id := p.next()
+	if f.ID, err = strconv.Atoi(id.text); err != nil || id.str {
+		return nil, fmt.Errorf("line %d: invalid field ID %q", id.line, id.text)
+	}
+
+	if p.peek().text == "[" {
+		p.next()
+		for {
+			opt, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			f.Options[opt.text] = p.next().text
+			if p.peek().text != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, p.expect(";")
+}
+
+// check validates names, field IDs, types and options across the schema
+func (s *schema) check() error {
+	if s.Package == "" {
+		return fmt.Errorf("missing package declaration")
+	}
+
+	messages := make(map[string]bool)
+	for _, m := range s.Messages {
+		if messages[m.Name] {
+			return fmt.Errorf("line %d: duplicate message %s", m.Line, m.Name)
+		}
+		messages[m.Name] = true
+	}
+
+	for _, m := range s.Messages {
+		ids := make(map[int]string)
+		names := make(map[string]bool)
+		for _, f := range m.Fields {
+			where := fmt.Sprintf("line %d: %s.%s", f.Line, m.Name, f.Name)
+			if f.ID < 1 {
+				return fmt.Errorf("%s: field ID must be positive", where)
+			}
+			if other, ok := ids[f.ID]; ok {
+				return fmt.Errorf("%s: field ID %d already used by %s", where, f.ID, other)
+			}
+			if names[f.Name] {
+				return fmt.Errorf("%s: duplicate field name", where)
+			}
+			ids[f.ID], names[f.Name] = f.Name, true
+
+			if f.Type == "map" {
+				if f.Repeated {
+					return fmt.Errorf("%s: maps cannot be repeated", where)
+				}
+				if f.KeyType != "string" {
+					return fmt.Errorf("%s: map keys must be strings", where)
+				}
+				if !isKnownType(f.ValType, messages) {
+					return fmt.Errorf("%s: unknown type %s", where, f.ValType)
+				}
+			} else if !isKnownType(f.Type, messages) {
+				return fmt.Errorf("%s: unknown type %s", where, f.Type)
+			}
+
+			for opt, value := range f.Options {
+				switch opt {
+				case "required":
+					if value != "true" && value != "false" {
+						return fmt.Errorf("%s: required must be true or false", where)
+					}
+					if value == "true" && !hasPresence(f, messages) {
+						return fmt.Errorf("%s: required needs a string, bytes, message, repeated or map field", where)
+					}
+				case "format":
+					if f.Type != "string" {
+						return fmt.Errorf("%s: format needs a string field", where)
+					}
+				default:
+					return fmt.Errorf("%s: unknown option %s", where, opt)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasPresence reports whether an empty value of f can be told apart, so that
+// required can be checked
+func hasPresence(f *idlField, messages map[string]bool) bool {
+	return f.Repeated || f.Type == "map" || f.Type == "string" || f.Type == "bytes" || messages[f.Type]
+}
+
+func isKnownType(name string, messages map[string]bool) bool {
+	_, scalar := scalarTypes[name]
+	return scalar || messages[name]
+}
@@ -0,0 +1,23 @@
+//go:build js && wasm
+
+// Command bogowasm is a WebAssembly module that exposes the bogo codec to
+// JavaScript as globalThis.bogo. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -trimpath -ldflags="-s -w" -o bogo.wasm ./cmd/bogowasm
+//
+// and load it with bogojs/bogo.js next to the wasm_exec.js shipped with Go.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/bubunyo/bogo/bogojs"
+)
+
+func main() {
+	bogo := js.Global().Get("Object").New()
+	bogojs.Register(bogo)
+	js.Global().Set("bogo", bogo)
+
+	select {} // keep the bindings alive
+}
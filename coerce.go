@@ -0,0 +1,109 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var coercionErr = errors.New("bogo coercion error")
+
+// pathCoercion is a function registered with WithPathCoercion
+type pathCoercion struct {
+	path string
+	keys []string // the keys of path, "[]" standing for every element of a list
+	fn   func(any) (any, error)
+}
+
+// WithPathCoercion normalizes the values found at path while decoding, by
+// replacing each with what fn returns, so fields that legacy producers write
+// in the wrong shape, such as integers as strings or timestamps in seconds,
+// are fixed once rather than in every consumer. Paths are written as for
+// OnField: dot-separated object keys, with "[]" selecting every element of a
+// list, e.g. "orders[].created". The empty path selects the whole document.
+//
+// fn receives the value as it would decode into an interface, including nil
+// for nulls, and is not called when the path is missing. Its errors fail the
+// decode. Coercions run after decoding, before values are assigned to the
+// destination of Unmarshal, in the order they were added. They are not
+// applied to lazy objects or typed values.
+func WithPathCoercion(path string, fn func(any) (any, error)) DecoderOption {
+	c := pathCoercion{path: path, keys: coercionKeys(path), fn: fn}
+	return func(d *Decoder) {
+		// Decoders copied from one another, as pools do, must not share
+		// what is appended
+		d.coercions = append(slices.Clip(d.coercions), c)
+	}
+}
+
+// coercionKeys splits path into its keys
+func coercionKeys(path string) []string {
+	var keys []string
+	for _, part := range strings.Split(path, ".") {
+		lists := 0
+		for ; strings.HasSuffix(part, "[]"); lists++ {
+			part = strings.TrimSuffix(part, "[]")
+		}
+		if part != "" {
+			keys = append(keys, part)
+		}
+		for range lists {
+			keys = append(keys, "[]")
+		}
+	}
+	return keys
+}
+
+// coerce applies the coercions of the decoder to v, the decoded document
+func (d *Decoder) coerce(v any) (any, error) {
+	for _, c := range d.coercions {
+		var err error
+		if v, err = coerceAt(v, c.keys, c.fn); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", coercionErr, c.path, err)
+		}
+	}
+	return v, nil
+}
+
+// coerceAt replaces the values at keys below v with what fn returns for them
+func coerceAt(v any, keys []string, fn func(any) (any, error)) (any, error) {
+	if len(keys) == 0 {
+		return fn(v)
+	}
+	var err error
+	switch x := v.(type) {
+	case map[string]any:
+		field, ok := x[keys[0]]
+		if !ok {
+			return v, nil
+		}
+		if x[keys[0]], err = coerceAt(field, keys[1:], fn); err != nil {
+			return nil, err
+		}
+	case []any:
+		if keys[0] != "[]" {
+			return v, nil
+		}
+		for i, elem := range x {
+			if x[i], err = coerceAt(elem, keys[1:], fn); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		// Typed lists, whose elements may no longer share a type once coerced
+		rv := reflect.ValueOf(v)
+		if keys[0] != "[]" || rv.Kind() != reflect.Slice {
+			return v, nil
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			if list[i], err = coerceAt(rv.Index(i).Interface(), keys[1:], fn); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,112 @@
+package bogo
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathCoercion(t *testing.T) {
+	atoi := func(v any) (any, error) {
+		if s, ok := v.(string); ok {
+			return strconv.ParseInt(s, 10, 64)
+		}
+		return v, nil
+	}
+	seconds := func(v any) (any, error) {
+		if n, ok := v.(int64); ok && n < 1e11 {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return v, nil
+	}
+
+	data := mustMarshal(t, map[string]any{
+		"user": map[string]any{"id": "42", "created": int64(1700000000)},
+		"orders": []any{
+			map[string]any{"qty": "3"},
+			map[string]any{"qty": "5"},
+			map[string]any{"note": "no quantity"},
+		},
+		"codes": []string{"7", "8"},
+	})
+	d := NewConfigurableDecoder(
+		WithPathCoercion("user.id", atoi),
+		WithPathCoercion("user.created", seconds),
+		WithPathCoercion("orders[].qty", atoi),
+		WithPathCoercion("codes[]", atoi),
+		WithPathCoercion("missing.field", func(any) (any, error) {
+			t.Error("called for a missing path")
+			return nil, nil
+		}),
+	)
+
+	decoded, err := d.Decode(data)
+	require.NoError(t, err)
+	doc := decoded.(map[string]any)
+	assert.Equal(t, map[string]any{"id": int64(42), "created": time.Unix(1700000000, 0).UTC()}, doc["user"])
+	assert.Equal(t, []any{
+		map[string]any{"qty": int64(3)},
+		map[string]any{"qty": int64(5)},
+		map[string]any{"note": "no quantity"},
+	}, doc["orders"])
+	assert.Equal(t, []any{int64(7), int64(8)}, doc["codes"])
+
+	var out struct {
+		User struct {
+			ID      int       `json:"id"`
+			Created time.Time `json:"created"`
+		} `json:"user"`
+		Orders []struct {
+			Qty int `json:"qty"`
+		} `json:"orders"`
+		Codes []int `json:"codes"`
+	}
+	require.NoError(t, d.Unmarshal(data, &out))
+	assert.Equal(t, 42, out.User.ID)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), out.User.Created)
+	assert.Equal(t, 5, out.Orders[1].Qty)
+	assert.Equal(t, []int{7, 8}, out.Codes)
+
+	t.Run("whole document", func(t *testing.T) {
+		d := NewConfigurableDecoder(WithPathCoercion("", func(v any) (any, error) {
+			return map[string]any{"wrapped": v}, nil
+		}))
+		decoded, err := d.Decode(mustMarshal(t, "x"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"wrapped": "x"}, decoded)
+	})
+
+	t.Run("flat maps", func(t *testing.T) {
+		d := NewConfigurableDecoder(WithPathCoercion("a", atoi))
+		var out map[string]int64
+		require.NoError(t, d.Unmarshal(mustMarshal(t, map[string]any{"a": "1", "b": int64(2)}), &out))
+		assert.Equal(t, map[string]int64{"a": 1, "b": 2}, out)
+	})
+
+	t.Run("errors fail the decode", func(t *testing.T) {
+		_, err := d.Decode(mustMarshal(t, map[string]any{"user": map[string]any{"id": "x"}}))
+		assert.ErrorIs(t, err, coercionErr)
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+		assert.ErrorContains(t, err, "user.id")
+	})
+
+	t.Run("pooled decoders do not share coercions", func(t *testing.T) {
+		pool := NewDecoderPool(WithPathCoercion("a", atoi))
+		upper := pool.Acquire(WithPathCoercion("b", atoi))
+		plain := pool.Acquire()
+		assert.Len(t, upper.coercions, 2)
+		assert.Len(t, plain.coercions, 1)
+		pool.Release(upper)
+		pool.Release(plain)
+
+		failing := WithPathCoercion("c", func(any) (any, error) { return nil, errors.New("boom") })
+		_, err := pool.Decode(mustMarshal(t, map[string]any{"c": 1}), failing)
+		assert.ErrorContains(t, err, "boom")
+		_, err = pool.Decode(mustMarshal(t, map[string]any{"c": 1}))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,99 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError reports a decoded value that cannot be assigned to the Go value
+// at Path, such as a string decoded into an int field or an overflowing number
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("bogo: field %s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors lists every problem found while assigning a document to a Go
+// value when the decoder collects errors. Its elements are *FieldError and
+// *ValidationError values, in field order.
+type DecodeErrors []error
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("bogo: %d decode errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e DecodeErrors) Unwrap() []error {
+	return e
+}
+
+// WithCollectErrors makes Unmarshal keep assigning fields after a field fails
+// and return every problem as DecodeErrors, with the path and reason of each.
+// This suits validation-style consumers that report all problems at once.
+// Malformed documents still fail on the first error, as nothing after it can
+// be read.
+func WithCollectErrors(collect bool) DecoderOption {
+	return func(d *Decoder) {
+		d.CollectErrors = collect
+	}
+}
+
+// Unmarshal decodes data and stores the result in the value pointed to by v,
+// like the package-level Unmarshal but with the decoder's configuration
+func (d *Decoder) Unmarshal(data []byte, v any) error {
+	data, err := d.prepare(data)
+	if err != nil {
+		return err
+	}
+	if u, ok := v.(Unmarshaler); ok {
+		return u.UnmarshalBogo(data)
+	}
+	if len(d.coercions) == 0 && d.unmarshalFlatMap(data[1:], v) || d.unmarshalScalar(data[1:], v) {
+		return nil
+	}
+	plan := d.rawPlanOf(v)
+	if plan == nil && d.unmarshalStruct(data[1:], v) {
+		return nil
+	}
+	var result any
+	if plan != nil {
+		result, err = d.decodeRaw(data[1:], plan)
+		if err == nil && len(d.coercions) > 0 {
+			result, err = d.coerce(result)
+		}
+		err = d.deadlineErr(err)
+	} else {
+		result, err = d.decodePrepared(data)
+	}
+	if err != nil {
+		return err
+	}
+	return assignResultWith(result, v, d)
+}
+
+// collectErrors appends the problems of err, found at segment, to errs.
+// Problems of nested values keep their own paths below segment.
+func collectErrors(errs DecodeErrors, err error, segment string) DecodeErrors {
+	var nested DecodeErrors
+	if errors.As(err, &nested) {
+		for _, e := range nested {
+			errs = append(errs, prefixPath(e, segment))
+		}
+		return errs
+	}
+	if errors.As(err, new(*ValidationError)) || errors.As(err, new(*FieldError)) {
+		return append(errs, prefixPath(err, segment))
+	}
+	return append(errs, &FieldError{Path: segment, Err: err})
+}
@@ -0,0 +1,98 @@
+package bogo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collectContact struct {
+	Email string `json:"email,format=email"`
+	Age   int8   `json:"age"`
+}
+
+type collectForm struct {
+	Name     string            `json:"name"`
+	Phone    string            `json:"phone,format=e164"`
+	Count    int               `json:"count"`
+	Contacts []collectContact  `json:"contacts"`
+	Owner    *collectContact   `json:"owner"`
+	Labels   map[string]string `json:"labels"`
+}
+
+func TestCollectErrors(t *testing.T) {
+	data, err := Marshal(map[string]any{
+		"name":  42,
+		"phone": "not a number",
+		"count": 3,
+		"contacts": []any{
+			map[string]any{"email": "ada@example.com", "age": 36},
+			map[string]any{"email": "broken", "age": 300},
+		},
+		"owner":  map[string]any{"age": "old"},
+		"labels": map[string]any{"a": "ok", "b": 2, "c": true},
+	})
+	require.NoError(t, err)
+
+	var form collectForm
+	err = NewConfigurableDecoder(WithCollectErrors(true)).Unmarshal(data, &form)
+	require.Error(t, err)
+
+	var errs DecodeErrors
+	require.True(t, errors.As(err, &errs))
+
+	paths := make([]string, len(errs))
+	for i, e := range errs {
+		var ferr *FieldError
+		var verr *ValidationError
+		switch {
+		case errors.As(e, &ferr):
+			paths[i] = ferr.Path
+		case errors.As(e, &verr):
+			paths[i] = verr.Path
+		}
+	}
+	assert.Equal(t, []string{
+		"name",
+		"phone",
+		"contacts[1].email",
+		"contacts[1].age",
+		"owner.age",
+		"labels.b",
+		"labels.c",
+	}, paths)
+	assert.Contains(t, err.Error(), "bogo: 7 decode errors: ")
+	assert.Contains(t, err.Error(), "bogo: field contacts[1].age: value 300 overflows int8")
+
+	// Valid fields are still assigned
+	assert.Equal(t, 3, form.Count)
+	assert.Equal(t, "ada@example.com", form.Contacts[0].Email)
+	assert.Equal(t, "ok", form.Labels["a"])
+
+	// The first format violation is still reachable with errors.As
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, "e164", verr.Format)
+}
+
+func TestCollectErrorsDisabled(t *testing.T) {
+	data, err := Marshal(map[string]any{"name": 42, "count": "three"})
+	require.NoError(t, err)
+
+	var form collectForm
+	err = NewConfigurableDecoder().Unmarshal(data, &form)
+	require.Error(t, err)
+	assert.False(t, errors.As(err, new(DecodeErrors)))
+	assert.Contains(t, err.Error(), "bogo: error assigning field")
+}
+
+func TestCollectErrorsValidDocument(t *testing.T) {
+	data, err := Marshal(map[string]any{"name": "Ada", "count": 1})
+	require.NoError(t, err)
+
+	var form collectForm
+	require.NoError(t, NewConfigurableDecoder(WithCollectErrors(true)).Unmarshal(data, &form))
+	assert.Equal(t, collectForm{Name: "Ada", Count: 1}, form)
+}
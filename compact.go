@@ -0,0 +1,45 @@
+package bogo
+
+import "errors"
+
+var errShortCompact = errors.New("bogo decode error: insufficient data for short string")
+
+// compactSize returns the encoded size, type byte included, of the compact
+// value at the start of data. It reports false if data does not start with a
+// compact type.
+func compactSize(data []byte) (int, bool) {
+	t := Type(data[0])
+	switch {
+	case isFixUint(t), isFixInt(t):
+		return 1, true
+	case isFixStr(t):
+		return 1 + int(t-TypeFixStr), true
+	}
+	return 0, false
+}
+
+// decodeCompact decodes the compact value at the start of data. It reports
+// false if data does not start with a compact type.
+func decodeCompact(data []byte) (any, bool, error) {
+	return decodeCompactWith(data, stringMaker{})
+}
+
+// decodeCompactWith is decodeCompact building strings with strs
+func decodeCompactWith(data []byte, strs stringMaker) (any, bool, error) {
+	t := Type(data[0])
+	switch {
+	case isFixUint(t):
+		v, err := strs.number(uint64(t-TypeFixUint), nil)
+		return v, true, err
+	case isFixInt(t):
+		v, err := strs.number(int64(t-TypeFixInt), nil)
+		return v, true, err
+	case isFixStr(t):
+		size := int(t - TypeFixStr)
+		if len(data) < 1+size {
+			return nil, true, errShortCompact
+		}
+		return strs.str(data[1 : 1+size]), true, nil
+	}
+	return nil, false, nil
+}
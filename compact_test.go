@@ -0,0 +1,136 @@
+package bogo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactIntegers(t *testing.T) {
+	encoder := NewConfigurableEncoder(WithCompactIntegers(true))
+
+	t.Run("small values take one byte", func(t *testing.T) {
+		data, err := encoder.Encode(uint64(7))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, byte(TypeFixUint) | 7}, data)
+
+		data, err = encoder.Encode(31)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, byte(TypeFixInt) | 31}, data)
+	})
+
+	t.Run("values out of range use regular encoding", func(t *testing.T) {
+		for _, v := range []any{uint64(32), 32, -1, int64(-1000)} {
+			compact, err := encoder.Encode(v)
+			require.NoError(t, err)
+			regular, err := Encode(v)
+			require.NoError(t, err)
+			assert.Equal(t, regular, compact, "value %v", v)
+		}
+	})
+
+	t.Run("round trip keeps signedness", func(t *testing.T) {
+		for _, v := range []any{uint64(0), uint64(31), int64(0), int64(31), int64(-5), uint64(1 << 40)} {
+			data, err := encoder.Encode(v)
+			require.NoError(t, err)
+
+			decoded, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, v, decoded)
+		}
+	})
+
+	t.Run("objects and lists", func(t *testing.T) {
+		type Metrics struct {
+			Hits   uint32 `json:"hits"`
+			Errors int    `json:"errors"`
+			Tags   []any  `json:"tags"`
+		}
+		data, err := encoder.Encode(Metrics{Hits: 3, Errors: 1, Tags: []any{1, "a", uint(2)}})
+		require.NoError(t, err)
+
+		regular, err := Encode(Metrics{Hits: 3, Errors: 1, Tags: []any{1, "a", uint(2)}})
+		require.NoError(t, err)
+		assert.Less(t, len(data), len(regular))
+
+		var out Metrics
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, Metrics{Hits: 3, Errors: 1, Tags: []any{int64(1), "a", uint64(2)}}, out)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), decoded.(map[string]any)["hits"])
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		data, err := Encode(uint64(7))
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUint), data[1])
+	})
+
+	t.Run("type names", func(t *testing.T) {
+		assert.Equal(t, Type(TypeUint).String(), (TypeFixUint | 3).String())
+		assert.Equal(t, Type(TypeInt).String(), (TypeFixInt | 3).String())
+	})
+}
+
+func TestCompactStrings(t *testing.T) {
+	encoder := NewConfigurableEncoder(WithCompactStrings(true))
+
+	t.Run("short strings carry their length in the type byte", func(t *testing.T) {
+		data, err := encoder.Encode("hello")
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, byte(TypeFixStr) | 5, 'h', 'e', 'l', 'l', 'o'}, data)
+
+		regular, err := Encode("hello")
+		require.NoError(t, err)
+		assert.Equal(t, len(regular)-2, len(data))
+	})
+
+	t.Run("round trip at the length boundary", func(t *testing.T) {
+		for _, s := range []string{"", strings.Repeat("a", 31), strings.Repeat("b", 32), strings.Repeat("c", 300)} {
+			data, err := encoder.Encode(s)
+			require.NoError(t, err)
+			assert.Equal(t, len(s) < 32, isFixStr(Type(data[1])), "length %d", len(s))
+
+			decoded, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, s, decoded)
+		}
+	})
+
+	t.Run("objects, lists and structs", func(t *testing.T) {
+		type User struct {
+			Name string `json:"name"`
+			Bio  string `json:"bio"`
+			Tags []any  `json:"tags"`
+		}
+		in := User{Name: "Ada", Bio: strings.Repeat("x", 40), Tags: []any{"go", 1}}
+		data, err := encoder.Encode(in)
+		require.NoError(t, err)
+
+		var out User
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, "Ada", out.Name)
+		assert.Equal(t, in.Bio, out.Bio)
+		assert.Equal(t, []any{"go", int64(1)}, out.Tags)
+
+		decoder := NewConfigurableDecoder(WithSelectiveFields([]string{"name"}))
+		decoded, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", decoded.(map[string]any)["name"])
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		_, err := Decode([]byte{Version, byte(TypeFixStr) | 5, 'h', 'i'})
+		assert.Error(t, err)
+	})
+
+	t.Run("UTF-8 validation", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithUTF8Validation(true))
+		_, err := decoder.Decode([]byte{Version, byte(TypeFixStr) | 2, 0xff, 0xfe})
+		assert.Error(t, err)
+	})
+}
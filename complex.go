@@ -0,0 +1,40 @@
+package bogo
+
+import (
+	"fmt"
+	"math"
+)
+
+const complexSize = 16 // real and imaginary parts as little-endian IEEE 754 doubles
+
+// encodeComplex encodes c bit-exactly; complex64 values are widened first
+func encodeComplex(c complex128) ([]byte, error) {
+	buf := make([]byte, 1+complexSize)
+	buf[0] = byte(TypeComplex)
+	wireOrder.PutUint64(buf[1:9], math.Float64bits(real(c)))
+	wireOrder.PutUint64(buf[9:17], math.Float64bits(imag(c)))
+	return buf, nil
+}
+
+// encodeComplexWithPolicy encodes c, applying policy when either of its parts
+// is not finite
+func encodeComplexWithPolicy(c complex128, policy NaNPolicy) ([]byte, error) {
+	if !isFinite(real(c)) || !isFinite(imag(c)) {
+		switch policy {
+		case NaNError:
+			return nil, fmt.Errorf("%w: %v", errNonFiniteFloat, c)
+		case NaNNull:
+			return encodeNull(), nil
+		}
+	}
+	return encodeComplex(c)
+}
+
+func decodeComplex(data []byte) (complex128, error) {
+	if len(data) < complexSize {
+		return 0, fmt.Errorf("complex decode error: insufficient data, need %d bytes, got %d", complexSize, len(data))
+	}
+	re := math.Float64frombits(wireOrder.Uint64(data[0:8]))
+	im := math.Float64frombits(wireOrder.Uint64(data[8:16]))
+	return complex(re, im), nil
+}
@@ -0,0 +1,100 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type impedance complex128
+
+type spectrum struct {
+	Peak    complex128            `json:"peak"`
+	Bins    []complex64           `json:"bins"`
+	Poles   map[string]complex128 `json:"poles"`
+	Load    impedance             `json:"load"`
+	Untyped any                   `json:"untyped"`
+}
+
+func TestComplex(t *testing.T) {
+	for _, c := range []complex128{0, 1 + 2i, complex(-math.MaxFloat64, math.SmallestNonzeroFloat64), complex(math.Inf(1), math.Copysign(0, -1))} {
+		data, err := Marshal(c)
+		require.NoError(t, err)
+		assert.Len(t, data, 2+complexSize)
+		assert.Equal(t, byte(TypeComplex), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, c, decoded)
+
+		var v complex128
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, math.Float64bits(real(c)), math.Float64bits(real(v)))
+		assert.Equal(t, math.Float64bits(imag(c)), math.Float64bits(imag(v)))
+	}
+}
+
+func TestComplexNaN(t *testing.T) {
+	data, err := Marshal(complex(math.NaN(), 1))
+	require.NoError(t, err)
+
+	var v complex128
+	require.NoError(t, Unmarshal(data, &v))
+	assert.True(t, math.IsNaN(real(v)))
+	assert.Equal(t, 1.0, imag(v))
+}
+
+func TestComplexNaNPolicy(t *testing.T) {
+	for _, c := range []complex128{complex(math.NaN(), 0), complex(0, math.Inf(-1))} {
+		_, err := NewConfigurableEncoder(WithNaNPolicy(NaNError)).Encode(c)
+		assert.ErrorIs(t, err, errNonFiniteFloat)
+		_, err = NewConfigurableEncoder(WithNaNPolicy(NaNError)).Encode(spectrum{Load: impedance(c)})
+		assert.ErrorIs(t, err, errNonFiniteFloat)
+
+		data, err := NewConfigurableEncoder(WithNaNPolicy(NaNNull)).Encode(map[string]any{"c": c})
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"c": nil}, decoded)
+	}
+}
+
+func TestComplexFields(t *testing.T) {
+	s := spectrum{
+		Peak:    3 - 4i,
+		Bins:    []complex64{1, 1i, -0.5 + 0.25i},
+		Poles:   map[string]complex128{"p1": -1 + 1i},
+		Load:    50 + 10i,
+		Untyped: 2i,
+	}
+
+	data, err := Marshal(s)
+	require.NoError(t, err)
+
+	var decoded spectrum
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, s, decoded)
+
+	var generic map[string]any
+	require.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, 3-4i, generic["peak"])
+	assert.Equal(t, 50+10i, generic["load"])
+}
+
+func TestComplex64Overflow(t *testing.T) {
+	data, err := Marshal(complex(math.MaxFloat64, 0))
+	require.NoError(t, err)
+
+	var v complex64
+	assert.Error(t, Unmarshal(data, &v))
+}
+
+func TestComplexTruncated(t *testing.T) {
+	data, err := Marshal(1 + 1i)
+	require.NoError(t, err)
+
+	_, err = Decode(data[:len(data)-1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,346 @@
+//go:build crosscheck
+
+package bogo
+
+// The wire cross-check suite pins the exact bytes of every value type and
+// checks them against the layout descriptors in layout.go. Run it on each
+// platform a port targets, in particular big-endian ones:
+//
+//	go test -tags crosscheck -run TestWireCrossCheck ./...
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var wireVectors = []struct {
+	name    string
+	value   any
+	encoder *Encoder
+	hex     string
+}{
+	{name: "null", value: nil, hex: "0000"},
+	{name: "true", value: true, hex: "0001"},
+	{name: "false", value: false, hex: "0002"},
+	{name: "string", value: "hi", hex: "00030102 6869"},
+	{name: "byte", value: byte(7), hex: "000407"},
+	{name: "int", value: int64(-2), hex: "00050103"},
+	{name: "uint", value: uint64(300), hex: "000602ac02"},
+	{name: "float without mantissa", value: 1.0, hex: "000702ff03"},
+	{name: "float with mantissa", value: 1.5, hex: "00070aff03 80808080808080 04"},
+	{name: "blob", value: []byte{0xde, 0xad}, hex: "00080102dead"},
+	{name: "timestamp", value: time.UnixMilli(0x0102030405060708), hex: "00090807060504030201"},
+	{name: "date", value: Date{Year: 2024, Month: time.March, Day: 14}, hex: "000de807030e"},
+	{name: "time of day", value: TimeOfDay{Hour: 1, Minute: 2, Second: 3, Nanosecond: 0x04050607}, hex: "000e01020307060504"},
+	{name: "range", value: ClosedRange(int64(1), int64(2)), hex: "000f03 05 0102 05 0104"},
+	{name: "untyped list", value: []any{true, nil}, hex: "000a01020100"},
+	{name: "typed list", value: []int64{1, 2}, encoder: NewConfigurableEncoder(WithCompactLists(true)), hex: "000b0107 05 0102 0102 0104"},
+	{name: "object", value: map[string]any{"a": true}, hex: "000c01050103016101"},
+	{name: "map", value: map[int64]bool{-1: true}, hex: "00140105 05 050101 01"},
+	{name: "object list", value: []struct {
+		A bool `json:"a"`
+	}{{true}, {false}}, encoder: NewConfigurableEncoder(WithObjectLists(true)), hex: "00150108 0101 0102 0161 01 02"},
+	{name: "zoned timestamp", value: time.UnixMilli(1).In(time.FixedZone("CET", 3600)), encoder: NewConfigurableEncoder(WithTimeFormat(TimeFormatZoned)), hex: "0016 0100000000000000 100e0000 0103 434554"},
+	{name: "extension", value: Ext{ID: 3, Data: []byte{0xab}}, hex: "0017 03 0101 ab"},
+	{name: "decimal", value: Decimal{Unscaled: 1230, Scale: 2}, hex: "0019 0104 029c13"},
+	{name: "expiring", value: Expiring{Value: true, Expiry: ExpiryAt(time.UnixMilli(1))}, hex: "001a 0100000000000000 01"},
+	{name: "symbols", value: []testStatus{statusActive, statusActive}, hex: "001b 0109 030106616374697665 0a0106 1c0100 1c0100"},
+	{name: "union", value: Union{Name: "a", Value: true}, hex: "001d 0101 61 01"},
+	{name: "numeric", value: int16(-2), encoder: NewConfigurableEncoder(WithNumericWidths(true)), hex: "001e 03 0501 03"},
+	{name: "keyed object", value: map[string]any{"a": true}, encoder: NewConfigurableEncoder(WithKeyTable(true)), hex: "001b 0104 03010161 1f0104 1c0100 01"},
+	{name: "timestamp nanos", value: time.Unix(0, 1), encoder: NewConfigurableEncoder(WithTimeFormat(TimeFormatNanos)), hex: "0020 0100000000000000"},
+	{name: "fixuint", value: uint64(5), encoder: NewConfigurableEncoder(WithCompactIntegers(true)), hex: "00a5"},
+	{name: "fixint", value: int64(5), encoder: NewConfigurableEncoder(WithCompactIntegers(true)), hex: "00e5"},
+	{name: "fixstr", value: "hi", encoder: NewConfigurableEncoder(WithCompactStrings(true)), hex: "00c26869"},
+}
+
+func TestWireCrossCheck(t *testing.T) {
+	t.Run("byte order", func(t *testing.T) {
+		assert.Equal(t, binary.LittleEndian, WireByteOrder())
+
+		var buf [8]byte
+		WireByteOrder().PutUint64(buf[:], 0x0102030405060708)
+		assert.Equal(t, []byte{8, 7, 6, 5, 4, 3, 2, 1}, buf[:])
+	})
+
+	for _, v := range wireVectors {
+		t.Run(v.name, func(t *testing.T) {
+			want, err := hex.DecodeString(stripSpaces(v.hex))
+			require.NoError(t, err)
+
+			encoder := v.encoder
+			if encoder == nil {
+				encoder = NewConfigurableEncoder()
+			}
+			data, err := encoder.Encode(v.value)
+			require.NoError(t, err)
+			assert.Equal(t, want, data, "got %x", data)
+
+			n, err := walkWire(want[1:])
+			require.NoError(t, err)
+			assert.Equal(t, len(want)-1, n, "layout does not cover the encoding")
+
+			_, err = Decode(want)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("chunked list", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeSeq(&buf, slices.Values([]any{true, nil})))
+		assert.Equal(t, stripSpaces("0018 0102 0100 0100"), hex.EncodeToString(buf.Bytes()))
+
+		n, err := walkWire(buf.Bytes()[1:])
+		require.NoError(t, err)
+		assert.Equal(t, buf.Len()-1, n, "layout does not cover the encoding")
+	})
+
+	t.Run("layouts cover encoded documents", func(t *testing.T) {
+		doc := map[string]any{
+			"name":   "bogo",
+			"count":  int64(-12345),
+			"size":   uint64(math.MaxUint64),
+			"ratio":  math.Pi,
+			"tags":   []string{"a", "bb"},
+			"nested": map[string]any{"list": []any{1, "x", []byte{1}, nil}},
+			"when":   time.UnixMilli(-1),
+		}
+		for _, encoder := range []*Encoder{
+			NewConfigurableEncoder(),
+			NewConfigurableEncoder(WithCompactLists(true), WithCompactIntegers(true), WithCompactStrings(true)),
+		} {
+			data, err := encoder.Encode(doc)
+			require.NoError(t, err)
+
+			n, err := walkWire(data[1:])
+			require.NoError(t, err)
+			assert.Equal(t, len(data)-1, n)
+		}
+	})
+}
+
+func stripSpaces(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' {
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
+
+// walkWire consumes the value at the start of data using only the layout
+// descriptors and returns the number of bytes it occupies
+func walkWire(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, errors.New("empty value")
+	}
+	layout, ok := LayoutOf(Type(data[0]))
+	if !ok {
+		return 0, fmt.Errorf("no layout for type %#x", data[0])
+	}
+
+	pos := 1
+	var size int
+	need := func(n int) error {
+		if pos+n > len(data) {
+			return fmt.Errorf("%s: need %d bytes at %d, have %d", layout.Type, n, pos, len(data)-pos)
+		}
+		return nil
+	}
+
+	for _, f := range layout.Fields {
+		switch f.Encoding {
+		case WireFixed, WireFixedSigned:
+			if err := need(f.Size); err != nil {
+				return 0, err
+			}
+			pos += f.Size
+
+		case WireUvarint, WireVarint, WireFloat:
+			if err := need(1); err != nil {
+				return 0, err
+			}
+			n := int(data[pos])
+			pos++
+			if err := need(n); err != nil {
+				return 0, err
+			}
+			field := data[pos : pos+n]
+			pos += n
+
+			switch f.Encoding {
+			case WireUvarint:
+				v, m := binary.Uvarint(field)
+				if m != n {
+					return 0, fmt.Errorf("%s.%s: uvarint spans %d of %d bytes", layout.Type, f.Name, m, n)
+				}
+				size = int(v)
+			case WireVarint:
+				if _, m := binary.Varint(field); m != n {
+					return 0, fmt.Errorf("%s.%s: varint spans %d of %d bytes", layout.Type, f.Name, m, n)
+				}
+			case WireFloat:
+				if n < 2 {
+					return 0, fmt.Errorf("%s.%s: float needs 2 bytes, has %d", layout.Type, f.Name, n)
+				}
+				if n > 2 {
+					if _, m := binary.Uvarint(field[2:]); m != n-2 {
+						return 0, fmt.Errorf("%s.%s: mantissa spans %d of %d bytes", layout.Type, f.Name, m, n-2)
+					}
+				}
+			}
+
+		case WireBytes, WireTypedElements:
+			n := f.Size
+			if n == 0 {
+				n = size
+			}
+			if err := need(n); err != nil {
+				return 0, err
+			}
+			pos += n
+
+		case WireValue:
+			n, err := walkWire(data[pos:])
+			if err != nil {
+				return 0, err
+			}
+			pos += n
+
+		case WireValues, WireEntries, WireKeyedValues:
+			if err := need(size); err != nil {
+				return 0, err
+			}
+			end := pos + size
+			if f.Encoding == WireKeyedValues {
+				if size == 0 {
+					return 0, fmt.Errorf("%s.%s: missing key type", layout.Type, f.Name)
+				}
+				pos++
+			}
+			for pos < end {
+				var n int
+				var err error
+				if f.Encoding != WireEntries {
+					n, err = walkWire(data[pos:end])
+				} else {
+					n, err = walkEntry(data[pos:end])
+				}
+				if err != nil {
+					return 0, err
+				}
+				pos += n
+			}
+
+		case WireRows:
+			if err := need(size); err != nil {
+				return 0, err
+			}
+			end := pos + size
+			var counts [2]uint64
+			for i := range counts {
+				if pos >= end || pos+1+int(data[pos]) > end {
+					return 0, fmt.Errorf("%s.%s: insufficient data for counts", layout.Type, f.Name)
+				}
+				n := int(data[pos])
+				v, m := binary.Uvarint(data[pos+1 : pos+1+n])
+				if m != n {
+					return 0, fmt.Errorf("%s.%s: uvarint spans %d of %d bytes", layout.Type, f.Name, m, n)
+				}
+				counts[i] = v
+				pos += 1 + n
+			}
+			for i := uint64(0); i < counts[0]; i++ {
+				if pos >= end || pos+1+int(data[pos]) > end {
+					return 0, fmt.Errorf("%s.%s: insufficient data for key %d", layout.Type, f.Name, i)
+				}
+				pos += 1 + int(data[pos])
+			}
+			for i := uint64(0); i < counts[0]*counts[1]; i++ {
+				n, err := walkWire(data[pos:end])
+				if err != nil {
+					return 0, err
+				}
+				pos += n
+			}
+			if pos != end {
+				return 0, fmt.Errorf("%s.%s: rows span %d of %d bytes", layout.Type, f.Name, size-(end-pos), size)
+			}
+
+		case WireChunks:
+			for {
+				if err := need(1); err != nil {
+					return 0, err
+				}
+				n := int(data[pos])
+				if err := need(1 + n); err != nil {
+					return 0, err
+				}
+				v, m := binary.Uvarint(data[pos+1 : pos+1+n])
+				if m != n {
+					return 0, fmt.Errorf("%s.%s: uvarint spans %d of %d bytes", layout.Type, f.Name, m, n)
+				}
+				pos += 1 + n
+				if v == 0 {
+					break
+				}
+				if err := need(int(v)); err != nil {
+					return 0, err
+				}
+				for end := pos + int(v); pos < end; {
+					n, err := walkWire(data[pos:end])
+					if err != nil {
+						return 0, err
+					}
+					pos += n
+				}
+			}
+
+		default:
+			return 0, fmt.Errorf("%s.%s: unknown encoding %s", layout.Type, f.Name, f.Encoding)
+		}
+	}
+
+	return pos, nil
+}
+
+// walkEntry consumes one object field entry
+func walkEntry(data []byte) (int, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return 0, errors.New("entry: insufficient data for size")
+	}
+	sizeLen := int(data[0])
+	entrySize, m := binary.Uvarint(data[1 : 1+sizeLen])
+	if m != sizeLen {
+		return 0, errors.New("entry: malformed size")
+	}
+	start := 1 + sizeLen
+	end := start + int(entrySize)
+	if end > len(data) || start >= end {
+		return 0, errors.New("entry: insufficient data for content")
+	}
+
+	keyEnd := start + 1 + int(data[start])
+	if keyEnd >= end {
+		return 0, errors.New("entry: insufficient data for key and value")
+	}
+	n, err := walkWire(data[keyEnd:end])
+	if err != nil {
+		return 0, err
+	}
+	if keyEnd+n != end {
+		return 0, fmt.Errorf("entry: value spans %d of %d bytes", n, end-keyEnd)
+	}
+	return end, nil
+}
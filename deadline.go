@@ -0,0 +1,56 @@
+package bogo
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDecodeDeadline is returned when a decode runs past the decoder's
+// DecodeDeadline
+var ErrDecodeDeadline = errors.New("bogo: decode deadline exceeded")
+
+// deadlineCheckInterval is the number of values decoded between readings of
+// the clock
+const deadlineCheckInterval = 64
+
+// WithDecodeDeadline bounds the wall-clock time a single Decode or Unmarshal
+// may spend decoding. Decodes running past budget, such as deeply nested or
+// very long documents, stop and fail with ErrDecodeDeadline, protecting
+// latency objectives from worst-case payloads without threading a context.
+// The clock is read every few values, so a decode may overrun the budget by
+// the time it takes to decode them; assigning decoded values to Go types is
+// not bounded.
+func WithDecodeDeadline(budget time.Duration) DecoderOption {
+	return func(d *Decoder) {
+		d.DecodeDeadline = budget
+	}
+}
+
+// decodeDeadline tracks the deadline of the decode in progress
+type decodeDeadline struct {
+	at       time.Time
+	values   int
+	exceeded bool
+}
+
+// check counts a decoded value and fails once the deadline has passed
+func (dl *decodeDeadline) check() error {
+	if dl == nil {
+		return nil
+	}
+	dl.values++
+	if dl.values%deadlineCheckInterval == 0 && time.Now().After(dl.at) {
+		dl.exceeded = true
+		return ErrDecodeDeadline
+	}
+	return nil
+}
+
+// deadlineErr returns ErrDecodeDeadline in place of err when err comes from
+// the deadline, which errors of nested values do not keep in their chain
+func (d *Decoder) deadlineErr(err error) error {
+	if err != nil && d.deadline.exceeded {
+		return ErrDecodeDeadline
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeDeadline(t *testing.T) {
+	items := make([]any, 10000)
+	for i := range items {
+		items[i] = map[string]any{"id": int64(i), "tags": []any{"a", int64(i)}}
+	}
+	data, err := Marshal(map[string]any{"items": items})
+	require.NoError(t, err)
+
+	t.Run("fails past the budget", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithDecodeDeadline(time.Nanosecond))
+		_, err := decoder.Decode(data)
+		assert.ErrorIs(t, err, ErrDecodeDeadline)
+
+		var v struct {
+			Items []map[string]any `json:"items"`
+		}
+		assert.ErrorIs(t, decoder.Unmarshal(data, &v), ErrDecodeDeadline)
+	})
+
+	t.Run("bounds structure checks", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithDecodeDeadline(time.Nanosecond), WithTrailingBytesRejection(true))
+		_, err := decoder.Decode(data)
+		assert.ErrorIs(t, err, ErrDecodeDeadline)
+	})
+
+	t.Run("restarts with every decode", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithDecodeDeadline(time.Minute))
+		for range 3 {
+			decoded, err := decoder.Decode(data)
+			require.NoError(t, err)
+			assert.Len(t, decoded.(map[string]any)["items"], len(items))
+		}
+	})
+
+	t.Run("small documents finish before the clock is read", func(t *testing.T) {
+		small, err := Marshal(map[string]any{"id": int64(1)})
+		require.NoError(t, err)
+		decoded, err := NewConfigurableDecoder(WithDecodeDeadline(time.Nanosecond)).Decode(small)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": int64(1)}, decoded)
+	})
+}
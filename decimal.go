@@ -0,0 +1,199 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var decimalErr = errors.New("bogo decimal error")
+
+// maxDecimalScale bounds the scale of decimals in both directions, which
+// keeps the digits written by Decimal.String in proportion to the data
+const maxDecimalScale = 1024
+
+// A decimal is laid out as
+//
+//	TypeDecimal | scale | unscaled
+//
+// where both are signed varints preceded by their 1-byte length, as in
+// TypeInt. Its value is unscaled × 10^-scale, so 12.30 is 1230 with scale 2.
+
+// Decimal is an exact decimal number, Unscaled × 10^-Scale, for values such as
+// amounts of money that floats cannot represent. The scale is kept as is:
+// 12.30 and 12.3 encode differently and decode back to the scale they were
+// written with. A negative scale stands for trailing zeros, so 1200 may be
+// written as 12 with scale -2.
+type Decimal struct {
+	Unscaled int64
+	Scale    int32
+}
+
+var decimalType = reflect.TypeFor[Decimal]()
+
+// DecimalValue is implemented by decimal types from other packages, such as
+// github.com/shopspring/decimal.Decimal, whose value is
+// Coefficient × 10^Exponent. Marshal encodes them as decimals, and decimals
+// are decoded into them through their UnmarshalText method.
+type DecimalValue interface {
+	Coefficient() *big.Int
+	Exponent() int32
+}
+
+var decimalValueType = reflect.TypeFor[DecimalValue]()
+
+// DecimalFrom converts v to a Decimal. It fails when the coefficient of v
+// does not fit an int64 or its exponent is out of range.
+func DecimalFrom(v DecimalValue) (Decimal, error) {
+	coefficient := v.Coefficient()
+	if !coefficient.IsInt64() {
+		return Decimal{}, wrapError(decimalErr, fmt.Sprintf("coefficient %s overflows int64", coefficient))
+	}
+	if v.Exponent() == math.MinInt32 {
+		return Decimal{}, wrapError(decimalErr, fmt.Sprintf("exponent %d out of range", v.Exponent()))
+	}
+	d := Decimal{Unscaled: coefficient.Int64(), Scale: -v.Exponent()}
+	return d, d.check()
+}
+
+// ParseDecimal parses s, such as "-12.30", keeping the digits after the point
+// as the scale
+func ParseDecimal(s string) (Decimal, error) {
+	digits, negative := s, false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" && fraction == "" || hasPoint && fraction == "" ||
+		strings.TrimLeft(whole+fraction, "0123456789") != "" {
+		return Decimal{}, wrapError(decimalErr, fmt.Sprintf("invalid decimal %q", s))
+	}
+	if negative {
+		whole = "-" + whole
+	}
+	unscaled, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Decimal{}, wrapError(decimalErr, fmt.Sprintf("decimal %q overflows int64", s))
+	}
+	d := Decimal{Unscaled: unscaled, Scale: int32(len(fraction))}
+	return d, d.check()
+}
+
+// String returns d in decimal notation with Scale digits after the point,
+// such as "12.30"
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.Unscaled, 10)
+	sign := ""
+	if d.Unscaled < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	scale := int(d.Scale)
+	switch {
+	case scale <= 0:
+		if d.Unscaled == 0 {
+			return "0"
+		}
+		return sign + digits + strings.Repeat("0", -scale)
+	case len(digits) <= scale:
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// Rat returns d as a rational number
+func (d Decimal) Rat() *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(d.Scale, -d.Scale))), nil)
+	r := new(big.Rat).SetInt64(d.Unscaled)
+	if d.Scale < 0 {
+		return r.Mul(r, new(big.Rat).SetInt(pow))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(pow))
+}
+
+// MarshalText writes d as String does
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText parses text as ParseDecimal does
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// check fails for scales beyond maxDecimalScale
+func (d Decimal) check() error {
+	if d.Scale > maxDecimalScale || d.Scale < -maxDecimalScale {
+		return wrapError(decimalErr, fmt.Sprintf("scale %d out of range", d.Scale))
+	}
+	return nil
+}
+
+func encodeDecimal(d Decimal) ([]byte, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	scale, err := encodeInt(int64(d.Scale))
+	if err != nil {
+		return nil, err
+	}
+	unscaled, err := encodeInt(d.Unscaled)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(scale)+len(unscaled)-1)
+	buf = append(buf, byte(TypeDecimal))
+	buf = append(buf, scale[1:]...)
+	return append(buf, unscaled[1:]...), nil
+}
+
+// encodeDecimalValue encodes v as a decimal when it implements DecimalValue
+func encodeDecimalValue(v any) ([]byte, bool, error) {
+	dv, ok := v.(DecimalValue)
+	if !ok {
+		return nil, false, nil
+	}
+	d, err := DecimalFrom(dv)
+	if err != nil {
+		return nil, true, err
+	}
+	data, err := encodeDecimal(d)
+	return data, true, err
+}
+
+// decimalFields reads the decimal at the start of data, after its type byte,
+// returning it and its encoded size
+func decimalFields(data []byte) (Decimal, int, error) {
+	var fields [2]int64
+	pos := 0
+	for i := range fields {
+		if pos >= len(data) || pos+1+int(data[pos]) > len(data) {
+			return Decimal{}, 0, wrapError(decimalErr, "insufficient data")
+		}
+		sizeLen := int(data[pos])
+		v, err := decodeInt(data[pos+1 : pos+1+sizeLen])
+		if err != nil {
+			return Decimal{}, 0, wrapError(decimalErr, err.Error())
+		}
+		fields[i] = v
+		pos += 1 + sizeLen
+	}
+	if fields[0] > maxDecimalScale || fields[0] < -maxDecimalScale {
+		return Decimal{}, 0, wrapError(decimalErr, fmt.Sprintf("scale %d out of range", fields[0]))
+	}
+	return Decimal{Unscaled: fields[1], Scale: int32(fields[0])}, pos, nil
+}
+
+func decodeDecimal(data []byte) (Decimal, error) {
+	d, _, err := decimalFields(data)
+	return d, err
+}
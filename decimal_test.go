@@ -0,0 +1,134 @@
+package bogo
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// money mimics the API of shopspring's decimal.Decimal
+type money struct {
+	value *big.Int
+	exp   int32
+}
+
+func (m money) Coefficient() *big.Int { return new(big.Int).Set(m.value) }
+func (m money) Exponent() int32       { return m.exp }
+
+func (m *money) UnmarshalText(text []byte) error {
+	d, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	m.value, m.exp = big.NewInt(d.Unscaled), -d.Scale
+	return nil
+}
+
+func TestDecimal(t *testing.T) {
+	for _, d := range []Decimal{
+		{Unscaled: 1230, Scale: 2},
+		{Unscaled: -5, Scale: 3},
+		{Unscaled: 12, Scale: -2},
+		{},
+		{Unscaled: math.MinInt64, Scale: 18},
+	} {
+		data, err := Marshal(d)
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeDecimal), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, d, decoded)
+
+		decoded, err = NewConfigurableDecoder().Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, d, decoded)
+
+		parsed, err := ParseDecimal(d.String())
+		require.NoError(t, err)
+		assert.Equal(t, 0, parsed.Rat().Cmp(d.Rat()), d.String())
+	}
+
+	t.Run("strings", func(t *testing.T) {
+		tests := map[Decimal]string{
+			{Unscaled: 1230, Scale: 2}: "12.30",
+			{Unscaled: -5, Scale: 3}:   "-0.005",
+			{Unscaled: 12, Scale: -2}:  "1200",
+			{Unscaled: 7}:              "7",
+			{Scale: -3}:                "0",
+		}
+		for d, want := range tests {
+			assert.Equal(t, want, d.String())
+		}
+
+		d, err := ParseDecimal("-0.50")
+		require.NoError(t, err)
+		assert.Equal(t, Decimal{Unscaled: -50, Scale: 2}, d)
+		for _, bad := range []string{"", ".", "1.", "1e3", "1.2.3", "99999999999999999999"} {
+			_, err := ParseDecimal(bad)
+			assert.Error(t, err, bad)
+		}
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		type invoice struct {
+			Total Decimal   `json:"total"`
+			Lines []Decimal `json:"lines"`
+			Tax   *Decimal  `json:"tax"`
+		}
+		in := invoice{
+			Total: Decimal{Unscaled: 1999, Scale: 2},
+			Lines: []Decimal{{Unscaled: 999, Scale: 2}, {Unscaled: 1000, Scale: 2}},
+			Tax:   &Decimal{Unscaled: 15, Scale: 2},
+		}
+		data, err := Marshal(in)
+		require.NoError(t, err)
+		var out invoice
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, in, out)
+	})
+
+	t.Run("decimal types of other packages", func(t *testing.T) {
+		price := money{value: big.NewInt(-4250), exp: -3}
+		data, err := Marshal(map[string]any{"price": price})
+		require.NoError(t, err)
+
+		var generic map[string]any
+		require.NoError(t, Unmarshal(data, &generic))
+		assert.Equal(t, Decimal{Unscaled: -4250, Scale: 3}, generic["price"])
+
+		var out struct {
+			Price money `json:"price"`
+		}
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, price, out.Price)
+
+		huge := money{value: new(big.Int).Lsh(big.NewInt(1), 70)}
+		_, err = Marshal(huge)
+		assert.ErrorContains(t, err, "overflows int64")
+	})
+
+	t.Run("scale out of range", func(t *testing.T) {
+		_, err := Marshal(Decimal{Unscaled: 1, Scale: maxDecimalScale + 1})
+		assert.ErrorContains(t, err, "out of range")
+
+		scale, err := encodeInt(math.MaxInt32)
+		require.NoError(t, err)
+		unscaled, err := encodeInt(1)
+		require.NoError(t, err)
+		data := append(append([]byte{Version, byte(TypeDecimal)}, scale[1:]...), unscaled[1:]...)
+		_, err = Decode(data)
+		assert.ErrorContains(t, err, "out of range")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		data := mustMarshal(t, Decimal{Unscaled: 1230, Scale: 2})
+		for i := 2; i < len(data); i++ {
+			_, err := Decode(data[:i])
+			assert.Error(t, err, "truncated at %d", i)
+		}
+	})
+}
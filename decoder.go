@@ -3,22 +3,47 @@ package bogo
 import (
 	"fmt"
 	"io"
+	"sync"
+	"time"
 )
 
 // Decoder provides structured decoding with configurable options
 type Decoder struct {
 	// Configuration options
-	MaxDepth          int      // Maximum nesting depth for objects/lists (0 = unlimited)
-	StrictMode        bool     // Strict type checking and validation
-	AllowUnknownTypes bool     // Allow unknown type IDs for forward compatibility
-	MaxObjectSize     int64    // Maximum size for objects/lists (0 = unlimited)
-	ValidateUTF8      bool     // Validate UTF-8 encoding in strings
-	TagName           string   // Struct tag name to use (default: "json" for compatibility)
-	SelectiveFields   []string // List of specific fields to decode (optimization)
+	MaxDepth            int                 // Maximum nesting depth for objects/lists (0 = unlimited)
+	StrictMode          bool                // Strict type checking and validation
+	AllowUnknownTypes   bool                // Allow unknown type IDs for forward compatibility
+	MaxObjectSize       int64               // Maximum size for objects/lists (0 = unlimited)
+	ValidateUTF8        bool                // Validate UTF-8 encoding in strings
+	TagName             string              // Struct tag name to use (default: "json" for compatibility)
+	SelectiveFields     []string            // List of specific fields to decode (optimization)
+	TimeFormat          TimeFormat          // How integer timestamps are interpreted when assigning to time.Time
+	ZeroTimePolicy      ZeroTimePolicy      // Reverse mapping for zero times written by the encoder
+	Locales             []string            // Preferred locales when decoding into LocalizedString
+	TypedValues         bool                // Wrap decoded values in TypedValue to expose their wire types
+	FieldProfiling      FieldProfiling      // Per-path profiling used by DecoderStatsCollector
+	WarnHandler         func(WarnEvent)     // Observes degraded paths tolerated in non-strict mode
+	MemoryCeiling       int64               // Maximum bytes a single Decode may allocate (0 = unlimited)
+	MaxElements         int                 // Maximum entries per object or elements per list (0 = unlimited)
+	RejectDuplicateKeys bool                // Fail on objects that repeat a key
+	RejectTrailingBytes bool                // Fail on bytes after the encoded value
+	CollectErrors       bool                // Report every field that fails to unmarshal instead of the first
+	Salvage             bool                // Skip corrupt object entries instead of failing the decode
+	LazyObjects         bool                // Return the top-level object as a *LazyObject
+	RequireMagic        bool                // Reject documents without the Magic or envelope prefix
+	StringInterner      func([]byte) string // Builds decoded strings and keys, e.g. to share duplicates
+	KeyCache            *KeyCache           // Object keys shared across decodes, see WithKeyCache
+	DecodeDeadline      time.Duration       // Wall-clock budget of a single decode (0 = unlimited)
+	Framing             Framing             // How StreamDecoder expects documents to be framed (default: Raw)
+	UseNumber           bool                // Decode numbers into interface values as Number
 
 	// Internal state
 	depth          int
 	bytesProcessed int64
+	subscriptions  map[string][]func(any) // OnField callbacks used by Scan
+	deadline       decodeDeadline
+	symbols        []string       // table of the enclosing TypeSymbols
+	coercions      []pathCoercion // added by WithPathCoercion
 }
 
 // DecoderOption is a function type for configuring a Decoder
@@ -90,10 +115,114 @@ func WithSelectiveFields(fields []string) DecoderOption {
 	}
 }
 
+// WithDecoderTimeFormat sets how integer values are interpreted when assigned to
+// time.Time destinations, as in documents written with TimeFormatNanos before
+// TypeTimestampNanos. Timestamp types and RFC 3339 strings are read regardless
+// of this setting.
+func WithDecoderTimeFormat(format TimeFormat) DecoderOption {
+	return func(d *Decoder) {
+		d.TimeFormat = format
+	}
+}
+
+// WithDecoderZeroTimePolicy sets the reverse mapping for zero times. With
+// ZeroTimeAsEpoch, epoch timestamps are assigned to time.Time fields as time.Time{}.
+// Null values always leave time.Time fields at their zero value.
+func WithDecoderZeroTimePolicy(policy ZeroTimePolicy) DecoderOption {
+	return func(d *Decoder) {
+		d.ZeroTimePolicy = policy
+	}
+}
+
+// WithLocales sets the preferred locales, in order, used to pick a translation
+// when a locale object is decoded into a LocalizedString.
+func WithLocales(locales ...string) DecoderOption {
+	return func(d *Decoder) {
+		d.Locales = locales
+	}
+}
+
+// WithTypedValues makes the decoder return every value wrapped in a TypedValue
+// carrying its wire type, so that e.g. uint64(5) and int64(5) remain
+// distinguishable after decoding into any.
+func WithTypedValues(enabled bool) DecoderOption {
+	return func(d *Decoder) {
+		d.TypedValues = enabled
+	}
+}
+
+// WithUseNumber makes the decoder deliver integers and floats decoded into
+// interface values as a Number instead of an int64, uint64 or float64, so the
+// caller decides how to convert them. Numbers assigned to numeric struct
+// fields, slices and maps are converted as usual. Typed lists keep their
+// Go slice type.
+func WithUseNumber(enabled bool) DecoderOption {
+	return func(d *Decoder) {
+		d.UseNumber = enabled
+	}
+}
+
+// WithStringInterner makes the decoder build decoded strings and object keys
+// by calling intern instead of copying the bytes. Returning a shared
+// string for repeated values collapses duplicates such as status fields into
+// one allocation:
+//
+//	bogo.WithStringInterner(func(b []byte) string {
+//		return unique.Make(string(b)).Value()
+//	})
+//
+// intern must not retain b, which refers to the data being decoded, and must be
+// safe for concurrent use if the decoder is.
+func WithStringInterner(intern func([]byte) string) DecoderOption {
+	return func(d *Decoder) {
+		d.StringInterner = intern
+	}
+}
+
+// WithKeyCache gives the decoder a cache of up to max object keys, so that
+// keys repeated across documents, such as "username" or "timestamp" in a
+// stream of similar records, are allocated once for the decoder's lifetime
+// rather than once per object. Keys beyond max are allocated as usual, which
+// bounds the memory untrusted input can pin. Copies of the decoder, such as
+// those of a DecoderPool, share the cache.
+func WithKeyCache(max int) DecoderOption {
+	return func(d *Decoder) {
+		d.KeyCache = NewKeyCache(max)
+	}
+}
+
+// strings returns the builder of decoded strings and keys
+func (d *Decoder) strings() stringMaker {
+	m := stringMaker{intern: d.StringInterner, keys: d.KeyCache, numbers: d.UseNumber, symbols: d.symbols}
+	if d.DecodeDeadline > 0 {
+		m.deadline = &d.deadline
+	}
+	return m
+}
+
 // Decode decodes data using the configured decoder
 func (d *Decoder) Decode(data []byte) (any, error) {
+	data, err := d.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodePrepared(data)
+}
+
+// prepare resets the decoder and checks data before decoding, returning the
+// document from its version byte on
+func (d *Decoder) prepare(data []byte) ([]byte, error) {
 	d.depth = 0          // Reset depth counter
 	d.bytesProcessed = 0 // Reset bytes counter
+	d.deadline = decodeDeadline{}
+	if d.DecodeDeadline > 0 {
+		d.deadline.at = time.Now().Add(d.DecodeDeadline)
+	}
+
+	data, err := d.negotiate(data)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(data) < 2 {
 		return nil, fmt.Errorf("bogo decode error: insufficient data, need at least 2 bytes for version and type")
@@ -103,12 +232,33 @@ func (d *Decoder) Decode(data []byte) (any, error) {
 	version := data[0]
 	if version != Version {
 		if d.StrictMode {
-			return nil, fmt.Errorf("bogo decode error: unsupported version %d, expected version %d", version, Version)
+			return nil, fmt.Errorf("bogo decode error: %w", versionMismatch(version))
 		}
 		// In non-strict mode, try to decode anyway (forward compatibility)
+		d.warnVersion(version)
+	}
+
+	if err := d.verifyStructure(data); err != nil {
+		return nil, d.deadlineErr(err)
+	}
+
+	if err := d.checkMemoryCeiling(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodePrepared decodes a document checked by prepare
+func (d *Decoder) decodePrepared(data []byte) (any, error) {
+	if d.TypedValues {
+		return decodeTypedValue(data[1:], stringMaker{})
 	}
 
-	return d.decode(data[1:]) // Skip version byte
+	v, err := d.decode(data[1:]) // Skip version byte
+	if err == nil && len(d.coercions) > 0 {
+		v, err = d.coerce(v)
+	}
+	return v, d.deadlineErr(err)
 }
 
 // DecodeFrom decodes data from an io.Reader
@@ -127,6 +277,12 @@ func (d *Decoder) decode(data []byte) (any, error) {
 		return nil, fmt.Errorf("bogo decode error: insufficient data for type")
 	}
 
+	if d.DecodeDeadline > 0 {
+		if err := d.deadline.check(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check max depth
 	if d.MaxDepth > 0 && d.depth > d.MaxDepth {
 		return nil, fmt.Errorf("bogo decode error: maximum nesting depth exceeded (%d)", d.MaxDepth)
@@ -142,6 +298,16 @@ func (d *Decoder) decode(data []byte) (any, error) {
 	// Track processed bytes
 	d.bytesProcessed += int64(len(data))
 
+	if v, ok, err := decodeCompactWith(data, d.strings()); ok {
+		if err != nil {
+			return nil, err
+		}
+		if str, isStr := v.(string); isStr && d.ValidateUTF8 && !isValidUTF8(str) {
+			return nil, fmt.Errorf("bogo decode error: invalid UTF-8 in string")
+		}
+		return v, nil
+	}
+
 	switch typeVal {
 	case TypeNull:
 		return nil, nil
@@ -161,7 +327,7 @@ func (d *Decoder) decode(data []byte) (any, error) {
 			return nil, fmt.Errorf("bogo decode error: insufficient data for string size info")
 		}
 
-		result, err := decodeString(data[2:], sizeLen)
+		result, err := decodeStringWith(data[2:], sizeLen, d.strings())
 		if err != nil {
 			return nil, err
 		}
@@ -187,7 +353,7 @@ func (d *Decoder) decode(data []byte) (any, error) {
 		if len(data) < 2+sizeLen {
 			return nil, fmt.Errorf("bogo decode error: insufficient data for int")
 		}
-		return decodeInt(data[2 : 2+sizeLen])
+		return d.strings().number(decodeInt(data[2 : 2+sizeLen]))
 
 	case TypeUint:
 		if len(data) < 2 {
@@ -197,7 +363,7 @@ func (d *Decoder) decode(data []byte) (any, error) {
 		if len(data) < 2+sizeLen {
 			return nil, fmt.Errorf("bogo decode error: insufficient data for uint")
 		}
-		return decodeUint(data[2 : 2+sizeLen])
+		return d.strings().number(decodeUint(data[2 : 2+sizeLen]))
 
 	case TypeFloat:
 		if len(data) < 2 {
@@ -207,7 +373,7 @@ func (d *Decoder) decode(data []byte) (any, error) {
 		if len(data) < 2+sizeLen {
 			return nil, fmt.Errorf("bogo decode error: insufficient data for float")
 		}
-		return decodeFloat(data[2 : 2+sizeLen])
+		return d.strings().number(decodeFloat(data[2 : 2+sizeLen]))
 
 	case TypeBlob:
 		return d.decodeBlobSafe(data[1:])
@@ -215,6 +381,63 @@ func (d *Decoder) decode(data []byte) (any, error) {
 	case TypeTimestamp:
 		return d.decodeTimestampSafe(data[1:])
 
+	case TypeDate:
+		return decodeDate(data[1:])
+
+	case TypeTimeOfDay:
+		return decodeTimeOfDay(data[1:])
+
+	case TypeRange:
+		return decodeRange(data[1:])
+
+	case TypeDuration:
+		return decodeDuration(data[1:])
+
+	case TypeZonedTimestamp:
+		return decodeZonedTimestamp(data[1:])
+
+	case TypeTimestampNanos:
+		return decodeTimestampNanos(data[1:])
+
+	case TypeExt:
+		return decodeExt(data[1:])
+
+	case TypeDecimal:
+		return decodeDecimal(data[1:])
+
+	case TypeExpiring:
+		return decodeExpiringWith(data[1:], d.decode)
+
+	case TypeSymbols:
+		return d.decodeSymbols(data[1:], d.decode)
+
+	case TypeSymbol:
+		return d.strings().symbol(data[1:])
+
+	case TypeUnion:
+		return decodeUnionWith(data[1:], d.decode)
+
+	case TypeNumeric:
+		return decodeNumeric(data[1:])
+
+	case TypeKeyedObject:
+		if d.LazyObjects && len(d.SelectiveFields) == 0 {
+			return d.decodeLazyObject(data)
+		}
+		return d.decodeKeyedObject(data[1:], d.decode)
+
+	case TypeChunkedList:
+		return d.decodeChunkedListWithDepth(data[1:])
+
+	case TypeBigInt:
+		return decodeBigInt(data[1:])
+
+	case TypeBigFloat:
+		return decodeBigFloat(data[1:])
+
+	case TypeComplex:
+		return decodeComplex(data[1:])
+
 	case TypeUntypedList:
 		return d.decodeListWithDepth(data[1:])
 
@@ -222,13 +445,36 @@ func (d *Decoder) decode(data []byte) (any, error) {
 		return d.decodeTypedListSafe(data[1:])
 
 	case TypeObject:
+		if d.Salvage {
+			d.depth++
+			defer func() { d.depth-- }()
+			return d.salvageObject(data[1:], "")
+		}
 		if len(d.SelectiveFields) > 0 {
 			return d.decodeObjectSelective(data[1:])
 		}
+		if d.LazyObjects {
+			return d.decodeLazyObject(data)
+		}
 		return d.decodeObjectWithDepth(data[1:])
 
+	case TypeMap:
+		d.depth++
+		defer func() { d.depth-- }()
+		return decodeMapWith(data[1:], d.strings())
+
+	case TypeObjectList:
+		d.depth++
+		defer func() { d.depth-- }()
+		return decodeObjectListWith(data[1:], d.strings())
+
 	default:
 		if d.AllowUnknownTypes {
+			d.warn(WarnEvent{
+				Kind:    WarnUnknownType,
+				Message: fmt.Sprintf("returning unknown type %d as UnknownType", typeVal),
+				Type:    typeVal,
+			})
 			// Return a special marker for unknown types
 			return UnknownType{TypeID: typeVal, Data: data}, nil
 		}
@@ -267,16 +513,43 @@ func (d *Decoder) decodeListWithDepth(data []byte) (any, error) {
 	d.depth++
 	defer func() { d.depth-- }()
 
-	// For now, use the original list decoding (without proper depth tracking)
-	// This is a placeholder implementation
-	return nil, fmt.Errorf("bogo decode error: list decoding with depth tracking not yet implemented")
+	elements, err := containerPayload(data)
+	if err != nil {
+		return nil, fmt.Errorf("bogo decode error: %w", err)
+	}
+
+	// Decode each element with d, so its options and limits apply to them
+	result := make([]any, 0, countElements(elements))
+	for pos := 0; pos < len(elements); {
+		size, err := getElementSize(elements[pos:])
+		if err != nil {
+			return nil, fmt.Errorf("bogo decode error: %w", err)
+		}
+		if size > len(elements)-pos {
+			return nil, fmt.Errorf("bogo decode error: insufficient data for list element")
+		}
+		element, err := d.decode(elements[pos : pos+size])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, element)
+		pos += size
+	}
+	return result, nil
+}
+
+func (d *Decoder) decodeChunkedListWithDepth(data []byte) (any, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+
+	return decodeChunkedListWith(data, d.strings())
 }
 
 func (d *Decoder) decodeTypedListSafe(data []byte) (any, error) {
 	d.depth++
 	defer func() { d.depth-- }()
 
-	result, err := decodeTypedList(data)
+	result, err := decodeTypedListWith(data, d.strings())
 	if err != nil {
 		return nil, err
 	}
@@ -289,7 +562,7 @@ func (d *Decoder) decodeObjectWithDepth(data []byte) (any, error) {
 	d.depth++
 	defer func() { d.depth-- }()
 
-	obj, err := decodeObject(data)
+	obj, err := decodeObjectWith(data, d.strings())
 	if err != nil {
 		return nil, err
 	}
@@ -407,6 +680,10 @@ func (d *Decoder) decodeValueSelective(data []byte) (any, error) {
 	// Track processed bytes
 	d.bytesProcessed += int64(len(data))
 
+	if v, ok, err := decodeCompact(data); ok {
+		return v, err
+	}
+
 	// Use the existing decodeValue logic but with selective decoder context
 	switch Type(data[0]) {
 	case TypeNull:
@@ -468,9 +745,45 @@ func (d *Decoder) decodeValueSelective(data []byte) (any, error) {
 			return nil, err
 		}
 		return timestamp, nil
+	case TypeDate:
+		return decodeDate(data[1:])
+	case TypeTimeOfDay:
+		return decodeTimeOfDay(data[1:])
+	case TypeRange:
+		return decodeRange(data[1:])
+	case TypeDuration:
+		return decodeDuration(data[1:])
+	case TypeZonedTimestamp:
+		return decodeZonedTimestamp(data[1:])
+	case TypeTimestampNanos:
+		return decodeTimestampNanos(data[1:])
+	case TypeExt:
+		return decodeExt(data[1:])
+	case TypeDecimal:
+		return decodeDecimal(data[1:])
+	case TypeExpiring:
+		return decodeExpiringWith(data[1:], d.decodeValueSelective)
+	case TypeSymbols:
+		return d.decodeSymbols(data[1:], d.decodeValueSelective)
+	case TypeSymbol:
+		return d.strings().symbol(data[1:])
+	case TypeUnion:
+		return decodeUnionWith(data[1:], d.decodeValueSelective)
+	case TypeNumeric:
+		return decodeNumeric(data[1:])
+	case TypeKeyedObject:
+		return d.decodeKeyedObject(data[1:], d.decodeValueSelective)
+	case TypeChunkedList:
+		return decodeChunkedListWith(data[1:], d.strings())
+	case TypeBigInt:
+		return decodeBigInt(data[1:])
+	case TypeBigFloat:
+		return decodeBigFloat(data[1:])
+	case TypeComplex:
+		return decodeComplex(data[1:])
 	case TypeUntypedList:
 		// For selective decoding, we still decode lists normally
-		list, err := decodeListValue(data[1:])
+		list, err := decodeListValueWith(data[1:], d.strings())
 		if err != nil {
 			return nil, err
 		}
@@ -488,6 +801,10 @@ func (d *Decoder) decodeValueSelective(data []byte) (any, error) {
 			return nil, err
 		}
 		return obj, nil
+	case TypeMap:
+		return decodeMapWith(data[1:], d.strings())
+	case TypeObjectList:
+		return decodeObjectListWith(data[1:], d.strings())
 	default:
 		return nil, fmt.Errorf("bogo decode error: unsupported value type: %d", data[0])
 	}
@@ -510,12 +827,19 @@ type DecodingStats struct {
 	TypesDecoded map[Type]int
 	ErrorsCount  int64
 	UnknownTypes int64
+
+	// Per-path costs, only collected with WithFieldProfiling
+	ProfiledDecodes int64
+	Fields          map[string]FieldProfile
 }
 
 // DecoderStatsCollector is a decoder that collects statistics
 type DecoderStatsCollector struct {
 	*Decoder
 	Stats DecodingStats
+
+	mu      sync.Mutex // guards Stats against concurrent readers such as exporters
+	decodes int64      // successful decodes, used for profile sampling
 }
 
 // NewDecoderStatsCollector creates a decoder that collects decoding statistics
@@ -531,6 +855,10 @@ func NewDecoderStatsCollector(options ...DecoderOption) *DecoderStatsCollector {
 // Decode wraps the parent Decode with statistics collection
 func (dsc *DecoderStatsCollector) Decode(data []byte) (any, error) {
 	result, err := dsc.Decoder.Decode(data)
+
+	dsc.mu.Lock()
+	defer dsc.mu.Unlock()
+
 	if err != nil {
 		dsc.Stats.ErrorsCount++
 		return nil, err
@@ -543,7 +871,7 @@ func (dsc *DecoderStatsCollector) Decode(data []byte) (any, error) {
 
 	// Count type usage and unknown types
 	if len(data) >= 2 {
-		typeVal := Type(data[1])
+		typeVal := Type(data[1]).baseType()
 		dsc.Stats.TypesDecoded[typeVal]++
 
 		if _, isUnknown := result.(UnknownType); isUnknown {
@@ -551,23 +879,64 @@ func (dsc *DecoderStatsCollector) Decode(data []byte) (any, error) {
 		}
 	}
 
+	dsc.decodes++
+	if every := int64(dsc.FieldProfiling.SampleEvery); every > 0 && (dsc.decodes-1)%every == 0 {
+		dsc.profileFields(data)
+	}
+
 	return result, nil
 }
 
+// profileFields decodes data again while recording per-path costs. Profiling
+// is best effort: documents the profiler cannot walk, such as ones holding
+// unknown types, are left out of the profile without failing Decode.
+func (dsc *DecoderStatsCollector) profileFields(data []byte) {
+	p := &fieldProfiler{fields: make(map[string]FieldProfile), allocs: dsc.FieldProfiling.Allocs}
+	if _, err := p.profile(data[1:], "", nil); err != nil {
+		return
+	}
+
+	if dsc.Stats.Fields == nil {
+		dsc.Stats.Fields = make(map[string]FieldProfile)
+	}
+	for path, fp := range p.fields {
+		if total, ok := dsc.Stats.Fields[path]; ok {
+			fp.Count += total.Count
+			fp.Duration += total.Duration
+			fp.Allocs += total.Allocs
+		}
+		dsc.Stats.Fields[path] = fp
+	}
+	dsc.Stats.ProfiledDecodes++
+}
+
 // GetStats returns a copy of the current statistics
 func (dsc *DecoderStatsCollector) GetStats() DecodingStats {
+	dsc.mu.Lock()
+	defer dsc.mu.Unlock()
+
 	stats := dsc.Stats
 	// Deep copy the map
 	stats.TypesDecoded = make(map[Type]int)
 	for k, v := range dsc.Stats.TypesDecoded {
 		stats.TypesDecoded[k] = v
 	}
+	if dsc.Stats.Fields != nil {
+		stats.Fields = make(map[string]FieldProfile, len(dsc.Stats.Fields))
+		for k, v := range dsc.Stats.Fields {
+			stats.Fields[k] = v
+		}
+	}
 	return stats
 }
 
 // ResetStats resets all statistics
 func (dsc *DecoderStatsCollector) ResetStats() {
+	dsc.mu.Lock()
+	defer dsc.mu.Unlock()
+
 	dsc.Stats = DecodingStats{
 		TypesDecoded: make(map[Type]int),
 	}
+	dsc.decodes = 0
 }
@@ -0,0 +1,685 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var documentErr = errors.New("bogo document error")
+
+// Document is a complete encoded document, starting with the version byte,
+// Magic or EnvelopeMagic. Edits keep the prefix or envelope of the document.
+// Values inside it are addressed by paths of object keys and list indexes,
+// written as in error messages, e.g. "contacts[2].email"; the empty path is
+// the whole document. Keys containing '.' or '[' cannot be addressed.
+//
+// Edits are copy-on-write: they return a new Document and leave the receiver
+// unchanged. Only the edited value is encoded. The rest of the document is
+// copied as it is, with the sizes of the containers enclosing the edit
+// rewritten, so editing a large payload does not decode it.
+type Document []byte
+
+// Get returns the encoded value at path, starting at its type byte. For a
+// repeated key it returns the last occurrence, which is the one decoding keeps.
+// Values that may refer to the symbol table of the document are returned under
+// a copy of it, as LookupField returns them.
+func (doc Document) Get(path string) ([]byte, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	value, err := doc.value()
+	if err != nil {
+		return nil, err
+	}
+	raw, table, err := valueAt(value, segments)
+	if err != nil {
+		return nil, err
+	}
+	return withSymbols(table, raw), nil
+}
+
+// valueAt returns the encoded value at segments below value, and the header of
+// the innermost symbol table around it
+func valueAt(value []byte, segments []pathSegment) ([]byte, []byte, error) {
+	var table []byte
+	var symbols []string
+	at := ""
+	for _, seg := range segments {
+		at = seg.join(at)
+		header, inner, err := splitSymbols(value)
+		if err != nil {
+			return nil, nil, wrapError(documentErr, err.Error())
+		}
+		if header != nil {
+			if symbols, err = tableSymbols(header); err != nil {
+				return nil, nil, err
+			}
+			table = header
+		}
+		value = inner
+
+		var found []byte
+		switch {
+		case !seg.selects(Type(value[0])):
+			return nil, nil, mismatch(at, seg, value)
+		case seg.isKey():
+			var fields []byte
+			if fields, err = objectFields(value); err == nil {
+				err = walkObject(value, fields, symbols, func(_, _ int, key, v []byte) bool {
+					if string(key) == seg.key {
+						found = v
+					}
+					return true
+				})
+			}
+		default:
+			found, err = listElement(value, seg.index)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if found == nil {
+			return nil, nil, fmt.Errorf("%w: %q", ErrFieldNotFound, at)
+		}
+		value = found
+	}
+	return value, table, nil
+}
+
+// walkObject calls fn with the bounds, key and encoded value of each entry of
+// fields, the payload of the object value, until fn returns false. The keys
+// of a keyed object are resolved against symbols.
+func walkObject(value, fields []byte, symbols []string, fn func(start, end int, key, v []byte) bool) error {
+	if Type(value[0]) != TypeKeyedObject {
+		return walkEntries(fields, fn)
+	}
+	return walkKeyedEntries(fields, stringMaker{symbols: symbols}, func(start, end int, key string, v []byte) bool {
+		return fn(start, end, []byte(key), v)
+	})
+}
+
+// Select decodes the values at paths, keyed by path. Paths missing from the
+// document are left out of the result, and nothing else is decoded.
+func (doc Document) Select(paths ...string) (map[string]any, error) {
+	values := make(map[string]any, len(paths))
+	for _, path := range paths {
+		raw, err := doc.Get(path)
+		if errors.Is(err, ErrFieldNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if values[path], err = decodeValue(raw); err != nil {
+			return nil, fmt.Errorf("%w: decoding %q: %w", documentErr, path, err)
+		}
+	}
+	return values, nil
+}
+
+// Set returns a copy of the document with the value at path replaced by the
+// encoding of value. A missing key is added to its object, and the index one
+// past the end of a list appends to it. Every occurrence of a repeated key is
+// replaced.
+func (doc Document) Set(path string, value any) (Document, error) {
+	encoded, err := defaultEncoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return doc.splice(path, encoded[1:])
+}
+
+// Delete returns a copy of the document without the value at path, removing
+// the key from its object or the element from its list. Every occurrence of a
+// repeated key is removed.
+func (doc Document) Delete(path string) (Document, error) {
+	if path == "" {
+		return nil, wrapError(documentErr, "cannot delete the whole document")
+	}
+	return doc.splice(path, nil)
+}
+
+// splice replaces the value at path with encoded, or removes it when encoded
+// is nil
+func (doc Document) splice(path string, encoded []byte) (Document, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return editDocument(doc, func(plain []byte) ([]byte, error) {
+		value, err := Document(plain).value()
+		if err != nil {
+			return nil, err
+		}
+		out, err := spliceValue(value, nil, segments, "", encoded)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{plain[0]}, out...), nil
+	})
+}
+
+// value returns the top-level value of the document, without trailing bytes
+func (doc Document) value() ([]byte, error) {
+	doc, _, err := openDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", documentErr, err)
+	}
+	if len(doc) < 2 {
+		return nil, wrapError(documentErr, "insufficient data, need at least 2 bytes for version and type")
+	}
+	if doc[0] != Version {
+		return nil, fmt.Errorf("%w: %w", documentErr, versionMismatch(doc[0]))
+	}
+	size, err := getElementSize(doc[1:])
+	if err != nil {
+		return nil, wrapError(documentErr, err.Error())
+	}
+	if size <= 0 || size > len(doc)-1 {
+		return nil, wrapError(documentErr, "insufficient data for value")
+	}
+	return doc[1 : 1+size], nil
+}
+
+// spliceValue returns value, found at path at below a symbol table holding
+// symbols, with the value at segments below it replaced by encoded, or removed
+// when encoded is nil
+func spliceValue(value []byte, symbols []string, segments []pathSegment, at string, encoded []byte) ([]byte, error) {
+	if len(segments) == 0 {
+		return encoded, nil
+	}
+	if Type(value[0]) == TypeSymbols {
+		return underSymbols(value, symbols, func(inner []byte, symbols []string) ([]byte, error) {
+			return spliceValue(inner, symbols, segments, at, encoded)
+		})
+	}
+	seg := segments[0]
+	at = seg.join(at)
+
+	if !seg.selects(Type(value[0])) {
+		return nil, mismatch(at, seg, value)
+	}
+	if seg.isKey() {
+		return spliceObject(value, symbols, segments, at, encoded)
+	}
+	return spliceList(value, symbols, segments, at, encoded)
+}
+
+func spliceObject(value []byte, symbols []string, segments []pathSegment, at string, encoded []byte) ([]byte, error) {
+	fields, err := objectFields(value)
+	if err != nil {
+		return nil, err
+	}
+
+	key := segments[0].key
+	keyed := Type(value[0]) == TypeKeyedObject
+	var out []byte
+	copied, found := 0, false
+	var spliceErr error
+	err = walkObject(value, fields, symbols, func(start, end int, k, v []byte) bool {
+		if string(k) != key {
+			return true
+		}
+		found = true
+		out = append(out, fields[copied:start]...)
+		copied = end
+
+		child, err := spliceValue(v, symbols, segments[1:], at, encoded)
+		if err != nil {
+			spliceErr = err
+			return false
+		}
+		switch {
+		case child == nil:
+		case keyed:
+			// The entry keeps its reference to the key
+			out = append(append(out, fields[start:end-len(v)]...), child...)
+		default:
+			entry, err := buildFieldEntry(key, child)
+			if err != nil {
+				spliceErr = wrapError(documentErr, err.Error())
+				return false
+			}
+			out = append(out, entry...)
+		}
+		return true
+	})
+	if err == nil {
+		err = spliceErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, fields[copied:]...)
+
+	if !found {
+		if len(segments) > 1 || encoded == nil {
+			return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, at)
+		}
+		if !keyed {
+			entry, err := buildFieldEntry(key, encoded)
+			if err != nil {
+				return nil, wrapError(documentErr, err.Error())
+			}
+			out = append(out, entry...)
+		} else if entry, ok := keyedEntry(symbols, key, encoded); ok {
+			out = append(out, entry...)
+		} else {
+			// The table lacks the key, so the object takes it with its keys
+			// written out
+			plain, err := plainObject(value, symbols)
+			if err != nil {
+				return nil, err
+			}
+			return spliceObject(plain, nil, segments, at, encoded)
+		}
+	}
+	return buildContainer(Type(value[0]), out)
+}
+
+func spliceList(value []byte, symbols []string, segments []pathSegment, at string, encoded []byte) ([]byte, error) {
+	elements, err := containerPayload(value[1:])
+	if err != nil {
+		return nil, wrapError(documentErr, err.Error())
+	}
+	index := segments[0].index
+	start, end, count, err := elementBounds(elements, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var child []byte
+	switch {
+	case index < count:
+		if child, err = spliceValue(elements[start:end], symbols, segments[1:], at, encoded); err != nil {
+			return nil, err
+		}
+	case index == count && len(segments) == 1 && encoded != nil:
+		child = encoded // append
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, at)
+	}
+
+	out := make([]byte, 0, len(elements)-(end-start)+len(child))
+	out = append(out, elements[:start]...)
+	out = append(out, child...)
+	out = append(out, elements[end:]...)
+	return buildContainer(TypeUntypedList, out)
+}
+
+// listElement returns element index of the untyped list in value, or nil if
+// the list is shorter
+func listElement(value []byte, index int) ([]byte, error) {
+	elements, err := containerPayload(value[1:])
+	if err != nil {
+		return nil, wrapError(documentErr, err.Error())
+	}
+	start, end, count, err := elementBounds(elements, index)
+	if err != nil || index >= count {
+		return nil, err
+	}
+	return elements[start:end], nil
+}
+
+// objectFields returns the field entries of the object in value. A null
+// object has no fields.
+func objectFields(value []byte) ([]byte, error) {
+	fields, err := containerPayload(value[1:])
+	if err != nil {
+		return nil, wrapError(documentErr, err.Error())
+	}
+	if len(fields) == 1 && fields[0] == TypeNull {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// elementBounds locates element index of a list payload. When the list has
+// no such element, count is at most index and the range is empty.
+func elementBounds(elements []byte, index int) (start, end, count int, err error) {
+	pos := 0
+	for ; pos < len(elements); count++ {
+		size, err := getElementSize(elements[pos:])
+		if err != nil {
+			return 0, 0, 0, wrapError(documentErr, err.Error())
+		}
+		if size <= 0 || pos+size > len(elements) {
+			return 0, 0, 0, wrapError(documentErr, "insufficient data for list element")
+		}
+		if count == index {
+			return pos, pos + size, count + 1, nil
+		}
+		pos += size
+	}
+	return pos, pos, count, nil
+}
+
+// pathSegment is an object key or, when index is not -1, a list index
+type pathSegment struct {
+	key   string
+	index int
+}
+
+func (s pathSegment) isKey() bool {
+	return s.index < 0
+}
+
+// selects reports whether the segment selects from values of type typ
+func (s pathSegment) selects(typ Type) bool {
+	if s.isKey() {
+		return typ == TypeObject || typ == TypeKeyedObject
+	}
+	return typ == TypeUntypedList
+}
+
+// join appends the segment to path
+func (s pathSegment) join(path string) string {
+	if !s.isKey() {
+		return path + "[" + strconv.Itoa(s.index) + "]"
+	}
+	if path == "" {
+		return s.key
+	}
+	return path + "." + s.key
+}
+
+// parsePath splits a path such as "contacts[2].email" into its segments
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for rest := path; rest != ""; {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, wrapError(documentErr, fmt.Sprintf("invalid path %q: unclosed [", path))
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, wrapError(documentErr, fmt.Sprintf("invalid path %q: bad index %q", path, rest[1:end]))
+			}
+			segments = append(segments, pathSegment{index: index})
+			rest = rest[end+1:]
+		} else {
+			if len(segments) > 0 {
+				var ok bool
+				if rest, ok = strings.CutPrefix(rest, "."); !ok {
+					return nil, wrapError(documentErr, fmt.Sprintf("invalid path %q", path))
+				}
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, wrapError(documentErr, fmt.Sprintf("invalid path %q: empty key", path))
+			}
+			segments = append(segments, pathSegment{key: rest[:end], index: -1})
+			rest = rest[end:]
+		}
+	}
+	return segments, nil
+}
+
+// mismatch reports a path segment that does not apply to the value it selects from
+func mismatch(at string, seg pathSegment, value []byte) error {
+	want := "an object"
+	if !seg.isKey() {
+		want = "a list"
+	}
+	return wrapError(documentErr, fmt.Sprintf("cannot select %s: parent is %s, not %s", at, Type(value[0]), want))
+}
+
+// AppendToList appends elem to the list at path in the document data, in the
+// path syntax of Document, and returns the updated document. The element is
+// encoded and inserted at the end of the list, and the size prefixes of the
+// list and of every container enclosing it are patched, so the cost of an
+// append does not grow with the number of elements already in the list.
+// Elements appended to a typed list must have its element type.
+//
+// Like the built-in append, AppendToList reuses the capacity of data when it
+// can, and data must not be used after the call.
+func AppendToList(data []byte, path string, elem any) ([]byte, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return editDocument(data, func(doc []byte) ([]byte, error) {
+		return appendToList(doc, segments, elem)
+	})
+}
+
+// appendToList is AppendToList on the plain document data
+func appendToList(data []byte, segments []pathSegment, elem any) ([]byte, error) {
+	if _, err := Document(data).value(); err != nil {
+		return nil, err
+	}
+
+	// Walk to the list, collecting the size prefixes enclosing it
+	base, at := 1, ""
+	var symbols []string
+	var prefixes []sizePrefix
+	for _, seg := range segments {
+		at = seg.join(at)
+		var err error
+		if base, symbols, err = belowSymbols(data, base, symbols); err != nil {
+			return nil, err
+		}
+		if !seg.selects(Type(data[base])) {
+			return nil, mismatch(at, seg, data[base:])
+		}
+		container, err := readContainerPrefix(data, base)
+		if err != nil {
+			return nil, err
+		}
+		payloadStart := container.offset + container.width
+		payload := data[payloadStart : payloadStart+int(container.value)]
+		prefixes = append(prefixes, container)
+
+		if seg.isKey() {
+			fields, err := objectFields(data[base:])
+			if err != nil {
+				return nil, err
+			}
+			entryStart, entryEnd, valueSize := -1, 0, 0
+			err = walkObject(data[base:], fields, symbols, func(start, end int, key, v []byte) bool {
+				if string(key) == seg.key {
+					entryStart, entryEnd, valueSize = start, end, len(v)
+				}
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+			if entryStart < 0 {
+				return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, at)
+			}
+			if Type(data[base]) == TypeKeyedObject {
+				// Keyed entries have no size of their own, their value ends them
+				base = payloadStart + entryEnd - valueSize
+				continue
+			}
+			entry, err := readSizePrefix(data, payloadStart+entryStart)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, entry)
+			base = entry.offset + entry.width + 1 + len(seg.key)
+			if base == payloadStart+entryEnd {
+				return nil, wrapError(documentErr, fmt.Sprintf("%s is null, not a list", at))
+			}
+		} else {
+			start, _, count, err := elementBounds(payload, seg.index)
+			if err != nil {
+				return nil, err
+			}
+			if seg.index >= count {
+				return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, at)
+			}
+			base = payloadStart + start
+		}
+	}
+
+	base, _, err := belowSymbols(data, base, nil)
+	if err != nil {
+		return nil, err
+	}
+	listType := Type(data[base])
+	if listType != TypeUntypedList && listType != TypeTypedList {
+		if at == "" {
+			at = "<root>"
+		}
+		return nil, wrapError(documentErr, fmt.Sprintf("%s is %s, not a list", at, listType))
+	}
+	list, err := readContainerPrefix(data, base)
+	if err != nil {
+		return nil, err
+	}
+	prefixes = append(prefixes, list)
+	insertAt := list.offset + list.width + int(list.value)
+
+	var encoded []byte
+	var edits []prefixEdit
+	switch listType {
+	case TypeUntypedList:
+		out, err := defaultEncoder.Encode(elem)
+		if err != nil {
+			return nil, err
+		}
+		encoded = out[1:]
+	case TypeTypedList:
+		var count sizePrefix
+		if encoded, count, err = typedListElement(data, list, elem); err != nil {
+			return nil, err
+		}
+		edits = append(edits, count.edit(count.value+1))
+	}
+
+	// Grow the sizes from the list outwards, each by the bytes added within it
+	delta := len(encoded)
+	for _, e := range edits {
+		delta += len(e.encoded) - e.prefix.width
+	}
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		e := prefixes[i].edit(prefixes[i].value + uint64(delta))
+		delta += len(e.encoded) - e.prefix.width
+		edits = append(edits, e)
+	}
+
+	// Prefixes keeping their width are patched in place
+	if delta == len(encoded) {
+		for _, e := range edits {
+			copy(data[e.prefix.offset:], e.encoded)
+		}
+		return slices.Insert(data, insertAt, encoded...), nil
+	}
+
+	slices.SortFunc(edits, func(a, b prefixEdit) int { return a.prefix.offset - b.prefix.offset })
+	out := make([]byte, 0, len(data)+delta)
+	pos := 0
+	for _, e := range edits {
+		out = append(out, data[pos:e.prefix.offset]...)
+		out = append(out, e.encoded...)
+		pos = e.prefix.offset + e.prefix.width
+	}
+	out = append(out, data[pos:insertAt]...)
+	out = append(out, encoded...)
+	return append(out, data[insertAt:]...), nil
+}
+
+// belowSymbols returns the offset of the value below the symbol tables at
+// offset in data, and the symbols of the innermost table, or symbols when
+// there is none. A table has no size covering the value below it, so appends
+// below it leave it as it is.
+func belowSymbols(data []byte, offset int, symbols []string) (int, []string, error) {
+	table, inner, err := splitSymbols(data[offset:])
+	if err != nil {
+		return 0, nil, wrapError(documentErr, err.Error())
+	}
+	if table != nil {
+		if symbols, err = tableSymbols(table); err != nil {
+			return 0, nil, err
+		}
+	}
+	return len(data) - len(inner), symbols, nil
+}
+
+// typedListElement encodes elem as an element of the typed list whose size
+// prefix is list, and returns it with the prefix holding the element count
+func typedListElement(data []byte, list sizePrefix, elem any) ([]byte, sizePrefix, error) {
+	payloadStart := list.offset + list.width
+	if list.value < 2 {
+		return nil, sizePrefix{}, wrapError(documentErr, "insufficient data for typed list header")
+	}
+	// The element count must lie within the payload of the list
+	count, err := readSizePrefix(data[:payloadStart+int(list.value)], payloadStart+1)
+	if err != nil {
+		return nil, sizePrefix{}, err
+	}
+
+	elemType := reflect.TypeOf(elem)
+	if elemType == nil {
+		return nil, sizePrefix{}, wrapError(documentErr, "cannot append null to a typed list")
+	}
+	single := reflect.MakeSlice(reflect.SliceOf(elemType), 1, 1)
+	single.Index(0).Set(reflect.ValueOf(elem))
+	encoded, err := encodeTypedList(single.Interface())
+	if err != nil {
+		return nil, sizePrefix{}, err
+	}
+	listType := Type(data[payloadStart])
+	if encoded[0] != TypeTypedList || Type(encoded[2+int(encoded[1])]) != listType {
+		return nil, sizePrefix{}, wrapError(documentErr, fmt.Sprintf("cannot append %T to a typed list of %s", elem, listType))
+	}
+	payload, err := containerPayload(encoded[1:])
+	if err != nil {
+		return nil, sizePrefix{}, wrapError(documentErr, err.Error())
+	}
+	return payload[2+int(payload[1]):], count, nil
+}
+
+// sizePrefix is a size prefix read from a document
+type sizePrefix struct {
+	offset int    // of the length byte
+	width  int    // length byte and uvarint
+	value  uint64 // the size
+}
+
+// readSizePrefix reads the size prefix at offset in data
+func readSizePrefix(data []byte, offset int) (sizePrefix, error) {
+	if offset >= len(data) || offset+1+int(data[offset]) > len(data) {
+		return sizePrefix{}, wrapError(documentErr, "insufficient data for size prefix")
+	}
+	width := 1 + int(data[offset])
+	value, err := decodeUint(data[offset+1 : offset+width])
+	if err != nil {
+		return sizePrefix{}, wrapError(documentErr, err.Error())
+	}
+	return sizePrefix{offset: offset, width: width, value: value}, nil
+}
+
+// readContainerPrefix reads the size prefix of the container at offset in
+// data, checking that its payload is present
+func readContainerPrefix(data []byte, offset int) (sizePrefix, error) {
+	p, err := readSizePrefix(data, offset+1)
+	if err != nil {
+		return sizePrefix{}, err
+	}
+	if p.value > uint64(len(data)-p.offset-p.width) {
+		return sizePrefix{}, wrapError(documentErr, "insufficient data for container content")
+	}
+	return p, nil
+}
+
+// prefixEdit replaces a size prefix with a new encoding
+type prefixEdit struct {
+	prefix  sizePrefix
+	encoded []byte
+}
+
+func (p sizePrefix) edit(value uint64) prefixEdit {
+	return prefixEdit{prefix: p, encoded: appendUvarintLen(nil, value)}
+}
@@ -0,0 +1,292 @@
+package bogo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocument(t *testing.T) Document {
+	t.Helper()
+	data, err := Marshal(map[string]any{
+		"name": "Ada",
+		"contacts": []any{
+			map[string]any{"email": "ada@example.com"},
+			map[string]any{"email": "ada@work.example.com"},
+		},
+		"address": map[string]any{"city": "London"},
+	})
+	require.NoError(t, err)
+	return Document(data)
+}
+
+func decodeDocument(t *testing.T, doc Document) map[string]any {
+	t.Helper()
+	var v map[string]any
+	require.NoError(t, Unmarshal(doc, &v))
+	return v
+}
+
+func TestDocumentGet(t *testing.T) {
+	doc := testDocument(t)
+
+	raw, err := doc.Get("contacts[1].email")
+	require.NoError(t, err)
+	value, err := decodeValue(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "ada@work.example.com", value)
+
+	raw, err = doc.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(doc[1:]), raw)
+
+	_, err = doc.Get("contacts[2]")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	_, err = doc.Get("address.zip")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	_, err = doc.Get("name.first")
+	assert.ErrorIs(t, err, documentErr)
+}
+
+func TestDocumentSet(t *testing.T) {
+	doc := testDocument(t)
+	original := bytes.Clone(doc)
+
+	tests := []struct {
+		name  string
+		path  string
+		value any
+		check func(t *testing.T, v map[string]any)
+	}{
+		{"replace scalar", "name", "Grace", func(t *testing.T, v map[string]any) {
+			assert.Equal(t, "Grace", v["name"])
+		}},
+		{"nested key", "address.city", "Paris", func(t *testing.T, v map[string]any) {
+			assert.Equal(t, map[string]any{"city": "Paris"}, v["address"])
+		}},
+		{"add key", "address.zip", "SW1", func(t *testing.T, v map[string]any) {
+			assert.Equal(t, map[string]any{"city": "London", "zip": "SW1"}, v["address"])
+		}},
+		{"list element", "contacts[0].email", "a@b.c", func(t *testing.T, v map[string]any) {
+			assert.Equal(t, "a@b.c", v["contacts"].([]any)[0].(map[string]any)["email"])
+		}},
+		{"append to list", "contacts[2]", "note", func(t *testing.T, v map[string]any) {
+			assert.Len(t, v["contacts"], 3)
+			assert.Equal(t, "note", v["contacts"].([]any)[2])
+		}},
+		{"grow size prefixes", "name", strings.Repeat("x", 300), func(t *testing.T, v map[string]any) {
+			assert.Equal(t, strings.Repeat("x", 300), v["name"])
+			assert.Equal(t, "London", v["address"].(map[string]any)["city"])
+		}},
+		{"whole document", "", []any{int64(1)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edited, err := doc.Set(tt.path, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, original, []byte(doc), "the original document is unchanged")
+
+			if tt.check == nil {
+				var v any
+				require.NoError(t, Unmarshal(edited, &v))
+				assert.Equal(t, tt.value, v)
+				return
+			}
+			tt.check(t, decodeDocument(t, edited))
+		})
+	}
+
+	t.Run("unchanged regions are copied verbatim", func(t *testing.T) {
+		edited, err := doc.Set("address.city", "Paris")
+		require.NoError(t, err)
+		contacts, err := doc.Get("contacts")
+		require.NoError(t, err)
+		assert.True(t, bytes.Contains(edited, contacts))
+	})
+
+	t.Run("repeated keys", func(t *testing.T) {
+		doc := Document(append([]byte{Version}, rawObject(
+			rawEntry("a", "first"),
+			rawEntry("b", "kept"),
+			rawEntry("a", "second"),
+		)...))
+		edited, err := doc.Set("a", "only")
+		require.NoError(t, err)
+
+		fields, err := Fields(edited)
+		require.NoError(t, err)
+		var keys []string
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"a", "b", "a"}, keys)
+		assert.Equal(t, map[string]any{"a": "only", "b": "kept"}, decodeDocument(t, edited))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := doc.Set("contacts[5]", "x")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = doc.Set("missing.key", "x")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = doc.Set("name[0]", "x")
+		assert.ErrorIs(t, err, documentErr)
+		_, err = Document{Version}.Set("a", "x")
+		assert.ErrorIs(t, err, documentErr)
+	})
+}
+
+func TestDocumentDelete(t *testing.T) {
+	doc := testDocument(t)
+
+	edited, err := doc.Delete("address")
+	require.NoError(t, err)
+	assert.NotContains(t, decodeDocument(t, edited), "address")
+
+	edited, err = doc.Delete("contacts[0]")
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"email": "ada@work.example.com"}}, decodeDocument(t, edited)["contacts"])
+
+	edited, err = doc.Delete("address.city")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, decodeDocument(t, edited)["address"])
+
+	_, err = doc.Delete("address.zip")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	_, err = doc.Delete("contacts[2]")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	_, err = doc.Delete("")
+	assert.ErrorIs(t, err, documentErr)
+}
+
+func TestParsePath(t *testing.T) {
+	segments, err := parsePath("contacts[2].email")
+	require.NoError(t, err)
+	assert.Equal(t, []pathSegment{{key: "contacts", index: -1}, {index: 2}, {key: "email", index: -1}}, segments)
+
+	segments, err = parsePath("[0][1]")
+	require.NoError(t, err)
+	assert.Equal(t, []pathSegment{{index: 0}, {index: 1}}, segments)
+
+	for _, path := range []string{"a..b", ".a", "a.", "a[", "a[-1]", "a[x]", "a[0]b"} {
+		_, err := parsePath(path)
+		assert.ErrorIs(t, err, documentErr, path)
+	}
+}
+
+func TestAppendToList(t *testing.T) {
+	newDoc := func(t *testing.T) []byte {
+		data, err := Marshal(map[string]any{
+			"meta":   map[string]any{"events": []any{"created"}},
+			"tags":   []string{"a", "b"},
+			"counts": []int64{1},
+			"name":   "Ada",
+		})
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("untyped list", func(t *testing.T) {
+		data, err := AppendToList(newDoc(t), "meta.events", "updated")
+		require.NoError(t, err)
+		data, err = AppendToList(data, "meta.events", map[string]any{"by": "Grace"})
+		require.NoError(t, err)
+
+		v := decodeDocument(t, Document(data))
+		assert.Equal(t, []any{"created", "updated", map[string]any{"by": "Grace"}}, v["meta"].(map[string]any)["events"])
+		assert.Equal(t, "Ada", v["name"])
+	})
+
+	t.Run("typed lists", func(t *testing.T) {
+		data, err := AppendToList(newDoc(t), "tags", "c")
+		require.NoError(t, err)
+		data, err = AppendToList(data, "counts", int64(2))
+		require.NoError(t, err)
+
+		v := decodeDocument(t, Document(data))
+		assert.Equal(t, []string{"a", "b", "c"}, v["tags"])
+		assert.Equal(t, []int64{1, 2}, v["counts"])
+
+		_, err = AppendToList(data, "tags", 3)
+		assert.ErrorIs(t, err, documentErr)
+	})
+
+	t.Run("top-level list", func(t *testing.T) {
+		data, err := Marshal([]any{int64(1)})
+		require.NoError(t, err)
+		data, err = AppendToList(data, "", int64(2))
+		require.NoError(t, err)
+
+		var v []any
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, []any{int64(1), int64(2)}, v)
+	})
+
+	t.Run("size prefixes growing wider", func(t *testing.T) {
+		data := newDoc(t)
+		want := []any{"created"}
+		for i := 0; i < 200; i++ {
+			elem := strings.Repeat("x", i)
+			var err error
+			data, err = AppendToList(data, "meta.events", elem)
+			require.NoError(t, err)
+			want = append(want, elem)
+		}
+		v := decodeDocument(t, Document(data))
+		assert.Equal(t, want, v["meta"].(map[string]any)["events"])
+		assert.Equal(t, []string{"a", "b"}, v["tags"])
+	})
+
+	t.Run("reuses capacity", func(t *testing.T) {
+		data := newDoc(t)
+		data = append(make([]byte, 0, len(data)+64), data...)
+		appended, err := AppendToList(data, "meta.events", "x")
+		require.NoError(t, err)
+		assert.Same(t, &data[0], &appended[0])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := AppendToList(newDoc(t), "name", "x")
+		assert.ErrorIs(t, err, documentErr)
+		_, err = AppendToList(newDoc(t), "missing", "x")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = AppendToList(newDoc(t), "meta.events[3]", "x")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = AppendToList([]byte{Version}, "", "x")
+		assert.ErrorIs(t, err, documentErr)
+
+		null := append([]byte{Version}, rawObject([]byte{6, 'e', 'v', 'e', 'n', 't', 's'})...)
+		_, err = AppendToList(null, "events", "x")
+		assert.ErrorIs(t, err, documentErr)
+	})
+
+	t.Run("element count outside the list", func(t *testing.T) {
+		// A typed list of ints declaring 2 bytes, whose 2-byte count follows it
+		data := []byte{Version, byte(TypeTypedList), 1, 2, byte(TypeInt), 2, 0xff, 0x7f}
+		_, err := Decode(data)
+		require.Error(t, err)
+
+		assert.NotPanics(t, func() {
+			_, err = AppendToList(data, "", int64(1))
+		})
+		assert.ErrorIs(t, err, documentErr)
+	})
+}
+
+func TestDocumentSelect(t *testing.T) {
+	doc := testDocument(t)
+
+	values, err := doc.Select("name", "contacts[1].email", "address.zip", "address")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":              "Ada",
+		"contacts[1].email": "ada@work.example.com",
+		"address":           map[string]any{"city": "London"},
+	}, values)
+
+	_, err = doc.Select("name.first")
+	assert.ErrorIs(t, err, documentErr)
+}
@@ -0,0 +1,97 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DuplicateKeyPolicy controls what happens when several keys of a reflected
+// map encode to the same object key, e.g. 1 and "1" in a map[any]any or the
+// NaN keys of a map[float64]T
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeyError fails the encode so no value is dropped silently (default)
+	DuplicateKeyError DuplicateKeyPolicy = iota
+	// DuplicateKeySuffix keeps every value, appending "#2", "#3", ... to the
+	// second and later colliding keys in a deterministic order
+	DuplicateKeySuffix
+	// DuplicateKeyLastWins keeps the value of the last colliding key in a
+	// deterministic order and drops the others
+	DuplicateKeyLastWins
+)
+
+func (p DuplicateKeyPolicy) String() string {
+	switch p {
+	case DuplicateKeyError:
+		return "error"
+	case DuplicateKeySuffix:
+		return "suffix"
+	case DuplicateKeyLastWins:
+		return "last-wins"
+	}
+	return "<unknown>"
+}
+
+var errDuplicateKey = errors.New("bogo encode error: duplicate map key")
+
+// mapObject converts a reflected map to an object, resolving keys that
+// stringify identically according to policy
+func mapObject(rv reflect.Value, policy DuplicateKeyPolicy) (map[string]any, error) {
+	groups := make(map[string][]reflect.Value, rv.Len())
+	for _, key := range rv.MapKeys() {
+		keyStr := fmt.Sprintf("%v", key.Interface())
+		groups[keyStr] = append(groups[keyStr], key)
+	}
+
+	obj := make(map[string]any, len(groups))
+	var collided []string
+	for keyStr, keys := range groups {
+		if len(keys) == 1 {
+			obj[keyStr] = rv.MapIndex(keys[0]).Interface()
+			continue
+		}
+		if policy == DuplicateKeyError {
+			return nil, fmt.Errorf("%w: %d keys encode as %q", errDuplicateKey, len(keys), keyStr)
+		}
+		collided = append(collided, keyStr)
+	}
+
+	// Resolve collisions after every unique key is known, so suffixed keys
+	// never overwrite one of them
+	sort.Strings(collided)
+	for _, keyStr := range collided {
+		keys := groups[keyStr]
+		sortKeys(keys)
+
+		if policy == DuplicateKeyLastWins {
+			obj[keyStr] = rv.MapIndex(keys[len(keys)-1]).Interface()
+			continue
+		}
+
+		obj[keyStr] = rv.MapIndex(keys[0]).Interface()
+		n := 2
+		for _, key := range keys[1:] {
+			suffixed := fmt.Sprintf("%s#%d", keyStr, n)
+			for _, taken := obj[suffixed]; taken; _, taken = obj[suffixed] {
+				n++
+				suffixed = fmt.Sprintf("%s#%d", keyStr, n)
+			}
+			obj[suffixed] = rv.MapIndex(key).Interface()
+			n++
+		}
+	}
+
+	return obj, nil
+}
+
+// sortKeys orders colliding keys by dynamic type and Go syntax representation.
+// Keys that are still indistinguishable, such as NaNs, keep an arbitrary order.
+func sortKeys(keys []reflect.Value) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i].Interface(), keys[j].Interface()
+		return fmt.Sprintf("%T:%#v", a, a) < fmt.Sprintf("%T:%#v", b, b)
+	})
+}
@@ -0,0 +1,64 @@
+package bogo
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateKeyPolicy(t *testing.T) {
+	colliding := map[any]any{1: "int", "1": "string", 2: "two"}
+
+	decodeObj := func(t *testing.T, data []byte) map[string]any {
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		return decoded.(map[string]any)
+	}
+
+	t.Run("error by default", func(t *testing.T) {
+		_, err := Encode(colliding)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, errDuplicateKey))
+		assert.Contains(t, err.Error(), `"1"`)
+	})
+
+	t.Run("NaN keys collide", func(t *testing.T) {
+		_, err := Encode(map[float64]int{math.NaN(): 1, math.NaN(): 2})
+		assert.True(t, errors.Is(err, errDuplicateKey))
+	})
+
+	t.Run("suffix keeps every value", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithDuplicateKeyPolicy(DuplicateKeySuffix))
+		data, err := encoder.Encode(colliding)
+		require.NoError(t, err)
+
+		// int sorts before string by type name
+		assert.Equal(t, map[string]any{"1": "int", "1#2": "string", "2": "two"}, decodeObj(t, data))
+	})
+
+	t.Run("suffix avoids existing keys", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithDuplicateKeyPolicy(DuplicateKeySuffix))
+		data, err := encoder.Encode(map[any]any{1: "a", "1": "b", "1#2": "c"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"1": "a", "1#2": "c", "1#3": "b"}, decodeObj(t, data))
+	})
+
+	t.Run("last wins is deterministic", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithDuplicateKeyPolicy(DuplicateKeyLastWins))
+		for i := 0; i < 10; i++ {
+			data, err := encoder.Encode(colliding)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"1": "string", "2": "two"}, decodeObj(t, data))
+		}
+	})
+
+	t.Run("unique keys are unaffected", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithStringMapKeys(true))
+		data, err := encoder.Encode(map[int]string{1: "a", 2: "b"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"1": "a", "2": "b"}, decodeObj(t, data))
+	})
+}
@@ -0,0 +1,25 @@
+package bogo
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+const durationSize = 8 // nanoseconds as a little-endian int64
+
+func encodeDuration(d time.Duration) ([]byte, error) {
+	buf := make([]byte, 1+durationSize)
+	buf[0] = byte(TypeDuration)
+	wireOrder.PutUint64(buf[1:], uint64(d))
+	return buf, nil
+}
+
+func decodeDuration(data []byte) (time.Duration, error) {
+	if len(data) < durationSize {
+		return 0, fmt.Errorf("duration decode error: insufficient data, need %d bytes, got %d", durationSize, len(data))
+	}
+	return time.Duration(int64(wireOrder.Uint64(data[:durationSize]))), nil
+}
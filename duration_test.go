@@ -0,0 +1,99 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type durationConfig struct {
+	Timeout  time.Duration            `json:"timeout"`
+	Retry    *time.Duration           `json:"retry"`
+	Backoff  []time.Duration          `json:"backoff"`
+	Limits   map[string]time.Duration `json:"limits"`
+	Untyped  any                      `json:"untyped"`
+	Attempts int64                    `json:"attempts"`
+}
+
+func TestDuration(t *testing.T) {
+	for _, d := range []time.Duration{0, time.Nanosecond, -90 * time.Minute, math.MaxInt64, math.MinInt64} {
+		data, err := Marshal(d)
+		require.NoError(t, err)
+		assert.Len(t, data, 2+durationSize)
+		assert.Equal(t, byte(TypeDuration), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, d, decoded)
+
+		var v time.Duration
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, d, v)
+	}
+}
+
+func TestDurationFields(t *testing.T) {
+	retry := 250 * time.Millisecond
+	config := durationConfig{
+		Timeout:  30 * time.Second,
+		Retry:    &retry,
+		Backoff:  []time.Duration{time.Second, 2 * time.Second},
+		Limits:   map[string]time.Duration{"read": time.Minute},
+		Untyped:  time.Hour,
+		Attempts: 3,
+	}
+
+	data, err := Marshal(config)
+	require.NoError(t, err)
+
+	var decoded durationConfig
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, config, decoded)
+
+	var generic map[string]any
+	require.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, 30*time.Second, generic["timeout"])
+	assert.Equal(t, time.Hour, generic["untyped"])
+	assert.Equal(t, []any{time.Second, 2 * time.Second}, generic["backoff"])
+}
+
+func TestDurationInterop(t *testing.T) {
+	t.Run("integers decode into durations", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithDurationsAsIntegers(true))
+		data, err := encoder.Encode(map[string]any{"timeout": 30 * time.Second})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"timeout": int64(30 * time.Second)}, decoded)
+
+		var v durationConfig
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, 30*time.Second, v.Timeout)
+	})
+
+	t.Run("durations decode into integers", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"attempts": 3 * time.Nanosecond})
+		require.NoError(t, err)
+
+		var v durationConfig
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, int64(3), v.Attempts)
+
+		var counts map[string]int64
+		require.NoError(t, Unmarshal(data, &counts))
+		assert.Equal(t, map[string]int64{"attempts": 3}, counts)
+	})
+
+	t.Run("range bounds", func(t *testing.T) {
+		data, err := Marshal(Range[time.Duration]{Start: time.Second, End: time.Minute, StartInclusive: true})
+		require.NoError(t, err)
+
+		var r Range[time.Duration]
+		require.NoError(t, Unmarshal(data, &r))
+		assert.Equal(t, Range[time.Duration]{Start: time.Second, End: time.Minute, StartInclusive: true}, r)
+	})
+}
@@ -2,9 +2,14 @@ package bogo
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"fmt"
 	"io"
+	"maps"
+	"math/big"
 	"reflect"
+	"slices"
+	"sync"
 	"time"
 )
 
@@ -16,9 +21,32 @@ type Encoder struct {
 	CompactLists   bool   // Use typed lists when beneficial
 	ValidateStrings bool   // Validate UTF-8 encoding in strings
 	TagName         string // Struct tag name to use (default: "json" for compatibility)
+	TimeFormat      TimeFormat // Wire representation for time.Time values (default: TimeFormatMillis)
+	ZeroTime        ZeroTimePolicy // How time.Time{} is encoded (default: ZeroTimeAsIs)
+	Uint8AsNumber   bool   // Encode uint8 scalars as TypeUint instead of TypeByte
+	RunesAsStrings  bool   // Encode int32/rune values and slices as strings
+	NaNPolicy       NaNPolicy // How NaN and ±Inf are encoded (default: NaNKeep)
+	CompactIntegers bool   // Store small integers in the type byte (TypeFixUint/TypeFixInt)
+	CompactStrings  bool   // Store short string lengths in the type byte (TypeFixStr)
+	DuplicateKeys   DuplicateKeyPolicy // Map keys that stringify identically (default: DuplicateKeyError)
+	MagicPrefix     bool   // Start documents with Magic so they can be identified
+	UnsupportedKinds UnsupportedKindPolicy // Channels, functions, uintptr and unsafe.Pointer (default: UnsupportedKindError)
+	CanonicalOrder  CanonicalOrder // Order object keys are written in (default: CanonicalOff, map iteration order)
+	DurationsAsIntegers bool // Encode time.Duration as TypeInt nanoseconds instead of TypeDuration
+	StringMapKeys bool // Encode maps with integer keys as objects with decimal keys instead of TypeMap
+	ObjectLists bool // Encode slices of structs as TypeObjectList, writing the field keys once
+	NilCollectionsAsEmpty bool // Encode nil slices and maps as empty lists, blobs and objects instead of null
+	Framing Framing // How StreamEncoder frames the documents it writes (default: Raw)
+	EnumsAsStrings bool // Write values of registered enums as strings instead of through a symbol table
+	NumericWidths bool // Record the Go type of numbers narrower than 64 bits, see WithNumericWidths
+	KeyTable bool // Write object keys once, in the symbol table of the document, see WithKeyTable
+	MaxOutputSize int64 // Maximum size of an encoded document in bytes (0 = unlimited), see WithMaxOutputSize
 
 	// Internal state
-	depth int
+	depth     int
+	listDepth int // number of enclosing lists, see encodeListWithDepth
+	symbols   *symbolTable // enum values and keys of the document, see encodeRoot
+	plainKeys bool         // write the next object with its keys, see keyTableActive
 }
 
 // EncoderOption is a function type for configuring an Encoder
@@ -32,6 +60,8 @@ func NewConfigurableEncoder(options ...EncoderOption) *Encoder {
 		CompactLists:   true,
 		ValidateStrings: true,
 		TagName:         "json", // Default to json tag for compatibility
+		TimeFormat:      TimeFormatMillis,
+		ZeroTime:        ZeroTimeAsIs,
 	}
 
 	for _, option := range options {
@@ -72,13 +102,167 @@ func WithStructTag(tagName string) EncoderOption {
 	}
 }
 
+// WithTimeFormat sets how time.Time values are encoded. TimeFormatRFC3339String
+// produces human-readable timestamps for consumers that value interoperability
+// over compactness.
+func WithTimeFormat(format TimeFormat) EncoderOption {
+	return func(e *Encoder) {
+		e.TimeFormat = format
+	}
+}
+
+// WithZeroTimePolicy sets how the zero time.Time{} value is encoded. By default it
+// is encoded as-is, which produces a large negative timestamp.
+func WithZeroTimePolicy(policy ZeroTimePolicy) EncoderOption {
+	return func(e *Encoder) {
+		e.ZeroTime = policy
+	}
+}
+
+// WithUint8AsNumber encodes uint8 scalars, including named uint8 types and
+// uint8 elements of lists, as TypeUint rather than TypeByte so that small
+// numbers decode the same way as larger ones. Since byte is an alias of uint8
+// the two cannot be told apart at runtime; []byte is always encoded as a blob.
+func WithUint8AsNumber(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.Uint8AsNumber = enabled
+	}
+}
+
+// WithRunesAsStrings encodes runes as text: a rune becomes a single-character
+// string and a []rune becomes a string. Since rune is an alias of int32 this
+// applies to every int32 value; use the Char type to encode individual
+// characters as strings without affecting other int32 values.
+func WithRunesAsStrings(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.RunesAsStrings = enabled
+	}
+}
+
+// WithNaNPolicy sets how NaN, +Inf and -Inf are encoded. NaNKeep preserves
+// them bit-exactly, NaNNull replaces them with null and NaNError rejects them.
+func WithNaNPolicy(policy NaNPolicy) EncoderOption {
+	return func(e *Encoder) {
+		e.NaNPolicy = policy
+	}
+}
+
+// WithCompactIntegers stores unsigned and signed integers 0-31 in
+// the type byte, so small counters and flags take 1 byte instead of 3. Decoders
+// always accept compact integers; the option is off by default so output stays
+// readable by decoders that predate them.
+func WithCompactIntegers(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.CompactIntegers = enabled
+	}
+}
+
+// WithCompactStrings stores the length of strings shorter than 32 bytes in the
+// type byte, saving 2 bytes per short string. Longer strings keep the regular
+// encoding. Like WithCompactIntegers it is off by default.
+func WithCompactStrings(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.CompactStrings = enabled
+	}
+}
+
+// WithDuplicateKeyPolicy sets how map keys that stringify to the same object
+// key are handled. The default, DuplicateKeyError, fails instead of dropping data.
+func WithDuplicateKeyPolicy(policy DuplicateKeyPolicy) EncoderOption {
+	return func(e *Encoder) {
+		e.DuplicateKeys = policy
+	}
+}
+
+// WithUnsupportedKinds sets what happens to channels, functions, uintptr and
+// unsafe.Pointer values, which have no bogo representation. The default, UnsupportedKindError, reports their path.
+func WithUnsupportedKinds(policy UnsupportedKindPolicy) EncoderOption {
+	return func(e *Encoder) {
+		e.UnsupportedKinds = policy
+	}
+}
+
+// WithDurationsAsIntegers encodes time.Duration values as TypeInt nanoseconds,
+// as encoders did before TypeDuration, for readers that predate it. Either
+// encoding decodes into time.Duration fields.
+func WithDurationsAsIntegers(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.DurationsAsIntegers = enabled
+	}
+}
+
+// WithStringMapKeys encodes maps with integer keys as objects keyed by the
+// keys in decimal, as encoders did before TypeMap, for readers that predate
+// it. Either encoding decodes into maps with integer keys.
+func WithStringMapKeys(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.StringMapKeys = enabled
+	}
+}
+
+// WithObjectLists encodes slices and arrays of structs as object lists, which
+// write the field keys once followed by a row of values per element instead of
+// repeating the keys in every element. Slices whose elements do not all have
+// the same fields, as omitempty can cause, are encoded as regular lists. Object
+// lists decode like lists of objects, so readers need not know the encoding
+// was used, but readers that predate TypeObjectList cannot read them.
+func WithObjectLists(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.ObjectLists = enabled
+	}
+}
+
+// WithNilCollectionsAsEmpty encodes nil slices and maps like empty ones, so a
+// nil []string becomes an empty list and a nil map an empty object, as many
+// JSON APIs expect. By default they encode as null, keeping nil and empty
+// collections apart. Nil pointers and interfaces still encode as null.
+func WithNilCollectionsAsEmpty(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.NilCollectionsAsEmpty = enabled
+	}
+}
+
+// WithCanonicalOrder enables canonical encoding, writing object keys in a
+// fixed order so that equal values always encode to the same bytes.
+// CanonicalDeclaration keeps struct fields in declaration order, as signing
+// schemes defined over a struct's layout need; CanonicalLexical sorts them.
+func WithCanonicalOrder(order CanonicalOrder) EncoderOption {
+	return func(e *Encoder) {
+		e.CanonicalOrder = order
+	}
+}
+
 // Encode encodes a value using the configured encoder
 func (e *Encoder) Encode(v any) ([]byte, error) {
+	return e.EncodeAppend(nil, v)
+}
+
+// EncodeAppend encodes v as Encode does and appends the document to dst,
+// returning the extended slice, or dst unchanged when v fails to encode. Hot
+// paths can pass a buffer kept from a previous call, truncated to zero length,
+// so that the document is written in place rather than into a new slice.
+func (e *Encoder) EncodeAppend(dst []byte, v any) ([]byte, error) {
 	e.depth = 0 // Reset depth counter
 
-	res, err := e.encode(v)
+	res, err := e.encodeRoot(v)
 	if err != nil {
-		return nil, err
+		pathErr, skip := e.childError(err, "")
+		switch {
+		case skip:
+			res = encodeNull()
+		case pathErr != nil:
+			return dst, pathErr
+		default:
+			return dst, err
+		}
+	}
+
+	size := 1 + len(res)
+	if e.MagicPrefix {
+		size += len(Magic)
+	}
+	if e.outputExceeded(size) {
+		return dst, &OutputSizeError{Limit: e.MaxOutputSize}
 	}
 
 	// todo: can i optimize by definiting the length of the slice before i copy into it?
@@ -88,7 +272,12 @@ func (e *Encoder) Encode(v any) ([]byte, error) {
 	// this might not work due to the fact that inner types are decoded first and we might know
 	//	their position is the backing list. but still work a short. the risk is that, we might need to
 	// padd, tradding size for speed
-	return append([]byte{Version}, res...), nil
+	dst = slices.Grow(dst, len(Magic)+1+len(res))
+	if e.MagicPrefix {
+		dst = append(dst, Magic[:]...)
+	}
+	dst = append(dst, Version)
+	return append(dst, res...), nil
 }
 
 // EncodeTo encodes a value directly to an io.Writer
@@ -102,6 +291,29 @@ func (e *Encoder) EncodeTo(w io.Writer, v any) error {
 	return err
 }
 
+// EncodeToTee encodes a value to w like EncodeTo, also writing the bytes that
+// reach w to tee. With a hash.Hash as tee, a message can be checksummed or
+// signed as it is written, without reading it back.
+func (e *Encoder) EncodeToTee(w, tee io.Writer, v any) error {
+	data, err := e.Encode(v)
+	if err != nil {
+		return err
+	}
+	return teeWrite(w, tee, data)
+}
+
+// teeWrite writes data to w and the part of it w accepted to tee, when tee is
+// not nil
+func teeWrite(w, tee io.Writer, data []byte) error {
+	n, err := w.Write(data)
+	if tee != nil {
+		if _, teeErr := tee.Write(data[:n]); err == nil {
+			err = teeErr
+		}
+	}
+	return err
+}
+
 // encode is the internal encoding function with depth tracking
 func (e *Encoder) encode(v any) ([]byte, error) {
 	// Check max depth
@@ -111,6 +323,11 @@ func (e *Encoder) encode(v any) ([]byte, error) {
 
 	// Handle null values
 	if isNullValue(v) {
+		if e.NilCollectionsAsEmpty {
+			if empty, ok := emptyCollection(v); ok {
+				return e.encode(empty)
+			}
+		}
 		return encodeNull(), nil
 	}
 
@@ -120,46 +337,182 @@ func (e *Encoder) encode(v any) ([]byte, error) {
 		if e.ValidateStrings && !isValidUTF8(val) {
 			return nil, fmt.Errorf("bogo encode error: invalid UTF-8 string")
 		}
+		if e.CompactStrings {
+			return encodeCompactString(val)
+		}
 		return encodeString(val)
 
 	case bool:
 		return encodeBool(val), nil
 
 	case byte:
+		if e.Uint8AsNumber {
+			return e.encodeUintWidth(reflect.Uint8, uint64(val))
+		}
 		return encodeByte(val)
 
 	case []byte:
 		return encodeBlob(val)
 
+	case map[string][]byte:
+		// Attachments and other binary-valued maps, without reflection
+		obj := make(map[string]any, len(val))
+		for key, b := range val {
+			obj[key] = b
+		}
+		return e.encodeObjectWithDepth(obj)
+
 	case time.Time:
-		return encodeTimestamp(val.UnixMilli())
+		return encodeTime(val, e.TimeFormat, e.ZeroTime)
+
+	case Expiry:
+		return encodeTime(val.Time(), e.TimeFormat, e.ZeroTime)
+
+	case Date:
+		return encodeDate(val)
+
+	case TimeOfDay:
+		return encodeTimeOfDay(val)
+
+	case *big.Int:
+		return encodeBigInt(val)
+
+	case *big.Float:
+		return encodeBigFloat(val)
+
+	case big.Int:
+		return encodeBigInt(&val)
+
+	case big.Float:
+		return encodeBigFloat(&val)
+
+	case Number:
+		return e.encode(val.value())
+
+	case Decimal:
+		return encodeDecimal(val)
+
+	case Union:
+		value, err := e.encode(val.Value)
+		if err != nil {
+			return nil, err
+		}
+		return buildUnion(val.Name, value)
+
+	case Expiring:
+		value, err := e.encode(val.Value)
+		if err != nil || val.Expiry.IsZero() {
+			return value, err
+		}
+		return encodeExpiring(val.Expiry, value)
+
+	case time.Duration:
+		if e.DurationsAsIntegers {
+			return e.encodeInt(int64(val))
+		}
+		return encodeDuration(val)
+
+	case rangeValue:
+		return encodeRange(val, e.encode)
+
+	case LocalizedString:
+		return e.encodeObjectWithDepth(map[string]any{val.Lang: val.Value})
+
+	case Char:
+		return encodeString(string(rune(val)))
+
+	case rune:
+		if e.RunesAsStrings {
+			return encodeString(string(val))
+		}
+		return e.encodeIntWidth(reflect.Int32, int64(val))
+
+	case int:
+		return e.encodeIntWidth(reflect.Int, int64(val))
+	case int8:
+		return e.encodeIntWidth(reflect.Int8, int64(val))
+	case int16:
+		return e.encodeIntWidth(reflect.Int16, int64(val))
+	case int64:
+		return e.encodeInt(val)
+	case uint:
+		return e.encodeUintWidth(reflect.Uint, uint64(val))
+	case uint16:
+		return e.encodeUintWidth(reflect.Uint16, uint64(val))
+	case uint32:
+		return e.encodeUintWidth(reflect.Uint32, uint64(val))
+	case uint64:
+		return e.encodeUint(val)
+
+	case []rune:
+		if e.RunesAsStrings {
+			return encodeRunes(val)
+		}
+		return e.encodeReflected(v)
+
+	case Graph:
+		obj, err := graphObject(val)
+		if err != nil {
+			return nil, err
+		}
+		return e.encodeObjectWithDepth(obj)
 
 	case []string:
-		if e.CompactLists {
+		if e.CompactLists && e.listDepth == 0 {
 			return e.encodeTypedListWithDepth(val)
 		}
 		return e.encodeListWithDepth(val)
 
 	case []int:
-		if e.CompactLists {
+		if e.CompactLists && e.listDepth == 0 && !e.NumericWidths {
 			return e.encodeTypedListWithDepth(val)
 		}
 		return e.encodeListWithDepth(val)
 
 	case []int64:
-		if e.CompactLists {
+		if e.CompactLists && e.listDepth == 0 {
 			return e.encodeTypedListWithDepth(val)
 		}
 		return e.encodeListWithDepth(val)
 
+	case float64:
+		return encodeFloatWithPolicy(val, e.NaNPolicy)
+
+	case float32:
+		return e.encodeFloatWidth(reflect.Float32, float64(val))
+
+	case complex128:
+		return encodeComplexWithPolicy(val, e.NaNPolicy)
+
+	case complex64:
+		return encodeComplexWithPolicy(complex128(val), e.NaNPolicy)
+
 	case []float64:
-		if e.CompactLists {
+		// Typed float lists cannot hold nulls, so lists with non-finite values
+		// are encoded element by element when the policy rewrites them
+		if e.NaNPolicy != NaNKeep {
+			if i := slices.IndexFunc(val, func(f float64) bool { return !isFinite(f) }); i >= 0 {
+				if e.NaNPolicy == NaNError {
+					return nil, fmt.Errorf("%w: %v at index %d", errNonFiniteFloat, val[i], i)
+				}
+				return e.encodeListWithDepth(val)
+			}
+		}
+		if e.CompactLists && e.listDepth == 0 {
 			return e.encodeTypedListWithDepth(val)
 		}
 		return e.encodeListWithDepth(val)
 
 	case []bool:
-		if e.CompactLists {
+		if e.CompactLists && e.listDepth == 0 {
+			return e.encodeTypedListWithDepth(val)
+		}
+		return e.encodeListWithDepth(val)
+
+	case []time.Time:
+		// Typed lists hold Unix milliseconds, which other formats and zero
+		// time policies do not write
+		if e.CompactLists && e.listDepth == 0 && e.TimeFormat == TimeFormatMillis && e.ZeroTime == ZeroTimeAsIs {
 			return e.encodeTypedListWithDepth(val)
 		}
 		return e.encodeListWithDepth(val)
@@ -168,6 +521,24 @@ func (e *Encoder) encode(v any) ([]byte, error) {
 		return e.encodeObjectWithDepth(val)
 
 	default:
+		if s, ok, err := enumValue(v); ok {
+			if err != nil {
+				return nil, err
+			}
+			return e.encodeEnum(s)
+		}
+		if data, ok, err := encodeExt(v); ok {
+			return data, err
+		}
+		if name, ok := registeredName(reflect.TypeOf(v)); ok {
+			return e.encodeRegistered(name, v)
+		}
+		if data, ok, err := encodeDecimalValue(v); ok {
+			return data, err
+		}
+		if data, ok, err := encodeCustom(v); ok {
+			return data, err
+		}
 		// Use reflection for complex types (including structs)
 		return e.encodeReflected(v)
 	}
@@ -178,7 +549,36 @@ func (e *Encoder) encodeListWithDepth(v any) ([]byte, error) {
 	e.depth++
 	defer func() { e.depth-- }()
 
-	return encodeList(v)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, wrapError(arrEncErr, "type is not a list type")
+	}
+
+	// Elements are encoded through the encoder so that its options apply to
+	// them. Lists nested in lists keep the untyped layout they have always had.
+	e.listDepth++
+	defer func() { e.listDepth-- }()
+
+	buf := bytes.Buffer{}
+	for i := 0; i < rv.Len(); i++ {
+		data, err := e.encode(rv.Index(i).Interface())
+		if err != nil {
+			pathErr, skip := e.childError(err, fmt.Sprintf("[%d]", i))
+			if pathErr != nil {
+				return nil, pathErr
+			}
+			if !skip {
+				return nil, fmt.Errorf("%w: error encoding element in list: %w", arrEncErr, err)
+			}
+			data = encodeNull()
+		}
+		buf.Write(data)
+		if e.outputExceeded(buf.Len()) {
+			return nil, e.outputSizeError(len(data), fmt.Sprintf("[%d]", i))
+		}
+	}
+
+	return buildContainer(TypeUntypedList, buf.Bytes())
 }
 
 // encodeTypedListWithDepth encodes typed lists with depth tracking
@@ -191,6 +591,12 @@ func (e *Encoder) encodeTypedListWithDepth(v any) ([]byte, error) {
 
 // encodeObjectWithDepth encodes objects with depth tracking
 func (e *Encoder) encodeObjectWithDepth(v map[string]any) ([]byte, error) {
+	return e.encodeOrderedObjectWithDepth(v, nil)
+}
+
+// encodeOrderedObjectWithDepth encodes objects with depth tracking, writing
+// the keys of v in the order of keys when it is not nil
+func (e *Encoder) encodeOrderedObjectWithDepth(v map[string]any, keys []string) ([]byte, error) {
 	// Check depth BEFORE incrementing
 	if e.MaxDepth > 0 && e.depth >= e.MaxDepth {
 		return nil, fmt.Errorf("bogo encode error: maximum nesting depth exceeded (%d)", e.MaxDepth)
@@ -212,20 +618,33 @@ func (e *Encoder) encodeObjectWithDepth(v map[string]any) ([]byte, error) {
 	}
 
 	// Encode the object with proper depth tracking
-	return e.encodeMapWithDepth(v)
+	return e.encodeMapWithDepth(v, keys)
 }
 
-// encodeMapWithDepth encodes a map with proper depth tracking and using the encoder
-func (e *Encoder) encodeMapWithDepth(obj map[string]any) ([]byte, error) {
+// encodeMapWithDepth encodes a map with proper depth tracking and using the
+// encoder. Keys are written in the order of keys when it is not nil, sorted
+// in canonical mode, and in map iteration order otherwise.
+func (e *Encoder) encodeMapWithDepth(obj map[string]any, keys []string) ([]byte, error) {
 	fieldsBuf := &bytes.Buffer{}
+	keyed := e.keyTableActive()
+
+	if keys == nil && e.CanonicalOrder != CanonicalOff {
+		keys = slices.Sorted(maps.Keys(obj))
+	}
 
 	// Encode each key-value pair as field entries
-	for key, value := range obj {
-		fieldEntry, err := e.encodeFieldEntryWithDepth(key, value)
-		if err != nil {
-			return nil, fmt.Errorf("bogo encode error: failed to encode field %s: %w", key, err)
+	if keys != nil {
+		for _, key := range keys {
+			if err := e.writeFieldEntry(fieldsBuf, key, obj[key], keyed); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for key, value := range obj {
+			if err := e.writeFieldEntry(fieldsBuf, key, value, keyed); err != nil {
+				return nil, err
+			}
 		}
-		fieldsBuf.Write(fieldEntry)
 	}
 
 	fieldsData := fieldsBuf.Bytes()
@@ -239,13 +658,45 @@ func (e *Encoder) encodeMapWithDepth(obj map[string]any) ([]byte, error) {
 
 	// Build final object: TypeObject + LenSize + DataSize + FieldData
 	result := &bytes.Buffer{}
-	result.WriteByte(TypeObject)
+	if keyed {
+		result.WriteByte(TypeKeyedObject)
+	} else {
+		result.WriteByte(TypeObject)
+	}
 	result.Write(encodedSizeData[1:]) // remove type byte from size encoding
 	result.Write(fieldsData)
 
 	return result.Bytes(), nil
 }
 
+// writeFieldEntry writes the field entry for key and value to buf, leaving
+// it out when the value is skipped by the UnsupportedKindPolicy. The key is
+// written as a reference to the symbol table when keyed is set.
+func (e *Encoder) writeFieldEntry(buf *bytes.Buffer, key string, value any, keyed bool) error {
+	var fieldEntry []byte
+	var err error
+	if keyed {
+		fieldEntry, err = e.encodeKeyedEntry(key, value)
+	} else {
+		fieldEntry, err = e.encodeFieldEntryWithDepth(key, value)
+	}
+	if err != nil {
+		pathErr, skip := e.childError(err, key)
+		if skip {
+			return nil
+		}
+		if pathErr != nil {
+			return pathErr
+		}
+		return fmt.Errorf("bogo encode error: failed to encode field %s: %w", key, err)
+	}
+	buf.Write(fieldEntry)
+	if e.outputExceeded(buf.Len()) {
+		return e.outputSizeError(len(fieldEntry), key)
+	}
+	return nil
+}
+
 // encodeFieldEntryWithDepth encodes a field entry using the encoder for depth tracking
 func (e *Encoder) encodeFieldEntryWithDepth(key string, value any) ([]byte, error) {
 	// Encode the value first to know its size using the encoder
@@ -285,17 +736,20 @@ func (e *Encoder) encodeReflected(v any) ([]byte, error) {
 	rv := reflect.ValueOf(v)
 	rt := reflect.TypeOf(v)
 
-	// Handle pointers
-	for rv.Kind() == reflect.Ptr {
+	// Handle pointers by encoding the pointed-to value, so that special types
+	// such as time.Time and Graph are recognised behind a pointer
+	if rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
 			return encodeNull(), nil
 		}
-		rv = rv.Elem()
-		rt = rt.Elem()
+		return e.encode(rv.Elem().Interface())
 	}
 
 	switch rv.Kind() {
 	case reflect.Struct:
+		if nullableValue(rt) != nil {
+			return e.encodeNullable(v.(driver.Valuer))
+		}
 		return e.encodeStruct(rv, rt)
 	case reflect.Slice, reflect.Array:
 		return e.encodeReflectedList(rv)
@@ -307,15 +761,56 @@ func (e *Encoder) encodeReflected(v any) ([]byte, error) {
 			return e.encode(rv.Interface())
 		}
 		return encodeNull(), nil
+	case reflect.Float32, reflect.Float64:
+		// Named float types
+		return e.encodeFloatWidth(rv.Kind(), rv.Float())
+	case reflect.Uint8:
+		// Named uint8 types
+		if e.Uint8AsNumber {
+			return e.encodeUintWidth(reflect.Uint8, rv.Uint())
+		}
+		return encodeByte(byte(rv.Uint()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.encodeIntWidth(rv.Kind(), rv.Int())
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return e.encodeUintWidth(rv.Kind(), rv.Uint())
+	case reflect.Complex64, reflect.Complex128:
+		// Named complex types
+		return encodeComplexWithPolicy(rv.Complex(), e.NaNPolicy)
+	case reflect.Chan, reflect.Func, reflect.Uintptr, reflect.UnsafePointer:
+		return nil, &UnsupportedTypeError{Type: rt}
 	default:
 		// Fall back to basic type encoding for other types
 		return encode(v)
 	}
 }
 
+// encodeInt encodes a signed integer, compactly if configured
+func (e *Encoder) encodeInt(v int64) ([]byte, error) {
+	if e.CompactIntegers {
+		return encodeCompactInt(v)
+	}
+	return encodeInt(v)
+}
+
+// encodeUint encodes an unsigned integer, compactly if configured
+func (e *Encoder) encodeUint(v uint64) ([]byte, error) {
+	if e.CompactIntegers {
+		return encodeCompactUint(v)
+	}
+	return encodeUint(v)
+}
+
 // encodeStruct converts a struct to a map[string]any and encodes it
 func (e *Encoder) encodeStruct(rv reflect.Value, rt reflect.Type) ([]byte, error) {
 	obj := make(map[string]any)
+	tagName := structTagName(rt, e.TagName)
+
+	// Field names in declaration order, for CanonicalDeclaration
+	var keys []string
+	if e.CanonicalOrder == CanonicalDeclaration {
+		keys = make([]string, 0, rt.NumField())
+	}
 
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
@@ -327,7 +822,7 @@ func (e *Encoder) encodeStruct(rv reflect.Value, rt reflect.Type) ([]byte, error
 		}
 
 		// Get field name from tag or use field name
-		fieldName := e.getFieldName(field)
+		fieldName := e.getFieldName(field, tagName)
 
 		// Skip if tag indicates to omit the field
 		if fieldName == "-" {
@@ -335,20 +830,23 @@ func (e *Encoder) encodeStruct(rv reflect.Value, rt reflect.Type) ([]byte, error
 		}
 
 		// Skip zero values if omitempty is specified
-		if e.shouldOmitEmpty(field) && e.isZeroValue(fieldValue) {
+		if e.shouldOmitEmpty(field, tagName) && e.isZeroValue(fieldValue) {
 			continue
 		}
 
 		// Recursively encode the field value
+		if _, seen := obj[fieldName]; !seen && keys != nil {
+			keys = append(keys, fieldName)
+		}
 		obj[fieldName] = fieldValue.Interface()
 	}
 
-	return e.encodeObjectWithDepth(obj)
+	return e.encodeOrderedObjectWithDepth(obj, keys)
 }
 
 // getFieldName returns the field name to use based on struct tags
-func (e *Encoder) getFieldName(field reflect.StructField) string {
-	tag := field.Tag.Get(e.TagName)
+func (e *Encoder) getFieldName(field reflect.StructField, tagName string) string {
+	tag := field.Tag.Get(tagName)
 	if tag == "" {
 		return field.Name
 	}
@@ -368,8 +866,8 @@ func (e *Encoder) getFieldName(field reflect.StructField) string {
 }
 
 // shouldOmitEmpty checks if the field has omitempty tag
-func (e *Encoder) shouldOmitEmpty(field reflect.StructField) bool {
-	tag := field.Tag.Get(e.TagName)
+func (e *Encoder) shouldOmitEmpty(field reflect.StructField, tagName string) bool {
+	tag := field.Tag.Get(tagName)
 	return len(tag) > 0 && (tag == "omitempty" || len(tag) > 10 && tag[len(tag)-10:] == ",omitempty")
 }
 
@@ -394,6 +892,25 @@ func (e *Encoder) isZeroValue(v reflect.Value) bool {
 
 // encodeReflectedList handles slice/list encoding via reflection
 func (e *Encoder) encodeReflectedList(rv reflect.Value) ([]byte, error) {
+	// Byte arrays, such as hashes, are blobs like byte slices
+	if rv.Kind() == reflect.Array && rv.Type().Elem() == byteType {
+		return encodeBlob(byteArray(rv))
+	}
+	// Lists of pointers take the layout of the values they point to
+	if values, ok := e.derefList(rv); ok {
+		return e.encode(values.Interface())
+	}
+	if e.CompactLists && e.listDepth == 0 && rv.Len() > 0 {
+		if data, ok := e.encodeTypedRows(rv); ok {
+			return data, nil
+		}
+	}
+	if e.ObjectLists && rv.Len() > 0 && isObjectListElem(rv.Type().Elem()) {
+		if data, ok := e.encodeObjectList(rv); ok {
+			return data, nil
+		}
+	}
+
 	length := rv.Len()
 	arr := make([]any, length)
 
@@ -404,13 +921,63 @@ func (e *Encoder) encodeReflectedList(rv reflect.Value) ([]byte, error) {
 	return e.encodeListWithDepth(arr)
 }
 
+// derefList returns the slice of the values the pointer elements of rv point
+// to, through any number of pointers, so that []*int and []*User encode as
+// []int and []User do. Lists holding nil pointers, or pointers encoded in
+// their own right, such as registered types and Marshalers, are left as is,
+// as are pointers to bytes and runes, whose slices encode as blobs and strings.
+func (e *Encoder) derefList(rv reflect.Value) (reflect.Value, bool) {
+	t := rv.Type().Elem()
+	if t.Kind() != reflect.Pointer {
+		return rv, false
+	}
+	for t.Kind() == reflect.Pointer {
+		if encodesPointer(t) {
+			return rv, false
+		}
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Uint8 || t.Kind() == reflect.Int32 && e.RunesAsStrings {
+		return rv, false
+	}
+
+	values := reflect.MakeSlice(reflect.SliceOf(t), rv.Len(), rv.Len())
+	for i := range rv.Len() {
+		elem := rv.Index(i)
+		for elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				return rv, false
+			}
+			elem = elem.Elem()
+		}
+		values.Index(i).Set(elem)
+	}
+	return values, true
+}
+
+// encodesPointer reports whether values of the pointer type t are encoded
+// otherwise than as the values they point to
+func encodesPointer(t reflect.Type) bool {
+	if t.Implements(marshalerType) || t.Implements(decimalValueType) {
+		return true
+	}
+	if _, ok := registeredName(t); ok {
+		return true
+	}
+	extMu.RLock()
+	defer extMu.RUnlock()
+	_, ok := extTypes[t]
+	return ok
+}
+
 // encodeReflectedMap handles map encoding via reflection
 func (e *Encoder) encodeReflectedMap(rv reflect.Value) ([]byte, error) {
-	obj := make(map[string]any)
-
-	for _, key := range rv.MapKeys() {
-		keyStr := fmt.Sprintf("%v", key.Interface())
-		obj[keyStr] = rv.MapIndex(key).Interface()
+	if !e.StringMapKeys && isIntegerKey(rv.Type().Key().Kind()) {
+		return e.encodeIntegerKeyedMap(rv)
+	}
+	obj, err := mapObject(rv, e.DuplicateKeys)
+	if err != nil {
+		return nil, err
 	}
 
 	return e.encodeObjectWithDepth(obj)
@@ -438,6 +1005,8 @@ type EncodingStats struct {
 type StatsCollector struct {
 	*Encoder
 	Stats EncodingStats
+
+	mu sync.Mutex // guards Stats against concurrent readers such as exporters
 }
 
 // NewStatsCollector creates an encoder that collects encoding statistics
@@ -453,6 +1022,10 @@ func NewStatsCollector(options ...EncoderOption) *StatsCollector {
 // Encode wraps the parent Encode with statistics collection
 func (sc *StatsCollector) Encode(v any) ([]byte, error) {
 	data, err := sc.Encoder.Encode(v)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
 	if err != nil {
 		sc.Stats.ErrorsCount++
 		return nil, err
@@ -465,7 +1038,7 @@ func (sc *StatsCollector) Encode(v any) ([]byte, error) {
 
 	// Count type usage (simplified - just count the main type)
 	if len(data) >= 2 {
-		typeVal := Type(data[1])
+		typeVal := Type(data[1]).baseType()
 		sc.Stats.TypesEncoded[typeVal]++
 	}
 
@@ -474,6 +1047,9 @@ func (sc *StatsCollector) Encode(v any) ([]byte, error) {
 
 // GetStats returns a copy of the current statistics
 func (sc *StatsCollector) GetStats() EncodingStats {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
 	stats := sc.Stats
 	// Deep copy the map
 	stats.TypesEncoded = make(map[Type]int)
@@ -485,6 +1061,9 @@ func (sc *StatsCollector) GetStats() EncodingStats {
 
 // ResetStats resets all statistics
 func (sc *StatsCollector) ResetStats() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
 	sc.Stats = EncodingStats{
 		TypesEncoded: make(map[Type]int),
 	}
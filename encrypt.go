@@ -0,0 +1,116 @@
+package bogo
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+var encryptErr = errors.New("bogo encryption error")
+
+// EncryptFields returns a copy of the object document data whose field values
+// are encrypted with aead, such as AES-GCM. Each value is replaced by a blob
+// holding a fresh nonce followed by the sealed value, authenticated together
+// with its key so values cannot be moved between fields.
+//
+// Keys, their order and the size of every entry stay in clear, so
+// intermediaries can list and look up fields with Fields and LookupField,
+// and route on them, without being able to read the values. Only top-level
+// values are encrypted; nested objects are sealed whole. The consumer
+// decrypts fields one at a time with DecryptField or ParseEncryptedObject, or
+// the whole document with DecryptFields.
+func EncryptFields(data []byte, aead cipher.AEAD) ([]byte, error) {
+	return transformFields(data, func(key, value []byte) ([]byte, error) {
+		sealed := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+		if _, err := rand.Read(sealed); err != nil {
+			return nil, wrapError(encryptErr, "failed to generate nonce", err.Error())
+		}
+		sealed = aead.Seal(sealed, sealed, value, key)
+		return encodeBlob(sealed)
+	})
+}
+
+// DecryptFields returns the document data encrypted by EncryptFields with its
+// values decrypted
+func DecryptFields(data []byte, aead cipher.AEAD) ([]byte, error) {
+	return transformFields(data, func(key, value []byte) ([]byte, error) {
+		return decryptValue(key, value, aead)
+	})
+}
+
+// DecryptField decrypts field key of the document data encrypted by
+// EncryptFields, leaving the other fields untouched. It returns the encoded
+// value as LookupField returns it for a document in clear.
+func DecryptField(data []byte, key string, aead cipher.AEAD) ([]byte, error) {
+	value, err := LookupField(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return decryptValue([]byte(key), value, aead)
+}
+
+// ParseEncryptedObject parses the keys of the document data encrypted by
+// EncryptFields. The values of the returned object are decrypted and decoded
+// on first access with Get; Raw returns them encrypted.
+func ParseEncryptedObject(data []byte, aead cipher.AEAD) (*LazyObject, error) {
+	o, err := ParseLazyObject(data)
+	if err != nil || o == nil {
+		return o, err
+	}
+	o.open = func(key string, value []byte) ([]byte, error) {
+		return decryptValue([]byte(key), value, aead)
+	}
+	return o, nil
+}
+
+// decryptValue opens the encrypted value of field key
+func decryptValue(key, value []byte, aead cipher.AEAD) ([]byte, error) {
+	if len(value) == 0 || Type(value[0]) != TypeBlob {
+		return nil, wrapError(encryptErr, fmt.Sprintf("field %q is not encrypted", key))
+	}
+	sealed, err := decodeBlob(value[1:])
+	if err != nil {
+		return nil, wrapError(encryptErr, err.Error())
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, wrapError(encryptErr, fmt.Sprintf("field %q is too short", key))
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, key)
+	if err != nil {
+		return nil, wrapError(encryptErr, fmt.Sprintf("field %q", key), err.Error())
+	}
+	if size, err := getElementSize(plain); err != nil || size != len(plain) {
+		return nil, wrapError(encryptErr, fmt.Sprintf("field %q holds a malformed value", key))
+	}
+	return plain, nil
+}
+
+// transformFields rebuilds the object document data with every value
+// replaced by fn, keeping the magic prefix when data has one
+func transformFields(data []byte, fn func(key, value []byte) ([]byte, error)) ([]byte, error) {
+	var entries []byte
+	var fnErr error
+	err := walkFields(data, nil, func(key, value []byte) bool {
+		var out []byte
+		if out, fnErr = fn(key, value); fnErr != nil {
+			return false
+		}
+		entries = appendObjectEntry(entries, string(key), out)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fnErr != nil {
+		return nil, fnErr
+	}
+
+	out := make([]byte, 0, len(Magic)+2+maxStorageByteLength+len(entries))
+	if hasMagic(data) {
+		out = append(out, Magic[:]...)
+	}
+	out = appendTypedHeader(append(out, Version), TypeObject, uint64(len(entries)))
+	return append(out, entries...), nil
+}
@@ -0,0 +1,107 @@
+package bogo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAEAD(t *testing.T, key byte) cipher.AEAD {
+	t.Helper()
+	block, err := aes.NewCipher(bytes.Repeat([]byte{key}, 32))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestEncryptFields(t *testing.T) {
+	aead := newTestAEAD(t, 1)
+	doc := map[string]any{
+		"tenant":  "acme",
+		"ssn":     "123-45-6789",
+		"balance": int64(1200),
+		"address": map[string]any{"city": "Paris"},
+		"closed":  nil,
+	}
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+
+	encrypted, err := EncryptFields(data, aead)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "123-45-6789")
+	assert.NotContains(t, string(encrypted), "Paris")
+
+	t.Run("keys stay readable", func(t *testing.T) {
+		fields, err := Fields(encrypted)
+		require.NoError(t, err)
+		var keys []string
+		for key, raw := range fields {
+			keys = append(keys, key)
+			assert.Equal(t, byte(TypeBlob), raw[0])
+		}
+		assert.ElementsMatch(t, []string{"tenant", "ssn", "balance", "address", "closed"}, keys)
+	})
+
+	t.Run("decrypts one field", func(t *testing.T) {
+		raw, err := DecryptField(encrypted, "ssn", aead)
+		require.NoError(t, err)
+		want, err := LookupField(data, "ssn")
+		require.NoError(t, err)
+		assert.Equal(t, want, raw)
+	})
+
+	t.Run("decrypts the document", func(t *testing.T) {
+		plain, err := DecryptFields(encrypted, aead)
+		require.NoError(t, err)
+		decoded, err := Decode(plain)
+		require.NoError(t, err)
+		assert.Equal(t, doc, decoded)
+	})
+
+	t.Run("decrypts lazily", func(t *testing.T) {
+		obj, err := ParseEncryptedObject(encrypted, aead)
+		require.NoError(t, err)
+		assert.True(t, obj.Has("balance"))
+		v, err := obj.Get("balance")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1200), v)
+		m, err := obj.Map()
+		require.NoError(t, err)
+		assert.Equal(t, doc, m)
+	})
+
+	t.Run("keeps the magic prefix", func(t *testing.T) {
+		data, err := NewConfigurableEncoder(WithMagicPrefix(true)).Encode(doc)
+		require.NoError(t, err)
+		encrypted, err := EncryptFields(data, aead)
+		require.NoError(t, err)
+		assert.True(t, IsBogo(encrypted))
+		plain, err := DecryptFields(encrypted, aead)
+		require.NoError(t, err)
+		assert.True(t, IsBogo(plain))
+	})
+
+	t.Run("rejects the wrong key", func(t *testing.T) {
+		_, err := DecryptField(encrypted, "ssn", newTestAEAD(t, 2))
+		assert.ErrorIs(t, err, encryptErr)
+	})
+
+	t.Run("values cannot be moved between fields", func(t *testing.T) {
+		ssn, err := LookupField(encrypted, "ssn")
+		require.NoError(t, err)
+		swapped, err := Document(encrypted).Set("tenant", RawValue(append([]byte{Version}, ssn...)))
+		require.NoError(t, err)
+		_, err = DecryptField(swapped, "tenant", aead)
+		assert.ErrorIs(t, err, encryptErr)
+	})
+
+	t.Run("rejects fields in clear", func(t *testing.T) {
+		_, err := DecryptFields(data, aead)
+		assert.ErrorContains(t, err, "is not encrypted")
+	})
+}
@@ -0,0 +1,345 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+var enumErr = errors.New("bogo enum error")
+
+// A document holding values of registered enum types is laid out as
+//
+//	TypeSymbols | size | symbols | value
+//
+// where symbols are the distinct enum values of the document, each a
+// TypeString value, and size is their encoded size, a uvarint preceded by its
+// 1-byte length. Within value, an enum value is
+//
+//	TypeSymbol | index
+//
+// with the index of its symbol a uvarint preceded by its 1-byte length.
+// Symbols refer to the table of the innermost TypeSymbols around them.
+
+var (
+	enumMu sync.RWMutex
+	// enums maps the types registered with RegisterEnum to their values
+	enums = map[reflect.Type]map[string]bool{}
+	// hasEnums spares encoders the registry lookup until an enum is registered
+	hasEnums atomic.Bool
+)
+
+// RegisterEnum records values as the valid values of the string enum type T,
+// such as a Status with the constants StatusActive and StatusSuspended.
+// Encoders write each distinct value of T once, in a symbol table at the start
+// of the document, and refer to it with a small integer wherever it occurs,
+// which shrinks documents that repeat status or category fields. Values of T
+// decode as strings, and as T when it is the destination.
+//
+// Encoding a value of T that is not among values fails, as does decoding one
+// into T. RegisterEnum panics when T is already registered or values is
+// empty. It is meant to be called from init functions.
+func RegisterEnum[T ~string](values ...T) {
+	t := reflect.TypeFor[T]()
+	if len(values) == 0 {
+		panic(fmt.Sprintf("bogo: RegisterEnum: no values for %s", t))
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[string(v)] = true
+	}
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	if _, ok := enums[t]; ok {
+		panic(fmt.Sprintf("bogo: RegisterEnum: %s already registered", t))
+	}
+	enums[t] = set
+	hasEnums.Store(true)
+}
+
+// WithEnumsAsStrings writes values of registered enum types as plain strings,
+// without a symbol table, for readers that expect strings on the wire
+func WithEnumsAsStrings(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.EnumsAsStrings = enabled
+	}
+}
+
+// enumValues returns the values t was registered with
+func enumValues(t reflect.Type) (map[string]bool, bool) {
+	if !hasEnums.Load() {
+		return nil, false
+	}
+	enumMu.RLock()
+	defer enumMu.RUnlock()
+	values, ok := enums[t]
+	return values, ok
+}
+
+// enumValue returns v as a string when its type is a registered enum, failing
+// when it is not one of the registered values
+func enumValue(v any) (string, bool, error) {
+	t := reflect.TypeOf(v)
+	values, ok := enumValues(t)
+	if !ok {
+		return "", false, nil
+	}
+	s := reflect.ValueOf(v).String()
+	if !values[s] {
+		return s, true, wrapError(enumErr, fmt.Sprintf("invalid %s value %q", t, s))
+	}
+	return s, true, nil
+}
+
+// checkEnum fails when t is a registered enum and s is not one of its values
+func checkEnum(t reflect.Type, s string) error {
+	if values, ok := enumValues(t); ok && !values[s] {
+		return wrapError(enumErr, fmt.Sprintf("invalid %s value %q", t, s))
+	}
+	return nil
+}
+
+// symbolTable collects the enum values of the document being encoded
+type symbolTable struct {
+	index   map[string]uint64
+	symbols []string
+}
+
+// ref returns the encoded reference to the symbol s, adding it to the table
+func (t *symbolTable) ref(s string) []byte {
+	i, ok := t.index[s]
+	if !ok {
+		if t.index == nil {
+			t.index = make(map[string]uint64)
+		}
+		i = uint64(len(t.symbols))
+		t.index[s] = i
+		t.symbols = append(t.symbols, s)
+	}
+	return appendUvarintLen([]byte{byte(TypeSymbol)}, i)
+}
+
+// wrap returns value under the symbols of the table
+func (t *symbolTable) wrap(value []byte) ([]byte, error) {
+	var table []byte
+	for _, s := range t.symbols {
+		symbol, err := encodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		table = append(table, symbol...)
+	}
+	out := make([]byte, 0, 2+maxStorageByteLength+len(table)+len(value))
+	out = appendTypedHeader(out, TypeSymbols, uint64(len(table)))
+	return append(append(out, table...), value...), nil
+}
+
+// encodeRoot encodes v as the top-level value of a document, under a symbol
+// table when it holds values of registered enum types or, with a key table,
+// objects
+func (e *Encoder) encodeRoot(v any) ([]byte, error) {
+	if (!hasEnums.Load() || e.EnumsAsStrings) && !e.KeyTable {
+		return e.encode(v)
+	}
+	// The table lives in a copy, as encoders such as the one behind Marshal
+	// are shared
+	c := *e
+	c.symbols = &symbolTable{}
+	res, err := c.encode(v)
+	if err != nil || len(c.symbols.symbols) == 0 {
+		return res, err
+	}
+	return c.symbols.wrap(res)
+}
+
+// encodeEnum encodes s, a value of a registered enum, as a reference to the
+// symbol table of the document when there is one
+func (e *Encoder) encodeEnum(s string) ([]byte, error) {
+	if e.symbols == nil || e.EnumsAsStrings {
+		return e.encode(s)
+	}
+	return e.symbols.ref(s), nil
+}
+
+// symbolIndex reads the index of the symbol data, after its type byte,
+// returning it and its encoded size
+func symbolIndex(data []byte) (uint64, int, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return 0, 0, wrapError(enumErr, "insufficient data for symbol")
+	}
+	sizeLen := int(data[0])
+	index, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return 0, 0, wrapError(enumErr, err.Error())
+	}
+	return index, 1 + sizeLen, nil
+}
+
+// symbolsTable returns the encoded symbols of the table data, after its type
+// byte, and the offset of the value below them
+func symbolsTable(data []byte) ([]byte, int, error) {
+	if len(data) == 0 {
+		return nil, 0, wrapError(enumErr, "insufficient data for symbols")
+	}
+	table, err := containerPayload(data)
+	if err != nil {
+		return nil, 0, wrapError(enumErr, err.Error())
+	}
+	offset := 1 + int(data[0]) + len(table)
+	if offset >= len(data) {
+		return nil, 0, wrapError(enumErr, "insufficient data for value")
+	}
+	return table, offset, nil
+}
+
+// walkSymbols calls fn with each encoded symbol of table
+func walkSymbols(table []byte, fn func(symbol []byte) error) error {
+	for pos := 0; pos < len(table); {
+		if Type(table[pos]) != TypeString {
+			return wrapError(enumErr, fmt.Sprintf("symbol of type %s", Type(table[pos])))
+		}
+		size, err := getElementSize(table[pos:])
+		if err != nil {
+			return wrapError(enumErr, err.Error())
+		}
+		if pos+size > len(table) {
+			return wrapError(enumErr, "insufficient data for symbol")
+		}
+		if err := fn(table[pos : pos+size]); err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
+
+// symbolsSize returns the size of the symbol table data, after its type byte,
+// including the value below it
+func symbolsSize(data []byte) (int, error) {
+	_, offset, err := symbolsTable(data)
+	if err != nil {
+		return 0, err
+	}
+	size, err := getElementSize(data[offset:])
+	if err != nil {
+		return 0, err
+	}
+	return offset + size, nil
+}
+
+// readSymbols decodes the symbols of the table data, after its type byte,
+// returning them and the offset of the value below them
+func readSymbols(data []byte, strs stringMaker) ([]string, int, error) {
+	table, offset, err := symbolsTable(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	symbols, err := decodeSymbolList(table, strs)
+	return symbols, offset, err
+}
+
+// tableSymbols decodes the symbols of the table header table, as splitSymbols
+// returns it. An empty header has no symbols.
+func tableSymbols(table []byte) ([]string, error) {
+	if len(table) == 0 {
+		return nil, nil
+	}
+	encoded, err := containerPayload(table[1:])
+	if err != nil {
+		return nil, wrapError(enumErr, err.Error())
+	}
+	return decodeSymbolList(encoded, stringMaker{})
+}
+
+// decodeSymbolList decodes the encoded symbols of a table
+func decodeSymbolList(table []byte, strs stringMaker) ([]string, error) {
+	var symbols []string
+	err := walkSymbols(table, func(symbol []byte) error {
+		s, err := decodeStringWith(symbol[2:], int(symbol[1]), strs)
+		if err != nil {
+			return wrapError(enumErr, err.Error())
+		}
+		symbols = append(symbols, s.(string))
+		return nil
+	})
+	return symbols, err
+}
+
+// decodeSymbolsWith decodes the value below the symbol table data, after its
+// type byte, with decode, resolving its symbols against the table
+func decodeSymbolsWith(data []byte, strs stringMaker, decode func([]byte, stringMaker) (any, error)) (any, error) {
+	symbols, offset, err := readSymbols(data, strs)
+	if err != nil {
+		return nil, err
+	}
+	strs.symbols = symbols
+	return decode(data[offset:], strs)
+}
+
+// decodeSymbols decodes the symbol table data, after its type byte, making
+// its symbols those of the decoder while the value below it is decoded
+func (d *Decoder) decodeSymbols(data []byte, decode func([]byte) (any, error)) (any, error) {
+	return decodeSymbolsWith(data, d.strings(), func(value []byte, strs stringMaker) (any, error) {
+		saved := d.symbols
+		d.symbols = strs.symbols
+		defer func() { d.symbols = saved }()
+		return decode(value)
+	})
+}
+
+// splitSymbols returns the header of the symbol table around value, from its
+// type byte to the value below it, and that value. Nested tables are skipped
+// down to the innermost one, which the symbols of the value refer to. A value
+// without a table has an empty header.
+func splitSymbols(value []byte) (table, inner []byte, err error) {
+	for len(value) > 0 && Type(value[0]) == TypeSymbols {
+		_, offset, err := symbolsTable(value[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		table, value = value[:1+offset], value[1+offset:]
+	}
+	return table, value, nil
+}
+
+// withSymbols returns value, found below the symbol table header table, under
+// a copy of the table when it may refer to it, so that it decodes on its own
+func withSymbols(table, value []byte) []byte {
+	if len(table) == 0 || !holdsSymbols(Type(value[0])) {
+		return value
+	}
+	out := make([]byte, 0, len(table)+len(value))
+	return append(append(out, table...), value...)
+}
+
+// holdsSymbols reports whether values of type t may refer to the symbol table
+// around them
+func holdsSymbols(t Type) bool {
+	switch t {
+	case TypeSymbol, TypeObject, TypeKeyedObject, TypeUntypedList, TypeChunkedList,
+		TypeMap, TypeObjectList, TypeExpiring, TypeUnion:
+		return true
+	}
+	return false
+}
+
+// underSymbols returns value with the value below its symbol tables replaced
+// by the result of edit, keeping the tables. edit is passed the symbols of the
+// innermost table, or symbols when value has none.
+func underSymbols(value []byte, symbols []string, edit func(inner []byte, symbols []string) ([]byte, error)) ([]byte, error) {
+	if len(value) == 0 || Type(value[0]) != TypeSymbols {
+		return edit(value, symbols)
+	}
+	symbols, offset, err := readSymbols(value[1:], stringMaker{})
+	if err != nil {
+		return nil, err
+	}
+	inner, err := underSymbols(value[1+offset:], symbols, edit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 1+offset+len(inner))
+	return append(append(out, value[:1+offset]...), inner...), nil
+}
@@ -0,0 +1,314 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStatus string
+
+const (
+	statusActive    testStatus = "active"
+	statusSuspended testStatus = "suspended"
+)
+
+type testCategory string
+
+func init() {
+	RegisterEnum(statusActive, statusSuspended)
+	RegisterEnum[testCategory]("book", "film")
+}
+
+func TestEnums(t *testing.T) {
+	type account struct {
+		Name     string         `json:"name"`
+		Status   testStatus     `json:"status"`
+		History  []testStatus   `json:"history"`
+		Category *testCategory  `json:"category"`
+		Labels   map[string]any `json:"labels"`
+	}
+	category := testCategory("film")
+	in := account{
+		Name:     "Ama",
+		Status:   statusActive,
+		History:  []testStatus{statusSuspended, statusActive, statusActive},
+		Category: &category,
+		Labels:   map[string]any{"previous": statusSuspended},
+	}
+
+	data, err := Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, byte(TypeSymbols), data[1])
+
+	var out account
+	require.NoError(t, Unmarshal(data, &out))
+	assert.Equal(t, in.Status, out.Status)
+	assert.Equal(t, in.History, out.History)
+	assert.Equal(t, in.Category, out.Category)
+
+	want := map[string]any{
+		"name":     "Ama",
+		"status":   "active",
+		"history":  []any{"suspended", "active", "active"},
+		"category": "film",
+		"labels":   map[string]any{"previous": "suspended"},
+	}
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, want, decoded)
+	decoded, err = NewConfigurableDecoder(WithDecoderStrictMode(true)).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, want, decoded)
+
+	_, err = Footprint(data)
+	assert.NoError(t, err)
+
+	t.Run("smaller than strings", func(t *testing.T) {
+		history := make([]testStatus, 100)
+		for i := range history {
+			history[i] = statusSuspended
+		}
+		symbols, err := Marshal(history)
+		require.NoError(t, err)
+		strs, err := NewConfigurableEncoder(WithEnumsAsStrings(true)).Encode(history)
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), strs[1])
+		assert.Less(t, len(symbols), len(strs)/2)
+
+		var got []testStatus
+		require.NoError(t, Unmarshal(strs, &got))
+		assert.Equal(t, history, got)
+	})
+
+	t.Run("lazy objects and lists", func(t *testing.T) {
+		lazy, err := NewConfigurableDecoder(WithLazyObjects(true)).Decode(data)
+		require.NoError(t, err)
+		status, err := lazy.(*LazyObject).Get("status")
+		require.NoError(t, err)
+		assert.Equal(t, "active", status)
+
+		list, err := Marshal([]testStatus{statusActive, statusSuspended})
+		require.NoError(t, err)
+		var got []testStatus
+		require.NoError(t, DecodeListInto(list, func(s testStatus) error {
+			got = append(got, s)
+			return nil
+		}))
+		assert.Equal(t, []testStatus{statusActive, statusSuspended}, got)
+	})
+
+	t.Run("documents without enums have no table", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"status": "active"})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeObject), data[1])
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := Marshal(testStatus("deleted"))
+		assert.ErrorContains(t, err, `invalid bogo.testStatus value "deleted"`)
+
+		data, err := Marshal(map[string]any{"status": "deleted"})
+		require.NoError(t, err)
+		var out account
+		assert.ErrorContains(t, Unmarshal(data, &out), `invalid bogo.testStatus value "deleted"`)
+	})
+
+	t.Run("symbols outside the table", func(t *testing.T) {
+		_, err := Decode(append([]byte{Version}, appendUvarintLen([]byte{byte(TypeSymbol)}, 0)...))
+		assert.ErrorContains(t, err, "outside a table of 0")
+
+		bad, err := Marshal([]testStatus{statusActive})
+		require.NoError(t, err)
+		bad[len(bad)-1] = 1 // the index of the only reference
+		_, err = Decode(bad)
+		assert.ErrorContains(t, err, "symbol 1 outside a table of 1")
+		_, err = NewConfigurableDecoder().Decode(bad)
+		assert.ErrorContains(t, err, "symbol 1 outside a table of 1")
+	})
+
+	t.Run("truncated tables", func(t *testing.T) {
+		assertRejected(t, []byte{Version, byte(TypeSymbols)})
+		assertRejected(t, []byte{Version, byte(TypeSymbols), 1})
+		assertRejected(t, []byte{Version, byte(TypeSymbols), 1, 9, byte(TypeString)})
+	})
+
+	t.Run("registering twice panics", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterEnum(statusActive) })
+		assert.Panics(t, func() { RegisterEnum[testStatus]() })
+	})
+}
+
+func TestEnumDocuments(t *testing.T) {
+	// The byte-level APIs read through the symbol table at the root
+	data, err := Marshal(map[string]any{
+		"name":    "Ama",
+		"status":  statusActive,
+		"history": []any{statusSuspended},
+		"profile": map[string]any{"status": statusSuspended, "token": ExpiresIn("t0k3n", time.Hour)},
+		"session": ExpiresIn(statusActive, time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, byte(TypeSymbols), data[1])
+
+	decodeField := func(t *testing.T, data []byte, key string) any {
+		t.Helper()
+		raw, err := LookupField(data, key)
+		require.NoError(t, err)
+		v, err := Decode(append([]byte{Version}, raw...))
+		require.NoError(t, err)
+		return v
+	}
+
+	t.Run("fields and accessors", func(t *testing.T) {
+		fields, err := Fields(data)
+		require.NoError(t, err)
+		keys := 0
+		for key, raw := range fields {
+			_, err := decodeValue(raw)
+			assert.NoError(t, err, key)
+			keys++
+		}
+		assert.Equal(t, 5, keys)
+
+		assert.Equal(t, "active", decodeField(t, data, "status"))
+		assert.Equal(t, []any{"suspended"}, decodeField(t, data, "history"))
+		status, err := FieldString(data, "status")
+		require.NoError(t, err)
+		assert.Equal(t, "active", status)
+		name, err := FieldString(data, "name")
+		require.NoError(t, err)
+		assert.Equal(t, "Ama", name)
+
+		profile, err := FieldObject(data, "profile")
+		require.NoError(t, err)
+		status, err = FieldString(profile, "status")
+		require.NoError(t, err)
+		assert.Equal(t, "suspended", status)
+	})
+
+	t.Run("documents", func(t *testing.T) {
+		doc := Document(data)
+		raw, err := doc.Get("profile.status")
+		require.NoError(t, err)
+		status, err := decodeValue(raw)
+		require.NoError(t, err)
+		assert.Equal(t, "suspended", status)
+
+		values, err := doc.Select("status", "history[0]")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"status": "active", "history[0]": "suspended"}, values)
+
+		doc, err = doc.Set("profile.status", statusActive)
+		require.NoError(t, err)
+		doc, err = doc.Set("name", "Kofi")
+		require.NoError(t, err)
+		doc, err = doc.Delete("session")
+		require.NoError(t, err)
+
+		decoded, err := Decode(doc)
+		require.NoError(t, err)
+		obj := decoded.(map[string]any)
+		assert.Equal(t, "Kofi", obj["name"])
+		assert.Equal(t, "active", obj["status"])
+		assert.Equal(t, "active", obj["profile"].(map[string]any)["status"])
+		assert.NotContains(t, obj, "session")
+	})
+
+	t.Run("append to lists", func(t *testing.T) {
+		appended, err := AppendToList(append([]byte(nil), data...), "history", statusActive)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"suspended", "active"}, decodeField(t, appended, "history"))
+		assert.Equal(t, "active", decodeField(t, appended, "status"))
+	})
+
+	t.Run("transforms", func(t *testing.T) {
+		tr, err := CompileTransform(
+			RenameField("status", "state"),
+			CastField("profile.status", TypeString),
+			MoveField("history", "profile.history"),
+		)
+		require.NoError(t, err)
+		out, err := tr.Apply(data)
+		require.NoError(t, err)
+
+		decoded, err := Decode(out)
+		require.NoError(t, err)
+		obj := decoded.(map[string]any)
+		assert.Equal(t, "active", obj["state"])
+		assert.NotContains(t, obj, "status")
+		profile := obj["profile"].(map[string]any)
+		assert.Equal(t, "suspended", profile["status"])
+		assert.Equal(t, []any{"suspended"}, profile["history"])
+	})
+
+	t.Run("encrypted fields", func(t *testing.T) {
+		aead := newTestAEAD(t, 1)
+		encrypted, err := EncryptFields(data, aead)
+		require.NoError(t, err)
+
+		raw, err := DecryptField(encrypted, "status", aead)
+		require.NoError(t, err)
+		status, err := decodeValue(raw)
+		require.NoError(t, err)
+		assert.Equal(t, "active", status)
+
+		plain, err := DecryptFields(encrypted, aead)
+		require.NoError(t, err)
+		want, err := Decode(data)
+		require.NoError(t, err)
+		got, err := Decode(plain)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("pruning", func(t *testing.T) {
+		pruned, err := Prune(data, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeSymbols), pruned[1])
+
+		decoded, err := Decode(pruned)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"name":    "Ama",
+			"status":  "active",
+			"history": []any{"suspended"},
+			"profile": map[string]any{"status": "suspended"},
+		}, decoded)
+	})
+
+	t.Run("salvage", func(t *testing.T) {
+		want, err := Decode(data)
+		require.NoError(t, err)
+		var warnings []WarnEvent
+		got, err := NewConfigurableDecoder(WithSalvage(true), WithWarnHandler(func(e WarnEvent) {
+			warnings = append(warnings, e)
+		})).Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("selections", func(t *testing.T) {
+		got, err := DecodeSelection(data, Selection{"status": nil, "profile": {"status": nil}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"status":  "active",
+			"profile": map[string]any{"status": "suspended"},
+		}, got)
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		got, err := NewConfigurableDecoder(WithTypedValues(true)).Decode(data)
+		require.NoError(t, err)
+		root := got.(TypedValue)
+		assert.Equal(t, Type(TypeObject), root.Type)
+		obj := root.Value.(map[string]any)
+		assert.Equal(t, TypedValue{Type: TypeSymbol, Value: "active"}, obj["status"])
+		assert.Equal(t, TypedValue{Type: TypeUntypedList, Value: []any{
+			TypedValue{Type: TypeSymbol, Value: "suspended"},
+		}}, obj["history"])
+	})
+}
@@ -0,0 +1,176 @@
+package bogo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// EnvelopeMagic starts every enveloped document
+var EnvelopeMagic = [4]byte{'B', 'O', 'G', 'O'}
+
+// EnvelopeVersion is the envelope layout written by EncodeEnveloped
+const EnvelopeVersion byte = 1
+
+// ErrNotEnveloped is returned when data does not start with EnvelopeMagic
+var ErrNotEnveloped = errors.New("bogo: data is not enveloped")
+
+var envelopeErr = errors.New("bogo envelope error")
+
+// Envelope header flags, recording which optional fields are present
+const (
+	envelopeContentType byte = 1 << iota
+	envelopeSchema
+	envelopeCreatedAt
+
+	envelopeKnownFlags = envelopeContentType | envelopeSchema | envelopeCreatedAt
+)
+
+// EnvelopeHeader is the metadata an envelope carries ahead of its payload, so
+// that storage systems can identify and route documents without decoding
+// them. Zero fields are omitted from the envelope.
+type EnvelopeHeader struct {
+	ContentType   string    // Media type of the payload, e.g. "application/vnd.acme.order"
+	SchemaID      string    // Identifier of the payload schema
+	SchemaVersion uint64    // Version of the payload schema, written when SchemaID is set
+	CreatedAt     time.Time // Creation time, kept to the millisecond
+}
+
+// An enveloped document is laid out as
+//
+//	magic(4) | version(1) | flags(1) | [content type] | [schema id, schema version] | [created at] | payload
+//
+// Strings and the payload are length prefixed as WriteLengthPrefixed writes
+// them, the schema version is a size prefix and the creation time is a varint
+// of Unix milliseconds. The payload is a complete bogo document.
+
+// EncodeEnveloped encodes v with the default encoder and wraps it in an
+// envelope carrying header
+func EncodeEnveloped(v any, header EnvelopeHeader) ([]byte, error) {
+	return defaultEncoder.EncodeEnveloped(v, header)
+}
+
+// EncodeEnveloped encodes v and wraps it in an envelope carrying header
+func (e *Encoder) EncodeEnveloped(v any, header EnvelopeHeader) ([]byte, error) {
+	payload, err := e.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return Envelop(payload, header), nil
+}
+
+// Envelop wraps an already encoded document in an envelope carrying header
+func Envelop(payload []byte, header EnvelopeHeader) []byte {
+	var flags byte
+	if header.ContentType != "" {
+		flags |= envelopeContentType
+	}
+	if header.SchemaID != "" {
+		flags |= envelopeSchema
+	}
+	if !header.CreatedAt.IsZero() {
+		flags |= envelopeCreatedAt
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 32+len(payload)))
+	buf.Write(EnvelopeMagic[:])
+	buf.WriteByte(EnvelopeVersion)
+	buf.WriteByte(flags)
+	if flags&envelopeContentType != 0 {
+		_, _ = WriteLengthPrefixed(buf, []byte(header.ContentType))
+	}
+	if flags&envelopeSchema != 0 {
+		_, _ = WriteLengthPrefixed(buf, []byte(header.SchemaID))
+		_, _ = WriteUvarintLen(buf, header.SchemaVersion)
+	}
+	if flags&envelopeCreatedAt != 0 {
+		buf.Write(binary.AppendVarint(nil, header.CreatedAt.UnixMilli()))
+	}
+	_, _ = WriteLengthPrefixed(buf, payload)
+	return buf.Bytes()
+}
+
+// DecodeEnveloped unwraps an enveloped document, decodes its payload into v
+// with the default decoder, and returns the envelope header
+func DecodeEnveloped(data []byte, v any) (EnvelopeHeader, error) {
+	return defaultDecoder.DecodeEnveloped(data, v)
+}
+
+// DecodeEnveloped unwraps an enveloped document, decodes its payload into v,
+// and returns the envelope header
+func (d *Decoder) DecodeEnveloped(data []byte, v any) (EnvelopeHeader, error) {
+	header, payload, err := OpenEnvelope(data)
+	if err != nil {
+		return EnvelopeHeader{}, err
+	}
+	return header, d.Unmarshal(payload, v)
+}
+
+// OpenEnvelope reads the header of an enveloped document and returns it with
+// the payload, which refers to data, without decoding the payload. It returns
+// ErrNotEnveloped when data does not start with EnvelopeMagic.
+func OpenEnvelope(data []byte) (EnvelopeHeader, []byte, error) {
+	var header EnvelopeHeader
+	if !bytes.HasPrefix(data, EnvelopeMagic[:]) {
+		return header, nil, ErrNotEnveloped
+	}
+	if len(data) < len(EnvelopeMagic)+2 {
+		return header, nil, wrapError(envelopeErr, "insufficient data for envelope header")
+	}
+	if version := data[len(EnvelopeMagic)]; version != EnvelopeVersion {
+		return header, nil, wrapError(envelopeErr, fmt.Sprintf("unsupported envelope version %d", version))
+	}
+	flags := data[len(EnvelopeMagic)+1]
+	if flags&^envelopeKnownFlags != 0 {
+		return header, nil, wrapError(envelopeErr, fmt.Sprintf("unknown envelope flags %#x", flags&^envelopeKnownFlags))
+	}
+
+	r := bytes.NewReader(data[len(EnvelopeMagic)+2:])
+	if flags&envelopeContentType != 0 {
+		contentType, err := ReadLengthPrefixed(r)
+		if err != nil {
+			return header, nil, envelopeFieldError("content type", err)
+		}
+		header.ContentType = string(contentType)
+	}
+	if flags&envelopeSchema != 0 {
+		schemaID, err := ReadLengthPrefixed(r)
+		if err != nil {
+			return header, nil, envelopeFieldError("schema id", err)
+		}
+		header.SchemaID = string(schemaID)
+		if header.SchemaVersion, err = ReadUvarintLen(r); err != nil {
+			return header, nil, envelopeFieldError("schema version", err)
+		}
+	}
+	if flags&envelopeCreatedAt != 0 {
+		millis, err := binary.ReadVarint(r)
+		if err != nil {
+			return header, nil, envelopeFieldError("creation time", err)
+		}
+		header.CreatedAt = time.UnixMilli(millis).UTC()
+	}
+
+	size, err := ReadUvarintLen(r)
+	if err != nil {
+		return header, nil, envelopeFieldError("payload", err)
+	}
+	if size > uint64(r.Len()) {
+		return header, nil, envelopeFieldError("payload", io.ErrUnexpectedEOF)
+	}
+	if trailing := r.Len() - int(size); trailing > 0 {
+		return header, nil, wrapError(envelopeErr, fmt.Sprintf("%d trailing bytes after the payload", trailing))
+	}
+	return header, data[len(data)-r.Len():], nil
+}
+
+// envelopeFieldError reports an envelope field that could not be read
+func envelopeFieldError(field string, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: reading %s: %w", envelopeErr, field, err)
+}
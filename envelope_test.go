@@ -0,0 +1,102 @@
+package bogo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	header := EnvelopeHeader{
+		ContentType:   "application/vnd.acme.order",
+		SchemaID:      "order",
+		SchemaVersion: 3,
+		CreatedAt:     time.UnixMilli(1700000000123).UTC(),
+	}
+	order := map[string]any{"id": "o-1", "total": int64(42)}
+
+	data, err := EncodeEnveloped(order, header)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, EnvelopeMagic[:]))
+
+	var decoded map[string]any
+	got, err := DecodeEnveloped(data, &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, header, got)
+	assert.Equal(t, order, decoded)
+
+	t.Run("open without decoding", func(t *testing.T) {
+		got, payload, err := OpenEnvelope(data)
+		require.NoError(t, err)
+		assert.Equal(t, header, got)
+
+		var decoded map[string]any
+		require.NoError(t, Unmarshal(payload, &decoded))
+		assert.Equal(t, order, decoded)
+	})
+
+	t.Run("optional fields", func(t *testing.T) {
+		for _, header := range []EnvelopeHeader{
+			{},
+			{ContentType: "text/plain"},
+			{SchemaID: "s"},
+			{CreatedAt: time.UnixMilli(-5).UTC()},
+		} {
+			data, err := EncodeEnveloped("x", header)
+			require.NoError(t, err)
+
+			var s string
+			got, err := DecodeEnveloped(data, &s)
+			require.NoError(t, err)
+			assert.Equal(t, header, got)
+			assert.Equal(t, "x", s)
+		}
+
+		minimal, err := EncodeEnveloped(nil, EnvelopeHeader{})
+		require.NoError(t, err)
+		assert.Len(t, minimal, len(EnvelopeMagic)+2+2+2, "magic, version, flags and a two byte payload")
+	})
+
+	t.Run("creation time is kept to the millisecond", func(t *testing.T) {
+		created := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+		data, err := EncodeEnveloped("x", EnvelopeHeader{CreatedAt: created})
+		require.NoError(t, err)
+		got, _, err := OpenEnvelope(data)
+		require.NoError(t, err)
+		assert.Equal(t, created.Truncate(time.Millisecond), got.CreatedAt)
+	})
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	data, err := EncodeEnveloped("payload", EnvelopeHeader{ContentType: "text/plain", SchemaID: "s"})
+	require.NoError(t, err)
+
+	plain, err := Marshal("payload")
+	require.NoError(t, err)
+	_, _, err = OpenEnvelope(plain)
+	assert.ErrorIs(t, err, ErrNotEnveloped)
+
+	for n := len(EnvelopeMagic); n < len(data); n++ {
+		_, _, err := OpenEnvelope(data[:n])
+		assert.ErrorIs(t, err, envelopeErr, "truncated to %d bytes", n)
+	}
+	_, _, err = OpenEnvelope(data[:len(data)-1])
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, _, err = OpenEnvelope(append(bytes.Clone(data), 0))
+	assert.ErrorIs(t, err, envelopeErr)
+
+	version := bytes.Clone(data)
+	version[len(EnvelopeMagic)] = 9
+	_, _, err = OpenEnvelope(version)
+	assert.ErrorIs(t, err, envelopeErr)
+
+	flags := bytes.Clone(data)
+	flags[len(EnvelopeMagic)+1] |= 0x80
+	_, _, err = OpenEnvelope(flags)
+	assert.ErrorIs(t, err, envelopeErr)
+}
@@ -0,0 +1,226 @@
+package bogo
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// EqualOption configures how Equal and Diff compare values
+type EqualOption func(*equalConfig)
+
+type equalConfig struct {
+	absTolerance float64
+	relTolerance float64
+}
+
+// WithAbsTolerance treats floats as equal when they differ by at most eps
+func WithAbsTolerance(eps float64) EqualOption {
+	return func(c *equalConfig) {
+		c.absTolerance = eps
+	}
+}
+
+// WithRelTolerance treats floats as equal when they differ by at most eps
+// times the larger magnitude of the two, e.g. 1e-9 for float noise
+func WithRelTolerance(eps float64) EqualOption {
+	return func(c *equalConfig) {
+		c.relTolerance = eps
+	}
+}
+
+// DiffKind classifies a Difference
+type DiffKind int
+
+const (
+	DiffChanged DiffKind = iota // Both documents have a value at the path, and they differ
+	DiffAdded                   // Only the second document has a value at the path
+	DiffRemoved                 // Only the first document has a value at the path
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffChanged:
+		return "changed"
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	}
+	return "<unknown>"
+}
+
+// Difference is a value that differs between two documents
+type Difference struct {
+	Path string // Location of the value, "" for the top-level value
+	Kind DiffKind
+	A, B any // Values in the first and second document; nil when absent
+}
+
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "<root>"
+	}
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("%s: added %v", path, d.B)
+	case DiffRemoved:
+		return fmt.Sprintf("%s: removed %v", path, d.A)
+	}
+	return fmt.Sprintf("%s: changed %v to %v", path, d.A, d.B)
+}
+
+// Equal reports whether the documents a and b hold the same values. Numbers
+// compare by value whatever their wire type, so int64(5) equals uint64(5),
+// and floats compare within the tolerances given by options. Object key order
+// does not matter.
+func Equal(a, b []byte, options ...EqualOption) (bool, error) {
+	diffs, err := Diff(a, b, options...)
+	return len(diffs) == 0, err
+}
+
+// Diff returns the values that differ between the documents a and b, compared
+// as Equal compares them, ordered by path
+func Diff(a, b []byte, options ...EqualOption) ([]Difference, error) {
+	var c equalConfig
+	for _, option := range options {
+		option(&c)
+	}
+
+	va, err := NewConfigurableDecoder().Decode(a)
+	if err != nil {
+		return nil, fmt.Errorf("bogo: diff: first document: %w", err)
+	}
+	vb, err := NewConfigurableDecoder().Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("bogo: diff: second document: %w", err)
+	}
+	return c.diff(nil, "", va, vb), nil
+}
+
+func (c *equalConfig) diff(diffs []Difference, path string, a, b any) []Difference {
+	changed := append(diffs, Difference{Path: path, Kind: DiffChanged, A: a, B: b})
+
+	if ma, ok := a.(map[string]any); ok {
+		mb, ok := b.(map[string]any)
+		if !ok {
+			return changed
+		}
+		return c.diffObjects(diffs, path, ma, mb)
+	}
+
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if isList(ra) && isList(rb) {
+		n := min(ra.Len(), rb.Len())
+		for i := 0; i < n; i++ {
+			diffs = c.diff(diffs, joinPath(path, fmt.Sprintf("[%d]", i)), ra.Index(i).Interface(), rb.Index(i).Interface())
+		}
+		for i := n; i < ra.Len(); i++ {
+			diffs = append(diffs, Difference{Path: joinPath(path, fmt.Sprintf("[%d]", i)), Kind: DiffRemoved, A: ra.Index(i).Interface()})
+		}
+		for i := n; i < rb.Len(); i++ {
+			diffs = append(diffs, Difference{Path: joinPath(path, fmt.Sprintf("[%d]", i)), Kind: DiffAdded, B: rb.Index(i).Interface()})
+		}
+		return diffs
+	}
+
+	if equal, ok := c.equalNumbers(ra, rb); ok {
+		if equal {
+			return diffs
+		}
+		return changed
+	}
+	if reflect.DeepEqual(a, b) {
+		return diffs
+	}
+	return changed
+}
+
+func (c *equalConfig) diffObjects(diffs []Difference, path string, a, b map[string]any) []Difference {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := joinPath(path, key)
+		va, inA := a[key]
+		vb, inB := b[key]
+		switch {
+		case !inA:
+			diffs = append(diffs, Difference{Path: child, Kind: DiffAdded, B: vb})
+		case !inB:
+			diffs = append(diffs, Difference{Path: child, Kind: DiffRemoved, A: va})
+		default:
+			diffs = c.diff(diffs, child, va, vb)
+		}
+	}
+	return diffs
+}
+
+// isList reports whether v is a decoded list. Blobs are compared as values.
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8
+}
+
+// equalNumbers compares two numbers by value. ok is false unless both a and b
+// are numbers. Integers compare exactly; a float compares within tolerance.
+func (c *equalConfig) equalNumbers(a, b reflect.Value) (equal, ok bool) {
+	fa, isFloatA, okA := numberValue(a)
+	fb, isFloatB, okB := numberValue(b)
+	if !okA || !okB {
+		return false, false
+	}
+	if !isFloatA && !isFloatB {
+		return integerSign(a) == integerSign(b) && integerMagnitude(a) == integerMagnitude(b), true
+	}
+	return c.equalFloats(fa, fb), true
+}
+
+func (c *equalConfig) equalFloats(a, b float64) bool {
+	if a == b || math.IsNaN(a) && math.IsNaN(b) {
+		return true
+	}
+	delta := math.Abs(a - b)
+	if delta <= c.absTolerance {
+		return true
+	}
+	return delta <= c.relTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// numberValue returns v as a float64 and whether it is a float
+func numberValue(v reflect.Value) (f float64, isFloat, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true, true
+	}
+	return 0, false, false
+}
+
+// integerSign reports whether the integer v is negative
+func integerSign(v reflect.Value) bool {
+	return v.CanInt() && v.Int() < 0
+}
+
+// integerMagnitude returns the absolute value of the integer v
+func integerMagnitude(v reflect.Value) uint64 {
+	if v.CanUint() {
+		return v.Uint()
+	}
+	if i := v.Int(); i < 0 {
+		return uint64(-(i + 1)) + 1
+	}
+	return uint64(v.Int())
+}
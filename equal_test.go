@@ -0,0 +1,90 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    any
+		options []EqualOption
+		equal   bool
+	}{
+		{"identical", map[string]any{"a": 1, "b": []any{"x"}}, map[string]any{"b": []any{"x"}, "a": 1}, nil, true},
+		{"integers by value", uint64(5), int64(5), nil, true},
+		{"negative integers", int64(-5), uint64(5), nil, false},
+		{"extreme integers", int64(math.MinInt64), uint64(1 << 63), nil, false},
+		{"float noise", 1.0, 1.0000001, nil, false},
+		{"absolute tolerance", 1.0, 1.0000001, []EqualOption{WithAbsTolerance(1e-6)}, true},
+		{"outside absolute tolerance", 1.0, 1.1, []EqualOption{WithAbsTolerance(1e-3)}, false},
+		{"relative tolerance", 1e12, 1e12 + 1, []EqualOption{WithRelTolerance(1e-9)}, true},
+		{"outside relative tolerance", 1.0, 1.001, []EqualOption{WithRelTolerance(1e-9)}, false},
+		{"integer against float", int64(3), 3.0000001, []EqualOption{WithAbsTolerance(1e-3)}, true},
+		{"tolerance in typed lists", []float64{1, 2}, []float64{1, 2.0000001}, []EqualOption{WithAbsTolerance(1e-6)}, true},
+		{"NaN", math.NaN(), math.NaN(), nil, true},
+		{"blobs", []byte{1, 2}, []byte{1, 2}, nil, true},
+		{"types differ", "1", int64(1), nil, false},
+		{"list length", []any{"a"}, []any{"a", "b"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := Equal(mustMarshal(t, tt.a), mustMarshal(t, tt.b), tt.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.equal, equal)
+		})
+	}
+
+	_, err := Equal([]byte{Version}, mustMarshal(t, 1))
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := mustMarshal(t, map[string]any{
+		"name":   "Ada",
+		"score":  9.5,
+		"tags":   []string{"x", "y"},
+		"old":    true,
+		"nested": map[string]any{"n": int64(1)},
+	})
+	b := mustMarshal(t, map[string]any{
+		"name":   "Ada",
+		"score":  9.5000001,
+		"tags":   []string{"x"},
+		"new":    "yes",
+		"nested": map[string]any{"n": int64(2)},
+	})
+
+	diffs, err := Diff(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []Difference{
+		{Path: "nested.n", Kind: DiffChanged, A: int64(1), B: int64(2)},
+		{Path: "new", Kind: DiffAdded, B: "yes"},
+		{Path: "old", Kind: DiffRemoved, A: true},
+		{Path: "score", Kind: DiffChanged, A: 9.5, B: 9.5000001},
+		{Path: "tags[1]", Kind: DiffRemoved, A: "y"},
+	}, diffs)
+	assert.Equal(t, "nested.n: changed 1 to 2", diffs[0].String())
+	assert.Equal(t, "new: added yes", diffs[1].String())
+	assert.Equal(t, "old: removed true", diffs[2].String())
+
+	diffs, err = Diff(a, b, WithRelTolerance(1e-6))
+	require.NoError(t, err)
+	assert.Len(t, diffs, 4, "score is within tolerance")
+
+	diffs, err = Diff(mustMarshal(t, "a"), mustMarshal(t, "b"))
+	require.NoError(t, err)
+	assert.Equal(t, "<root>: changed a to b", diffs[0].String())
+}
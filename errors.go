@@ -1,8 +1,38 @@
 package bogo
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	arrEncErr = errors.New("list encoder error")
 	arrDecErr = errors.New("list decoder error")
 )
+
+// ErrVersionMismatch is returned when a document was written with a format
+// version other than Version. Upgrade tooling can find it with errors.As and
+// branch on Got and Want rather than parsing the message; Hint says which side
+// needs upgrading.
+type ErrVersionMismatch struct {
+	Got  byte   // Version byte of the document
+	Want byte   // Version this package reads and writes
+	Hint string // What to do about it
+}
+
+func (e *ErrVersionMismatch) Error() string {
+	msg := fmt.Sprintf("unsupported version %d, expected version %d", e.Got, e.Want)
+	if e.Hint != "" {
+		msg += " (" + e.Hint + ")"
+	}
+	return msg
+}
+
+// versionMismatch returns the error for a document written with version got
+func versionMismatch(got byte) *ErrVersionMismatch {
+	hint := "the data was written by a newer version of bogo; upgrade the reader"
+	if got < Version {
+		hint = "the data was written by an older version of bogo; re-encode it"
+	}
+	return &ErrVersionMismatch{Got: got, Want: Version, Hint: hint}
+}
@@ -0,0 +1,204 @@
+package bogo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var eventWriterErr = errors.New("event writer error")
+
+// EventWriter builds a bogo document from a stream of structural events, the
+// encoding counterpart of Decoder.Scan. It is intended for code generators and
+// bridges that already produce events and would otherwise have to build an
+// intermediate map. Nesting is validated as events arrive; the document is
+// written to the underlying writer as soon as its top-level value is complete.
+//
+//	ew := bogo.NewEventWriter(w)
+//	ew.BeginObject()
+//	ew.Field("name")
+//	ew.Value("Alice")
+//	ew.Field("tags")
+//	ew.BeginList()
+//	ew.Value("admin")
+//	ew.End()
+//	ew.End()
+type EventWriter struct {
+	w       io.Writer
+	encoder *Encoder
+	stack   []*eventFrame
+	done    bool
+	err     error
+}
+
+// eventFrame is an open object or list whose contents are buffered until End,
+// when its size is known
+type eventFrame struct {
+	typ        Type
+	buf        bytes.Buffer
+	key        string
+	keyPending bool
+}
+
+// NewEventWriter creates an EventWriter that writes to w. Values passed to Value
+// are encoded with an Encoder configured by options.
+func NewEventWriter(w io.Writer, options ...EncoderOption) *EventWriter {
+	return &EventWriter{
+		w:       w,
+		encoder: NewConfigurableEncoder(options...),
+	}
+}
+
+// BeginObject opens an object. Inside an object it must follow Field.
+func (ew *EventWriter) BeginObject() error {
+	return ew.begin(TypeObject)
+}
+
+// BeginList opens a list. Inside an object it must follow Field.
+func (ew *EventWriter) BeginList() error {
+	return ew.begin(TypeUntypedList)
+}
+
+// Field sets the key for the next value of the enclosing object
+func (ew *EventWriter) Field(key string) error {
+	if ew.err != nil {
+		return ew.err
+	}
+
+	frame := ew.current()
+	if frame == nil || frame.typ != TypeObject {
+		return ew.fail("Field called outside of an object")
+	}
+	if frame.keyPending {
+		return ew.fail(fmt.Sprintf("Field %q called before a value for field %q", key, frame.key))
+	}
+	if len(key) > 255 {
+		return ew.fail(fmt.Sprintf("key too long (%d bytes, max 255)", len(key)))
+	}
+
+	frame.key = key
+	frame.keyPending = true
+	return nil
+}
+
+// Value encodes a complete value, which may itself be a map, slice or struct
+func (ew *EventWriter) Value(v any) error {
+	if ew.err != nil {
+		return ew.err
+	}
+	if err := ew.checkValuePosition(); err != nil {
+		return err
+	}
+
+	encoded, err := ew.encoder.encode(v)
+	if err != nil {
+		ew.err = err
+		return err
+	}
+	return ew.emit(encoded)
+}
+
+// End closes the innermost open object or list
+func (ew *EventWriter) End() error {
+	if ew.err != nil {
+		return ew.err
+	}
+
+	frame := ew.current()
+	if frame == nil {
+		return ew.fail("End called with no open object or list")
+	}
+	if frame.keyPending {
+		return ew.fail(fmt.Sprintf("End called before a value for field %q", frame.key))
+	}
+
+	ew.stack = ew.stack[:len(ew.stack)-1]
+	encoded, err := buildContainer(frame.typ, frame.buf.Bytes())
+	if err != nil {
+		ew.err = err
+		return err
+	}
+	return ew.emit(encoded)
+}
+
+// Close reports an error if the document is incomplete
+func (ew *EventWriter) Close() error {
+	if ew.err != nil {
+		return ew.err
+	}
+	if len(ew.stack) > 0 {
+		return ew.fail(fmt.Sprintf("%d unclosed objects or lists", len(ew.stack)))
+	}
+	if !ew.done {
+		return ew.fail("no value written")
+	}
+	return nil
+}
+
+func (ew *EventWriter) begin(typ Type) error {
+	if ew.err != nil {
+		return ew.err
+	}
+	if err := ew.checkValuePosition(); err != nil {
+		return err
+	}
+	if ew.encoder.MaxDepth > 0 && len(ew.stack) >= ew.encoder.MaxDepth {
+		return ew.fail(fmt.Sprintf("maximum nesting depth exceeded (%d)", ew.encoder.MaxDepth))
+	}
+
+	ew.stack = append(ew.stack, &eventFrame{typ: typ})
+	return nil
+}
+
+// checkValuePosition verifies that a value may start at the current position
+func (ew *EventWriter) checkValuePosition() error {
+	frame := ew.current()
+	switch {
+	case frame == nil && ew.done:
+		return ew.fail("document already complete")
+	case frame != nil && frame.typ == TypeObject && !frame.keyPending:
+		return ew.fail("value inside an object must follow Field")
+	}
+	return nil
+}
+
+// emit appends an encoded value to the enclosing container, or writes the
+// document if the value is the top-level value
+func (ew *EventWriter) emit(encoded []byte) error {
+	frame := ew.current()
+	if frame == nil {
+		ew.done = true
+		if _, err := ew.w.Write(append([]byte{Version}, encoded...)); err != nil {
+			ew.err = err
+			return err
+		}
+		return nil
+	}
+
+	if frame.typ == TypeObject {
+		entry, err := buildFieldEntry(frame.key, encoded)
+		if err != nil {
+			ew.err = err
+			return err
+		}
+		frame.buf.Write(entry)
+		frame.keyPending = false
+		return nil
+	}
+
+	frame.buf.Write(encoded)
+	return nil
+}
+
+func (ew *EventWriter) current() *eventFrame {
+	if len(ew.stack) == 0 {
+		return nil
+	}
+	return ew.stack[len(ew.stack)-1]
+}
+
+func (ew *EventWriter) fail(msg string) error {
+	ew.err = wrapError(eventWriterErr, msg)
+	return ew.err
+}
@@ -0,0 +1,93 @@
+package bogo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventWriter(t *testing.T) {
+	t.Run("builds nested documents", func(t *testing.T) {
+		var buf bytes.Buffer
+		ew := NewEventWriter(&buf)
+
+		require.NoError(t, ew.BeginObject())
+		require.NoError(t, ew.Field("name"))
+		require.NoError(t, ew.Value("Alice"))
+		require.NoError(t, ew.Field("roles"))
+		require.NoError(t, ew.BeginList())
+		require.NoError(t, ew.Value("admin"))
+		require.NoError(t, ew.BeginObject())
+		require.NoError(t, ew.Field("scope"))
+		require.NoError(t, ew.Value(int64(3)))
+		require.NoError(t, ew.End())
+		require.NoError(t, ew.End())
+		require.NoError(t, ew.Field("profile"))
+		require.NoError(t, ew.Value(map[string]any{"age": int64(30)}))
+		require.NoError(t, ew.End())
+		require.NoError(t, ew.Close())
+
+		decoded, err := Decode(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"name":    "Alice",
+			"roles":   []any{"admin", map[string]any{"scope": int64(3)}},
+			"profile": map[string]any{"age": int64(30)},
+		}, decoded)
+	})
+
+	t.Run("matches Encode for equivalent values", func(t *testing.T) {
+		var buf bytes.Buffer
+		ew := NewEventWriter(&buf)
+		require.NoError(t, ew.BeginList())
+		require.NoError(t, ew.Value(int64(1)))
+		require.NoError(t, ew.Value("two"))
+		require.NoError(t, ew.End())
+
+		expected, err := Encode([]any{int64(1), "two"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, buf.Bytes())
+	})
+
+	t.Run("rejects invalid nesting", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			events func(ew *EventWriter) error
+		}{
+			{"value without field", func(ew *EventWriter) error {
+				ew.BeginObject()
+				return ew.Value(1)
+			}},
+			{"field in list", func(ew *EventWriter) error {
+				ew.BeginList()
+				return ew.Field("x")
+			}},
+			{"dangling field", func(ew *EventWriter) error {
+				ew.BeginObject()
+				ew.Field("x")
+				return ew.End()
+			}},
+			{"unbalanced end", func(ew *EventWriter) error {
+				return ew.End()
+			}},
+			{"second root value", func(ew *EventWriter) error {
+				ew.Value(1)
+				return ew.Value(2)
+			}},
+			{"unclosed", func(ew *EventWriter) error {
+				ew.BeginList()
+				return ew.Close()
+			}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				err := tt.events(NewEventWriter(&buf))
+				assert.ErrorIs(t, err, eventWriterErr)
+			})
+		}
+	})
+}
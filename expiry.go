@@ -0,0 +1,237 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Expiry is an absolute point in time after which a value is no longer valid.
+// It is encoded like time.Time, as an absolute timestamp, so the remaining
+// lifetime can be computed after decoding without tracking when the value was written.
+type Expiry time.Time
+
+// NewExpiry returns an Expiry that elapses ttl from now
+func NewExpiry(ttl time.Duration) Expiry {
+	return Expiry(time.Now().Add(ttl))
+}
+
+// ExpiryAt returns an Expiry that elapses at t
+func ExpiryAt(t time.Time) Expiry {
+	return Expiry(t)
+}
+
+// Time returns the expiry as a time.Time
+func (e Expiry) Time() time.Time {
+	return time.Time(e)
+}
+
+// IsZero reports whether no expiry has been set
+func (e Expiry) IsZero() bool {
+	return time.Time(e).IsZero()
+}
+
+// IsExpired reports whether the expiry has elapsed. A zero Expiry never expires.
+func (e Expiry) IsExpired() bool {
+	return !e.IsZero() && !time.Now().Before(time.Time(e))
+}
+
+// Remaining returns the time left before expiry, or 0 if it has already elapsed.
+// A zero Expiry never expires and reports a remaining duration of 0.
+func (e Expiry) Remaining() time.Duration {
+	if e.IsZero() {
+		return 0
+	}
+	remaining := time.Until(time.Time(e))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (e Expiry) String() string {
+	return time.Time(e).String()
+}
+
+var expiryType = reflect.TypeOf(Expiry{})
+
+var expiringErr = errors.New("bogo expiring value error")
+
+// An expiring value is laid out as
+//
+//	TypeExpiring | expiry(8) | value
+//
+// where expiry is Unix milliseconds, little-endian, and value is a complete
+// encoded value other than another expiring value.
+const expiringHeader = 8
+
+// Expiring is a value that is valid until Expiry, typically a field of a cache
+// entry whose attributes live for different durations. The expiry is written
+// next to the value so Prune can drop the field once it has elapsed without
+// decoding the document. A zero Expiry never expires and encodes the value
+// alone.
+//
+// Decoded into an interface, expiring values are returned as an Expiring;
+// decoded into other types, they are replaced by their value.
+type Expiring struct {
+	Value  any
+	Expiry Expiry
+}
+
+var expiringType = reflect.TypeFor[Expiring]()
+
+// ExpiresIn returns v as an Expiring that elapses ttl from now
+func ExpiresIn(v any, ttl time.Duration) Expiring {
+	return Expiring{Value: v, Expiry: NewExpiry(ttl)}
+}
+
+// IsExpired reports whether the expiry of e has elapsed
+func (e Expiring) IsExpired() bool {
+	return e.Expiry.IsExpired()
+}
+
+// Prune returns the object document data without the fields holding expiring
+// values that have expired at now, in nested objects as well as at the top
+// level. Other fields are copied byte for byte, without being decoded. When
+// no field has expired, data itself is returned.
+func Prune(data []byte, now time.Time) ([]byte, error) {
+	obj, err := objectValue(data)
+	if err != nil {
+		return nil, err
+	}
+	pruned, changed, err := pruneValue(obj, nil, now.UnixMilli())
+	if err != nil || !changed {
+		return data, err
+	}
+
+	out := make([]byte, 0, len(Magic)+1+len(pruned))
+	if hasMagic(data) {
+		out = append(out, Magic[:]...)
+	}
+	return append(append(out, Version), pruned...), nil
+}
+
+// pruneValue returns value, which starts with its type byte, without the
+// expired fields of the objects in it, and whether any were dropped. The keys
+// of keyed objects are resolved against symbols.
+func pruneValue(value []byte, symbols []string, now int64) ([]byte, bool, error) {
+	switch Type(value[0]) {
+	case TypeExpiring:
+		inner, changed, err := pruneValue(value[1+expiringHeader:], symbols, now)
+		if err != nil || !changed {
+			return value, false, err
+		}
+		return append(append([]byte{}, value[:1+expiringHeader]...), inner...), true, nil
+	case TypeSymbols:
+		symbols, offset, err := readSymbols(value[1:], stringMaker{})
+		if err != nil {
+			return value, false, err
+		}
+		inner, changed, err := pruneValue(value[1+offset:], symbols, now)
+		if err != nil || !changed {
+			return value, false, err
+		}
+		return append(append([]byte{}, value[:1+offset]...), inner...), true, nil
+	case TypeObject, TypeKeyedObject:
+	default:
+		return value, false, nil
+	}
+
+	fields, err := objectFields(value)
+	if err != nil || fields == nil {
+		return value, false, err
+	}
+	var entries []byte
+	changed := false
+	var pruneErr error
+	err = walkObject(value, fields, symbols, func(start, end int, key, v []byte) bool {
+		if Type(v[0]) == TypeExpiring && expiringMillis(v[1:]) <= now {
+			changed = true
+			return true
+		}
+		var sub []byte
+		var subChanged bool
+		if sub, subChanged, pruneErr = pruneValue(v, symbols, now); pruneErr != nil {
+			return false
+		}
+		changed = changed || subChanged
+		if Type(value[0]) == TypeKeyedObject {
+			// The entry keeps its reference to the key
+			entries = append(append(entries, fields[start:end-len(v)]...), sub...)
+		} else {
+			entries = appendObjectEntry(entries, string(key), sub)
+		}
+		return true
+	})
+	if err == nil {
+		err = pruneErr
+	}
+	if err != nil || !changed {
+		return value, false, err
+	}
+	return append(appendTypedHeader(nil, Type(value[0]), uint64(len(entries))), entries...), true, nil
+}
+
+func encodeExpiring(expiry Expiry, value []byte) ([]byte, error) {
+	if Type(value[0]) == TypeExpiring {
+		return nil, wrapError(expiringErr, "expiring values cannot be nested")
+	}
+	buf := make([]byte, 1+expiringHeader, 1+expiringHeader+len(value))
+	buf[0] = byte(TypeExpiring)
+	wireOrder.PutUint64(buf[1:], uint64(expiry.Time().UnixMilli()))
+	return append(buf, value...), nil
+}
+
+// expiringMillis returns the expiry of the expiring value data, after its
+// type byte, whose size has been checked
+func expiringMillis(data []byte) int64 {
+	return int64(wireOrder.Uint64(data))
+}
+
+// expiringSize returns the size of the expiring value data, after its type
+// byte
+func expiringSize(data []byte) (int, error) {
+	if len(data) <= expiringHeader {
+		return 0, wrapError(expiringErr, "insufficient data")
+	}
+	if Type(data[expiringHeader]) == TypeExpiring {
+		return 0, wrapError(expiringErr, "expiring values cannot be nested")
+	}
+	size, err := getElementSize(data[expiringHeader:])
+	if err != nil {
+		return 0, err
+	}
+	return expiringHeader + size, nil
+}
+
+// decodeExpiringWith decodes the expiring value data, after its type byte,
+// decoding the value with decode
+func decodeExpiringWith(data []byte, decode func([]byte) (any, error)) (any, error) {
+	if _, err := expiringSize(data); err != nil {
+		return nil, err
+	}
+	v, err := decode(data[expiringHeader:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", expiringErr, err)
+	}
+	return Expiring{Value: v, Expiry: Expiry(time.UnixMilli(expiringMillis(data)))}, nil
+}
+
+// fromExpiring returns the value of v when it is an Expiring assigned to a
+// destination, or a pointer to one, other than an interface or an Expiring,
+// and v unchanged otherwise
+func fromExpiring(v any, dst reflect.Value) any {
+	e, ok := v.(Expiring)
+	if !ok {
+		return v
+	}
+	t := dst.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || t == expiringType {
+		return v
+	}
+	return e.Value
+}
@@ -0,0 +1,142 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiry(t *testing.T) {
+	type CacheEntry struct {
+		Key       string `json:"key"`
+		ExpiresAt Expiry `json:"expires_at"`
+	}
+
+	t.Run("encodes as absolute timestamp", func(t *testing.T) {
+		at := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		data, err := Encode(ExpiryAt(at))
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeTimestamp), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.True(t, at.Equal(decoded.(time.Time)))
+	})
+
+	t.Run("round trips through structs", func(t *testing.T) {
+		entry := CacheEntry{Key: "session", ExpiresAt: NewExpiry(time.Hour)}
+		data, err := Marshal(entry)
+		require.NoError(t, err)
+
+		var decoded CacheEntry
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.False(t, decoded.ExpiresAt.IsExpired())
+		assert.InDelta(t, time.Hour, decoded.ExpiresAt.Remaining(), float64(time.Second))
+	})
+
+	t.Run("elapsed expiry", func(t *testing.T) {
+		entry := CacheEntry{Key: "session", ExpiresAt: NewExpiry(-time.Minute)}
+		data, err := Marshal(entry)
+		require.NoError(t, err)
+
+		var decoded CacheEntry
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.True(t, decoded.ExpiresAt.IsExpired())
+		assert.Equal(t, time.Duration(0), decoded.ExpiresAt.Remaining())
+	})
+
+	t.Run("zero expiry never expires", func(t *testing.T) {
+		var e Expiry
+		assert.False(t, e.IsExpired())
+		assert.Equal(t, time.Duration(0), e.Remaining())
+	})
+}
+
+func TestExpiring(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := map[string]any{
+		"user":  "ama",
+		"token": Expiring{Value: "t0k3n", Expiry: ExpiryAt(now.Add(-time.Second))},
+		"quota": Expiring{Value: int64(10), Expiry: ExpiryAt(now.Add(time.Hour))},
+		"prefs": map[string]any{
+			"theme": "dark",
+			"promo": Expiring{Value: true, Expiry: ExpiryAt(now)},
+		},
+	}
+	data, err := Marshal(entry)
+	require.NoError(t, err)
+
+	t.Run("decodes with the expiry", func(t *testing.T) {
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		token := decoded.(map[string]any)["token"].(Expiring)
+		assert.Equal(t, "t0k3n", token.Value)
+		assert.True(t, now.Add(-time.Second).Equal(token.Expiry.Time()))
+
+		again, err := Marshal(decoded)
+		require.NoError(t, err)
+		equal, err := Equal(data, again)
+		require.NoError(t, err)
+		assert.True(t, equal)
+	})
+
+	t.Run("unwraps for typed destinations", func(t *testing.T) {
+		var out struct {
+			User  string `json:"user"`
+			Token string `json:"token"`
+			Quota *int   `json:"quota"`
+			Prefs any    `json:"prefs"`
+		}
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, "t0k3n", out.Token)
+		assert.Equal(t, 10, *out.Quota)
+
+		var kept struct {
+			Quota Expiring `json:"quota"`
+		}
+		require.NoError(t, NewConfigurableDecoder().Unmarshal(data, &kept))
+		assert.Equal(t, int64(10), kept.Quota.Value)
+	})
+
+	t.Run("prune drops expired fields", func(t *testing.T) {
+		pruned, err := Prune(data, now)
+		require.NoError(t, err)
+		assert.Less(t, len(pruned), len(data))
+
+		decoded, err := Decode(pruned)
+		require.NoError(t, err)
+		obj := decoded.(map[string]any)
+		assert.NotContains(t, obj, "token")
+		assert.Contains(t, obj, "quota")
+		assert.Equal(t, map[string]any{"theme": "dark"}, obj["prefs"])
+
+		_, err = NewConfigurableDecoder(WithDecoderStrictMode(true)).Decode(pruned)
+		assert.NoError(t, err)
+	})
+
+	t.Run("prune keeps live documents", func(t *testing.T) {
+		pruned, err := Prune(data, now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, data, pruned)
+
+		magic, err := NewConfigurableEncoder(WithMagicPrefix(true)).Encode(entry)
+		require.NoError(t, err)
+		pruned, err = Prune(magic, now)
+		require.NoError(t, err)
+		assert.True(t, hasMagic(pruned))
+	})
+
+	t.Run("zero expiry writes the value alone", func(t *testing.T) {
+		data, err := Marshal(Expiring{Value: "x"})
+		require.NoError(t, err)
+		assert.Equal(t, mustMarshal(t, "x"), data)
+	})
+
+	t.Run("rejects nesting", func(t *testing.T) {
+		inner := Expiring{Value: 1, Expiry: ExpiryAt(now)}
+		_, err := Marshal(Expiring{Value: inner, Expiry: ExpiryAt(now)})
+		assert.ErrorContains(t, err, "nested")
+	})
+}
@@ -0,0 +1,47 @@
+//go:build !bogo_small && !tinygo
+
+package bogo
+
+import "expvar"
+
+// typeCounts keys counts by TypeName
+func typeCounts(types map[Type]int) map[string]int {
+	counts := make(map[string]int, len(types))
+	for t, n := range types {
+		counts[TypeName(t)] += n
+	}
+	return counts
+}
+
+// ExpvarFunc returns an expvar.Var reporting a snapshot of the collector's
+// statistics as JSON, ready for expvar.Publish:
+//
+//	expvar.Publish("bogo_encoder", collector.ExpvarFunc())
+func (sc *StatsCollector) ExpvarFunc() expvar.Func {
+	return func() any {
+		stats := sc.GetStats()
+		return map[string]any{
+			"bytes_encoded":  stats.BytesEncoded,
+			"max_depth_used": stats.MaxDepthUsed,
+			"errors_count":   stats.ErrorsCount,
+			"types_encoded":  typeCounts(stats.TypesEncoded),
+		}
+	}
+}
+
+// ExpvarFunc returns an expvar.Var reporting a snapshot of the collector's
+// statistics as JSON, ready for expvar.Publish:
+//
+//	expvar.Publish("bogo_decoder", collector.ExpvarFunc())
+func (dsc *DecoderStatsCollector) ExpvarFunc() expvar.Func {
+	return func() any {
+		stats := dsc.GetStats()
+		return map[string]any{
+			"bytes_decoded":  stats.BytesDecoded,
+			"max_depth_used": stats.MaxDepthUsed,
+			"errors_count":   stats.ErrorsCount,
+			"unknown_types":  stats.UnknownTypes,
+			"types_decoded":  typeCounts(stats.TypesDecoded),
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !bogo_small && !tinygo
+
+package bogo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpvarFunc(t *testing.T) {
+	t.Run("encoder", func(t *testing.T) {
+		collector := NewStatsCollector(WithCompactIntegers(true))
+		for _, v := range []any{"a", int64(1), int64(2), int64(1000)} {
+			_, err := collector.Encode(v)
+			require.NoError(t, err)
+		}
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal([]byte(collector.ExpvarFunc().String()), &got))
+		assert.Equal(t, float64(collector.GetStats().BytesEncoded), got["bytes_encoded"])
+		assert.Equal(t, map[string]any{"string": float64(1), "int": float64(3)}, got["types_encoded"])
+	})
+
+	t.Run("decoder", func(t *testing.T) {
+		collector := NewDecoderStatsCollector()
+		_, err := collector.Decode([]byte{Version, TypeBoolTrue})
+		require.NoError(t, err)
+		_, err = collector.Decode([]byte{Version})
+		require.Error(t, err)
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal([]byte(collector.ExpvarFunc().String()), &got))
+		assert.Equal(t, float64(2), got["bytes_decoded"])
+		assert.Equal(t, float64(1), got["errors_count"])
+		assert.Equal(t, map[string]any{"bool:true": float64(1)}, got["types_decoded"])
+	})
+}
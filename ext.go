@@ -0,0 +1,153 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var extErr = errors.New("bogo extension error")
+
+// An extension value is laid out as
+//
+//	TypeExt | extension ID | size prefix | data
+//
+// where the extension ID is a signed byte and data is whatever the encoder
+// registered for the ID returned.
+
+// Ext is an extension value whose ID has no decoder registered with
+// RegisterExt in this program. It keeps the encoded data, and encodes back to
+// the same extension value, so payloads pass through intermediaries that do
+// not know the type intact.
+type Ext struct {
+	ID   int8
+	Data []byte
+}
+
+// extType describes an extension type registered with RegisterExt
+type extType struct {
+	id     int8
+	typ    reflect.Type
+	encode func(any) ([]byte, error)
+	decode func([]byte) (any, error)
+}
+
+var (
+	extMu sync.RWMutex
+	// extTypes and extIDs map the types registered with RegisterExt to their
+	// extension and back
+	extTypes = map[reflect.Type]*extType{}
+	extIDs   = map[int8]*extType{}
+)
+
+// RegisterExt defines an extension type: values of type T are encoded as a
+// TypeExt value holding id and the bytes returned by encode, and decoded back
+// with decode, so custom types such as decimals or geographic points get a
+// compact wire form of their own that round trips and is told apart from
+// blobs. Decode returns the value of type T, which Unmarshal assigns to
+// fields of type T, *T or an interface T implements.
+//
+// As with MessagePack, IDs 0 to 127 are for applications and negative IDs
+// are reserved. RegisterExt panics when id is reserved, or when the ID or the
+// type is already registered to something else. It is meant to be called
+// from init functions.
+func RegisterExt[T any](id int8, encode func(T) ([]byte, error), decode func([]byte) (T, error)) {
+	if id < 0 {
+		panic(fmt.Sprintf("bogo: RegisterExt: extension ID %d is reserved", id))
+	}
+	if encode == nil || decode == nil {
+		panic("bogo: RegisterExt: nil encode or decode function")
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Interface {
+		panic(fmt.Sprintf("bogo: RegisterExt: %s is an interface type", t))
+	}
+	ext := &extType{
+		id:  id,
+		typ: t,
+		encode: func(v any) ([]byte, error) {
+			return encode(v.(T))
+		},
+		decode: func(data []byte) (any, error) {
+			return decode(data)
+		},
+	}
+
+	extMu.Lock()
+	defer extMu.Unlock()
+	if other, ok := extIDs[id]; ok && other.typ != t {
+		panic(fmt.Sprintf("bogo: RegisterExt: extension ID %d already registered for %s", id, other.typ))
+	}
+	if other, ok := extTypes[t]; ok && other.id != id {
+		panic(fmt.Sprintf("bogo: RegisterExt: type %s already registered as extension %d", t, other.id))
+	}
+	extIDs[id] = ext
+	extTypes[t] = ext
+}
+
+// encodeExt encodes v as an extension value when it is an Ext or of a type
+// registered with RegisterExt, reporting whether it is
+func encodeExt(v any) ([]byte, bool, error) {
+	if ext, ok := v.(Ext); ok {
+		data, err := buildExt(ext.ID, ext.Data)
+		return data, true, err
+	}
+	extMu.RLock()
+	ext, ok := extTypes[reflect.TypeOf(v)]
+	extMu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	data, err := ext.encode(v)
+	if err != nil {
+		return nil, true, fmt.Errorf("%w: encoding %T as extension %d: %w", extErr, v, ext.id, err)
+	}
+	data, err = buildExt(ext.id, data)
+	return data, true, err
+}
+
+// buildExt returns the extension value holding id and data
+func buildExt(id int8, data []byte) ([]byte, error) {
+	buf := make([]byte, 0, 2+1+maxStorageByteLength+len(data))
+	buf = appendUvarintLen(append(buf, byte(TypeExt), byte(id)), uint64(len(data)))
+	return append(buf, data...), nil
+}
+
+// extData splits the extension value data, which follows the type byte, into
+// its ID and its data, and returns its size without the type byte
+func extData(data []byte) (int8, []byte, int, error) {
+	if len(data) < 1 {
+		return 0, nil, 0, errors.New("insufficient data for extension ID")
+	}
+	prefix, err := readSizePrefix(data, 1)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	start := 1 + prefix.width
+	if prefix.value > uint64(len(data)-start) {
+		return 0, nil, 0, errors.New("insufficient data for extension data")
+	}
+	end := start + int(prefix.value)
+	return int8(data[0]), data[start:end], end, nil
+}
+
+// decodeExt decodes an extension value with the decoder registered for its
+// ID, or into an Ext holding a copy of its data when there is none
+func decodeExt(data []byte) (any, error) {
+	id, payload, _, err := extData(data)
+	if err != nil {
+		return nil, wrapError(extErr, err.Error())
+	}
+	extMu.RLock()
+	ext, ok := extIDs[id]
+	extMu.RUnlock()
+	if !ok {
+		return Ext{ID: id, Data: append([]byte(nil), payload...)}, nil
+	}
+	v, err := ext.decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding extension %d as %s: %w", extErr, id, ext.typ, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,126 @@
+package bogo
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type geoPoint struct {
+	Lat, Lng float64
+}
+
+func encodeGeoPoint(p geoPoint) ([]byte, error) {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lng) {
+		return nil, errors.New("NaN coordinate")
+	}
+	buf := make([]byte, 16)
+	binary.LittleEndian.PutUint64(buf, math.Float64bits(p.Lat))
+	binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(p.Lng))
+	return buf, nil
+}
+
+func decodeGeoPoint(data []byte) (geoPoint, error) {
+	if len(data) != 16 {
+		return geoPoint{}, errors.New("a point takes 16 bytes")
+	}
+	return geoPoint{
+		Lat: math.Float64frombits(binary.LittleEndian.Uint64(data)),
+		Lng: math.Float64frombits(binary.LittleEndian.Uint64(data[8:])),
+	}, nil
+}
+
+func init() {
+	RegisterExt(1, encodeGeoPoint, decodeGeoPoint)
+}
+
+func TestRegisterExt(t *testing.T) {
+	type place struct {
+		Name   string     `json:"name"`
+		At     geoPoint   `json:"at"`
+		Near   *geoPoint  `json:"near"`
+		Route  []geoPoint `json:"route"`
+		Extras any        `json:"extras"`
+	}
+	at := geoPoint{Lat: 5.6037, Lng: -0.187}
+	in := place{
+		Name:   "Accra",
+		At:     at,
+		Near:   &geoPoint{Lat: 5.55, Lng: -0.2},
+		Route:  []geoPoint{at, {Lat: 6.6885, Lng: -1.6244}},
+		Extras: geoPoint{Lat: 1, Lng: 2},
+	}
+
+	data, err := Marshal(in)
+	require.NoError(t, err)
+	var out place
+	require.NoError(t, Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	t.Run("compact and told apart from blobs", func(t *testing.T) {
+		data, err := Marshal(at)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, TypeExt, 1, 1, 16}, data[:5])
+		assert.Len(t, data, 21)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, at, decoded)
+		decoded, err = NewConfigurableDecoder().Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, at, decoded)
+
+		blob, err := Marshal([]byte(data[5:]))
+		require.NoError(t, err)
+		decoded, err = Decode(blob)
+		require.NoError(t, err)
+		assert.IsType(t, []byte{}, decoded)
+	})
+
+	t.Run("unknown IDs pass through", func(t *testing.T) {
+		unknown := Ext{ID: 42, Data: []byte{1, 2, 3}}
+		data, err := Marshal(map[string]any{"x": unknown})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"x": unknown}, decoded)
+
+		again, err := Marshal(decoded)
+		require.NoError(t, err)
+		assert.Equal(t, data, again)
+
+		_, err = Footprint(data)
+		assert.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Marshal(geoPoint{Lat: math.NaN()})
+		assert.ErrorIs(t, err, extErr)
+		assert.ErrorContains(t, err, "NaN coordinate")
+
+		bad, err := Marshal(Ext{ID: 1, Data: []byte{1}})
+		require.NoError(t, err)
+		_, err = Decode(bad)
+		assert.ErrorIs(t, err, extErr)
+		assert.ErrorContains(t, err, "16 bytes")
+
+		for i := 2; i < len(bad); i++ {
+			_, err := Decode(bad[:i])
+			assert.Error(t, err, "truncated at %d", i)
+		}
+	})
+
+	t.Run("registration", func(t *testing.T) {
+		assert.NotPanics(t, func() { RegisterExt(1, encodeGeoPoint, decodeGeoPoint) }, "registering again is allowed")
+		assert.Panics(t, func() { RegisterExt(-1, encodeGeoPoint, decodeGeoPoint) }, "reserved ID")
+		assert.Panics(t, func() { RegisterExt(2, encodeGeoPoint, decodeGeoPoint) }, "type registered under another ID")
+		assert.Panics(t, func() {
+			RegisterExt(1, func(string) ([]byte, error) { return nil, nil }, func([]byte) (string, error) { return "", nil })
+		}, "ID registered for another type")
+	})
+}
@@ -0,0 +1,76 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The reflection-free paths must encode exactly as the reflective paths they
+// short-circuit, which arrays and named types still take.
+func TestReflectionFreePaths(t *testing.T) {
+	t.Run("typed lists", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			fast, slow any
+		}{
+			{"strings", []string{"a", "", "héllo"}, [3]string{"a", "", "héllo"}},
+			{"ints", []int{0, -1, math.MaxInt32}, [3]int{0, -1, math.MaxInt32}},
+			{"int64s", []int64{math.MinInt64, 5}, [2]int64{math.MinInt64, 5}},
+			{"uint64s", []uint64{0, math.MaxUint64}, [2]uint64{0, math.MaxUint64}},
+			{"float64s", []float64{1.5, -0.0, math.Inf(1)}, [3]float64{1.5, -0.0, math.Inf(1)}},
+			{"bools", []bool{true, false}, [2]bool{true, false}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, _, ok := typedListElements(tt.fast)
+				require.True(t, ok)
+
+				fast, err := encodeTypedList(tt.fast)
+				require.NoError(t, err)
+				slow, err := encodeTypedList(tt.slow)
+				require.NoError(t, err)
+				assert.Equal(t, slow, fast)
+			})
+		}
+
+		_, _, _, ok := typedListElements([]string{})
+		assert.False(t, ok, "empty lists are encoded as untyped lists")
+		_, _, _, ok = typedListElements([]float32{1})
+		assert.False(t, ok)
+	})
+
+	t.Run("integers", func(t *testing.T) {
+		type named int64
+		type namedUint uint64
+
+		for _, compact := range []bool{false, true} {
+			encoder := NewConfigurableEncoder(WithCompactIntegers(compact))
+			for _, v := range []any{int(-7), int8(-7), int16(-7), int64(-7)} {
+				fast, err := encoder.Encode(v)
+				require.NoError(t, err)
+				slow, err := encoder.Encode(named(-7))
+				require.NoError(t, err)
+				assert.Equal(t, slow, fast, "%T", v)
+			}
+			for _, v := range []any{uint(7), uint16(7), uint32(7), uint64(7)} {
+				fast, err := encoder.Encode(v)
+				require.NoError(t, err)
+				slow, err := encoder.Encode(namedUint(7))
+				require.NoError(t, err)
+				assert.Equal(t, slow, fast, "%T", v)
+			}
+		}
+	})
+
+	t.Run("null detection", func(t *testing.T) {
+		assert.True(t, isNullValue([]byte(nil)))
+		assert.True(t, isNullValue([]any(nil)))
+		assert.True(t, isNullValue(map[string]any(nil)))
+		assert.False(t, isNullValue([]byte{}))
+		assert.False(t, isNullValue(0))
+		assert.False(t, isNullValue(""))
+	})
+}
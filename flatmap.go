@@ -0,0 +1,134 @@
+package bogo
+
+import "unicode/utf8"
+
+// unmarshalFlatMap decodes an object of strings, integers, floats or blobs
+// straight into a *map[string]string, *map[string]int64, *map[string]float64
+// or *map[string][]byte without building the map[string]any that Unmarshal
+// otherwise converts. It reports false, leaving v untouched, for other
+// destinations and for objects it cannot decode exactly as the general path
+// would, such as ones holding nulls, other than in blob maps, or values of
+// other types; those take the general path.
+func (d *Decoder) unmarshalFlatMap(data []byte, v any) bool {
+	switch dst := v.(type) {
+	case *map[string]string:
+		return decodeFlatMap(d, data, dst, func(value []byte) (string, bool) {
+			s, ok := flatString(value)
+			return d.strings().str(s), ok
+		})
+	case *map[string]int64:
+		return decodeFlatMap(d, data, dst, flatInt)
+	case *map[string]float64:
+		return decodeFlatMap(d, data, dst, flatFloat)
+	case *map[string][]byte:
+		return decodeFlatMap(d, data, dst, flatBytes)
+	}
+	return false
+}
+
+func decodeFlatMap[V any](d *Decoder, data []byte, dst *map[string]V, parse func(value []byte) (V, bool)) bool {
+	if dst == nil || d.Salvage || d.TypedValues || len(d.SelectiveFields) > 0 {
+		return false
+	}
+	if len(data) == 0 || Type(data[0]) != TypeObject {
+		return false
+	}
+	if d.MaxObjectSize > 0 && int64(len(data)) > d.MaxObjectSize {
+		return false
+	}
+	fields, err := containerPayload(data[1:])
+	if err != nil || len(fields) == 1 && fields[0] == TypeNull {
+		return false
+	}
+
+	m := make(map[string]V, countEntries(fields))
+	ok := true
+	err = walkEntries(fields, func(_, _ int, key, value []byte) bool {
+		if d.StrictMode && d.ValidateUTF8 && !utf8.Valid(key) {
+			ok = false
+			return false
+		}
+		var v V
+		if v, ok = parse(value); !ok {
+			return false
+		}
+		m[d.strings().key(key)] = v
+		return true
+	})
+	if err != nil || !ok {
+		return false
+	}
+	*dst = m
+	return true
+}
+
+// flatString returns the bytes of the string value, which is a TypeString or
+// TypeFixStr value
+func flatString(value []byte) ([]byte, bool) {
+	t := Type(value[0])
+	if isFixStr(t) {
+		size := int(t - TypeFixStr)
+		if len(value) < 1+size {
+			return nil, false
+		}
+		return value[1 : 1+size], true
+	}
+	payload, ok := flatPayload(value, TypeString)
+	if !ok {
+		return nil, false
+	}
+	size, err := decodeUint(payload)
+	if err != nil || uint64(len(value)-len(payload)-2) < size {
+		return nil, false
+	}
+	start := 2 + len(payload)
+	return value[start : start+int(size)], true
+}
+
+// flatInt decodes a TypeInt or TypeFixInt value
+func flatInt(value []byte) (int64, bool) {
+	if t := Type(value[0]); isFixInt(t) {
+		return int64(t - TypeFixInt), true
+	}
+	payload, ok := flatPayload(value, TypeInt)
+	if !ok {
+		return 0, false
+	}
+	n, err := decodeInt(payload)
+	return n, err == nil
+}
+
+// flatFloat decodes a TypeFloat value
+func flatFloat(value []byte) (float64, bool) {
+	payload, ok := flatPayload(value, TypeFloat)
+	if !ok || len(payload) < 2 {
+		return 0, false
+	}
+	f, err := decodeFloat(payload)
+	return f, err == nil
+}
+
+// flatBytes decodes a TypeBlob value, a string, taken as its bytes, or a null
+func flatBytes(value []byte) ([]byte, bool) {
+	switch t := Type(value[0]); {
+	case t == TypeNull:
+		return nil, true
+	case t == TypeBlob:
+		b, err := decodeBlob(value[1:])
+		return b, err == nil
+	}
+	return flatString(value)
+}
+
+// flatPayload returns the size-prefixed payload of a value of type typ: the
+// bytes after its type and size-length bytes
+func flatPayload(value []byte, typ Type) ([]byte, bool) {
+	if len(value) < 2 || Type(value[0]) != typ {
+		return nil, false
+	}
+	sizeLen := int(value[1])
+	if sizeLen == 0 || len(value) < 2+sizeLen {
+		return nil, false
+	}
+	return value[2 : 2+sizeLen], true
+}
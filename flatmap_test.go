@@ -0,0 +1,126 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unmarshalGeneral decodes data into v through map[string]any, as Unmarshal
+// does for destinations without a direct path
+func unmarshalGeneral(d *Decoder, data []byte, v any) error {
+	result, err := d.Decode(data)
+	if err != nil {
+		return err
+	}
+	return assignResultWith(result, v, d)
+}
+
+func TestUnmarshalFlatMaps(t *testing.T) {
+	compact := NewConfigurableEncoder(WithCompactIntegers(true), WithCompactStrings(true))
+	encode := func(e *Encoder, v any) []byte {
+		data, err := e.Encode(v)
+		require.NoError(t, err)
+		return data
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		dst    func() any
+		direct bool
+	}{
+		{"strings", encode(defaultEncoder, map[string]string{"a": "x", "b": ""}), func() any { return new(map[string]string) }, true},
+		{"short strings", encode(compact, map[string]string{"a": "x", "long": string(make([]byte, 40))}), func() any { return new(map[string]string) }, true},
+		{"ints", encode(defaultEncoder, map[string]int64{"a": math.MinInt64, "b": 0, "c": 7}), func() any { return new(map[string]int64) }, true},
+		{"small ints", encode(compact, map[string]int64{"a": 3, "b": 300}), func() any { return new(map[string]int64) }, true},
+		{"floats", encode(defaultEncoder, map[string]float64{"a": 1.5, "b": math.Inf(-1), "c": 0}), func() any { return new(map[string]float64) }, true},
+		{"empty", encode(defaultEncoder, map[string]string{}), func() any { return new(map[string]string) }, true},
+		{"blobs", encode(defaultEncoder, map[string][]byte{"a": {1, 2}, "b": nil, "c": {}}), func() any { return new(map[string][]byte) }, true},
+		{"empty blobs", encode(NewConfigurableEncoder(WithNilCollectionsAsEmpty(true)), map[string][]byte{"b": nil}), func() any { return new(map[string][]byte) }, true},
+		{"strings into blobs", encode(compact, map[string]string{"a": "x", "long": string(make([]byte, 40))}), func() any { return new(map[string][]byte) }, true},
+		{"null entry into blobs", append([]byte{Version}, rawObject([]byte{1, 'a'})...), func() any { return new(map[string][]byte) }, true},
+		{"ints into blobs", encode(defaultEncoder, map[string]any{"a": []byte{1}, "b": int64(1)}), func() any { return new(map[string][]byte) }, false},
+		{"repeated keys", append([]byte{Version}, rawObject(rawEntry("a", "1"), rawEntry("a", "2"))...), func() any { return new(map[string]string) }, true},
+		{"null object", append([]byte{Version}, rawObjectFields([]byte{TypeNull})...), func() any { return &map[string]string{"old": "x"} }, false},
+		{"null entry", append([]byte{Version}, rawObject([]byte{1, 'a'})...), func() any { return new(map[string]string) }, false},
+		{"mixed values", encode(defaultEncoder, map[string]any{"a": "x", "b": int64(1)}), func() any { return new(map[string]string) }, false},
+		{"unsigned into int64", encode(defaultEncoder, map[string]uint64{"a": 1}), func() any { return new(map[string]int64) }, false},
+		{"ints into float64", encode(defaultEncoder, map[string]int64{"a": 1}), func() any { return new(map[string]float64) }, false},
+		{"not an object", encode(defaultEncoder, []string{"a"}), func() any { return new(map[string]string) }, false},
+		{"truncated", append([]byte{Version}, rawObject(rawEntry("a", "x"))...)[:6], func() any { return new(map[string]string) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewConfigurableDecoder()
+			direct, general := tt.dst(), tt.dst()
+
+			if len(tt.data) > 1 {
+				assert.Equal(t, tt.direct, d.unmarshalFlatMap(tt.data[1:], tt.dst()), "direct path")
+			}
+			err := d.Unmarshal(tt.data, direct)
+			generalErr := unmarshalGeneral(d, tt.data, general)
+			if generalErr != nil {
+				assert.EqualError(t, err, generalErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, general, direct)
+		})
+	}
+
+	t.Run("decoder options", func(t *testing.T) {
+		data := append([]byte{Version}, rawObject(rawEntry("\xff", "x"))...)
+		strict := NewConfigurableDecoder(WithDecoderStrictMode(true))
+		assert.False(t, strict.unmarshalFlatMap(data[1:], new(map[string]string)))
+		assert.Error(t, strict.Unmarshal(data, new(map[string]string)))
+
+		data = encode(defaultEncoder, map[string]string{"a": "x", "b": "y"})
+		selective := NewConfigurableDecoder(WithSelectiveFields([]string{"a"}))
+		var m map[string]string
+		require.NoError(t, selective.Unmarshal(data, &m))
+		assert.Equal(t, map[string]string{"a": "x"}, m)
+
+		var interned []string
+		interner := NewConfigurableDecoder(WithStringInterner(func(b []byte) string {
+			interned = append(interned, string(b))
+			return string(b)
+		}))
+		require.NoError(t, interner.Unmarshal(data, &m))
+		assert.ElementsMatch(t, []string{"a", "x", "b", "y"}, interned)
+
+		var nilMap *map[string]string
+		assert.Error(t, Unmarshal(data, nilMap))
+	})
+}
+
+func BenchmarkUnmarshalFlatMap(b *testing.B) {
+	m := make(map[string]string)
+	for _, key := range []string{"status", "region", "tier", "owner", "plan", "source"} {
+		m[key] = key + "-value"
+	}
+	data, err := Marshal(m)
+	require.NoError(b, err)
+
+	b.Run("Direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out map[string]string
+			if err := Unmarshal(data, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("General", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out map[string]string
+			if err := unmarshalGeneral(defaultDecoder, data, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
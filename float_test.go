@@ -0,0 +1,143 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonFiniteFloats(t *testing.T) {
+	values := []float64{math.NaN(), math.Inf(1), math.Inf(-1)}
+
+	t.Run("round trip bit-exactly", func(t *testing.T) {
+		for _, f := range append(values, math.Float64frombits(0x7FF8000000000001), math.Float64frombits(0xFFF0000000000001)) {
+			data, err := Encode(f)
+			require.NoError(t, err)
+
+			decoded, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, math.Float64bits(f), math.Float64bits(decoded.(float64)), "value %v", f)
+		}
+	})
+
+	t.Run("round trip in typed lists and objects", func(t *testing.T) {
+		data, err := Encode(map[string]any{"values": values, "nan": math.NaN()})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		obj := decoded.(map[string]any)
+		assert.True(t, math.IsNaN(obj["nan"].(float64)))
+
+		list := obj["values"].([]float64)
+		require.Len(t, list, 3)
+		assert.True(t, math.IsNaN(list[0]))
+		assert.True(t, math.IsInf(list[1], 1))
+		assert.True(t, math.IsInf(list[2], -1))
+	})
+
+	t.Run("error policy", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithNaNPolicy(NaNError))
+		type readings []float64
+		for _, v := range []any{
+			math.NaN(), float32(math.Inf(1)), []float64{1, math.Inf(-1)}, map[string]any{"x": math.NaN()},
+			[]float32{1, float32(math.NaN())}, readings{math.Inf(1)}, [2]float64{1, math.NaN()}, []any{math.NaN()},
+		} {
+			_, err := encoder.Encode(v)
+			assert.ErrorIs(t, err, errNonFiniteFloat)
+		}
+
+		_, err := encoder.Encode([]float64{1, 2})
+		assert.NoError(t, err)
+	})
+
+	t.Run("null policy", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithNaNPolicy(NaNNull))
+		data, err := encoder.Encode(map[string]any{"values": []float64{1, math.NaN(), math.Inf(1)}, "x": math.Inf(-1)})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"values": []any{1.0, nil, nil}, "x": nil}, decoded)
+	})
+}
+
+func TestFloatBitExactness(t *testing.T) {
+	special := []float64{
+		0,
+		math.Copysign(0, -1),
+		math.SmallestNonzeroFloat64,
+		-math.SmallestNonzeroFloat64,
+		math.Float64frombits(0x000FFFFFFFFFFFFF), // largest subnormal
+		math.Float64frombits(0x0010000000000000), // smallest normal
+		math.MaxFloat64,
+		-math.MaxFloat64,
+		1,
+		-1.5,
+		math.Pi,
+	}
+
+	// A deterministic spread of bit patterns across all exponents
+	var x uint64 = 0x9E3779B97F4A7C15
+	for i := 0; i < 1000; i++ {
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+		special = append(special, math.Float64frombits(x))
+	}
+
+	assertBits := func(t *testing.T, expected, actual float64) {
+		t.Helper()
+		assert.Equal(t, math.Float64bits(expected), math.Float64bits(actual), "expected %v (%#016x), got %v (%#016x)",
+			expected, math.Float64bits(expected), actual, math.Float64bits(actual))
+	}
+
+	t.Run("scalars", func(t *testing.T) {
+		for _, f := range special {
+			data, err := Encode(f)
+			require.NoError(t, err)
+
+			decoded, err := Decode(data)
+			require.NoError(t, err)
+			assertBits(t, f, decoded.(float64))
+		}
+	})
+
+	t.Run("typed lists", func(t *testing.T) {
+		data, err := Encode(special)
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		list := decoded.([]float64)
+		require.Len(t, list, len(special))
+		for i := range special {
+			assertBits(t, special[i], list[i])
+		}
+	})
+
+	t.Run("struct fields", func(t *testing.T) {
+		type Sample struct {
+			F64 float64 `json:"f64"`
+			F32 float32 `json:"f32"`
+		}
+
+		samples := []Sample{
+			{F64: math.Copysign(0, -1), F32: float32(math.Copysign(0, -1))},
+			{F64: math.SmallestNonzeroFloat64, F32: math.SmallestNonzeroFloat32},
+			{F64: -math.SmallestNonzeroFloat64, F32: math.Float32frombits(0x007FFFFF)},
+		}
+
+		for _, sample := range samples {
+			data, err := Marshal(sample)
+			require.NoError(t, err)
+
+			var decoded Sample
+			require.NoError(t, Unmarshal(data, &decoded))
+			assertBits(t, sample.F64, decoded.F64)
+			assert.Equal(t, math.Float32bits(sample.F32), math.Float32bits(decoded.F32))
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package bogo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var framingErr = errors.New("bogo framing error")
+
+// Framing selects how StreamEncoder writes and StreamDecoder reads the
+// documents of a stream
+type Framing uint8
+
+const (
+	// Raw streams hold a single document, written as is. The decoder reads
+	// the stream to its end.
+	Raw Framing = iota
+	// LengthPrefixed streams hold any number of documents, each preceded by
+	// its size prefix as written by WriteLengthPrefixed. The decoder reads
+	// one frame per Decode and returns io.EOF after the last one.
+	LengthPrefixed
+)
+
+func (f Framing) String() string {
+	switch f {
+	case Raw:
+		return "raw"
+	case LengthPrefixed:
+		return "length-prefixed"
+	}
+	return fmt.Sprintf("Framing(%d)", uint8(f))
+}
+
+// WithFraming sets the framing of the documents written by a StreamEncoder.
+// The decoder of the stream must be given the same framing with
+// WithDecoderFraming; a decoder expecting the other framing fails rather
+// than misreading the stream.
+func WithFraming(framing Framing) EncoderOption {
+	return func(e *Encoder) {
+		e.Framing = framing
+	}
+}
+
+// WithDecoderFraming sets the framing a StreamDecoder expects, see WithFraming
+func WithDecoderFraming(framing Framing) DecoderOption {
+	return func(d *Decoder) {
+		d.Framing = framing
+	}
+}
+
+// frame returns data as written to a stream with framing
+func (f Framing) frame(data []byte) []byte {
+	if f != LengthPrefixed {
+		return data
+	}
+	framed := make([]byte, 0, 1+binary.MaxVarintLen64+len(data))
+	return append(appendUvarintLen(framed, uint64(len(data))), data...)
+}
+
+// readFrame reads the next document of a length-prefixed stream. A stream
+// starting like a document rather than a size prefix is raw.
+func readFrame(r io.Reader) ([]byte, error) {
+	var first [1]byte
+	if _, err := io.ReadFull(r, first[:]); err != nil {
+		return nil, err
+	}
+	if startsDocument(first[0]) {
+		return nil, wrapError(framingErr, "stream is raw, expected length-prefixed frames")
+	}
+	var buf [binary.MaxVarintLen64]byte
+	size, err := readUvarint(r, buf[:], int(first[0]))
+	if err != nil {
+		return nil, err
+	}
+	return readPayload(r, size)
+}
+
+// checkRaw rejects a raw stream whose data is a length-prefixed frame
+func checkRaw(data []byte) error {
+	if len(data) == 0 || startsDocument(data[0]) {
+		return nil
+	}
+	prefix, err := readSizePrefix(data, 0)
+	if err != nil || prefix.value > uint64(len(data)-prefix.width) {
+		return nil
+	}
+	if rest := data[prefix.width:]; len(rest) > 0 && startsDocument(rest[0]) {
+		return wrapError(framingErr, "stream is length-prefixed, expected a raw document")
+	}
+	return nil
+}
+
+// startsDocument reports whether b can be the first byte of a document: its
+// version byte or the first byte of a magic prefix. Size prefixes start with
+// their length, which is never zero.
+func startsDocument(b byte) bool {
+	return b == Version || bytes.IndexByte([]byte{Magic[0], EnvelopeMagic[0]}, b) >= 0
+}
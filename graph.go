@@ -0,0 +1,219 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Graph is a set of nodes connected by directed edges. Edges reference nodes by
+// ID and are encoded as pairs of node indices, which keeps graphs compact and
+// avoids the unbounded recursion of encoding pointer-linked structures directly.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphNode is a node with a unique ID and optional payload
+type GraphNode struct {
+	ID   string
+	Data any
+}
+
+// GraphEdge is a directed edge between two node IDs
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// AddNode appends a node to the graph
+func (g *Graph) AddNode(id string, data any) {
+	g.Nodes = append(g.Nodes, GraphNode{ID: id, Data: data})
+}
+
+// AddEdge appends a directed edge between two node IDs
+func (g *Graph) AddEdge(from, to string) {
+	g.Edges = append(g.Edges, GraphEdge{From: from, To: to})
+}
+
+// Adjacency returns the outgoing neighbours of every node, in edge order
+func (g Graph) Adjacency() map[string][]string {
+	adj := make(map[string][]string, len(g.Nodes))
+	for _, node := range g.Nodes {
+		adj[node.ID] = nil
+	}
+	for _, edge := range g.Edges {
+		adj[edge.From] = append(adj[edge.From], edge.To)
+	}
+	return adj
+}
+
+// GraphFromLinks builds a Graph from pointer-linked nodes reachable from roots.
+// Each node is visited once, so cyclic structures are encoded as ID references
+// rather than recursing forever. id returns a node's unique ID, links returns the
+// nodes it points to and data returns the payload to store for it (may be nil).
+func GraphFromLinks[T any](roots []*T, id func(*T) string, links func(*T) []*T, data func(*T) any) Graph {
+	var g Graph
+	visited := make(map[*T]bool)
+
+	var visit func(node *T)
+	visit = func(node *T) {
+		if node == nil || visited[node] {
+			return
+		}
+		visited[node] = true
+
+		var payload any
+		if data != nil {
+			payload = data(node)
+		}
+		g.AddNode(id(node), payload)
+
+		for _, next := range links(node) {
+			if next == nil {
+				continue
+			}
+			g.AddEdge(id(node), id(next))
+			visit(next)
+		}
+	}
+
+	for _, root := range roots {
+		visit(root)
+	}
+	return g
+}
+
+var (
+	graphType   = reflect.TypeOf(Graph{})
+	graphEncErr = errors.New("graph encoder error")
+	graphDecErr = errors.New("graph decoder error")
+)
+
+// graphObject converts a graph into its wire object:
+// {"ids": [...], "data": [...], "from": [...], "to": [...]}
+// where from/to hold indices into ids. "data" is omitted when no node has a payload.
+func graphObject(g Graph) (map[string]any, error) {
+	index := make(map[string]int64, len(g.Nodes))
+	ids := make([]string, len(g.Nodes))
+	data := make([]any, len(g.Nodes))
+	hasData := false
+
+	for i, node := range g.Nodes {
+		if _, dup := index[node.ID]; dup {
+			return nil, wrapError(graphEncErr, fmt.Sprintf("duplicate node id %q", node.ID))
+		}
+		index[node.ID] = int64(i)
+		ids[i] = node.ID
+		data[i] = node.Data
+		hasData = hasData || node.Data != nil
+	}
+
+	from := make([]int64, len(g.Edges))
+	to := make([]int64, len(g.Edges))
+	for i, edge := range g.Edges {
+		f, ok := index[edge.From]
+		if !ok {
+			return nil, wrapError(graphEncErr, fmt.Sprintf("edge references unknown node %q", edge.From))
+		}
+		t, ok := index[edge.To]
+		if !ok {
+			return nil, wrapError(graphEncErr, fmt.Sprintf("edge references unknown node %q", edge.To))
+		}
+		from[i], to[i] = f, t
+	}
+
+	obj := map[string]any{"ids": ids, "from": from, "to": to}
+	if hasData {
+		obj["data"] = data
+	}
+	return obj, nil
+}
+
+// graphFromValue rebuilds a graph from a decoded wire object
+func graphFromValue(value any) (Graph, error) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return Graph{}, wrapError(graphDecErr, fmt.Sprintf("expected object, got %T", value))
+	}
+
+	ids, err := toStringSlice(obj["ids"])
+	if err != nil {
+		return Graph{}, wrapError(graphDecErr, "ids", err.Error())
+	}
+	from, err := toInt64Slice(obj["from"])
+	if err != nil {
+		return Graph{}, wrapError(graphDecErr, "from", err.Error())
+	}
+	to, err := toInt64Slice(obj["to"])
+	if err != nil {
+		return Graph{}, wrapError(graphDecErr, "to", err.Error())
+	}
+	if len(from) != len(to) {
+		return Graph{}, wrapError(graphDecErr, "edge lists differ in length")
+	}
+
+	var data []any
+	if raw, ok := obj["data"]; ok {
+		if data, ok = raw.([]any); !ok || len(data) != len(ids) {
+			return Graph{}, wrapError(graphDecErr, "node data does not match node ids")
+		}
+	}
+
+	g := Graph{Nodes: make([]GraphNode, len(ids)), Edges: make([]GraphEdge, len(from))}
+	for i, id := range ids {
+		g.Nodes[i].ID = id
+		if data != nil {
+			g.Nodes[i].Data = data[i]
+		}
+	}
+	for i := range from {
+		if from[i] < 0 || from[i] >= int64(len(ids)) || to[i] < 0 || to[i] >= int64(len(ids)) {
+			return Graph{}, wrapError(graphDecErr, fmt.Sprintf("edge %d references a node out of range", i))
+		}
+		g.Edges[i] = GraphEdge{From: ids[from[i]], To: ids[to[i]]}
+	}
+	return g, nil
+}
+
+// toStringSlice accepts both typed and untyped decoded string lists
+func toStringSlice(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []any:
+		out := make([]string, len(v))
+		for i, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("element %d is %T, not string", i, elem)
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected string list, got %T", value)
+}
+
+// toInt64Slice accepts both typed and untyped decoded integer lists
+func toInt64Slice(value any) ([]int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []int64:
+		return v, nil
+	case []any:
+		out := make([]int64, len(v))
+		for i, elem := range v {
+			n, ok := elem.(int64)
+			if !ok {
+				return nil, fmt.Errorf("element %d is %T, not int64", i, elem)
+			}
+			out[i] = n
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected integer list, got %T", value)
+}
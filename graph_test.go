@@ -0,0 +1,67 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var g Graph
+		g.AddNode("a", map[string]any{"weight": int64(1)})
+		g.AddNode("b", nil)
+		g.AddNode("c", "leaf")
+		g.AddEdge("a", "b")
+		g.AddEdge("b", "c")
+		g.AddEdge("c", "a")
+
+		type Payload struct {
+			Name  string `json:"name"`
+			Graph Graph  `json:"graph"`
+		}
+
+		data, err := Marshal(&Payload{Name: "cycle", Graph: g})
+		require.NoError(t, err)
+
+		var decoded Payload
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, g, decoded.Graph)
+		assert.Equal(t, []string{"b"}, decoded.Graph.Adjacency()["a"])
+	})
+
+	t.Run("pointer linked cycles", func(t *testing.T) {
+		type Node struct {
+			Name string
+			Next []*Node
+		}
+		a := &Node{Name: "a"}
+		b := &Node{Name: "b"}
+		c := &Node{Name: "c"}
+		a.Next = []*Node{b, c}
+		b.Next = []*Node{c}
+		c.Next = []*Node{a}
+
+		g := GraphFromLinks([]*Node{a},
+			func(n *Node) string { return n.Name },
+			func(n *Node) []*Node { return n.Next },
+			nil,
+		)
+		assert.Len(t, g.Nodes, 3)
+		assert.Len(t, g.Edges, 4)
+
+		data, err := Encode(&g)
+		require.NoError(t, err)
+
+		var decoded Graph
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, g, decoded)
+	})
+
+	t.Run("edges to unknown nodes are rejected", func(t *testing.T) {
+		g := Graph{Nodes: []GraphNode{{ID: "a"}}, Edges: []GraphEdge{{From: "a", To: "z"}}}
+		_, err := Encode(g)
+		assert.ErrorIs(t, err, graphEncErr)
+	})
+}
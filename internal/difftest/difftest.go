@@ -0,0 +1,373 @@
+// Package difftest is a differential tester for bogo. It encodes random values
+// with bogo, encoding/json and msgpack, decodes them again, and compares what
+// each format returns, so semantic divergence between the formats, such as the
+// ambiguity between uint8 values and bytes, is found without writing a test
+// for every combination of types.
+//
+// Every decoded value is reduced to a canonical form in which numbers of any
+// Go type compare by value. Values are then compared with the expectation for
+// their format, which is the original value after the conversions listed in
+// Conversions. Anything else is reported as a Divergence.
+package difftest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bubunyo/bogo"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Format names a serialization format under test
+type Format string
+
+const (
+	Bogo    Format = "bogo"
+	JSON    Format = "json"
+	MsgPack Format = "msgpack"
+)
+
+// Formats are the formats compared by Check and CheckTyped
+var Formats = []Format{Bogo, JSON, MsgPack}
+
+// Conversions documents the differences between the formats that are
+// expected, and are applied to the original value before it is compared.
+// Decoding into a value of the original type must round-trip exactly, apart
+// from time precision.
+var Conversions = []string{
+	"bogo: times have millisecond precision",
+	"bogo: times decode into any as Unix milliseconds",
+	"json: []byte decodes into any as a base64 string",
+	"json: times decode into any as RFC 3339 strings",
+	"json: float32 values decode into any with float32 precision",
+}
+
+// Divergence is a value that decoded differently than expected
+type Divergence struct {
+	Format Format
+	Path   string
+	Want   any // canonical form of the expected value
+	Got    any // canonical form of the decoded value
+}
+
+func (d Divergence) String() string {
+	path := d.Path
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("%s: %s: want %s, got %s", d.Format, path, show(d.Want), show(d.Got))
+}
+
+// Check round-trips v through every format, decoding into any, and returns
+// the values that diverge from their documented form
+func Check(v any) ([]Divergence, error) {
+	var divergences []Divergence
+	for _, format := range Formats {
+		got, err := roundTrip(format, v, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", format, err)
+		}
+		want := expect(format, reflect.ValueOf(v), false)
+		divergences = append(divergences, compare(format, "", want, canon(reflect.ValueOf(got)))...)
+	}
+	return divergences, nil
+}
+
+// CheckTyped round-trips v through every format, decoding into a new value of
+// the type of v, and returns the values that do not survive the round trip.
+// A nil v has no type and is not checked.
+func CheckTyped(v any) ([]Divergence, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var divergences []Divergence
+	for _, format := range Formats {
+		target := reflect.New(reflect.TypeOf(v))
+		if _, err := roundTrip(format, v, target.Interface()); err != nil {
+			return nil, fmt.Errorf("%s: %w", format, err)
+		}
+		want := expect(format, reflect.ValueOf(v), true)
+		divergences = append(divergences, compare(format, "", want, canon(target.Elem()))...)
+	}
+	return divergences, nil
+}
+
+// roundTrip encodes v with format and decodes it into target, or into any
+// when target is nil
+func roundTrip(format Format, v, target any) (any, error) {
+	var out any
+	if target == nil {
+		target = &out
+	}
+
+	switch format {
+	case Bogo:
+		data, err := bogo.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := bogo.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+	case JSON:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber() // keep integers exact
+		if err := dec.Decode(target); err != nil {
+			return nil, err
+		}
+	case MsgPack:
+		data, err := msgpack.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := msgpack.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Canonical forms. Numbers are *big.Rat, strings are string, bools are bool,
+// and nil is nil.
+type (
+	blob    string         // bytes
+	instant time.Time      // a point in time
+	list    []any          // a list of canonical values
+	object  map[string]any // an object of canonical values
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	numberType = reflect.TypeOf(json.Number(""))
+)
+
+// canon returns the canonical form of a decoded or original value
+func canon(rv reflect.Value) any {
+	if !rv.IsValid() {
+		return nil
+	}
+	if rv.Type() == timeType {
+		return instant(rv.Interface().(time.Time))
+	}
+	if rv.Type() == numberType {
+		// Numbers with a fraction or exponent are float64 in every format
+		if strings.ContainsAny(rv.String(), ".eE") {
+			f, err := strconv.ParseFloat(rv.String(), 64)
+			if err != nil {
+				return rv.String()
+			}
+			return new(big.Rat).SetFloat64(f)
+		}
+		r, ok := new(big.Rat).SetString(rv.String())
+		if !ok {
+			return rv.String()
+		}
+		return r
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return canon(rv.Elem())
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return new(big.Rat).SetFloat64(rv.Float())
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return blob(rv.Bytes())
+		}
+		l := make(list, rv.Len())
+		for i := range l {
+			l[i] = canon(rv.Index(i))
+		}
+		return l
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		o := make(object, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			o[iter.Key().String()] = canon(iter.Value())
+		}
+		return o
+	case reflect.Struct:
+		o := make(object, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if field.IsExported() {
+				o[fieldName(field)] = canon(rv.Field(i))
+			}
+		}
+		return o
+	}
+	return fmt.Sprintf("<unsupported %s>", rv.Type())
+}
+
+// expect returns the canonical form format is expected to decode for the
+// original value rv, applying the documented conversions. typed is true when
+// decoding into a value of the original type, and false when decoding into any.
+func expect(format Format, rv reflect.Value, typed bool) any {
+	if !rv.IsValid() {
+		return nil
+	}
+
+	if rv.Type() == timeType {
+		t := rv.Interface().(time.Time)
+		switch {
+		case format == Bogo && typed:
+			return instant(t.Truncate(time.Millisecond))
+		case format == Bogo:
+			return new(big.Rat).SetInt64(t.UnixMilli())
+		case format == JSON && !typed:
+			return t.Format(time.RFC3339Nano)
+		}
+		return instant(t)
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return expect(format, rv.Elem(), typed)
+	case reflect.Float32:
+		if format == JSON && !typed {
+			f, _ := strconv.ParseFloat(strconv.FormatFloat(rv.Float(), 'g', -1, 32), 64)
+			return new(big.Rat).SetFloat64(f)
+		}
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if format == JSON && !typed {
+				return base64.StdEncoding.EncodeToString(rv.Bytes())
+			}
+			return blob(rv.Bytes())
+		}
+		l := make(list, rv.Len())
+		for i := range l {
+			l[i] = expect(format, rv.Index(i), typed)
+		}
+		return l
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		o := make(object, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			o[iter.Key().String()] = expect(format, iter.Value(), typed)
+		}
+		return o
+	case reflect.Struct:
+		o := make(object, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if field.IsExported() {
+				o[fieldName(field)] = expect(format, rv.Field(i), typed)
+			}
+		}
+		return o
+	}
+	return canon(rv)
+}
+
+// compare returns the differences between two canonical values
+func compare(format Format, path string, want, got any) []Divergence {
+	diverge := []Divergence{{Format: format, Path: path, Want: want, Got: got}}
+
+	switch w := want.(type) {
+	case *big.Rat:
+		if g, ok := got.(*big.Rat); ok && w.Cmp(g) == 0 {
+			return nil
+		}
+		return diverge
+	case instant:
+		if g, ok := got.(instant); ok && time.Time(w).Equal(time.Time(g)) {
+			return nil
+		}
+		return diverge
+	case list:
+		g, ok := got.(list)
+		if !ok || len(g) != len(w) {
+			return diverge
+		}
+		var out []Divergence
+		for i := range w {
+			out = append(out, compare(format, fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return out
+	case object:
+		g, ok := got.(object)
+		if !ok || len(g) != len(w) {
+			return diverge
+		}
+		var out []Divergence
+		for _, key := range sortedKeys(w) {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			out = append(out, compare(format, child, w[key], g[key])...)
+		}
+		return out
+	}
+	if want == got {
+		return nil
+	}
+	return diverge
+}
+
+func sortedKeys(o object) []string {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// show formats a canonical value for a divergence report
+func show(v any) string {
+	switch v := v.(type) {
+	case *big.Rat:
+		return v.RatString()
+	case instant:
+		return time.Time(v).Format(time.RFC3339Nano)
+	case blob:
+		return fmt.Sprintf("bytes %x", string(v))
+	case list:
+		return fmt.Sprintf("list of %d", len(v))
+	case object:
+		return fmt.Sprintf("object of %d", len(v))
+	case string:
+		return strconv.Quote(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
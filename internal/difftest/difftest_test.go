@@ -0,0 +1,46 @@
+package difftest
+
+import (
+	"testing"
+)
+
+func checkSeed(t *testing.T, seed int64) {
+	t.Helper()
+	v := Generate(seed)
+	divergences, err := Check(v)
+	if err != nil {
+		t.Fatalf("seed %d: %v", seed, err)
+	}
+	for _, d := range divergences {
+		t.Errorf("seed %d: %s", seed, d)
+	}
+
+	typed := GenerateTyped(seed)
+	for _, check := range []func(any) ([]Divergence, error){Check, CheckTyped} {
+		divergences, err := check(typed)
+		if err != nil {
+			t.Fatalf("typed seed %d (%T): %v", seed, typed, err)
+		}
+		for _, d := range divergences {
+			t.Errorf("typed seed %d (%T): %s", seed, typed, d)
+		}
+	}
+}
+
+func TestDifferential(t *testing.T) {
+	for seed := int64(0); seed < 2000; seed++ {
+		checkSeed(t, seed)
+		if t.Failed() {
+			return
+		}
+	}
+}
+
+func FuzzDifferential(f *testing.F) {
+	for seed := int64(0); seed < 16; seed++ {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		checkSeed(t, seed)
+	})
+}
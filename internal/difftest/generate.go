@@ -0,0 +1,204 @@
+package difftest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// scalarTypes are the leaf types of generated values
+var scalarTypes = []reflect.Type{
+	reflect.TypeOf(false),
+	reflect.TypeOf(""),
+	reflect.TypeOf(int(0)),
+	reflect.TypeOf(int8(0)),
+	reflect.TypeOf(int16(0)),
+	reflect.TypeOf(int32(0)),
+	reflect.TypeOf(int64(0)),
+	reflect.TypeOf(uint(0)),
+	reflect.TypeOf(uint8(0)),
+	reflect.TypeOf(uint16(0)),
+	reflect.TypeOf(uint32(0)),
+	reflect.TypeOf(uint64(0)),
+	reflect.TypeOf(float32(0)),
+	reflect.TypeOf(float64(0)),
+	reflect.TypeOf([]byte(nil)),
+	timeType,
+}
+
+// Generate returns a random dynamic value built from map[string]any, []any
+// and scalars, as decoded JSON would look. The same seed always generates the
+// same value.
+func Generate(seed int64) any {
+	g := &generator{r: rand.New(rand.NewSource(seed))}
+	return g.dynamic(3)
+}
+
+// GenerateTyped returns a random value of a random type built from slices,
+// string-keyed maps, pointers, structs and scalars, as an application would
+// pass to Marshal. The same seed always generates the same value.
+func GenerateTyped(seed int64) any {
+	g := &generator{r: rand.New(rand.NewSource(seed))}
+	return g.value(g.typ(3)).Interface()
+}
+
+type generator struct {
+	r *rand.Rand
+}
+
+// typ returns a random type nesting containers at most depth levels deep
+func (g *generator) typ(depth int) reflect.Type {
+	if depth == 0 || g.r.Intn(3) == 0 {
+		return scalarTypes[g.r.Intn(len(scalarTypes))]
+	}
+	switch g.r.Intn(4) {
+	case 0:
+		return reflect.SliceOf(g.typ(depth - 1))
+	case 1:
+		return reflect.MapOf(reflect.TypeOf(""), g.typ(depth-1))
+	case 2:
+		return reflect.PointerTo(g.typ(depth - 1))
+	}
+	fields := make([]reflect.StructField, 1+g.r.Intn(4))
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: g.typ(depth - 1),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"f%d" msgpack:"f%d"`, i, i)),
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// value returns a random value of type t
+func (g *generator) value(t reflect.Type) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Slice:
+		if g.r.Intn(8) == 0 {
+			return v // nil
+		}
+		n := g.r.Intn(4)
+		v.Set(reflect.MakeSlice(t, n, n))
+		for i := 0; i < n; i++ {
+			v.Index(i).Set(g.value(t.Elem()))
+		}
+	case reflect.Map:
+		if g.r.Intn(8) == 0 {
+			return v // nil
+		}
+		v.Set(reflect.MakeMap(t))
+		for i := g.r.Intn(4); i > 0; i-- {
+			v.SetMapIndex(reflect.ValueOf(g.key()), g.value(t.Elem()))
+		}
+	case reflect.Pointer:
+		if g.r.Intn(4) == 0 {
+			return v // nil
+		}
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(g.value(t.Elem()))
+	case reflect.Struct:
+		if t == timeType {
+			v.Set(reflect.ValueOf(g.time()))
+			break
+		}
+		for i := 0; i < t.NumField(); i++ {
+			v.Field(i).Set(g.value(t.Field(i).Type))
+		}
+	default:
+		v.Set(g.scalar(t))
+	}
+	return v
+}
+
+// dynamic returns a random map[string]any, []any or scalar
+func (g *generator) dynamic(depth int) any {
+	if depth == 0 || g.r.Intn(3) == 0 {
+		if g.r.Intn(8) == 0 {
+			return nil
+		}
+		return g.value(scalarTypes[g.r.Intn(len(scalarTypes))]).Interface()
+	}
+	if g.r.Intn(2) == 0 {
+		l := make([]any, g.r.Intn(4))
+		for i := range l {
+			l[i] = g.dynamic(depth - 1)
+		}
+		return l
+	}
+	m := make(map[string]any)
+	for i := g.r.Intn(4); i > 0; i-- {
+		m[g.key()] = g.dynamic(depth - 1)
+	}
+	return m
+}
+
+// scalar returns a random value of scalar type t, favouring boundaries
+func (g *generator) scalar(t reflect.Type) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(g.r.Intn(2) == 0)
+	case reflect.String:
+		v.SetString(g.text())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := t.Bits()
+		limits := []int64{0, 1, -1, int64(1)<<(bits-1) - 1, -1 << (bits - 1)}
+		if g.r.Intn(2) == 0 {
+			v.SetInt(limits[g.r.Intn(len(limits))])
+		} else {
+			v.SetInt(g.r.Int63() >> (64 - bits))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bits := t.Bits()
+		limits := []uint64{0, 1, math.MaxUint64 >> (64 - bits)}
+		if g.r.Intn(2) == 0 {
+			v.SetUint(limits[g.r.Intn(len(limits))])
+		} else {
+			v.SetUint(g.r.Uint64() >> (64 - bits))
+		}
+	case reflect.Float32, reflect.Float64:
+		// NaN and infinities have no JSON encoding
+		f := []float64{0, 1, -1.5, 0.1, 1e300, math.SmallestNonzeroFloat64, g.r.NormFloat64() * 1e6}[g.r.Intn(7)]
+		if t.Kind() == reflect.Float32 {
+			f = []float64{0, 1, -1.5, 0.1, math.MaxFloat32, float64(float32(g.r.NormFloat64()))}[g.r.Intn(6)]
+		}
+		v.SetFloat(f)
+	case reflect.Slice: // []byte
+		b := make([]byte, g.r.Intn(6))
+		g.r.Read(b)
+		v.SetBytes(b)
+	}
+	return v
+}
+
+// text returns a random valid UTF-8 string
+func (g *generator) text() string {
+	alphabet := []string{"a", "Z", "0", " ", "é", "日", "🙂", "\n", `"`, "\\", "\x00"}
+	var b strings.Builder
+	for i := g.r.Intn(8); i > 0; i-- {
+		b.WriteString(alphabet[g.r.Intn(len(alphabet))])
+	}
+	return b.String()
+}
+
+// key returns a random map key
+func (g *generator) key() string {
+	return fmt.Sprintf("k%d", g.r.Intn(10))
+}
+
+// time returns a random UTC time between 1970 and 2100
+func (g *generator) time() time.Time {
+	return time.Unix(0, g.r.Int63n(130*365*24*int64(time.Hour))).UTC()
+}
+
+// fieldName returns the name of a struct field in all three formats
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
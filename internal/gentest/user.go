@@ -0,0 +1,27 @@
+// Package gentest holds types used to test code generated by bogogen.
+package gentest
+
+import "time"
+
+//go:generate go run ../../cmd/bogogen -type User -accessors
+
+// User is a document read through the generated UserDoc accessors
+type User struct {
+	Name    string    `json:"name"`
+	Age     int       `json:"age"`
+	Visits  uint32    `json:"visits"`
+	Score   float32   `json:"score"`
+	Admin   bool      `json:"admin"`
+	Avatar  []byte    `json:"avatar"`
+	Joined  time.Time `json:"joined"`
+	Address *Address  `json:"address"`
+	Tags    []string  `json:"tags"`
+	Secret  string    `json:"-"`
+	private string
+}
+
+// Address is referenced by User and gets its own AddressDoc
+type Address struct {
+	City string `json:"city"`
+	Zip  string
+}
@@ -0,0 +1,94 @@
+// Code generated by bogogen; DO NOT EDIT.
+
+package gentest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bubunyo/bogo"
+)
+
+// UserDoc reads the fields of an encoded User in place.
+type UserDoc []byte
+
+// Name reads field "name".
+func (d UserDoc) Name() (string, error) {
+	return bogo.FieldString(d, "name")
+}
+
+// Age reads field "age".
+func (d UserDoc) Age() (int, error) {
+	v, err := bogo.FieldInt(d, "age")
+	if err != nil {
+		return 0, err
+	}
+	if v < math.MinInt || v > math.MaxInt {
+		return 0, fmt.Errorf("bogo: field age: value %d overflows int", v)
+	}
+	return int(v), nil
+}
+
+// Visits reads field "visits".
+func (d UserDoc) Visits() (uint32, error) {
+	v, err := bogo.FieldUint(d, "visits")
+	if err != nil {
+		return 0, err
+	}
+	if v > math.MaxUint32 {
+		return 0, fmt.Errorf("bogo: field visits: value %d overflows uint32", v)
+	}
+	return uint32(v), nil
+}
+
+// Score reads field "score".
+func (d UserDoc) Score() (float32, error) {
+	v, err := bogo.FieldFloat(d, "score")
+	if err != nil {
+		return 0, err
+	}
+	if !math.IsInf(v, 0) && math.Abs(v) > math.MaxFloat32 {
+		return 0, fmt.Errorf("bogo: field score: value %g overflows float32", v)
+	}
+	return float32(v), nil
+}
+
+// Admin reads field "admin".
+func (d UserDoc) Admin() (bool, error) {
+	return bogo.FieldBool(d, "admin")
+}
+
+// Avatar reads field "avatar".
+func (d UserDoc) Avatar() ([]byte, error) {
+	return bogo.FieldBlob(d, "avatar")
+}
+
+// Joined reads field "joined".
+func (d UserDoc) Joined() (time.Time, error) {
+	return bogo.FieldTime(d, "joined")
+}
+
+// Address reads field "address".
+func (d UserDoc) Address() (AddressDoc, error) {
+	v, err := bogo.FieldObject(d, "address")
+	return AddressDoc(v), err
+}
+
+// Tags reads field "tags".
+func (d UserDoc) Tags() (any, error) {
+	return bogo.FieldValue(d, "tags")
+}
+
+// AddressDoc reads the fields of an encoded Address in place.
+type AddressDoc []byte
+
+// City reads field "city".
+func (d AddressDoc) City() (string, error) {
+	return bogo.FieldString(d, "city")
+}
+
+// Zip reads field "Zip".
+func (d AddressDoc) Zip() (string, error) {
+	return bogo.FieldString(d, "Zip")
+}
@@ -0,0 +1,97 @@
+package gentest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDoc(t *testing.T) {
+	joined := time.UnixMilli(1700000000000)
+	data, err := bogo.Marshal(User{
+		Name:    "Ada",
+		Age:     36,
+		Visits:  12,
+		Score:   9.5,
+		Admin:   true,
+		Avatar:  []byte{1, 2},
+		Joined:  joined,
+		Address: &Address{City: "London", Zip: "N1"},
+		Tags:    []string{"a", "b"},
+	})
+	require.NoError(t, err)
+
+	doc := UserDoc(data)
+
+	name, err := doc.Name()
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", name)
+
+	age, err := doc.Age()
+	require.NoError(t, err)
+	assert.Equal(t, 36, age)
+
+	visits, err := doc.Visits()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12), visits)
+
+	score, err := doc.Score()
+	require.NoError(t, err)
+	assert.Equal(t, float32(9.5), score)
+
+	admin, err := doc.Admin()
+	require.NoError(t, err)
+	assert.True(t, admin)
+
+	avatar, err := doc.Avatar()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, avatar)
+
+	when, err := doc.Joined()
+	require.NoError(t, err)
+	assert.True(t, joined.Equal(when))
+
+	address, err := doc.Address()
+	require.NoError(t, err)
+	city, err := address.City()
+	require.NoError(t, err)
+	assert.Equal(t, "London", city)
+	zip, err := address.Zip()
+	require.NoError(t, err)
+	assert.Equal(t, "N1", zip)
+
+	tags, err := doc.Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tags)
+}
+
+func TestUserDocNilAddress(t *testing.T) {
+	data, err := bogo.Marshal(User{Name: "Bob"})
+	require.NoError(t, err)
+
+	address, err := UserDoc(data).Address()
+	require.NoError(t, err)
+
+	assert.Nil(t, address)
+	_, err = address.City()
+	assert.True(t, errors.Is(err, bogo.ErrFieldNotFound))
+}
+
+func TestUserDocOverflow(t *testing.T) {
+	data, err := bogo.Marshal(map[string]any{
+		"visits": uint64(1) << 40,
+		"score":  1e300,
+	})
+	require.NoError(t, err)
+
+	doc := UserDoc(data)
+
+	_, err = doc.Visits()
+	assert.ErrorContains(t, err, "overflows uint32")
+	_, err = doc.Score()
+	assert.ErrorContains(t, err, "overflows float32")
+}
@@ -0,0 +1,4 @@
+// Package idltest holds messages used to test code generated by bogoidl.
+package idltest
+
+//go:generate go run ../../cmd/bogoidl users.bogo
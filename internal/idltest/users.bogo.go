@@ -0,0 +1,126 @@
+// Code generated by bogoidl from users.bogo; DO NOT EDIT.
+
+package idltest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bubunyo/bogo"
+)
+
+// Field IDs of User
+const (
+	UserNameField              = 1
+	UserEmailField             = 2
+	UserAgeField               = 3
+	UserTagsField              = 4
+	UserLabelsField            = 5
+	UserJoinedField            = 6
+	UserAddressField           = 7
+	UserPreviousAddressesField = 8
+	UserOfficesField           = 9
+	UserAvatarField            = 10
+	UserScoreField             = 11
+	UserAdminField             = 12
+	UserUserIDField            = 13
+)
+
+// User is generated from message User.
+type User struct {
+	Name              string             `json:"name"`
+	Email             string             `json:"email,format=email"`
+	Age               int64              `json:"age,omitempty"`
+	Tags              []string           `json:"tags"`
+	Labels            map[string]string  `json:"labels"`
+	Joined            time.Time          `json:"joined"`
+	Address           *Address           `json:"address"`
+	PreviousAddresses []Address          `json:"previous_addresses"`
+	Offices           map[string]Address `json:"offices"`
+	Avatar            []byte             `json:"avatar"`
+	Score             float64            `json:"score"`
+	Admin             bool               `json:"admin"`
+	UserID            uint64             `json:"user_id"`
+}
+
+// Validate checks the constraints declared on User.
+func (m *User) Validate() error {
+	if len(m.Name) == 0 {
+		return errors.New("name is required")
+	}
+	if err := bogo.ValidateFormat("email", m.Email); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if m.Address != nil {
+		if err := m.Address.Validate(); err != nil {
+			return fmt.Errorf("address: %w", err)
+		}
+	}
+	for i := range m.PreviousAddresses {
+		if err := m.PreviousAddresses[i].Validate(); err != nil {
+			return fmt.Errorf("previous_addresses[%d]: %w", i, err)
+		}
+	}
+	for k, v := range m.Offices {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("offices[%q]: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// Encode validates m and encodes it with bogo.Marshal.
+func (m *User) Encode() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return bogo.Marshal(m)
+}
+
+// Decode decodes data into m with bogo.Unmarshal and validates the result.
+func (m *User) Decode(data []byte) error {
+	if err := bogo.Unmarshal(data, m); err != nil {
+		return err
+	}
+	return m.Validate()
+}
+
+// Field IDs of Address
+const (
+	AddressCityField  = 1
+	AddressPhoneField = 2
+)
+
+// Address is generated from message Address.
+type Address struct {
+	City  string `json:"city"`
+	Phone string `json:"phone,format=e164"`
+}
+
+// Validate checks the constraints declared on Address.
+func (m *Address) Validate() error {
+	if len(m.City) == 0 {
+		return errors.New("city is required")
+	}
+	if err := bogo.ValidateFormat("e164", m.Phone); err != nil {
+		return fmt.Errorf("phone: %w", err)
+	}
+	return nil
+}
+
+// Encode validates m and encodes it with bogo.Marshal.
+func (m *Address) Encode() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return bogo.Marshal(m)
+}
+
+// Decode decodes data into m with bogo.Unmarshal and validates the result.
+func (m *Address) Decode(data []byte) error {
+	if err := bogo.Unmarshal(data, m); err != nil {
+		return err
+	}
+	return m.Validate()
+}
@@ -0,0 +1,94 @@
+package idltest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bubunyo/bogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedMessages(t *testing.T) {
+	user := User{
+		Name:              "Ada",
+		Email:             "ada@example.com",
+		Age:               36,
+		Tags:              []string{"admin", "ops"},
+		Labels:            map[string]string{"team": "core"},
+		Joined:            time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Address:           &Address{City: "London", Phone: "+442071234567"},
+		PreviousAddresses: []Address{{City: "Paris"}},
+		Offices:           map[string]Address{"hq": {City: "Accra"}},
+		Avatar:            []byte{1, 2, 3},
+		Score:             9.5,
+		Admin:             true,
+		UserID:            42,
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		data, err := user.Encode()
+		require.NoError(t, err)
+
+		var decoded User
+		require.NoError(t, decoded.Decode(data))
+		assert.Equal(t, user.Name, decoded.Name)
+		assert.Equal(t, user.Email, decoded.Email)
+		assert.Equal(t, user.Age, decoded.Age)
+		assert.Equal(t, user.Tags, decoded.Tags)
+		assert.Equal(t, user.Labels, decoded.Labels)
+		assert.True(t, user.Joined.Equal(decoded.Joined))
+		assert.Equal(t, user.Address, decoded.Address)
+		assert.Equal(t, user.PreviousAddresses, decoded.PreviousAddresses)
+		assert.Equal(t, user.Offices, decoded.Offices)
+		assert.Equal(t, user.Avatar, decoded.Avatar)
+		assert.Equal(t, user.Score, decoded.Score)
+		assert.Equal(t, user.Admin, decoded.Admin)
+		assert.Equal(t, user.UserID, decoded.UserID)
+	})
+
+	t.Run("optional fields are omitted", func(t *testing.T) {
+		data, err := (&User{Name: "Ada"}).Encode()
+		require.NoError(t, err)
+
+		_, err = bogo.LookupField(data, "age")
+		assert.ErrorIs(t, err, bogo.ErrFieldNotFound)
+		_, err = bogo.LookupField(data, "email")
+		assert.NoError(t, err)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name string
+			user User
+			err  string
+		}{
+			{"required", User{}, "name is required"},
+			{"format", User{Name: "Ada", Email: "not an email"}, "email: bogo: value \"not an email\" is not a valid email"},
+			{"nested required", User{Name: "Ada", Address: &Address{}}, "address: city is required"},
+			{"nested format", User{Name: "Ada", Address: &Address{City: "x", Phone: "123"}}, "address: phone:"},
+			{"repeated", User{Name: "Ada", PreviousAddresses: []Address{{City: "x"}, {}}}, "previous_addresses[1]: city is required"},
+			{"map", User{Name: "Ada", Offices: map[string]Address{"hq": {}}}, `offices["hq"]: city is required`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := tt.user.Encode()
+				assert.ErrorContains(t, err, tt.err)
+			})
+		}
+	})
+
+	t.Run("decode validates", func(t *testing.T) {
+		data, err := bogo.Marshal(map[string]any{"email": "ada@example.com"})
+		require.NoError(t, err)
+
+		var decoded User
+		assert.EqualError(t, decoded.Decode(data), "name is required")
+	})
+
+	t.Run("field IDs", func(t *testing.T) {
+		assert.Equal(t, 1, UserNameField)
+		assert.Equal(t, 13, UserUserIDField)
+		assert.Equal(t, 2, AddressPhoneField)
+	})
+}
@@ -0,0 +1,35 @@
+// Command tinygo is a firmware-style telemetry encoder that CI compiles with
+// TinyGo to keep the primitive, blob and typed list paths building there.
+// It also builds and runs with the standard toolchain.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bubunyo/bogo"
+)
+
+func main() {
+	encoder := bogo.NewConfigurableEncoder(bogo.WithCompactIntegers(true), bogo.WithCompactLists(true))
+	data, err := encoder.Encode(map[string]any{
+		"device":   "sensor-7",
+		"uptime":   uint32(86400),
+		"battery":  87,
+		"charging": false,
+		"temps":    []float64{21.5, 21.75, 22},
+		"samples":  []int64{3, -1, 4},
+		"firmware": []byte{0x01, 0x02},
+	})
+	if err != nil {
+		fmt.Println("encode:", err)
+		os.Exit(1)
+	}
+
+	decoded, err := bogo.Decode(data)
+	if err != nil {
+		fmt.Println("decode:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d bytes: %v\n", len(data), decoded)
+}
@@ -0,0 +1,48 @@
+package bogo
+
+import "sync"
+
+// KeyCache holds the object keys a decoder has built, so that later objects
+// repeating them reuse the same strings. It is safe for concurrent use.
+type KeyCache struct {
+	mu   sync.RWMutex
+	keys map[string]string
+	max  int
+}
+
+// NewKeyCache returns a cache holding up to max keys
+func NewKeyCache(max int) *KeyCache {
+	return &KeyCache{keys: make(map[string]string), max: max}
+}
+
+// Len returns the number of cached keys
+func (c *KeyCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.keys)
+}
+
+// key returns the cached string for b, building it with strs and caching it
+// when the cache has room
+func (c *KeyCache) key(b []byte, strs stringMaker) string {
+	c.mu.RLock()
+	s, ok := c.keys[string(b)]
+	full := len(c.keys) >= c.max
+	c.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	s = strs.str(b)
+	if full {
+		return s
+	}
+	c.mu.Lock()
+	if cached, ok := c.keys[s]; ok {
+		s = cached // added by a concurrent decode
+	} else if len(c.keys) < c.max {
+		c.keys[s] = s
+	}
+	c.mu.Unlock()
+	return s
+}
@@ -0,0 +1,124 @@
+package bogo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyData returns the address of the bytes of the key of m equal to key
+func keyData(m map[string]any, key string) *byte {
+	for k := range m {
+		if k == key {
+			return unsafe.StringData(k)
+		}
+	}
+	return nil
+}
+
+func TestKeyCache(t *testing.T) {
+	d := NewConfigurableDecoder(WithKeyCache(100))
+
+	var first map[string]any
+	for i := 0; i < 3; i++ {
+		data, err := Marshal(map[string]any{"username": fmt.Sprint("user", i), "timestamp": i})
+		require.NoError(t, err)
+		var v map[string]any
+		require.NoError(t, d.Unmarshal(data, &v))
+		assert.Equal(t, fmt.Sprint("user", i), v["username"])
+
+		if first == nil {
+			first = v
+			continue
+		}
+		assert.Same(t, keyData(first, "username"), keyData(v, "username"), "keys are shared across decodes")
+		assert.Same(t, keyData(first, "timestamp"), keyData(v, "timestamp"))
+	}
+	assert.Equal(t, 2, d.KeyCache.Len(), "values are not cached")
+
+	t.Run("flat maps", func(t *testing.T) {
+		data, err := Marshal(map[string]string{"username": "ada"})
+		require.NoError(t, err)
+		var v map[string]string
+		require.NoError(t, d.Unmarshal(data, &v))
+		for k := range v {
+			assert.Same(t, keyData(first, "username"), unsafe.StringData(k))
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		d := NewConfigurableDecoder(WithKeyCache(2))
+		data, err := Marshal(map[string]any{"a": 1, "b": 2, "c": 3, "d": 4})
+		require.NoError(t, err)
+		v, err := d.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": int64(1), "b": int64(2), "c": int64(3), "d": int64(4)}, v)
+		assert.Equal(t, 2, d.KeyCache.Len())
+	})
+
+	t.Run("with a string interner", func(t *testing.T) {
+		table := &stringTable{strings: map[string]string{}}
+		d := NewConfigurableDecoder(WithKeyCache(10), WithStringInterner(table.intern))
+		data, err := Marshal(map[string]any{"status": "ok"})
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err := d.Decode(data)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 4, table.calls, "the interner builds each key once and every value")
+	})
+
+	t.Run("concurrent decodes", func(t *testing.T) {
+		// Encoders and decoders hold per-call state, so the documents are
+		// encoded up front and each goroutine decodes with its own decoder
+		// sharing the cache
+		cache := NewKeyCache(1000)
+		docs := make([][]byte, 50)
+		for i := range docs {
+			data, err := Marshal(map[string]any{fmt.Sprint("k", i%20): i})
+			require.NoError(t, err)
+			docs[i] = data
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d := NewConfigurableDecoder()
+				d.KeyCache = cache
+				for _, data := range docs {
+					_, err := d.Decode(data)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 20, cache.Len())
+	})
+}
+
+func BenchmarkKeyCache(b *testing.B) {
+	data, err := Marshal(map[string]any{"username": "ada", "timestamp": 1, "status": "ok", "region": "eu"})
+	require.NoError(b, err)
+
+	for _, bm := range []struct {
+		name    string
+		decoder *Decoder
+	}{
+		{"off", NewConfigurableDecoder()},
+		{"on", NewConfigurableDecoder(WithKeyCache(1024))},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bm.decoder.Decode(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var keyTableErr = errors.New("bogo key table error")
+
+// An object whose keys are in the symbol table of the document is laid out as
+//
+//	TypeKeyedObject | size prefix | key | value | key | value ...
+//
+// where each key is a TypeSymbol value referring to the table of the innermost
+// TypeSymbols around the object, and values are complete encoded values. It
+// decodes as an object.
+
+// WithKeyTable writes each distinct object key of a document once, in the
+// symbol table at its start that also holds enum values, and refers to it by
+// index in every object that uses it. Lists of many objects with the same keys
+// shrink by the size of their keys, whichever types the objects come from.
+// Document edits adding a key missing from the table rewrite its object with
+// plain keys.
+func WithKeyTable(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.KeyTable = enabled
+	}
+}
+
+// keyTableActive reports whether the objects being encoded refer to their keys
+// through the symbol table. The object requested plain by encodeObjectList is
+// not, and clears the request.
+func (e *Encoder) keyTableActive() bool {
+	plain := e.plainKeys
+	e.plainKeys = false
+	return e.KeyTable && e.symbols != nil && !plain
+}
+
+// encodeKeyedEntry encodes the key, as a reference to the symbol table, and
+// the value of an entry of a keyed object
+func (e *Encoder) encodeKeyedEntry(key string, value any) ([]byte, error) {
+	if len(key) > 255 {
+		return nil, fmt.Errorf("key too long, maximum 255 bytes")
+	}
+	encodedValue, err := e.encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return append(e.symbols.ref(key), encodedValue...), nil
+}
+
+// decodeKeyedObjectWith decodes the keyed object data, after its type byte,
+// resolving its keys with strs and decoding the values of the keys keep
+// reports true, or of every key when keep is nil, with decode
+func decodeKeyedObjectWith(data []byte, strs stringMaker, keep func(string) bool, decode func([]byte) (any, error)) (map[string]any, error) {
+	entries, err := containerPayload(data)
+	if err != nil {
+		return nil, wrapError(keyTableErr, err.Error())
+	}
+	result := make(map[string]any, countElements(entries)/2)
+	var decodeErr error
+	err = walkKeyedEntries(entries, strs, func(_, _ int, key string, value []byte) bool {
+		if keep == nil || keep(key) {
+			result[key], decodeErr = decode(value)
+		}
+		return decodeErr == nil
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// walkKeyedEntries calls fn with the bounds, key and encoded value of each
+// entry of entries, the payload of a keyed object, until fn returns false.
+// Keys are resolved with strs. An entry spans entries[start:end], its value
+// ending it.
+func walkKeyedEntries(entries []byte, strs stringMaker, fn func(start, end int, key string, value []byte) bool) error {
+	for pos := 0; pos < len(entries); {
+		key, keySize, err := keyedObjectKey(entries[pos:], strs)
+		if err != nil {
+			return err
+		}
+		valueStart := pos + keySize
+		if valueStart >= len(entries) {
+			return wrapError(keyTableErr, fmt.Sprintf("no value for key %q", key))
+		}
+		size, err := getElementSize(entries[valueStart:])
+		if err != nil {
+			return err
+		}
+		if valueStart+size > len(entries) {
+			return wrapError(keyTableErr, fmt.Sprintf("insufficient data for key %q", key))
+		}
+		if !fn(pos, valueStart+size, key, entries[valueStart:valueStart+size]) {
+			return nil
+		}
+		pos = valueStart + size
+	}
+	return nil
+}
+
+// keyedEntry returns the entry of a keyed object for key and the encoded
+// value, referring to key in symbols, or false when symbols lack it
+func keyedEntry(symbols []string, key string, value []byte) ([]byte, bool) {
+	i := slices.Index(symbols, key)
+	if i < 0 {
+		return nil, false
+	}
+	entry := appendUvarintLen([]byte{byte(TypeSymbol)}, uint64(i))
+	return append(entry, value...), true
+}
+
+// plainObject returns the keyed object value, starting at its type byte, as
+// an object with its keys written out. Keys are resolved against symbols.
+func plainObject(value []byte, symbols []string) ([]byte, error) {
+	entries, err := containerPayload(value[1:])
+	if err != nil {
+		return nil, wrapError(keyTableErr, err.Error())
+	}
+	var fields []byte
+	err = walkKeyedEntries(entries, stringMaker{symbols: symbols}, func(_, _ int, key string, v []byte) bool {
+		fields = appendObjectEntry(fields, key, v)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildContainer(TypeObject, fields)
+}
+
+// keyedObjectKey returns the key at the start of data, an entry of a keyed
+// object, and its encoded size
+func keyedObjectKey(data []byte, strs stringMaker) (string, int, error) {
+	if Type(data[0]) != TypeSymbol {
+		return "", 0, wrapError(keyTableErr, fmt.Sprintf("key of type %s", Type(data[0])))
+	}
+	_, size, err := symbolIndex(data[1:])
+	if err != nil {
+		return "", 0, err
+	}
+	key, err := strs.symbol(data[1:])
+	if err != nil {
+		return "", 0, err
+	}
+	return key.(string), 1 + size, nil
+}
+
+// decodeKeyedObject decodes the keyed object data, after its type byte,
+// keeping only the selected fields when the decoder has some
+func (d *Decoder) decodeKeyedObject(data []byte, decode func([]byte) (any, error)) (any, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+
+	var keep func(string) bool
+	if len(d.SelectiveFields) > 0 {
+		keep = func(key string) bool {
+			for _, field := range d.SelectiveFields {
+				if field == key {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return decodeKeyedObjectWith(data, d.strings(), keep, decode)
+}
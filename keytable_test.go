@@ -0,0 +1,298 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyTable(t *testing.T) {
+	type event struct {
+		Kind   string         `json:"kind"`
+		Status testStatus     `json:"status"`
+		Count  int64          `json:"count"`
+		Tags   map[string]any `json:"tags"`
+	}
+	events := make([]event, 50)
+	for i := range events {
+		events[i] = event{Kind: "click", Status: statusActive, Count: int64(i), Tags: map[string]any{"source": "web"}}
+	}
+	encoder := NewConfigurableEncoder(WithKeyTable(true))
+
+	data, err := encoder.Encode(events)
+	require.NoError(t, err)
+	assert.Equal(t, byte(TypeSymbols), data[1])
+	plain, err := Marshal(events)
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(plain)*3/4)
+
+	var out []event
+	require.NoError(t, Unmarshal(data, &out))
+	assert.Equal(t, events, out)
+
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	want, err := Decode(plain)
+	require.NoError(t, err)
+	assert.Equal(t, want, decoded)
+	decoded, err = NewSecureDecoder().Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, want, decoded)
+
+	_, err = Footprint(data)
+	assert.NoError(t, err)
+
+	t.Run("selected fields", func(t *testing.T) {
+		data, err := encoder.Encode(map[string]any{"kind": "click", "count": 3})
+		require.NoError(t, err)
+		decoded, err := NewConfigurableDecoder(WithSelectiveFields([]string{"kind"})).Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"kind": "click"}, decoded)
+	})
+
+	t.Run("enums as strings", func(t *testing.T) {
+		data, err := NewConfigurableEncoder(WithKeyTable(true), WithEnumsAsStrings(true)).Encode(events[0])
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "active", decoded.(map[string]any)["status"])
+		symbols, _, err := readSymbols(data[2:], stringMaker{})
+		require.NoError(t, err)
+		assert.NotContains(t, symbols, "active")
+	})
+
+	t.Run("object lists keep their rows plain", func(t *testing.T) {
+		e := NewConfigurableEncoder(WithKeyTable(true), WithObjectLists(true))
+		data, err := e.Encode(events)
+		require.NoError(t, err)
+		_, offset, err := symbolsTable(data[2:])
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeObjectList), data[2+offset])
+		var out []event
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, events, out)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		entry := append([]byte{byte(TypeSymbol), 1, 0}, byte(TypeBoolTrue))
+		object, err := buildContainer(TypeKeyedObject, append(entry, entry...))
+		require.NoError(t, err)
+		table, err := encodeString("a")
+		require.NoError(t, err)
+		data := appendTypedHeader([]byte{Version}, TypeSymbols, uint64(len(table)))
+		data = append(append(data, table...), object...)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": true}, decoded)
+		_, err = NewSecureDecoder().Decode(data)
+		assert.ErrorContains(t, err, "duplicate object key")
+	})
+
+	t.Run("keys outside a table", func(t *testing.T) {
+		object, err := buildContainer(TypeKeyedObject, []byte{byte(TypeSymbol), 1, 0, byte(TypeBoolTrue)})
+		require.NoError(t, err)
+		_, err = Decode(append([]byte{Version}, object...))
+		assert.ErrorContains(t, err, "symbol 0 outside a table of 0")
+	})
+}
+
+func TestKeyTableDocuments(t *testing.T) {
+	// The byte-level APIs read keyed objects through the table at the root
+	encoder := NewConfigurableEncoder(WithKeyTable(true))
+	data, err := encoder.Encode(map[string]any{
+		"kind":    "click",
+		"count":   int64(3),
+		"tags":    map[string]any{"source": "web"},
+		"history": []any{"a"},
+		"events":  []any{map[string]any{"kind": "view"}},
+		"session": ExpiresIn("t0k3n", time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, byte(TypeSymbols), data[1])
+	_, root, err := splitSymbols(data[1:])
+	require.NoError(t, err)
+	require.Equal(t, byte(TypeKeyedObject), root[0])
+
+	rootType := func(t *testing.T, data []byte) byte {
+		t.Helper()
+		_, root, err := splitSymbols(data[1:])
+		require.NoError(t, err)
+		return root[0]
+	}
+
+	t.Run("fields and accessors", func(t *testing.T) {
+		fields, err := Fields(data)
+		require.NoError(t, err)
+		var keys []string
+		for key, raw := range fields {
+			_, err := decodeValue(raw)
+			assert.NoError(t, err, key)
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, []string{"kind", "count", "tags", "history", "events", "session"}, keys)
+
+		kind, err := FieldString(data, "kind")
+		require.NoError(t, err)
+		assert.Equal(t, "click", kind)
+		count, err := FieldInt(data, "count")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		events, err := FieldValue(data, "events")
+		require.NoError(t, err)
+		assert.Equal(t, []any{map[string]any{"kind": "view"}}, events)
+
+		tags, err := FieldObject(data, "tags")
+		require.NoError(t, err)
+		source, err := FieldString(tags, "source")
+		require.NoError(t, err)
+		assert.Equal(t, "web", source)
+	})
+
+	t.Run("documents", func(t *testing.T) {
+		doc := Document(data)
+		values, err := doc.Select("tags.source", "events[0].kind")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"tags.source": "web", "events[0].kind": "view"}, values)
+
+		// Keys in the table keep their objects keyed
+		doc, err = doc.Set("tags.source", "app")
+		require.NoError(t, err)
+		doc, err = doc.Set("tags.kind", "internal")
+		require.NoError(t, err)
+		doc, err = doc.Delete("session")
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeKeyedObject), rootType(t, doc))
+
+		// A new key rewrites its object with plain keys
+		doc, err = doc.Set("region", "eu")
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeObject), rootType(t, doc))
+
+		decoded, err := Decode(doc)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"kind":    "click",
+			"count":   int64(3),
+			"tags":    map[string]any{"source": "app", "kind": "internal"},
+			"history": []any{"a"},
+			"events":  []any{map[string]any{"kind": "view"}},
+			"region":  "eu",
+		}, decoded)
+	})
+
+	t.Run("append to lists", func(t *testing.T) {
+		appended, err := AppendToList(append([]byte(nil), data...), "history", "b")
+		require.NoError(t, err)
+		history, err := FieldValue(appended, "history")
+		require.NoError(t, err)
+		assert.Equal(t, []any{"a", "b"}, history)
+		kind, err := FieldString(appended, "kind")
+		require.NoError(t, err)
+		assert.Equal(t, "click", kind)
+	})
+
+	t.Run("transforms", func(t *testing.T) {
+		tr, err := CompileTransform(
+			RenameField("tags.source", "kind"),
+			RenameField("count", "total"),
+			CastField("total", TypeString),
+			MoveField("events", "tags.events"),
+			DropField("session"),
+		)
+		require.NoError(t, err)
+		out, err := tr.Apply(data)
+		require.NoError(t, err)
+
+		decoded, err := Decode(out)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"kind":    "click",
+			"total":   "3",
+			"history": []any{"a"},
+			"tags": map[string]any{
+				"kind":   "web",
+				"events": []any{map[string]any{"kind": "view"}},
+			},
+		}, decoded)
+	})
+
+	t.Run("encrypted fields", func(t *testing.T) {
+		aead := newTestAEAD(t, 1)
+		encrypted, err := EncryptFields(data, aead)
+		require.NoError(t, err)
+
+		raw, err := DecryptField(encrypted, "tags", aead)
+		require.NoError(t, err)
+		tags, err := decodeValue(raw)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"source": "web"}, tags)
+
+		plain, err := DecryptFields(encrypted, aead)
+		require.NoError(t, err)
+		want, err := Decode(data)
+		require.NoError(t, err)
+		got, err := Decode(plain)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("pruning", func(t *testing.T) {
+		pruned, err := Prune(data, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeKeyedObject), rootType(t, pruned))
+
+		decoded, err := Decode(pruned)
+		require.NoError(t, err)
+		assert.NotContains(t, decoded, "session")
+		assert.Equal(t, "click", decoded.(map[string]any)["kind"])
+	})
+
+	t.Run("selections", func(t *testing.T) {
+		got, err := DecodeSelection(data, Selection{"kind": nil, "tags": {"source": nil}, "events": {"kind": nil}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"kind":   "click",
+			"tags":   map[string]any{"source": "web"},
+			"events": []any{map[string]any{"kind": "view"}},
+		}, got)
+
+		got, err = DecodeSelection(data, Selection{"tags": {}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"tags": map[string]any{}}, got)
+	})
+
+	t.Run("lazy objects", func(t *testing.T) {
+		got, err := NewConfigurableDecoder(WithLazyObjects(true)).Decode(data)
+		require.NoError(t, err)
+		obj, ok := got.(*LazyObject)
+		require.True(t, ok, "got %T", got)
+		assert.Equal(t, 6, obj.Len())
+		tags, err := obj.Get("tags")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"source": "web"}, tags)
+
+		parsed, err := ParseLazyObject(data)
+		require.NoError(t, err)
+		kind, err := parsed.Get("kind")
+		require.NoError(t, err)
+		assert.Equal(t, "click", kind)
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		got, err := NewConfigurableDecoder(WithTypedValues(true)).Decode(data)
+		require.NoError(t, err)
+		root := got.(TypedValue)
+		assert.Equal(t, Type(TypeKeyedObject), root.Type)
+		obj := root.Value.(map[string]any)
+		assert.Equal(t, TypedValue{Type: TypeString, Value: "click"}, obj["kind"])
+		assert.Equal(t, TypedValue{Type: TypeKeyedObject, Value: map[string]any{
+			"source": TypedValue{Type: TypeString, Value: "web"},
+		}}, obj["tags"])
+	})
+}
@@ -0,0 +1,213 @@
+package bogo
+
+import "encoding/binary"
+
+// wireOrder is the byte order of every fixed-width integer on the wire,
+// independent of the host platform. Variable-length integers use the
+// LEB128-style varints of encoding/binary, which are byte order free.
+var wireOrder = binary.LittleEndian
+
+// WireByteOrder returns the byte order of fixed-width integers on the wire.
+// It is always little-endian; ports to other platforms and languages can use
+// it together with LayoutOf to check their byte handling.
+func WireByteOrder() binary.ByteOrder {
+	return wireOrder
+}
+
+// WireEncoding describes how a single field of an encoded value is stored
+type WireEncoding uint8
+
+const (
+	// WireFixed is an unsigned little-endian integer of Size bytes
+	WireFixed WireEncoding = iota
+	// WireFixedSigned is a two's complement little-endian integer of Size bytes
+	WireFixedSigned
+	// WireUvarint is a 1-byte length followed by an unsigned varint of that many bytes
+	WireUvarint
+	// WireVarint is a 1-byte length followed by a zig-zag signed varint of that many bytes
+	WireVarint
+	// WireFloat is a 1-byte length followed by a 2-byte little-endian sign and
+	// exponent and, when the length exceeds 2, an unsigned varint mantissa
+	WireFloat
+	// WireBytes is raw data. Its size is Size when non-zero, otherwise the
+	// value of the preceding WireUvarint field.
+	WireBytes
+	// WireValue is a complete nested value starting with its type byte
+	WireValue
+	// WireValues is a run of nested values filling the size given by the
+	// preceding WireUvarint field
+	WireValues
+	// WireEntries is a run of object field entries filling the size given by
+	// the preceding WireUvarint field. Each entry is a WireUvarint entry size,
+	// a 1-byte key length, the key and a WireValue.
+	WireEntries
+	// WireTypedElements is a typed list body filling the size given by the
+	// preceding WireUvarint field: a 1-byte element type, a WireUvarint count
+	// and the elements without type bytes.
+	WireTypedElements
+	// WireKeyedValues is a map body filling the size given by the preceding
+	// WireUvarint field: a 1-byte key type followed by alternating key and
+	// value WireValues.
+	WireKeyedValues
+	// WireRows is an object list body filling the size given by the preceding
+	// WireUvarint field: WireUvarint key and row counts, the keys, each a
+	// 1-byte length followed by the key, and key count times row count
+	// WireValues, row by row.
+	WireRows
+	// WireChunks is a run of chunks, each a WireUvarint size followed by
+	// WireValues filling it, ended by a zero size
+	WireChunks
+)
+
+func (e WireEncoding) String() string {
+	switch e {
+	case WireFixed:
+		return "fixed"
+	case WireFixedSigned:
+		return "fixed-signed"
+	case WireUvarint:
+		return "uvarint"
+	case WireVarint:
+		return "varint"
+	case WireFloat:
+		return "float"
+	case WireBytes:
+		return "bytes"
+	case WireValue:
+		return "value"
+	case WireValues:
+		return "values"
+	case WireEntries:
+		return "entries"
+	case WireTypedElements:
+		return "typed-elements"
+	case WireKeyedValues:
+		return "keyed-values"
+	case WireRows:
+		return "rows"
+	case WireChunks:
+		return "chunks"
+	}
+	return "<unknown>"
+}
+
+// WireField is one field of a wire layout
+type WireField struct {
+	Name     string
+	Encoding WireEncoding
+	Size     int // Size in bytes for fixed-size fields, 0 when variable
+}
+
+// WireLayout lists the fields that follow the type byte of an encoded value,
+// in wire order
+type WireLayout struct {
+	Type   Type
+	Fields []WireField
+}
+
+// sizedFields is the common layout of a length followed by data
+func sizedFields(data WireEncoding) []WireField {
+	return []WireField{
+		{Name: "Size", Encoding: WireUvarint},
+		{Name: "Data", Encoding: data},
+	}
+}
+
+var wireLayouts = map[Type][]WireField{
+	TypeNull:        nil,
+	TypeBoolTrue:    nil,
+	TypeBoolFalse:   nil,
+	TypeString:      sizedFields(WireBytes),
+	TypeByte:        {{Name: "Value", Encoding: WireFixed, Size: 1}},
+	TypeInt:         {{Name: "Value", Encoding: WireVarint}},
+	TypeUint:        {{Name: "Value", Encoding: WireUvarint}},
+	TypeFloat:       {{Name: "Value", Encoding: WireFloat}},
+	TypeBlob:        sizedFields(WireBytes),
+	TypeTimestamp:   {{Name: "Millis", Encoding: WireFixedSigned, Size: 8}},
+	TypeUntypedList: sizedFields(WireValues),
+	TypeTypedList:   sizedFields(WireTypedElements),
+	TypeObject:      sizedFields(WireEntries),
+	TypeDate: {
+		{Name: "Year", Encoding: WireFixedSigned, Size: 2},
+		{Name: "Month", Encoding: WireFixed, Size: 1},
+		{Name: "Day", Encoding: WireFixed, Size: 1},
+	},
+	TypeTimeOfDay: {
+		{Name: "Hour", Encoding: WireFixed, Size: 1},
+		{Name: "Minute", Encoding: WireFixed, Size: 1},
+		{Name: "Second", Encoding: WireFixed, Size: 1},
+		{Name: "Nanosecond", Encoding: WireFixed, Size: 4},
+	},
+	TypeRange: {
+		{Name: "Flags", Encoding: WireFixed, Size: 1},
+		{Name: "Start", Encoding: WireValue},
+		{Name: "End", Encoding: WireValue},
+	},
+	TypeDuration: {{Name: "Nanoseconds", Encoding: WireFixedSigned, Size: 8}},
+	TypeBigInt:   sizedFields(WireBytes),
+	TypeBigFloat: sizedFields(WireBytes),
+	TypeComplex: {
+		{Name: "Real", Encoding: WireFixed, Size: 8},
+		{Name: "Imag", Encoding: WireFixed, Size: 8},
+	},
+	TypeMap:        sizedFields(WireKeyedValues),
+	TypeObjectList: sizedFields(WireRows),
+	TypeZonedTimestamp: {
+		{Name: "Millis", Encoding: WireFixedSigned, Size: 8},
+		{Name: "Offset", Encoding: WireFixedSigned, Size: 4},
+		{Name: "NameSize", Encoding: WireUvarint},
+		{Name: "Name", Encoding: WireBytes},
+	},
+	TypeExt: {
+		{Name: "ID", Encoding: WireFixedSigned, Size: 1},
+		{Name: "Size", Encoding: WireUvarint},
+		{Name: "Data", Encoding: WireBytes},
+	},
+	TypeChunkedList: {{Name: "Chunks", Encoding: WireChunks}},
+	TypeDecimal: {
+		{Name: "Scale", Encoding: WireVarint},
+		{Name: "Unscaled", Encoding: WireVarint},
+	},
+	TypeExpiring: {
+		{Name: "Expiry", Encoding: WireFixedSigned, Size: 8},
+		{Name: "Value", Encoding: WireValue},
+	},
+	TypeSymbols: {
+		{Name: "Size", Encoding: WireUvarint},
+		{Name: "Symbols", Encoding: WireValues},
+		{Name: "Value", Encoding: WireValue},
+	},
+	TypeSymbol: {
+		{Name: "Index", Encoding: WireUvarint},
+	},
+	TypeUnion: {
+		{Name: "Size", Encoding: WireUvarint},
+		{Name: "Name", Encoding: WireBytes},
+		{Name: "Value", Encoding: WireValue},
+	},
+	TypeNumeric: {
+		{Name: "Kind", Encoding: WireFixed, Size: 1},
+		{Name: "Value", Encoding: WireValue},
+	},
+	TypeKeyedObject:    sizedFields(WireValues),
+	TypeTimestampNanos: {{Name: "Nanos", Encoding: WireFixedSigned, Size: 8}},
+}
+
+// LayoutOf returns the wire layout of values of type t. It reports false for
+// types this version of the format does not define.
+func LayoutOf(t Type) (WireLayout, bool) {
+	switch {
+	case isFixUint(t), isFixInt(t), t == TypeFixStr:
+		return WireLayout{Type: t}, true
+	case isFixStr(t):
+		return WireLayout{Type: t, Fields: []WireField{
+			{Name: "Data", Encoding: WireBytes, Size: int(t - TypeFixStr)},
+		}}, true
+	}
+
+	fields, ok := wireLayouts[t]
+	if !ok {
+		return WireLayout{}, false
+	}
+	return WireLayout{Type: t, Fields: fields}, true
+}
@@ -0,0 +1,39 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutOf(t *testing.T) {
+	t.Run("every type has a layout", func(t *testing.T) {
+		for _, typ := range AllTypes() {
+			layout, ok := LayoutOf(typ)
+			assert.True(t, ok, "type %s", typ)
+			assert.Equal(t, typ, layout.Type)
+		}
+	})
+
+	t.Run("compact types", func(t *testing.T) {
+		layout, ok := LayoutOf(TypeFixStr | 3)
+		assert.True(t, ok)
+		assert.Equal(t, []WireField{{Name: "Data", Encoding: WireBytes, Size: 3}}, layout.Fields)
+
+		layout, ok = LayoutOf(TypeFixUint | 3)
+		assert.True(t, ok)
+		assert.Empty(t, layout.Fields)
+	})
+
+	t.Run("unknown types", func(t *testing.T) {
+		_, ok := LayoutOf(0x99)
+		assert.False(t, ok)
+	})
+
+	t.Run("fixed-width fields are little-endian", func(t *testing.T) {
+		data, err := Encode(Date{Year: 0x0102, Month: 3, Day: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{Version, TypeDate, 0x02, 0x01, 3, 4}, data)
+		assert.Equal(t, uint16(0x0102), WireByteOrder().Uint16(data[2:4]))
+	})
+}
@@ -0,0 +1,158 @@
+package bogo
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LazyObject is an object whose keys are parsed up front but whose values are
+// kept encoded until they are first read with Get. Callers that list every key
+// but read only a few values skip decoding the rest. A LazyObject refers to
+// the data it was parsed from, which must not be modified while it is in use.
+// It is safe for concurrent use.
+type LazyObject struct {
+	keys []string          // in wire order, each key once
+	raw  map[string][]byte // encoded values, the last one for repeated keys
+
+	// open decrypts values before they are decoded, see ParseEncryptedObject
+	open func(key string, value []byte) ([]byte, error)
+	// symbols is the symbol table of the document the object was decoded from
+	symbols []string
+
+	mu     sync.Mutex
+	values map[string]any // values decoded so far
+}
+
+// WithLazyObjects makes Decode return a top-level object as a *LazyObject
+// instead of a map[string]any. Unmarshal decodes it in full unless the
+// destination is a *LazyObject or an interface.
+func WithLazyObjects(enabled bool) DecoderOption {
+	return func(d *Decoder) {
+		d.LazyObjects = enabled
+	}
+}
+
+// ParseLazyObject parses the keys of the object encoded in data. data is a
+// complete document or an encoded object value, such as one returned by Raw,
+// so nested objects can be navigated lazily as well. A null object parses as
+// nil.
+func ParseLazyObject(data []byte) (*LazyObject, error) {
+	return parseLazyObject(data, nil)
+}
+
+// parseLazyObject is ParseLazyObject resolving the keys of a keyed object
+// without a table of its own against symbols
+func parseLazyObject(data []byte, symbols []string) (*LazyObject, error) {
+	obj, err := objectValue(data)
+	if err != nil {
+		return nil, err
+	}
+	_, inner, err := splitSymbols(obj)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", accessorErr, err)
+	}
+	fields, err := containerPayload(inner[1:])
+	if err != nil {
+		return nil, wrapError(accessorErr, err.Error())
+	}
+	if len(fields) == 1 && fields[0] == TypeNull {
+		return nil, nil
+	}
+
+	o := &LazyObject{raw: make(map[string][]byte, countEntries(fields))}
+	err = walkFields(obj, symbols, func(key, value []byte) bool {
+		if _, seen := o.raw[string(key)]; !seen {
+			o.keys = append(o.keys, string(key))
+		}
+		o.raw[string(key)] = value
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// decodeLazyObject parses the object or keyed object at the start of data
+// with the key checks decodeObjectWithDepth applies
+func (d *Decoder) decodeLazyObject(data []byte) (any, error) {
+	o, err := parseLazyObject(data, d.symbols)
+	if err != nil || o == nil {
+		return nil, err
+	}
+	if d.StrictMode && d.ValidateUTF8 {
+		for _, key := range o.keys {
+			if !isValidUTF8(key) {
+				return nil, fmt.Errorf("bogo decode error: invalid UTF-8 in object key")
+			}
+		}
+	}
+	o.symbols = d.symbols
+	return o, nil
+}
+
+// Keys returns the keys of the object in the order they were written
+func (o *LazyObject) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Len returns the number of keys
+func (o *LazyObject) Len() int {
+	return len(o.keys)
+}
+
+// Has reports whether the object has key
+func (o *LazyObject) Has(key string) bool {
+	_, ok := o.raw[key]
+	return ok
+}
+
+// Raw returns the encoded value of key, starting at its type byte, or nil if
+// the object has no such key. It never decodes.
+func (o *LazyObject) Raw(key string) []byte {
+	return o.raw[key]
+}
+
+// Get decodes the value of key on first access and returns the cached value
+// afterwards. It returns ErrFieldNotFound if the object has no such key.
+func (o *LazyObject) Get(key string) (any, error) {
+	raw, ok := o.raw[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, key)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if v, ok := o.values[key]; ok {
+		return v, nil
+	}
+	if o.open != nil {
+		var err error
+		if raw, err = o.open(key, raw); err != nil {
+			return nil, err
+		}
+	}
+	v, err := decodeValueWith(raw, stringMaker{symbols: o.symbols})
+	if err != nil {
+		return nil, fmt.Errorf("bogo decode error: field %q: %w", key, err)
+	}
+	if o.values == nil {
+		o.values = make(map[string]any, len(o.keys))
+	}
+	o.values[key] = v
+	return v, nil
+}
+
+// Map decodes every value and returns the object as Decode returns it
+// without lazy decoding
+func (o *LazyObject) Map() (map[string]any, error) {
+	m := make(map[string]any, len(o.keys))
+	for _, key := range o.keys {
+		v, err := o.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
@@ -0,0 +1,141 @@
+package bogo
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyObjects(t *testing.T) {
+	data := append([]byte{Version}, rawObject(
+		rawEntry("name", "Ada"),
+		rawEntry("age", int64(36)),
+		rawEntry("address", map[string]any{"city": "London"}),
+		rawEntry("name", "Grace"),
+	)...)
+
+	d := NewConfigurableDecoder(WithLazyObjects(true))
+	result, err := d.Decode(data)
+	require.NoError(t, err)
+	obj, ok := result.(*LazyObject)
+	require.True(t, ok, "got %T", result)
+
+	assert.Equal(t, []string{"name", "age", "address"}, obj.Keys())
+	assert.Equal(t, 3, obj.Len())
+	assert.True(t, obj.Has("age"))
+	assert.False(t, obj.Has("email"))
+	assert.Empty(t, obj.values, "nothing is decoded before Get")
+
+	name, err := obj.Get("name")
+	require.NoError(t, err)
+	assert.Equal(t, "Grace", name, "the last value of a repeated key wins")
+	assert.Len(t, obj.values, 1)
+
+	_, err = obj.Get("email")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+
+	t.Run("nested objects", func(t *testing.T) {
+		address, err := ParseLazyObject(obj.Raw("address"))
+		require.NoError(t, err)
+		city, err := address.Get("city")
+		require.NoError(t, err)
+		assert.Equal(t, "London", city)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		m, err := obj.Map()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"name":    "Grace",
+			"age":     int64(36),
+			"address": map[string]any{"city": "London"},
+		}, m)
+	})
+
+	t.Run("concurrent gets", func(t *testing.T) {
+		obj, err := ParseLazyObject(data)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				age, err := obj.Get("age")
+				assert.NoError(t, err)
+				assert.Equal(t, int64(36), age)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestLazyObjectsUnmarshal(t *testing.T) {
+	data, err := Marshal(map[string]any{"name": "Ada", "age": 36})
+	require.NoError(t, err)
+	d := NewConfigurableDecoder(WithLazyObjects(true))
+
+	var lazy *LazyObject
+	require.NoError(t, d.Unmarshal(data, &lazy))
+	assert.ElementsMatch(t, []string{"name", "age"}, lazy.Keys())
+
+	var anything any
+	require.NoError(t, d.Unmarshal(data, &anything))
+	assert.IsType(t, &LazyObject{}, anything)
+
+	var user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	require.NoError(t, d.Unmarshal(data, &user))
+	assert.Equal(t, "Ada", user.Name)
+	assert.Equal(t, 36, user.Age)
+
+	var m map[string]any
+	require.NoError(t, d.Unmarshal(data, &m))
+	assert.Equal(t, "Ada", m["name"])
+}
+
+func TestLazyObjectsEdgeCases(t *testing.T) {
+	d := NewConfigurableDecoder(WithLazyObjects(true))
+
+	t.Run("null object", func(t *testing.T) {
+		result, err := d.Decode(append([]byte{Version}, rawObjectFields([]byte{TypeNull})...))
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("other values are decoded", func(t *testing.T) {
+		data, err := Marshal([]any{"a"})
+		require.NoError(t, err)
+		result, err := d.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"a"}, result)
+	})
+
+	t.Run("corrupt value fails on Get", func(t *testing.T) {
+		data := append([]byte{Version}, rawObject(
+			rawEntry("ok", "fine"),
+			[]byte{3, 'b', 'a', 'd', 0x99},
+		)...)
+		result, err := d.Decode(data)
+		require.NoError(t, err)
+		obj := result.(*LazyObject)
+
+		_, err = obj.Get("ok")
+		assert.NoError(t, err)
+		_, err = obj.Get("bad")
+		assert.Error(t, err)
+		_, err = obj.Map()
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid keys in strict mode", func(t *testing.T) {
+		data := append([]byte{Version}, rawObject(rawEntry("\xff", "x"))...)
+		strict := NewConfigurableDecoder(WithLazyObjects(true), WithDecoderStrictMode(true))
+		_, err := strict.Decode(data)
+		assert.Error(t, err)
+	})
+}
@@ -19,7 +19,7 @@ func encodeList(list any) ([]byte, error) {
 		elem := v.Index(i)
 		data, err := encode(elem.Interface())
 		if err != nil {
-			return nil, wrapError(arrEncErr, "error encoding element in list", err.Error())
+			return nil, fmt.Errorf("%w: error encoding element in list: %w", arrEncErr, err)
 		}
 		buf.Write(data)
 	}
@@ -37,3 +37,80 @@ func TestListEncoding(t *testing.T) {
 
 	assert.Equal(t, expected[7], actual[7])
 }
+
+func TestPointerLists(t *testing.T) {
+	n, s := 5, "s"
+	pn := &n
+	r := reading{Sensor: "a", Value: 1.5, Ok: true}
+	pr := &r
+
+	tests := []struct {
+		name   string
+		values any
+		ptrs   any
+	}{
+		{"ints", []int{5, 5}, []*int{&n, pn}},
+		{"strings", []string{"s"}, []*string{&s}},
+		{"nested pointers", []int{5}, []**int{&pn}},
+		{"structs", []reading{r, r}, []*reading{pr, pr}},
+		{"arrays", []int{5, 5}, [2]*int{pn, pn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, e := range []*Encoder{
+				NewConfigurableEncoder(WithCanonicalOrder(CanonicalDeclaration)),
+				NewConfigurableEncoder(WithCanonicalOrder(CanonicalDeclaration), WithObjectLists(true)),
+			} {
+				want, err := e.Encode(tt.values)
+				require.NoError(t, err)
+				got, err := e.Encode(tt.ptrs)
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+
+	t.Run("decode back into pointers", func(t *testing.T) {
+		data, err := NewConfigurableEncoder(WithObjectLists(true)).Encode([]*reading{pr, pr})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeObjectList), data[1])
+		var readings []*reading
+		require.NoError(t, Unmarshal(data, &readings))
+		assert.Equal(t, []*reading{pr, pr}, readings)
+
+		data, err = Marshal([]**int{&pn})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeTypedList), data[1])
+		var ints []**int
+		require.NoError(t, Unmarshal(data, &ints))
+		require.Len(t, ints, 1)
+		assert.Equal(t, 5, **ints[0])
+	})
+
+	t.Run("byte and rune pointers stay numbers", func(t *testing.T) {
+		b, r := byte(1), 'x'
+		data, err := Marshal([]*byte{&b})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), data[1])
+		data, err = NewConfigurableEncoder(WithRunesAsStrings(true)).Encode([]*rune{&r})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), data[1])
+	})
+
+	t.Run("nil pointers keep the untyped layout", func(t *testing.T) {
+		data, err := Marshal([]*int{pn, nil})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), data[1])
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{int64(5), nil}, decoded)
+	})
+
+	t.Run("pointers encoded in their own right", func(t *testing.T) {
+		data, err := Marshal([]*rect{{W: 1, H: 2}})
+		require.NoError(t, err)
+		var shapes []shape
+		require.NoError(t, Unmarshal(data, &shapes))
+		assert.Equal(t, []shape{&rect{W: 1, H: 2}}, shapes)
+	})
+}
@@ -0,0 +1,120 @@
+package bogo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// DecodeListInto decodes the list document data one element at a time,
+// storing each in a T as Unmarshal does and passing it to fn, so a large list
+// can be processed without allocating the whole []T. Iteration stops at the
+// first error, which is returned; an error returned by fn is returned as is.
+// The options configure the decoder.
+//
+// Elements of lists and chunked lists are decoded as fn asks for them. Typed
+// lists and object lists, whose elements are not complete values, are
+// decoded in full before the first call.
+func DecodeListInto[T any](data []byte, fn func(elem T) error, options ...DecoderOption) error {
+	d := NewConfigurableDecoder(options...)
+	data, err := d.prepare(data)
+	if err != nil {
+		return err
+	}
+
+	index := 0
+	each := func(v any) error {
+		var elem T
+		if err := assignResultWith(v, &elem, d); err != nil {
+			return fmt.Errorf("bogo: element %d: %w", index, err)
+		}
+		index++
+		return fn(elem)
+	}
+	eachValue := func(value []byte) error {
+		if d.TypedValues {
+			v, err := decodeTypedValue(value, stringMaker{symbols: d.symbols})
+			if err != nil {
+				return err
+			}
+			return each(v)
+		}
+		d.depth = 1
+		v, err := d.decode(value)
+		if err != nil {
+			return d.deadlineErr(err)
+		}
+		return each(v)
+	}
+
+	value := data[1:]
+	if Type(value[0]) == TypeSymbols {
+		// Elements refer to the symbols of the document
+		symbols, offset, err := readSymbols(value[1:], d.strings())
+		if err != nil {
+			return err
+		}
+		d.symbols, value = symbols, value[1+offset:]
+	}
+
+	switch Type(value[0]) {
+	case TypeUntypedList:
+		elements, err := containerPayload(value[1:])
+		if err != nil {
+			return wrapError(arrDecErr, err.Error())
+		}
+		for pos := 0; pos < len(elements); {
+			size, err := getElementSize(elements[pos:])
+			if err != nil || size <= 0 || size > len(elements)-pos {
+				return wrapError(arrDecErr, fmt.Sprintf("malformed element %d", index))
+			}
+			if err := eachValue(elements[pos : pos+size]); err != nil {
+				return err
+			}
+			pos += size
+		}
+		return nil
+	case TypeChunkedList:
+		var fnErr error
+		err := walkChunkValues(value[1:], func(value []byte) error {
+			fnErr = eachValue(value)
+			return fnErr
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+		if err != nil {
+			return wrapError(chunkErr, err.Error())
+		}
+		return nil
+	case TypeTypedList, TypeObjectList:
+		list, err := d.decodePrepared(data)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(list)
+		for i := 0; i < rv.Len(); i++ {
+			if err := each(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return wrapError(arrDecErr, fmt.Sprintf("document holds %s, not a list", TypeName(Type(value[0]))))
+}
+
+// DecodeListChan sends the elements of the list document data to ch one at a
+// time, decoding each as DecodeListInto does. Sends block until ch is
+// received from, so a slow consumer holds back decoding. It returns once the
+// last element is sent, without closing ch, or with the context's error when
+// ctx is done first.
+func DecodeListChan[T any](ctx context.Context, data []byte, ch chan<- T, options ...DecoderOption) error {
+	return DecodeListInto(data, func(elem T) error {
+		select {
+		case ch <- elem:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, options...)
+}
@@ -0,0 +1,91 @@
+package bogo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeListInto(t *testing.T) {
+	readings := []reading{{Sensor: "a", Value: 1}, {Sensor: "b", Value: 2, Ok: true}, {Sensor: "c", Value: 3}}
+	var chunked countingWriter
+	require.NoError(t, EncodeSeq(&chunked, slices.Values(readings)))
+	objectList, err := NewConfigurableEncoder(WithObjectLists(true)).Encode(readings)
+	require.NoError(t, err)
+
+	documents := map[string][]byte{
+		"list":         mustMarshal(t, readings),
+		"chunked list": chunked.Bytes(),
+		"object list":  objectList,
+	}
+	for name, data := range documents {
+		t.Run(name, func(t *testing.T) {
+			var got []reading
+			require.NoError(t, DecodeListInto(data, func(r reading) error {
+				got = append(got, r)
+				return nil
+			}))
+			assert.Equal(t, readings, got)
+		})
+	}
+
+	t.Run("typed list", func(t *testing.T) {
+		var sum int
+		require.NoError(t, DecodeListInto(mustMarshal(t, []int64{1, 2, 3}), func(n int) error {
+			sum += n
+			return nil
+		}))
+		assert.Equal(t, 6, sum)
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := DecodeListInto(documents["list"], func(reading) error {
+			calls++
+			return stop
+		})
+		assert.Same(t, stop, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("reports the element that does not fit", func(t *testing.T) {
+		err := DecodeListInto(mustMarshal(t, []any{1, "two"}), func(int) error { return nil })
+		assert.ErrorContains(t, err, "element 1")
+	})
+
+	t.Run("rejects other documents", func(t *testing.T) {
+		err := DecodeListInto(mustMarshal(t, "text"), func(any) error { return nil })
+		assert.ErrorContains(t, err, "not a list")
+	})
+
+	t.Run("applies the options", func(t *testing.T) {
+		future := append([]byte{Version + 1}, documents["list"][1:]...)
+		err := DecodeListInto(future, func(any) error { return nil }, WithDecoderStrictMode(true))
+		var mismatch *ErrVersionMismatch
+		assert.ErrorAs(t, err, &mismatch)
+	})
+}
+
+func TestDecodeListChan(t *testing.T) {
+	data := mustMarshal(t, []string{"a", "b", "c"})
+
+	ch := make(chan string)
+	done := make(chan error, 1)
+	go func() { done <- DecodeListChan(context.Background(), data, ch) }()
+	var got []string
+	for range 3 {
+		got = append(got, <-ch)
+	}
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := DecodeListChan(ctx, data, make(chan string))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,82 @@
+package bogo
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LocalizedString is a string value tagged with its language, such as "en" or
+// "pt-BR". It is encoded as an object with a single entry keyed by the language
+// tag, which is the same layout as LocalizedText, so a LocalizedString field can
+// be decoded from a multi-locale object by picking the decoder's preferred locale.
+type LocalizedString struct {
+	Lang  string
+	Value string
+}
+
+func (s LocalizedString) String() string {
+	return s.Value
+}
+
+// LocalizedText holds the translations of a string keyed by language tag
+type LocalizedText map[string]string
+
+// Get returns the translation for the first matching locale. Each locale is
+// matched exactly and then by base language, so "en-GB" matches "en" and
+// "en" matches "en-US". If nothing matches, the translation with the smallest
+// tag is returned so results are deterministic.
+func (t LocalizedText) Get(locales ...string) (LocalizedString, bool) {
+	if len(t) == 0 {
+		return LocalizedString{}, false
+	}
+
+	tags := make([]string, 0, len(t))
+	for tag := range t {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, locale := range locales {
+		if value, ok := t[locale]; ok {
+			return LocalizedString{Lang: locale, Value: value}, true
+		}
+		base := baseLanguage(locale)
+		for _, tag := range tags {
+			if strings.EqualFold(baseLanguage(tag), base) {
+				return LocalizedString{Lang: tag, Value: t[tag]}, true
+			}
+		}
+	}
+
+	return LocalizedString{Lang: tags[0], Value: t[tags[0]]}, true
+}
+
+// baseLanguage returns the primary language subtag of a BCP 47 tag
+func baseLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+var localizedStringType = reflect.TypeOf(LocalizedString{})
+
+// localizedStringFromValue selects a LocalizedString from a decoded locale object
+func localizedStringFromValue(value any, locales []string) (LocalizedString, bool) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return LocalizedString{}, false
+	}
+
+	text := make(LocalizedText, len(obj))
+	for tag, v := range obj {
+		str, ok := v.(string)
+		if !ok {
+			return LocalizedString{}, false
+		}
+		text[tag] = str
+	}
+
+	return text.Get(locales...)
+}
@@ -0,0 +1,61 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizedString(t *testing.T) {
+	type Product struct {
+		SKU   string          `json:"sku"`
+		Title LocalizedString `json:"title"`
+		Blurb LocalizedText   `json:"blurb"`
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		product := Product{
+			SKU:   "A-1",
+			Title: LocalizedString{Lang: "fr", Value: "Chaise"},
+			Blurb: LocalizedText{"en": "A chair", "fr": "Une chaise"},
+		}
+		data, err := Marshal(product)
+		require.NoError(t, err)
+
+		var decoded Product
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, product, decoded)
+	})
+
+	t.Run("selects preferred locale from translations", func(t *testing.T) {
+		data, err := Marshal(map[string]any{
+			"sku":   "A-1",
+			"title": LocalizedText{"en-US": "Chair", "de": "Stuhl", "pt-BR": "Cadeira"},
+		})
+		require.NoError(t, err)
+
+		decoder := NewConfigurableDecoder(WithLocales("pt-PT", "de"))
+		result, err := decoder.Decode(data)
+		require.NoError(t, err)
+
+		var decoded Product
+		require.NoError(t, assignResultWith(result, &decoded, decoder))
+		assert.Equal(t, LocalizedString{Lang: "pt-BR", Value: "Cadeira"}, decoded.Title)
+	})
+
+	t.Run("text lookup", func(t *testing.T) {
+		text := LocalizedText{"en": "Hello", "es": "Hola"}
+
+		got, ok := text.Get("es-MX")
+		assert.True(t, ok)
+		assert.Equal(t, "Hola", got.Value)
+
+		got, ok = text.Get("ja")
+		assert.True(t, ok)
+		assert.Equal(t, "en", got.Lang)
+
+		_, ok = LocalizedText{}.Get("en")
+		assert.False(t, ok)
+	})
+}
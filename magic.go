@@ -0,0 +1,105 @@
+package bogo
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Magic optionally precedes the version byte so that bogo documents can be
+// told apart from other binary data. Documents without it remain valid.
+var Magic = [4]byte{'B', 'G', 'O', '1'}
+
+// ErrMissingMagic is returned by decoders created with WithRequireMagic for
+// documents that do not start with Magic
+var ErrMissingMagic = errors.New("bogo decode error: missing magic prefix")
+
+// WithMagicPrefix makes the encoder start every document with Magic
+func WithMagicPrefix(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.MagicPrefix = enabled
+	}
+}
+
+// WithRequireMagic makes the decoder reject documents that start neither with
+// Magic nor with EnvelopeMagic, instead of reading them as legacy documents
+func WithRequireMagic(required bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RequireMagic = required
+	}
+}
+
+// IsBogo reports whether data identifies itself as bogo, by starting with
+// Magic or with the EnvelopeMagic of an enveloped document. Legacy documents,
+// which start with the version byte alone, cannot be recognized reliably and
+// are reported as false.
+func IsBogo(data []byte) bool {
+	return hasMagic(data) || bytes.HasPrefix(data, EnvelopeMagic[:])
+}
+
+func hasMagic(data []byte) bool {
+	return bytes.HasPrefix(data, Magic[:])
+}
+
+// stripMagic removes the magic prefix of data, if present
+func stripMagic(data []byte) []byte {
+	if hasMagic(data) {
+		return data[len(Magic):]
+	}
+	return data
+}
+
+// openDocument returns the plain document held in data, which is a legacy
+// document, a document starting with Magic, or an enveloped document, and
+// whether data identified itself with either prefix. Every entry point reads
+// documents through it, or through negotiate for decoders.
+func openDocument(data []byte) ([]byte, bool, error) {
+	switch {
+	case hasMagic(data):
+		return data[len(Magic):], true, nil
+	case bytes.HasPrefix(data, EnvelopeMagic[:]):
+		_, payload, err := OpenEnvelope(data)
+		if err != nil {
+			return nil, true, err
+		}
+		return stripMagic(payload), true, nil
+	}
+	return data, false, nil
+}
+
+// negotiate returns the plain document held in data as openDocument does,
+// rejecting legacy documents when the decoder requires Magic
+func (d *Decoder) negotiate(data []byte) ([]byte, error) {
+	doc, prefixed, err := openDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if !prefixed && d.RequireMagic {
+		return nil, ErrMissingMagic
+	}
+	return doc, nil
+}
+
+// editDocument applies edit to the plain document held in data and frames the
+// result as data was: behind Magic, in an envelope with the same header, or
+// as a legacy document
+func editDocument(data []byte, edit func(doc []byte) ([]byte, error)) ([]byte, error) {
+	switch {
+	case hasMagic(data):
+		out, err := edit(data[len(Magic):])
+		if err != nil {
+			return nil, err
+		}
+		return append(Magic[:], out...), nil
+	case bytes.HasPrefix(data, EnvelopeMagic[:]):
+		header, payload, err := OpenEnvelope(data)
+		if err != nil {
+			return nil, err
+		}
+		out, err := editDocument(payload, edit)
+		if err != nil {
+			return nil, err
+		}
+		return Envelop(out, header), nil
+	}
+	return edit(data)
+}
@@ -0,0 +1,134 @@
+package bogo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicPrefix(t *testing.T) {
+	e := NewConfigurableEncoder(WithMagicPrefix(true))
+	data, err := e.Encode(map[string]any{"name": "Ada"})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, append(Magic[:], Version)))
+	assert.True(t, IsBogo(data))
+
+	var v map[string]any
+	require.NoError(t, Unmarshal(data, &v))
+	assert.Equal(t, map[string]any{"name": "Ada"}, v)
+
+	name, err := FieldString(data, "name")
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", name)
+}
+
+func TestIsBogo(t *testing.T) {
+	legacy, err := Marshal("x")
+	require.NoError(t, err)
+	enveloped, err := EncodeEnveloped("x", EnvelopeHeader{})
+	require.NoError(t, err)
+
+	assert.False(t, IsBogo(legacy), "legacy documents carry no magic")
+	assert.True(t, IsBogo(enveloped))
+	assert.True(t, IsBogo(append(Magic[:], legacy...)))
+	assert.False(t, IsBogo([]byte("BGO")))
+	assert.False(t, IsBogo(nil))
+}
+
+func TestDecoderNegotiation(t *testing.T) {
+	legacy, err := Marshal("x")
+	require.NoError(t, err)
+	magic, err := NewConfigurableEncoder(WithMagicPrefix(true)).Encode("x")
+	require.NoError(t, err)
+	enveloped := Envelop(magic, EnvelopeHeader{SchemaID: "s"})
+
+	for name, data := range map[string][]byte{"legacy": legacy, "magic": magic, "enveloped": enveloped} {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewConfigurableDecoder().Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, "x", v)
+		})
+	}
+
+	t.Run("magic required", func(t *testing.T) {
+		d := NewConfigurableDecoder(WithRequireMagic(true))
+		_, err := d.Decode(legacy)
+		assert.ErrorIs(t, err, ErrMissingMagic)
+
+		for _, data := range [][]byte{magic, enveloped} {
+			v, err := d.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, "x", v)
+		}
+	})
+
+	t.Run("corrupt envelope", func(t *testing.T) {
+		_, err := NewConfigurableDecoder().Decode(enveloped[:len(enveloped)-1])
+		assert.ErrorIs(t, err, envelopeErr)
+	})
+}
+
+func TestEntryPointsNegotiate(t *testing.T) {
+	legacy, err := Marshal(map[string]any{"tags": []any{"a"}})
+	require.NoError(t, err)
+	magic := append(Magic[:], legacy...)
+	enveloped := Envelop(magic, EnvelopeHeader{SchemaID: "s"})
+
+	for name, data := range map[string][]byte{"legacy": legacy, "magic": magic, "enveloped": enveloped} {
+		t.Run(name, func(t *testing.T) {
+			v, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"tags": []any{"a"}}, v)
+
+			var scanned []any
+			d := NewConfigurableDecoder()
+			d.OnField("tags[]", func(v any) { scanned = append(scanned, v) })
+			require.NoError(t, d.Scan(data))
+			assert.Equal(t, []any{"a"}, scanned)
+
+			raw, err := Document(data).Get("tags[0]")
+			require.NoError(t, err)
+			assert.Equal(t, "a", mustDecodeValue(t, raw))
+
+			edited, err := Document(data).Set("tags[1]", "b")
+			require.NoError(t, err)
+			assert.Equal(t, IsBogo(data), IsBogo(edited), "edits keep the prefix")
+			appended, err := AppendToList(bytes.Clone(data), "tags", "c")
+			require.NoError(t, err)
+			footprint, err := Footprint(data)
+			require.NoError(t, err)
+			legacyFootprint, err := Footprint(legacy)
+			require.NoError(t, err)
+			assert.Equal(t, legacyFootprint, footprint)
+			repaired, fixes, err := Repair(data)
+			require.NoError(t, err)
+			assert.Empty(t, fixes)
+			assert.Equal(t, data, repaired)
+
+			for doc, want := range map[string][]any{
+				string(edited):   {"a", "b"},
+				string(appended): {"a", "c"},
+			} {
+				v, err := Decode([]byte(doc))
+				require.NoError(t, err)
+				assert.Equal(t, map[string]any{"tags": want}, v)
+			}
+		})
+	}
+
+	t.Run("repair offsets", func(t *testing.T) {
+		_, fixes, err := Repair(append(bytes.Clone(magic), 0))
+		require.NoError(t, err)
+		require.Len(t, fixes, 1)
+		assert.Equal(t, len(magic), fixes[0].Offset)
+	})
+}
+
+func mustDecodeValue(t *testing.T, raw []byte) any {
+	t.Helper()
+	v, err := decodeValue(raw)
+	require.NoError(t, err)
+	return v
+}
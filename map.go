@@ -0,0 +1,233 @@
+package bogo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var mapDecErr = errors.New("bogo map decode error")
+
+// A map with integer keys is laid out as
+//
+//	TypeMap | size prefix | key type(1) | key | value | key | value ...
+//
+// where the key type is TypeInt or TypeUint and keys and values are complete
+// encoded values. It decodes as map[int64]any or map[uint64]any. Maps with
+// string keys are objects.
+
+// isIntegerKey reports whether maps keyed by k are encoded as TypeMap
+func isIntegerKey(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// encodeIntegerKeyedMap encodes a map with integer keys, keeping their type
+func (e *Encoder) encodeIntegerKeyedMap(rv reflect.Value) ([]byte, error) {
+	if e.MaxDepth > 0 && e.depth >= e.MaxDepth {
+		return nil, fmt.Errorf("bogo encode error: maximum nesting depth exceeded (%d)", e.MaxDepth)
+	}
+	e.depth++
+	defer func() { e.depth-- }()
+
+	signed := reflect.Zero(rv.Type().Key()).CanInt()
+	keys := rv.MapKeys()
+	if e.CanonicalOrder != CanonicalOff {
+		sort.Slice(keys, func(i, j int) bool {
+			if signed {
+				return keys[i].Int() < keys[j].Int()
+			}
+			return keys[i].Uint() < keys[j].Uint()
+		})
+	}
+
+	buf := bytes.Buffer{}
+	if signed {
+		buf.WriteByte(TypeInt)
+	} else {
+		buf.WriteByte(TypeUint)
+	}
+	for _, key := range keys {
+		var segment string
+		var keyData []byte
+		if signed {
+			segment = strconv.FormatInt(key.Int(), 10)
+			keyData, _ = e.encodeInt(key.Int())
+		} else {
+			segment = strconv.FormatUint(key.Uint(), 10)
+			keyData, _ = e.encodeUint(key.Uint())
+		}
+		data, err := e.encode(rv.MapIndex(key).Interface())
+		if err != nil {
+			pathErr, skip := e.childError(err, segment)
+			if skip {
+				continue
+			}
+			if pathErr != nil {
+				return nil, pathErr
+			}
+			return nil, fmt.Errorf("bogo encode error: failed to encode map entry %s: %w", segment, err)
+		}
+		buf.Write(keyData)
+		buf.Write(data)
+		if e.outputExceeded(buf.Len()) {
+			return nil, e.outputSizeError(len(data), segment)
+		}
+	}
+
+	return buildContainer(TypeMap, buf.Bytes())
+}
+
+func decodeMapWith(data []byte, strs stringMaker) (any, error) {
+	return decodeMapValues(data, strs, func(value []byte) (any, error) {
+		return decodeValueWith(value, strs)
+	})
+}
+
+// decodeMapValues decodes a map as decodeMapWith does, decoding its values
+// with decode
+func decodeMapValues(data []byte, strs stringMaker, decode func(value []byte) (any, error)) (any, error) {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return nil, wrapError(mapDecErr, err.Error())
+	}
+	if len(payload) == 0 {
+		return nil, wrapError(mapDecErr, "missing key type")
+	}
+
+	keyType, entries := Type(payload[0]), payload[1:]
+	var signed map[int64]any
+	var unsigned map[uint64]any
+	switch keyType {
+	case TypeInt:
+		signed = make(map[int64]any)
+	case TypeUint:
+		unsigned = make(map[uint64]any)
+	default:
+		return nil, wrapError(mapDecErr, fmt.Sprintf("unsupported key type %s", TypeName(keyType)))
+	}
+
+	// Keys are plain integers even when values are decoded as Number
+	keyStrs := strs
+	keyStrs.numbers = false
+	for pos := 0; pos < len(entries); {
+		key, n, err := decodeMapElement(entries[pos:], func(key []byte) (any, error) {
+			return decodeValueWith(key, keyStrs)
+		})
+		if err != nil {
+			return nil, wrapError(mapDecErr, "failed to decode key", err.Error())
+		}
+		pos += n
+		if pos >= len(entries) {
+			return nil, wrapError(mapDecErr, "missing value")
+		}
+		value, n, err := decodeMapElement(entries[pos:], decode)
+		if err != nil {
+			return nil, wrapError(mapDecErr, "failed to decode value", err.Error())
+		}
+		pos += n
+
+		switch k := key.(type) {
+		case int64:
+			if signed == nil {
+				return nil, wrapError(mapDecErr, fmt.Sprintf("int key in a map with %s keys", TypeName(keyType)))
+			}
+			signed[k] = value
+		case uint64:
+			if unsigned == nil {
+				return nil, wrapError(mapDecErr, fmt.Sprintf("uint key in a map with %s keys", TypeName(keyType)))
+			}
+			unsigned[k] = value
+		default:
+			return nil, wrapError(mapDecErr, fmt.Sprintf("invalid key of type %T", key))
+		}
+	}
+
+	if signed != nil {
+		return signed, nil
+	}
+	return unsigned, nil
+}
+
+// decodeMapElement decodes the key or value at the start of data, returning
+// the number of bytes it used
+func decodeMapElement(data []byte, decode func([]byte) (any, error)) (any, int, error) {
+	size, err := getElementSize(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if size > len(data) {
+		return nil, 0, errors.New("insufficient data")
+	}
+	value, err := decode(data[:size])
+	return value, size, err
+}
+
+// convertKeyedMap converts a decoded map into a map of another type,
+// converting keys and values. String keys are parsed for integer key types,
+// so maps encoded as objects before TypeMap existed decode too.
+func convertKeyedMap(source reflect.Value, target reflect.Value, d *Decoder) error {
+	targetType := target.Type()
+	newMap := reflect.MakeMapWithSize(targetType, source.Len())
+	var errs DecodeErrors
+
+	iter := source.MapRange()
+	for iter.Next() {
+		segment := fmt.Sprint(iter.Key().Interface())
+		key := reflect.New(targetType.Key()).Elem()
+		err := assignMapKey(iter.Key().Interface(), key, d)
+		if err == nil {
+			value := reflect.New(targetType.Elem()).Elem()
+			if err = assignValueToField(iter.Value().Interface(), value, d); err == nil {
+				newMap.SetMapIndex(key, value)
+				continue
+			}
+		}
+		if d.CollectErrors {
+			errs = collectErrors(errs, err, segment)
+			continue
+		}
+		if errors.As(err, new(*ValidationError)) {
+			return prefixPath(err, segment)
+		}
+		return fmt.Errorf("failed to convert map entry %s: %w", segment, err)
+	}
+
+	target.Set(newMap)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// assignMapKey stores a decoded map key in key
+func assignMapKey(value any, key reflect.Value, d *Decoder) error {
+	s, ok := value.(string)
+	if !ok || key.Kind() == reflect.String {
+		return assignValueToField(value, key, d)
+	}
+	switch {
+	case key.CanInt():
+		n, err := strconv.ParseInt(s, 10, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s map key %q", key.Type(), s)
+		}
+		key.SetInt(n)
+		return nil
+	case key.CanUint():
+		n, err := strconv.ParseUint(s, 10, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s map key %q", key.Type(), s)
+		}
+		key.SetUint(n)
+		return nil
+	}
+	return assignValueToField(value, key, d)
+}
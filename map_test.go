@@ -0,0 +1,159 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shardID uint16
+
+type routingTable struct {
+	Owners  map[int64]string      `json:"owners"`
+	Weights map[shardID]float64   `json:"weights"`
+	Nested  map[int32]map[int]int `json:"nested"`
+	Names   map[string]int        `json:"names"`
+}
+
+func TestIntegerKeyedMaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		decoded any
+	}{
+		{"int64 keys", map[int64]string{-1: "a", math.MaxInt64: "b"}, map[int64]any{-1: "a", math.MaxInt64: "b"}},
+		{"int keys", map[int]bool{0: true}, map[int64]any{0: true}},
+		{"uint64 keys", map[uint64]string{math.MaxUint64: "max"}, map[uint64]any{math.MaxUint64: "max"}},
+		{"uint8 keys", map[uint8]int{255: 1}, map[uint64]any{255: int64(1)}},
+		{"named keys", map[shardID]string{7: "x"}, map[uint64]any{7: "x"}},
+		{"empty", map[int32]string{}, map[int64]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, byte(TypeMap), data[1])
+
+			decoded, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.decoded, decoded)
+
+			decoded, err = NewConfigurableDecoder().Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.decoded, decoded)
+		})
+	}
+}
+
+func TestIntegerKeyedMapUnmarshal(t *testing.T) {
+	table := routingTable{
+		Owners:  map[int64]string{1: "alpha", -2: "beta"},
+		Weights: map[shardID]float64{3: 0.5},
+		Nested:  map[int32]map[int]int{4: {5: 6}},
+		Names:   map[string]int{"x": 1},
+	}
+
+	data, err := Marshal(table)
+	require.NoError(t, err)
+
+	var decoded routingTable
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, table, decoded)
+
+	var owners map[int16]string
+	data, err = Marshal(table.Owners)
+	require.NoError(t, err)
+	require.NoError(t, Unmarshal(data, &owners))
+	assert.Equal(t, map[int16]string{1: "alpha", -2: "beta"}, owners)
+}
+
+func TestIntegerKeyedMapUseNumber(t *testing.T) {
+	data, err := Marshal(map[int64]int64{-1: 10, 2: 20})
+	require.NoError(t, err)
+
+	d := NewConfigurableDecoder(WithUseNumber(true))
+	decoded, err := d.Decode(data)
+	require.NoError(t, err)
+	require.IsType(t, map[int64]any{}, decoded)
+	value := decoded.(map[int64]any)[-1]
+	require.IsType(t, Number{}, value)
+	n, err := value.(Number).Int64()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+
+	var typed map[int]string
+	data, err = Marshal(map[int]string{1: "a", 2: "b"})
+	require.NoError(t, err)
+	require.NoError(t, d.Unmarshal(data, &typed))
+	assert.Equal(t, map[int]string{1: "a", 2: "b"}, typed)
+}
+
+func TestIntegerKeyedMapOverflow(t *testing.T) {
+	data, err := Marshal(map[int64]string{1000: "big"})
+	require.NoError(t, err)
+
+	var small map[int8]string
+	assert.Error(t, Unmarshal(data, &small))
+}
+
+func TestStringMapKeys(t *testing.T) {
+	encoder := NewConfigurableEncoder(WithStringMapKeys(true))
+	data, err := encoder.Encode(map[int64]string{-1: "a", 2: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, byte(TypeObject), data[1])
+
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"-1": "a", "2": "b"}, decoded)
+
+	// Objects written this way still decode into integer keyed maps
+	var m map[int64]string
+	require.NoError(t, Unmarshal(data, &m))
+	assert.Equal(t, map[int64]string{-1: "a", 2: "b"}, m)
+
+	var u map[uint32]string
+	assert.Error(t, Unmarshal(data, &u))
+}
+
+func TestIntegerKeyedMapCanonical(t *testing.T) {
+	encoder := NewConfigurableEncoder(WithCanonicalOrder(CanonicalLexical))
+	m := map[int]string{10: "a", -3: "b", 2: "c", 0: "d"}
+	first, err := encoder.Encode(m)
+	require.NoError(t, err)
+	for range 10 {
+		data, err := encoder.Encode(m)
+		require.NoError(t, err)
+		assert.Equal(t, first, data)
+	}
+}
+
+func TestIntegerKeyedMapUnsupportedValues(t *testing.T) {
+	_, err := Marshal(map[int]any{3: func() {}})
+	var unsupported *UnsupportedTypeError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "3", unsupported.Path)
+
+	encoder := NewConfigurableEncoder(WithUnsupportedKinds(UnsupportedKindSkip))
+	data, err := encoder.Encode(map[int]any{3: func() {}, 4: "kept"})
+	require.NoError(t, err)
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]any{4: "kept"}, decoded)
+}
+
+func TestIntegerKeyedMapMalformed(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"missing key type": {Version, TypeMap, 1, 0},
+		"string key type":  {Version, TypeMap, 1, 1, TypeString},
+		"missing value":    {Version, TypeMap, 1, 4, TypeInt, TypeInt, 1, 2},
+		"mismatched key":   {Version, TypeMap, 1, 6, TypeInt, TypeUint, 1, 2, TypeBoolTrue, TypeNull},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(data)
+			assert.ErrorIs(t, err, mapDecErr)
+		})
+	}
+}
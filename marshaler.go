@@ -0,0 +1,136 @@
+package bogo
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Marshaler is implemented by types that control their own encoding, such as
+// UUIDs, money amounts or enums. MarshalBogo returns a complete document, as
+// Marshal does, whose value is used in place of the receiver:
+//
+//	func (id UUID) MarshalBogo() ([]byte, error) {
+//		return bogo.Marshal(id.String())
+//	}
+//
+// Encoders check for Marshaler before falling back to reflection, so it does
+// not apply to the types bogo encodes natively. Values are checked, not their
+// addresses, so implement it with a value receiver.
+//
+// Types without MarshalBogo that implement encoding.TextMarshaler encode as
+// the string it returns, and failing that, types implementing
+// encoding.BinaryMarshaler encode as a blob. This covers types such as
+// net.IP and netip.Addr without reflecting into their fields.
+type Marshaler interface {
+	MarshalBogo() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves. UnmarshalBogo
+// receives a complete document holding the encoded value and must copy it if
+// it keeps it. A destination passed to Unmarshal receives the document as
+// given; values nested in a document are re-encoded from their decoded form,
+// so a timestamp arrives as Unix milliseconds. Null leaves the destination at
+// its zero value without calling UnmarshalBogo.
+//
+// Destinations without UnmarshalBogo receive strings through
+// encoding.TextUnmarshaler and blobs through encoding.BinaryUnmarshaler.
+type Unmarshaler interface {
+	UnmarshalBogo(data []byte) error
+}
+
+var (
+	timeType              = reflect.TypeFor[time.Time]()
+	marshalerType         = reflect.TypeFor[Marshaler]()
+	unmarshalerType       = reflect.TypeFor[Unmarshaler]()
+	textUnmarshalerType   = reflect.TypeFor[encoding.TextUnmarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// encodeCustom encodes v with the first of Marshaler, encoding.TextMarshaler
+// and encoding.BinaryMarshaler it implements, reporting whether it
+// implements any
+func encodeCustom(v any) ([]byte, bool, error) {
+	if m, ok := v.(Marshaler); ok {
+		data, err := encodeMarshaler(m)
+		return data, true, err
+	}
+	// Pointers are dereferenced first, so that native types such as
+	// *time.Time keep their encoding
+	if reflect.TypeOf(v).Kind() == reflect.Pointer {
+		return nil, false, nil
+	}
+	switch m := v.(type) {
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		if err != nil {
+			return nil, true, fmt.Errorf("bogo encode error: %T.MarshalText: %w", m, err)
+		}
+		data, err := encodeString(string(text))
+		return data, true, err
+	case encoding.BinaryMarshaler:
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return nil, true, fmt.Errorf("bogo encode error: %T.MarshalBinary: %w", m, err)
+		}
+		data, err := encodeBlob(b)
+		return data, true, err
+	}
+	return nil, false, nil
+}
+
+// encodeMarshaler returns the value encoded by m.MarshalBogo, checking that
+// it is a single well-formed value
+func encodeMarshaler(m Marshaler) ([]byte, error) {
+	data, err := m.MarshalBogo()
+	if err != nil {
+		return nil, fmt.Errorf("bogo encode error: %T.MarshalBogo: %w", m, err)
+	}
+	data = stripMagic(data)
+	if len(data) < 2 || data[0] != Version {
+		return nil, fmt.Errorf("bogo encode error: %T.MarshalBogo returned no document", m)
+	}
+	value := data[1:]
+	if size, err := getElementSize(value); err != nil || size != len(value) {
+		return nil, fmt.Errorf("bogo encode error: %T.MarshalBogo returned a malformed document", m)
+	}
+	return value, nil
+}
+
+// assignUnmarshaler passes a decoded value to dest when its address
+// implements Unmarshaler, or a decoded string or blob when it implements
+// encoding.TextUnmarshaler or encoding.BinaryUnmarshaler, reporting whether
+// it was passed
+func assignUnmarshaler(value any, dest reflect.Value) (bool, error) {
+	// Values of the destination's own type, such as captured RawValues, are
+	// assigned as they are
+	if !dest.CanAddr() || reflect.TypeOf(value) == dest.Type() {
+		return false, nil
+	}
+	ptr := reflect.PointerTo(dest.Type())
+	switch {
+	case dest.Type() == timeType || dest.Type() == bigIntType || dest.Type() == bigFloatType:
+		// Decoded natively, honouring the decoder's time format
+		return false, nil
+	case ptr.Implements(unmarshalerType):
+		e := *defaultEncoder
+		data, err := e.Encode(value)
+		if err != nil {
+			return true, fmt.Errorf("cannot re-encode %T for %s: %w", value, dest.Type(), err)
+		}
+		return true, dest.Addr().Interface().(Unmarshaler).UnmarshalBogo(data)
+	case ptr.Implements(textUnmarshalerType):
+		if s, ok := value.(string); ok {
+			return true, dest.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+		// Decimals reach decimal types of other packages as text
+		if d, ok := value.(Decimal); ok {
+			return true, dest.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(d.String()))
+		}
+	}
+	if b, ok := value.([]byte); ok && ptr.Implements(binaryUnmarshalerType) {
+		return true, dest.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+	}
+	return false, nil
+}
@@ -0,0 +1,232 @@
+package bogo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uuid encodes as its canonical string instead of a list of 16 bytes
+type uuid [16]byte
+
+func (u uuid) MarshalBogo() ([]byte, error) {
+	return Marshal(fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]))
+}
+
+func (u *uuid) UnmarshalBogo(data []byte) error {
+	var s string
+	if err := Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != len(u) {
+		return fmt.Errorf("invalid uuid %q", s)
+	}
+	copy(u[:], b)
+	return nil
+}
+
+// cents encodes a money amount as an object of units and currency
+type cents struct {
+	amount   int64
+	currency string
+}
+
+func (c cents) MarshalBogo() ([]byte, error) {
+	return Marshal(map[string]any{"units": c.amount, "currency": c.currency})
+}
+
+func (c *cents) UnmarshalBogo(data []byte) error {
+	var v struct {
+		Units    int64  `json:"units"`
+		Currency string `json:"currency"`
+	}
+	if err := Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = cents{amount: v.Units, currency: v.Currency}
+	return nil
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalBogo() ([]byte, error) { return nil, errors.New("boom") }
+
+type rawMarshaler []byte
+
+func (r rawMarshaler) MarshalBogo() ([]byte, error) { return r, nil }
+
+type invoice struct {
+	ID     uuid            `json:"id"`
+	Total  cents           `json:"total"`
+	Lines  []cents         `json:"lines"`
+	ByCode map[string]uuid `json:"by_code"`
+	Ref    *uuid           `json:"ref"`
+}
+
+func TestMarshaler(t *testing.T) {
+	id := uuid{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	data, err := Marshal(id)
+	require.NoError(t, err)
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678-9abc-def0-0102-030405060708", decoded)
+
+	var back uuid
+	require.NoError(t, Unmarshal(data, &back))
+	assert.Equal(t, id, back)
+
+	data, err = NewConfigurableEncoder(WithMagicPrefix(true)).Encode(map[string]any{"id": id})
+	require.NoError(t, err)
+	decoded, err = NewConfigurableDecoder().Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "12345678-9abc-def0-0102-030405060708"}, decoded)
+}
+
+func TestMarshalerFields(t *testing.T) {
+	id := uuid{1}
+	ref := uuid{2}
+	inv := invoice{
+		ID:     id,
+		Total:  cents{amount: 1250, currency: "EUR"},
+		Lines:  []cents{{amount: 1000, currency: "EUR"}, {amount: 250, currency: "EUR"}},
+		ByCode: map[string]uuid{"a": {3}},
+		Ref:    &ref,
+	}
+
+	data, err := Marshal(inv)
+	require.NoError(t, err)
+
+	var generic map[string]any
+	require.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, map[string]any{"units": int64(1250), "currency": "EUR"}, generic["total"])
+
+	var decoded invoice
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, inv, decoded)
+
+	data, err = Marshal(invoice{})
+	require.NoError(t, err)
+	decoded = invoice{Ref: &ref}
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Nil(t, decoded.Ref)
+}
+
+func TestMarshalerErrors(t *testing.T) {
+	_, err := Marshal(map[string]any{"x": failingMarshaler{}})
+	assert.ErrorContains(t, err, "MarshalBogo: boom")
+
+	for name, raw := range map[string]rawMarshaler{
+		"empty":          {},
+		"no version":     {TypeString, 1, 1, 'a'},
+		"truncated":      {Version, TypeString, 1, 5, 'a'},
+		"trailing bytes": {Version, TypeNull, TypeNull},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Marshal(map[string]any{"raw": raw})
+			assert.ErrorContains(t, err, "rawMarshaler.MarshalBogo")
+		})
+	}
+
+	data, err := Marshal(map[string]any{"id": "not a uuid"})
+	require.NoError(t, err)
+	var v struct {
+		ID uuid `json:"id"`
+	}
+	assert.ErrorContains(t, Unmarshal(data, &v), `invalid uuid "not a uuid"`)
+}
+
+// version implements only encoding.BinaryMarshaler
+type version struct{ major, minor byte }
+
+func (v version) MarshalBinary() ([]byte, error) { return []byte{v.major, v.minor}, nil }
+
+func (v *version) UnmarshalBinary(b []byte) error {
+	if len(b) != 2 {
+		return fmt.Errorf("invalid version %x", b)
+	}
+	*v = version{b[0], b[1]}
+	return nil
+}
+
+type failingTextMarshaler struct{}
+
+func (failingTextMarshaler) MarshalText() ([]byte, error) { return nil, errors.New("boom") }
+
+func TestTextAndBinaryMarshalers(t *testing.T) {
+	type host struct {
+		IP      net.IP      `json:"ip"`
+		Addr    netip.Addr  `json:"addr"`
+		Version version     `json:"version"`
+		Seen    time.Time   `json:"seen"`
+		Peers   []net.IP    `json:"peers"`
+		Gateway *netip.Addr `json:"gateway"`
+	}
+	gateway := netip.MustParseAddr("10.0.0.1")
+	h := host{
+		IP:      net.ParseIP("192.0.2.1"),
+		Addr:    netip.MustParseAddr("2001:db8::1"),
+		Version: version{1, 4},
+		Seen:    time.UnixMilli(1700000000000),
+		Peers:   []net.IP{net.ParseIP("192.0.2.2"), net.ParseIP("192.0.2.3")},
+		Gateway: &gateway,
+	}
+
+	data, err := Marshal(h)
+	require.NoError(t, err)
+
+	generic, err := Decode(data)
+	require.NoError(t, err)
+	m := generic.(map[string]any)
+	assert.Equal(t, "192.0.2.1", m["ip"])
+	assert.Equal(t, "2001:db8::1", m["addr"])
+	assert.Equal(t, []byte{1, 4}, m["version"])
+	assert.Equal(t, "10.0.0.1", m["gateway"])
+
+	var decoded host
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.True(t, h.IP.Equal(decoded.IP))
+	assert.Equal(t, h.Addr, decoded.Addr)
+	assert.Equal(t, h.Version, decoded.Version)
+	assert.True(t, h.Seen.Equal(decoded.Seen), "time.Time keeps its native encoding")
+	require.Len(t, decoded.Peers, 2)
+	assert.True(t, h.Peers[1].Equal(decoded.Peers[1]))
+	assert.Equal(t, h.Gateway, decoded.Gateway)
+
+	t.Run("top-level values", func(t *testing.T) {
+		data, err := Marshal(h.Addr)
+		require.NoError(t, err)
+		var addr netip.Addr
+		require.NoError(t, Unmarshal(data, &addr))
+		assert.Equal(t, h.Addr, addr)
+	})
+
+	t.Run("Marshaler takes precedence", func(t *testing.T) {
+		data, err := Marshal(uuid{1})
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "01000000-0000-0000-0000-000000000000", decoded)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Marshal(map[string]any{"x": failingTextMarshaler{}})
+		assert.ErrorContains(t, err, "MarshalText: boom")
+
+		data, err := Marshal(map[string]any{"addr": "not an address"})
+		require.NoError(t, err)
+		var v struct {
+			Addr netip.Addr `json:"addr"`
+		}
+		assert.Error(t, Unmarshal(data, &v))
+	})
+}
@@ -0,0 +1,466 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMemoryCeiling is returned when decoding a document would allocate more
+// than the decoder's MemoryCeiling
+var ErrMemoryCeiling = errors.New("bogo: memory ceiling exceeded")
+
+var footprintErr = errors.New("footprint error")
+
+// Sizes, in bytes, of the values a decode allocates on 64-bit platforms.
+// Values stored in an interface are boxed in their own allocation.
+const (
+	wordSize       = 8   // boxed int64, uint64 or float64, or a pointer
+	stringHeader   = 16  // string header, also the size of an interface slot
+	sliceHeader    = 24  // slice header
+	timeBytes      = 24  // time.Time
+	dateBytes      = 24  // Date
+	timeOfDayBytes = 32  // TimeOfDay
+	rangeBytes     = 40  // Range[any]
+	bigIntBytes    = 32  // big.Int
+	bigFloatBytes  = 40  // big.Float
+	locationBytes  = 136 // time.Location of a fixed zone, with its zone
+	expiringBytes  = 40  // Expiring
+	unionBytes     = 32  // Union
+	mapHeader      = 48  // map header
+	mapTable       = 64  // table and directory of maps outgrowing one group
+)
+
+// mapGroup is a group of 8 map slots for string keys and interface values,
+// with their control word
+const mapGroup = 8*2*stringHeader + wordSize
+
+// alloc rounds n up to the size of the heap block that holds it. The runtime's
+// size classes are finer than these steps, so the result is an upper bound.
+func alloc(n int64) int64 {
+	if n > 512 {
+		n += wordSize // type header of larger blocks holding pointers
+	}
+	switch {
+	case n <= 0:
+		return 0
+	case n <= 16:
+		return (n + 7) &^ 7
+	case n <= 128:
+		return (n + 15) &^ 15
+	case n <= 1024:
+		return (n + 127) &^ 127
+	case n <= 32<<10:
+		return (n + 1023) &^ 1023
+	}
+	return (n + 8191) &^ 8191
+}
+
+// mapAlloc is the memory of a map[string]any holding n entries, excluding the
+// keys' bytes and the values. Maps hold up to 7 entries per group of 8 slots,
+// in a power of two number of groups.
+func mapAlloc(n int64) int64 {
+	groups := int64(1)
+	for groups*7 < n {
+		groups *= 2
+	}
+	cost := alloc(mapHeader) + alloc(groups*mapGroup)
+	if groups > 1 {
+		cost += alloc(mapTable)
+	}
+	return cost
+}
+
+// WithMemoryCeiling bounds the memory a single Decode may allocate. Before
+// anything is allocated, the document is walked to compute the footprint of
+// the decoded value, and documents needing more than limit bytes fail with
+// ErrMemoryCeiling. Maps and lists are allocated at their final size, so the
+// footprint does not depend on growth. The walk costs one extra pass over the
+// document and no allocations, which suits gateways where running out of
+// memory is unacceptable. MaxObjectSize is capped at limit as well.
+func WithMemoryCeiling(limit int64) DecoderOption {
+	return func(d *Decoder) {
+		d.MemoryCeiling = limit
+		if d.MaxObjectSize == 0 || d.MaxObjectSize > limit {
+			d.MaxObjectSize = limit
+		}
+	}
+}
+
+// Footprint returns the number of bytes Decode allocates for the values
+// decoded from data, a complete document. It is the figure compared against
+// the decoder's MemoryCeiling, and a safe size for a per-document budget.
+func Footprint(data []byte) (int64, error) {
+	data, _, err := openDocument(data)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", footprintErr, err)
+	}
+	if len(data) < 2 {
+		return 0, wrapError(footprintErr, "insufficient data, need at least 2 bytes for version and type")
+	}
+	cost, _, err := valueFootprint(data[1:])
+	return cost, err
+}
+
+// checkMemoryCeiling fails when decoding data would exceed the ceiling
+func (d *Decoder) checkMemoryCeiling(data []byte) error {
+	if d.MemoryCeiling <= 0 {
+		return nil
+	}
+	cost, err := Footprint(data)
+	if err != nil {
+		return err
+	}
+	if cost > d.MemoryCeiling {
+		return fmt.Errorf("%w: decoding needs %d bytes, ceiling is %d", ErrMemoryCeiling, cost, d.MemoryCeiling)
+	}
+	return nil
+}
+
+// valueFootprint returns the allocation cost of the value at the start of
+// data and its encoded size
+func valueFootprint(data []byte) (int64, int, error) {
+	size, err := getElementSize(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	if size > len(data) {
+		return 0, 0, wrapError(footprintErr, "insufficient data for value")
+	}
+	value := data[:size]
+
+	switch t := Type(value[0]); {
+	case isFixStr(t):
+		return alloc(stringHeader) + alloc(int64(size-1)), size, nil
+	case isFixInt(t), isFixUint(t):
+		return wordSize, size, nil
+	}
+
+	switch Type(value[0]) {
+	case TypeNull, TypeBoolTrue, TypeBoolFalse, TypeByte:
+		return 0, size, nil
+	case TypeInt, TypeUint, TypeFloat, TypeDuration, TypeNumeric:
+		return wordSize, size, nil
+	case TypeDecimal:
+		return 2 * wordSize, size, nil
+	case TypeExpiring:
+		// An Expiring, with the value boxed in its interface
+		inner, _, err := valueFootprint(value[1+expiringHeader:])
+		if err != nil {
+			return 0, 0, err
+		}
+		return alloc(expiringBytes) + inner, size, nil
+	case TypeComplex:
+		return 2 * wordSize, size, nil
+	case TypeSymbols:
+		// The symbols, shared by the strings decoded from references to them
+		table, offset, err := symbolsTable(value[1:])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		cost := alloc(sliceHeader)
+		err = walkSymbols(table, func(symbol []byte) error {
+			c, _, err := valueFootprint(symbol)
+			cost += c
+			return err
+		})
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		inner, _, err := valueFootprint(value[1+offset:])
+		if err != nil {
+			return 0, 0, err
+		}
+		return cost + inner, size, nil
+	case TypeSymbol:
+		return alloc(stringHeader), size, nil
+	case TypeUnion:
+		// A Union, with its name and the value boxed in its interface
+		name, offset, err := unionName(value[1:])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		inner, _, err := valueFootprint(value[1+offset:])
+		if err != nil {
+			return 0, 0, err
+		}
+		return alloc(unionBytes) + alloc(int64(len(name))) + inner, size, nil
+	case TypeString:
+		payload, err := containerPayload(value[1:])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		return alloc(stringHeader) + alloc(int64(len(payload))), size, nil
+	case TypeBlob:
+		return alloc(sliceHeader), size, nil // blobs share the input buffer
+	case TypeBigInt, TypeBigFloat:
+		payload, err := containerPayload(value[1:])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		header := int64(bigIntBytes)
+		if Type(value[0]) == TypeBigFloat {
+			header = bigFloatBytes
+		}
+		return alloc(header) + alloc(int64(len(payload))), size, nil
+	case TypeTimestamp, TypeTimestampNanos:
+		return alloc(timeBytes), size, nil
+	case TypeZonedTimestamp:
+		name, _, err := zoneName(value[1:])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		return alloc(timeBytes) + alloc(locationBytes) + alloc(int64(len(name))), size, nil
+	case TypeExt:
+		// An Ext with a copy of the data; registered decoders are not bounded
+		_, data, _, err := extData(value[1:])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		return alloc(wordSize+sliceHeader) + alloc(int64(len(data))), size, nil
+	case TypeDate:
+		return alloc(dateBytes), size, nil
+	case TypeTimeOfDay:
+		return alloc(timeOfDayBytes), size, nil
+	case TypeRange:
+		return rangeFootprint(value[1:], size)
+	case TypeTypedList:
+		return typedListFootprint(value[1:], size)
+	case TypeUntypedList:
+		return listFootprint(value[1:], size)
+	case TypeObject:
+		return objectFootprint(value[1:], size)
+	case TypeKeyedObject:
+		return keyedObjectFootprint(value[1:], size)
+	case TypeMap:
+		return mapFootprint(value[1:], size)
+	case TypeObjectList:
+		return objectListFootprint(value[1:], size)
+	case TypeChunkedList:
+		return chunkedListFootprint(value[1:], size)
+	}
+	return 0, 0, wrapError(footprintErr, fmt.Sprintf("unsupported type %d", value[0]))
+}
+
+func rangeFootprint(data []byte, size int) (int64, int, error) {
+	if len(data) < 1 {
+		return 0, 0, wrapError(footprintErr, "insufficient data for range")
+	}
+	cost := alloc(rangeBytes)
+	for pos := 1; pos < len(data); {
+		bound, n, err := valueFootprint(data[pos:])
+		if err != nil {
+			return 0, 0, err
+		}
+		cost += bound
+		pos += n
+	}
+	return cost, size, nil
+}
+
+func listFootprint(data []byte, size int) (int64, int, error) {
+	elements, err := containerPayload(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	cost := alloc(sliceHeader) + alloc(int64(countElements(elements))*stringHeader)
+	for pos := 0; pos < len(elements); {
+		elem, n, err := valueFootprint(elements[pos:])
+		if err != nil {
+			return 0, 0, err
+		}
+		cost += elem
+		pos += n
+	}
+	return cost, size, nil
+}
+
+func chunkedListFootprint(data []byte, size int) (int64, int, error) {
+	var cost, n int64
+	err := walkChunkValues(data, func(value []byte) error {
+		elem, _, err := valueFootprint(value)
+		cost += elem
+		n++
+		return err
+	})
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	return alloc(sliceHeader) + alloc(n*stringHeader) + cost, size, nil
+}
+
+func mapFootprint(data []byte, size int) (int64, int, error) {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	if len(payload) == 0 {
+		return 0, 0, wrapError(footprintErr, "missing map key type")
+	}
+	elements := payload[1:]
+	cost := mapAlloc(int64(countElements(elements) / 2))
+	for pos := 0; pos < len(elements); {
+		elem, n, err := valueFootprint(elements[pos:])
+		if err != nil {
+			return 0, 0, err
+		}
+		cost += elem
+		pos += n
+	}
+	return cost, size, nil
+}
+
+func objectListFootprint(data []byte, size int) (int64, int, error) {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	keys, rows, values, err := objectListHeader(payload)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	// Rows share the strings of the keys
+	cost := alloc(sliceHeader) + alloc(int64(rows)*stringHeader) + int64(rows)*mapAlloc(int64(len(keys)))
+	for _, key := range keys {
+		cost += alloc(int64(len(key)))
+	}
+	for pos := 0; pos < len(values); {
+		elem, n, err := valueFootprint(values[pos:])
+		if err != nil {
+			return 0, 0, err
+		}
+		cost += elem
+		pos += n
+	}
+	return cost, size, nil
+}
+
+func objectFootprint(data []byte, size int) (int64, int, error) {
+	fields, err := containerPayload(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	if len(fields) == 1 && fields[0] == TypeNull {
+		return 0, size, nil
+	}
+
+	cost := mapAlloc(int64(countEntries(fields)))
+	for pos := 0; pos < len(fields); {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			return 0, 0, wrapError(footprintErr, "insufficient data for entry size")
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil {
+			return 0, 0, wrapError(footprintErr, err.Error())
+		}
+		entryStart := pos + 1 + entrySizeLen
+		if entrySize > uint64(len(fields)-entryStart) || entrySize == 0 {
+			return 0, 0, wrapError(footprintErr, "insufficient data for entry content")
+		}
+		entry := fields[entryStart : entryStart+int(entrySize)]
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			return 0, 0, wrapError(footprintErr, "insufficient data for key")
+		}
+		cost += alloc(int64(keyLen))
+		if len(entry) > 1+keyLen {
+			value, _, err := valueFootprint(entry[1+keyLen:])
+			if err != nil {
+				return 0, 0, err
+			}
+			cost += value
+		}
+		pos = entryStart + int(entrySize)
+	}
+	return cost, size, nil
+}
+
+func keyedObjectFootprint(data []byte, size int) (int64, int, error) {
+	entries, err := containerPayload(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	// Keys share the strings of the symbol table
+	cost := mapAlloc(int64(countElements(entries) / 2))
+	for pos, key := 0, true; pos < len(entries); key = !key {
+		value, n, err := valueFootprint(entries[pos:])
+		if err != nil {
+			return 0, 0, err
+		}
+		if !key {
+			cost += value
+		}
+		pos += n
+	}
+	return cost, size, nil
+}
+
+func typedListFootprint(data []byte, size int) (int64, int, error) {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	if len(payload) < 2 {
+		return alloc(sliceHeader), size, nil
+	}
+	elementType := Type(payload[0])
+	countLen := int(payload[1])
+	if len(payload) < 2+countLen {
+		return 0, 0, wrapError(footprintErr, "insufficient data for typed list count")
+	}
+	count, err := decodeUint(payload[2 : 2+countLen])
+	if err != nil {
+		return 0, 0, wrapError(footprintErr, err.Error())
+	}
+	elements := payload[2+countLen:]
+	// Every element takes at least one byte on the wire, which bounds count
+	// before it is used to size anything
+	if count > uint64(len(elements)) {
+		return 0, 0, wrapError(footprintErr, "typed list count exceeds its data")
+	}
+	n := int64(count)
+
+	cost := alloc(sliceHeader)
+	switch elementType {
+	case TypeString:
+		cost += alloc(n * stringHeader)
+		for pos := 0; pos < len(elements); {
+			lenSize := int(elements[pos])
+			if pos+1+lenSize > len(elements) {
+				return 0, 0, wrapError(footprintErr, "insufficient data for typed list string")
+			}
+			strLen, err := decodeUint(elements[pos+1 : pos+1+lenSize])
+			if err != nil {
+				return 0, 0, wrapError(footprintErr, err.Error())
+			}
+			cost += alloc(int64(strLen))
+			pos += 1 + lenSize + int(strLen)
+		}
+	case TypeInt, TypeUint, TypeFloat, TypeTimestamp:
+		cost += alloc(n * wordSize)
+	case TypeBoolTrue:
+		cost += alloc(n)
+	case TypeByte:
+		// byte lists share the input buffer
+	case TypeTypedList:
+		cost += alloc(n * sliceHeader)
+		for pos := 0; pos < len(elements); {
+			rowSize, ok := typedElementSize(TypeTypedList, elements[pos:])
+			if !ok {
+				return 0, 0, wrapError(footprintErr, "insufficient data for typed list row")
+			}
+			// Rows are never nested themselves, which bounds the recursion
+			if payload := elements[pos+1+int(elements[pos]) : pos+rowSize]; len(payload) > 0 && Type(payload[0]) == TypeTypedList {
+				return 0, 0, wrapError(footprintErr, "nested typed list row")
+			}
+			row, _, err := typedListFootprint(elements[pos:pos+rowSize], rowSize)
+			if err != nil {
+				return 0, 0, err
+			}
+			cost += row
+			pos += rowSize
+		}
+	}
+	return cost, size, nil
+}
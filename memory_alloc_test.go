@@ -0,0 +1,101 @@
+//go:build !race
+
+// The race detector pads every allocation, so allocations are only measured
+// in regular builds.
+
+package bogo
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The footprint must bound what decoding actually allocates
+func TestFootprintBoundsAllocations(t *testing.T) {
+	big := make(map[string]any)
+	for i := 0; i < 100; i++ {
+		big[fmt.Sprintf("key-%d", i)] = fmt.Sprintf("value number %d", i)
+	}
+	var rows []any
+	for i := 0; i < 50; i++ {
+		rows = append(rows, map[string]any{"id": i, "name": "row", "tags": []string{"a", "b"}})
+	}
+	words := make([]string, 40)
+	for i := range words {
+		words[i] = fmt.Sprintf("word %d of a typed list", i)
+	}
+
+	docs := map[string]any{
+		"string":     "a string long enough to need its own block",
+		"typed list": words,
+		"big object": big,
+		"nested": map[string]any{
+			"rows":   rows,
+			"floats": []float64{1.5, 2.5},
+			"flags":  []bool{true, false, true},
+			"blob":   []byte("payload"),
+			"when":   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			"day":    Date{Year: 2024, Month: time.May, Day: 1},
+			"window": ClosedRange(1, 10),
+			"empty":  map[string]any{},
+			"none":   nil,
+		},
+	}
+
+	for name, doc := range docs {
+		t.Run(name, func(t *testing.T) {
+			data, err := Marshal(doc)
+			require.NoError(t, err)
+			footprint, err := Footprint(data)
+			require.NoError(t, err)
+
+			decoder := NewConfigurableDecoder()
+			allocated := uint64(1 << 62)
+			for range 5 {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				_, err := decoder.Decode(data)
+				runtime.ReadMemStats(&after)
+				require.NoError(t, err)
+				allocated = min(allocated, after.TotalAlloc-before.TotalAlloc)
+			}
+			assert.LessOrEqual(t, allocated, uint64(footprint))
+		})
+	}
+}
+
+// Appending to a buffer with room saves allocating the document
+func TestMarshalAppendAllocations(t *testing.T) {
+	v := map[string]any{"id": int64(7), "name": "ada"}
+	buf := make([]byte, 0, 256)
+	appended := testing.AllocsPerRun(100, func() {
+		buf, _ = MarshalAppend(buf[:0], v)
+	})
+	marshaled := testing.AllocsPerRun(100, func() {
+		_, _ = Marshal(v)
+	})
+	assert.Less(t, appended, marshaled)
+}
+
+// Structs are filled without the maps the general path builds first
+func TestUnmarshalStructAllocations(t *testing.T) {
+	data := mustMarshal(t, map[string]any{
+		"id":    int64(1),
+		"items": []any{map[string]any{"sku": "a", "qty": 1}, map[string]any{"sku": "b", "qty": 2}},
+	})
+	direct := testing.AllocsPerRun(100, func() {
+		var out directOrder
+		_ = Unmarshal(data, &out)
+	})
+	general := testing.AllocsPerRun(100, func() {
+		var out directOrder
+		_ = unmarshalGeneral(defaultDecoder, data, &out)
+	})
+	assert.Less(t, direct, general)
+}
@@ -0,0 +1,82 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFootprint(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  int64
+	}{
+		{"null", nil, 0},
+		{"bool", true, 0},
+		{"int", 1234, 8},
+		{"string", "hello", 16 + 8},
+		{"blob", []byte("shared with the input"), 32},
+		{"typed ints", []int64{1, 2, 3}, 32 + 32},
+		{"empty object", map[string]any{}, 48 + 384},
+		{"object", map[string]any{"name": "Ada"}, (48 + 384) + 8 + (16 + 8)},
+		{"integer keyed map", map[int64]string{1: "Ada"}, (48 + 384) + 8 + (16 + 8)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			require.NoError(t, err)
+
+			got, err := Footprint(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := Footprint([]byte{Version})
+		assert.Error(t, err)
+		_, err = Footprint([]byte{Version, TypeString, 1, 200})
+		assert.Error(t, err)
+		_, err = Footprint([]byte{Version, 0x99})
+		assert.Error(t, err)
+	})
+}
+
+func TestMemoryCeiling(t *testing.T) {
+	data, err := Marshal(map[string]any{"name": "Ada", "tags": []string{"a", "b", "c"}})
+	require.NoError(t, err)
+	footprint, err := Footprint(data)
+	require.NoError(t, err)
+
+	t.Run("within the ceiling", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithMemoryCeiling(footprint))
+		result, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", result.(map[string]any)["name"])
+	})
+
+	t.Run("over the ceiling", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithMemoryCeiling(footprint - 1))
+		_, err := decoder.Decode(data)
+		assert.True(t, errors.Is(err, ErrMemoryCeiling))
+		assert.ErrorContains(t, err, fmt.Sprintf("decoding needs %d bytes, ceiling is %d", footprint, footprint-1))
+	})
+
+	t.Run("caps the object size", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithMemoryCeiling(4096))
+		assert.Equal(t, int64(4096), decoder.MaxObjectSize)
+
+		decoder = NewConfigurableDecoder(WithMaxObjectSize(100), WithMemoryCeiling(4096))
+		assert.Equal(t, int64(100), decoder.MaxObjectSize)
+	})
+
+	t.Run("malformed documents fail before decoding", func(t *testing.T) {
+		decoder := NewConfigurableDecoder(WithMemoryCeiling(4096))
+		_, err := decoder.Decode([]byte{Version, TypeTypedList, 1, 3, TypeInt, 1, 200})
+		assert.ErrorContains(t, err, "footprint error")
+	})
+}
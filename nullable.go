@@ -0,0 +1,80 @@
+package bogo
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	nullablesMu sync.RWMutex
+	// nullables maps a nullable wrapper type to the type of value it holds
+	nullables = map[reflect.Type]reflect.Type{
+		reflect.TypeFor[sql.NullString]():  reflect.TypeFor[string](),
+		reflect.TypeFor[sql.NullInt64]():   reflect.TypeFor[int64](),
+		reflect.TypeFor[sql.NullInt32]():   reflect.TypeFor[int64](),
+		reflect.TypeFor[sql.NullInt16]():   reflect.TypeFor[int64](),
+		reflect.TypeFor[sql.NullByte]():    reflect.TypeFor[int64](),
+		reflect.TypeFor[sql.NullFloat64](): reflect.TypeFor[float64](),
+		reflect.TypeFor[sql.NullBool]():    reflect.TypeFor[bool](),
+		reflect.TypeFor[sql.NullTime]():    reflect.TypeFor[time.Time](),
+	}
+)
+
+// RegisterNullable makes every encoder and decoder treat T, a wrapper such as
+// pgtype.Text or sql.Null[V], as a value of type V that may be null. T is
+// encoded as the value returned by its Value method, or null when that is nil,
+// and decoded by passing the value, converted to V, to the Scan method of *T;
+// null is decoded as the zero T. V should be a type Scan accepts, usually one
+// of the driver.Value types. The sql.Null* types of database/sql are
+// registered already. Registering a type again replaces its value type.
+func RegisterNullable[T driver.Valuer, V any]() {
+	t := reflect.TypeFor[T]()
+	if !reflect.PointerTo(t).Implements(reflect.TypeFor[sql.Scanner]()) {
+		panic(fmt.Sprintf("bogo: RegisterNullable: *%s does not implement sql.Scanner", t))
+	}
+	nullablesMu.Lock()
+	defer nullablesMu.Unlock()
+	nullables[t] = reflect.TypeFor[V]()
+}
+
+// nullableValue returns the type of value held by t, which is nil unless t
+// was registered with RegisterNullable
+func nullableValue(t reflect.Type) reflect.Type {
+	nullablesMu.RLock()
+	defer nullablesMu.RUnlock()
+	return nullables[t]
+}
+
+// encodeNullable encodes a registered nullable wrapper as its value or null
+func (e *Encoder) encodeNullable(v driver.Valuer) ([]byte, error) {
+	value, err := v.Value()
+	if err != nil {
+		return nil, fmt.Errorf("bogo encode error: %T.Value: %w", v, err)
+	}
+	if value == nil {
+		return encodeNull(), nil
+	}
+	return e.encode(value)
+}
+
+// assignNullable stores a decoded value in a registered nullable wrapper
+// destination, reporting whether dest is one
+func assignNullable(value any, dest reflect.Value, d *Decoder) (bool, error) {
+	valueType := nullableValue(dest.Type())
+	if valueType == nil || !dest.CanAddr() {
+		return false, nil
+	}
+	held := reflect.New(valueType).Elem()
+	if err := assignValueToField(value, held, d); err != nil {
+		return true, err
+	}
+	scanner := dest.Addr().Interface().(sql.Scanner)
+	if err := scanner.Scan(held.Interface()); err != nil {
+		return true, fmt.Errorf("cannot scan into %s: %w", dest.Type(), err)
+	}
+	return true, nil
+}
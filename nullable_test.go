@@ -0,0 +1,151 @@
+package bogo
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type customerRow struct {
+	ID       int64           `json:"id"`
+	Email    sql.NullString  `json:"email"`
+	Age      sql.NullInt32   `json:"age"`
+	Score    sql.NullFloat64 `json:"score"`
+	Verified sql.NullBool    `json:"verified"`
+	Deleted  sql.NullTime    `json:"deleted"`
+	Note     *sql.NullString `json:"note"`
+}
+
+// pgText mimics a third-party nullable wrapper such as pgtype.Text
+type pgText struct {
+	String string
+	Valid  bool
+}
+
+func (t pgText) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.String, nil
+}
+
+func (t *pgText) Scan(src any) error {
+	if src == nil {
+		*t = pgText{}
+		return nil
+	}
+	s, ok := src.(string)
+	if !ok {
+		return errors.New("pgText: not a string")
+	}
+	*t = pgText{String: s, Valid: true}
+	return nil
+}
+
+func TestNullableFields(t *testing.T) {
+	note := sql.NullString{String: "vip", Valid: true}
+	row := customerRow{
+		ID:       7,
+		Email:    sql.NullString{String: "a@example.com", Valid: true},
+		Age:      sql.NullInt32{Int32: 41, Valid: true},
+		Score:    sql.NullFloat64{Float64: 9.5, Valid: true},
+		Verified: sql.NullBool{Bool: false, Valid: true},
+		Deleted:  sql.NullTime{Time: time.UnixMilli(1700000000000), Valid: true},
+		Note:     &note,
+	}
+
+	data, err := Marshal(row)
+	require.NoError(t, err)
+
+	var decoded customerRow
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, row, decoded)
+
+	var generic map[string]any
+	require.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, "a@example.com", generic["email"])
+	assert.Equal(t, int64(41), generic["age"])
+	assert.Equal(t, false, generic["verified"])
+}
+
+func TestNullableNulls(t *testing.T) {
+	data, err := Marshal(customerRow{ID: 1})
+	require.NoError(t, err)
+
+	var generic map[string]any
+	require.NoError(t, Unmarshal(data, &generic))
+	for _, key := range []string{"email", "age", "score", "verified", "deleted", "note"} {
+		assert.Contains(t, generic, key)
+		assert.Nil(t, generic[key], key)
+	}
+
+	decoded := customerRow{Email: sql.NullString{String: "stale", Valid: true}}
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, customerRow{ID: 1}, decoded)
+}
+
+func TestNullableTopLevel(t *testing.T) {
+	data, err := Marshal(sql.NullInt64{Int64: -3, Valid: true})
+	require.NoError(t, err)
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), decoded)
+
+	var v sql.NullInt64
+	require.NoError(t, Unmarshal(data, &v))
+	assert.Equal(t, sql.NullInt64{Int64: -3, Valid: true}, v)
+
+	data, err = Marshal(sql.NullInt64{})
+	require.NoError(t, err)
+	require.NoError(t, Unmarshal(data, &v))
+	assert.False(t, v.Valid)
+}
+
+func TestNullableScanError(t *testing.T) {
+	data, err := Marshal(map[string]any{"age": int64(1) << 40})
+	require.NoError(t, err)
+
+	var row struct {
+		Age sql.NullInt32 `json:"age"`
+	}
+	assert.Error(t, Unmarshal(data, &row))
+}
+
+func TestRegisterNullable(t *testing.T) {
+	type record struct {
+		Name pgText `json:"name"`
+		Tag  pgText `json:"tag"`
+	}
+	RegisterNullable[pgText, string]()
+	defer func() {
+		nullablesMu.Lock()
+		delete(nullables, reflect.TypeFor[pgText]())
+		nullablesMu.Unlock()
+	}()
+
+	in := record{Name: pgText{String: "ada", Valid: true}}
+	data, err := Marshal(in)
+	require.NoError(t, err)
+
+	var generic map[string]any
+	require.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, map[string]any{"name": "ada", "tag": nil}, generic)
+
+	var out record
+	require.NoError(t, Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+}
+
+func TestRegisterNullableWithoutScanner(t *testing.T) {
+	assert.Panics(t, func() { RegisterNullable[valuerOnly, string]() })
+}
+
+type valuerOnly struct{}
+
+func (valuerOnly) Value() (driver.Value, error) { return nil, nil }
@@ -2,8 +2,11 @@ package bogo
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
+	"reflect"
+	"strconv"
 )
 
 func encodeNum(v any) ([]byte, error) {
@@ -71,6 +74,67 @@ func decodeUint(data []byte) (uint64, error) {
 	return val, nil
 }
 
+// NaNPolicy controls how non-finite floats (NaN, +Inf and -Inf) are encoded
+type NaNPolicy int
+
+const (
+	// NaNKeep encodes non-finite floats bit-exactly (default)
+	NaNKeep NaNPolicy = iota
+	// NaNError rejects non-finite floats with an error
+	NaNError
+	// NaNNull encodes non-finite floats as TypeNull, for consumers such as JSON
+	// that cannot represent them
+	NaNNull
+)
+
+func (p NaNPolicy) String() string {
+	switch p {
+	case NaNKeep:
+		return "keep"
+	case NaNError:
+		return "error"
+	case NaNNull:
+		return "null"
+	}
+	return "<unknown>"
+}
+
+var errNonFiniteFloat = errors.New("bogo encode error: non-finite float")
+
+// isFinite reports whether f is neither NaN nor infinite
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// encodeFloatWithPolicy encodes f, applying policy when f is not finite
+func encodeFloatWithPolicy(f float64, policy NaNPolicy) ([]byte, error) {
+	if !isFinite(f) {
+		switch policy {
+		case NaNError:
+			return nil, fmt.Errorf("%w: %v", errNonFiniteFloat, f)
+		case NaNNull:
+			return encodeNull(), nil
+		}
+	}
+	return encodeFloat(f)
+}
+
+// encodeCompactUint encodes data in the type byte when it fits TypeFixUint
+func encodeCompactUint(data uint64) ([]byte, error) {
+	if data <= maxFixUint {
+		return []byte{byte(TypeFixUint) | byte(data)}, nil
+	}
+	return encodeUint(data)
+}
+
+// encodeCompactInt encodes data in the type byte when it fits TypeFixInt
+func encodeCompactInt(data int64) ([]byte, error) {
+	if data >= 0 && data <= maxFixInt {
+		return []byte{byte(TypeFixInt) | byte(data)}, nil
+	}
+	return encodeInt(data)
+}
+
 func decomposeFloat64(f float64) (signExp uint16, mantissa uint64) {
 	bits := math.Float64bits(f)
 	sign := int(bits >> 63)
@@ -88,7 +152,7 @@ func encodeFloat(f float64) ([]byte, error) {
 	buf := make([]byte, 32) // big enough buffer
 	buf[0] = byte(TypeFloat)
 
-	binary.LittleEndian.PutUint16(buf[2:4], signExp) // write 2 bytes
+	wireOrder.PutUint16(buf[2:4], signExp) // write 2 bytes
 
 	// encode mantisa
 	n := 0
@@ -101,12 +165,13 @@ func encodeFloat(f float64) ([]byte, error) {
 	return buf[:n+4], nil
 }
 
-// todo: use a different encoding scheme that does not loose precision
+// decodeFloat rebuilds the exact float64 bit pattern written by encodeFloat,
+// including -0.0, subnormals, infinities and NaN payloads
 func decodeFloat(data []byte) (float64, error) {
 	if len(data) == 0 {
 		return 0, fmt.Errorf("empty input")
 	}
-	signExpo := binary.LittleEndian.Uint16(data[0:2])
+	signExpo := wireOrder.Uint16(data[0:2])
 	sign := int(signExpo >> 15)
 	exp := signExpo & 0x7FFF
 
@@ -123,3 +188,144 @@ func decodeFloat(data []byte) (float64, error) {
 	bits := (uint64(sign) << 63) | (uint64(exp) << 52) | mantissa
 	return math.Float64frombits(bits), nil
 }
+
+// Number is a number decoded into an interface value by a decoder created
+// with WithUseNumber. Like json.Number it leaves the choice of Go type to the
+// caller: Int64, Uint64 and Float64 convert it on demand, failing rather than
+// losing the sign or the fraction. It keeps the wire type it was decoded
+// from, so it encodes back to the same value. The zero Number is the integer
+// 0.
+type Number struct {
+	typ  Type   // TypeInt, TypeUint or TypeFloat
+	bits uint64 // the int64, uint64 or float64 bits of the value
+}
+
+var numberType = reflect.TypeFor[Number]()
+
+// numberOf returns v as a Number when it is an int64, uint64 or float64
+func numberOf(v any) (Number, bool) {
+	switch n := v.(type) {
+	case int64:
+		return Number{typ: TypeInt, bits: uint64(n)}, true
+	case uint64:
+		return Number{typ: TypeUint, bits: n}, true
+	case float64:
+		return Number{typ: TypeFloat, bits: math.Float64bits(n)}, true
+	}
+	return Number{}, false
+}
+
+// fromNumber returns v as the int64, uint64 or float64 it holds when it is
+// a Number, or a number decoded with its width, assigned to a destination, or
+// a pointer to one, other than an interface or a Number, and v unchanged
+// otherwise
+func fromNumber(v any, dst reflect.Value) any {
+	switch v.(type) {
+	case Number, int, int8, int16, int32, uint, uint16, uint32, float32:
+	default:
+		return v
+	}
+	t := dst.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || t == numberType {
+		return v
+	}
+	if n, ok := v.(Number); ok {
+		return n.value()
+	}
+	return widenNumber(v)
+}
+
+// value returns n as the int64, uint64 or float64 it was decoded from
+func (n Number) value() any {
+	switch n.typ {
+	case TypeUint:
+		return n.bits
+	case TypeFloat:
+		return math.Float64frombits(n.bits)
+	}
+	return int64(n.bits)
+}
+
+// Type returns the wire type n was decoded from: TypeInt, TypeUint or
+// TypeFloat
+func (n Number) Type() Type {
+	if n.typ == TypeUint || n.typ == TypeFloat {
+		return n.typ
+	}
+	return TypeInt
+}
+
+// Int64 returns n as an int64. It fails for floats with a fraction and for
+// values out of range.
+func (n Number) Int64() (int64, error) {
+	switch v := n.value().(type) {
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("bogo: number %d overflows int64", v)
+		}
+		return int64(v), nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("bogo: number %v is not an int64", v)
+		}
+		return int64(v), nil
+	default:
+		return v.(int64), nil
+	}
+}
+
+// Uint64 returns n as a uint64. It fails for negative numbers, floats with a
+// fraction and values out of range.
+func (n Number) Uint64() (uint64, error) {
+	switch v := n.value().(type) {
+	case int64:
+		if v < 0 {
+			return 0, fmt.Errorf("bogo: number %d is negative", v)
+		}
+		return uint64(v), nil
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+			return 0, fmt.Errorf("bogo: number %v is not a uint64", v)
+		}
+		return uint64(v), nil
+	default:
+		return v.(uint64), nil
+	}
+}
+
+// Float64 returns n as a float64, rounding integers beyond 2^53 to the
+// nearest float
+func (n Number) Float64() float64 {
+	switch v := n.value().(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v.(float64)
+	}
+}
+
+// String returns n in decimal, floats in the shortest form that parses back
+// to the same value
+func (n Number) String() string {
+	switch v := n.value().(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	default:
+		return strconv.FormatFloat(v.(float64), 'g', -1, 64)
+	}
+}
+
+// MarshalJSON writes n as a JSON number
+func (n Number) MarshalJSON() ([]byte, error) {
+	if f, ok := n.value().(float64); ok && !isFinite(f) {
+		return nil, fmt.Errorf("bogo: number %v has no JSON form", f)
+	}
+	return []byte(n.String()), nil
+}
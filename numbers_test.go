@@ -0,0 +1,102 @@
+package bogo
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseNumber(t *testing.T) {
+	data := mustMarshal(t, map[string]any{
+		"small": 7,
+		"big":   int64(math.MaxInt64),
+		"huge":  uint64(math.MaxUint64),
+		"neg":   -3,
+		"pi":    3.25,
+		"list":  []any{1, "a", 2.5},
+		"name":  "n",
+	})
+	decoder := NewConfigurableDecoder(WithUseNumber(true))
+
+	decoded, err := decoder.Decode(data)
+	require.NoError(t, err)
+	obj := decoded.(map[string]any)
+	require.IsType(t, Number{}, obj["small"])
+	assert.Equal(t, "n", obj["name"])
+
+	huge := obj["huge"].(Number)
+	assert.Equal(t, Type(TypeUint), huge.Type())
+	_, err = huge.Int64()
+	assert.Error(t, err)
+	u, err := huge.Uint64()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u)
+
+	neg := obj["neg"].(Number)
+	i, err := neg.Int64()
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), i)
+	_, err = neg.Uint64()
+	assert.Error(t, err)
+
+	pi := obj["pi"].(Number)
+	assert.Equal(t, 3.25, pi.Float64())
+	assert.Equal(t, "3.25", pi.String())
+	_, err = pi.Int64()
+	assert.Error(t, err)
+
+	list := obj["list"].([]any)
+	assert.IsType(t, Number{}, list[0])
+	assert.IsType(t, Number{}, list[2])
+
+	t.Run("encodes back to the same values", func(t *testing.T) {
+		again, err := Marshal(decoded)
+		require.NoError(t, err)
+		equal, err := Equal(data, again)
+		require.NoError(t, err)
+		assert.True(t, equal)
+	})
+
+	t.Run("converts for typed destinations", func(t *testing.T) {
+		var doc struct {
+			Small int8     `json:"small"`
+			Huge  uint64   `json:"huge"`
+			Pi    *float32 `json:"pi"`
+			List  []any    `json:"list"`
+			Big   Number   `json:"big"`
+		}
+		require.NoError(t, decoder.Unmarshal(data, &doc))
+		assert.Equal(t, int8(7), doc.Small)
+		assert.Equal(t, uint64(math.MaxUint64), doc.Huge)
+		assert.Equal(t, float32(3.25), *doc.Pi)
+		assert.IsType(t, Number{}, doc.List[0])
+		assert.Equal(t, "9223372036854775807", doc.Big.String())
+
+		var ints map[string]int64
+		err := decoder.Unmarshal(mustMarshal(t, map[string]any{"a": 1, "b": -2}), &ints)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"a": 1, "b": -2}, ints)
+	})
+
+	t.Run("marshals to JSON numbers", func(t *testing.T) {
+		out, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `"huge":18446744073709551615`)
+		assert.Contains(t, string(out), `"pi":3.25`)
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), decoded.(map[string]any)["small"])
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		var n Number
+		assert.Equal(t, "0", n.String())
+		assert.Equal(t, Type(TypeInt), n.Type())
+	})
+}
@@ -2,6 +2,7 @@ package bogo
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 )
@@ -54,6 +55,11 @@ func encodeFieldEntry(key string, value any) ([]byte, error) {
 		return nil, err
 	}
 
+	return buildFieldEntry(key, encodedValue)
+}
+
+// buildFieldEntry frames an already encoded value as an object field entry
+func buildFieldEntry(key string, encodedValue []byte) ([]byte, error) {
 	keyBytes := []byte(key)
 	keyLen := len(keyBytes)
 
@@ -80,9 +86,23 @@ func encodeFieldEntry(key string, value any) ([]byte, error) {
 	return entry.Bytes(), nil
 }
 
+// buildContainer frames encoded list elements or object field entries with the
+// container type and the total payload size
+func buildContainer(typ Type, payload []byte) ([]byte, error) {
+	result := make([]byte, 0, 2+binary.MaxVarintLen64+len(payload))
+	result = appendTypedHeader(result, typ, uint64(len(payload)))
+	return append(result, payload...), nil
+}
+
 var objDecErr = errors.New("object decoder error")
 
 func decodeObject(data []byte) (map[string]any, error) {
+	return decodeObjectWith(data, stringMaker{})
+}
+
+// decodeObjectWith is decodeObject building keys and strings with strs. The
+// same holds for the other decode*With functions.
+func decodeObjectWith(data []byte, strs stringMaker) (map[string]any, error) {
 	if len(data) == 0 {
 		return map[string]any{}, nil
 	}
@@ -117,11 +137,11 @@ func decodeObject(data []byte) (map[string]any, error) {
 	}
 
 	// Parse all field entries
-	result := make(map[string]any)
+	result := make(map[string]any, countEntries(fieldsData))
 	pos := 0
 
 	for pos < len(fieldsData) {
-		key, value, bytesRead, err := decodeFieldEntry(fieldsData[pos:])
+		key, value, bytesRead, err := decodeFieldEntryWith(fieldsData[pos:], strs)
 		if err != nil {
 			return nil, wrapError(objDecErr, "failed to decode field entry", err.Error())
 		}
@@ -134,6 +154,10 @@ func decodeObject(data []byte) (map[string]any, error) {
 }
 
 func decodeFieldEntry(data []byte) (key string, value any, bytesRead int, err error) {
+	return decodeFieldEntryWith(data, stringMaker{})
+}
+
+func decodeFieldEntryWith(data []byte, strs stringMaker) (key string, value any, bytesRead int, err error) {
 	if len(data) == 0 {
 		return "", nil, 0, errors.New("empty field entry data")
 	}
@@ -167,7 +191,7 @@ func decodeFieldEntry(data []byte) (key string, value any, bytesRead int, err er
 		return "", nil, 0, errors.New("insufficient data for key")
 	}
 
-	key = string(entryData[1 : 1+keyLen])
+	key = strs.key(entryData[1 : 1+keyLen])
 
 	// Decode value
 	valueData := entryData[1+keyLen:]
@@ -176,7 +200,7 @@ func decodeFieldEntry(data []byte) (key string, value any, bytesRead int, err er
 		// For now, return nil and let caller handle it
 		value = nil
 	} else {
-		value, err = decodeValue(valueData)
+		value, err = decodeValueWith(valueData, strs)
 		if err != nil {
 			return "", nil, 0, err
 		}
@@ -186,9 +210,20 @@ func decodeFieldEntry(data []byte) (key string, value any, bytesRead int, err er
 }
 
 func decodeValue(data []byte) (any, error) {
+	return decodeValueWith(data, stringMaker{})
+}
+
+func decodeValueWith(data []byte, strs stringMaker) (any, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
+	if err := strs.deadline.check(); err != nil {
+		return nil, err
+	}
+
+	if v, ok, err := decodeCompactWith(data, strs); ok {
+		return v, err
+	}
 
 	// Use the existing decode function but without version check
 	switch Type(data[0]) {
@@ -199,19 +234,26 @@ func decodeValue(data []byte) (any, error) {
 	case TypeBoolFalse:
 		return false, nil
 	case TypeString:
+		if len(data) < 2 {
+			return nil, errors.New("insufficient data for string size")
+		}
 		sizeLen := int(data[1])
-		return decodeString(data[2:], sizeLen)
+		return decodeStringWith(data[2:], sizeLen, strs)
 	case TypeByte:
 		return decodeByte(data[1:])
-	case TypeInt:
-		sizeLen := int(data[1])
-		return decodeInt(data[2 : 2+sizeLen])
-	case TypeUint:
-		sizeLen := int(data[1])
-		return decodeUint(data[2 : 2+sizeLen])
-	case TypeFloat:
-		sizeLen := int(data[1])
-		return decodeFloat(data[2 : 2+sizeLen])
+	case TypeInt, TypeUint, TypeFloat:
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, fmt.Errorf("insufficient data for %s", Type(data[0]))
+		}
+		number := data[2 : 2+int(data[1])]
+		switch Type(data[0]) {
+		case TypeInt:
+			return strs.number(decodeInt(number))
+		case TypeUint:
+			return strs.number(decodeUint(number))
+		default:
+			return strs.number(decodeFloat(number))
+		}
 	case TypeBlob:
 		blob, err := decodeBlob(data[1:])
 		if err != nil {
@@ -224,34 +266,138 @@ func decodeValue(data []byte) (any, error) {
 			return nil, err
 		}
 		return timestamp, nil
+	case TypeDate:
+		return decodeDate(data[1:])
+	case TypeTimeOfDay:
+		return decodeTimeOfDay(data[1:])
+	case TypeRange:
+		return decodeRange(data[1:])
+	case TypeDuration:
+		return decodeDuration(data[1:])
+	case TypeZonedTimestamp:
+		return decodeZonedTimestamp(data[1:])
+	case TypeTimestampNanos:
+		return decodeTimestampNanos(data[1:])
+	case TypeExt:
+		return decodeExt(data[1:])
+	case TypeDecimal:
+		return decodeDecimal(data[1:])
+	case TypeExpiring:
+		return decodeExpiringWith(data[1:], func(value []byte) (any, error) {
+			return decodeValueWith(value, strs)
+		})
+	case TypeSymbols:
+		return decodeSymbolsWith(data[1:], strs, decodeValueWith)
+	case TypeSymbol:
+		return strs.symbol(data[1:])
+	case TypeUnion:
+		return decodeUnionWith(data[1:], func(value []byte) (any, error) {
+			return decodeValueWith(value, strs)
+		})
+	case TypeNumeric:
+		return decodeNumeric(data[1:])
+	case TypeKeyedObject:
+		return decodeKeyedObjectWith(data[1:], strs, nil, func(value []byte) (any, error) {
+			return decodeValueWith(value, strs)
+		})
+	case TypeChunkedList:
+		return decodeChunkedListWith(data[1:], strs)
+	case TypeBigInt:
+		return decodeBigInt(data[1:])
+	case TypeBigFloat:
+		return decodeBigFloat(data[1:])
+	case TypeComplex:
+		return decodeComplex(data[1:])
 	case TypeUntypedList:
 		// Decode list within object
-		list, err := decodeListValue(data[1:])
+		list, err := decodeListValueWith(data[1:], strs)
 		if err != nil {
 			return nil, err
 		}
 		return list, nil
 	case TypeTypedList:
 		// Decode typed list within object
-		typedList, err := decodeTypedList(data[1:])
+		typedList, err := decodeTypedListWith(data[1:], strs)
 		if err != nil {
 			return nil, err
 		}
 		return typedList, nil
 	case TypeObject:
 		// Recursive object decoding
-		obj, err := decodeObject(data[1:])
+		obj, err := decodeObjectWith(data[1:], strs)
 		if err != nil {
 			return nil, err
 		}
 		return obj, nil
+	case TypeMap:
+		return decodeMapWith(data[1:], strs)
+	case TypeObjectList:
+		return decodeObjectListWith(data[1:], strs)
 	default:
 		return nil, fmt.Errorf("unsupported value type: %d", data[0])
 	}
 }
 
+// containerPayload returns the payload of a list or object given the data
+// after its type byte
+func containerPayload(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	sizeLen := int(data[0])
+	if len(data) < 1+sizeLen {
+		return nil, errors.New("insufficient data for container size")
+	}
+	size, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(data)-1-sizeLen) {
+		return nil, errors.New("insufficient data for container content")
+	}
+	end := 1 + sizeLen + int(size)
+	return data[1+sizeLen : end], nil
+}
+
+// countEntries counts the field entries of an object payload from their size
+// prefixes, so the decoded map is allocated at its final size. Malformed data
+// stops the count; decoding reports the error.
+func countEntries(fields []byte) int {
+	n := 0
+	for pos := 0; pos < len(fields); n++ {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			break
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil || entrySize > uint64(len(fields)) {
+			break
+		}
+		pos += 1 + entrySizeLen + int(entrySize)
+	}
+	return n
+}
+
+// countElements counts the elements of a list payload from their headers, so
+// the decoded slice is allocated at its final size
+func countElements(elements []byte) int {
+	n := 0
+	for pos := 0; pos < len(elements); n++ {
+		size, err := getElementSize(elements[pos:])
+		if err != nil || size <= 0 {
+			break
+		}
+		pos += size
+	}
+	return n
+}
+
 // decodeListValue decodes a list and returns the result as any
 func decodeListValue(data []byte) (any, error) {
+	return decodeListValueWith(data, stringMaker{})
+}
+
+func decodeListValueWith(data []byte, strs stringMaker) (any, error) {
 	if len(data) == 0 {
 		return []any{}, nil
 	}
@@ -267,16 +413,16 @@ func decodeListValue(data []byte) (any, error) {
 		return nil, err
 	}
 
-	listStart := 1 + sizeLen
-	listEnd := listStart + int(listSize)
-	if len(data) < listEnd {
+	if listSize > uint64(len(data)-1-sizeLen) {
 		return nil, errors.New("insufficient data for list content")
 	}
+	listStart := 1 + sizeLen
+	listEnd := listStart + int(listSize)
 
 	listData := data[listStart:listEnd]
 
 	// Parse all list elements
-	result := []any{}
+	result := make([]any, 0, countElements(listData))
 	pos := 0
 
 	for pos < len(listData) {
@@ -286,7 +432,7 @@ func decodeListValue(data []byte) (any, error) {
 		}
 
 		// Decode the element
-		element, err := decodeValue(listData[pos:])
+		element, err := decodeValueWith(listData[pos:], strs)
 		if err != nil {
 			return nil, err
 		}
@@ -311,6 +457,10 @@ func getElementSize(data []byte) (int, error) {
 		return 0, errors.New("empty data")
 	}
 
+	if size, ok := compactSize(data); ok {
+		return size, nil
+	}
+
 	switch Type(data[0]) {
 	case TypeNull:
 		return 1, nil
@@ -352,6 +502,85 @@ func getElementSize(data []byte) (int, error) {
 		return 2 + sizeLen + int(blobSize), nil
 	case TypeTimestamp:
 		return 9, nil // 1 + 8 bytes for timestamp
+	case TypeZonedTimestamp:
+		_, size, err := zoneName(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeExt:
+		_, _, size, err := extData(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeChunkedList:
+		size, err := walkChunks(data[1:], nil)
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeDecimal:
+		_, size, err := decimalFields(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeExpiring:
+		size, err := expiringSize(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeSymbols:
+		size, err := symbolsSize(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeSymbol:
+		_, size, err := symbolIndex(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeUnion:
+		size, err := unionSize(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeNumeric:
+		size, err := numericSize(data[1:])
+		if err != nil {
+			return 0, err
+		}
+		return 1 + size, nil
+	case TypeDate:
+		return 1 + dateSize, nil
+	case TypeTimeOfDay:
+		return 1 + timeOfDaySize, nil
+	case TypeRange:
+		return rangeSize(data)
+	case TypeDuration:
+		return 1 + durationSize, nil
+	case TypeTimestampNanos:
+		return 1 + timestampNanosSize, nil
+	case TypeComplex:
+		return 1 + complexSize, nil
+	case TypeBigInt, TypeBigFloat:
+		if len(data) < 2 {
+			return 0, errors.New("insufficient data for big number size")
+		}
+		sizeLen := int(data[1])
+		if len(data) < 2+sizeLen {
+			return 0, errors.New("insufficient data for big number size value")
+		}
+		numSize, err := decodeUint(data[2 : 2+sizeLen])
+		if err != nil {
+			return 0, err
+		}
+		return 2 + sizeLen + int(numSize), nil
 	case TypeUntypedList:
 		if len(data) < 2 {
 			return 0, errors.New("insufficient data for list size")
@@ -378,7 +607,7 @@ func getElementSize(data []byte) (int, error) {
 			return 0, err
 		}
 		return 2 + sizeLen + int(listSize), nil
-	case TypeObject:
+	case TypeObject, TypeMap, TypeObjectList, TypeKeyedObject:
 		if len(data) < 2 {
 			return 0, errors.New("insufficient data for object size")
 		}
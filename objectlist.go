@@ -0,0 +1,183 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var objListDecErr = errors.New("bogo object list decode error")
+
+// An object list is laid out as
+//
+//	TypeObjectList | size prefix | key count | row count | keys | values
+//
+// where the counts are size prefixes, each key is a 1-byte length followed by
+// the key, as in object entries, and values are complete encoded values, key
+// count of them per row in the order of the keys. It holds objects that share
+// their keys, such as the elements of a struct slice, writing the keys once
+// rather than once per object, and decodes as a list of objects.
+
+// encodeObjectList encodes the elements of the struct slice or array rv as an
+// object list. It reports false when they do not all encode to objects with
+// the same keys, as when omitempty leaves fields out of some of them, or when
+// they fail to encode, so the caller encodes a regular list. Keys are written
+// in the order of the first element, which the values of every row follow.
+func (e *Encoder) encodeObjectList(rv reflect.Value) ([]byte, bool) {
+	e.depth++
+	defer func() { e.depth-- }()
+	e.listDepth++
+	defer func() { e.listDepth-- }()
+
+	var keys []string
+	var index map[string]int
+	var row [][]byte
+	var values []byte
+	for i := 0; i < rv.Len(); i++ {
+		// Rows are written as plain objects, their keys going in the header
+		e.plainKeys = true
+		data, err := e.encode(rv.Index(i).Interface())
+		e.plainKeys = false
+		if err != nil || len(data) == 0 || Type(data[0]) != TypeObject {
+			return nil, false
+		}
+		fields, err := objectFields(data)
+		if err != nil || fields == nil {
+			return nil, false
+		}
+
+		if i == 0 {
+			index = map[string]int{}
+			err = walkEntries(fields, func(_, _ int, key, _ []byte) bool {
+				index[string(key)] = len(keys)
+				keys = append(keys, string(key))
+				return true
+			})
+			if err != nil || len(keys) == 0 {
+				return nil, false
+			}
+			row = make([][]byte, len(keys))
+		}
+
+		n, same := 0, true
+		clear(row)
+		err = walkEntries(fields, func(_, _ int, key, value []byte) bool {
+			slot, ok := index[string(key)]
+			if !ok || row[slot] != nil {
+				same = false
+				return false
+			}
+			row[slot] = value
+			n++
+			return true
+		})
+		if err != nil || !same || n != len(keys) {
+			return nil, false
+		}
+		for _, value := range row {
+			values = append(values, value...)
+		}
+		if e.outputExceeded(len(values)) {
+			// The plain list reports which element passed the limit
+			return nil, false
+		}
+	}
+
+	payload := appendUvarintLen(nil, uint64(len(keys)))
+	payload = appendUvarintLen(payload, uint64(rv.Len()))
+	for _, key := range keys {
+		payload = append(append(payload, byte(len(key))), key...)
+	}
+	data, err := buildContainer(TypeObjectList, append(payload, values...))
+	return data, err == nil
+}
+
+// isObjectListElem reports whether lists of t are encoded as object lists
+// when Encoder.ObjectLists is set
+func isObjectListElem(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// objectListHeader splits the payload of an object list into its keys, its
+// row count and the encoded values of its rows
+func objectListHeader(payload []byte) (keys [][]byte, rows int, values []byte, err error) {
+	keyCount, err := readSizePrefix(payload, 0)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	rowCount, err := readSizePrefix(payload, keyCount.width)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	pos := keyCount.width + rowCount.width
+	// Every key takes at least one byte and every value at least one, which
+	// bounds what corrupt counts can make us allocate
+	if keyCount.value == 0 {
+		return nil, 0, nil, errors.New("object list has no keys")
+	}
+	if keyCount.value > uint64(len(payload)-pos) {
+		return nil, 0, nil, errors.New("key count exceeds the list size")
+	}
+
+	keys = make([][]byte, keyCount.value)
+	for i := range keys {
+		if pos >= len(payload) || pos+1+int(payload[pos]) > len(payload) {
+			return nil, 0, nil, fmt.Errorf("insufficient data for key %d", i)
+		}
+		keys[i] = payload[pos+1 : pos+1+int(payload[pos])]
+		pos += 1 + len(keys[i])
+	}
+
+	values = payload[pos:]
+	if rowCount.value > uint64(len(values))/keyCount.value {
+		return nil, 0, nil, errors.New("row count exceeds the list size")
+	}
+	return keys, int(rowCount.value), values, nil
+}
+
+// decodeObjectListWith decodes an object list into a list of objects. The
+// objects share the strings of their keys.
+func decodeObjectListWith(data []byte, strs stringMaker) (any, error) {
+	return decodeObjectListValues(data, strs, func(value []byte) (any, error) {
+		return decodeValueWith(value, strs)
+	})
+}
+
+// decodeObjectListValues decodes an object list as decodeObjectListWith
+// does, decoding the values of its rows with decode
+func decodeObjectListValues(data []byte, strs stringMaker, decode func(value []byte) (any, error)) (any, error) {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return nil, wrapError(objListDecErr, err.Error())
+	}
+	keys, rows, values, err := objectListHeader(payload)
+	if err != nil {
+		return nil, wrapError(objListDecErr, err.Error())
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = strs.key(key)
+	}
+
+	list := make([]any, rows)
+	pos := 0
+	for i := range list {
+		row := make(map[string]any, len(names))
+		for _, name := range names {
+			size, err := getElementSize(values[pos:])
+			if err != nil || size > len(values)-pos {
+				return nil, wrapError(objListDecErr, fmt.Sprintf("insufficient data for row %d field %s", i, name))
+			}
+			if row[name], err = decode(values[pos : pos+size]); err != nil {
+				return nil, wrapError(objListDecErr, fmt.Sprintf("row %d field %s", i, name), err.Error())
+			}
+			pos += size
+		}
+		list[i] = row
+	}
+	if pos != len(values) {
+		return nil, wrapError(objListDecErr, fmt.Sprintf("%d trailing bytes after the rows", len(values)-pos))
+	}
+	return list, nil
+}
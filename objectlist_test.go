@@ -0,0 +1,120 @@
+package bogo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reading struct {
+	Sensor string  `json:"sensor"`
+	Value  float64 `json:"value"`
+	Ok     bool    `json:"ok"`
+}
+
+type station struct {
+	Name     string    `json:"name"`
+	Readings []reading `json:"readings"`
+}
+
+type sparseReading struct {
+	Sensor string `json:"sensor"`
+	Note   string `json:"note,omitempty"`
+}
+
+func TestObjectLists(t *testing.T) {
+	readings := make([]reading, 100)
+	for i := range readings {
+		readings[i] = reading{Sensor: fmt.Sprint("s", i%4), Value: float64(i) / 2, Ok: i%3 != 0}
+	}
+	encoder := NewConfigurableEncoder(WithObjectLists(true))
+
+	data, err := encoder.Encode(readings)
+	require.NoError(t, err)
+	assert.Equal(t, byte(TypeObjectList), data[1])
+	plain, err := Encode(readings)
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(plain)*2/3, "keys are written once")
+
+	var got []reading
+	require.NoError(t, Unmarshal(data, &got))
+	assert.Equal(t, readings, got)
+
+	var maps []map[string]any
+	require.NoError(t, Unmarshal(data, &maps))
+	require.Len(t, maps, len(readings))
+	assert.Equal(t, map[string]any{"sensor": "s1", "value": 0.5, "ok": true}, maps[1])
+
+	t.Run("decodes like a list of objects", func(t *testing.T) {
+		want, err := Decode(plain)
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+
+		decoded, err = NewConfigurableDecoder().Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+	})
+
+	t.Run("nested in structs", func(t *testing.T) {
+		in := station{Name: "north", Readings: readings[:3]}
+		data, err := encoder.Encode(in)
+		require.NoError(t, err)
+
+		var out station
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, in, out)
+
+		readingsData, err := LookupField(data, "readings")
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeObjectList), readingsData[0])
+	})
+
+	t.Run("fields that differ fall back to a list", func(t *testing.T) {
+		sparse := []sparseReading{{Sensor: "a"}, {Sensor: "b", Note: "late"}}
+		data, err := encoder.Encode(sparse)
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), data[1])
+
+		var got []sparseReading
+		require.NoError(t, Unmarshal(data, &got))
+		assert.Equal(t, sparse, got)
+	})
+
+	t.Run("empty slices are lists", func(t *testing.T) {
+		data, err := encoder.Encode([]reading{})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), data[1])
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.Equal(t, byte(TypeUntypedList), plain[1])
+	})
+}
+
+func TestObjectListErrors(t *testing.T) {
+	data, err := NewConfigurableEncoder(WithObjectLists(true)).Encode([]reading{{Sensor: "a"}, {Sensor: "b"}})
+	require.NoError(t, err)
+	for i := 2; i < len(data); i++ {
+		_, err := Decode(data[:i])
+		assert.Error(t, err, "truncated at %d", i)
+	}
+
+	payload := appendUvarintLen(appendUvarintLen(nil, 1), 1<<40)
+	payload = append(payload, 1, 'k', TypeNull)
+	corrupt, err := buildContainer(TypeObjectList, payload)
+	require.NoError(t, err)
+	_, err = Decode(append([]byte{Version}, corrupt...))
+	assert.ErrorContains(t, err, "row count exceeds")
+	_, err = Footprint(append([]byte{Version}, corrupt...))
+	assert.Error(t, err)
+
+	noKeys, err := buildContainer(TypeObjectList, appendUvarintLen(appendUvarintLen(nil, 0), 5))
+	require.NoError(t, err)
+	_, err = Decode(append([]byte{Version}, noKeys...))
+	assert.ErrorContains(t, err, "no keys")
+}
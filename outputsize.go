@@ -0,0 +1,47 @@
+package bogo
+
+import "fmt"
+
+// OutputSizeError is returned when a value would encode beyond the limit set
+// with WithMaxOutputSize
+type OutputSizeError struct {
+	Path  string // Location of the value that passed the limit, e.g. "orders[12].lines"; "" for the top-level value
+	Limit int64
+}
+
+func (e *OutputSizeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("bogo encode error: output exceeds the maximum size of %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("bogo encode error: output exceeds the maximum size of %d bytes at %s", e.Limit, e.Path)
+}
+
+// WithMaxOutputSize fails encodes whose document would be larger than n
+// bytes with an *OutputSizeError naming the path of the value that passed
+// the limit, so a runaway in-memory structure is stopped while it is being
+// encoded rather than producing a message that downstream queues reject
+// anyway. Containers are checked as each of their children is written, so
+// an encode stops within a child of the limit. EncodeSeq and EncodeChan apply
+// the limit to each element of the list they stream. Zero means no limit.
+func WithMaxOutputSize(n int64) EncoderOption {
+	return func(e *Encoder) {
+		e.MaxOutputSize = n
+	}
+}
+
+// outputExceeded reports whether size bytes of output pass the limit
+func (e *Encoder) outputExceeded(size int) bool {
+	return e.MaxOutputSize > 0 && int64(size) > e.MaxOutputSize
+}
+
+// outputSizeError returns the error of a container whose output passed the
+// limit once its child at segment, encoded to n bytes, was written. The
+// child is named when it passed the limit by itself and the container,
+// whose path its parents prepend, otherwise.
+func (e *Encoder) outputSizeError(n int, segment string) error {
+	err := &OutputSizeError{Limit: e.MaxOutputSize}
+	if e.outputExceeded(n) {
+		err.Path = segment
+	}
+	return err
+}
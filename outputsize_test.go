@@ -0,0 +1,87 @@
+package bogo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxOutputSize(t *testing.T) {
+	type line struct {
+		SKU  string `json:"sku"`
+		Note string `json:"note"`
+	}
+	type order struct {
+		ID    int64  `json:"id"`
+		Lines []line `json:"lines"`
+	}
+	lines := make([]line, 1000)
+	for i := range lines {
+		lines[i] = line{SKU: "sku", Note: "a note on the line"}
+	}
+	encoder := NewConfigurableEncoder(WithMaxOutputSize(4096))
+
+	tests := []struct {
+		name string
+		v    any
+		path string
+	}{
+		{"wide list", []order{{ID: 1}, {ID: 2, Lines: lines}}, "[1].lines"},
+		{"nested object", map[string]any{"small": "x", "big": map[string]any{"orders": []order{{Lines: lines}}}}, "big.orders[0].lines"},
+		{"large leaf", map[string]any{"blob": make([]byte, 5000)}, "blob"},
+		{"integer keys", map[int]string{1: strings.Repeat("x", 5000)}, "1"},
+		{"top-level value", strings.Repeat("x", 5000), ""},
+		{"many small children", map[string]any{"a": strings.Repeat("x", 3000), "b": strings.Repeat("y", 3000)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := []byte("prefix")
+			_, err := encoder.EncodeAppend(dst, tt.v)
+			var tooLarge *OutputSizeError
+			require.ErrorAs(t, err, &tooLarge)
+			assert.Equal(t, tt.path, tooLarge.Path)
+			assert.Equal(t, int64(4096), tooLarge.Limit)
+		})
+	}
+
+	t.Run("within the limit", func(t *testing.T) {
+		v := order{ID: 1, Lines: lines[:10]}
+		data, err := encoder.Encode(v)
+		require.NoError(t, err)
+		assert.Len(t, data, len(mustMarshal(t, v)))
+
+		exact := NewConfigurableEncoder(WithMaxOutputSize(int64(len(data))))
+		_, err = exact.Encode(v)
+		assert.NoError(t, err)
+		short := NewConfigurableEncoder(WithMaxOutputSize(int64(len(data) - 1)))
+		_, err = short.Encode(v)
+		assert.ErrorContains(t, err, "output exceeds the maximum size of")
+	})
+
+	t.Run("object lists", func(t *testing.T) {
+		e := NewConfigurableEncoder(WithMaxOutputSize(4096), WithObjectLists(true))
+		_, err := e.Encode(map[string]any{"lines": lines})
+		var tooLarge *OutputSizeError
+		require.ErrorAs(t, err, &tooLarge)
+		assert.Equal(t, "lines", tooLarge.Path)
+	})
+
+	t.Run("streams check each element", func(t *testing.T) {
+		var buf bytes.Buffer
+		values := func(yield func(line) bool) {
+			for _, l := range lines {
+				if !yield(l) {
+					return
+				}
+			}
+		}
+		require.NoError(t, EncodeSeq(&buf, values, WithMaxOutputSize(4096)))
+		assert.Greater(t, buf.Len(), 4096)
+
+		err := EncodeSeq(&buf, func(yield func(order) bool) { yield(order{Lines: lines}) }, WithMaxOutputSize(4096))
+		assert.ErrorContains(t, err, "at [0].lines")
+	})
+}
@@ -142,6 +142,15 @@ func (p *Parser) getBogoType(t reflect.Type) Type {
 	if t == reflect.TypeOf(time.Time{}) {
 		return TypeTimestamp
 	}
+	if t == dateType {
+		return TypeDate
+	}
+	if t == timeOfDayType {
+		return TypeTimeOfDay
+	}
+	if t == durationType {
+		return TypeDuration
+	}
 
 	switch t.Kind() {
 	case reflect.String:
@@ -157,14 +166,22 @@ func (p *Parser) getBogoType(t reflect.Type) Type {
 		return TypeUint
 	case reflect.Float32, reflect.Float64:
 		return TypeFloat
+	case reflect.Complex64, reflect.Complex128:
+		return TypeComplex
 	case reflect.Slice:
 		if t.Elem().Kind() == reflect.Uint8 {
 			return TypeBlob // []byte
 		}
 		return TypeUntypedList
 	case reflect.Array:
+		if t.Elem() == byteType {
+			return TypeBlob
+		}
 		return TypeUntypedList
 	case reflect.Map:
+		if isIntegerKey(t.Key().Kind()) {
+			return TypeMap
+		}
 		return TypeObject
 	case reflect.Struct:
 		return TypeObject
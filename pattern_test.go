@@ -400,3 +400,49 @@ func BenchmarkDecoderPattern(b *testing.B) {
 		}
 	}
 }
+
+func TestUint8AsNumber(t *testing.T) {
+	type Level uint8
+
+	t.Run("default encodes uint8 as byte", func(t *testing.T) {
+		data, err := Encode([]any{uint8(5), uint16(500)})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{byte(5), uint64(500)}, decoded)
+	})
+
+	t.Run("option encodes uint8 as uint", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithUint8AsNumber(true))
+
+		data, err := encoder.Encode([]any{uint8(5), uint16(500), Level(7)})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{uint64(5), uint64(500), uint64(7)}, decoded)
+
+		data, err = encoder.Encode(map[string]any{"level": Level(2), "raw": []byte{1, 2}})
+		require.NoError(t, err)
+
+		decoded, err = Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"level": uint64(2), "raw": []byte{1, 2}}, decoded)
+	})
+
+	t.Run("named uint8 types decode into fields", func(t *testing.T) {
+		type Settings struct {
+			Level Level `json:"level"`
+		}
+
+		for _, enabled := range []bool{false, true} {
+			data, err := NewConfigurableEncoder(WithUint8AsNumber(enabled)).Encode(Settings{Level: 3})
+			require.NoError(t, err)
+
+			var decoded Settings
+			require.NoError(t, Unmarshal(data, &decoded))
+			assert.Equal(t, Level(3), decoded.Level)
+		}
+	})
+}
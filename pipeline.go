@@ -0,0 +1,127 @@
+package bogo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var pipelineErr = errors.New("bogo pipeline error")
+
+// EncodingWriter turns values into a length-prefixed stream of documents
+// written to another writer, so encoding can be stacked with the compression,
+// encryption and network layers of an io pipeline:
+//
+//	pr, pw := io.Pipe()
+//	go func() {
+//		w := bogo.NewEncodingWriter(pw)
+//		for _, order := range orders {
+//			if err := w.WriteValue(order); err != nil {
+//				pw.CloseWithError(err)
+//				return
+//			}
+//		}
+//		w.Close()
+//	}()
+//	r := bogo.NewDecodingReader(pr)
+//
+// It is an io.WriteCloser, whose Write takes documents already encoded, such
+// as those returned by Marshal, so it can also stand where a plain writer is
+// expected. The stream is read by NewDecodingReader, or by a StreamDecoder
+// with WithDecoderFraming(LengthPrefixed).
+type EncodingWriter struct {
+	dst    io.Writer
+	enc    *StreamEncoder
+	closed bool
+}
+
+// NewEncodingWriter returns an EncodingWriter writing to dst, encoding values
+// with options. Documents are always length-prefixed, whatever framing the
+// options set.
+func NewEncodingWriter(dst io.Writer, options ...EncoderOption) *EncodingWriter {
+	options = append(options[:len(options):len(options)], WithFraming(LengthPrefixed))
+	return &EncodingWriter{dst: dst, enc: NewEncoderWithOptions(dst, options...)}
+}
+
+// WriteValue encodes v and writes it as the next document of the stream
+func (w *EncodingWriter) WriteValue(v any) error {
+	if w.closed {
+		return wrapError(pipelineErr, "write to closed EncodingWriter")
+	}
+	return w.enc.Encode(v)
+}
+
+// Write writes p, a complete encoded document, as the next document of the
+// stream. It returns len(p) once the whole document has been written.
+func (w *EncodingWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, wrapError(pipelineErr, "write to closed EncodingWriter")
+	}
+	if len(p) < 2 || !startsDocument(p[0]) {
+		return 0, wrapError(pipelineErr, "write of data that is not an encoded document")
+	}
+	if _, err := w.dst.Write(LengthPrefixed.frame(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close ends the stream, closing dst when it is an io.Closer, such as the
+// writing end of an io.Pipe or a gzip.Writer, so that readers see its end
+func (w *EncodingWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if c, ok := w.dst.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// DecodingReader turns a length-prefixed stream of documents read from
+// another reader, such as the one written by an EncodingWriter, back into
+// values, one document at a time. Reads are buffered.
+type DecodingReader struct {
+	src     *bufio.Reader
+	decoder *Decoder
+}
+
+// NewDecodingReader returns a DecodingReader reading from src, decoding
+// values with options
+func NewDecodingReader(src io.Reader, options ...DecoderOption) *DecodingReader {
+	return &DecodingReader{src: bufio.NewReader(src), decoder: NewConfigurableDecoder(options...)}
+}
+
+// ReadValue reads and decodes the next document of the stream. It returns
+// io.EOF once the stream ends between documents.
+func (r *DecodingReader) ReadValue() (any, error) {
+	data, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.decoder.Decode(data)
+}
+
+// Decode reads the next document of the stream and stores it in v, as
+// Unmarshal does. It returns io.EOF once the stream ends between documents.
+func (r *DecodingReader) Decode(v any) error {
+	data, err := r.next()
+	if err != nil {
+		return err
+	}
+	return r.decoder.Unmarshal(data, v)
+}
+
+// next reads the next document of the stream
+func (r *DecodingReader) next() ([]byte, error) {
+	data, err := readFrame(r.src)
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bogo decode error: failed to read data: %w", err)
+	}
+	return data, nil
+}
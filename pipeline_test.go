@@ -0,0 +1,103 @@
+package bogo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	type order struct {
+		ID    int64  `json:"id"`
+		Buyer string `json:"buyer"`
+	}
+	orders := []order{{ID: 1, Buyer: "ama"}, {ID: 2, Buyer: "kofi"}, {ID: 3, Buyer: "esi"}}
+
+	// Values go through gzip and an io.Pipe and come back out
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		w := NewEncodingWriter(zw)
+		for _, o := range orders[:2] {
+			if err := w.WriteValue(o); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		// Documents encoded elsewhere are written as they are
+		data, err := Marshal(orders[2])
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	zr, err := gzip.NewReader(pr)
+	require.NoError(t, err)
+	r := NewDecodingReader(zr)
+	var got []order
+	for {
+		var o order
+		err := r.Decode(&o)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, o)
+	}
+	assert.Equal(t, orders, got)
+
+	t.Run("values", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewEncodingWriter(&buf, WithFraming(Raw))
+		require.NoError(t, w.WriteValue("a"))
+		require.NoError(t, w.WriteValue(int64(2)))
+		require.NoError(t, w.Close())
+
+		r := NewDecodingReader(&buf)
+		v, err := r.ReadValue()
+		require.NoError(t, err)
+		assert.Equal(t, "a", v)
+		v, err = r.ReadValue()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), v)
+		_, err = r.ReadValue()
+		assert.Equal(t, io.EOF, err)
+
+		// The stream is one a framed StreamDecoder reads too
+		var s string
+		require.NoError(t, NewDecoderWithOptions(bytes.NewReader(mustFrame(t, "x")), WithDecoderFraming(LengthPrefixed)).Decode(&s))
+		assert.Equal(t, "x", s)
+	})
+
+	t.Run("misuse", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewEncodingWriter(&buf)
+		_, err := w.Write([]byte("not a document"))
+		assert.ErrorContains(t, err, "not an encoded document")
+
+		require.NoError(t, w.Close())
+		assert.ErrorContains(t, w.WriteValue("late"), "closed")
+		_, err = w.Write(mustMarshal(t, "late"))
+		assert.ErrorContains(t, err, "closed")
+		assert.Zero(t, buf.Len())
+
+		_, err = NewDecodingReader(bytes.NewReader(mustMarshal(t, "raw"))).ReadValue()
+		assert.ErrorContains(t, err, "expected length-prefixed frames")
+	})
+}
+
+// mustFrame returns v encoded as written by an EncodingWriter
+func mustFrame(t *testing.T, v any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, NewEncodingWriter(&buf).WriteValue(v))
+	return buf.Bytes()
+}
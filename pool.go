@@ -0,0 +1,58 @@
+package bogo
+
+import "sync"
+
+// DecoderPool hands out decoders sharing one base configuration, so servers
+// can tailor a decoder to each request, e.g. with WithSelectiveFields, without
+// constructing a new one every time. It is safe for concurrent use.
+type DecoderPool struct {
+	base Decoder
+	pool sync.Pool
+}
+
+// NewDecoderPool creates a pool of decoders configured with options
+func NewDecoderPool(options ...DecoderOption) *DecoderPool {
+	p := &DecoderPool{base: *NewConfigurableDecoder(options...)}
+	p.pool.New = func() any {
+		d := p.base
+		return &d
+	}
+	return p
+}
+
+// Acquire returns a decoder with the pool's configuration and options applied
+// on top of it. The decoder must not be used concurrently, and should be
+// returned with Release once the request is done with it.
+func (p *DecoderPool) Acquire(options ...DecoderOption) *Decoder {
+	d := p.pool.Get().(*Decoder)
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Release resets d to the pool's configuration, dropping any options and
+// OnField subscriptions added since Acquire, and returns it to the pool. d
+// must not be used afterwards.
+func (p *DecoderPool) Release(d *Decoder) {
+	if d == nil {
+		return
+	}
+	*d = p.base
+	p.pool.Put(d)
+}
+
+// Decode decodes data with a pooled decoder, applying options for this call only
+func (p *DecoderPool) Decode(data []byte, options ...DecoderOption) (any, error) {
+	d := p.Acquire(options...)
+	defer p.Release(d)
+	return d.Decode(data)
+}
+
+// Unmarshal decodes data into v with a pooled decoder, applying options for
+// this call only
+func (p *DecoderPool) Unmarshal(data []byte, v any, options ...DecoderOption) error {
+	d := p.Acquire(options...)
+	defer p.Release(d)
+	return d.Unmarshal(data, v)
+}
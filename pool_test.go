@@ -0,0 +1,61 @@
+package bogo
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderPool(t *testing.T) {
+	data, err := Marshal(map[string]any{"id": int64(1), "name": "Ada", "email": "ada@example.com"})
+	require.NoError(t, err)
+
+	pool := NewDecoderPool(WithDecoderMaxDepth(10))
+
+	d := pool.Acquire(WithSelectiveFields([]string{"name"}))
+	assert.Equal(t, 10, d.MaxDepth)
+	result, err := d.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Ada"}, result)
+	d.OnField("id", func(any) {})
+	pool.Release(d)
+	assert.Empty(t, d.SelectiveFields, "released decoders are reset")
+	assert.Nil(t, d.subscriptions)
+	pool.Release(nil)
+
+	result, err = pool.Decode(data)
+	require.NoError(t, err)
+	assert.Len(t, result, 3, "options of earlier requests do not carry over")
+
+	var user struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, pool.Unmarshal(data, &user, WithSelectiveFields([]string{"email"})))
+	assert.Equal(t, "ada@example.com", user.Email)
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		fields := []string{"id", "name", "email"}
+		var wg sync.WaitGroup
+		for i := 0; i < 32; i++ {
+			wg.Add(1)
+			go func(field string) {
+				defer wg.Done()
+				result, err := pool.Decode(data, WithSelectiveFields([]string{field}))
+				if assert.NoError(t, err) {
+					assert.Equal(t, []string{field}, keysOf(result.(map[string]any)), field)
+				}
+			}(fields[i%len(fields)])
+		}
+		wg.Wait()
+	})
+}
+
+func keysOf(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,220 @@
+package bogo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldProfiling configures per-path profiling in DecoderStatsCollector.
+// Profiled decodes walk the document a second time, timing every path, so
+// sampling keeps the overhead bounded on hot paths.
+type FieldProfiling struct {
+	SampleEvery int  // Profile one in every SampleEvery decodes (0 = off, 1 = every decode)
+	Allocs      bool // Also count heap allocations per path; this stops the world twice per value
+}
+
+// WithFieldProfiling enables per-path decode profiling for DecoderStatsCollector.
+// Results are reported in DecodingStats.Fields and can be written as folded
+// stacks with DecodingStats.WriteFoldedStacks. Plain decoders ignore it.
+func WithFieldProfiling(profiling FieldProfiling) DecoderOption {
+	return func(d *Decoder) {
+		d.FieldProfiling = profiling
+	}
+}
+
+// FieldProfile is the decode cost attributed to one path. Duration and Allocs
+// are self costs: time and allocations spent in nested paths are reported on
+// those paths, so values add up along a stack as a flame graph expects.
+type FieldProfile struct {
+	Path     string        // Path as used by OnField, e.g. "orders[].amount"; "" is the document root
+	Count    int64         // Number of values decoded at Path
+	Duration time.Duration // Total self time spent decoding values at Path
+	Allocs   uint64        // Total self heap allocations, when FieldProfiling.Allocs is set
+
+	frames []string
+}
+
+// ProfileMetric selects the value reported by DecodingStats.WriteFoldedStacks
+type ProfileMetric int
+
+const (
+	// ProfileDuration reports self time in nanoseconds
+	ProfileDuration ProfileMetric = iota
+	// ProfileAllocs reports self heap allocations
+	ProfileAllocs
+)
+
+// WriteFoldedStacks writes the field profiles in the folded stack format
+// ("decode;orders;[];amount 1234"), one line per path, which flamegraph.pl,
+// speedscope and pprof-compatible tools render as a flame graph.
+func (s DecodingStats) WriteFoldedStacks(w io.Writer, metric ProfileMetric) error {
+	profiles := make([]FieldProfile, 0, len(s.Fields))
+	for _, p := range s.Fields {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Path < profiles[j].Path })
+
+	bw := bufio.NewWriter(w)
+	for _, p := range profiles {
+		value := uint64(p.Duration.Nanoseconds())
+		if metric == ProfileAllocs {
+			value = p.Allocs
+		}
+		frames := append([]string{"decode"}, p.frames...)
+		for i, f := range frames {
+			frames[i] = strings.NewReplacer(";", "_", " ", "_").Replace(f)
+		}
+		if _, err := fmt.Fprintf(bw, "%s %d\n", strings.Join(frames, ";"), value); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+var profileErr = errors.New("profile error")
+
+// fieldProfiler decodes a document while attributing cost to each path
+type fieldProfiler struct {
+	fields map[string]FieldProfile
+	allocs bool
+	mem    runtime.MemStats
+}
+
+func (p *fieldProfiler) mallocs() uint64 {
+	if !p.allocs {
+		return 0
+	}
+	runtime.ReadMemStats(&p.mem)
+	return p.mem.Mallocs
+}
+
+// profile decodes the value at the start of data, records its self cost under
+// path and returns the decoded value
+func (p *fieldProfiler) profile(data []byte, path string, frames []string) (any, error) {
+	startAllocs := p.mallocs()
+	start := time.Now()
+
+	var value any
+	var err error
+	var nested time.Duration
+	var nestedAllocs uint64
+
+	// child decodes a nested value, excluding its cost from this path
+	child := func(data []byte, key string) (any, error) {
+		childStart := time.Now()
+		childAllocs := p.mallocs()
+
+		childPath, childFrames := key, append(frames[:len(frames):len(frames)], key)
+		if key == "[]" {
+			childPath = path + key
+		} else if path != "" {
+			childPath = path + "." + key
+		}
+		v, err := p.profile(data, childPath, childFrames)
+
+		nestedAllocs += p.mallocs() - childAllocs
+		nested += time.Since(childStart)
+		return v, err
+	}
+
+	switch Type(data[0]) {
+	case TypeObject:
+		value, err = p.profileObject(data[1:], child)
+	case TypeUntypedList:
+		value, err = p.profileList(data[1:], child)
+	default:
+		value, err = decodeValue(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	elapsed := time.Since(start)
+	allocs := p.mallocs() - startAllocs
+
+	fp, ok := p.fields[path]
+	if !ok {
+		fp = FieldProfile{Path: path, frames: frames}
+	}
+	fp.Count++
+	fp.Duration += elapsed - nested
+	fp.Allocs += allocs - nestedAllocs
+	p.fields[path] = fp
+
+	return value, nil
+}
+
+func (p *fieldProfiler) profileObject(data []byte, child func([]byte, string) (any, error)) (any, error) {
+	fields, err := containerPayload(data)
+	if err != nil {
+		return nil, wrapError(profileErr, err.Error())
+	}
+	if len(fields) == 1 && fields[0] == TypeNull {
+		return nil, nil
+	}
+
+	result := make(map[string]any)
+	for pos := 0; pos < len(fields); {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			return nil, wrapError(profileErr, "insufficient data for entry size")
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil {
+			return nil, wrapError(profileErr, err.Error())
+		}
+		entryStart := pos + 1 + entrySizeLen
+		entryEnd := entryStart + int(entrySize)
+		if entryEnd > len(fields) || entryStart >= entryEnd {
+			return nil, wrapError(profileErr, "insufficient data for entry content")
+		}
+		entry := fields[entryStart:entryEnd]
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			return nil, wrapError(profileErr, "insufficient data for key")
+		}
+		key := string(entry[1 : 1+keyLen])
+
+		var value any
+		if len(entry) > 1+keyLen {
+			if value, err = child(entry[1+keyLen:], key); err != nil {
+				return nil, err
+			}
+		}
+		result[key] = value
+		pos = entryEnd
+	}
+	return result, nil
+}
+
+func (p *fieldProfiler) profileList(data []byte, child func([]byte, string) (any, error)) (any, error) {
+	elements, err := containerPayload(data)
+	if err != nil {
+		return nil, wrapError(profileErr, err.Error())
+	}
+
+	result := []any{}
+	for pos := 0; pos < len(elements); {
+		size, err := getElementSize(elements[pos:])
+		if err != nil {
+			return nil, wrapError(profileErr, err.Error())
+		}
+		if pos+size > len(elements) {
+			return nil, wrapError(profileErr, "insufficient data for list element")
+		}
+		value, err := child(elements[pos:pos+size], "[]")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		pos += size
+	}
+	return result, nil
+}
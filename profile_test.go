@@ -0,0 +1,88 @@
+package bogo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldProfiling(t *testing.T) {
+	doc := map[string]any{
+		"user": map[string]any{"name": "Ada", "age": int64(36)},
+		"orders": []any{
+			map[string]any{"amount": 1.5},
+			map[string]any{"amount": 2.5},
+		},
+		"tags": []string{"a", "b"},
+	}
+	data, err := Encode(doc)
+	require.NoError(t, err)
+
+	t.Run("records every path", func(t *testing.T) {
+		decoder := NewDecoderStatsCollector(WithFieldProfiling(FieldProfiling{SampleEvery: 1}))
+		_, err := decoder.Decode(data)
+		require.NoError(t, err)
+
+		stats := decoder.GetStats()
+		assert.Equal(t, int64(1), stats.ProfiledDecodes)
+		for _, path := range []string{"", "user", "user.name", "user.age", "orders", "orders[]", "orders[].amount", "tags"} {
+			assert.Contains(t, stats.Fields, path)
+		}
+		assert.Equal(t, int64(2), stats.Fields["orders[]"].Count)
+		assert.Equal(t, int64(2), stats.Fields["orders[].amount"].Count)
+		assert.Equal(t, int64(1), stats.Fields["user.name"].Count)
+	})
+
+	t.Run("samples decodes", func(t *testing.T) {
+		decoder := NewDecoderStatsCollector(WithFieldProfiling(FieldProfiling{SampleEvery: 3}))
+		for i := 0; i < 7; i++ {
+			_, err := decoder.Decode(data)
+			require.NoError(t, err)
+		}
+		stats := decoder.GetStats()
+		assert.Equal(t, int64(3), stats.ProfiledDecodes)
+		assert.Equal(t, int64(3), stats.Fields["user.name"].Count)
+
+		decoder.ResetStats()
+		assert.Empty(t, decoder.GetStats().Fields)
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		decoder := NewDecoderStatsCollector()
+		_, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Nil(t, decoder.GetStats().Fields)
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		decoder := NewDecoderStatsCollector(WithFieldProfiling(FieldProfiling{SampleEvery: 1, Allocs: true}))
+		_, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Positive(t, decoder.GetStats().Fields["user.name"].Allocs)
+	})
+
+	t.Run("folded stacks", func(t *testing.T) {
+		decoder := NewDecoderStatsCollector(WithFieldProfiling(FieldProfiling{SampleEvery: 1}))
+		_, err := decoder.Decode(data)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, decoder.GetStats().WriteFoldedStacks(&buf, ProfileDuration))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, len(decoder.GetStats().Fields))
+		assert.Regexp(t, `^decode \d+$`, lines[0])
+		assert.Contains(t, buf.String(), "decode;orders;[];amount ")
+		assert.Contains(t, buf.String(), "decode;user;name ")
+	})
+
+	t.Run("unknown types do not fail decode", func(t *testing.T) {
+		decoder := NewDecoderStatsCollector(WithUnknownTypes(true), WithFieldProfiling(FieldProfiling{SampleEvery: 1}))
+		_, err := decoder.Decode([]byte{Version, 0x99, 0x01})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), decoder.GetStats().ProfiledDecodes)
+	})
+}
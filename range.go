@@ -0,0 +1,203 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Range is an interval between two bounds, such as a numeric range or a time
+// window. Bounds are encoded with their own type information, so any numeric or
+// temporal type may be used. Values decoded into an interface are returned as
+// Range[any], with times written as strings by TimeFormatRFC3339String parsed
+// back into time.Time; decoding into a struct field converts the bounds to T.
+type Range[T any] struct {
+	Start          T
+	End            T
+	StartInclusive bool
+	EndInclusive   bool
+}
+
+// ClosedRange returns the range [start, end]
+func ClosedRange[T any](start, end T) Range[T] {
+	return Range[T]{Start: start, End: end, StartInclusive: true, EndInclusive: true}
+}
+
+// HalfOpenRange returns the range [start, end)
+func HalfOpenRange[T any](start, end T) Range[T] {
+	return Range[T]{Start: start, End: end, StartInclusive: true}
+}
+
+func (r Range[T]) String() string {
+	open, closed := "(", ")"
+	if r.StartInclusive {
+		open = "["
+	}
+	if r.EndInclusive {
+		closed = "]"
+	}
+	return fmt.Sprintf("%s%v, %v%s", open, r.Start, r.End, closed)
+}
+
+// rangeValue is implemented by every Range instantiation so the encoder can
+// handle ranges without knowing their type parameter
+type rangeValue interface {
+	rangeBounds() (start, end any, flags byte)
+}
+
+// rangeAssigner is implemented by *Range[T] to convert decoded bounds into T
+type rangeAssigner interface {
+	assignRange(r Range[any], d *Decoder) error
+}
+
+const (
+	rangeStartInclusive byte = 1 << iota
+	rangeEndInclusive
+)
+
+func (r Range[T]) rangeBounds() (start, end any, flags byte) {
+	if r.StartInclusive {
+		flags |= rangeStartInclusive
+	}
+	if r.EndInclusive {
+		flags |= rangeEndInclusive
+	}
+	return r.Start, r.End, flags
+}
+
+func (r *Range[T]) assignRange(src Range[any], d *Decoder) error {
+	if err := assignValueToField(src.Start, reflect.ValueOf(&r.Start).Elem(), d); err != nil {
+		return fmt.Errorf("range start: %w", err)
+	}
+	if err := assignValueToField(src.End, reflect.ValueOf(&r.End).Elem(), d); err != nil {
+		return fmt.Errorf("range end: %w", err)
+	}
+	r.StartInclusive = src.StartInclusive
+	r.EndInclusive = src.EndInclusive
+	return nil
+}
+
+var rangeEncErr = errors.New("range encoder error")
+
+// encodeRange encodes a range as TypeRange + Flags + Start + End, using
+// encodeBound to encode each bound with its type header
+func encodeRange(r rangeValue, encodeBound func(any) ([]byte, error)) ([]byte, error) {
+	start, end, flags := r.rangeBounds()
+
+	startData, err := encodeBound(start)
+	if err != nil {
+		return nil, wrapError(rangeEncErr, "failed to encode start", err.Error())
+	}
+	endData, err := encodeBound(end)
+	if err != nil {
+		return nil, wrapError(rangeEncErr, "failed to encode end", err.Error())
+	}
+	startType, endType := Type(startData[0]).baseType(), Type(endData[0]).baseType()
+	if !isRangeBound(startData) || !isRangeBound(endData) {
+		return nil, wrapError(rangeEncErr, fmt.Sprintf("unsupported bound types %s and %s", startType, endType))
+	}
+
+	buf := make([]byte, 0, 2+len(startData)+len(endData))
+	buf = append(buf, TypeRange, flags)
+	buf = append(buf, startData...)
+	buf = append(buf, endData...)
+	return buf, nil
+}
+
+// isRangeBound reports whether the encoded value data can bound a range. Times
+// written by TimeFormatRFC3339String are strings, and are the only strings
+// accepted.
+func isRangeBound(data []byte) bool {
+	typ := Type(data[0]).baseType()
+	if typ != TypeString {
+		return isRangeBoundType(typ)
+	}
+	v, err := decodeValue(data)
+	if err != nil {
+		return false
+	}
+	_, ok := timeString(v)
+	return ok
+}
+
+// timeString returns v as a time when it is an RFC 3339 string
+func timeString(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+// isRangeBoundType reports whether values of type t, a base type rather than
+// a compact form, are ordered and can bound a range
+func isRangeBoundType(t Type) bool {
+	switch t {
+	case TypeNull, TypeByte, TypeInt, TypeUint, TypeFloat, TypeNumeric, TypeTimestamp, TypeTimestampNanos, TypeZonedTimestamp, TypeDate, TypeTimeOfDay, TypeDuration:
+		return true
+	}
+	return false
+}
+
+func decodeRange(data []byte) (Range[any], error) {
+	if len(data) < 1 {
+		return Range[any]{}, fmt.Errorf("range decode error: insufficient data for flags")
+	}
+	flags := data[0]
+	data = data[1:]
+
+	startSize, err := getElementSize(data)
+	if err != nil {
+		return Range[any]{}, fmt.Errorf("range decode error: %w", err)
+	}
+	if len(data) < startSize {
+		return Range[any]{}, fmt.Errorf("range decode error: insufficient data for start")
+	}
+	if len(data) == startSize {
+		return Range[any]{}, fmt.Errorf("range decode error: insufficient data for end")
+	}
+	start, err := decodeValue(data[:startSize])
+	if err != nil {
+		return Range[any]{}, fmt.Errorf("range decode error: %w", err)
+	}
+
+	end, err := decodeValue(data[startSize:])
+	if err != nil {
+		return Range[any]{}, fmt.Errorf("range decode error: %w", err)
+	}
+
+	// Bounds written as strings are times, see isRangeBound
+	if t, ok := timeString(start); ok {
+		start = t
+	}
+	if t, ok := timeString(end); ok {
+		end = t
+	}
+	return Range[any]{
+		Start:          start,
+		End:            end,
+		StartInclusive: flags&rangeStartInclusive != 0,
+		EndInclusive:   flags&rangeEndInclusive != 0,
+	}, nil
+}
+
+// rangeSize returns the encoded size of a range value, including its type byte
+func rangeSize(data []byte) (int, error) {
+	if len(data) < 2 {
+		return 0, errors.New("insufficient data for range flags")
+	}
+	startSize, err := getElementSize(data[2:])
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 2+startSize {
+		return 0, errors.New("insufficient data for range start")
+	}
+	endSize, err := getElementSize(data[2+startSize:])
+	if err != nil {
+		return 0, err
+	}
+	return 2 + startSize + endSize, nil
+}
@@ -0,0 +1,138 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRange(t *testing.T) {
+	t.Run("numeric range decodes as Range[any]", func(t *testing.T) {
+		data, err := Encode(HalfOpenRange(10, 20))
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeRange), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, Range[any]{Start: int64(10), End: int64(20), StartInclusive: true}, decoded)
+		assert.Equal(t, "[10, 20)", decoded.(Range[any]).String())
+	})
+
+	t.Run("struct fields convert bounds", func(t *testing.T) {
+		type Shift struct {
+			Worker string           `json:"worker"`
+			Window Range[time.Time] `json:"window"`
+			Rates  Range[float64]   `json:"rates"`
+			Slots  *Range[int]      `json:"slots"`
+			Any    Range[any]       `json:"any"`
+		}
+		start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+		shift := Shift{
+			Worker: "sam",
+			Window: HalfOpenRange(start, start.Add(8*time.Hour)),
+			Rates:  ClosedRange(12.5, 30.0),
+			Slots:  &Range[int]{Start: 1, End: 4, EndInclusive: true},
+			Any:    ClosedRange[any](int64(-1), uint64(1)),
+		}
+
+		data, err := Marshal(shift)
+		require.NoError(t, err)
+
+		var decoded Shift
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.True(t, shift.Window.Start.Equal(decoded.Window.Start))
+		assert.True(t, shift.Window.End.Equal(decoded.Window.End))
+		assert.True(t, decoded.Window.StartInclusive)
+		assert.False(t, decoded.Window.EndInclusive)
+		assert.Equal(t, shift.Rates, decoded.Rates)
+		assert.Equal(t, shift.Slots, decoded.Slots)
+		assert.Equal(t, shift.Any, decoded.Any)
+	})
+
+	t.Run("ranges inside lists", func(t *testing.T) {
+		data, err := Encode([]any{ClosedRange(1.5, 2.5), "after"})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, []any{Range[any]{Start: 1.5, End: 2.5, StartInclusive: true, EndInclusive: true}, "after"}, decoded)
+	})
+
+	t.Run("unordered bounds are rejected", func(t *testing.T) {
+		_, err := Encode(ClosedRange("a", "z"))
+		assert.ErrorIs(t, err, rangeEncErr)
+	})
+
+	t.Run("numeric widths", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithNumericWidths(true))
+		data, err := encoder.Encode(ClosedRange[int16](1, 4))
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, ClosedRange[any](int16(1), int16(4)), decoded)
+
+		var r Range[int16]
+		require.NoError(t, Unmarshal(data, &r))
+		assert.Equal(t, ClosedRange[int16](1, 4), r)
+	})
+
+	t.Run("compact integers", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithCompactIntegers(true))
+		data, err := encoder.Encode(HalfOpenRange(-2, 20))
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, HalfOpenRange[any](int64(-2), int64(20)), decoded)
+
+		_, err = encoder.Encode(ClosedRange[any](1, "z"))
+		assert.ErrorContains(t, err, "unsupported bound types <int> and <string>")
+	})
+
+	t.Run("zoned times", func(t *testing.T) {
+		paris, err := time.LoadLocation("Europe/Paris")
+		require.NoError(t, err)
+		start := time.Date(2024, 5, 1, 9, 0, 0, 0, paris)
+		window := HalfOpenRange(start, start.Add(8*time.Hour))
+
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatZoned))
+		data, err := encoder.Encode(window)
+		require.NoError(t, err)
+
+		var decoded Range[time.Time]
+		require.NoError(t, Unmarshal(data, &decoded))
+		// Bounds are the same instants, in the zone they were written in
+		assert.True(t, window.Start.Equal(decoded.Start))
+		assert.True(t, window.End.Equal(decoded.End))
+		assert.Equal(t, "Europe/Paris", decoded.Start.Location().String())
+		assert.True(t, decoded.StartInclusive)
+		assert.False(t, decoded.EndInclusive)
+	})
+
+	t.Run("times written as strings", func(t *testing.T) {
+		start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.FixedZone("", 2*3600))
+		window := ClosedRange(start, start.Add(90*time.Minute))
+
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatRFC3339String))
+		data, err := encoder.Encode(window)
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		r := decoded.(Range[any])
+		assert.True(t, window.Start.Equal(r.Start.(time.Time)))
+		assert.True(t, window.End.Equal(r.End.(time.Time)))
+		assert.True(t, r.Start.(time.Time).Before(r.End.(time.Time)))
+
+		var typed Range[time.Time]
+		require.NoError(t, Unmarshal(data, &typed))
+		assert.True(t, window.Start.Equal(typed.Start))
+		assert.True(t, window.End.Equal(typed.End))
+
+		_, err = encoder.Encode(ClosedRange("2024-05-01", "2024-05-02"))
+		assert.ErrorIs(t, err, rangeEncErr, "only time strings bound ranges")
+	})
+}
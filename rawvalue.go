@@ -0,0 +1,151 @@
+package bogo
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// RawValue is an encoded document, the version byte followed by one value,
+// kept as bytes. Like json.RawMessage it lets routers and proxies forward
+// parts of a payload without decoding them: Marshal checks that a RawValue
+// holds a single well-formed value and embeds it verbatim, a nil RawValue
+// encoding as null, and Unmarshal stores a copy of the encoded value.
+//
+// RawValue fields of the struct passed to Unmarshal, and of structs nested
+// in its fields, are captured from the document without being decoded.
+// Elsewhere, such as in list elements or map values, a RawValue receives the
+// value re-encoded from its decoded form, as an Unmarshaler does.
+type RawValue []byte
+
+var rawValueType = reflect.TypeFor[RawValue]()
+
+// MarshalBogo returns r, or an encoded null when r is nil
+func (r RawValue) MarshalBogo() ([]byte, error) {
+	if r == nil {
+		return []byte{Version, TypeNull}, nil
+	}
+	return r, nil
+}
+
+// UnmarshalBogo stores a copy of data in r
+func (r *RawValue) UnmarshalBogo(data []byte) error {
+	if r == nil {
+		return fmt.Errorf("bogo: UnmarshalBogo on nil *RawValue")
+	}
+	*r = append((*r)[:0], data...)
+	return nil
+}
+
+// rawPlan maps the keys of an object to the struct fields that capture raw
+// values: nil for a RawValue field, the plan of its own fields for a nested
+// struct holding RawValues
+type rawPlan map[string]rawPlan
+
+// rawTypes caches whether a struct type has RawValue fields at any depth
+var rawTypes sync.Map // reflect.Type -> bool
+
+// rawPlanOf returns the plan for decoding into v, or nil when v does not
+// point to a struct with RawValue fields
+func (d *Decoder) rawPlanOf(v any) rawPlan {
+	if d.TypedValues || d.Salvage || len(d.SelectiveFields) > 0 {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	t = t.Elem()
+	hasRaw, ok := rawTypes.Load(t)
+	if !ok {
+		hasRaw = buildRawPlan(t, d.TagName, map[reflect.Type]bool{}) != nil
+		rawTypes.Store(t, hasRaw)
+	}
+	if !hasRaw.(bool) {
+		return nil
+	}
+	// Plans are built per call, as the tag of a type can be registered later
+	return buildRawPlan(t, d.TagName, map[reflect.Type]bool{})
+}
+
+// buildRawPlan returns the plan of struct type t, or nil when it has no
+// RawValue fields. visiting guards against recursive types.
+func buildRawPlan(t reflect.Type, fallbackTag string, visiting map[reflect.Type]bool) rawPlan {
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	tagName := structTagName(t, fallbackTag)
+	var plan rawPlan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := getStructFieldName(field, tagName)
+		if name == "-" {
+			continue
+		}
+
+		ft := field.Type
+		if ft == rawValueType {
+			if plan == nil {
+				plan = rawPlan{}
+			}
+			plan[name] = nil
+			continue
+		}
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+		if sub := buildRawPlan(ft, fallbackTag, visiting); sub != nil {
+			if plan == nil {
+				plan = rawPlan{}
+			}
+			plan[name] = sub
+		}
+	}
+	return plan
+}
+
+// decodeRaw decodes value, starting at its type byte, keeping the values of
+// the RawValue fields of plan encoded
+func (d *Decoder) decodeRaw(value []byte, plan rawPlan) (any, error) {
+	if Type(value[0]) != TypeObject {
+		return d.decode(value)
+	}
+	fields, err := objectFields(value)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, nil
+	}
+	obj := make(map[string]any, countEntries(fields))
+	var fieldErr error
+	err = walkEntries(fields, func(_, _ int, key, v []byte) bool {
+		k := d.strings().key(key)
+		sub, ok := plan[k]
+		switch {
+		case !ok:
+			obj[k], fieldErr = d.decode(v)
+		case sub == nil:
+			obj[k] = append(RawValue{Version}, v...)
+		default:
+			obj[k], fieldErr = d.decodeRaw(v, sub)
+		}
+		if fieldErr != nil {
+			fieldErr = fmt.Errorf("bogo decode error: field %s: %w", k, fieldErr)
+		}
+		return fieldErr == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, fieldErr
+}
@@ -0,0 +1,126 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawValue(t *testing.T) {
+	payload, err := Marshal(map[string]any{"sku": "A-1", "qty": int64(3), "at": time.UnixMilli(1700000000000)})
+	require.NoError(t, err)
+
+	type envelope struct {
+		Route   string   `json:"route"`
+		Payload RawValue `json:"payload"`
+		Missing RawValue `json:"missing"`
+	}
+
+	t.Run("embeds the bytes verbatim", func(t *testing.T) {
+		data, err := Marshal(envelope{Route: "orders", Payload: payload})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), decoded.(map[string]any)["payload"].(map[string]any)["qty"])
+
+		var captured envelope
+		require.NoError(t, Unmarshal(data, &captured))
+		assert.Equal(t, "orders", captured.Route)
+		assert.Equal(t, RawValue(payload), captured.Payload, "nested timestamps keep their type")
+		assert.Equal(t, RawValue{Version, TypeNull}, captured.Missing)
+	})
+
+	t.Run("captures a copy", func(t *testing.T) {
+		data, err := Marshal(envelope{Route: "orders", Payload: payload})
+		require.NoError(t, err)
+		var captured envelope
+		require.NoError(t, Unmarshal(data, &captured))
+		clear(data)
+		assert.Equal(t, RawValue(payload), captured.Payload)
+	})
+
+	t.Run("nested structs", func(t *testing.T) {
+		type outer struct {
+			Name  string    `json:"name"`
+			Inner envelope  `json:"inner"`
+			Ptr   *envelope `json:"ptr"`
+		}
+		data, err := Marshal(outer{Name: "x", Inner: envelope{Payload: payload}, Ptr: &envelope{Payload: payload}})
+		require.NoError(t, err)
+
+		var decoded outer
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, RawValue(payload), decoded.Inner.Payload)
+		require.NotNil(t, decoded.Ptr)
+		assert.Equal(t, RawValue(payload), decoded.Ptr.Payload)
+	})
+
+	t.Run("elsewhere values are re-encoded", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"items": []any{"a", int64(1)}})
+		require.NoError(t, err)
+		var v struct {
+			Items []RawValue `json:"items"`
+		}
+		require.NoError(t, Unmarshal(data, &v))
+		require.Len(t, v.Items, 2)
+		s, err := Decode(v.Items[0])
+		require.NoError(t, err)
+		assert.Equal(t, "a", s)
+	})
+
+	t.Run("top level", func(t *testing.T) {
+		var raw RawValue
+		require.NoError(t, Unmarshal(payload, &raw))
+		assert.Equal(t, RawValue(payload), raw)
+
+		data, err := Marshal(raw)
+		require.NoError(t, err)
+		assert.Equal(t, payload, data)
+
+		data, err = Marshal(RawValue(nil))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, TypeNull}, data)
+	})
+
+	t.Run("malformed values fail to encode", func(t *testing.T) {
+		_, err := Marshal(envelope{Payload: RawValue{Version, TypeString, 1, 9, 'a'}})
+		assert.ErrorContains(t, err, "malformed")
+	})
+}
+
+func BenchmarkRawValue(b *testing.B) {
+	items := make([]any, 100)
+	for i := range items {
+		items[i] = map[string]any{"id": int64(i), "name": "item", "tags": []any{"a", "b"}}
+	}
+	payload, _ := Marshal(items)
+	data, _ := Marshal(map[string]any{"route": "orders", "payload": RawValue(payload)})
+
+	b.Run("captured", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var v struct {
+				Route   string   `json:"route"`
+				Payload RawValue `json:"payload"`
+			}
+			if err := Unmarshal(data, &v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("decoded", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var v struct {
+				Route   string `json:"route"`
+				Payload []any  `json:"payload"`
+			}
+			if err := Unmarshal(data, &v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var unionErr = errors.New("bogo union error")
+
+// A value of a registered type is laid out as
+//
+//	TypeUnion | size | name | value
+//
+// where name is the name the type was registered under, preceded by its size,
+// a uvarint with its 1-byte length, and value is the encoded value.
+
+// Keys of the object values of registered types were encoded as before
+// TypeUnion, which are still decoded as unions
+const (
+	registryTypeKey  = "$type"
+	registryValueKey = "$value"
+)
+
+var (
+	registryMu sync.RWMutex
+	// registeredTypes and registeredNames map the types registered with
+	// Register to their names and back
+	registeredTypes = map[reflect.Type]string{}
+	registeredNames = map[string]reflect.Type{}
+)
+
+// Register records the concrete type of value under name, as gob.RegisterName
+// does, so that polymorphic payloads decode into their original types. Values
+// of the type are encoded as a union of name, the discriminator, and the
+// value, and Unmarshal rebuilds a value of the type wherever a union is
+// assigned to an interface, such as a struct field of type Event or any.
+// Assigned to the type itself, the value is unwrapped; Decode returns a
+// Union. Types bogo encodes natively, such as time.Time, are not tagged. A
+// pointer type is distinct from the type it points to.
+//
+// Register panics when the name or the type is already registered to
+// something else. It is meant to be called from init functions.
+func Register(name string, value any) {
+	if name == "" {
+		panic("bogo: Register: empty name")
+	}
+	t := reflect.TypeOf(value)
+	if t == nil {
+		panic("bogo: Register: nil value")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if other, ok := registeredNames[name]; ok && other != t {
+		panic(fmt.Sprintf("bogo: Register: name %q already registered for %s", name, other))
+	}
+	if other, ok := registeredTypes[t]; ok && other != name {
+		panic(fmt.Sprintf("bogo: Register: type %s already registered as %q", t, other))
+	}
+	registeredNames[name] = t
+	registeredTypes[t] = name
+}
+
+// registeredName returns the name t was registered under
+func registeredName(t reflect.Type) (string, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	name, ok := registeredTypes[t]
+	return name, ok
+}
+
+// Union is a value tagged with the name of its type, as Decode returns the
+// values of types registered with Register. Value is decoded as the values of
+// unregistered types are. A Union encodes back to the same union, so it can
+// be passed on by code that does not know the type.
+type Union struct {
+	Name  string
+	Value any
+}
+
+var unionType = reflect.TypeFor[Union]()
+
+// encodeRegistered encodes v, of a type registered as name, with its name
+func (e *Encoder) encodeRegistered(name string, v any) ([]byte, error) {
+	value, ok, err := encodeCustom(v)
+	if !ok {
+		value, err = e.encodeReflected(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildUnion(name, value)
+}
+
+// buildUnion returns the union of name and the encoded value
+func buildUnion(name string, value []byte) ([]byte, error) {
+	if name == "" {
+		return nil, wrapError(unionErr, "empty name")
+	}
+	buf := make([]byte, 0, 2+maxStorageByteLength+len(name)+len(value))
+	buf = appendTypedHeader(buf, TypeUnion, uint64(len(name)))
+	return append(append(buf, name...), value...), nil
+}
+
+// unionName returns the name of the union data, after its type byte, and the
+// offset of its value
+func unionName(data []byte) ([]byte, int, error) {
+	if len(data) == 0 {
+		return nil, 0, wrapError(unionErr, "insufficient data for name")
+	}
+	name, err := containerPayload(data)
+	if err != nil {
+		return nil, 0, wrapError(unionErr, err.Error())
+	}
+	offset := 1 + int(data[0]) + len(name)
+	if offset >= len(data) {
+		return nil, 0, wrapError(unionErr, "insufficient data for value")
+	}
+	return name, offset, nil
+}
+
+// unionSize returns the size of the union data, after its type byte
+func unionSize(data []byte) (int, error) {
+	_, offset, err := unionName(data)
+	if err != nil {
+		return 0, err
+	}
+	size, err := getElementSize(data[offset:])
+	if err != nil {
+		return 0, err
+	}
+	return offset + size, nil
+}
+
+// decodeUnionWith decodes the union data, after its type byte, decoding the
+// value with decode
+func decodeUnionWith(data []byte, decode func([]byte) (any, error)) (any, error) {
+	name, offset, err := unionName(data)
+	if err != nil {
+		return nil, err
+	}
+	v, err := decode(data[offset:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", unionErr, name, err)
+	}
+	return Union{Name: string(name), Value: v}, nil
+}
+
+// registeredValue reports whether value is a Union, or the object registered
+// types were encoded as before unions, returning its name, its type, which is
+// nil when the name is not registered, and the wrapped value
+func registeredValue(value any) (string, reflect.Type, any, bool) {
+	var name string
+	var inner any
+	switch v := value.(type) {
+	case Union:
+		name, inner = v.Name, v.Value
+	case map[string]any:
+		if len(v) != 2 {
+			return "", nil, nil, false
+		}
+		var ok bool
+		if name, ok = v[registryTypeKey].(string); !ok {
+			return "", nil, nil, false
+		}
+		if inner, ok = v[registryValueKey]; !ok {
+			return "", nil, nil, false
+		}
+	default:
+		return "", nil, nil, false
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return name, registeredNames[name], inner, true
+}
+
+// assignRegistered assigns a value encoded with its registered name to dest,
+// reporting whether value is one. Interfaces receive a value of the
+// registered type and other destinations the wrapped value.
+func assignRegistered(value any, dest reflect.Value, d *Decoder) (bool, error) {
+	name, t, inner, ok := registeredValue(value)
+	if !ok || dest.Type() == unionType {
+		return false, nil
+	}
+	_, isUnion := value.(Union)
+	if dest.Kind() != reflect.Interface {
+		if t == nil && !isUnion {
+			// An object that happens to look like a registered value
+			return false, nil
+		}
+		return true, assignValueToField(inner, dest, d)
+	}
+	if t == nil {
+		if dest.NumMethod() == 0 {
+			return false, nil
+		}
+		return true, fmt.Errorf("bogo: type %q is not registered", name)
+	}
+	if !t.AssignableTo(dest.Type()) {
+		return true, fmt.Errorf("bogo: registered type %s does not implement %s", t, dest.Type())
+	}
+	v := reflect.New(t).Elem()
+	if err := assignValueToField(inner, v, d); err != nil {
+		return true, err
+	}
+	dest.Set(v)
+	return true, nil
+}
@@ -0,0 +1,174 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shape interface{ area() float64 }
+
+type square struct {
+	Side float64 `json:"side"`
+}
+
+func (s square) area() float64 { return s.Side * s.Side }
+
+type rect struct {
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+func (r *rect) area() float64 { return r.W * r.H }
+
+type unregisteredShape struct{}
+
+func (unregisteredShape) area() float64 { return 0 }
+
+func init() {
+	Register("test.square", square{})
+	Register("test.rect", &rect{})
+	Register("test.label", "")
+}
+
+func TestRegister(t *testing.T) {
+	type drawing struct {
+		Main   shape          `json:"main"`
+		Shapes []shape        `json:"shapes"`
+		Named  map[string]any `json:"named"`
+		Any    any            `json:"any"`
+		Plain  square         `json:"plain"`
+	}
+	in := drawing{
+		Main:   square{Side: 2},
+		Shapes: []shape{&rect{W: 2, H: 3}, square{Side: 1}},
+		Named:  map[string]any{"big": square{Side: 10}},
+		Any:    &rect{W: 1, H: 1},
+		Plain:  square{Side: 4},
+	}
+
+	data, err := Marshal(in)
+	require.NoError(t, err)
+
+	var out drawing
+	require.NoError(t, Unmarshal(data, &out))
+	assert.Equal(t, in.Main, out.Main)
+	assert.Equal(t, in.Shapes, out.Shapes)
+	assert.Equal(t, in.Any, out.Any)
+	assert.Equal(t, in.Plain, out.Plain, "concrete destinations unwrap the value")
+	assert.Equal(t, Union{Name: "test.square", Value: map[string]any{"side": 10.0}},
+		out.Named["big"], "values nested in decoded maps stay unions")
+
+	t.Run("Decode keeps the tag", func(t *testing.T) {
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		main := decoded.(map[string]any)["main"]
+		assert.Equal(t, Union{Name: "test.square", Value: map[string]any{"side": 2.0}}, main)
+
+		raw, err := LookupField(data, "main")
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUnion), raw[0])
+
+		again, err := Marshal(main)
+		require.NoError(t, err)
+		var s shape
+		require.NoError(t, Unmarshal(again, &s))
+		assert.Equal(t, in.Main, s, "unions encode back as they were")
+	})
+
+	t.Run("objects written before unions", func(t *testing.T) {
+		legacy := map[string]any{registryTypeKey: "test.rect", registryValueKey: map[string]any{"w": 2.0, "h": 3.0}}
+		data, err := Marshal(map[string]any{"main": legacy})
+		require.NoError(t, err)
+		var typed struct {
+			Main shape `json:"main"`
+		}
+		require.NoError(t, Unmarshal(data, &typed))
+		assert.Equal(t, &rect{W: 2, H: 3}, typed.Main)
+	})
+
+	t.Run("unions of unregistered names", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"main": Union{Name: "test.circle", Value: map[string]any{"side": 1.0}}})
+		require.NoError(t, err)
+
+		var typed struct {
+			Main shape `json:"main"`
+		}
+		assert.ErrorContains(t, Unmarshal(data, &typed), `type "test.circle" is not registered`)
+
+		var concrete struct {
+			Main square `json:"main"`
+		}
+		require.NoError(t, Unmarshal(data, &concrete))
+		assert.Equal(t, square{Side: 1}, concrete.Main)
+
+		_, err = Marshal(Union{Value: 1})
+		assert.ErrorContains(t, err, "empty name")
+	})
+
+	t.Run("truncated unions", func(t *testing.T) {
+		assertRejected(t, []byte{Version, byte(TypeUnion)})
+		assertRejected(t, []byte{Version, byte(TypeUnion), 1})
+		assertRejected(t, []byte{Version, byte(TypeUnion), 1, 9, 'a'})
+	})
+
+	t.Run("top-level interfaces", func(t *testing.T) {
+		data, err := Marshal(&rect{W: 5, H: 1})
+		require.NoError(t, err)
+		var s shape
+		require.NoError(t, Unmarshal(data, &s))
+		assert.Equal(t, 5.0, s.area())
+	})
+
+	t.Run("native types are not tagged", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"v": "plain"})
+		require.NoError(t, err)
+		var v struct {
+			V any `json:"v"`
+		}
+		require.NoError(t, Unmarshal(data, &v))
+		assert.Equal(t, "plain", v.V)
+	})
+
+	t.Run("unregistered names", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"main": map[string]any{registryTypeKey: "test.circle", registryValueKey: 1.0}})
+		require.NoError(t, err)
+
+		var typed struct {
+			Main shape `json:"main"`
+		}
+		assert.ErrorContains(t, Unmarshal(data, &typed), `type "test.circle" is not registered`)
+
+		var untyped struct {
+			Main any `json:"main"`
+		}
+		require.NoError(t, Unmarshal(data, &untyped))
+		assert.IsType(t, map[string]any{}, untyped.Main)
+	})
+
+	t.Run("types not implementing the interface", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"main": map[string]any{registryTypeKey: "test.label", registryValueKey: "x"}})
+		require.NoError(t, err)
+		var typed struct {
+			Main shape `json:"main"`
+		}
+		assert.ErrorContains(t, Unmarshal(data, &typed), "does not implement")
+	})
+
+	t.Run("unregistered types encode untagged", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"s": unregisteredShape{}})
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{}, decoded.(map[string]any)["s"])
+	})
+}
+
+func TestRegisterConflicts(t *testing.T) {
+	assert.NotPanics(t, func() { Register("test.square", square{}) }, "registering again is allowed")
+	assert.Panics(t, func() { Register("test.square", rect{}) })
+	assert.Panics(t, func() { Register("test.other", square{}) })
+	assert.Panics(t, func() { Register("", square{}) })
+	assert.Panics(t, func() { Register("test.nil", nil) })
+}
@@ -0,0 +1,304 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var repairErr = errors.New("bogo repair error")
+
+// Fix describes one change made by Repair
+type Fix struct {
+	Offset  int    // Offset in the input of the bytes that were changed
+	Path    string // Location of the repaired value, "" for the top-level value
+	Message string
+}
+
+func (f Fix) String() string {
+	path := f.Path
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("%s (offset %d): %s", path, f.Offset, f.Message)
+}
+
+// Repair normalizes a document written by a faulty producer and reports what
+// it changed. Trailing garbage is removed, container and entry sizes that
+// disagree with their content are corrected, padding inside containers is
+// dropped, and truncated containers keep the entries and elements that were
+// written in full. Values are self-describing, so when a size prefix and the
+// value it frames disagree, the value wins.
+//
+// Well-formed documents are returned unchanged with no fixes. Repair fails
+// when the document cannot be read at all, such as an unknown version or a
+// truncated top-level scalar.
+func Repair(data []byte) ([]byte, []Fix, error) {
+	var fixes []Fix
+	out, err := editDocument(data, func(doc []byte) ([]byte, error) {
+		repaired, docFixes, err := repairDocument(doc)
+		for _, f := range docFixes {
+			f.Offset += len(data) - len(doc) // doc ends data, after its prefix
+			fixes = append(fixes, f)
+		}
+		return repaired, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, fixes, nil
+}
+
+// repairDocument is Repair on the plain document data
+func repairDocument(data []byte) ([]byte, []Fix, error) {
+	if len(data) < 2 {
+		return nil, nil, wrapError(repairErr, "insufficient data, need at least 2 bytes for version and type")
+	}
+	if data[0] != Version {
+		return nil, nil, fmt.Errorf("%w: %w", repairErr, versionMismatch(data[0]))
+	}
+
+	r := &repairer{}
+	value, n, err := r.value(data[1:], 1, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if trailing := len(data) - 1 - n; trailing > 0 {
+		r.fix(1+n, "", "removed %d trailing bytes", trailing)
+	}
+	return append([]byte{Version}, value...), r.fixes, nil
+}
+
+type repairer struct {
+	fixes []Fix
+}
+
+func (r *repairer) fix(offset int, path, format string, args ...any) {
+	r.fixes = append(r.fixes, Fix{Offset: offset, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// value repairs the value at the start of data, which begins at offset in the
+// input, and returns the repaired encoding and the number of input bytes used
+func (r *repairer) value(data []byte, offset int, path string) ([]byte, int, error) {
+	if len(data) == 0 {
+		return nil, 0, wrapError(repairErr, "insufficient data for value")
+	}
+
+	switch Type(data[0]) {
+	case TypeObject:
+		return r.object(data, offset, path)
+	case TypeUntypedList:
+		return r.list(data, offset, path)
+	case TypeTypedList:
+		return r.typedList(data, offset, path)
+	}
+
+	size, err := getElementSize(data)
+	if err != nil {
+		return nil, 0, wrapError(repairErr, err.Error())
+	}
+	if size <= 0 || size > len(data) {
+		return nil, 0, wrapError(repairErr, fmt.Sprintf("value truncated, %d of %d bytes present", len(data), size))
+	}
+	return data[:size], size, nil
+}
+
+// payload returns the payload of the container at the start of data and the
+// number of input bytes the container spans. A size exceeding the available
+// data is clamped to it.
+func (r *repairer) payload(data []byte, offset int, path string) ([]byte, int, int, error) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return nil, 0, 0, wrapError(repairErr, "insufficient data for container size")
+	}
+	sizeLen := int(data[1])
+	size, err := decodeUint(data[2 : 2+sizeLen])
+	if err != nil {
+		return nil, 0, 0, wrapError(repairErr, err.Error())
+	}
+	start := 2 + sizeLen
+	if size > uint64(len(data)-start) {
+		r.fix(offset, path, "container size %d exceeds the %d bytes available", size, len(data)-start)
+		return data[start:], start, len(data), nil
+	}
+	return data[start : start+int(size)], start, start + int(size), nil
+}
+
+func (r *repairer) object(data []byte, offset int, path string) ([]byte, int, error) {
+	payload, start, n, err := r.payload(data, offset, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(payload) == 1 && payload[0] == TypeNull {
+		return data[:n], n, nil
+	}
+
+	var fields []byte
+	for pos := 0; pos < len(payload); {
+		entryOffset := offset + start + pos
+		entrySizeLen := int(payload[pos])
+		entryStart := pos + 1 + entrySizeLen
+		if entryStart >= len(payload) {
+			r.fix(entryOffset, path, "dropped %d bytes of an incomplete entry", len(payload)-pos)
+			break
+		}
+		entrySize, err := decodeUint(payload[pos+1 : entryStart])
+		keyLen := int(payload[entryStart])
+		valueStart := entryStart + 1 + keyLen
+		if err != nil || valueStart > len(payload) {
+			r.fix(entryOffset, path, "dropped %d bytes of an incomplete entry", len(payload)-pos)
+			break
+		}
+		key := string(payload[entryStart+1 : valueStart])
+		childPath := joinPath(path, key)
+
+		// Entries without a value decode as null
+		var value []byte
+		valueEnd := valueStart
+		if entrySize != uint64(1+keyLen) {
+			mark := len(r.fixes)
+			v, used, err := r.value(payload[valueStart:], offset+start+valueStart, childPath)
+			if err != nil {
+				r.fixes = r.fixes[:mark]
+				r.fix(entryOffset, childPath, "dropped %d bytes of an unreadable entry: %v", len(payload)-pos, err)
+				break
+			}
+			value, valueEnd = v, valueStart+used
+		}
+
+		next := valueEnd
+		switch declaredEnd := uint64(entryStart) + entrySize; {
+		case declaredEnd > uint64(valueEnd) && declaredEnd <= uint64(len(payload)):
+			r.fix(entryOffset, childPath, "removed %d bytes of padding after the value", declaredEnd-uint64(valueEnd))
+			next = int(declaredEnd)
+		case declaredEnd != uint64(valueEnd):
+			r.fix(entryOffset, childPath, "entry size %d corrected to %d", entrySize, valueEnd-entryStart)
+		}
+
+		entry, err := buildFieldEntry(key, value)
+		if err != nil {
+			return nil, 0, wrapError(repairErr, err.Error())
+		}
+		fields = append(fields, entry...)
+		pos = next
+	}
+
+	out, err := buildContainer(TypeObject, fields)
+	if err != nil {
+		return nil, 0, wrapError(repairErr, err.Error())
+	}
+	return out, n, nil
+}
+
+func (r *repairer) list(data []byte, offset int, path string) ([]byte, int, error) {
+	payload, start, n, err := r.payload(data, offset, path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var elements []byte
+	for pos, i := 0, 0; pos < len(payload); i++ {
+		elemPath := joinPath(path, fmt.Sprintf("[%d]", i))
+		mark := len(r.fixes)
+		elem, used, err := r.value(payload[pos:], offset+start+pos, elemPath)
+		if err != nil {
+			r.fixes = r.fixes[:mark]
+			r.fix(offset+start+pos, elemPath, "dropped %d bytes of an unreadable element: %v", len(payload)-pos, err)
+			break
+		}
+		elements = append(elements, elem...)
+		pos += used
+	}
+
+	out, err := buildContainer(TypeUntypedList, elements)
+	if err != nil {
+		return nil, 0, wrapError(repairErr, err.Error())
+	}
+	return out, n, nil
+}
+
+func (r *repairer) typedList(data []byte, offset int, path string) ([]byte, int, error) {
+	payload, start, n, err := r.payload(data, offset, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(payload) < 2 || len(payload) < 2+int(payload[1]) {
+		return nil, 0, wrapError(repairErr, "insufficient data for typed list header")
+	}
+	elementType := Type(payload[0])
+	countLen := int(payload[1])
+	count, err := decodeUint(payload[2 : 2+countLen])
+	if err != nil {
+		return nil, 0, wrapError(repairErr, err.Error())
+	}
+	elements := payload[2+countLen:]
+	elementsOffset := offset + start + 2 + countLen
+
+	// Walk the elements that are present in full
+	pos, found := 0, uint64(0)
+	for ; found < count && pos < len(elements); found++ {
+		size, ok := typedElementSize(elementType, elements[pos:])
+		if !ok {
+			break
+		}
+		pos += size
+	}
+
+	switch {
+	case found < count:
+		r.fix(elementsOffset+pos, path, "typed list truncated, kept %d of %d elements", found, count)
+	case pos < len(elements):
+		r.fix(elementsOffset+pos, path, "removed %d bytes of padding after the elements", len(elements)-pos)
+	default:
+		return data[:n], n, nil
+	}
+
+	encodedCount, err := encodeUint(found)
+	if err != nil {
+		return nil, 0, wrapError(repairErr, err.Error())
+	}
+	content := append([]byte{byte(elementType)}, encodedCount[1:]...)
+	content = append(content, elements[:pos]...)
+	out, err := buildContainer(TypeTypedList, content)
+	if err != nil {
+		return nil, 0, wrapError(repairErr, err.Error())
+	}
+	return out, n, nil
+}
+
+// typedElementSize returns the size of the typed list element at the start of
+// data. It reports false for unknown element types and incomplete elements.
+func typedElementSize(elementType Type, data []byte) (int, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	switch elementType {
+	case TypeByte, TypeBoolTrue, TypeBoolFalse:
+		return 1, true
+	case TypeInt, TypeUint, TypeFloat:
+		size := 1 + int(data[0])
+		return size, size <= len(data)
+	case TypeTimestamp:
+		return 8, 8 <= len(data)
+	case TypeTypedList:
+		// A typed list without its type byte
+		lenSize := int(data[0])
+		if 1+lenSize > len(data) {
+			return 0, false
+		}
+		listLen, err := decodeUint(data[1 : 1+lenSize])
+		if err != nil || listLen > uint64(len(data)-1-lenSize) {
+			return 0, false
+		}
+		return 1 + lenSize + int(listLen), true
+	case TypeString:
+		lenSize := int(data[0])
+		if 1+lenSize > len(data) {
+			return 0, false
+		}
+		strLen, err := decodeUint(data[1 : 1+lenSize])
+		if err != nil || strLen > uint64(len(data)-1-lenSize) {
+			return 0, false
+		}
+		return 1 + lenSize + int(strLen), true
+	}
+	return 0, false
+}
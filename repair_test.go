@@ -0,0 +1,137 @@
+package bogo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixMessages(fixes []Fix) []string {
+	msgs := make([]string, len(fixes))
+	for i, f := range fixes {
+		msgs[i] = f.String()
+	}
+	return msgs
+}
+
+func TestRepairLeavesValidDocumentsUnchanged(t *testing.T) {
+	docs := []any{
+		"hello",
+		int64(-42),
+		[]string{"a", "b"},
+		map[string]any{
+			"name":  "Ada",
+			"tags":  []int64{1, 2, 3},
+			"items": []any{"x", map[string]any{"n": 1.5}},
+			"meta":  map[string]any{"empty": map[string]any{}, "none": nil},
+		},
+	}
+	for _, doc := range docs {
+		data, err := Marshal(doc)
+		require.NoError(t, err)
+
+		repaired, fixes, err := Repair(data)
+		require.NoError(t, err)
+		assert.Equal(t, data, repaired)
+		assert.Empty(t, fixes)
+	}
+}
+
+func TestRepairTrailingBytes(t *testing.T) {
+	data, err := Marshal(map[string]any{"a": 1})
+	require.NoError(t, err)
+	padded := append(append([]byte{}, data...), 0, 0, 0)
+
+	repaired, fixes, err := Repair(padded)
+	require.NoError(t, err)
+	assert.Equal(t, data, repaired)
+	assert.Equal(t, []string{
+		fmt.Sprintf("<root> (offset %d): removed 3 trailing bytes", len(data)),
+	}, fixMessages(fixes))
+}
+
+func TestRepairTruncatedObject(t *testing.T) {
+	data := append([]byte{Version}, rawObject(
+		rawEntry("a", 1),
+		rawEntry("b", "two"),
+		rawEntry("c", "cut off here"),
+	)...)
+	truncated := data[:len(data)-4]
+
+	repaired, fixes, err := Repair(truncated)
+	require.NoError(t, err)
+	require.Len(t, fixes, 2)
+	assert.Contains(t, fixes[0].Message, "container size")
+	assert.Equal(t, "c", fixes[1].Path)
+	assert.Contains(t, fixes[1].Message, "unreadable entry")
+
+	result, err := Decode(repaired)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int64(1), "b": "two"}, result)
+}
+
+func TestRepairEntrySizes(t *testing.T) {
+	value, err := encode("hello")
+	require.NoError(t, err)
+
+	// An entry size covering two extra bytes of padding
+	padded := append([]byte{1, byte(1 + 1 + len(value) + 2), 1, 'p'}, value...)
+	padded = append(padded, 0xEE, 0xEE)
+	// An entry size smaller than the value it frames
+	short := append([]byte{1, 3, 1, 's'}, value...)
+
+	data := append([]byte{Version}, rawObjectFields(append(padded, short...))...)
+
+	repaired, fixes, err := Repair(data)
+	require.NoError(t, err)
+	fieldsOffset := 4 // version, type and a two byte size
+	assert.Equal(t, []string{
+		fmt.Sprintf("p (offset %d): removed 2 bytes of padding after the value", fieldsOffset),
+		fmt.Sprintf("s (offset %d): entry size 3 corrected to %d", fieldsOffset+len(padded), 2+len(value)),
+	}, fixMessages(fixes))
+
+	result, err := Decode(repaired)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"p": "hello", "s": "hello"}, result)
+}
+
+func TestRepairNestedContainers(t *testing.T) {
+	// A typed list claiming a fourth element that was never written
+	content := append([]byte{TypeInt}, rawSize(4)...)
+	for _, v := range []int64{10, 20, 30} {
+		elem, err := encodeInt(v)
+		require.NoError(t, err)
+		content = append(content, elem[1:]...)
+	}
+	list := append(append([]byte{TypeTypedList}, rawSize(len(content))...), content...)
+
+	data := append([]byte{Version}, rawObject(
+		append([]byte{4, 'l', 'i', 's', 't'}, list...),
+		rawEntry("ok", true),
+	)...)
+
+	repaired, fixes, err := Repair(data)
+	require.NoError(t, err)
+	require.Len(t, fixes, 1)
+	assert.Equal(t, "list", fixes[0].Path)
+	assert.Equal(t, "typed list truncated, kept 3 of 4 elements", fixes[0].Message)
+
+	result, err := Decode(repaired)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"list": []int64{10, 20, 30}, "ok": true}, result)
+}
+
+func TestRepairUnrecoverable(t *testing.T) {
+	_, _, err := Repair([]byte{Version})
+	assert.ErrorIs(t, err, repairErr)
+
+	_, _, err = Repair([]byte{0x07, TypeNull})
+	assert.ErrorContains(t, err, "unsupported version 7")
+
+	data, err := Marshal("a string that is cut short")
+	require.NoError(t, err)
+	_, _, err = Repair(data[:10])
+	assert.ErrorContains(t, err, "value truncated")
+}
@@ -0,0 +1,58 @@
+package bogo
+
+import (
+	"reflect"
+	"unicode/utf8"
+)
+
+// Char is a rune that is encoded as a single-character string rather than as
+// an integer, so its character semantics survive a round trip. Plain runes are
+// indistinguishable from int32 and are encoded as numbers unless the encoder is
+// configured with WithRunesAsStrings.
+type Char rune
+
+func (c Char) String() string {
+	return string(rune(c))
+}
+
+var (
+	charType      = reflect.TypeOf(Char(0))
+	runeSliceType = reflect.TypeOf([]rune(nil))
+)
+
+// encodeRunes encodes runes as a UTF-8 string
+func encodeRunes(runes []rune) ([]byte, error) {
+	return encodeString(string(runes))
+}
+
+// assignRunes assigns a decoded string to a rune-like destination: a single
+// character to an int32 kind, or a whole string to a slice of int32 kind.
+// It reports whether the destination was set.
+func assignRunes(value any, dest reflect.Value) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	switch dest.Kind() {
+	case reflect.Int32:
+		if utf8.RuneCountInString(str) != 1 {
+			return false
+		}
+		r, _ := utf8.DecodeRuneInString(str)
+		dest.SetInt(int64(r))
+		return true
+	case reflect.Slice:
+		if dest.Type().Elem().Kind() != reflect.Int32 {
+			return false
+		}
+		runes := []rune(str)
+		slice := reflect.MakeSlice(dest.Type(), len(runes), len(runes))
+		for i, r := range runes {
+			slice.Index(i).SetInt(int64(r))
+		}
+		dest.Set(slice)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunes(t *testing.T) {
+	type Glyphs struct {
+		Initial Char   `json:"initial"`
+		Symbol  rune   `json:"symbol"`
+		Word    []rune `json:"word"`
+	}
+
+	t.Run("Char always encodes as a string", func(t *testing.T) {
+		data, err := Encode(Char('é'))
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "é", decoded)
+	})
+
+	t.Run("runes encode as numbers by default", func(t *testing.T) {
+		data, err := Marshal(Glyphs{Initial: 'A', Symbol: 'λ', Word: []rune("héllo")})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		obj := decoded.(map[string]any)
+		assert.Equal(t, "A", obj["initial"])
+		assert.Equal(t, int64('λ'), obj["symbol"])
+
+		var glyphs Glyphs
+		require.NoError(t, Unmarshal(data, &glyphs))
+		assert.Equal(t, Glyphs{Initial: 'A', Symbol: 'λ', Word: []rune("héllo")}, glyphs)
+	})
+
+	t.Run("runes as strings option", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithRunesAsStrings(true))
+		data, err := encoder.Encode(Glyphs{Initial: 'A', Symbol: 'λ', Word: []rune("héllo")})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"initial": "A", "symbol": "λ", "word": "héllo"}, decoded)
+
+		var glyphs Glyphs
+		require.NoError(t, Unmarshal(data, &glyphs))
+		assert.Equal(t, Glyphs{Initial: 'A', Symbol: 'λ', Word: []rune("héllo")}, glyphs)
+	})
+
+	t.Run("multi-character strings do not fit a rune", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"initial": "AB"})
+		require.NoError(t, err)
+
+		var glyphs Glyphs
+		assert.Error(t, Unmarshal(data, &glyphs))
+	})
+}
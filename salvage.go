@@ -0,0 +1,125 @@
+package bogo
+
+import "fmt"
+
+// WithSalvage makes the decoder skip object entries that cannot be decoded
+// instead of failing the whole document. Each entry carries its own length, so
+// a corrupt value is stepped over and the remaining entries are still
+// returned; nested objects are salvaged the same way. A truncated document
+// keeps the entries that were received in full. Every dropped entry is
+// reported to the WarnHandler as a WarnCorruptEntry event with its path.
+//
+// Salvage only applies to objects: corruption outside of an object entry, or
+// in an entry's length, still fails the decode or drops the remaining
+// entries. WithMemoryCeiling and the checks of NewSecureDecoder reject corrupt
+// documents before they are salvaged.
+func WithSalvage(enabled bool) DecoderOption {
+	return func(d *Decoder) {
+		d.Salvage = enabled
+	}
+}
+
+// salvageObject decodes the object payload in data, dropping corrupt entries.
+// path locates the object in the document.
+func (d *Decoder) salvageObject(data []byte, path string) (map[string]any, error) {
+	if len(data) == 0 {
+		return map[string]any{}, nil
+	}
+
+	sizeLen := int(data[0])
+	if len(data) < 1+sizeLen {
+		return nil, wrapError(objDecErr, "insufficient data for field size")
+	}
+	fieldsSize, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return nil, wrapError(objDecErr, "failed to decode fields size", err.Error())
+	}
+	fields := data[1+sizeLen:]
+	if fieldsSize <= uint64(len(fields)) {
+		fields = fields[:fieldsSize]
+	} else {
+		d.warnCorrupt(path, fmt.Sprintf("object truncated, %d of %d bytes present", len(fields), fieldsSize))
+	}
+	if len(fields) == 1 && fields[0] == TypeNull {
+		return nil, nil
+	}
+
+	result := make(map[string]any, countEntries(fields))
+	for pos := 0; pos < len(fields); {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			d.warnCorrupt(path, "dropping remaining entries: insufficient data for entry size")
+			break
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		entryStart := pos + 1 + entrySizeLen
+		if err != nil || entrySize == 0 || entrySize > uint64(len(fields)-entryStart) {
+			d.warnCorrupt(path, "dropping remaining entries: invalid entry size")
+			break
+		}
+		entry := fields[entryStart : entryStart+int(entrySize)]
+		pos = entryStart + int(entrySize)
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			d.warnCorrupt(path, "dropping entry: insufficient data for key")
+			continue
+		}
+		key := string(entry[1 : 1+keyLen])
+		if d.ValidateUTF8 && !isValidUTF8(key) {
+			d.warnCorrupt(path, "dropping entry: invalid UTF-8 in object key")
+			continue
+		}
+
+		value, err := d.salvageValue(entry[1+keyLen:], joinPath(path, key))
+		if err != nil {
+			d.warnCorrupt(joinPath(path, key), "dropping entry: "+err.Error())
+			continue
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// salvageValue decodes one entry value, which must fill the entry exactly.
+// Corrupt values can trip the bounds of the regular decoders, so a panic is
+// reported as an error of the entry.
+func (d *Decoder) salvageValue(data []byte, path string) (value any, err error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("corrupt value: %v", r)
+		}
+	}()
+
+	size, err := getElementSize(data)
+	if err != nil {
+		return nil, err
+	}
+	if size != len(data) {
+		return nil, fmt.Errorf("value size %d does not match entry size %d", size, len(data))
+	}
+
+	if Type(data[0]) == TypeObject {
+		return d.salvageObject(data[1:], path)
+	}
+	value, err = decodeValueWith(data, d.strings())
+	if err != nil {
+		return nil, err
+	}
+	if str, ok := value.(string); ok && d.ValidateUTF8 && !isValidUTF8(str) {
+		return nil, fmt.Errorf("invalid UTF-8 in string")
+	}
+	return value, nil
+}
+
+// warnCorrupt reports a dropped object entry
+func (d *Decoder) warnCorrupt(path, msg string) {
+	d.warn(WarnEvent{
+		Kind:    WarnCorruptEntry,
+		Message: msg,
+		Path:    path,
+	})
+}
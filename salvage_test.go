@@ -0,0 +1,107 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func salvageDecoder(events *[]WarnEvent) *Decoder {
+	return NewConfigurableDecoder(
+		WithSalvage(true),
+		WithWarnHandler(func(ev WarnEvent) { *events = append(*events, ev) }),
+	)
+}
+
+func TestSalvageCorruptEntry(t *testing.T) {
+	corrupt := []byte{1, 'x', 0x99} // unsupported type
+	data := append([]byte{Version}, rawObject(
+		rawEntry("id", 7),
+		corrupt,
+		rawEntry("msg", "hello"),
+	)...)
+
+	_, err := NewConfigurableDecoder().Decode(data)
+	require.Error(t, err)
+
+	var events []WarnEvent
+	result, err := salvageDecoder(&events).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": int64(7), "msg": "hello"}, result)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, WarnCorruptEntry, events[0].Kind)
+	assert.Equal(t, "x", events[0].Path)
+	assert.Contains(t, events[0].Message, "dropping entry")
+}
+
+func TestSalvageNestedObject(t *testing.T) {
+	meta := rawObject(
+		rawEntry("host", "web-1"),
+		append([]byte{3, 'b', 'a', 'd'}, TypeString, 1, 9, 'a'),
+	)
+	data := append([]byte{Version}, rawObject(
+		rawEntry("level", "info"),
+		append([]byte{4, 'm', 'e', 't', 'a'}, meta...),
+	)...)
+
+	var events []WarnEvent
+	result, err := salvageDecoder(&events).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"level": "info",
+		"meta":  map[string]any{"host": "web-1"},
+	}, result)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "meta.bad", events[0].Path)
+}
+
+func TestSalvageTruncatedDocument(t *testing.T) {
+	data := append([]byte{Version}, rawObject(
+		rawEntry("a", 1),
+		rawEntry("b", "two"),
+		rawEntry("c", "a value that gets cut off"),
+	)...)
+	data = data[:len(data)-5]
+
+	var events []WarnEvent
+	result, err := salvageDecoder(&events).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int64(1), "b": "two"}, result)
+
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0].Message, "object truncated")
+	assert.Contains(t, events[1].Message, "dropping remaining entries")
+}
+
+func TestSalvageInvalidUTF8(t *testing.T) {
+	data := append([]byte{Version}, rawObject(
+		rawEntry("ok", "fine"),
+		rawEntry("bad", "x\xff"),
+	)...)
+
+	var events []WarnEvent
+	result, err := salvageDecoder(&events).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"ok": "fine"}, result)
+	require.Len(t, events, 1)
+	assert.Equal(t, "bad", events[0].Path)
+}
+
+func TestSalvageValidDocument(t *testing.T) {
+	doc := map[string]any{
+		"name": "Ada",
+		"tags": []string{"a", "b"},
+		"meta": map[string]any{"n": int64(1), "empty": map[string]any{}},
+	}
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+
+	var events []WarnEvent
+	result, err := salvageDecoder(&events).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, doc, result)
+	assert.Empty(t, events)
+}
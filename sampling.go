@@ -0,0 +1,85 @@
+package bogo
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Sampling selects the values a SamplingEncoder encodes
+type Sampling struct {
+	Every    int  // Encode every Nth value offered, starting with the first (0 or 1 = every value)
+	OnChange bool // Encode a value only when its encoding differs from the last one encoded
+}
+
+// SamplingStats counts the values offered to a SamplingEncoder and those it
+// encoded
+type SamplingStats struct {
+	Offered uint64
+	Encoded uint64
+}
+
+// SamplingEncoder encodes a sample of the values offered to it, for
+// high-frequency emitters such as metric reporters that would otherwise
+// encode every value and drop most of them later. Values skipped by Every are
+// not encoded at all. OnChange compares encodings byte for byte, so the
+// encoder writes object keys in canonical order, lexical unless configured
+// otherwise. A SamplingEncoder is safe for concurrent use.
+type SamplingEncoder struct {
+	encoder  *Encoder
+	sampling Sampling
+
+	mu    sync.Mutex
+	last  []byte // last encoding returned, for OnChange
+	stats SamplingStats
+}
+
+// NewSamplingEncoder returns an encoder sampling values as sampling selects
+// and encoding them with an encoder configured by options
+func NewSamplingEncoder(sampling Sampling, options ...EncoderOption) *SamplingEncoder {
+	encoder := NewConfigurableEncoder(options...)
+	if sampling.OnChange && encoder.CanonicalOrder == CanonicalOff {
+		encoder.CanonicalOrder = CanonicalLexical
+	}
+	return &SamplingEncoder{encoder: encoder, sampling: sampling}
+}
+
+// Sample encodes v when it is selected, reporting whether it was. Values not
+// selected return nil, false and no error.
+func (s *SamplingEncoder) Sample(v any) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Offered++
+	if every := uint64(s.sampling.Every); every > 1 && (s.stats.Offered-1)%every != 0 {
+		return nil, false, nil
+	}
+
+	data, err := s.encoder.Encode(v)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.sampling.OnChange {
+		if s.last != nil && bytes.Equal(data, s.last) {
+			return nil, false, nil
+		}
+		s.last = append(s.last[:0], data...)
+	}
+	s.stats.Encoded++
+	return data, true, nil
+}
+
+// Stats returns the number of values offered and encoded so far
+func (s *SamplingEncoder) Stats() SamplingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Reset clears the counters and forgets the last value encoded, so the next
+// value offered is encoded
+func (s *SamplingEncoder) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = nil
+	s.stats = SamplingStats{}
+}
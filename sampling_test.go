@@ -0,0 +1,73 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplingEncoder(t *testing.T) {
+	t.Run("every Nth value", func(t *testing.T) {
+		s := NewSamplingEncoder(Sampling{Every: 3})
+		var sampled []int
+		for i := range 7 {
+			data, ok, err := s.Sample(i)
+			require.NoError(t, err)
+			if ok {
+				var v int
+				require.NoError(t, Unmarshal(data, &v))
+				sampled = append(sampled, v)
+			}
+		}
+		assert.Equal(t, []int{0, 3, 6}, sampled)
+		assert.Equal(t, SamplingStats{Offered: 7, Encoded: 3}, s.Stats())
+	})
+
+	t.Run("only changes", func(t *testing.T) {
+		s := NewSamplingEncoder(Sampling{OnChange: true})
+		readings := []map[string]any{
+			{"cpu": 0.5, "mem": int64(100)},
+			{"mem": int64(100), "cpu": 0.5},
+			{"cpu": 0.7, "mem": int64(100)},
+			{"cpu": 0.7, "mem": int64(100)},
+			{"cpu": 0.5, "mem": int64(100)},
+		}
+		var encoded []bool
+		for _, r := range readings {
+			_, ok, err := s.Sample(r)
+			require.NoError(t, err)
+			encoded = append(encoded, ok)
+		}
+		assert.Equal(t, []bool{true, false, true, false, true}, encoded)
+
+		s.Reset()
+		_, ok, err := s.Sample(readings[4])
+		require.NoError(t, err)
+		assert.True(t, ok, "Reset forgets the last value")
+		assert.Equal(t, SamplingStats{Offered: 1, Encoded: 1}, s.Stats())
+	})
+
+	t.Run("both", func(t *testing.T) {
+		s := NewSamplingEncoder(Sampling{Every: 2, OnChange: true})
+		var sampled []any
+		for _, v := range []string{"a", "b", "a", "c", "b", "b", "b"} {
+			data, ok, err := s.Sample(v)
+			require.NoError(t, err)
+			if ok {
+				decoded, err := Decode(data)
+				require.NoError(t, err)
+				sampled = append(sampled, decoded)
+			}
+		}
+		assert.Equal(t, []any{"a", "b"}, sampled)
+	})
+
+	t.Run("errors are not sampled", func(t *testing.T) {
+		s := NewSamplingEncoder(Sampling{OnChange: true})
+		_, ok, err := s.Sample(make(chan int))
+		assert.Error(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, SamplingStats{Offered: 1}, s.Stats())
+	})
+}
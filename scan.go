@@ -0,0 +1,320 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// OnField subscribes fn to the values found at path when the decoder scans a
+// document with Scan. Paths are dot-separated object keys, with "[]" selecting
+// every element of a list, e.g. "orders[].amount". The empty path selects the
+// whole document. Multiple subscriptions to the same path are called in order.
+func (d *Decoder) OnField(path string, fn func(value any)) {
+	if d.subscriptions == nil {
+		d.subscriptions = make(map[string][]func(any))
+	}
+	d.subscriptions[path] = append(d.subscriptions[path], fn)
+}
+
+var scanErr = errors.New("scan error")
+
+// Scan walks data in a single pass and invokes the callbacks registered with
+// OnField for every matching value. Only subscribed values are materialised;
+// everything else is skipped using the size information in the wire format,
+// so aggregates can be computed over large documents without storing them.
+func (d *Decoder) Scan(data []byte) error {
+	data, err := d.negotiate(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", scanErr, err)
+	}
+	if len(data) < 2 {
+		return wrapError(scanErr, "insufficient data, need at least 2 bytes for version and type")
+	}
+	if data[0] != Version {
+		if d.StrictMode {
+			return fmt.Errorf("%w: %w", scanErr, versionMismatch(data[0]))
+		}
+		d.warnVersion(data[0])
+	}
+	if len(d.subscriptions) == 0 {
+		return nil
+	}
+
+	// Every proper prefix of a subscribed path must be descended into
+	prefixes := make(map[string]bool)
+	for path := range d.subscriptions {
+		if path != "" {
+			prefixes[""] = true
+		}
+		for i := range path {
+			if path[i] == '.' || path[i] == '[' {
+				prefixes[path[:i]] = true
+			}
+		}
+	}
+
+	s := &scanner{subscriptions: d.subscriptions, prefixes: prefixes, maxDepth: d.MaxDepth}
+	return s.walk(data[1:], "", 0)
+}
+
+type scanner struct {
+	subscriptions map[string][]func(any)
+	prefixes      map[string]bool
+	maxDepth      int
+	strs          stringMaker // holds the table of the enclosing TypeSymbols
+}
+
+// walk visits the value at the start of data, which lives at path
+func (s *scanner) walk(data []byte, path string, depth int) error {
+	if s.maxDepth > 0 && depth > s.maxDepth {
+		return wrapError(scanErr, fmt.Sprintf("maximum nesting depth exceeded (%d)", s.maxDepth))
+	}
+
+	if fns, ok := s.subscriptions[path]; ok {
+		value, err := decodeValueWith(data, s.strs)
+		if err != nil {
+			return wrapError(scanErr, fmt.Sprintf("failed to decode %q", path), err.Error())
+		}
+		for _, fn := range fns {
+			fn(value)
+		}
+	}
+
+	if !s.prefixes[path] || len(data) == 0 {
+		return nil
+	}
+	return s.descend(data, path, depth)
+}
+
+// descend visits the values inside the value at the start of data
+func (s *scanner) descend(data []byte, path string, depth int) error {
+	switch Type(data[0]) {
+	case TypeObject:
+		return s.walkObject(data[1:], path, depth)
+	case TypeUntypedList:
+		return s.walkList(data[1:], path, depth)
+	case TypeTypedList:
+		return s.walkTypedList(data[1:], path)
+	case TypeObjectList:
+		return s.walkObjectList(data[1:], path, depth)
+	case TypeSymbols:
+		return s.walkSymbols(data[1:], path, depth)
+	case TypeKeyedObject:
+		return s.walkKeyedObject(data[1:], path, depth)
+	}
+	return nil
+}
+
+// walkSymbols descends into the value below a symbol table, which lives at
+// the same path, resolving its keys and enum values against the table
+func (s *scanner) walkSymbols(data []byte, path string, depth int) error {
+	symbols, offset, err := readSymbols(data, s.strs)
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+	saved := s.strs.symbols
+	s.strs.symbols = symbols
+	defer func() { s.strs.symbols = saved }()
+	return s.descend(data[offset:], path, depth)
+}
+
+// walkKeyedObject visits the fields of an object whose keys are in the symbol
+// table as walkObject visits those of a plain object
+func (s *scanner) walkKeyedObject(data []byte, path string, depth int) error {
+	entries, err := containerPayload(data)
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+	for pos := 0; pos < len(entries); {
+		key, size, err := keyedObjectKey(entries[pos:], s.strs)
+		if err != nil {
+			return wrapError(scanErr, err.Error())
+		}
+		pos += size
+		if pos >= len(entries) {
+			return wrapError(scanErr, fmt.Sprintf("no value for key %q", key))
+		}
+		size, err = getElementSize(entries[pos:])
+		if err != nil {
+			return wrapError(scanErr, err.Error())
+		}
+		if pos+size > len(entries) {
+			return wrapError(scanErr, fmt.Sprintf("insufficient data for key %q", key))
+		}
+
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		if err := s.walk(entries[pos:pos+size], childPath, depth+1); err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
+
+func (s *scanner) walkObject(data []byte, path string, depth int) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	sizeLen := int(data[0])
+	if len(data) < 1+sizeLen {
+		return wrapError(scanErr, "insufficient data for object size")
+	}
+	fieldsSize, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+	fieldsStart := 1 + sizeLen
+	fieldsEnd := fieldsStart + int(fieldsSize)
+	if len(data) < fieldsEnd {
+		return wrapError(scanErr, "insufficient data for object fields")
+	}
+	fields := data[fieldsStart:fieldsEnd]
+
+	for pos := 0; pos < len(fields); {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			return wrapError(scanErr, "insufficient data for entry size")
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil {
+			return wrapError(scanErr, err.Error())
+		}
+		entryStart := pos + 1 + entrySizeLen
+		entryEnd := entryStart + int(entrySize)
+		if entryEnd > len(fields) || entryStart >= entryEnd {
+			return wrapError(scanErr, "insufficient data for entry content")
+		}
+		entry := fields[entryStart:entryEnd]
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			return wrapError(scanErr, "insufficient data for key")
+		}
+		key := string(entry[1 : 1+keyLen])
+
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		if err := s.walk(entry[1+keyLen:], childPath, depth+1); err != nil {
+			return err
+		}
+
+		pos = entryEnd
+	}
+	return nil
+}
+
+func (s *scanner) walkList(data []byte, path string, depth int) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	sizeLen := int(data[0])
+	if len(data) < 1+sizeLen {
+		return wrapError(scanErr, "insufficient data for list size")
+	}
+	listSize, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+	listStart := 1 + sizeLen
+	listEnd := listStart + int(listSize)
+	if len(data) < listEnd {
+		return wrapError(scanErr, "insufficient data for list content")
+	}
+	elements := data[listStart:listEnd]
+
+	elemPath := path + "[]"
+	for pos := 0; pos < len(elements); {
+		size, err := getElementSize(elements[pos:])
+		if err != nil {
+			return wrapError(scanErr, err.Error())
+		}
+		if pos+size > len(elements) {
+			return wrapError(scanErr, "insufficient data for list element")
+		}
+		if err := s.walk(elements[pos:pos+size], elemPath, depth+1); err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
+
+// walkTypedList reports each element of a typed list. Typed list elements are
+// primitives, so only subscriptions on the element path itself can match.
+func (s *scanner) walkTypedList(data []byte, path string) error {
+	fns, ok := s.subscriptions[path+"[]"]
+	if !ok {
+		return nil
+	}
+
+	list, err := decodeTypedList(data)
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+
+	rv := reflect.ValueOf(list)
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i).Interface()
+		for _, fn := range fns {
+			fn(elem)
+		}
+	}
+	return nil
+}
+
+// walkObjectList visits the rows of an object list as walkList visits the
+// objects of a list, and the values of each row as walkObject visits fields
+func (s *scanner) walkObjectList(data []byte, path string, depth int) error {
+	payload, err := containerPayload(data)
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+	keys, rows, values, err := objectListHeader(payload)
+	if err != nil {
+		return wrapError(scanErr, err.Error())
+	}
+
+	elemPath := path + "[]"
+	fns := s.subscriptions[elemPath]
+	row := make([][]byte, len(keys))
+	pos := 0
+	for i := 0; i < rows; i++ {
+		for j := range keys {
+			size, err := getElementSize(values[pos:])
+			if err != nil || size > len(values)-pos {
+				return wrapError(scanErr, fmt.Sprintf("insufficient data for row %d", i))
+			}
+			row[j] = values[pos : pos+size]
+			pos += size
+		}
+
+		if len(fns) > 0 {
+			obj := make(map[string]any, len(keys))
+			for j, key := range keys {
+				if obj[string(key)], err = decodeValueWith(row[j], s.strs); err != nil {
+					return wrapError(scanErr, fmt.Sprintf("failed to decode %q", elemPath), err.Error())
+				}
+			}
+			for _, fn := range fns {
+				fn(obj)
+			}
+		}
+		if !s.prefixes[elemPath] {
+			continue
+		}
+		for j, key := range keys {
+			if err := s.walk(row[j], elemPath+"."+string(key), depth+2); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderScan(t *testing.T) {
+	doc := map[string]any{
+		"region": "eu",
+		"orders": []any{
+			map[string]any{"id": "a", "amount": 12.5},
+			map[string]any{"id": "b", "amount": 7.5},
+			map[string]any{"id": "c", "amount": 30.0},
+		},
+		"tags":   []string{"x", "y"},
+		"counts": []int64{1, 2, 3, 4},
+		"meta":   map[string]any{"owner": map[string]any{"name": "ops"}},
+	}
+	data, err := Encode(doc)
+	require.NoError(t, err)
+
+	t.Run("aggregates over list elements", func(t *testing.T) {
+		decoder := NewConfigurableDecoder()
+
+		var total float64
+		var orders int
+		decoder.OnField("orders[].amount", func(v any) { total += v.(float64) })
+		decoder.OnField("orders[]", func(v any) { orders++ })
+
+		require.NoError(t, decoder.Scan(data))
+		assert.Equal(t, 50.0, total)
+		assert.Equal(t, 3, orders)
+	})
+
+	t.Run("typed lists and nested objects", func(t *testing.T) {
+		decoder := NewConfigurableDecoder()
+
+		var sum int64
+		var tags []string
+		var owner any
+		decoder.OnField("counts[]", func(v any) { sum += v.(int64) })
+		decoder.OnField("tags", func(v any) { tags = v.([]string) })
+		decoder.OnField("meta.owner.name", func(v any) { owner = v })
+
+		require.NoError(t, decoder.Scan(data))
+		assert.Equal(t, int64(10), sum)
+		assert.Equal(t, []string{"x", "y"}, tags)
+		assert.Equal(t, "ops", owner)
+	})
+
+	t.Run("missing paths are never called", func(t *testing.T) {
+		decoder := NewConfigurableDecoder()
+		called := false
+		decoder.OnField("orders[].missing", func(any) { called = true })
+		decoder.OnField("region.sub", func(any) { called = true })
+
+		require.NoError(t, decoder.Scan(data))
+		assert.False(t, called)
+	})
+
+	t.Run("root subscription", func(t *testing.T) {
+		listData, err := Encode([]any{int64(1), "two"})
+		require.NoError(t, err)
+
+		decoder := NewConfigurableDecoder()
+		var elements []any
+		decoder.OnField("[]", func(v any) { elements = append(elements, v) })
+
+		require.NoError(t, decoder.Scan(listData))
+		assert.Equal(t, []any{int64(1), "two"}, elements)
+	})
+
+	t.Run("object lists", func(t *testing.T) {
+		type order struct {
+			ID     string  `json:"id"`
+			Amount float64 `json:"amount"`
+		}
+		e := NewConfigurableEncoder(WithObjectLists(true))
+		data, err := e.Encode(map[string]any{"orders": []order{{"a", 12.5}, {"b", 7.5}}})
+		require.NoError(t, err)
+		require.Contains(t, data, byte(TypeObjectList))
+
+		decoder := NewConfigurableDecoder()
+		var total float64
+		var orders []any
+		decoder.OnField("orders[].amount", func(v any) { total += v.(float64) })
+		decoder.OnField("orders[]", func(v any) { orders = append(orders, v) })
+
+		require.NoError(t, decoder.Scan(data))
+		assert.Equal(t, 20.0, total)
+		assert.Equal(t, []any{
+			map[string]any{"id": "a", "amount": 12.5},
+			map[string]any{"id": "b", "amount": 7.5},
+		}, orders)
+	})
+
+	t.Run("keyed objects", func(t *testing.T) {
+		e := NewConfigurableEncoder(WithKeyTable(true))
+		data, err := e.Encode(doc)
+		require.NoError(t, err)
+		require.Equal(t, byte(TypeSymbols), data[1])
+
+		decoder := NewConfigurableDecoder()
+		var total float64
+		var owner, meta any
+		decoder.OnField("orders[].amount", func(v any) { total += v.(float64) })
+		decoder.OnField("meta.owner.name", func(v any) { owner = v })
+		decoder.OnField("meta", func(v any) { meta = v })
+
+		require.NoError(t, decoder.Scan(data))
+		assert.Equal(t, 50.0, total)
+		assert.Equal(t, "ops", owner)
+		assert.Equal(t, map[string]any{"owner": map[string]any{"name": "ops"}}, meta)
+	})
+}
@@ -0,0 +1,512 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var structureErr = errors.New("bogo decode error")
+
+// Limits applied by NewSecureDecoder
+const (
+	SecureMaxDepth      = 32
+	SecureMaxObjectSize = 1 << 20
+	SecureMaxElements   = 10_000
+	SecureMemoryCeiling = 16 << 20
+)
+
+// NewSecureDecoder returns a decoder with safe defaults for untrusted input,
+// such as requests of internet-facing services. Documents are checked in full
+// before anything is decoded, and are rejected when they
+//
+//   - are not of the current version or contain unknown types (strict mode)
+//   - nest objects and lists deeper than SecureMaxDepth
+//   - are larger than SecureMaxObjectSize bytes
+//   - hold more than SecureMaxElements entries in a single object, map or list
+//   - would allocate more than SecureMemoryCeiling bytes when decoded
+//   - contain strings or keys that are not valid UTF-8
+//   - repeat a key within an object
+//   - are followed by trailing bytes
+//
+// Options are applied after the defaults, so individual limits can be changed.
+func NewSecureDecoder(options ...DecoderOption) *Decoder {
+	d := NewConfigurableDecoder(
+		WithDecoderStrictMode(true),
+		WithUnknownTypes(false),
+		WithUTF8Validation(true),
+		WithDecoderMaxDepth(SecureMaxDepth),
+		WithMemoryCeiling(SecureMemoryCeiling),
+		WithMaxObjectSize(SecureMaxObjectSize),
+		WithMaxElements(SecureMaxElements),
+		WithDuplicateKeyRejection(true),
+		WithTrailingBytesRejection(true),
+	)
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// WithMaxElements limits the number of entries of every object and map and
+// the number of elements of every list in a document, at any depth
+func WithMaxElements(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.MaxElements = n
+	}
+}
+
+// WithDuplicateKeyRejection fails decodes of objects that repeat a key. By
+// default the last value of a repeated key wins.
+func WithDuplicateKeyRejection(reject bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RejectDuplicateKeys = reject
+	}
+}
+
+// WithTrailingBytesRejection fails decodes of documents followed by bytes that
+// are not part of the encoded value. By default trailing bytes are ignored.
+func WithTrailingBytesRejection(reject bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RejectTrailingBytes = reject
+	}
+}
+
+// verifyStructure walks the whole document before it is decoded when element
+// limits, duplicate key or trailing byte rejection are enabled. The walk also
+// applies MaxDepth, MaxObjectSize and UTF-8 validation to the whole document,
+// where decoding only applies them to the outermost value.
+func (d *Decoder) verifyStructure(data []byte) error {
+	if d.MaxElements <= 0 && !d.RejectDuplicateKeys && !d.RejectTrailingBytes {
+		return nil
+	}
+	if d.MaxObjectSize > 0 && int64(len(data)) > d.MaxObjectSize {
+		return wrapError(structureErr, fmt.Sprintf("maximum object size exceeded (%d bytes)", d.MaxObjectSize))
+	}
+	if _, known := LayoutOf(Type(data[1])); !known {
+		if d.AllowUnknownTypes {
+			return nil
+		}
+		return wrapError(structureErr, fmt.Sprintf("unsupported type %d", data[1]))
+	}
+
+	size, err := d.verifyValue(data[1:], 0)
+	if err != nil {
+		return err
+	}
+	if trailing := len(data) - 1 - size; trailing > 0 && d.RejectTrailingBytes {
+		return wrapError(structureErr, fmt.Sprintf("%d trailing bytes after value", trailing))
+	}
+	return nil
+}
+
+// verifyValue checks the value at the start of data and returns its size
+func (d *Decoder) verifyValue(data []byte, depth int) (int, error) {
+	if d.DecodeDeadline > 0 {
+		if err := d.deadline.check(); err != nil {
+			return 0, err
+		}
+	}
+	size, err := getElementSize(data)
+	if err != nil {
+		return 0, wrapError(structureErr, err.Error())
+	}
+	if size <= 0 || size > len(data) {
+		return 0, wrapError(structureErr, "insufficient data for value")
+	}
+	value := data[:size]
+
+	if isFixStr(Type(value[0])) {
+		return size, d.verifyUTF8(value[1:], "string")
+	}
+
+	switch Type(value[0]) {
+	case TypeString:
+		payload, err := containerPayload(value[1:])
+		if err != nil {
+			return 0, wrapError(structureErr, err.Error())
+		}
+		return size, d.verifyUTF8(payload, "string")
+	case TypeUntypedList:
+		return size, d.verifyList(value[1:], depth+1)
+	case TypeTypedList:
+		return size, d.verifyTypedList(value[1:], depth+1)
+	case TypeObject:
+		return size, d.verifyObject(value[1:], depth+1)
+	case TypeKeyedObject:
+		return size, d.verifyKeyedObject(value[1:], depth+1)
+	case TypeMap:
+		return size, d.verifyMap(value[1:], depth+1)
+	case TypeObjectList:
+		return size, d.verifyObjectList(value[1:], depth+1)
+	case TypeChunkedList:
+		return size, d.verifyChunkedList(value[1:], depth+1)
+	case TypeExpiring:
+		_, err := d.verifyValue(value[1+expiringHeader:], depth)
+		return size, err
+	case TypeSymbols:
+		table, offset, err := symbolsTable(value[1:])
+		if err != nil {
+			return 0, wrapError(structureErr, err.Error())
+		}
+		err = walkSymbols(table, func(symbol []byte) error {
+			_, err := d.verifyValue(symbol, depth)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		_, err = d.verifyValue(value[1+offset:], depth)
+		return size, err
+	case TypeUnion:
+		name, offset, err := unionName(value[1:])
+		if err != nil {
+			return 0, wrapError(structureErr, err.Error())
+		}
+		if err := d.verifyUTF8(name, "union name"); err != nil {
+			return 0, err
+		}
+		_, err = d.verifyValue(value[1+offset:], depth+1)
+		return size, err
+	}
+	return size, nil
+}
+
+func (d *Decoder) verifyDepth(depth int) error {
+	if d.MaxDepth > 0 && depth > d.MaxDepth {
+		return wrapError(structureErr, fmt.Sprintf("maximum nesting depth exceeded (%d)", d.MaxDepth))
+	}
+	return nil
+}
+
+func (d *Decoder) verifyCount(n int, what string) error {
+	if d.MaxElements > 0 && n > d.MaxElements {
+		return wrapError(structureErr, fmt.Sprintf("%s has more than %d elements", what, d.MaxElements))
+	}
+	return nil
+}
+
+func (d *Decoder) verifyUTF8(b []byte, what string) error {
+	if d.ValidateUTF8 && !isValidUTF8(string(b)) {
+		return wrapError(structureErr, fmt.Sprintf("invalid UTF-8 in %s", what))
+	}
+	return nil
+}
+
+func (d *Decoder) verifyList(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	elements, err := containerPayload(data)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+
+	n := 0
+	for pos := 0; pos < len(elements); n++ {
+		if err := d.verifyCount(n+1, "list"); err != nil {
+			return err
+		}
+		size, err := d.verifyValue(elements[pos:], depth)
+		if err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
+
+func (d *Decoder) verifyChunkedList(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	n := 0
+	var verifyErr error
+	err := walkChunkValues(data, func(value []byte) error {
+		n++
+		if verifyErr = d.verifyCount(n, "list"); verifyErr == nil {
+			_, verifyErr = d.verifyValue(value, depth)
+		}
+		return verifyErr
+	})
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	return nil
+}
+
+func (d *Decoder) verifyObject(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	fields, err := containerPayload(data)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	if len(fields) == 1 && fields[0] == TypeNull {
+		return nil
+	}
+
+	var seen map[string]struct{}
+	if d.RejectDuplicateKeys {
+		seen = make(map[string]struct{}, countEntries(fields))
+	}
+
+	n := 0
+	for pos := 0; pos < len(fields); n++ {
+		if err := d.verifyCount(n+1, "object"); err != nil {
+			return err
+		}
+
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			return wrapError(structureErr, "insufficient data for entry size")
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil {
+			return wrapError(structureErr, err.Error())
+		}
+		entryStart := pos + 1 + entrySizeLen
+		if entrySize == 0 || entrySize > uint64(len(fields)-entryStart) {
+			return wrapError(structureErr, "insufficient data for entry content")
+		}
+		entry := fields[entryStart : entryStart+int(entrySize)]
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			return wrapError(structureErr, "insufficient data for key")
+		}
+		key := entry[1 : 1+keyLen]
+		if err := d.verifyUTF8(key, "object key"); err != nil {
+			return err
+		}
+		if seen != nil {
+			if _, dup := seen[string(key)]; dup {
+				return wrapError(structureErr, fmt.Sprintf("duplicate object key %q", key))
+			}
+			seen[string(key)] = struct{}{}
+		}
+
+		if len(entry) > 1+keyLen {
+			size, err := d.verifyValue(entry[1+keyLen:], depth)
+			if err != nil {
+				return err
+			}
+			if size != len(entry)-1-keyLen && d.RejectTrailingBytes {
+				return wrapError(structureErr, fmt.Sprintf("entry %q has trailing bytes", key))
+			}
+		}
+		pos = entryStart + int(entrySize)
+	}
+	return nil
+}
+
+func (d *Decoder) verifyKeyedObject(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	entries, err := containerPayload(data)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+
+	// Keys are told apart by their index, as symbol tables hold distinct
+	// strings
+	var seen map[uint64]struct{}
+	if d.RejectDuplicateKeys {
+		seen = make(map[uint64]struct{}, countElements(entries)/2)
+	}
+
+	n := 0
+	for pos := 0; pos < len(entries); n++ {
+		if err := d.verifyCount(n+1, "object"); err != nil {
+			return err
+		}
+		if Type(entries[pos]) != TypeSymbol {
+			return wrapError(structureErr, fmt.Sprintf("object key of type %s", Type(entries[pos])))
+		}
+		index, size, err := symbolIndex(entries[pos+1:])
+		if err != nil {
+			return wrapError(structureErr, err.Error())
+		}
+		if seen != nil {
+			if _, dup := seen[index]; dup {
+				return wrapError(structureErr, fmt.Sprintf("duplicate object key %d", index))
+			}
+			seen[index] = struct{}{}
+		}
+		pos += 1 + size
+		if pos >= len(entries) {
+			return wrapError(structureErr, "insufficient data for entry value")
+		}
+		if size, err = d.verifyValue(entries[pos:], depth); err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
+
+func (d *Decoder) verifyMap(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	payload, err := containerPayload(data)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	if len(payload) == 0 {
+		return wrapError(structureErr, "missing map key type")
+	}
+
+	entries := payload[1:]
+	n := 0
+	for pos := 0; pos < len(entries); n++ {
+		if err := d.verifyCount(n+1, "map"); err != nil {
+			return err
+		}
+		size, err := d.verifyValue(entries[pos:], depth)
+		if err != nil {
+			return err
+		}
+		pos += size
+		if pos >= len(entries) {
+			return wrapError(structureErr, "insufficient data for map value")
+		}
+		if size, err = d.verifyValue(entries[pos:], depth); err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
+
+// verifyObjectList checks an object list as a list of objects: the rows count
+// as list elements and the shared keys as the entries of every row, whose
+// values are one level deeper than the list
+func (d *Decoder) verifyObjectList(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	payload, err := containerPayload(data)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	keys, rows, values, err := objectListHeader(payload)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	if err := d.verifyCount(rows, "list"); err != nil {
+		return err
+	}
+	if rows > 0 {
+		if err := d.verifyDepth(depth + 1); err != nil {
+			return err
+		}
+	}
+	if err := d.verifyCount(len(keys), "object"); err != nil {
+		return err
+	}
+
+	var seen map[string]struct{}
+	if d.RejectDuplicateKeys {
+		seen = make(map[string]struct{}, len(keys))
+	}
+	for _, key := range keys {
+		if err := d.verifyUTF8(key, "object key"); err != nil {
+			return err
+		}
+		if seen != nil {
+			if _, dup := seen[string(key)]; dup {
+				return wrapError(structureErr, fmt.Sprintf("duplicate object key %q", key))
+			}
+			seen[string(key)] = struct{}{}
+		}
+	}
+
+	pos := 0
+	for i := 0; i < rows*len(keys); i++ {
+		if pos >= len(values) {
+			return wrapError(structureErr, fmt.Sprintf("insufficient data for row %d", i/len(keys)))
+		}
+		size, err := d.verifyValue(values[pos:], depth+1)
+		if err != nil {
+			return err
+		}
+		pos += size
+	}
+	if pos != len(values) && d.RejectTrailingBytes {
+		return wrapError(structureErr, fmt.Sprintf("%d trailing bytes after the rows", len(values)-pos))
+	}
+	return nil
+}
+
+func (d *Decoder) verifyTypedList(data []byte, depth int) error {
+	if err := d.verifyDepth(depth); err != nil {
+		return err
+	}
+	payload, err := containerPayload(data)
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	if len(payload) < 2 {
+		return nil
+	}
+	countLen := int(payload[1])
+	if len(payload) < 2+countLen {
+		return wrapError(structureErr, "insufficient data for typed list count")
+	}
+	count, err := decodeUint(payload[2 : 2+countLen])
+	if err != nil {
+		return wrapError(structureErr, err.Error())
+	}
+	elements := payload[2+countLen:]
+	if count > uint64(len(elements)) {
+		return wrapError(structureErr, "typed list count exceeds its data")
+	}
+	if err := d.verifyCount(int(count), "typed list"); err != nil {
+		return err
+	}
+	switch Type(payload[0]) {
+	case TypeString:
+		return d.verifyTypedStrings(elements)
+	case TypeTypedList:
+		// Rows are typed lists without their type byte, one level deeper
+		for pos := 0; pos < len(elements); {
+			size, ok := typedElementSize(TypeTypedList, elements[pos:])
+			if !ok {
+				return wrapError(structureErr, "insufficient data for typed list row")
+			}
+			if err := d.verifyTypedList(elements[pos:pos+size], depth+1); err != nil {
+				return err
+			}
+			pos += size
+		}
+	}
+	return nil
+}
+
+// verifyTypedStrings checks the elements of a typed list of strings
+func (d *Decoder) verifyTypedStrings(elements []byte) error {
+	for pos := 0; pos < len(elements); {
+		lenSize := int(elements[pos])
+		if pos+1+lenSize > len(elements) {
+			return wrapError(structureErr, "insufficient data for typed list string")
+		}
+		strLen, err := decodeUint(elements[pos+1 : pos+1+lenSize])
+		if err != nil {
+			return wrapError(structureErr, err.Error())
+		}
+		start := pos + 1 + lenSize
+		if strLen > uint64(len(elements)-start) {
+			return wrapError(structureErr, "insufficient data for typed list string")
+		}
+		if err := d.verifyUTF8(elements[start:start+int(strLen)], "string"); err != nil {
+			return err
+		}
+		pos = start + int(strLen)
+	}
+	return nil
+}
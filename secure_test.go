@@ -0,0 +1,237 @@
+package bogo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawObject encodes an object from pre-encoded entries, which allows
+// documents the encoder never produces, such as repeated keys
+func rawObject(entries ...[]byte) []byte {
+	var fields []byte
+	for _, entry := range entries {
+		fields = append(fields, rawSize(len(entry))...)
+		fields = append(fields, entry...)
+	}
+	return rawObjectFields(fields)
+}
+
+// rawObjectFields frames already sized field entries as an object
+func rawObjectFields(fields []byte) []byte {
+	return append(append([]byte{TypeObject}, rawSize(len(fields))...), fields...)
+}
+
+// rawSize encodes a size as its length followed by the value
+func rawSize(n int) []byte {
+	b, _ := encodeUint(uint64(n))
+	return b[1:]
+}
+
+func rawEntry(key string, value any) []byte {
+	encoded, err := encode(value)
+	if err != nil {
+		panic(err)
+	}
+	return append(append([]byte{byte(len(key))}, key...), encoded...)
+}
+
+func TestNewSecureDecoder(t *testing.T) {
+	d := NewSecureDecoder()
+	assert.True(t, d.StrictMode)
+	assert.False(t, d.AllowUnknownTypes)
+	assert.True(t, d.ValidateUTF8)
+	assert.Equal(t, SecureMaxDepth, d.MaxDepth)
+	assert.Equal(t, int64(SecureMaxObjectSize), d.MaxObjectSize)
+	assert.Equal(t, SecureMaxElements, d.MaxElements)
+	assert.Equal(t, int64(SecureMemoryCeiling), d.MemoryCeiling)
+	assert.True(t, d.RejectDuplicateKeys)
+	assert.True(t, d.RejectTrailingBytes)
+
+	d = NewSecureDecoder(WithMaxElements(5), WithDecoderMaxDepth(3))
+	assert.Equal(t, 5, d.MaxElements)
+	assert.Equal(t, 3, d.MaxDepth)
+}
+
+func TestSecureDecoderAcceptsValidDocuments(t *testing.T) {
+	doc := map[string]any{
+		"name":  "Ada",
+		"tags":  []string{"a", "b"},
+		"items": []any{map[string]any{"id": 1}, "two", 3.5},
+		"meta":  map[string]any{"nested": map[string]any{"ok": true}},
+	}
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+
+	result, err := NewSecureDecoder().Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", result.(map[string]any)["name"])
+
+	type item struct {
+		ID   int64            `json:"id"`
+		Tags []string         `json:"tags"`
+		Refs map[int64]string `json:"refs"`
+	}
+	data, err = NewConfigurableEncoder(WithObjectLists(true)).Encode([]item{{1, []string{"a"}, map[int64]string{1: "b"}}, {ID: 2}})
+	require.NoError(t, err)
+	require.Equal(t, byte(TypeObjectList), data[1])
+	_, err = NewSecureDecoder().Decode(data)
+	require.NoError(t, err)
+}
+
+func TestSecureDecoderRejects(t *testing.T) {
+	nested := any("leaf")
+	for i := 0; i < 5; i++ {
+		nested = map[string]any{"n": nested}
+	}
+	deep, err := Marshal(nested)
+	require.NoError(t, err)
+
+	wide, err := Marshal(map[string]any{"a": 1, "b": 2, "c": 3})
+	require.NoError(t, err)
+
+	long, err := Marshal(map[string]any{"list": []any{1, 2, 3, 4}})
+	require.NoError(t, err)
+
+	typed, err := Marshal(map[string]any{"list": []int64{1, 2, 3, 4}})
+	require.NoError(t, err)
+
+	trailing, err := Marshal(map[string]any{"a": 1})
+	require.NoError(t, err)
+	trailing = append(trailing, 0x00)
+
+	duplicate := append([]byte{Version}, rawObject(rawEntry("a", 1), rawEntry("a", 2))...)
+
+	badKey := append([]byte{Version}, rawObject(rawEntry("\xff", 1))...)
+	badString := append([]byte{Version}, rawObject(rawEntry("s", "x\xffy"))...)
+
+	unknown := []byte{Version, 0x99}
+
+	longMap, err := Marshal(map[int64]any{1: 1, 2: 2, 3: 3, 4: 4})
+	require.NoError(t, err)
+	deepMap, err := Marshal(map[int64]any{1: []any{[]any{[]any{[]any{"x"}}}}})
+	require.NoError(t, err)
+	key, value := rawEntry("", int64(1))[1:], rawEntry("", "x\xffy")[1:]
+	badMap, err := buildContainer(TypeMap, append(append([]byte{TypeInt}, key...), value...))
+	require.NoError(t, err)
+	badMapValue := append([]byte{Version}, badMap...)
+
+	type row struct {
+		A any `json:"a"`
+		B any `json:"b"`
+		C any `json:"c"`
+		D any `json:"d"`
+	}
+	type cell struct {
+		A any `json:"a"`
+	}
+	objectLists := NewConfigurableEncoder(WithObjectLists(true))
+	longRows, err := objectLists.Encode([]cell{{1}, {2}, {3}, {4}})
+	require.NoError(t, err)
+	wideRows, err := objectLists.Encode([]row{{1, 2, 3, 4}, {5, 6, 7, 8}})
+	require.NoError(t, err)
+	deepRows, err := objectLists.Encode([]cell{{[]any{[]any{[]any{"x"}}}}, {nil}})
+	require.NoError(t, err)
+	rawRows := func(key string, value any) []byte {
+		payload := append(append(rawSize(1), rawSize(1)...), rawEntry(key, value)...)
+		list, err := buildContainer(TypeObjectList, payload)
+		require.NoError(t, err)
+		return append([]byte{Version}, list...)
+	}
+	longInner, err := Marshal([][]int64{{1}, {1, 2, 3, 4}})
+	require.NoError(t, err)
+	// Typed lists nested deeper than the single level the encoder writes
+	deepInner, err := buildTypedList(TypeInt, 1, []byte{1, 2})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		deepInner, err = buildTypedList(TypeTypedList, 1, deepInner[1:])
+		require.NoError(t, err)
+	}
+	deepInner = append([]byte{Version}, deepInner...)
+	badInner, err := Marshal([][]string{{"a"}, {"x\xffy"}})
+	require.NoError(t, err)
+	require.Equal(t, byte(TypeTypedList), badInner[1])
+	badRowKey := rawRows("\xff", 1)
+	badRowValue := rawRows("a", "x\xffy")
+
+	small := []DecoderOption{WithMaxElements(3), WithDecoderMaxDepth(4)}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"deep nesting", deep, "maximum nesting depth exceeded (4)"},
+		{"wide object", wide, ""},
+		{"long list", long, "list has more than 3 elements"},
+		{"long typed list", typed, "typed list has more than 3 elements"},
+		{"trailing bytes", trailing, "1 trailing bytes after value"},
+		{"duplicate keys", duplicate, `duplicate object key "a"`},
+		{"invalid key", badKey, "invalid UTF-8 in object key"},
+		{"invalid nested string", badString, "invalid UTF-8 in string"},
+		{"unknown type", unknown, "unsupported type 153"},
+		{"long map", longMap, "map has more than 3 elements"},
+		{"deep map", deepMap, "maximum nesting depth exceeded (4)"},
+		{"invalid map value", badMapValue, "invalid UTF-8 in string"},
+		{"long inner typed list", longInner, "typed list has more than 3 elements"},
+		{"deep typed lists", deepInner, "maximum nesting depth exceeded (4)"},
+		{"invalid inner typed list string", badInner, "invalid UTF-8 in string"},
+		{"long object list", longRows, "list has more than 3 elements"},
+		{"wide object list", wideRows, "object has more than 3 elements"},
+		{"deep object list", deepRows, "maximum nesting depth exceeded (4)"},
+		{"invalid object list key", badRowKey, "invalid UTF-8 in object key"},
+		{"invalid object list value", badRowValue, "invalid UTF-8 in string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSecureDecoder(small...).Decode(tt.data)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, structureErr))
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	t.Run("oversized document", func(t *testing.T) {
+		data, err := Marshal(strings.Repeat("x", 200))
+		require.NoError(t, err)
+		_, err = NewSecureDecoder(WithMaxObjectSize(100)).Decode(data)
+		assert.ErrorContains(t, err, "maximum object size exceeded (100 bytes)")
+	})
+
+	t.Run("memory ceiling", func(t *testing.T) {
+		obj := make(map[string]any)
+		for _, key := range strings.Split("abcdefghijklmnopqrst", "") {
+			obj[key] = true
+		}
+		data, err := Marshal(obj)
+		require.NoError(t, err)
+		_, err = NewSecureDecoder(WithMemoryCeiling(512)).Decode(data)
+		assert.True(t, errors.Is(err, ErrMemoryCeiling))
+	})
+}
+
+func TestDecoderLimitsAreOptIn(t *testing.T) {
+	trailing, err := Marshal(map[string]any{"a": 1})
+	require.NoError(t, err)
+	trailing = append(trailing, 0x00)
+	duplicate := append([]byte{Version}, rawObject(rawEntry("a", 1), rawEntry("a", 2))...)
+
+	d := NewConfigurableDecoder()
+	_, err = d.Decode(trailing)
+	assert.NoError(t, err)
+
+	result, err := d.Decode(duplicate)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int64(2)}, result)
+
+	_, err = NewConfigurableDecoder(WithDuplicateKeyRejection(true)).Decode(duplicate)
+	assert.ErrorContains(t, err, "duplicate object key")
+	_, err = NewConfigurableDecoder(WithTrailingBytesRejection(true)).Decode(trailing)
+	assert.ErrorContains(t, err, "trailing bytes")
+}
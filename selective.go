@@ -0,0 +1,122 @@
+package bogo
+
+import "fmt"
+
+// DecodeSelective decodes data with the default decoder, keeping only fields
+// of the top-level object
+func DecodeSelective(data []byte, fields []string) (any, error) {
+	return defaultDecoder.DecodeSelective(data, fields)
+}
+
+// DecodeSelective decodes data like Decode with WithSelectiveFields(fields),
+// without changing the decoder. The field set belongs to the call, so requests
+// wanting different fields can share one decoder, concurrently too, as long
+// as its configuration is not changed meanwhile.
+func (d *Decoder) DecodeSelective(data []byte, fields []string) (any, error) {
+	c := *d
+	c.SelectiveFields = fields
+	return c.Decode(data)
+}
+
+// UnmarshalSelective decodes the fields of data into v like Unmarshal with
+// WithSelectiveFields(fields), without changing the decoder
+func (d *Decoder) UnmarshalSelective(data []byte, v any, fields []string) error {
+	c := *d
+	c.SelectiveFields = fields
+	return c.Unmarshal(data, v)
+}
+
+// Selection is a tree of object keys to decode. A key mapped to nil keeps its
+// whole value; a key mapped to a Selection applies it to the value, to each
+// element when the value is a list. Unselected fields are skipped using their
+// size information, without being decoded.
+type Selection map[string]Selection
+
+// DecodeSelection decodes data with the default decoder, keeping only the
+// fields of sel
+func DecodeSelection(data []byte, sel Selection) (any, error) {
+	return defaultDecoder.DecodeSelection(data, sel)
+}
+
+// DecodeSelection decodes data like Decode, keeping only the fields of sel at
+// every level. A nil selection decodes everything. Like DecodeSelective, it
+// does not change the decoder.
+func (d *Decoder) DecodeSelection(data []byte, sel Selection) (any, error) {
+	c := *d
+	data, err := c.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	if sel == nil {
+		return c.decodePrepared(data)
+	}
+	v, err := c.decodeSelected(data[1:], sel)
+	return v, c.deadlineErr(err)
+}
+
+// decodeSelected decodes value, starting at its type byte, keeping the fields
+// of sel, also below a symbol table. Values other than objects and lists are
+// decoded whole.
+func (d *Decoder) decodeSelected(value []byte, sel Selection) (any, error) {
+	switch Type(value[0]) {
+	case TypeSymbols:
+		return d.decodeSymbols(value[1:], func(v []byte) (any, error) {
+			return d.decodeSelected(v, sel)
+		})
+
+	case TypeObject, TypeKeyedObject:
+		fields, err := objectFields(value)
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			return nil, nil
+		}
+		obj := make(map[string]any, len(sel))
+		var fieldErr error
+		err = walkObject(value, fields, d.symbols, func(_, _ int, key, v []byte) bool {
+			sub, ok := sel[string(key)]
+			if !ok {
+				return true
+			}
+			k := d.strings().key(key)
+			if sub == nil {
+				obj[k], fieldErr = d.decode(v)
+			} else {
+				obj[k], fieldErr = d.decodeSelected(v, sub)
+			}
+			if fieldErr != nil {
+				fieldErr = fmt.Errorf("bogo decode error: field %s: %w", k, fieldErr)
+			}
+			return fieldErr == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return obj, fieldErr
+
+	case TypeUntypedList:
+		elements, err := containerPayload(value[1:])
+		if err != nil {
+			return nil, wrapError(documentErr, err.Error())
+		}
+		list := make([]any, 0, countElements(elements))
+		for pos := 0; pos < len(elements); {
+			size, err := getElementSize(elements[pos:])
+			if err != nil {
+				return nil, wrapError(documentErr, err.Error())
+			}
+			if size <= 0 || pos+size > len(elements) {
+				return nil, wrapError(documentErr, "insufficient data for list element")
+			}
+			elem, err := d.decodeSelected(elements[pos:pos+size], sel)
+			if err != nil {
+				return nil, fmt.Errorf("bogo decode error: element %d: %w", len(list), err)
+			}
+			list = append(list, elem)
+			pos += size
+		}
+		return list, nil
+	}
+	return d.decode(value)
+}
@@ -0,0 +1,98 @@
+package bogo
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSelective(t *testing.T) {
+	data, err := Marshal(map[string]any{"id": int64(1), "name": "Ada", "email": "ada@example.com"})
+	require.NoError(t, err)
+
+	d := NewConfigurableDecoder()
+	result, err := d.DecodeSelective(data, []string{"name"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Ada"}, result)
+	assert.Empty(t, d.SelectiveFields, "the decoder is unchanged")
+
+	result, err = DecodeSelective(data, []string{"id", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": int64(1)}, result)
+
+	var user struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	require.NoError(t, d.UnmarshalSelective(data, &user, []string{"email"}))
+	assert.Equal(t, "", user.Name)
+	assert.Equal(t, "ada@example.com", user.Email)
+
+	t.Run("concurrent requests share a decoder", func(t *testing.T) {
+		fields := []string{"id", "name", "email"}
+		var wg sync.WaitGroup
+		for i := 0; i < 32; i++ {
+			wg.Add(1)
+			go func(field string) {
+				defer wg.Done()
+				result, err := d.DecodeSelective(data, []string{field})
+				if assert.NoError(t, err) {
+					assert.Equal(t, []string{field}, keysOf(result.(map[string]any)))
+				}
+			}(fields[i%len(fields)])
+		}
+		wg.Wait()
+	})
+}
+
+func TestDecodeSelection(t *testing.T) {
+	data, err := Marshal(map[string]any{
+		"id": int64(1),
+		"author": map[string]any{
+			"name":  "Ada",
+			"email": "ada@example.com",
+			"tags":  []string{"math"},
+		},
+		"comments": []any{
+			map[string]any{"body": "first", "likes": int64(3)},
+			map[string]any{"body": "second", "likes": int64(5)},
+			nil,
+		},
+		"body": "unselected",
+	})
+	require.NoError(t, err)
+
+	result, err := DecodeSelection(data, Selection{
+		"id":       nil,
+		"author":   {"name": nil, "tags": nil},
+		"comments": {"likes": nil},
+		"missing":  nil,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int64(1),
+		"author":   map[string]any{"name": "Ada", "tags": []string{"math"}},
+		"comments": []any{map[string]any{"likes": int64(3)}, map[string]any{"likes": int64(5)}, nil},
+	}, result)
+
+	t.Run("nil selection decodes everything", func(t *testing.T) {
+		all, err := DecodeSelection(data, nil)
+		require.NoError(t, err)
+		full, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, full, all)
+	})
+
+	t.Run("scalars ignore nested selections", func(t *testing.T) {
+		result, err := DecodeSelection(data, Selection{"body": {"x": nil}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"body": "unselected"}, result)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := DecodeSelection(data[:len(data)-3], Selection{"id": nil})
+		assert.Error(t, err)
+	})
+}
@@ -1,12 +1,16 @@
 package bogo
 
 import (
+	"bufio"
+	"fmt"
 	"io"
+	"iter"
 )
 
 // StreamEncoder writes bogo values to an output stream, similar to json.Encoder
 type StreamEncoder struct {
 	w       io.Writer
+	tee     io.Writer
 	encoder *Encoder
 }
 
@@ -26,15 +30,23 @@ func NewEncoderWithOptions(w io.Writer, options ...EncoderOption) *StreamEncoder
 	}
 }
 
-// Encode encodes v and writes it to the stream, similar to json.Encoder.Encode
+// Encode encodes v and writes it to the stream, similar to json.Encoder.Encode.
+// With WithFraming(LengthPrefixed) the document is preceded by its size
+// prefix, so any number of values can be written to one stream.
 func (enc *StreamEncoder) Encode(v any) error {
 	data, err := enc.encoder.Encode(v)
 	if err != nil {
 		return err
 	}
 
-	_, err = enc.w.Write(data)
-	return err
+	return teeWrite(enc.w, enc.tee, enc.encoder.Framing.frame(data))
+}
+
+// SetTee sets a writer, such as a hash.Hash, that also receives every byte the
+// stream writes from now on, so a stream can be checksummed or signed as it is
+// written. A nil tee stops the copying.
+func (enc *StreamEncoder) SetTee(tee io.Writer) {
+	enc.tee = tee
 }
 
 // SetEncoder allows setting a custom encoder instance
@@ -66,14 +78,31 @@ func NewDecoderWithOptions(r io.Reader, options ...DecoderOption) *StreamDecoder
 	}
 }
 
-// Decode reads the next bogo value from the stream and stores it in v, similar to json.Decoder.Decode
+// Decode reads the next bogo value from the stream and stores it in v, similar to json.Decoder.Decode.
+// With WithDecoderFraming(LengthPrefixed) it reads one frame and returns
+// io.EOF once the stream ends between frames; otherwise it reads the stream
+// to its end.
 func (dec *StreamDecoder) Decode(v any) error {
-	result, err := dec.decoder.DecodeFrom(dec.r)
+	var data []byte
+	var err error
+	if dec.decoder.Framing == LengthPrefixed {
+		data, err = readFrame(dec.r)
+		if err == io.EOF {
+			return err
+		}
+	} else if data, err = io.ReadAll(dec.r); err == nil {
+		err = checkRaw(data)
+	}
+	if err != nil {
+		return fmt.Errorf("bogo decode error: failed to read data: %w", err)
+	}
+
+	result, err := dec.decoder.Decode(data)
 	if err != nil {
 		return err
 	}
 
-	return assignResult(result, v)
+	return assignResultWith(result, v, dec.decoder)
 }
 
 // SetDecoder allows setting a custom decoder instance
@@ -82,3 +111,39 @@ func (dec *StreamDecoder) SetDecoder(d *Decoder) {
 		dec.decoder = d
 	}
 }
+
+// UnmarshalSeq returns an iterator over the records of a framed stream, each a
+// document written by WriteLengthPrefixed, decoding them with the default
+// decoder one at a time as the loop asks for them:
+//
+//	for order, err := range bogo.UnmarshalSeq[Order](file) {
+//		if err != nil {
+//			return err
+//		}
+//		process(order)
+//	}
+//
+// A record that fails to decode is yielded with its error and the iteration
+// goes on to the next frame; a stream that cannot be read, such as one ending
+// inside a frame, ends the iteration after its error. Reads are buffered, so
+// stopping early may leave r positioned past the last record yielded.
+func UnmarshalSeq[T any](r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		br := bufio.NewReader(r)
+		for {
+			var v T
+			frame, err := ReadLengthPrefixed(br)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(v, err)
+				return
+			}
+			err = defaultDecoder.Unmarshal(frame, &v)
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
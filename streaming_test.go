@@ -2,6 +2,10 @@ package bogo
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -182,6 +186,119 @@ func TestStreamingAPICompatibility(t *testing.T) {
 	})
 }
 
+// shortWriter accepts limit bytes, then fails
+type shortWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		n := w.limit - w.buf.Len()
+		w.buf.Write(p[:n])
+		return n, errors.New("short write")
+	}
+	return w.buf.Write(p)
+}
+
+func TestEncodeToTee(t *testing.T) {
+	value := map[string]any{"id": int64(7), "payload": bytes.Repeat([]byte("x"), 1000)}
+
+	t.Run("hashes the written bytes", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := sha256.New()
+		require.NoError(t, NewConfigurableEncoder().EncodeToTee(&buf, h, value))
+		sum := sha256.Sum256(buf.Bytes())
+		assert.Equal(t, sum[:], h.Sum(nil))
+	})
+
+	t.Run("tee gets what reached the writer", func(t *testing.T) {
+		w := &shortWriter{limit: 10}
+		var tee bytes.Buffer
+		assert.Error(t, NewConfigurableEncoder().EncodeToTee(w, &tee, value))
+		assert.Equal(t, w.buf.Bytes(), tee.Bytes())
+	})
+
+	t.Run("stream encoder", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := sha256.New()
+		enc := NewEncoder(&buf)
+		require.NoError(t, enc.Encode("before"))
+		before := buf.Len()
+
+		enc.SetTee(h)
+		require.NoError(t, enc.Encode(value))
+		require.NoError(t, enc.Encode([]any{"more", int64(1)}))
+		sum := sha256.Sum256(buf.Bytes()[before:])
+		assert.Equal(t, sum[:], h.Sum(nil))
+
+		enc.SetTee(nil)
+		require.NoError(t, enc.Encode("after"))
+		assert.Equal(t, sum[:], h.Sum(nil))
+	})
+}
+
+func TestFraming(t *testing.T) {
+	values := []any{"first", int64(2), map[string]any{"third": true}}
+
+	t.Run("length-prefixed streams hold many values", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoderWithOptions(&buf, WithFraming(LengthPrefixed))
+		for _, v := range values {
+			require.NoError(t, enc.Encode(v))
+		}
+
+		first, err := ReadLengthPrefixed(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{Version, TypeString, 1, 5, 'f', 'i', 'r', 's', 't'}, first)
+
+		dec := NewDecoderWithOptions(&buf, WithDecoderFraming(LengthPrefixed))
+		for _, want := range values {
+			var got any
+			require.NoError(t, dec.Decode(&got))
+			assert.Equal(t, want, got)
+		}
+		var got any
+		assert.Equal(t, io.EOF, dec.Decode(&got))
+	})
+
+	t.Run("raw by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewEncoder(&buf).Encode("raw"))
+		assert.Equal(t, byte(Version), buf.Bytes()[0])
+		assert.Equal(t, "raw", Raw.String())
+		assert.Equal(t, "length-prefixed", LengthPrefixed.String())
+	})
+
+	t.Run("sides must agree", func(t *testing.T) {
+		var framed, raw bytes.Buffer
+		require.NoError(t, NewEncoderWithOptions(&framed, WithFraming(LengthPrefixed)).Encode("value"))
+		require.NoError(t, NewEncoder(&raw).Encode("value"))
+
+		var got any
+		err := NewDecoder(&framed).Decode(&got)
+		assert.ErrorIs(t, err, framingErr)
+		assert.ErrorContains(t, err, "stream is length-prefixed")
+
+		err = NewDecoderWithOptions(&raw, WithDecoderFraming(LengthPrefixed)).Decode(&got)
+		assert.ErrorIs(t, err, framingErr)
+		assert.ErrorContains(t, err, "stream is raw")
+
+		var magic bytes.Buffer
+		require.NoError(t, NewEncoderWithOptions(&magic, WithMagicPrefix(true)).Encode("value"))
+		err = NewDecoderWithOptions(&magic, WithDecoderFraming(LengthPrefixed)).Decode(&got)
+		assert.ErrorIs(t, err, framingErr)
+	})
+
+	t.Run("truncated frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewEncoderWithOptions(&buf, WithFraming(LengthPrefixed)).Encode("value"))
+		var got any
+		err := NewDecoderWithOptions(bytes.NewReader(buf.Bytes()[:4]), WithDecoderFraming(LengthPrefixed)).Decode(&got)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
 // Test that streaming API follows json package naming and behavior
 func TestStreamingAPINaming(t *testing.T) {
 	t.Run("Constructor names match json", func(t *testing.T) {
@@ -280,3 +397,80 @@ func BenchmarkStreamingDecoding(b *testing.B) {
 		}
 	})
 }
+
+func TestUnmarshalSeq(t *testing.T) {
+	type record struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var stream bytes.Buffer
+	for i := 0; i < 100; i++ {
+		data, err := Marshal(record{ID: int64(i), Name: fmt.Sprint("r", i)})
+		require.NoError(t, err)
+		_, err = WriteLengthPrefixed(&stream, data)
+		require.NoError(t, err)
+	}
+
+	var got []record
+	for r, err := range UnmarshalSeq[record](bytes.NewReader(stream.Bytes())) {
+		require.NoError(t, err)
+		got = append(got, r)
+	}
+	require.Len(t, got, 100)
+	assert.Equal(t, record{ID: 99, Name: "r99"}, got[99])
+
+	t.Run("stopping early", func(t *testing.T) {
+		n := 0
+		for range UnmarshalSeq[record](bytes.NewReader(stream.Bytes())) {
+			n++
+			if n == 3 {
+				break
+			}
+		}
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("records that fail to decode", func(t *testing.T) {
+		var stream bytes.Buffer
+		for _, v := range []any{record{ID: 1}, "not a record", record{ID: 3}} {
+			data, err := Marshal(v)
+			require.NoError(t, err)
+			_, err = WriteLengthPrefixed(&stream, data)
+			require.NoError(t, err)
+		}
+
+		var ids []int64
+		var errs int
+		for r, err := range UnmarshalSeq[record](&stream) {
+			if err != nil {
+				errs++
+				continue
+			}
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, []int64{1, 3}, ids)
+		assert.Equal(t, 1, errs)
+	})
+
+	t.Run("truncated stream", func(t *testing.T) {
+		var errs []error
+		n := 0
+		for _, err := range UnmarshalSeq[record](bytes.NewReader(stream.Bytes()[:stream.Len()-3])) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			n++
+		}
+		assert.Equal(t, 99, n)
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], io.ErrUnexpectedEOF)
+	})
+
+	t.Run("empty stream", func(t *testing.T) {
+		for range UnmarshalSeq[record](bytes.NewReader(nil)) {
+			t.Fatal("no records expected")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package bogo
 import (
 	"bytes"
 	"errors"
+	"fmt"
 )
 
 var stringEncodeError = errors.New("string encoding error")
@@ -25,10 +26,81 @@ func encodeString(v string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// encodeCompactString encodes v as TypeFixStr when it is short enough, and
+// with the regular string encoding otherwise
+func encodeCompactString(v string) ([]byte, error) {
+	if len(v) > maxFixStr {
+		return encodeString(v)
+	}
+	return append([]byte{byte(TypeFixStr) | byte(len(v))}, v...), nil
+}
+
 func decodeString(data []byte, sizeLen int) (any, error) {
+	return decodeStringWith(data, sizeLen, stringMaker{})
+}
+
+// decodeStringWith decodes a string, building it with strs
+func decodeStringWith(data []byte, sizeLen int, strs stringMaker) (any, error) {
+	if len(data) < sizeLen {
+		return nil, errors.New("insufficient data for string size")
+	}
 	size, err := decodeUint(data[:sizeLen])
 	if err != nil {
 		return nil, err
 	}
-	return string(data[sizeLen : sizeLen+int(size)]), nil
+	if size > uint64(len(data)-sizeLen) {
+		return nil, errors.New("insufficient data for string")
+	}
+	return strs.str(data[sizeLen : sizeLen+int(size)]), nil
+}
+
+// stringMaker builds the strings and object keys of a decode. The zero value
+// copies bytes into new strings.
+type stringMaker struct {
+	intern func([]byte) string // Decoder.StringInterner
+	keys   *KeyCache           // Decoder.KeyCache
+
+	deadline *decodeDeadline // Decoder.DecodeDeadline, checked for every value
+	numbers  bool            // Decoder.UseNumber
+
+	symbols []string // table of the enclosing TypeSymbols
+}
+
+// str returns b as a string, through intern when it is set
+func (m stringMaker) str(b []byte) string {
+	if m.intern != nil {
+		return m.intern(b)
+	}
+	return string(b)
+}
+
+// number returns v as a Number when numbers are requested and v is one, and
+// v unchanged otherwise
+func (m stringMaker) number(v any, err error) (any, error) {
+	if m.numbers && err == nil {
+		if n, ok := numberOf(v); ok {
+			return n, nil
+		}
+	}
+	return v, err
+}
+
+// symbol returns the symbol referred to by data, the index of a TypeSymbol
+func (m stringMaker) symbol(data []byte) (any, error) {
+	index, _, err := symbolIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	if index >= uint64(len(m.symbols)) {
+		return nil, wrapError(enumErr, fmt.Sprintf("symbol %d outside a table of %d", index, len(m.symbols)))
+	}
+	return m.symbols[index], nil
+}
+
+// key returns the object key b as a string, from the key cache when there is one
+func (m stringMaker) key(b []byte) string {
+	if m.keys != nil {
+		return m.keys.key(b, m)
+	}
+	return m.str(b)
 }
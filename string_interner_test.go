@@ -0,0 +1,89 @@
+package bogo
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stringTable interns strings and counts how often it is asked to
+type stringTable struct {
+	mu      sync.Mutex
+	strings map[string]string
+	calls   int
+}
+
+func (st *stringTable) intern(b []byte) string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.calls++
+	if s, ok := st.strings[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	st.strings[s] = s
+	return s
+}
+
+func TestStringInterner(t *testing.T) {
+	rows := make([]any, 3)
+	for i := range rows {
+		rows[i] = map[string]any{"status": "active"}
+	}
+	data, err := Marshal(map[string]any{"rows": rows, "tags": []string{"active", "active"}})
+	require.NoError(t, err)
+
+	table := &stringTable{strings: map[string]string{}}
+	d := NewConfigurableDecoder(WithStringInterner(table.intern))
+	result, err := d.Decode(data)
+	require.NoError(t, err)
+
+	obj := result.(map[string]any)
+	statuses := obj["tags"].([]string)
+	for _, row := range obj["rows"].([]any) {
+		statuses = append(statuses, row.(map[string]any)["status"].(string))
+	}
+	assert.Equal(t, 10, table.calls, "keys, values and typed list elements are interned")
+	assert.Len(t, table.strings, 4)
+	for _, s := range statuses {
+		assert.Equal(t, "active", s)
+		assert.Equal(t, unsafe.StringData(statuses[0]), unsafe.StringData(s), "duplicates share memory")
+	}
+
+	t.Run("top-level and compact strings", func(t *testing.T) {
+		table := &stringTable{strings: map[string]string{}}
+		d := NewConfigurableDecoder(WithStringInterner(table.intern))
+
+		for _, encoder := range []*Encoder{NewConfigurableEncoder(), NewConfigurableEncoder(WithCompactStrings(true))} {
+			data, err := encoder.Encode("ok")
+			require.NoError(t, err)
+			result, err := d.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, "ok", result)
+		}
+		assert.Equal(t, 2, table.calls)
+	})
+
+	t.Run("unmarshal into structs", func(t *testing.T) {
+		table := &stringTable{strings: map[string]string{}}
+		d := NewConfigurableDecoder(WithStringInterner(table.intern))
+
+		var got struct {
+			Status string `json:"status"`
+		}
+		data, err := Marshal(map[string]any{"status": "done"})
+		require.NoError(t, err)
+		require.NoError(t, d.Unmarshal(data, &got))
+		assert.Equal(t, "done", got.Status)
+		assert.Contains(t, table.strings, "done")
+	})
+
+	t.Run("UTF-8 is still validated", func(t *testing.T) {
+		d := NewConfigurableDecoder(WithStringInterner(func(b []byte) string { return string(b) }))
+		_, err := d.Decode([]byte{Version, TypeString, 1, 1, 0xff})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,231 @@
+package bogo
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"unicode/utf8"
+)
+
+// errIndirect stops the direct path for values it leaves to the general one
+var errIndirect = errors.New("value takes the general path")
+
+// directField is a struct field filled by the direct path
+type directField struct {
+	index  int
+	format string // declared with the format tag option
+}
+
+// directKey identifies the fields of a struct type under a tag name
+type directKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// directFields caches the fields of struct types by their wire names
+var directFields sync.Map // directKey -> map[string][]directField
+
+// unmarshalStruct decodes an object value into the struct v points to, or a
+// list of objects into the slice of structs it points to, matching keys
+// against the fields of the struct while walking the entries and decoding
+// each value straight into its field, so no map of the object is built and
+// the values of unknown keys are never decoded. Nested objects bound for
+// plain structs are filled the same way. It reports whether v was filled;
+// other destinations, and documents that fail on the direct path, take the
+// general path, which reports their errors.
+func (d *Decoder) unmarshalStruct(value []byte, v any) bool {
+	if len(d.coercions) > 0 || d.TypedValues || d.Salvage || d.LazyObjects || len(d.SelectiveFields) > 0 {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return false
+	}
+	dest := rv.Elem()
+	switch Type(value[0]) {
+	case TypeObject:
+		if !directStruct(dest.Type()) {
+			return false
+		}
+		fields, err := objectFields(value)
+		if err != nil || fields == nil || legacyRegistered(fields) {
+			return false
+		}
+		return d.fillStruct(fields, dest, d.strings()) == nil
+	case TypeUntypedList:
+		if !directSlice(dest.Type()) {
+			return false
+		}
+		return d.fillSlice(value, dest, d.strings()) == nil
+	}
+	return false
+}
+
+// directStruct reports whether struct type t is filled field by field from
+// objects, as assignMapToStruct does, rather than decoded as a whole
+func directStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || nullableValue(t) != nil {
+		return false
+	}
+	switch t {
+	case timeType, bigIntType, bigFloatType, graphType, localizedStringType, expiryType, unionType:
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+// directSlice reports whether t is a slice of plain structs or of pointers to
+// them, filled element by element
+func directSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || nullableValue(t) != nil || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return directStruct(elem)
+}
+
+// legacyRegistered reports whether the entries of an object may be those of
+// a registered value encoded before unions, which assignRegistered unwraps
+func legacyRegistered(fields []byte) bool {
+	n, found := 0, false
+	_ = walkEntries(fields, func(_, _ int, key, _ []byte) bool {
+		n++
+		found = found || string(key) == registryTypeKey
+		return n <= 2
+	})
+	return found && n == 2
+}
+
+// directFieldsOf returns the fields of struct type t by the names
+// assignMapToStruct matches them with. Names shared by several fields fill
+// each of them.
+func directFieldsOf(t reflect.Type, tagName string) map[string][]directField {
+	key := directKey{t, tagName}
+	if fields, ok := directFields.Load(key); ok {
+		return fields.(map[string][]directField)
+	}
+	fields := make(map[string][]directField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := getStructFieldName(field, tagName)
+		if name == "-" {
+			continue
+		}
+		fields[name] = append(fields[name], directField{index: i, format: getStructFieldFormat(field, tagName)})
+	}
+	directFields.Store(key, fields)
+	return fields
+}
+
+// fillStruct assigns the entries of an object payload to the fields of dest
+func (d *Decoder) fillStruct(fields []byte, dest reflect.Value, strs stringMaker) error {
+	if err := strs.deadline.check(); err != nil {
+		return err
+	}
+	table := directFieldsOf(dest.Type(), structTagName(dest.Type(), d.TagName))
+	var err error
+	walkErr := walkEntries(fields, func(_, _ int, key, value []byte) bool {
+		if d.StrictMode && d.ValidateUTF8 && !utf8.Valid(key) {
+			err = errIndirect
+			return false
+		}
+		for _, f := range table[string(key)] {
+			field := dest.Field(f.index)
+			if f.format == "" {
+				err = d.fillValue(value, field, strs)
+			} else {
+				err = d.fillFormatted(value, field, f.format, strs)
+			}
+			if err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	return err
+}
+
+// fillValue decodes value into dest, filling plain structs, pointers to them
+// and slices of them directly from objects and lists of objects
+func (d *Decoder) fillValue(value []byte, dest reflect.Value, strs stringMaker) error {
+	switch Type(value[0]) {
+	case TypeObject:
+		t := dest.Type()
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if !directStruct(t) {
+			break
+		}
+		fields, err := objectFields(value)
+		if err != nil {
+			return err
+		}
+		if fields == nil || legacyRegistered(fields) {
+			break
+		}
+		if dest.Kind() == reflect.Pointer {
+			if dest.IsNil() {
+				dest.Set(reflect.New(t))
+			}
+			dest = dest.Elem()
+		}
+		return d.fillStruct(fields, dest, strs)
+
+	case TypeUntypedList:
+		if directSlice(dest.Type()) {
+			return d.fillSlice(value, dest, strs)
+		}
+	}
+	decoded, err := decodeValueWith(value, strs)
+	if err != nil {
+		return err
+	}
+	return assignValueToField(decoded, dest, d)
+}
+
+// fillFormatted decodes value into dest once it matches format
+func (d *Decoder) fillFormatted(value []byte, dest reflect.Value, format string, strs stringMaker) error {
+	decoded, err := decodeValueWith(value, strs)
+	if err != nil {
+		return err
+	}
+	if err := validateFormat(decoded, format); err != nil {
+		return err
+	}
+	return assignValueToField(decoded, dest, d)
+}
+
+// fillSlice decodes the elements of a list into a new slice stored in dest
+func (d *Decoder) fillSlice(value []byte, dest reflect.Value, strs stringMaker) error {
+	elements, err := containerPayload(value[1:])
+	if err != nil {
+		return err
+	}
+	n := countElements(elements)
+	list := reflect.MakeSlice(dest.Type(), n, n)
+	for i, pos := 0, 0; pos < len(elements); i++ {
+		size, err := getElementSize(elements[pos:])
+		if err != nil {
+			return err
+		}
+		if size <= 0 || pos+size > len(elements) || i >= n {
+			return errIndirect
+		}
+		if err := d.fillValue(elements[pos:pos+size], list.Index(i), strs); err != nil {
+			return err
+		}
+		pos += size
+	}
+	dest.Set(list)
+	return nil
+}
@@ -0,0 +1,134 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type directItem struct {
+	SKU   string  `json:"sku"`
+	Qty   int     `json:"qty"`
+	Price float64 `json:"price"`
+}
+
+type directOrder struct {
+	ID       int64          `json:"id"`
+	Buyer    string         `json:"buyer,omitempty"`
+	Email    string         `json:"email,format=email"`
+	Placed   time.Time      `json:"placed"`
+	Items    []directItem   `json:"items"`
+	Primary  *directItem    `json:"primary"`
+	Extra    map[string]any `json:"extra"`
+	Tags     []string       `json:"tags"`
+	Internal string         `json:"-"`
+	hidden   string
+}
+
+func TestUnmarshalStructs(t *testing.T) {
+	order := map[string]any{
+		"id":      int64(7),
+		"buyer":   "ama",
+		"email":   "ama@example.com",
+		"placed":  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		"items":   []any{map[string]any{"sku": "a", "qty": 2, "price": 1.5}, map[string]any{"sku": "b"}},
+		"primary": map[string]any{"sku": "a", "qty": 2},
+		"extra":   map[string]any{"note": "gift"},
+		"tags":    []string{"x", "y"},
+		"unknown": map[string]any{"deep": []any{1, "two"}},
+		"-":       "skipped",
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		dst    func() any
+		direct bool
+	}{
+		{"struct", mustMarshal(t, order), func() any { return new(directOrder) }, true},
+		{"into a filled struct", mustMarshal(t, map[string]any{"qty": 3}), func() any { return &directItem{SKU: "kept"} }, true},
+		{"into a set pointer", mustMarshal(t, map[string]any{"primary": map[string]any{"qty": 3}}), func() any {
+			return &directOrder{Primary: &directItem{SKU: "kept"}}
+		}, true},
+		{"null field", mustMarshal(t, map[string]any{"primary": nil, "items": nil}), func() any {
+			return &directOrder{Primary: &directItem{}, Items: []directItem{{}}}
+		}, true},
+		{"slice of structs", mustMarshal(t, []any{map[string]any{"sku": "a"}, map[string]any{"qty": 1}}), func() any { return new([]directItem) }, true},
+		{"slice of pointers", mustMarshal(t, []any{map[string]any{"sku": "a"}, nil}), func() any { return new([]*directItem) }, true},
+		{"empty slice", mustMarshal(t, []any{}), func() any { return new([]directItem) }, true},
+		{"repeated keys", append([]byte{Version}, rawObject(rawEntry("sku", "a"), rawEntry("sku", "b"))...), func() any { return new(directItem) }, true},
+		{"null object", append([]byte{Version}, rawObjectFields([]byte{TypeNull})...), func() any { return &directItem{SKU: "x"} }, false},
+		{"registered value", mustMarshal(t, map[string]any{registryTypeKey: "unknown", registryValueKey: "x"}), func() any { return new(directItem) }, false},
+		{"not an object", mustMarshal(t, "a"), func() any { return new(directItem) }, false},
+		{"not a struct", mustMarshal(t, order), func() any { return new(map[string]any) }, false},
+		{"time", mustMarshal(t, time.Now()), func() any { return new(time.Time) }, false},
+		{"overflow", mustMarshal(t, map[string]any{"qty": uint64(1 << 63)}), func() any { return new(directItem) }, false},
+		{"bad format", mustMarshal(t, map[string]any{"email": "nope"}), func() any { return new(directOrder) }, false},
+		{"wrong type", mustMarshal(t, map[string]any{"items": "a"}), func() any { return new(directOrder) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewConfigurableDecoder()
+			direct, general := tt.dst(), tt.dst()
+			assert.Equal(t, tt.direct, d.unmarshalStruct(tt.data[1:], tt.dst()), "direct path")
+			err := d.Unmarshal(tt.data, direct)
+			generalErr := unmarshalGeneral(d, tt.data, general)
+			if generalErr != nil {
+				assert.EqualError(t, err, generalErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, general, direct)
+		})
+	}
+
+	t.Run("decoder options", func(t *testing.T) {
+		data := mustMarshal(t, map[string]any{"sku": "a", "qty": 1})
+		for name, d := range map[string]*Decoder{
+			"selective": NewConfigurableDecoder(WithSelectiveFields([]string{"sku"})),
+			"coercion":  NewConfigurableDecoder(WithPathCoercion("sku", func(v any) (any, error) { return v, nil })),
+			"salvage":   NewConfigurableDecoder(WithSalvage(true)),
+		} {
+			assert.False(t, d.unmarshalStruct(data[1:], new(directItem)), name)
+		}
+
+		// Errors are those of the general path, collected when asked to be
+		d := NewConfigurableDecoder(WithCollectErrors(true))
+		data = mustMarshal(t, map[string]any{"qty": "x", "price": "y"})
+		var out directItem
+		var errs DecodeErrors
+		require.ErrorAs(t, d.Unmarshal(data, &out), &errs)
+		assert.Len(t, errs, 2)
+	})
+}
+
+func BenchmarkUnmarshalStruct(b *testing.B) {
+	items := make([]any, 20)
+	for i := range items {
+		items[i] = map[string]any{"sku": "sku", "qty": i, "price": 9.99}
+	}
+	data, err := Marshal(map[string]any{"id": 1, "buyer": "ama", "items": items})
+	require.NoError(b, err)
+
+	b.Run("Direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out directOrder
+			if err := Unmarshal(data, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("General", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out directOrder
+			if err := unmarshalGeneral(defaultDecoder, data, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
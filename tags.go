@@ -0,0 +1,56 @@
+package bogo
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	structTagsMu sync.RWMutex
+	structTags   = map[reflect.Type]string{}
+)
+
+// RegisterStructTag makes every encoder and decoder read the field names and
+// options of struct type T from the tagName tag, whatever their own TagName
+// and whichever tag a call asks for. A pointer type registers the struct it
+// points to. Registering lets legacy "json"-tagged types and newer
+// "bogo"-tagged types be encoded together, each by its own tags, without an
+// encoder per tag set. Registering a type again replaces its tag name.
+func RegisterStructTag[T any](tagName string) {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	structTagsMu.Lock()
+	defer structTagsMu.Unlock()
+	structTags[t] = tagName
+}
+
+// structTagName returns the tag name to read for the fields of struct type t,
+// which is fallback unless t was registered with RegisterStructTag
+func structTagName(t reflect.Type, fallback string) string {
+	structTagsMu.RLock()
+	defer structTagsMu.RUnlock()
+	if tagName, ok := structTags[t]; ok {
+		return tagName
+	}
+	return fallback
+}
+
+// MarshalWithTag encodes v like Marshal, reading struct tags from tagName
+// instead of the default encoder's TagName. Types registered with
+// RegisterStructTag keep their registered tag.
+func MarshalWithTag(v any, tagName string) ([]byte, error) {
+	e := *defaultEncoder
+	e.TagName = tagName
+	return e.Encode(v)
+}
+
+// UnmarshalWithTag decodes data into v like Unmarshal, reading struct tags
+// from tagName instead of the default decoder's TagName. Types registered with
+// RegisterStructTag keep their registered tag.
+func UnmarshalWithTag(data []byte, v any, tagName string) error {
+	d := *defaultDecoder
+	d.TagName = tagName
+	return d.Unmarshal(data, v)
+}
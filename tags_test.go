@@ -0,0 +1,84 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type legacyAccount struct {
+	ID    string `json:"account_id" bogo:"id"`
+	Notes string `json:"notes,omitempty" bogo:"notes"`
+}
+
+type taggedOrder struct {
+	Number  int            `json:"Number" bogo:"number"`
+	Account *legacyAccount `json:"Account" bogo:"account"`
+}
+
+func TestMarshalWithTag(t *testing.T) {
+	order := taggedOrder{Number: 7, Account: &legacyAccount{ID: "a1"}}
+
+	data, err := MarshalWithTag(order, "bogo")
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]any{
+		"number":  int64(7),
+		"account": map[string]any{"id": "a1", "notes": ""},
+	}, decoded)
+
+	var back taggedOrder
+	require.NoError(t, UnmarshalWithTag(data, &back, "bogo"))
+	assert.Equal(t, order, back)
+
+	// The default encoder is left untouched
+	data, err = Marshal(order)
+	require.NoError(t, err)
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Contains(t, decoded, "Number")
+}
+
+func TestRegisterStructTag(t *testing.T) {
+	type legacy struct {
+		ID    string `json:"legacy_id" bogo:"id"`
+		Notes string `json:"notes,omitempty" bogo:"notes"`
+	}
+	type current struct {
+		Name   string  `json:"Name" bogo:"name"`
+		Legacy *legacy `json:"Legacy" bogo:"legacy"`
+	}
+	RegisterStructTag[*legacy]("json")
+
+	value := current{Name: "x", Legacy: &legacy{ID: "l1"}}
+	data, err := MarshalWithTag(value, "bogo")
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]any{
+		"name":   "x",
+		"legacy": map[string]any{"legacy_id": "l1"}, // json tags, including omitempty
+	}, decoded)
+
+	var back current
+	require.NoError(t, UnmarshalWithTag(data, &back, "bogo"))
+	assert.Equal(t, value, back)
+
+	t.Run("applies to configured encoders", func(t *testing.T) {
+		e := NewConfigurableEncoder(WithStructTag("bogo"))
+		data, err := e.Encode(legacy{ID: "l2"})
+		require.NoError(t, err)
+
+		d := NewConfigurableDecoder(WithDecoderStructTag("bogo"))
+		var back legacy
+		require.NoError(t, d.Unmarshal(data, &back))
+		assert.Equal(t, "l2", back.ID)
+
+		id, err := FieldString(data, "legacy_id")
+		require.NoError(t, err)
+		assert.Equal(t, "l2", id)
+	})
+}
@@ -1,15 +1,51 @@
 package bogo
 
 import (
-	"encoding/binary"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 )
 
+// TimeFormat controls how time.Time values are represented on the wire
+type TimeFormat int
+
+const (
+	// TimeFormatMillis encodes time.Time as a TypeTimestamp holding Unix milliseconds (default)
+	TimeFormatMillis TimeFormat = iota
+	// TimeFormatNanos encodes time.Time as a TypeTimestampNanos holding Unix
+	// nanoseconds. Times outside the years 1678-2262 that int64 nanoseconds
+	// span, such as time.Time{}, are written as a TypeTimestamp instead.
+	// Documents written before TypeTimestampNanos hold a TypeInt, which
+	// readers need WithDecoderTimeFormat(TimeFormatNanos) to interpret.
+	TimeFormatNanos
+	// TimeFormatRFC3339String encodes time.Time as a TypeString in RFC 3339 format
+	// with nanosecond precision, trading compactness for readability
+	TimeFormatRFC3339String
+	// TimeFormatZoned encodes time.Time as a TypeZonedTimestamp holding Unix
+	// milliseconds together with the UTC offset and the name of the location,
+	// so times decode in the zone they were created in rather than in UTC
+	TimeFormatZoned
+)
+
+func (f TimeFormat) String() string {
+	switch f {
+	case TimeFormatMillis:
+		return "millis"
+	case TimeFormatNanos:
+		return "nanos"
+	case TimeFormatRFC3339String:
+		return "rfc3339"
+	case TimeFormatZoned:
+		return "zoned"
+	}
+	return "<unknown>"
+}
+
 func encodeTimestamp(timestamp int64) ([]byte, error) {
 	buf := make([]byte, 9) // 1 byte type + 8 bytes int64
 	buf[0] = byte(TypeTimestamp)
-	binary.LittleEndian.PutUint64(buf[1:], uint64(timestamp))
+	wireOrder.PutUint64(buf[1:], uint64(timestamp))
 	return buf, nil
 }
 
@@ -18,11 +54,212 @@ func decodeTimestamp(data []byte) (int64, error) {
 		return 0, fmt.Errorf("timestamp decode error: insufficient data, need 8 bytes, got %d", len(data))
 	}
 
-	timestamp := int64(binary.LittleEndian.Uint64(data[:8]))
+	timestamp := int64(wireOrder.Uint64(data[:8]))
 	return timestamp, nil
 }
 
+const timestampNanosSize = 8 // nanoseconds as a little-endian int64
+
+// The times Unix nanoseconds in an int64 can represent, roughly 1678-2262
+var (
+	minNanosTime = time.Unix(0, math.MinInt64)
+	maxNanosTime = time.Unix(0, math.MaxInt64)
+)
+
+// encodeTimestampNanos encodes t as a TypeTimestampNanos, or as a
+// TypeTimestamp when t is outside the range of int64 nanoseconds, such as
+// time.Time{}, trading precision for the right time
+func encodeTimestampNanos(t time.Time) ([]byte, error) {
+	if t.Before(minNanosTime) || t.After(maxNanosTime) {
+		return encodeTimestamp(t.UnixMilli())
+	}
+	buf := make([]byte, 1+timestampNanosSize)
+	buf[0] = byte(TypeTimestampNanos)
+	wireOrder.PutUint64(buf[1:], uint64(t.UnixNano()))
+	return buf, nil
+}
+
+// decodeTimestampNanos decodes a TypeTimestampNanos into a time.Time in UTC
+func decodeTimestampNanos(data []byte) (time.Time, error) {
+	if len(data) < timestampNanosSize {
+		return time.Time{}, fmt.Errorf("timestamp decode error: insufficient data, need %d bytes, got %d", timestampNanosSize, len(data))
+	}
+	return time.Unix(0, int64(wireOrder.Uint64(data[:timestampNanosSize]))).UTC(), nil
+}
+
 // Helper function to encode time.Time as timestamp
 func encodeTimeValue(t time.Time) ([]byte, error) {
 	return encodeTimestamp(t.UnixMilli())
 }
+
+// encodeTimeWithFormat encodes time.Time using the given wire representation
+func encodeTimeWithFormat(t time.Time, format TimeFormat) ([]byte, error) {
+	switch format {
+	case TimeFormatMillis:
+		return encodeTimestamp(t.UnixMilli())
+	case TimeFormatNanos:
+		return encodeTimestampNanos(t)
+	case TimeFormatRFC3339String:
+		return encodeString(t.Format(time.RFC3339Nano))
+	case TimeFormatZoned:
+		return encodeZonedTime(t)
+	}
+	return nil, fmt.Errorf("bogo encode error: unsupported time format %d", format)
+}
+
+// A zoned timestamp is laid out as
+//
+//	TypeZonedTimestamp | millis | offset | size prefix | name
+//
+// where millis is the Unix time in milliseconds as in TypeTimestamp, offset
+// the UTC offset in seconds as a little-endian int32, and name the name of
+// the location, such as "Europe/Paris", empty for the local time zone, whose
+// name means nothing to the reader.
+const zonedTimestampSize = 12 // millis and offset
+
+// encodeZonedTime encodes t as a TypeZonedTimestamp
+func encodeZonedTime(t time.Time) ([]byte, error) {
+	name := t.Location().String()
+	if t.Location() == time.Local {
+		name = ""
+	}
+	_, offset := t.Zone()
+	buf := make([]byte, 1+zonedTimestampSize, 1+zonedTimestampSize+maxStorageByteLength+len(name))
+	buf[0] = byte(TypeZonedTimestamp)
+	wireOrder.PutUint64(buf[1:], uint64(t.UnixMilli()))
+	wireOrder.PutUint32(buf[9:], uint32(int32(offset)))
+	buf = appendUvarintLen(buf, uint64(len(name)))
+	return append(buf, name...), nil
+}
+
+// decodeZonedTimestamp decodes a zoned timestamp into a time.Time in its
+// location when this system knows the location under that name and offset,
+// and in a fixed zone with the name and offset otherwise
+func decodeZonedTimestamp(data []byte) (time.Time, error) {
+	name, _, err := zoneName(data)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("zoned timestamp decode error: %w", err)
+	}
+	t := time.UnixMilli(int64(wireOrder.Uint64(data)))
+	offset := int(int32(wireOrder.Uint32(data[8:])))
+	return t.In(zoneLocation(string(name), offset, t)), nil
+}
+
+// zoneName returns the location name of the zoned timestamp data, which
+// follows the type byte, and the size of the timestamp without its type byte
+func zoneName(data []byte) ([]byte, int, error) {
+	if len(data) < zonedTimestampSize {
+		return nil, 0, fmt.Errorf("insufficient data, need %d bytes, got %d", zonedTimestampSize, len(data))
+	}
+	prefix, err := readSizePrefix(data, zonedTimestampSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := zonedTimestampSize + prefix.width
+	if prefix.value > uint64(len(data)-start) {
+		return nil, 0, fmt.Errorf("insufficient data for zone name")
+	}
+	end := start + int(prefix.value)
+	return data[start:end], end, nil
+}
+
+// locations caches the locations loaded by zoneLocation. Only known
+// locations are stored, which bounds it by the size of the time zone database.
+var locations sync.Map // string -> *time.Location
+
+// zoneLocation returns the location name when it is known and has offset at
+// t, and a fixed zone otherwise
+func zoneLocation(name string, offset int, t time.Time) *time.Location {
+	if name == "" && offset == 0 {
+		return time.UTC
+	}
+	if name != "" {
+		loc, ok := locations.Load(name)
+		if !ok {
+			if loaded, err := time.LoadLocation(name); err == nil {
+				loc, _ = locations.LoadOrStore(name, loaded)
+			}
+		}
+		if loc != nil {
+			if _, known := t.In(loc.(*time.Location)).Zone(); known == offset {
+				return loc.(*time.Location)
+			}
+		}
+	}
+	return time.FixedZone(name, offset)
+}
+
+// ZeroTimePolicy controls how the zero time.Time{} value is represented on the wire
+type ZeroTimePolicy int
+
+const (
+	// ZeroTimeAsIs encodes time.Time{} like any other time (default)
+	ZeroTimeAsIs ZeroTimePolicy = iota
+	// ZeroTimeAsNull encodes time.Time{} as TypeNull
+	ZeroTimeAsNull
+	// ZeroTimeAsEpoch encodes time.Time{} as the Unix epoch (a 0 timestamp) in the configured format
+	ZeroTimeAsEpoch
+)
+
+func (p ZeroTimePolicy) String() string {
+	switch p {
+	case ZeroTimeAsIs:
+		return "as-is"
+	case ZeroTimeAsNull:
+		return "null"
+	case ZeroTimeAsEpoch:
+		return "epoch"
+	}
+	return "<unknown>"
+}
+
+// unixEpoch is the time a zero timestamp decodes to
+var unixEpoch = time.Unix(0, 0).UTC()
+
+// encodeTime encodes time.Time honouring the zero time policy and time format
+func encodeTime(t time.Time, format TimeFormat, policy ZeroTimePolicy) ([]byte, error) {
+	if t.IsZero() {
+		switch policy {
+		case ZeroTimeAsNull:
+			return encodeNull(), nil
+		case ZeroTimeAsEpoch:
+			t = unixEpoch
+		}
+	}
+	return encodeTimeWithFormat(t, format)
+}
+
+// timeFromValue converts a decoded value back into a time.Time. Integers are
+// interpreted according to format, while strings are always accepted as RFC 3339
+// so that data written in either representation can be read back.
+func timeFromValue(value any, format TimeFormat) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case int64:
+		if format == TimeFormatNanos {
+			return time.Unix(0, v).UTC(), true
+		}
+		return time.UnixMilli(v), true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// decodeTime converts a decoded value into a time.Time using the decoder's time
+// format, mapping the epoch back to time.Time{} under ZeroTimeAsEpoch
+func (d *Decoder) decodeTime(value any) (time.Time, bool) {
+	t, ok := timeFromValue(value, d.TimeFormat)
+	if !ok {
+		return time.Time{}, false
+	}
+	if d.ZeroTimePolicy == ZeroTimeAsEpoch && t.Equal(unixEpoch) {
+		return time.Time{}, true
+	}
+	return t, true
+}
@@ -0,0 +1,296 @@
+package bogo
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // zoned timestamps load locations by name
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeFormat(t *testing.T) {
+	type Event struct {
+		Name string    `json:"name"`
+		At   time.Time `json:"at"`
+	}
+
+	at := time.Date(2024, 1, 15, 10, 30, 45, 123456789, time.UTC)
+
+	t.Run("rfc3339 string mode encodes readable strings", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatRFC3339String))
+		data, err := encoder.Encode(at)
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeString), data[1])
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-15T10:30:45.123456789Z", decoded)
+	})
+
+	t.Run("rfc3339 string mode round trips through structs", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatRFC3339String))
+		data, err := encoder.Encode(Event{Name: "launch", At: at})
+		require.NoError(t, err)
+
+		var decoded Event
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.True(t, at.Equal(decoded.At))
+		assert.Equal(t, "launch", decoded.Name)
+	})
+
+	t.Run("nanos mode preserves full precision", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatNanos))
+		data, err := encoder.Encode(Event{Name: "launch", At: at})
+		require.NoError(t, err)
+
+		var decoded Event
+		decoder := NewConfigurableDecoder(WithDecoderTimeFormat(TimeFormatNanos))
+		result, err := decoder.Decode(data)
+		require.NoError(t, err)
+		require.NoError(t, assignResultWith(result, &decoded, decoder))
+		assert.True(t, at.Equal(decoded.At))
+	})
+
+	t.Run("nanos mode is read without the decoder option", func(t *testing.T) {
+		data, err := NewConfigurableEncoder(WithTimeFormat(TimeFormatNanos)).Encode(Event{Name: "launch", At: at})
+		require.NoError(t, err)
+
+		for _, decoder := range []*Decoder{
+			NewConfigurableDecoder(),
+			NewConfigurableDecoder(WithDecoderTimeFormat(TimeFormatMillis)),
+			NewConfigurableDecoder(WithDecoderTimeFormat(TimeFormatNanos)),
+		} {
+			var decoded Event
+			result, err := decoder.Decode(data)
+			require.NoError(t, err)
+			require.NoError(t, assignResultWith(result, &decoded, decoder))
+			assert.True(t, at.Equal(decoded.At), "decoder time format %s", decoder.TimeFormat)
+		}
+
+		var decoded Event
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.True(t, at.Equal(decoded.At))
+
+		field, err := FieldTime(data, "at")
+		require.NoError(t, err)
+		assert.True(t, at.Equal(field))
+	})
+
+	t.Run("nanos mode falls back to millis outside the int64 range", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatNanos))
+		for _, when := range []time.Time{
+			{},
+			time.Date(2262, 4, 12, 0, 0, 0, 0, time.UTC),
+			time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC),
+		} {
+			data, err := encoder.Encode(Event{Name: "launch", At: when})
+			require.NoError(t, err)
+
+			var decoded Event
+			require.NoError(t, Unmarshal(data, &decoded))
+			assert.True(t, when.Equal(decoded.At), "%s decoded as %s", when, decoded.At)
+		}
+
+		data, err := encoder.Encode(time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeTimestamp), data[1])
+	})
+
+	t.Run("nanos written as integers need the decoder option", func(t *testing.T) {
+		// Encoders before TypeTimestampNanos wrote a plain TypeInt
+		legacy, err := Marshal(map[string]any{"name": "launch", "at": at.UnixNano()})
+		require.NoError(t, err)
+
+		var decoded Event
+		decoder := NewConfigurableDecoder(WithDecoderTimeFormat(TimeFormatNanos))
+		result, err := decoder.Decode(legacy)
+		require.NoError(t, err)
+		require.NoError(t, assignResultWith(result, &decoded, decoder))
+		assert.True(t, at.Equal(decoded.At))
+	})
+
+	t.Run("millis mode remains the default", func(t *testing.T) {
+		data, err := Marshal(Event{Name: "launch", At: at})
+		require.NoError(t, err)
+
+		var decoded Event
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.True(t, at.Truncate(time.Millisecond).Equal(decoded.At))
+	})
+
+	t.Run("zoned mode keeps the location", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		encoder := NewConfigurableEncoder(WithTimeFormat(TimeFormatZoned))
+
+		for _, in := range []time.Time{
+			at.In(newYork),
+			at.In(time.FixedZone("IST", 5*3600+1800)),
+			at.In(time.FixedZone("", -3*3600)),
+			at,
+		} {
+			data, err := encoder.Encode(Event{Name: "launch", At: in})
+			require.NoError(t, err)
+
+			var decoded Event
+			require.NoError(t, Unmarshal(data, &decoded))
+			assert.True(t, in.Truncate(time.Millisecond).Equal(decoded.At))
+			assert.Equal(t, in.Location().String(), decoded.At.Location().String())
+			assert.Equal(t, in.Format(time.RFC3339), decoded.At.Format(time.RFC3339))
+		}
+
+		data, err := encoder.Encode(at.In(newYork))
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeZonedTimestamp), data[1])
+		decoded, err := NewConfigurableDecoder().Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, "America/New_York", decoded.(time.Time).Location().String())
+		summer := decoded.(time.Time).AddDate(0, 6, 0)
+		assert.Equal(t, "EDT", summer.Format("MST"), "time zone rules carry over")
+
+		_, err = Footprint(data)
+		assert.NoError(t, err)
+	})
+
+	t.Run("zoned mode falls back to a fixed zone", func(t *testing.T) {
+		misnamed := at.Truncate(time.Millisecond).In(time.FixedZone("Europe/Paris", 7*3600))
+		data, err := NewConfigurableEncoder(WithTimeFormat(TimeFormatZoned)).Encode(misnamed)
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		_, offset := decoded.(time.Time).Zone()
+		assert.Equal(t, 7*3600, offset)
+		assert.True(t, misnamed.Equal(decoded.(time.Time)))
+
+		for i := 2; i < len(data); i++ {
+			_, err := Decode(data[:i])
+			assert.Error(t, err, "truncated at %d", i)
+		}
+	})
+
+	t.Run("top level time destination accepts strings", func(t *testing.T) {
+		data, err := Marshal("2024-01-15T10:30:45Z")
+		require.NoError(t, err)
+
+		var decoded time.Time
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.True(t, at.Truncate(time.Second).Equal(decoded))
+	})
+}
+
+func TestZeroTimePolicy(t *testing.T) {
+	type Record struct {
+		ID        int64      `json:"id"`
+		DeletedAt time.Time  `json:"deleted_at"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	t.Run("as-is keeps the default encoding", func(t *testing.T) {
+		data, err := Encode(time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeTimestamp), data[1])
+	})
+
+	t.Run("null policy encodes zero time as null", func(t *testing.T) {
+		encoder := NewConfigurableEncoder(WithZeroTimePolicy(ZeroTimeAsNull))
+		data, err := encoder.Encode(Record{ID: 1})
+		require.NoError(t, err)
+
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Nil(t, decoded.(map[string]any)["deleted_at"])
+
+		var record Record
+		require.NoError(t, Unmarshal(data, &record))
+		assert.True(t, record.DeletedAt.IsZero())
+		assert.Nil(t, record.ExpiresAt)
+	})
+
+	t.Run("epoch policy round trips zero time", func(t *testing.T) {
+		for _, format := range []TimeFormat{TimeFormatMillis, TimeFormatNanos, TimeFormatRFC3339String, TimeFormatZoned} {
+			t.Run(format.String(), func(t *testing.T) {
+				encoder := NewConfigurableEncoder(WithZeroTimePolicy(ZeroTimeAsEpoch), WithTimeFormat(format))
+				data, err := encoder.Encode(Record{ID: 1})
+				require.NoError(t, err)
+
+				decoder := NewConfigurableDecoder(
+					WithDecoderZeroTimePolicy(ZeroTimeAsEpoch),
+					WithDecoderTimeFormat(format),
+				)
+				result, err := decoder.Decode(data)
+				require.NoError(t, err)
+
+				record := Record{DeletedAt: time.Now()}
+				require.NoError(t, assignResultWith(result, &record, decoder))
+				assert.True(t, record.DeletedAt.IsZero())
+			})
+		}
+	})
+}
+
+func TestTimestampTypedList(t *testing.T) {
+	times := []time.Time{time.UnixMilli(1700000000000), time.UnixMilli(0), time.UnixMilli(-86400000)}
+
+	data, err := Marshal(times)
+	require.NoError(t, err)
+	assert.Equal(t, byte(TypeTypedList), data[1])
+	assert.Equal(t, 2+2+1+2+8*len(times), len(data), "elements take 8 bytes each")
+
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1700000000000, 0, -86400000}, decoded, "times decode into any as Unix milliseconds")
+
+	var out []time.Time
+	require.NoError(t, Unmarshal(data, &out))
+	require.Len(t, out, len(times))
+	for i, got := range out {
+		assert.True(t, times[i].Equal(got), "index %d", i)
+	}
+
+	t.Run("struct fields", func(t *testing.T) {
+		type schedule struct {
+			Runs []time.Time `json:"runs"`
+		}
+		data, err := Marshal(schedule{Runs: times})
+		require.NoError(t, err)
+
+		var out schedule
+		require.NoError(t, Unmarshal(data, &out))
+		require.Len(t, out.Runs, len(times))
+		for i, got := range out.Runs {
+			assert.True(t, times[i].Equal(got), "index %d", i)
+		}
+
+		// Lists written element by element still decode
+		generic, err := NewConfigurableEncoder(WithCompactLists(false)).Encode(schedule{Runs: times})
+		require.NoError(t, err)
+		out = schedule{}
+		require.NoError(t, Unmarshal(generic, &out))
+		assert.True(t, times[0].Equal(out.Runs[0]))
+	})
+
+	t.Run("other time encodings keep element lists", func(t *testing.T) {
+		for _, encoder := range []*Encoder{
+			NewConfigurableEncoder(WithTimeFormat(TimeFormatNanos)),
+			NewConfigurableEncoder(WithZeroTimePolicy(ZeroTimeAsNull)),
+		} {
+			data, err := encoder.Encode(times)
+			require.NoError(t, err)
+			assert.Equal(t, byte(TypeUntypedList), data[1])
+		}
+	})
+
+	t.Run("appending", func(t *testing.T) {
+		data, err := Marshal(map[string]any{"runs": times})
+		require.NoError(t, err)
+		data, err = AppendToList(data, "runs", time.UnixMilli(42))
+		require.NoError(t, err)
+		runs, err := Document(data).Get("runs")
+		require.NoError(t, err)
+		decoded, err := decodeValue(runs)
+		require.NoError(t, err)
+		assert.Len(t, decoded, len(times)+1)
+	})
+}
@@ -0,0 +1,355 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var transformErr = errors.New("bogo transform error")
+
+// TransformStep is one edit of a Transform, created by RenameField,
+// DropField, CastField or MoveField. Paths are written as for Document.
+type TransformStep struct {
+	op   transformOp
+	path string
+	to   string // new key for renames, destination for moves
+	typ  Type   // target type for casts
+}
+
+type transformOp uint8
+
+const (
+	transformRename transformOp = iota
+	transformDrop
+	transformCast
+	transformMove
+)
+
+// RenameField renames the field at path to key, keeping its position in its
+// object
+func RenameField(path, key string) TransformStep {
+	return TransformStep{op: transformRename, path: path, to: key}
+}
+
+// DropField removes the value at path
+func DropField(path string) TransformStep {
+	return TransformStep{op: transformDrop, path: path}
+}
+
+// CastField converts the value at path to typ, which is one of TypeString,
+// TypeInt, TypeUint, TypeFloat and TypeDecimal. Numbers convert between each
+// other when the value fits, strings parse, and every scalar formats as a
+// string. Nulls stay null.
+func CastField(path string, typ Type) TransformStep {
+	return TransformStep{op: transformCast, path: path, typ: typ}
+}
+
+// MoveField moves the value at from to the path to, creating the objects
+// missing along to
+func MoveField(from, to string) TransformStep {
+	return TransformStep{op: transformMove, path: from, to: to}
+}
+
+func (s TransformStep) String() string {
+	switch s.op {
+	case transformRename:
+		return fmt.Sprintf("rename %s to %s", s.path, s.to)
+	case transformDrop:
+		return fmt.Sprintf("drop %s", s.path)
+	case transformCast:
+		return fmt.Sprintf("cast %s to %s", s.path, TypeName(s.typ))
+	case transformMove:
+		return fmt.Sprintf("move %s to %s", s.path, s.to)
+	}
+	return "<unknown>"
+}
+
+// Transform reshapes encoded documents by a fixed list of steps, for jobs that
+// rewrite many messages the same way. Steps run over the encoded bytes as
+// Document edits do: fields that are renamed, moved or dropped are copied
+// without being decoded, and only the values being cast are decoded and
+// encoded again.
+//
+// A step whose path is missing from a document leaves it unchanged, so one
+// Transform can be applied to messages that do not all carry every field.
+// A Transform is safe for concurrent use.
+type Transform struct {
+	steps []compiledStep
+}
+
+// compiledStep is a step with its paths parsed
+type compiledStep struct {
+	TransformStep
+	path []pathSegment
+	to   []pathSegment // destination of moves
+}
+
+// CompileTransform checks steps and prepares them to be applied in order
+func CompileTransform(steps ...TransformStep) (*Transform, error) {
+	t := &Transform{steps: make([]compiledStep, len(steps))}
+	for i, step := range steps {
+		compiled, err := compileStep(step)
+		if err != nil {
+			return nil, fmt.Errorf("%w: step %d, %s: %w", transformErr, i, step, err)
+		}
+		t.steps[i] = compiled
+	}
+	return t, nil
+}
+
+func compileStep(step TransformStep) (compiledStep, error) {
+	c := compiledStep{TransformStep: step}
+	var err error
+	if c.path, err = parsePath(step.path); err != nil {
+		return c, err
+	}
+	if len(c.path) == 0 {
+		return c, errors.New("the whole document cannot be transformed")
+	}
+
+	switch step.op {
+	case transformRename:
+		if !c.path[len(c.path)-1].isKey() {
+			return c, errors.New("only object fields can be renamed")
+		}
+		if step.to == "" || len(step.to) > 255 {
+			return c, fmt.Errorf("invalid key %q", step.to)
+		}
+	case transformMove:
+		if c.to, err = parsePath(step.to); err != nil {
+			return c, err
+		}
+		if len(c.to) == 0 {
+			return c, errors.New("cannot move to the whole document")
+		}
+	case transformCast:
+		switch step.typ {
+		case TypeString, TypeInt, TypeUint, TypeFloat, TypeDecimal:
+		default:
+			return c, fmt.Errorf("cannot cast to %s", TypeName(step.typ))
+		}
+	}
+	return c, nil
+}
+
+// Apply returns the document data reshaped by the steps of t, keeping the
+// magic prefix when data has one
+func (t *Transform) Apply(data []byte) ([]byte, error) {
+	var prefix []byte
+	if hasMagic(data) {
+		prefix, data = Magic[:], data[len(Magic):]
+	}
+	value, err := Document(data).value()
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range t.steps {
+		if value, err = step.apply(value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", transformErr, step.TransformStep, err)
+		}
+	}
+
+	out := make([]byte, 0, len(prefix)+1+len(value))
+	out = append(append(out, prefix...), Version)
+	return append(out, value...), nil
+}
+
+// apply runs the step on value, the top-level value of a document
+func (s compiledStep) apply(value []byte) ([]byte, error) {
+	raw, table, err := valueAt(value, s.path)
+	if errors.Is(err, ErrFieldNotFound) {
+		return value, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.op {
+	case transformDrop:
+		return spliceValue(value, nil, s.path, "", nil)
+	case transformCast:
+		v, err := decodeValue(withSymbols(table, raw))
+		if err != nil {
+			return nil, err
+		}
+		if v, err = castValue(v, s.typ); err != nil {
+			return nil, err
+		}
+		encoded, err := defaultEncoder.Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return spliceValue(value, nil, s.path, "", encoded[1:])
+	case transformRename:
+		parent := s.path[:len(s.path)-1]
+		obj, table, err := valueAt(value, parent)
+		if err != nil {
+			return nil, err
+		}
+		symbols, err := tableSymbols(table)
+		if err != nil {
+			return nil, err
+		}
+		if obj, err = renameKey(obj, symbols, s.path[len(s.path)-1].key, s.TransformStep.to); err != nil {
+			return nil, err
+		}
+		return spliceValue(value, nil, parent, "", obj)
+	}
+
+	// Moves; raw stays valid, as splicing copies. It may land below another
+	// symbol table, so it takes a copy of its own.
+	if value, err = spliceValue(value, nil, s.path, "", nil); err != nil {
+		return nil, err
+	}
+	return insertValue(value, s.to, withSymbols(table, raw))
+}
+
+// renameKey returns the object value, below a symbol table holding symbols,
+// with the key from renamed to, in place. Fields already named to are
+// replaced. A keyed object whose table lacks to is rewritten with its keys
+// written out.
+func renameKey(value []byte, symbols []string, from, to string) ([]byte, error) {
+	if Type(value[0]) == TypeSymbols {
+		return underSymbols(value, symbols, func(obj []byte, symbols []string) ([]byte, error) {
+			return renameKey(obj, symbols, from, to)
+		})
+	}
+	keyed := Type(value[0]) == TypeKeyedObject
+	if keyed && !slices.Contains(symbols, to) {
+		plain, err := plainObject(value, symbols)
+		if err != nil {
+			return nil, err
+		}
+		return renameKey(plain, nil, from, to)
+	}
+	fields, err := objectFields(value)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	var entryErr error
+	err = walkObject(value, fields, symbols, func(start, end int, key, v []byte) bool {
+		switch string(key) {
+		case from:
+			var entry []byte
+			if keyed {
+				entry, _ = keyedEntry(symbols, to, v)
+			} else if entry, entryErr = buildFieldEntry(to, v); entryErr != nil {
+				return false
+			}
+			out = append(out, entry...)
+		case to:
+		default:
+			out = append(out, fields[start:end]...)
+		}
+		return true
+	})
+	if err == nil {
+		err = entryErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildContainer(Type(value[0]), out)
+}
+
+// insertValue sets the value at segments below value to encoded, creating
+// the objects missing along the path
+func insertValue(value []byte, segments []pathSegment, encoded []byte) ([]byte, error) {
+	out, err := spliceValue(value, nil, segments, "", encoded)
+	if !errors.Is(err, ErrFieldNotFound) {
+		return out, err
+	}
+	// Find the deepest value on the path that exists, and nest encoded in
+	// new objects for the keys below it
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, _, err := valueAt(value, segments[:i]); err != nil {
+			continue
+		}
+		nested := encoded
+		for j := len(segments) - 1; j > i; j-- {
+			if !segments[j].isKey() {
+				return nil, err
+			}
+			entry, err := buildFieldEntry(segments[j].key, nested)
+			if err != nil {
+				return nil, err
+			}
+			if nested, err = buildContainer(TypeObject, entry); err != nil {
+				return nil, err
+			}
+		}
+		return spliceValue(value, nil, segments[:i+1], "", nested)
+	}
+	return nil, err
+}
+
+// castValue converts the decoded value v to a value encoding as typ
+func castValue(v any, typ Type) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	n, isNumber := numberOf(v)
+	switch typ {
+	case TypeString:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case bool:
+			return strconv.FormatBool(x), nil
+		case Decimal:
+			return x.String(), nil
+		case time.Time:
+			return x.Format(time.RFC3339Nano), nil
+		}
+		if isNumber {
+			return n.String(), nil
+		}
+	case TypeInt:
+		if s, ok := v.(string); ok {
+			return strconv.ParseInt(s, 10, 64)
+		}
+		if isNumber {
+			return n.Int64()
+		}
+	case TypeUint:
+		if s, ok := v.(string); ok {
+			return strconv.ParseUint(s, 10, 64)
+		}
+		if isNumber {
+			return n.Uint64()
+		}
+	case TypeFloat:
+		switch x := v.(type) {
+		case string:
+			return strconv.ParseFloat(x, 64)
+		case Decimal:
+			f, _ := x.Rat().Float64()
+			return f, nil
+		}
+		if isNumber {
+			return n.Float64(), nil
+		}
+	case TypeDecimal:
+		switch x := v.(type) {
+		case Decimal:
+			return x, nil
+		case string:
+			return ParseDecimal(x)
+		case float64:
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				break
+			}
+			return ParseDecimal(strconv.FormatFloat(x, 'f', -1, 64))
+		}
+		if isNumber {
+			i, err := n.Int64()
+			return Decimal{Unscaled: i}, err
+		}
+	}
+	return nil, fmt.Errorf("cannot cast %T to %s", v, TypeName(typ))
+}
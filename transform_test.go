@@ -0,0 +1,99 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	order := map[string]any{
+		"id":       "42",
+		"customer": map[string]any{"name": "Ama", "email": "ama@example.com"},
+		"total":    "19.99",
+		"qty":      int64(3),
+		"internal": map[string]any{"trace": "abc"},
+		"items":    []any{map[string]any{"sku": "a1", "price": 5.5}},
+	}
+	data := mustMarshal(t, order)
+
+	transform, err := CompileTransform(
+		RenameField("customer.name", "full_name"),
+		DropField("internal"),
+		CastField("id", TypeInt),
+		CastField("total", TypeDecimal),
+		CastField("qty", TypeString),
+		CastField("items[0].price", TypeDecimal),
+		MoveField("customer.email", "contact.email"),
+		DropField("missing.field"),
+	)
+	require.NoError(t, err)
+
+	out, err := transform.Apply(data)
+	require.NoError(t, err)
+	decoded, err := Decode(out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int64(42),
+		"customer": map[string]any{"full_name": "Ama"},
+		"total":    Decimal{Unscaled: 1999, Scale: 2},
+		"qty":      "3",
+		"items":    []any{map[string]any{"sku": "a1", "price": Decimal{Unscaled: 55, Scale: 1}}},
+		"contact":  map[string]any{"email": "ama@example.com"},
+	}, decoded)
+
+	t.Run("renames keep the field position", func(t *testing.T) {
+		rename, err := CompileTransform(RenameField("b", "z"))
+		require.NoError(t, err)
+		doc, err := NewObjectBuilder().Set("a", 1).Set("b", 2).Set("c", 3).Bytes()
+		require.NoError(t, err)
+
+		out, err := rename.Apply(doc)
+		require.NoError(t, err)
+		var keys []string
+		fields, err := Fields(out)
+		require.NoError(t, err)
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"a", "z", "c"}, keys)
+	})
+
+	t.Run("missing paths leave documents unchanged", func(t *testing.T) {
+		noop, err := CompileTransform(DropField("nope"), CastField("x.y", TypeInt), MoveField("a", "b"))
+		require.NoError(t, err)
+		out, err := noop.Apply(data)
+		require.NoError(t, err)
+		assert.Equal(t, data, out)
+	})
+
+	t.Run("keeps the magic prefix", func(t *testing.T) {
+		magic, err := NewConfigurableEncoder(WithMagicPrefix(true)).Encode(order)
+		require.NoError(t, err)
+		out, err := transform.Apply(magic)
+		require.NoError(t, err)
+		assert.True(t, hasMagic(out))
+	})
+
+	t.Run("reports values that do not cast", func(t *testing.T) {
+		bad, err := CompileTransform(CastField("customer.name", TypeInt))
+		require.NoError(t, err)
+		_, err = bad.Apply(data)
+		assert.ErrorContains(t, err, "cast customer.name to int")
+	})
+
+	t.Run("rejects invalid steps", func(t *testing.T) {
+		for _, step := range []TransformStep{
+			DropField(""),
+			RenameField("items[0]", "x"),
+			RenameField("id", ""),
+			CastField("id", TypeObject),
+			MoveField("id", ""),
+			DropField("a..b"),
+		} {
+			_, err := CompileTransform(step)
+			assert.Error(t, err, step.String())
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package bogo
+
+import "unicode/utf8"
+
+// MarshalT encodes v as Marshal does. It is the typed counterpart of
+// UnmarshalT, so values can round trip without naming their type twice.
+func MarshalT[T any](v T) ([]byte, error) {
+	return defaultEncoder.Encode(v)
+}
+
+// UnmarshalT decodes data as Unmarshal does and returns the value as a T,
+// or the zero T with the error when data does not decode into one:
+//
+//	order, err := bogo.UnmarshalT[Order](data)
+//
+// Strings, integers, floats, booleans and blobs are read straight from the
+// wire for T of those types, and structs are filled field by field, without
+// going through interface values.
+func UnmarshalT[T any](data []byte) (T, error) {
+	var v T
+	if err := defaultDecoder.Unmarshal(data, &v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// unmarshalScalar decodes a string, integer, float, boolean or blob value
+// into a destination of exactly that type, without boxing the value in an
+// interface, reporting whether it did. Values of other types, including
+// those that only convert to the destination, are left to the general path.
+func (d *Decoder) unmarshalScalar(value []byte, v any) bool {
+	if d.TypedValues || len(d.coercions) > 0 {
+		return false
+	}
+	switch dst := v.(type) {
+	case *string:
+		return setScalar(dst, value, func(value []byte) (string, bool) {
+			s, ok := flatString(value)
+			if !ok || d.ValidateUTF8 && !utf8.Valid(s) {
+				return "", false
+			}
+			return d.strings().str(s), true
+		})
+	case *int64:
+		return setScalar(dst, value, flatInt)
+	case *float64:
+		return setScalar(dst, value, flatFloat)
+	case *bool:
+		return setScalar(dst, value, flatBool)
+	case *[]byte:
+		return setScalar(dst, value, func(value []byte) ([]byte, bool) {
+			if Type(value[0]) != TypeBlob {
+				return nil, false
+			}
+			b, err := decodeBlob(value[1:])
+			return b, err == nil && (d.MaxObjectSize <= 0 || int64(len(b)) <= d.MaxObjectSize)
+		})
+	}
+	return false
+}
+
+// setScalar stores the value parse reads from value in dst, or the zero
+// value for a null
+func setScalar[V any](dst *V, value []byte, parse func(value []byte) (V, bool)) bool {
+	if dst == nil {
+		return false
+	}
+	var v V
+	if Type(value[0]) != TypeNull {
+		var ok bool
+		if v, ok = parse(value); !ok {
+			return false
+		}
+	}
+	*dst = v
+	return true
+}
+
+// flatBool decodes a TypeBoolTrue or TypeBoolFalse value
+func flatBool(value []byte) (bool, bool) {
+	switch Type(value[0]) {
+	case TypeBoolTrue:
+		return true, true
+	case TypeBoolFalse:
+		return false, true
+	}
+	return false, false
+}
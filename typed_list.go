@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 func encodeTypedList(arr any) ([]byte, error) {
+	if elementTypeCode, count, elements, ok := typedListElements(arr); ok {
+		return buildTypedList(elementTypeCode, count, elements)
+	}
+
 	v := reflect.ValueOf(arr)
 
 	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
@@ -31,8 +36,6 @@ func encodeTypedList(arr any) ([]byte, error) {
 		}
 	}
 
-	buf := bytes.Buffer{}
-
 	// Determine the element type for optimization
 	var elementTypeCode byte
 	switch elemType.Kind() {
@@ -50,6 +53,11 @@ func encodeTypedList(arr any) ([]byte, error) {
 		elementTypeCode = TypeFloat
 	case reflect.Bool:
 		elementTypeCode = TypeBoolTrue // We'll handle true/false during encoding
+	case reflect.Struct:
+		if elemType != timeType {
+			return encodeList(arr)
+		}
+		elementTypeCode = TypeTimestamp
 	default:
 		// Unsupported type for optimization - fall back to regular list
 		return encodeList(arr)
@@ -101,13 +109,110 @@ func encodeTypedList(arr any) ([]byte, error) {
 			} else {
 				elementsBuf.WriteByte(0)
 			}
+		case TypeTimestamp:
+			elementsBuf.Write(wireOrder.AppendUint64(nil, uint64(elem.(time.Time).UnixMilli())))
+		}
+	}
+
+	return buildTypedList(elementTypeCode, v.Len(), elementsBuf.Bytes())
+}
+
+// typedListElements encodes the elements of common slice types without
+// reflection, so that typed lists of primitives also work in builds where
+// reflection is limited, such as TinyGo. The output matches the reflective
+// path. ok is false for other types and for empty lists.
+func typedListElements(arr any) (elementTypeCode byte, count int, elements []byte, ok bool) {
+	var buf []byte
+	// appendValue appends an encoded value without its type byte
+	appendValue := func(encoded []byte, _ error) {
+		buf = append(buf, encoded[1:]...)
+	}
+
+	switch list := arr.(type) {
+	case []string:
+		for _, s := range list {
+			appendValue(encodeUint(uint64(len(s))))
+			buf = append(buf, s...)
+		}
+		elementTypeCode, count = TypeString, len(list)
+	case []int:
+		for _, n := range list {
+			appendValue(encodeInt(int64(n)))
+		}
+		elementTypeCode, count = TypeInt, len(list)
+	case []int64:
+		for _, n := range list {
+			appendValue(encodeInt(n))
+		}
+		elementTypeCode, count = TypeInt, len(list)
+	case []uint64:
+		for _, n := range list {
+			appendValue(encodeUint(n))
+		}
+		elementTypeCode, count = TypeUint, len(list)
+	case []float64:
+		for _, f := range list {
+			appendValue(encodeFloat(f))
+		}
+		elementTypeCode, count = TypeFloat, len(list)
+	case []bool:
+		for _, b := range list {
+			if b {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
 		}
+		elementTypeCode, count = TypeBoolTrue, len(list)
+	case []time.Time:
+		for _, t := range list {
+			buf = wireOrder.AppendUint64(buf, uint64(t.UnixMilli()))
+		}
+		elementTypeCode, count = TypeTimestamp, len(list)
+	default:
+		return 0, 0, nil, false
 	}
+	return elementTypeCode, count, buf, count > 0
+}
 
-	elementsData := elementsBuf.Bytes()
+// encodeTypedRows encodes rv, a slice or array of the slices typedListElements
+// handles, as a nested typed list: a typed list of TypeTypedList elements,
+// each a typed list without its type byte. It reports false for other element
+// types and when a row is nil, as typed lists cannot hold nulls.
+func (e *Encoder) encodeTypedRows(rv reflect.Value) ([]byte, bool) {
+	switch rv.Type().Elem() {
+	case reflect.TypeFor[[]string](), reflect.TypeFor[[]int](), reflect.TypeFor[[]int64](), reflect.TypeFor[[]bool]():
+	case reflect.TypeFor[[]float64]():
+		// Rows are not checked for NaN and ±Inf, which the policy may rewrite
+		if e.NaNPolicy != NaNKeep {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
 
+	var elements []byte
+	for i := 0; i < rv.Len(); i++ {
+		row := rv.Index(i)
+		if row.IsNil() {
+			return nil, false
+		}
+		elementTypeCode, count, rowElements, _ := typedListElements(row.Interface())
+		list, err := buildTypedList(elementTypeCode, count, rowElements)
+		if err != nil {
+			return nil, false
+		}
+		elements = append(elements, list[1:]...)
+	}
+	data, err := buildTypedList(TypeTypedList, rv.Len(), elements)
+	return data, err == nil
+}
+
+// buildTypedList assembles a typed list from its encoded elements:
+// TypeTypedList + LenSize + DataSize + ElementType + Count + Elements
+func buildTypedList(elementTypeCode byte, count int, elementsData []byte) ([]byte, error) {
 	// Encode count
-	countData, err := encodeUint(uint64(v.Len()))
+	countData, err := encodeUint(uint64(count))
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +224,7 @@ func encodeTypedList(arr any) ([]byte, error) {
 		return nil, err
 	}
 
-	// Build final typed list: TypeTypedList + LenSize + DataSize + ElementType + Count + Elements
+	buf := bytes.Buffer{}
 	buf.WriteByte(TypeTypedList)
 	buf.Write(lengthData[1:]) // Remove type byte from length encoding
 	buf.WriteByte(elementTypeCode)
@@ -143,6 +248,11 @@ func encodeFloatValue(val float64) ([]byte, error) {
 }
 
 func decodeTypedList(data []byte) (any, error) {
+	return decodeTypedListWith(data, stringMaker{})
+}
+
+// decodeTypedListWith is decodeTypedList building strings with strs
+func decodeTypedListWith(data []byte, strs stringMaker) (any, error) {
 	if len(data) < 2 {
 		return nil, fmt.Errorf("typed list decode error: insufficient data for size")
 	}
@@ -217,7 +327,7 @@ func decodeTypedList(data []byte) (any, error) {
 				return nil, fmt.Errorf("typed list decode error: insufficient string content data")
 			}
 
-			result[i] = string(elementsData[pos : pos+int(strLen)])
+			result[i] = strs.str(elementsData[pos : pos+int(strLen)])
 			pos += int(strLen)
 		}
 		return result, nil
@@ -308,7 +418,67 @@ func decodeTypedList(data []byte) (any, error) {
 		}
 		return result, nil
 
+	case TypeTimestamp:
+		// Like other nested timestamps, elements decode as Unix milliseconds
+		if uint64(len(elementsData)) != count*8 {
+			return nil, fmt.Errorf("typed list decode error: timestamp list size mismatch")
+		}
+		result := make([]int64, count)
+		for i := range result {
+			result[i] = int64(wireOrder.Uint64(elementsData[8*i:]))
+		}
+		return result, nil
+
+	case TypeTypedList:
+		return decodeTypedRows(elementsData, count, strs)
+
 	default:
 		return nil, fmt.Errorf("typed list decode error: unsupported element type: %d", elementType)
 	}
 }
+
+// decodeTypedRows decodes the rows of a nested typed list. Rows must not be
+// nested themselves, which bounds the recursion. When every row decodes to the
+// same type, the rows are returned as a slice of that type, such as [][]int64.
+func decodeTypedRows(data []byte, count uint64, strs stringMaker) (any, error) {
+	// Every row takes at least one byte on the wire, which bounds count
+	if count > uint64(len(data)) {
+		return nil, fmt.Errorf("typed list decode error: row count exceeds its data")
+	}
+
+	rows := make([]any, count)
+	pos := 0
+	for i := range rows {
+		size, ok := typedElementSize(TypeTypedList, data[pos:])
+		if !ok {
+			return nil, fmt.Errorf("typed list decode error: insufficient data for row %d", i)
+		}
+		row := data[pos : pos+size]
+		if payload := row[1+int(row[0]):]; len(payload) > 0 && Type(payload[0]) == TypeTypedList {
+			return nil, fmt.Errorf("typed list decode error: row %d is a nested typed list", i)
+		}
+		var err error
+		if rows[i], err = decodeTypedListWith(row, strs); err != nil {
+			return nil, fmt.Errorf("typed list decode error: row %d: %w", i, err)
+		}
+		pos += size
+	}
+	if pos != len(data) {
+		return nil, fmt.Errorf("typed list decode error: %d trailing bytes after the rows", len(data)-pos)
+	}
+
+	if len(rows) == 0 {
+		return rows, nil
+	}
+	rowType := reflect.TypeOf(rows[0])
+	for _, row := range rows[1:] {
+		if reflect.TypeOf(row) != rowType {
+			return rows, nil
+		}
+	}
+	result := reflect.MakeSlice(reflect.SliceOf(rowType), len(rows), len(rows))
+	for i, row := range rows {
+		result.Index(i).Set(reflect.ValueOf(row))
+	}
+	return result.Interface(), nil
+}
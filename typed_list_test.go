@@ -0,0 +1,108 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type matrixDoc struct {
+	Name   string      `json:"name"`
+	Matrix [][]float64 `json:"matrix"`
+	Labels [][]string  `json:"labels"`
+}
+
+func TestNestedTypedLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		decoded any
+	}{
+		{"int64 rows", [][]int64{{1, 2, 3}, {-4, 5}, {}}, [][]int64{{1, 2, 3}, {-4, 5}, {}}},
+		{"int rows", [][]int{{1}, {2, 3}}, [][]int64{{1}, {2, 3}}},
+		{"float64 rows", [][]float64{{0.5, 1}, {2.25, -3}}, [][]float64{{0.5, 1}, {2.25, -3}}},
+		{"string rows", [][]string{{"a", "b"}, {"c"}}, [][]string{{"a", "b"}, {"c"}}},
+		{"bool rows", [][]bool{{true, false}}, [][]bool{{true, false}}},
+		{"array of rows", [2][]int64{{1}, {2}}, [][]int64{{1}, {2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, byte(TypeTypedList), data[1])
+
+			decoded, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.decoded, decoded)
+
+			decoded, err = NewConfigurableDecoder().Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.decoded, decoded)
+
+			_, err = Footprint(data)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("smaller than lists of lists", func(t *testing.T) {
+		matrix := make([][]int64, 50)
+		for i := range matrix {
+			matrix[i] = make([]int64, 50)
+			for j := range matrix[i] {
+				matrix[i][j] = int64(i * j)
+			}
+		}
+		typed, err := Marshal(matrix)
+		require.NoError(t, err)
+		untyped, err := NewConfigurableEncoder(WithCompactLists(false)).Encode(matrix)
+		require.NoError(t, err)
+		assert.Less(t, len(typed), len(untyped)*4/5)
+
+		var got [][]int64
+		require.NoError(t, Unmarshal(typed, &got))
+		assert.Equal(t, matrix, got)
+	})
+
+	t.Run("struct fields", func(t *testing.T) {
+		doc := matrixDoc{Name: "m", Matrix: [][]float64{{1, 2}, {3, 4}}, Labels: [][]string{{"x"}, {}}}
+		data, err := Marshal(doc)
+		require.NoError(t, err)
+		matrix, err := LookupField(data, "matrix")
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeTypedList), matrix[0])
+
+		var got matrixDoc
+		require.NoError(t, Unmarshal(data, &got))
+		assert.Equal(t, doc, got)
+
+		var ints struct {
+			Matrix [][]int `json:"matrix"`
+		}
+		intData, err := Marshal(map[string]any{"matrix": [][]int64{{1, 2}, {3}}})
+		require.NoError(t, err)
+		require.NoError(t, Unmarshal(intData, &ints))
+		assert.Equal(t, [][]int{{1, 2}, {3}}, ints.Matrix)
+	})
+
+	t.Run("nil rows fall back to a list", func(t *testing.T) {
+		data, err := Marshal([][]int64{{1}, nil})
+		require.NoError(t, err)
+		assert.Equal(t, byte(TypeUntypedList), data[1])
+	})
+
+	t.Run("nested rows are rejected", func(t *testing.T) {
+		row, err := buildTypedList(TypeInt, 0, nil)
+		require.NoError(t, err)
+		inner, err := buildTypedList(TypeTypedList, 1, row[1:])
+		require.NoError(t, err)
+		outer, err := buildTypedList(TypeTypedList, 1, inner[1:])
+		require.NoError(t, err)
+
+		_, err = Decode(append([]byte{Version}, outer...))
+		assert.ErrorContains(t, err, "nested typed list")
+		_, err = Footprint(append([]byte{Version}, outer...))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,80 @@
+package bogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalUnmarshalT(t *testing.T) {
+	order := directOrder{ID: 7, Buyer: "ama", Items: []directItem{{SKU: "a", Qty: 2}}}
+	data, err := MarshalT(order)
+	require.NoError(t, err)
+	got, err := UnmarshalT[directOrder](data)
+	require.NoError(t, err)
+	var want directOrder
+	require.NoError(t, Unmarshal(data, &want))
+	assert.Equal(t, want, got)
+	assert.Equal(t, order.Items, got.Items)
+
+	items, err := UnmarshalT[[]directItem](mustMarshal(t, order.Items))
+	require.NoError(t, err)
+	assert.Equal(t, order.Items, items)
+
+	// Failures return the zero T
+	n, err := UnmarshalT[int64](mustMarshal(t, "x"))
+	assert.Error(t, err)
+	assert.Zero(t, n)
+
+	t.Run("scalars", func(t *testing.T) {
+		compact := NewConfigurableEncoder(WithCompactIntegers(true), WithCompactStrings(true))
+		encode := func(e *Encoder, v any) []byte {
+			data, err := e.Encode(v)
+			require.NoError(t, err)
+			return data
+		}
+		tests := []struct {
+			name   string
+			data   []byte
+			dst    func() any
+			direct bool
+		}{
+			{"string", mustMarshal(t, "hello"), func() any { return new(string) }, true},
+			{"short string", encode(compact, "hi"), func() any { return new(string) }, true},
+			{"int", mustMarshal(t, int64(math.MinInt64)), func() any { return new(int64) }, true},
+			{"small int", encode(compact, int64(3)), func() any { return new(int64) }, true},
+			{"float", mustMarshal(t, 2.5), func() any { return new(float64) }, true},
+			{"true", mustMarshal(t, true), func() any { return new(bool) }, true},
+			{"false", mustMarshal(t, false), func() any { return new(bool) }, true},
+			{"blob", mustMarshal(t, []byte{1, 2}), func() any { return new([]byte) }, true},
+			{"null", mustMarshal(t, nil), func() any { s := "old"; return &s }, true},
+			{"null blob", mustMarshal(t, nil), func() any { return &[]byte{1} }, true},
+			{"string into blob", mustMarshal(t, "x"), func() any { return new([]byte) }, false},
+			{"int into float", mustMarshal(t, int64(1)), func() any { return new(float64) }, false},
+			{"unsigned into int", mustMarshal(t, uint64(1)), func() any { return new(int64) }, false},
+			{"invalid UTF-8", append([]byte{Version}, byte(TypeFixStr)+1, 0xff), func() any { return new(string) }, false},
+			{"named string", mustMarshal(t, "active"), func() any { return new(testStatus) }, false},
+			{"int", mustMarshal(t, int64(1)), func() any { return new(int) }, false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				d := NewConfigurableDecoder()
+				direct, general := tt.dst(), tt.dst()
+				assert.Equal(t, tt.direct, d.unmarshalScalar(tt.data[1:], tt.dst()), "direct path")
+				err := d.Unmarshal(tt.data, direct)
+				generalErr := unmarshalGeneral(d, tt.data, general)
+				if generalErr != nil {
+					assert.EqualError(t, err, generalErr.Error())
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, general, direct)
+			})
+		}
+
+		assert.False(t, NewConfigurableDecoder().unmarshalScalar(mustMarshal(t, "x")[1:], (*string)(nil)))
+		assert.False(t, NewConfigurableDecoder(WithTypedValues(true)).unmarshalScalar(mustMarshal(t, "x")[1:], new(string)))
+	})
+}
@@ -0,0 +1,189 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TypedValue is a decoded value together with the wire type it was encoded as.
+// It is produced when decoding with WithTypedValues(true) so that consumers can
+// tell apart values that are indistinguishable once assigned to any, such as
+// uint64(5) and int64(5), or a byte and a small uint.
+//
+// Objects and keyed objects decode to map[string]any and untyped lists to
+// []any whose entries are themselves TypedValues, as do the values of maps
+// with integer keys. Object lists decode to []any of map[string]any rows whose
+// values are TypedValues. Typed lists keep their homogeneous Go slice as
+// Value. A symbol table is read through: the value below it is wrapped, with
+// its symbols resolved.
+type TypedValue struct {
+	Type  Type
+	Value any
+}
+
+func (tv TypedValue) String() string {
+	return fmt.Sprintf("%s(%v)", tv.Type, tv.Value)
+}
+
+var typedValueErr = errors.New("typed value decoder error")
+
+// decodeTypedValue decodes the value at the start of data, wrapping it and any
+// nested values in TypedValue. Strings and symbols are read with strs.
+func decodeTypedValue(data []byte, strs stringMaker) (TypedValue, error) {
+	if len(data) == 0 {
+		return TypedValue{}, wrapError(typedValueErr, "insufficient data for type")
+	}
+
+	typ := Type(data[0])
+	switch typ {
+	case TypeSymbols:
+		symbols, offset, err := readSymbols(data[1:], strs)
+		if err != nil {
+			return TypedValue{}, err
+		}
+		strs.symbols = symbols
+		return decodeTypedValue(data[1+offset:], strs)
+
+	case TypeObject:
+		obj, err := decodeTypedObject(data[1:], strs)
+		if err != nil {
+			return TypedValue{}, err
+		}
+		return TypedValue{Type: typ, Value: obj}, nil
+
+	case TypeKeyedObject:
+		obj, err := decodeKeyedObjectWith(data[1:], strs, nil, typedDecoder(strs))
+		if err != nil {
+			return TypedValue{}, err
+		}
+		return TypedValue{Type: typ, Value: obj}, nil
+
+	case TypeUntypedList:
+		list, err := decodeTypedListElements(data[1:], strs)
+		if err != nil {
+			return TypedValue{}, err
+		}
+		return TypedValue{Type: typ, Value: list}, nil
+
+	case TypeMap:
+		m, err := decodeMapValues(data[1:], strs, typedDecoder(strs))
+		if err != nil {
+			return TypedValue{}, err
+		}
+		return TypedValue{Type: typ, Value: m}, nil
+
+	case TypeObjectList:
+		list, err := decodeObjectListValues(data[1:], strs, typedDecoder(strs))
+		if err != nil {
+			return TypedValue{}, err
+		}
+		return TypedValue{Type: typ, Value: list}, nil
+	}
+
+	value, err := decodeValueWith(data, strs)
+	if err != nil {
+		return TypedValue{}, err
+	}
+	return TypedValue{Type: typ.baseType(), Value: value}, nil
+}
+
+// typedDecoder returns decodeTypedValue with strs returning any, for decoders
+// of container values
+func typedDecoder(strs stringMaker) func([]byte) (any, error) {
+	return func(data []byte) (any, error) {
+		return decodeTypedValue(data, strs)
+	}
+}
+
+func decodeTypedObject(data []byte, strs stringMaker) (map[string]any, error) {
+	if len(data) == 0 {
+		return map[string]any{}, nil
+	}
+
+	sizeLen := int(data[0])
+	if len(data) < sizeLen+1 {
+		return nil, wrapError(typedValueErr, "insufficient data for field size")
+	}
+	fieldsSize, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return nil, wrapError(typedValueErr, "failed to decode fields size", err.Error())
+	}
+	fieldsStart := 1 + sizeLen
+	fieldsEnd := fieldsStart + int(fieldsSize)
+	if len(data) < fieldsEnd {
+		return nil, wrapError(typedValueErr, "insufficient data for fields")
+	}
+	fields := data[fieldsStart:fieldsEnd]
+
+	result := make(map[string]any)
+	for pos := 0; pos < len(fields); {
+		entrySizeLen := int(fields[pos])
+		if pos+1+entrySizeLen > len(fields) {
+			return nil, wrapError(typedValueErr, "insufficient data for entry size")
+		}
+		entrySize, err := decodeUint(fields[pos+1 : pos+1+entrySizeLen])
+		if err != nil {
+			return nil, wrapError(typedValueErr, "failed to decode entry size", err.Error())
+		}
+		entryStart := pos + 1 + entrySizeLen
+		entryEnd := entryStart + int(entrySize)
+		if entryEnd > len(fields) || entryStart >= entryEnd {
+			return nil, wrapError(typedValueErr, "insufficient data for entry content")
+		}
+		entry := fields[entryStart:entryEnd]
+
+		keyLen := int(entry[0])
+		if len(entry) < 1+keyLen {
+			return nil, wrapError(typedValueErr, "insufficient data for key")
+		}
+		key := string(entry[1 : 1+keyLen])
+
+		value, err := decodeTypedValue(entry[1+keyLen:], strs)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+
+		pos = entryEnd
+	}
+	return result, nil
+}
+
+func decodeTypedListElements(data []byte, strs stringMaker) ([]any, error) {
+	if len(data) == 0 {
+		return []any{}, nil
+	}
+
+	sizeLen := int(data[0])
+	if len(data) < sizeLen+1 {
+		return nil, wrapError(typedValueErr, "insufficient data for list size")
+	}
+	listSize, err := decodeUint(data[1 : 1+sizeLen])
+	if err != nil {
+		return nil, wrapError(typedValueErr, "failed to decode list size", err.Error())
+	}
+	listStart := 1 + sizeLen
+	listEnd := listStart + int(listSize)
+	if len(data) < listEnd {
+		return nil, wrapError(typedValueErr, "insufficient data for list content")
+	}
+	elements := data[listStart:listEnd]
+
+	result := []any{}
+	for pos := 0; pos < len(elements); {
+		size, err := getElementSize(elements[pos:])
+		if err != nil {
+			return nil, wrapError(typedValueErr, err.Error())
+		}
+		if pos+size > len(elements) {
+			return nil, wrapError(typedValueErr, "insufficient data for list element")
+		}
+		value, err := decodeTypedValue(elements[pos:pos+size], strs)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		pos += size
+	}
+	return result, nil
+}
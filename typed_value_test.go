@@ -0,0 +1,80 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedValues(t *testing.T) {
+	decoder := NewConfigurableDecoder(WithTypedValues(true))
+
+	t.Run("scalars keep their wire type", func(t *testing.T) {
+		tests := []struct {
+			value    any
+			expected TypedValue
+		}{
+			{int64(5), TypedValue{Type: TypeInt, Value: int64(5)}},
+			{uint64(5), TypedValue{Type: TypeUint, Value: uint64(5)}},
+			{byte(5), TypedValue{Type: TypeByte, Value: byte(5)}},
+			{"five", TypedValue{Type: TypeString, Value: "five"}},
+			{nil, TypedValue{Type: TypeNull}},
+		}
+
+		for _, tt := range tests {
+			data, err := Encode(tt.value)
+			require.NoError(t, err)
+
+			decoded, err := decoder.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, decoded)
+		}
+	})
+
+	t.Run("containers wrap nested values", func(t *testing.T) {
+		data, err := Encode(map[string]any{
+			"count": uint64(5),
+			"items": []any{int64(5), byte(5)},
+			"tags":  []string{"a"},
+		})
+		require.NoError(t, err)
+
+		decoded, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, TypedValue{Type: TypeObject, Value: map[string]any{
+			"count": TypedValue{Type: TypeUint, Value: uint64(5)},
+			"items": TypedValue{Type: TypeUntypedList, Value: []any{
+				TypedValue{Type: TypeInt, Value: int64(5)},
+				TypedValue{Type: TypeByte, Value: byte(5)},
+			}},
+			"tags": TypedValue{Type: TypeTypedList, Value: []string{"a"}},
+		}}, decoded)
+	})
+	t.Run("maps wrap their values", func(t *testing.T) {
+		data, err := Encode(map[int64]any{1: uint64(1), -2: "two"})
+		require.NoError(t, err)
+
+		decoded, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, TypedValue{Type: TypeMap, Value: map[int64]any{
+			1:  TypedValue{Type: TypeUint, Value: uint64(1)},
+			-2: TypedValue{Type: TypeString, Value: "two"},
+		}}, decoded)
+	})
+	t.Run("object lists wrap the values of their rows", func(t *testing.T) {
+		type item struct {
+			ID uint64 `json:"id"`
+		}
+		data, err := NewConfigurableEncoder(WithObjectLists(true)).Encode([]item{{1}, {2}})
+		require.NoError(t, err)
+		require.Equal(t, byte(TypeObjectList), data[1])
+
+		decoded, err := decoder.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, TypedValue{Type: TypeObjectList, Value: []any{
+			map[string]any{"id": TypedValue{Type: TypeUint, Value: uint64(1)}},
+			map[string]any{"id": TypedValue{Type: TypeUint, Value: uint64(2)}},
+		}}, decoded)
+	})
+}
@@ -1,5 +1,7 @@
 package bogo
 
+import "strings"
+
 type Type byte
 
 // Type constants
@@ -9,6 +11,8 @@ const (
 	Version byte = 0x00 // version 0
 )
 
+// Type IDs are part of the wire format and never change. New types are
+// added at the end, before typeCount, and listed by AllTypes.
 const (
 	TypeNull = iota
 	TypeBoolTrue
@@ -24,10 +28,76 @@ const (
 	TypeUntypedList
 	TypeTypedList
 	TypeObject
+	TypeDate
+	TypeTimeOfDay
+	TypeRange
+	TypeDuration
+	TypeBigInt
+	TypeBigFloat
+	TypeComplex
+	TypeMap
+	TypeObjectList
+	TypeZonedTimestamp
+	TypeExt
+	TypeChunkedList
+	TypeDecimal
+	TypeExpiring
+	TypeSymbols
+	TypeSymbol
+	TypeUnion
+	TypeNumeric
+	TypeKeyedObject
+	TypeTimestampNanos
+
+	// typeCount is the number of regular types; it must stay last
+	typeCount
+)
+
+// Compact types store their value or length in the type byte itself. They
+// occupy 0xA0-0xFF; 0x20-0x9F remain available for regular types.
+const (
+	// TypeFixUint (0xA0-0xBF) holds an unsigned integer 0-31 in the low bits
+	TypeFixUint Type = 0xA0
+	// TypeFixStr (0xC0-0xDF) holds the length of a string shorter than 32 bytes in the low bits
+	TypeFixStr Type = 0xC0
+	// TypeFixInt (0xE0-0xFF) holds a non-negative signed integer 0-31 in the low bits
+	TypeFixInt Type = 0xE0
+
+	maxFixUint = 0x1F
+	maxFixStr  = 0x1F
+	maxFixInt  = 0x1F
 )
 
+// isFixUint reports whether t is in the TypeFixUint range
+func isFixUint(t Type) bool {
+	return t >= TypeFixUint && t <= TypeFixUint+maxFixUint
+}
+
+// isFixStr reports whether t is in the TypeFixStr range
+func isFixStr(t Type) bool {
+	return t >= TypeFixStr && t <= TypeFixStr+maxFixStr
+}
+
+// isFixInt reports whether t is in the TypeFixInt range
+func isFixInt(t Type) bool {
+	return t >= TypeFixInt
+}
+
+// baseType maps compact types to the regular type they stand for
+func (t Type) baseType() Type {
+	switch {
+	case isFixUint(t):
+		return TypeUint
+	case isFixStr(t):
+		return TypeString
+	case isFixInt(t):
+		return TypeInt
+	}
+	return t
+}
+
 func (t Type) String() string {
-	switch t {
+	switch t.baseType() {
 	case TypeNull:
 		return "<null>"
 	case TypeBoolTrue:
@@ -54,10 +124,68 @@ func (t Type) String() string {
 		return "<blob>"
 	case TypeTimestamp:
 		return "<timestamp>"
+	case TypeDate:
+		return "<date>"
+	case TypeTimeOfDay:
+		return "<time_of_day>"
+	case TypeRange:
+		return "<range>"
+	case TypeDuration:
+		return "<duration>"
+	case TypeBigInt:
+		return "<big_int>"
+	case TypeBigFloat:
+		return "<big_float>"
+	case TypeComplex:
+		return "<complex>"
+	case TypeMap:
+		return "<map>"
+	case TypeObjectList:
+		return "<object_list>"
+	case TypeZonedTimestamp:
+		return "<zoned_timestamp>"
+	case TypeExt:
+		return "<ext>"
+	case TypeChunkedList:
+		return "<chunked_list>"
+	case TypeDecimal:
+		return "<decimal>"
+	case TypeExpiring:
+		return "<expiring>"
+	case TypeSymbols:
+		return "<symbols>"
+	case TypeSymbol:
+		return "<symbol>"
+	case TypeUnion:
+		return "<union>"
+	case TypeNumeric:
+		return "<numeric>"
+	case TypeKeyedObject:
+		return "<keyed_object>"
+	case TypeTimestampNanos:
+		return "<timestamp_nanos>"
 	}
 	return "<unknown>"
 }
 
+// AllTypes returns every regular type in ID order. Their names are given by
+// String and TypeName. Compact types, which stand for TypeUint, TypeString
+// and TypeInt, are not listed.
+func AllTypes() []Type {
+	types := make([]Type, typeCount)
+	for i := range types {
+		types[i] = Type(i)
+	}
+	return types
+}
+
+// TypeName returns the name of t without decoration, e.g. "string" or
+// "typed_list", for use as a metric label. Compact types report the name of the
+// type they stand for.
+func TypeName(t Type) string {
+	return strings.Trim(t.String(), "<>")
+}
+
 type TypeNumber interface {
 	int64 | float64
 }
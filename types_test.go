@@ -0,0 +1,130 @@
+package bogo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllTypes(t *testing.T) {
+	t.Run("IDs are stable", func(t *testing.T) {
+		names := []string{
+			"null", "bool:true", "bool:false", "string", "byte", "int", "uint",
+			"float", "blob", "timestamp", "list", "typed_list", "object", "date",
+			"time_of_day", "range", "duration", "big_int", "big_float", "complex",
+			"map", "object_list", "zoned_timestamp", "ext",
+			"chunked_list", "decimal", "expiring", "symbols", "symbol", "union", "numeric",
+			"keyed_object", "timestamp_nanos",
+		}
+		types := AllTypes()
+		assert.Len(t, types, len(names), "new types are appended to this table")
+		for i, typ := range types {
+			assert.Equal(t, Type(i), typ)
+			if i < len(names) {
+				assert.Equal(t, names[i], TypeName(typ), "type %#x", i)
+			}
+		}
+	})
+
+	t.Run("the next ID is unassigned", func(t *testing.T) {
+		next := Type(typeCount)
+		assert.Equal(t, "<unknown>", next.String(), "add the type before typeCount")
+		_, ok := LayoutOf(next)
+		assert.False(t, ok)
+	})
+
+	// Every type must be handled by each switch on type IDs. A handled type
+	// may fail on the made-up data of the value below, but not on its type, so
+	// the unsupported-type messages must not appear, and it must never panic.
+	for _, typ := range AllTypes() {
+		t.Run(TypeName(typ), func(t *testing.T) {
+			assert.NotEqual(t, "<unknown>", typ.String())
+			_, ok := LayoutOf(typ)
+			assert.True(t, ok, "LayoutOf")
+
+			value := sizedValue(typ)
+			assert.NotNil(t, value, "getElementSize sizes none of the candidate values")
+			if value == nil {
+				value = []byte{byte(typ), 1, 1}
+			}
+			document := append([]byte{Version}, value...)
+			unsupported := []string{
+				fmt.Sprintf("unsupported type: %d", typ),
+				fmt.Sprintf("unsupported type %d", typ),
+				fmt.Sprintf("unsupported value type: %d", typ),
+				"type coder not supported",
+			}
+			checks := map[string]func() error{
+				"getElementSize":        func() error { _, err := getElementSize(value); return err },
+				"decodeValue":           func() error { _, err := decodeValue(value); return err },
+				"Decode":                func() error { _, err := Decode(document); return err },
+				"Decoder.Decode":        func() error { _, err := NewConfigurableDecoder().Decode(document); return err },
+				"secure Decoder.Decode": func() error { _, err := NewSecureDecoder().Decode(document); return err },
+				"Footprint":             func() error { _, err := Footprint(document); return err },
+			}
+			for name, check := range checks {
+				var err error
+				if !assert.NotPanics(t, func() { err = check() }, name) || err == nil {
+					continue
+				}
+				for _, msg := range unsupported {
+					assert.False(t, strings.Contains(err.Error(), msg), "%s does not handle %s: %v", name, typ, err)
+				}
+			}
+
+			// Values cut short must be rejected rather than crash the decoder
+			if len(value) > 1 {
+				assertRejected(t, document[:len(document)-1])
+			}
+		})
+	}
+}
+
+// sizedValue returns a value of type typ that getElementSize accepts, made
+// of a few candidate bodies that are well formed for most types, or nil
+func sizedValue(typ Type) []byte {
+	for _, body := range [][]byte{
+		{1, 1, 0},                      // empty containers, one-byte scalars
+		{1, 0},                         // zero counts
+		{1, 0, 1, 0},                   // pairs of varints
+		append(make([]byte, 12), 1, 0), // fixed-size fields followed by a size
+	} {
+		value := append(append([]byte{byte(typ)}, body...), make([]byte, 24)...)
+		if size, err := getElementSize(value); err == nil && size <= len(value) {
+			return value[:size]
+		}
+	}
+	return nil
+}
+
+// assertRejected checks that every decode entry point returns an error for
+// the malformed document, without panicking
+func assertRejected(t *testing.T, document []byte) {
+	t.Helper()
+	checks := map[string]func() error{
+		"Decode":                func() error { _, err := Decode(document); return err },
+		"Decoder.Decode":        func() error { _, err := NewConfigurableDecoder().Decode(document); return err },
+		"secure Decoder.Decode": func() error { _, err := NewSecureDecoder().Decode(document); return err },
+		"typed Decoder.Decode": func() error {
+			_, err := NewConfigurableDecoder(WithTypedValues(true)).Decode(document)
+			return err
+		},
+		"Unmarshal":       func() error { var v any; return Unmarshal(document, &v) },
+		"Document.Get":    func() error { _, err := Document(document).Get("a"); return err },
+		"Document.Set":    func() error { _, err := Document(document).Set("a", 1); return err },
+		"Footprint":       func() error { _, err := Footprint(document); return err },
+		"DecodeSelective": func() error { _, err := DecodeSelective(document, []string{"a"}); return err },
+	}
+	// Repair and salvage may recover part of the document rather than reject it
+	assert.NotPanics(t, func() { _, _, _ = Repair(document) }, "Repair")
+	assert.NotPanics(t, func() { _, _ = NewConfigurableDecoder(WithSalvage(true)).Decode(document) }, "salvage Decoder.Decode")
+	for name, check := range checks {
+		var err error
+		if !assert.NotPanics(t, func() { err = check() }, name) {
+			continue
+		}
+		assert.Error(t, err, name)
+	}
+}
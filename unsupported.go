@@ -0,0 +1,78 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedKindPolicy controls what happens to values with no bogo
+// representation: channels, functions, uintptr and unsafe.Pointer. The policy
+// applies to nil channels and functions too, so a value is treated the same
+// whatever it holds and however deeply it is nested.
+type UnsupportedKindPolicy int
+
+const (
+	// UnsupportedKindError fails the encode with an *UnsupportedTypeError
+	// naming the path of the value (default)
+	UnsupportedKindError UnsupportedKindPolicy = iota
+	// UnsupportedKindSkip leaves such values out: struct fields and map
+	// entries are omitted, list elements become null so later indexes keep
+	// their meaning, and a top-level value encodes as null
+	UnsupportedKindSkip
+)
+
+func (p UnsupportedKindPolicy) String() string {
+	switch p {
+	case UnsupportedKindError:
+		return "error"
+	case UnsupportedKindSkip:
+		return "skip"
+	}
+	return "<unknown>"
+}
+
+// UnsupportedTypeError is returned when a value that cannot be encoded is met
+type UnsupportedTypeError struct {
+	Path string // Location of the value, e.g. "items[2].callback"; "" for the top-level value
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("bogo encode error: unsupported type %s", e.Type)
+	}
+	return fmt.Sprintf("bogo encode error: unsupported type %s at %s", e.Type, e.Path)
+}
+
+// isUnsupportedKind reports whether values of kind k have no bogo representation
+func isUnsupportedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Chan, reflect.Func, reflect.Uintptr, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// childError inspects err, returned while encoding the child of a container
+// found at segment. skip reports that the child is an unsupported value to
+// leave out; deeper values never get here when skipping, as their own
+// containers left them out. Otherwise, when err comes from an unsupported
+// value or one past the output size limit, it is returned with segment
+// prepended to its path.
+func (e *Encoder) childError(err error, segment string) (pathErr error, skip bool) {
+	var tooLarge *OutputSizeError
+	if errors.As(err, &tooLarge) {
+		tooLarge.Path = joinPath(segment, tooLarge.Path)
+		return tooLarge, false
+	}
+	var unsupported *UnsupportedTypeError
+	if !errors.As(err, &unsupported) {
+		return nil, false
+	}
+	if e.UnsupportedKinds == UnsupportedKindSkip {
+		return nil, true
+	}
+	unsupported.Path = joinPath(segment, unsupported.Path)
+	return unsupported, false
+}
@@ -0,0 +1,95 @@
+package bogo
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unsupportedStep struct {
+	Name string `json:"name"`
+	Run  func() `json:"run"`
+}
+
+type unsupportedJob struct {
+	Steps []unsupportedStep `json:"steps"`
+	Done  chan struct{}     `json:"done"`
+}
+
+func TestUnsupportedKinds(t *testing.T) {
+	x := 1
+	values := []any{
+		make(chan int),
+		(chan int)(nil),
+		func() {},
+		(func())(nil),
+		uintptr(1),
+		unsafe.Pointer(&x),
+	}
+
+	for _, v := range values {
+		_, err := Marshal(v)
+		var unsupported *UnsupportedTypeError
+		require.ErrorAs(t, err, &unsupported, "%T", v)
+		assert.Equal(t, reflect.TypeOf(v), unsupported.Type)
+		assert.Empty(t, unsupported.Path)
+		assert.Contains(t, err.Error(), "unsupported type")
+
+		_, err = encode(v)
+		assert.ErrorAs(t, err, &unsupported, "package-level encode of %T", v)
+	}
+}
+
+func TestUnsupportedKindPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		path  string
+	}{
+		{"struct field", struct{ C uintptr }{}, "C"},
+		{"nil function field", unsupportedStep{Name: "build"}, "run"},
+		{"map entry", map[string]any{"a": map[string]any{"b": uintptr(1)}}, "a.b"},
+		{"list element", []any{"ok", func() {}}, "[1]"},
+		{"nested", map[string]any{"steps": []unsupportedStep{{Name: "build"}}}, "steps[0].run"},
+		{"behind a pointer", map[string]any{"p": &struct{ F func() }{}}, "p.F"},
+		{"reflected map", map[string]chan int{"c": nil}, "c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.value)
+			var unsupported *UnsupportedTypeError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.path, unsupported.Path)
+			assert.Contains(t, err.Error(), " at "+tt.path)
+		})
+	}
+}
+
+func TestUnsupportedKindSkip(t *testing.T) {
+	encoder := NewConfigurableEncoder(WithUnsupportedKinds(UnsupportedKindSkip))
+	roundTrip := func(t *testing.T, v any) any {
+		t.Helper()
+		data, err := encoder.Encode(v)
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		return decoded
+	}
+
+	assert.Nil(t, roundTrip(t, func() {}))
+	assert.Equal(t, map[string]any{"name": "build"}, roundTrip(t, unsupportedStep{Name: "build", Run: func() {}}))
+	assert.Equal(t, []any{"ok", nil, int64(2)}, roundTrip(t, []any{"ok", uintptr(1), 2}))
+	assert.Equal(t, map[string]any{
+		"steps": []any{map[string]any{"name": "build"}},
+	}, roundTrip(t, unsupportedJob{Steps: []unsupportedStep{{Name: "build"}}, Done: make(chan struct{})}))
+}
+
+func TestUnsupportedKindPolicyString(t *testing.T) {
+	assert.Equal(t, "error", UnsupportedKindError.String())
+	assert.Equal(t, "skip", UnsupportedKindSkip.String())
+	assert.Equal(t, "<unknown>", UnsupportedKindPolicy(9).String())
+}
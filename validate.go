@@ -0,0 +1,152 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FormatValidator checks that a string conforms to a named format
+type FormatValidator func(value string) error
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]FormatValidator{
+		"e164": validateE164,
+	}
+)
+
+// RegisterFormat registers a named string format that can be enforced at decode
+// time by declaring it in a struct tag, for example `json:"contact,format=email"`.
+// Registering a name that already exists replaces its validator.
+func RegisterFormat(name string, validator FormatValidator) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = validator
+}
+
+// lookupFormat returns the validator registered under name
+func lookupFormat(name string) (FormatValidator, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	validator, ok := formats[name]
+	return validator, ok
+}
+
+// ValidationError reports a decoded string that does not match the format
+// declared for its field. Path locates the value within the decoded document,
+// e.g. "contacts[2].email".
+type ValidationError struct {
+	Path   string
+	Format string
+	Value  string
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("bogo: value %q is not a valid %s: %v", e.Value, e.Format, e.Err)
+	}
+	return fmt.Sprintf("bogo: field %s: value %q is not a valid %s: %v", e.Path, e.Value, e.Format, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// prefixPath prepends a path segment to validation and field errors so that
+// problems in nested values report their full location. Other errors are
+// returned as-is.
+func prefixPath(err error, segment string) error {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		verr.Path = joinPath(segment, verr.Path)
+		return verr
+	}
+	var ferr *FieldError
+	if errors.As(err, &ferr) {
+		ferr.Path = joinPath(segment, ferr.Path)
+		return ferr
+	}
+	return err
+}
+
+// joinPath prepends segment to path, e.g. "contacts" and "[2].email"
+func joinPath(segment, path string) string {
+	if segment == "" {
+		return path
+	}
+	if path == "" || strings.HasPrefix(path, "[") {
+		return segment + path
+	}
+	return segment + "." + path
+}
+
+// getStructFieldFormat returns the format declared with the format= tag option
+func getStructFieldFormat(field reflect.StructField, tagName string) string {
+	tag := field.Tag.Get(tagName)
+	for _, opt := range strings.Split(tag, ",")[1:] {
+		if name, ok := strings.CutPrefix(opt, "format="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// ValidateFormat checks value, a string or a list of strings, against the
+// format registered under name, as decoding does for fields declared with the
+// format= tag option. Empty strings are valid.
+func ValidateFormat(name string, value any) error {
+	return validateFormat(value, name)
+}
+
+// validateFormat checks a decoded value against a named format. Strings are
+// validated directly and lists are validated element by element; empty strings
+// and other values are left to the regular assignment rules.
+func validateFormat(value any, format string) error {
+	validator, ok := lookupFormat(format)
+	if !ok {
+		return fmt.Errorf("bogo: unknown format %q", format)
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		if err := validator(v); err != nil {
+			return &ValidationError{Format: format, Value: v, Err: err}
+		}
+	case []string:
+		for i, s := range v {
+			if s == "" {
+				continue
+			}
+			if err := validator(s); err != nil {
+				return &ValidationError{Path: fmt.Sprintf("[%d]", i), Format: format, Value: s, Err: err}
+			}
+		}
+	case []any:
+		for i, elem := range v {
+			if err := validateFormat(elem, format); err != nil {
+				return prefixPath(err, fmt.Sprintf("[%d]", i))
+			}
+		}
+	}
+	return nil
+}
+
+func validateE164(value string) error {
+	digits := strings.TrimPrefix(value, "+")
+	if len(digits) == len(value) || len(digits) < 2 || len(digits) > 15 || digits[0] == '0' {
+		return errors.New("expected + followed by up to 15 digits")
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return errors.New("expected + followed by up to 15 digits")
+		}
+	}
+	return nil
+}
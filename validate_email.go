@@ -0,0 +1,25 @@
+//go:build !bogo_small && !tinygo
+
+package bogo
+
+import (
+	"errors"
+	"net/mail"
+)
+
+// The email format parses addresses with net/mail, which bogo_small and TinyGo
+// builds replace with a lighter syntax check.
+func init() {
+	formats["email"] = validateEmail
+}
+
+func validateEmail(value string) error {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return err
+	}
+	if addr.Address != value {
+		return errors.New("display names are not allowed")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build bogo_small || tinygo
+
+package bogo
+
+import (
+	"errors"
+	"strings"
+)
+
+// Small and TinyGo builds check the shape of addresses without net/mail: a local part
+// and a dotted domain separated by a single @, with no spaces or brackets.
+func init() {
+	formats["email"] = validateEmail
+}
+
+func validateEmail(value string) error {
+	local, domain, ok := strings.Cut(value, "@")
+	if !ok || local == "" || domain == "" || strings.Contains(domain, "@") {
+		return errors.New("expected local@domain")
+	}
+	if strings.ContainsAny(value, " \t<>()[],;:\\\"") {
+		return errors.New("unexpected character in address")
+	}
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return errors.New("expected a dotted domain")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package bogo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatValidation(t *testing.T) {
+	type Contact struct {
+		Email string   `json:"email,format=email"`
+		Phone string   `json:"phone,format=e164"`
+		Alt   []string `json:"alt,format=email"`
+	}
+	type Account struct {
+		Owner    Contact            `json:"owner"`
+		Contacts map[string]Contact `json:"contacts"`
+	}
+
+	t.Run("valid values decode", func(t *testing.T) {
+		account := Account{
+			Owner:    Contact{Email: "ada@example.com", Phone: "+14155550100", Alt: []string{"a@b.io"}},
+			Contacts: map[string]Contact{"work": {Email: "bob@example.com", Phone: "+442071838750"}},
+		}
+		data, err := Marshal(account)
+		require.NoError(t, err)
+
+		var decoded Account
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, account, decoded)
+	})
+
+	t.Run("violations report paths", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			account Account
+			path    string
+			format  string
+		}{
+			{"top level field", Account{Owner: Contact{Email: "not-an-email"}}, "owner.email", "email"},
+			{"map entry", Account{Contacts: map[string]Contact{"home": {Phone: "555-0100"}}}, "contacts.home.phone", "e164"},
+			{"string list", Account{Owner: Contact{Alt: []string{"ok@example.com", "nope"}}}, "owner.alt[1]", "email"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				data, err := Marshal(tt.account)
+				require.NoError(t, err)
+
+				var decoded Account
+				err = Unmarshal(data, &decoded)
+				var verr *ValidationError
+				require.True(t, errors.As(err, &verr), "expected validation error, got %v", err)
+				assert.Equal(t, tt.path, verr.Path)
+				assert.Equal(t, tt.format, verr.Format)
+			})
+		}
+	})
+
+	t.Run("custom formats", func(t *testing.T) {
+		RegisterFormat("upper", func(value string) error {
+			if strings.ToUpper(value) != value {
+				return errors.New("must be upper case")
+			}
+			return nil
+		})
+
+		type Code struct {
+			Value string `json:"value,format=upper"`
+		}
+
+		data, err := Marshal(Code{Value: "abc"})
+		require.NoError(t, err)
+
+		var decoded Code
+		assert.ErrorContains(t, Unmarshal(data, &decoded), "must be upper case")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		type Bad struct {
+			Value string `json:"value,format=missing"`
+		}
+
+		data, err := Marshal(Bad{Value: "x"})
+		require.NoError(t, err)
+
+		var decoded Bad
+		assert.ErrorContains(t, Unmarshal(data, &decoded), `unknown format "missing"`)
+	})
+
+	t.Run("ValidateFormat", func(t *testing.T) {
+		assert.NoError(t, ValidateFormat("email", "ada@example.com"))
+		assert.NoError(t, ValidateFormat("email", ""))
+		assert.EqualError(t, ValidateFormat("e164", "123"), `bogo: value "123" is not a valid e164: expected + followed by up to 15 digits`)
+
+		var verr *ValidationError
+		require.ErrorAs(t, ValidateFormat("email", []string{"ada@example.com", "nope"}), &verr)
+		assert.Equal(t, "[1]", verr.Path)
+		assert.ErrorContains(t, ValidateFormat("missing", "x"), `unknown format "missing"`)
+	})
+}
@@ -0,0 +1,42 @@
+package bogo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrVersionMismatch(t *testing.T) {
+	data := []byte{0x07, TypeNull}
+	strict := NewConfigurableDecoder(WithDecoderStrictMode(true))
+
+	checks := map[string]func() error{
+		"Decode":         func() error { _, err := Decode(data); return err },
+		"Decoder.Decode": func() error { _, err := strict.Decode(data); return err },
+		"Scan":           func() error { return strict.Scan(data) },
+		"Document":       func() error { _, err := Document(data).Get("a"); return err },
+		"Repair":         func() error { _, _, err := Repair(data); return err },
+		"Batch": func() error {
+			var out []any
+			return UnmarshalMany(append(BatchMagic[:], data...), &out)
+		},
+	}
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			err := check()
+			var mismatch *ErrVersionMismatch
+			require.True(t, errors.As(err, &mismatch), "got %v", err)
+			assert.Equal(t, byte(7), mismatch.Got)
+			assert.Equal(t, Version, mismatch.Want)
+			assert.Contains(t, mismatch.Hint, "newer version")
+			assert.Contains(t, err.Error(), "unsupported version 7, expected version 0")
+		})
+	}
+
+	t.Run("lenient decoders read on", func(t *testing.T) {
+		_, err := NewConfigurableDecoder(WithDecoderStrictMode(false)).Decode(data)
+		assert.NoError(t, err)
+	})
+}
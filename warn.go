@@ -0,0 +1,70 @@
+package bogo
+
+import "fmt"
+
+// WarnKind identifies a degraded decoding path that non-strict decoding
+// tolerates instead of failing
+type WarnKind int
+
+const (
+	// WarnVersionMismatch is reported when data carries a different format
+	// version and is decoded anyway
+	WarnVersionMismatch WarnKind = iota
+	// WarnUnknownType is reported when an unknown type is returned as an
+	// UnknownType because WithUnknownTypes is enabled
+	WarnUnknownType
+	// WarnCorruptEntry is reported when a corrupt object entry is dropped
+	// because WithSalvage is enabled
+	WarnCorruptEntry
+)
+
+func (k WarnKind) String() string {
+	switch k {
+	case WarnVersionMismatch:
+		return "version_mismatch"
+	case WarnUnknownType:
+		return "unknown_type"
+	case WarnCorruptEntry:
+		return "corrupt_entry"
+	}
+	return "<unknown>"
+}
+
+// WarnEvent describes one degraded decoding path that was taken
+type WarnEvent struct {
+	Kind    WarnKind
+	Message string
+	Version byte   // Version found in the data, for WarnVersionMismatch
+	Type    Type   // Type found in the data, for WarnUnknownType
+	Path    string // Location of the dropped entry, for WarnCorruptEntry
+}
+
+func (e WarnEvent) String() string {
+	return fmt.Sprintf("bogo warning (%s): %s", e.Kind, e.Message)
+}
+
+// WithWarnHandler sets a function called whenever the decoder tolerates data
+// it would reject in strict mode, so operators can observe how often degraded
+// parsing paths are hit in production. The handler runs synchronously on the
+// decoding goroutine and should return quickly.
+func WithWarnHandler(handler func(WarnEvent)) DecoderOption {
+	return func(d *Decoder) {
+		d.WarnHandler = handler
+	}
+}
+
+// warn reports ev to the configured WarnHandler, if any
+func (d *Decoder) warn(ev WarnEvent) {
+	if d.WarnHandler != nil {
+		d.WarnHandler(ev)
+	}
+}
+
+// warnVersion reports a tolerated version mismatch
+func (d *Decoder) warnVersion(version byte) {
+	d.warn(WarnEvent{
+		Kind:    WarnVersionMismatch,
+		Message: fmt.Sprintf("decoding version %d data as version %d", version, Version),
+		Version: version,
+	})
+}
@@ -0,0 +1,68 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarnHandler(t *testing.T) {
+	var events []WarnEvent
+	handler := WithWarnHandler(func(ev WarnEvent) { events = append(events, ev) })
+
+	t.Run("version mismatch", func(t *testing.T) {
+		events = nil
+		decoder := NewConfigurableDecoder(handler)
+
+		decoded, err := decoder.Decode([]byte{0x99, TypeBoolTrue})
+		require.NoError(t, err)
+		assert.Equal(t, true, decoded)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, WarnVersionMismatch, events[0].Kind)
+		assert.Equal(t, byte(0x99), events[0].Version)
+		assert.Contains(t, events[0].String(), "version_mismatch")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		events = nil
+		decoder := NewConfigurableDecoder(handler, WithUnknownTypes(true))
+
+		_, err := decoder.Decode([]byte{Version, 0x99, 0x01})
+		require.NoError(t, err)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, WarnUnknownType, events[0].Kind)
+		assert.Equal(t, Type(0x99), events[0].Type)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		events = nil
+		decoder := NewConfigurableDecoder(handler)
+		decoder.OnField("", func(any) {})
+
+		require.NoError(t, decoder.Scan([]byte{0x99, TypeNull}))
+		require.Len(t, events, 1)
+		assert.Equal(t, WarnVersionMismatch, events[0].Kind)
+	})
+
+	t.Run("strict mode fails instead of warning", func(t *testing.T) {
+		events = nil
+		decoder := NewConfigurableDecoder(handler, WithDecoderStrictMode(true))
+
+		_, err := decoder.Decode([]byte{0x99, TypeNull})
+		assert.Error(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("well-formed data does not warn", func(t *testing.T) {
+		events = nil
+		data, err := Encode(map[string]any{"a": []any{1, "b"}})
+		require.NoError(t, err)
+
+		_, err = NewConfigurableDecoder(handler).Decode(data)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
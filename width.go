@@ -0,0 +1,181 @@
+package bogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var numericErr = errors.New("bogo numeric error")
+
+// A number written with its Go width is laid out as
+//
+//	TypeNumeric | kind | value
+//
+// where kind is one of the numericKind constants and value is a complete
+// TypeInt, TypeUint or TypeFloat value, or one of their compact forms, whose
+// value fits the kind.
+
+// numericKind is the Go type of a TypeNumeric value
+type numericKind byte
+
+const (
+	numericInt numericKind = iota + 1
+	numericInt8
+	numericInt16
+	numericInt32
+	numericUint
+	numericUint8
+	numericUint16
+	numericUint32
+	numericFloat32
+)
+
+// numericKinds maps the kinds of the numbers whose width is recorded to their
+// numericKind. int64, uint64 and float64 decode as themselves and have none.
+var numericKinds = map[reflect.Kind]numericKind{
+	reflect.Int:     numericInt,
+	reflect.Int8:    numericInt8,
+	reflect.Int16:   numericInt16,
+	reflect.Int32:   numericInt32,
+	reflect.Uint:    numericUint,
+	reflect.Uint8:   numericUint8,
+	reflect.Uint16:  numericUint16,
+	reflect.Uint32:  numericUint32,
+	reflect.Float32: numericFloat32,
+}
+
+// WithNumericWidths records the Go type of int, int8, int16, int32, uint,
+// uint16, uint32 and float32 values, and of uint8 values written as numbers,
+// so that decoding into an interface returns a number of the type it was
+// written with rather than an int64, uint64 or float64. Lists of them are
+// written element by element. Each number takes two bytes more.
+func WithNumericWidths(enabled bool) EncoderOption {
+	return func(e *Encoder) {
+		e.NumericWidths = enabled
+	}
+}
+
+// encodeIntWidth encodes v, a value of kind, with its width when widths are
+// recorded
+func (e *Encoder) encodeIntWidth(kind reflect.Kind, v int64) ([]byte, error) {
+	data, err := e.encodeInt(v)
+	return e.withWidth(kind, data, err)
+}
+
+// encodeUintWidth encodes v, a value of kind, with its width when widths are
+// recorded
+func (e *Encoder) encodeUintWidth(kind reflect.Kind, v uint64) ([]byte, error) {
+	data, err := e.encodeUint(v)
+	return e.withWidth(kind, data, err)
+}
+
+// encodeFloatWidth encodes v, a value of kind, with its width when widths are
+// recorded
+func (e *Encoder) encodeFloatWidth(kind reflect.Kind, v float64) ([]byte, error) {
+	data, err := encodeFloatWithPolicy(v, e.NaNPolicy)
+	return e.withWidth(kind, data, err)
+}
+
+// withWidth returns the encoded number data, written for a value of kind,
+// with its width when widths are recorded
+func (e *Encoder) withWidth(kind reflect.Kind, data []byte, err error) ([]byte, error) {
+	numeric, ok := numericKinds[kind]
+	if !e.NumericWidths || !ok || err != nil || Type(data[0]) == TypeNull {
+		return data, err
+	}
+	buf := make([]byte, 0, 2+len(data))
+	return append(append(buf, byte(TypeNumeric), byte(numeric)), data...), nil
+}
+
+// numericSize returns the size of the numeric data, after its type byte
+func numericSize(data []byte) (int, error) {
+	if len(data) < 2 {
+		return 0, wrapError(numericErr, "insufficient data")
+	}
+	size, err := getElementSize(data[1:])
+	if err != nil {
+		return 0, err
+	}
+	return 1 + size, nil
+}
+
+// decodeNumeric decodes the numeric data, after its type byte, into a number
+// of its kind
+func decodeNumeric(data []byte) (any, error) {
+	if len(data) < 2 {
+		return nil, wrapError(numericErr, "insufficient data")
+	}
+	kind := numericKind(data[0])
+	v, err := decodeValue(data[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", numericErr, err)
+	}
+
+	var n any
+	fits := false
+	switch x := v.(type) {
+	case int64:
+		switch kind {
+		case numericInt:
+			n, fits = fitNumber[int](x)
+		case numericInt8:
+			n, fits = fitNumber[int8](x)
+		case numericInt16:
+			n, fits = fitNumber[int16](x)
+		case numericInt32:
+			n, fits = fitNumber[int32](x)
+		}
+	case uint64:
+		switch kind {
+		case numericUint:
+			n, fits = fitNumber[uint](x)
+		case numericUint8:
+			n, fits = fitNumber[uint8](x)
+		case numericUint16:
+			n, fits = fitNumber[uint16](x)
+		case numericUint32:
+			n, fits = fitNumber[uint32](x)
+		}
+	case float64:
+		if kind == numericFloat32 {
+			n, fits = float32(x), float64(float32(x)) == x || math.IsNaN(x)
+		}
+	}
+	if !fits {
+		return nil, wrapError(numericErr, fmt.Sprintf("%v does not fit kind %d", v, kind))
+	}
+	return n, nil
+}
+
+// fitNumber returns v as a T, and whether it holds the same value
+func fitNumber[T int | int8 | int16 | int32 | uint | uint8 | uint16 | uint32, V int64 | uint64](v V) (any, bool) {
+	t := T(v)
+	return t, V(t) == v
+}
+
+// widenNumber returns the numbers decodeNumeric returns, other than uint8, as
+// the int64, uint64 or float64 they would have decoded as without their
+// width, and v unchanged otherwise
+func widenNumber(v any) any {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case uint:
+		return uint64(n)
+	case uint16:
+		return uint64(n)
+	case uint32:
+		return uint64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
@@ -0,0 +1,87 @@
+package bogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumericWidths(t *testing.T) {
+	type level int16
+	type sample struct {
+		A int     `json:"a"`
+		B int8    `json:"b"`
+		C int16   `json:"c"`
+		D int32   `json:"d"`
+		E uint    `json:"e"`
+		F uint16  `json:"f"`
+		G uint32  `json:"g"`
+		H float32 `json:"h"`
+		I int64   `json:"i"`
+		L level   `json:"l"`
+		N []int   `json:"n"`
+	}
+	in := sample{A: -1, B: -8, C: 300, D: -70000, E: 1 << 20, F: 65535, G: 1 << 31, H: 1.5, I: 9, L: -3, N: []int{1, 2}}
+
+	for _, e := range []*Encoder{
+		NewConfigurableEncoder(WithNumericWidths(true)),
+		NewConfigurableEncoder(WithNumericWidths(true), WithCompactIntegers(true)),
+	} {
+		data, err := e.Encode(in)
+		require.NoError(t, err)
+
+		want := map[string]any{
+			"a": -1, "b": int8(-8), "c": int16(300), "d": int32(-70000),
+			"e": uint(1 << 20), "f": uint16(65535), "g": uint32(1 << 31), "h": float32(1.5),
+			"i": int64(9), "l": int16(-3), "n": []any{1, 2},
+		}
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+		decoded, err = NewConfigurableDecoder().Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded)
+
+		var out sample
+		require.NoError(t, Unmarshal(data, &out))
+		assert.Equal(t, in, out)
+
+		var widened struct {
+			C int64   `json:"c"`
+			H float64 `json:"h"`
+		}
+		require.NoError(t, Unmarshal(data, &widened))
+		assert.Equal(t, int64(300), widened.C)
+		assert.Equal(t, 1.5, widened.H)
+
+		_, err = Footprint(data)
+		assert.NoError(t, err)
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		decoded, err := Decode(mustMarshal(t, map[string]any{"c": int16(3)}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"c": int64(3)}, decoded)
+	})
+
+	t.Run("uint8 numbers", func(t *testing.T) {
+		data, err := NewConfigurableEncoder(WithNumericWidths(true), WithUint8AsNumber(true)).Encode(uint8(7))
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(7), decoded)
+	})
+
+	t.Run("values that do not fit", func(t *testing.T) {
+		value, err := encodeInt(300)
+		require.NoError(t, err)
+		data := append([]byte{Version, byte(TypeNumeric), byte(numericInt8)}, value...)
+		_, err = Decode(data)
+		assert.ErrorContains(t, err, "300 does not fit")
+
+		data = append([]byte{Version, byte(TypeNumeric), byte(numericUint16)}, value...)
+		_, err = NewConfigurableDecoder().Decode(data)
+		assert.ErrorContains(t, err, "does not fit")
+	})
+}
@@ -0,0 +1,134 @@
+package bogo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var wireErr = errors.New("bogo wire error")
+
+// The primitives below read and write the framing bogo uses for its own
+// containers, so that formats built on top of bogo, such as segment files and
+// indexes, frame their records the same way. A size prefix is one byte holding
+// the length of a uvarint followed by the uvarint itself; a container header is
+// a type byte followed by the size prefix of its payload.
+//
+// Readers return io.EOF when r is exhausted before the first byte, and an error
+// wrapping io.ErrUnexpectedEOF when it ends part way through.
+
+// WriteUvarintLen writes n as a size prefix and returns the number of bytes written
+func WriteUvarintLen(w io.Writer, n uint64) (int, error) {
+	var buf [1 + binary.MaxVarintLen64]byte
+	return w.Write(appendUvarintLen(buf[:0], n))
+}
+
+// ReadUvarintLen reads a size prefix written by WriteUvarintLen
+func ReadUvarintLen(r io.Reader) (uint64, error) {
+	var buf [binary.MaxVarintLen64]byte
+	if _, err := io.ReadFull(r, buf[:1]); err != nil {
+		return 0, err
+	}
+	return readUvarint(r, buf[:], int(buf[0]))
+}
+
+// WriteLengthPrefixed writes payload preceded by its size prefix and returns
+// the number of bytes written
+func WriteLengthPrefixed(w io.Writer, payload []byte) (int, error) {
+	n, err := WriteUvarintLen(w, uint64(len(payload)))
+	if err != nil {
+		return n, err
+	}
+	m, err := w.Write(payload)
+	return n + m, err
+}
+
+// ReadLengthPrefixed reads a payload written by WriteLengthPrefixed. The
+// payload is read incrementally, so a corrupt size prefix cannot allocate
+// more memory than r holds.
+func ReadLengthPrefixed(r io.Reader) ([]byte, error) {
+	size, err := ReadUvarintLen(r)
+	if err != nil {
+		return nil, err
+	}
+	return readPayload(r, size)
+}
+
+// WriteTypedHeader writes a container header, the type byte followed by the
+// size prefix of a payload of size bytes, and returns the number of bytes
+// written. Writing the payload after the header produces a value bogo decodes.
+func WriteTypedHeader(w io.Writer, typ Type, size uint64) (int, error) {
+	var buf [2 + binary.MaxVarintLen64]byte
+	return w.Write(appendTypedHeader(buf[:0], typ, size))
+}
+
+// ReadTypedHeader reads a container header written by WriteTypedHeader and
+// returns the type and the payload size
+func ReadTypedHeader(r io.Reader) (Type, uint64, error) {
+	var buf [binary.MaxVarintLen64]byte
+	if _, err := io.ReadFull(r, buf[:1]); err != nil {
+		return 0, 0, err
+	}
+	typ := Type(buf[0])
+	if _, err := io.ReadFull(r, buf[:1]); err != nil {
+		return 0, 0, truncated("size prefix", err)
+	}
+	size, err := readUvarint(r, buf[:], int(buf[0]))
+	return typ, size, err
+}
+
+// appendUvarintLen appends n as a size prefix to dst
+func appendUvarintLen(dst []byte, n uint64) []byte {
+	mark := len(dst)
+	dst = binary.AppendUvarint(append(dst, 0), n)
+	dst[mark] = byte(len(dst) - mark - 1)
+	return dst
+}
+
+// appendObjectEntry appends an object entry holding key and the encoded value
+// to dst
+func appendObjectEntry(dst []byte, key string, value []byte) []byte {
+	dst = appendUvarintLen(dst, uint64(1+len(key)+len(value)))
+	return append(append(append(dst, byte(len(key))), key...), value...)
+}
+
+// appendTypedHeader appends a container header to dst
+func appendTypedHeader(dst []byte, typ Type, size uint64) []byte {
+	return appendUvarintLen(append(dst, byte(typ)), size)
+}
+
+// readUvarint reads the uvarint of a size prefix whose length byte was sizeLen
+func readUvarint(r io.Reader, buf []byte, sizeLen int) (uint64, error) {
+	if sizeLen == 0 || sizeLen > len(buf) {
+		return 0, wrapError(wireErr, fmt.Sprintf("invalid size prefix length %d", sizeLen))
+	}
+	if _, err := io.ReadFull(r, buf[:sizeLen]); err != nil {
+		return 0, truncated("size prefix", err)
+	}
+	n, err := decodeUint(buf[:sizeLen])
+	if err != nil {
+		return 0, wrapError(wireErr, err.Error())
+	}
+	return n, nil
+}
+
+// readPayload reads exactly size bytes from r
+func readPayload(r io.Reader, size uint64) ([]byte, error) {
+	payload, err := io.ReadAll(io.LimitReader(r, int64(min(size, 1<<62))))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(payload)) < size {
+		return nil, truncated("payload", io.ErrUnexpectedEOF)
+	}
+	return payload, nil
+}
+
+// truncated reports a read that ended part way through a frame
+func truncated(what string, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: truncated %s: %w", wireErr, what, err)
+}
@@ -0,0 +1,131 @@
+package bogo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUvarintLen(t *testing.T) {
+	for _, n := range []uint64{0, 1, 127, 128, 1 << 32, 1<<64 - 1} {
+		var buf bytes.Buffer
+		written, err := WriteUvarintLen(&buf, n)
+		require.NoError(t, err)
+		assert.Equal(t, buf.Len(), written)
+
+		// Same framing as the size prefixes bogo writes itself
+		encoded, err := encodeUint(n)
+		require.NoError(t, err)
+		assert.Equal(t, encoded[1:], buf.Bytes())
+
+		got, err := ReadUvarintLen(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, n, got)
+	}
+}
+
+func TestLengthPrefixed(t *testing.T) {
+	var buf bytes.Buffer
+	for _, payload := range [][]byte{{}, []byte("segment"), bytes.Repeat([]byte{7}, 300)} {
+		_, err := WriteLengthPrefixed(&buf, payload)
+		require.NoError(t, err)
+	}
+
+	for _, want := range [][]byte{{}, []byte("segment"), bytes.Repeat([]byte{7}, 300)} {
+		got, err := ReadLengthPrefixed(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ReadLengthPrefixed(&buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTypedHeader(t *testing.T) {
+	t.Run("builds values bogo decodes", func(t *testing.T) {
+		entry, err := encodeFieldEntry("name", "Ada")
+		require.NoError(t, err)
+
+		buf := bytes.NewBuffer([]byte{Version})
+		_, err = WriteTypedHeader(buf, TypeObject, uint64(len(entry)))
+		require.NoError(t, err)
+		buf.Write(entry)
+
+		var v map[string]any
+		require.NoError(t, Unmarshal(buf.Bytes(), &v))
+		assert.Equal(t, map[string]any{"name": "Ada"}, v)
+	})
+
+	t.Run("reads headers bogo writes", func(t *testing.T) {
+		data, err := Marshal([]any{"a", int64(1)})
+		require.NoError(t, err)
+
+		r := bytes.NewReader(data[1:])
+		typ, size, err := ReadTypedHeader(r)
+		require.NoError(t, err)
+		assert.Equal(t, Type(TypeUntypedList), typ)
+		assert.Equal(t, uint64(r.Len()), size)
+	})
+}
+
+func TestWireErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(io.Reader) error
+		data []byte
+		eof  bool // clean end of input rather than a truncated frame
+	}{
+		{"empty size prefix", readUvarintLen, nil, true},
+		{"empty header", readTypedHeader, nil, true},
+		{"empty frame", readLengthPrefixed, nil, true},
+		{"truncated size prefix", readUvarintLen, []byte{2, 0x80}, false},
+		{"header without size", readTypedHeader, []byte{TypeObject}, false},
+		{"truncated payload", readLengthPrefixed, []byte{1, 5, 'a', 'b'}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.read(bytes.NewReader(tt.data))
+			if tt.eof {
+				assert.Equal(t, io.EOF, err)
+				return
+			}
+			assert.ErrorIs(t, err, wireErr)
+			assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		})
+	}
+
+	t.Run("invalid size prefix length", func(t *testing.T) {
+		for _, data := range [][]byte{{0}, {11}} {
+			_, err := ReadUvarintLen(bytes.NewReader(data))
+			assert.ErrorIs(t, err, wireErr)
+		}
+	})
+
+	t.Run("oversized payload is not preallocated", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := WriteUvarintLen(&buf, 1<<62)
+		require.NoError(t, err)
+		buf.WriteString("short")
+
+		_, err = ReadLengthPrefixed(&buf)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func readUvarintLen(r io.Reader) error {
+	_, err := ReadUvarintLen(r)
+	return err
+}
+
+func readTypedHeader(r io.Reader) error {
+	_, _, err := ReadTypedHeader(r)
+	return err
+}
+
+func readLengthPrefixed(r io.Reader) error {
+	_, err := ReadLengthPrefixed(r)
+	return err
+}
@@ -221,3 +221,46 @@ func TestNilTypedValues(t *testing.T) {
 		})
 	}
 }
+
+func TestNilCollectionsAsEmpty(t *testing.T) {
+	type profile struct {
+		Tags   []string          `json:"tags"`
+		Labels map[string]string `json:"labels"`
+		Scores map[int64]int     `json:"scores"`
+		Avatar []byte            `json:"avatar"`
+		Parent *profile          `json:"parent"`
+	}
+	encoder := NewConfigurableEncoder(WithNilCollectionsAsEmpty(true))
+
+	data, err := encoder.Encode(profile{})
+	require.NoError(t, err)
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"tags":   []any{},
+		"labels": map[string]any{},
+		"scores": map[int64]any{},
+		"avatar": []byte{},
+		"parent": nil,
+	}, decoded, "nil pointers stay null")
+
+	var got profile
+	require.NoError(t, Unmarshal(data, &got))
+	assert.NotNil(t, got.Tags)
+	assert.NotNil(t, got.Labels)
+
+	data, err = encoder.Encode([]string(nil))
+	require.NoError(t, err)
+	decoded, err = Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, []any{}, decoded)
+
+	t.Run("off by default", func(t *testing.T) {
+		data, err := Encode(profile{})
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Nil(t, decoded.(map[string]any)["tags"])
+		assert.Nil(t, decoded.(map[string]any)["labels"])
+	})
+}